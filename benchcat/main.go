@@ -0,0 +1,430 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// benchcat draws the results of Go benchmarks, or how they changed between
+// runs, as bar charts in the terminal, so regressions stand out in red.
+//
+// Usage:
+//
+//	go test -bench . | benchcat [flags]
+//	benchcat [flags] old.txt new.txt...
+//	benchstat old.txt new.txt | benchcat [flags]
+//
+// A single run of go test -bench is drawn as the mean of each metric of every
+// benchmark. With more files, the change from the first one is drawn instead,
+// in red when it's a regression and in green when it's an improvement. The
+// output of benchstat is drawn the same way, with the changes it found.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/pkg/errors"
+)
+
+var (
+	metrics   = flag.String("metric", "", "comma separated units drawn, such as ns/op,allocs/op, all of them by default")
+	threshold = flag.Float64("threshold", 5, "changes smaller than this percentage are drawn in gray")
+	width     = flag.Int("width", chart.DefaultWidth, "width of the charts in pixels")
+	jsonFlag  = flag.Bool("json", false, "print a line of JSON describing each chart displayed, written to the terminal instead")
+)
+
+// Colors of changes.
+var (
+	regression  = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+	improvement = color.RGBA{0x2c, 0xa0, 0x2c, 0xff}
+	unchanged   = color.RGBA{0xa0, 0xa0, 0xa0, 0xff}
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\tgo test -bench . | %s [flags]\n\t%s [flags] old.txt new.txt...\n\tbenchstat old.txt new.txt | %s [flags]\n", os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(paths []string) error {
+	var runs []*benchRun
+	if len(paths) == 0 {
+		r, err := parse(os.Stdin)
+		if err != nil {
+			return errors.Wrap(err, "could not read standard input")
+		}
+		r.name = "stdin"
+		runs = append(runs, r)
+	}
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return errors.Wrapf(err, "could not open %s", p)
+		}
+		r, err := parse(f)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", p)
+		}
+		r.name = path.Base(p)
+		runs = append(runs, r)
+	}
+
+	var charts []*chart.BarChart
+	switch {
+	case len(runs) == 1 && len(runs[0].keys) == 0 && len(runs[0].tables) > 0:
+		charts = benchstatCharts(runs[0].tables)
+	case len(runs) == 1:
+		charts = valueCharts(runs[0])
+	default:
+		charts = changeCharts(runs)
+	}
+	if len(charts) == 0 {
+		return errors.New("no benchmark results found, pipe the output of go test -bench or benchstat")
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	for _, c := range charts {
+		c.Width = *width
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, c.Draw()); err != nil {
+			return errors.Wrap(err, "could not encode chart")
+		}
+		name := strings.Replace(strings.Fields(c.Title)[0], "/", "-", -1)
+		enc, err := imgcat.NewEncoder(out, append(options, imgcat.Name(strings.TrimSuffix(name, ":")+".png"))...)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(buf); err != nil {
+			return errors.Wrap(err, "could not display chart")
+		}
+	}
+	return nil
+}
+
+// A benchRun holds the results of a run of go test -bench, or the tables of
+// benchstat.
+type benchRun struct {
+	name string
+	// keys of the benchmarks in the order they were first seen.
+	keys []string
+	// labels of the benchmarks by key.
+	labels map[string]string
+	// samples of each metric of the benchmarks, by key and by unit.
+	samples map[string]map[string][]float64
+	// units in the order they were first seen.
+	units []string
+	// tables found in the output of benchstat.
+	tables []*table
+}
+
+// A table of changes reported by benchstat for a unit.
+type table struct {
+	unit string
+	// columns compared to the first one, named after the files if known.
+	columns []string
+	rows    []change
+}
+
+// A change of a benchmark, in percents. NaN means benchstat found no
+// significant change.
+type change struct {
+	name   string
+	deltas []float64
+}
+
+// parse reads the output of go test -bench, or of benchstat.
+func parse(r io.Reader) (*benchRun, error) {
+	run := &benchRun{labels: map[string]string{}, samples: map[string]map[string][]float64{}}
+	var pkg string
+	pkgs := map[string]bool{}
+	var t *table
+	var names []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, "pkg: ") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "pkg: "))
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && strings.HasPrefix(fields[0], "Benchmark") {
+			pkgs[pkg] = true
+			run.add(pkg, fields)
+			continue
+		}
+
+		// benchstat tables, drawing their borders with │.
+		fields = strings.Fields(strings.Replace(line, "│", " ", -1))
+		switch {
+		case len(fields) == 0:
+			t = nil
+		case strings.Contains(line, "│") && !strings.Contains(line, "vs base") && t == nil && len(fields) > 1:
+			// The names of the files compared, above the units.
+			names = fields
+		case strings.Contains(line, "vs base"):
+			t = &table{unit: fields[0]}
+			if len(names) > 1 {
+				t.columns = names[1:]
+			}
+			run.tables = append(run.tables, t)
+		case fields[0] == "name" && fields[len(fields)-1] == "delta" && len(fields) >= 4:
+			// The older format: name old time/op new time/op delta.
+			t = &table{unit: fields[2]}
+			run.tables = append(run.tables, t)
+		case t != nil:
+			if c, ok := parseChange(fields); ok {
+				t.rows = append(t.rows, c)
+			}
+		}
+	}
+	if len(pkgs) > 1 {
+		for _, k := range run.keys {
+			p, name := splitKey(k)
+			run.labels[k] = path.Base(p) + "." + name
+		}
+	}
+	return run, s.Err()
+}
+
+// add records the metrics of a line of results such as
+// BenchmarkFoo-8  1000  1234 ns/op  56 B/op  2 allocs/op, up to the first
+// value that isn't a number. Lines without any are left out.
+func (r *benchRun) add(pkg string, fields []string) {
+	name := strings.TrimPrefix(fields[0], "Benchmark")
+	if _, err := strconv.Atoi(fields[1]); err != nil {
+		return
+	}
+	var values []float64
+	var units []string
+	for i := 2; i+1 < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			break
+		}
+		values, units = append(values, v), append(units, fields[i+1])
+	}
+	if len(values) == 0 {
+		return
+	}
+	key := pkg + " " + name
+	if _, ok := r.samples[key]; !ok {
+		r.keys = append(r.keys, key)
+		r.labels[key] = name
+		r.samples[key] = map[string][]float64{}
+	}
+	for i, unit := range units {
+		if !r.hasUnit(unit) {
+			r.units = append(r.units, unit)
+		}
+		r.samples[key][unit] = append(r.samples[key][unit], values[i])
+	}
+}
+
+func (r *benchRun) hasUnit(unit string) bool {
+	for _, u := range r.units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// mean returns the mean of the samples of a benchmark for unit, and whether
+// there are any.
+func (r *benchRun) mean(key, unit string) (float64, bool) {
+	samples := r.samples[key][unit]
+	if len(samples) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += v
+	}
+	return sum / float64(len(samples)), true
+}
+
+func splitKey(key string) (pkg, name string) {
+	i := strings.LastIndex(key, " ")
+	return key[:i], key[i+1:]
+}
+
+// parseChange parses a row of a benchstat table, such as
+// Foo-8  1.23µs ± 2%  1.10µs ± 1%  -10.57%  (p=0.000 n=10+10). Changes are
+// signed percents, or ~ when they're not significant.
+func parseChange(fields []string) (change, bool) {
+	c := change{name: fields[0]}
+	for _, f := range fields[1:] {
+		if f == "~" {
+			c.deltas = append(c.deltas, math.NaN())
+			continue
+		}
+		if !strings.HasSuffix(f, "%") || (f[0] != '+' && f[0] != '-') {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(f, "%"), 64)
+		if err != nil {
+			continue
+		}
+		c.deltas = append(c.deltas, v)
+	}
+	return c, len(c.deltas) > 0
+}
+
+// wanted reports whether unit is drawn, as given with -metric.
+func wanted(unit string) bool {
+	if *metrics == "" {
+		return true
+	}
+	for _, m := range strings.Split(*metrics, ",") {
+		if strings.TrimSpace(m) == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// higherIsBetter reports whether larger values of unit are improvements, as
+// for throughputs.
+func higherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s") || unit == "speed"
+}
+
+// valueCharts returns a chart of the mean of each metric of the benchmarks.
+func valueCharts(r *benchRun) []*chart.BarChart {
+	var charts []*chart.BarChart
+	for _, unit := range r.units {
+		if !wanted(unit) {
+			continue
+		}
+		c := &chart.BarChart{Title: unit, Format: func(v float64) string { return strconv.FormatFloat(v, 'g', 4, 64) }}
+		for _, k := range r.keys {
+			if v, ok := r.mean(k, unit); ok {
+				c.Bars = append(c.Bars, chart.Bar{Label: r.labels[k], Value: v})
+			}
+		}
+		charts = append(charts, c)
+	}
+	return charts
+}
+
+// changeCharts returns a chart of the change of each metric of the benchmarks
+// from the first run to the others.
+func changeCharts(runs []*benchRun) []*chart.BarChart {
+	base := runs[0]
+	var charts []*chart.BarChart
+	for _, unit := range base.units {
+		if !wanted(unit) {
+			continue
+		}
+		title := unit + ": " + runs[1].name + " vs " + base.name
+		if len(runs) > 2 {
+			title = unit + " vs " + base.name
+		}
+		c := changeChart(title, unit)
+		for _, k := range base.keys {
+			old, ok := base.mean(k, unit)
+			if !ok || old == 0 {
+				continue
+			}
+			for _, r := range runs[1:] {
+				v, ok := r.mean(k, unit)
+				if !ok {
+					continue
+				}
+				label := base.labels[k]
+				if len(runs) > 2 {
+					label += " " + r.name
+				}
+				c.Bars = append(c.Bars, chart.Bar{Label: label, Value: (v - old) / old * 100})
+			}
+		}
+		if len(c.Bars) > 0 {
+			charts = append(charts, c)
+		}
+	}
+	return charts
+}
+
+// benchstatCharts returns a chart of the changes of each table of benchstat.
+func benchstatCharts(tables []*table) []*chart.BarChart {
+	var charts []*chart.BarChart
+	for _, t := range tables {
+		if !wanted(t.unit) || len(t.rows) == 0 {
+			continue
+		}
+		c := changeChart(t.unit+" vs base", t.unit)
+		for _, row := range t.rows {
+			for i, d := range row.deltas {
+				label := row.name
+				if len(row.deltas) > 1 && i < len(t.columns) {
+					label += " " + t.columns[i]
+				}
+				if math.IsNaN(d) {
+					// Not significant, drawn as no change.
+					d = 0
+				}
+				c.Bars = append(c.Bars, chart.Bar{Label: label, Value: d})
+			}
+		}
+		charts = append(charts, c)
+	}
+	return charts
+}
+
+// changeChart returns a chart of changes in percents of unit, colored by
+// whether they're regressions or improvements.
+func changeChart(title, unit string) *chart.BarChart {
+	return &chart.BarChart{
+		Title: title,
+		Format: func(v float64) string {
+			if v == 0 {
+				return "~"
+			}
+			return fmt.Sprintf("%+.1f%%", v)
+		},
+		BarColor: func(b chart.Bar) color.Color {
+			change := b.Value
+			if higherIsBetter(unit) {
+				change = -change
+			}
+			switch {
+			case change >= *threshold:
+				return regression
+			case change <= -*threshold:
+				return improvement
+			}
+			return unchanged
+		},
+	}
+}
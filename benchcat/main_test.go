@@ -0,0 +1,160 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tc := []struct {
+		name    string
+		input   string
+		keys    []string
+		units   []string
+		samples map[string]map[string][]float64
+	}{
+		{
+			name: "ordinary",
+			input: `goos: linux
+goarch: amd64
+pkg: example.com/foo
+BenchmarkEncode-8   	    1000	      1234 ns/op
+BenchmarkEncode-8   	    1000	      1266 ns/op
+BenchmarkDecode-8   	     500	      2000 ns/op
+PASS
+ok  	example.com/foo	3.210s
+`,
+			keys:  []string{"example.com/foo Encode-8", "example.com/foo Decode-8"},
+			units: []string{"ns/op"},
+			samples: map[string]map[string][]float64{
+				"example.com/foo Encode-8": {"ns/op": {1234, 1266}},
+				"example.com/foo Decode-8": {"ns/op": {2000}},
+			},
+		},
+		{
+			name: "benchmem",
+			input: `pkg: example.com/foo
+BenchmarkEncode-8   	    1000	      1234 ns/op	     560 B/op	       2 allocs/op
+BenchmarkEncode-8   	    1000	      1266 ns/op	  12.50 MB/s	     560 B/op	       2 allocs/op
+`,
+			keys:  []string{"example.com/foo Encode-8"},
+			units: []string{"ns/op", "B/op", "allocs/op", "MB/s"},
+			samples: map[string]map[string][]float64{
+				"example.com/foo Encode-8": {"ns/op": {1234, 1266}, "B/op": {560, 560}, "allocs/op": {2, 2}, "MB/s": {12.5}},
+			},
+		},
+		{
+			name: "malformed",
+			input: `pkg: example.com/foo
+BenchmarkFail-8 --- FAIL: BenchmarkFail-8
+BenchmarkEncode-8   	    many	      1234 ns/op
+BenchmarkDecode-8   	     500	      fast ns/op
+BenchmarkHalf-8   	     500	      2000 ns/op	     lots B/op
+BenchmarkShort-8 500
+Benchmark
+`,
+			keys:  []string{"example.com/foo Half-8"},
+			units: []string{"ns/op"},
+			samples: map[string]map[string][]float64{
+				"example.com/foo Half-8": {"ns/op": {2000}},
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("could not parse: %v", err)
+			}
+			if !reflect.DeepEqual(run.keys, tt.keys) {
+				t.Errorf("expected keys %q; got %q", tt.keys, run.keys)
+			}
+			if !reflect.DeepEqual(run.units, tt.units) {
+				t.Errorf("expected units %q; got %q", tt.units, run.units)
+			}
+			if !reflect.DeepEqual(run.samples, tt.samples) {
+				t.Errorf("expected samples %v; got %v", tt.samples, run.samples)
+			}
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	run, err := parse(strings.NewReader(`pkg: example.com/foo
+BenchmarkEncode-8 1000 1234 ns/op
+pkg: example.com/bar
+BenchmarkEncode-8 1000 4321 ns/op
+`))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+	// Benchmarks of several packages are told apart by their package.
+	want := map[string]string{"example.com/foo Encode-8": "foo.Encode-8", "example.com/bar Encode-8": "bar.Encode-8"}
+	if !reflect.DeepEqual(run.labels, want) {
+		t.Errorf("expected labels %v; got %v", want, run.labels)
+	}
+	if m, ok := run.mean("example.com/bar Encode-8", "ns/op"); !ok || m != 4321 {
+		t.Errorf("expected mean 4321; got %v, %v", m, ok)
+	}
+}
+
+func TestParseBenchstat(t *testing.T) {
+	run, err := parse(strings.NewReader(`goos: linux
+pkg: example.com/foo
+          │   old.txt   │               new.txt               │
+          │   sec/op    │   sec/op     vs base                │
+Encode-8    1.234µ ± 2%   1.100µ ± 1%  -10.86% (p=0.000 n=10)
+Decode-8    2.000µ ± 1%   2.010µ ± 3%        ~ (p=0.436 n=10)
+geomean     1.571µ        1.487µ        -5.35%
+
+name      old time/op  new time/op  delta
+Encode-8  1.23µs ± 2%  1.10µs ± 1%  -10.57%  (p=0.000 n=10+10)
+Decode-8  2.00µs ± 1%  1.98µs ± 3%     ~     (p=0.436 n=10+10)
+Broken-8  1.00µs ± 1%  oops
+`))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+	if len(run.tables) != 2 {
+		t.Fatalf("expected 2 tables; got %d", len(run.tables))
+	}
+
+	tc := []struct {
+		unit    string
+		columns []string
+		rows    []change
+	}{
+		{"sec/op", []string{"new.txt"}, []change{{"Encode-8", []float64{-10.86}}, {"Decode-8", []float64{math.NaN()}}, {"geomean", []float64{-5.35}}}},
+		{"time/op", nil, []change{{"Encode-8", []float64{-10.57}}, {"Decode-8", []float64{math.NaN()}}}},
+	}
+	for i, tt := range tc {
+		got := run.tables[i]
+		if got.unit != tt.unit || !reflect.DeepEqual(got.columns, tt.columns) {
+			t.Errorf("table %d: expected unit %q and columns %q; got %q and %q", i, tt.unit, tt.columns, got.unit, got.columns)
+		}
+		if len(got.rows) != len(tt.rows) {
+			t.Errorf("table %d: expected rows %v; got %v", i, tt.rows, got.rows)
+			continue
+		}
+		for j, row := range tt.rows {
+			if got.rows[j].name != row.name || !sameDeltas(got.rows[j].deltas, row.deltas) {
+				t.Errorf("table %d: expected row %v; got %v", i, row, got.rows[j])
+			}
+		}
+	}
+}
+
+// sameDeltas reports whether a and b hold the same changes, NaN included.
+func sameDeltas(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.IsNaN(a[i]) && math.IsNaN(b[i])) {
+			return false
+		}
+	}
+	return true
+}
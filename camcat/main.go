@@ -0,0 +1,222 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// camcat displays the frames of a camera stream live in the terminal, for
+// quick camera checks on headless boxes. It reads MJPEG streams over HTTP,
+// RTSP streams through ffmpeg, and concatenated JPEG images on standard input.
+//
+// Usage:
+//
+//	camcat [flags] url
+//
+// Frames are redrawn in place until interrupted. When frames arrive faster
+// than they can be displayed, or faster than -fps, the older ones are dropped
+// so what's displayed stays current.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+var (
+	fps      = flag.Float64("fps", 10, "maximum number of frames displayed per second")
+	once     = flag.Bool("once", false, "display a single frame inline and exit")
+	ffmpeg   = flag.String("ffmpeg", "ffmpeg", "ffmpeg command used to read RTSP streams")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the frame displayed with -once, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] url\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "url is an http, https, rtsp, or rtsps URL, or - to read JPEG images from standard input\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *fps <= 0 {
+		log.Fatal("-fps must be positive")
+	}
+	if *jsonFlag && !*once {
+		log.Fatal("-json describes a single frame, use it with -once")
+	}
+
+	src, err := open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *once {
+		err = snapshot(src)
+	} else {
+		err = live(src, displayName(flag.Arg(0)))
+	}
+	if cerr := src.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// displayName returns how the stream at rawurl is named in the status line,
+// without the password it may contain.
+func displayName(rawurl string) string {
+	if rawurl == "-" {
+		return "standard input"
+	}
+	if u, err := url.Parse(rawurl); err == nil {
+		return u.Redacted()
+	}
+	return rawurl
+}
+
+// snapshot displays the first frame of src.
+func snapshot(src source) error {
+	frame, err := src.next()
+	if err != nil {
+		return errors.Wrap(err, "could not read frame")
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.PaneWidth(100), imgcat.PreserveAspectRatio(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(bytes.NewReader(frame))
+}
+
+// live displays the frames of src full screen as they arrive, with a status
+// line below them, until interrupted.
+func live(src source, name string) error {
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	// The last row holds the status line.
+	rows := size.Rows - 1
+	if rows < 1 {
+		rows = 1
+	}
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true), imgcat.Width(imgcat.Cells(size.Cols)),
+		imgcat.Height(imgcat.Cells(rows)), imgcat.PreserveAspectRatio(true), imgcat.Newline(false))
+	if err != nil {
+		return err
+	}
+
+	// Read frames in the background, keeping only the latest one.
+	frames := make(chan []byte, 1)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			frame, err := src.next()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case <-frames:
+			default:
+			}
+			frames <- frame
+		}
+	}()
+
+	// Use the alternate screen, without cursor, and restore the terminal
+	// when interrupted.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	status := func(format string, args ...interface{}) {
+		fmt.Printf("\x1b[%d;1H\x1b[2K%s", size.Rows, fmt.Sprintf(format, args...))
+	}
+	status("connecting to %s", name)
+
+	var start time.Time
+	shown := 0
+	show := func(frame []byte) error {
+		fmt.Print("\x1b[H")
+		if err := enc.Encode(bytes.NewReader(frame)); err != nil {
+			return err
+		}
+		if shown == 0 {
+			start = time.Now()
+		}
+		shown++
+		var rate float64
+		if shown > 1 {
+			rate = float64(shown-1) / time.Since(start).Seconds()
+		}
+		if cfg, _, err := image.DecodeConfig(bytes.NewReader(frame)); err == nil {
+			status("%s  %dx%d  %.1f fps", name, cfg.Width, cfg.Height, rate)
+		} else {
+			status("%s  %.1f fps", name, rate)
+		}
+		return nil
+	}
+
+	interval := time.Duration(float64(time.Second) / *fps)
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case err := <-errc:
+			// The last frame may still be waiting.
+			select {
+			case frame := <-frames:
+				if err := show(frame); err != nil {
+					return err
+				}
+			default:
+			}
+			if err != errEnd || shown == 0 {
+				return errors.Wrap(err, "could not read frame")
+			}
+			// Keep the last frame on screen until interrupted.
+			status("%s: stream ended after %d frames", name, shown)
+			<-interrupt
+			return nil
+		case frame := <-frames:
+			if err := show(frame); err != nil {
+				return err
+			}
+			select {
+			case <-interrupt:
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxFrameBytes limits the size of a frame, so a broken stream can't exhaust
+// memory.
+const maxFrameBytes = 32 << 20
+
+// errEnd is returned by sources once the stream has ended.
+var errEnd = errors.New("end of stream")
+
+// A source of camera frames, as JPEG images.
+type source interface {
+	// next returns the next frame, or errEnd.
+	next() ([]byte, error)
+	Close() error
+}
+
+// backends open sources by the scheme of their URL.
+var backends = map[string]func(u *url.URL) (source, error){
+	"http":  openMJPEG,
+	"https": openMJPEG,
+	"rtsp":  openFFmpeg,
+	"rtsps": openFFmpeg,
+}
+
+// open returns the source for the stream at rawurl, or standard input if it's
+// "-".
+func open(rawurl string) (source, error) {
+	if rawurl == "-" {
+		return &jpegStream{r: bufio.NewReader(os.Stdin), c: os.Stdin}, nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", rawurl)
+	}
+	backend, ok := backends[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, errors.Errorf("unsupported stream %s, use an http, https, rtsp, or rtsps URL", rawurl)
+	}
+	return backend(u)
+}
+
+// mjpegStream reads the parts of a multipart/x-mixed-replace HTTP response,
+// the usual way IP cameras serve MJPEG. A response with a single image, from
+// the snapshot URLs of cameras, is a stream of one frame.
+type mjpegStream struct {
+	body  io.ReadCloser
+	parts *multipart.Reader
+	done  bool
+}
+
+func openMJPEG(u *url.URL) (source, error) {
+	res, err := http.Get(u.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not connect to %s", u.Redacted())
+	}
+	// Bodies of responses that aren't streams are closed unread, which can't
+	// fail in a way that matters.
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, errors.Errorf("could not get %s: %s", u.Redacted(), res.Status)
+	}
+	typ, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		_ = res.Body.Close()
+		return nil, errors.Wrapf(err, "bad content type from %s", u.Redacted())
+	}
+	switch {
+	case strings.HasPrefix(typ, "multipart/"):
+		// Some cameras include the leading dashes in the boundary.
+		boundary := strings.TrimPrefix(params["boundary"], "--")
+		if boundary == "" {
+			_ = res.Body.Close()
+			return nil, errors.Errorf("no boundary in the %s stream from %s", typ, u.Redacted())
+		}
+		return &mjpegStream{body: res.Body, parts: multipart.NewReader(res.Body, boundary)}, nil
+	case strings.HasPrefix(typ, "image/"):
+		return &mjpegStream{body: res.Body}, nil
+	}
+	_ = res.Body.Close()
+	return nil, errors.Errorf("%s is not a camera stream: got content type %s", u.Redacted(), typ)
+}
+
+func (s *mjpegStream) next() ([]byte, error) {
+	if s.done {
+		return nil, errEnd
+	}
+	if s.parts == nil {
+		s.done = true
+		return readFrame(s.body)
+	}
+	for {
+		part, err := s.parts.NextPart()
+		if err == nil {
+			if typ := part.Header.Get("Content-Type"); typ != "" && !strings.HasPrefix(typ, "image/") {
+				// Some streams interleave other data, such as audio.
+				continue
+			}
+			var frame []byte
+			if frame, err = readFrame(part); err == nil {
+				return frame, nil
+			}
+		}
+		// Streams are usually cut rather than closed with a final boundary,
+		// which drops the frame being sent.
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errEnd
+		}
+		return nil, err
+	}
+}
+
+func (s *mjpegStream) Close() error { return s.body.Close() }
+
+// readFrame reads a whole frame from r.
+func readFrame(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxFrameBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxFrameBytes {
+		return nil, errors.Errorf("frame larger than %d bytes", maxFrameBytes)
+	}
+	return b, nil
+}
+
+// openFFmpeg reads an RTSP stream with ffmpeg, which writes its frames as
+// concatenated JPEG images.
+func openFFmpeg(u *url.URL) (source, error) {
+	path, err := exec.LookPath(*ffmpeg)
+	if err != nil {
+		return nil, errors.Errorf("%s streams need ffmpeg, which was not found: install it or set -ffmpeg", u.Scheme)
+	}
+	cmd := exec.Command(path, "-loglevel", "error", "-rtsp_transport", "tcp", "-i", u.String(),
+		"-an", "-f", "mjpeg", "-q:v", "5", "-")
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run ffmpeg")
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "could not run ffmpeg")
+	}
+	return &ffmpegStream{jpegStream{r: bufio.NewReader(out), c: out}, cmd, stderr}, nil
+}
+
+type ffmpegStream struct {
+	jpegStream
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (s *ffmpegStream) next() ([]byte, error) {
+	frame, err := s.jpegStream.next()
+	if err != errEnd {
+		return frame, err
+	}
+	// ffmpeg exits when it can't read the stream, and tells why.
+	if err := s.cmd.Wait(); err != nil {
+		return nil, errors.Errorf("ffmpeg failed: %s", strings.TrimSpace(s.stderr.String()))
+	}
+	return nil, errEnd
+}
+
+func (s *ffmpegStream) Close() error {
+	if s.cmd.ProcessState == nil {
+		// ffmpeg was stopped while streaming, so it fails, as intended.
+		_ = s.cmd.Process.Kill()
+		_ = s.cmd.Wait()
+	}
+	return nil
+}
+
+// jpegStream splits a stream of concatenated JPEG images, as written by
+// ffmpeg -f mjpeg, following the segments of each image to find where it
+// ends.
+type jpegStream struct {
+	r *bufio.Reader
+	c io.Closer
+}
+
+func (s *jpegStream) next() ([]byte, error) {
+	frame := new(bytes.Buffer)
+	// Skip anything before the start of the image.
+	for {
+		b, err := s.r.ReadByte()
+		if err == io.EOF {
+			return nil, errEnd
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b != 0xff {
+			continue
+		}
+		if next, err := s.r.Peek(1); err == nil && next[0] == 0xd8 {
+			s.r.ReadByte()
+			frame.Write([]byte{0xff, 0xd8})
+			break
+		}
+	}
+	if err := s.copySegments(frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, errors.Wrap(err, "truncated frame")
+	}
+	return frame.Bytes(), nil
+}
+
+// copySegments copies the segments of an image from the start of the image to
+// its end to frame.
+func (s *jpegStream) copySegments(frame *bytes.Buffer) error {
+	for {
+		if frame.Len() > maxFrameBytes {
+			return errors.Errorf("frame larger than %d bytes", maxFrameBytes)
+		}
+		marker, err := s.marker()
+		if err != nil {
+			return err
+		}
+		frame.Write([]byte{0xff, marker})
+		switch {
+		case marker == 0xd9: // End of image.
+			return nil
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd7:
+			// Markers without a length.
+			continue
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(s.r, length[:]); err != nil {
+			return err
+		}
+		n := int(length[0])<<8 | int(length[1])
+		if n < 2 {
+			return errors.New("bad segment length")
+		}
+		frame.Write(length[:])
+		if _, err := io.CopyN(frame, s.r, int64(n-2)); err != nil {
+			return err
+		}
+		if marker == 0xda {
+			// Start of scan, copy the entropy coded data up to the next
+			// marker, where 0xff bytes are followed by zero or a restart
+			// marker.
+			if err := s.copyScan(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// marker reads the next marker, skipping fill bytes.
+func (s *jpegStream) marker() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b != 0xff {
+		return 0, fmt.Errorf("expected marker, got %#x", b)
+	}
+	for {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != 0xff {
+			return b, nil
+		}
+	}
+}
+
+func (s *jpegStream) copyScan(frame *bytes.Buffer) error {
+	for {
+		b, err := s.r.Peek(1)
+		if err != nil {
+			return err
+		}
+		if b[0] != 0xff {
+			frame.WriteByte(b[0])
+			// Discarding what was peeked never fails.
+			_, _ = s.r.Discard(1)
+			continue
+		}
+		b, err = s.r.Peek(2)
+		if err != nil {
+			return err
+		}
+		if b[1] != 0 && (b[1] < 0xd0 || b[1] > 0xd7) {
+			// A marker, left for the caller to read.
+			return nil
+		}
+		frame.Write(b)
+		_, _ = s.r.Discard(2)
+	}
+}
+
+func (s *jpegStream) Close() error { return s.c.Close() }
@@ -0,0 +1,191 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// contactsheet displays the images in a directory as a single contact sheet,
+// a grid of thumbnails labeled with their file names. Sending one image is
+// much faster than sending every image, especially through tmux or SSH.
+//
+// Usage:
+//
+//	contactsheet [flags] [dir]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/webp"
+	"github.com/pkg/errors"
+)
+
+var (
+	all      = flag.Bool("a", false, "include files starting with a dot")
+	cols     = flag.Int("cols", 6, "number of columns of the sheet")
+	size     = flag.Int("size", 160, "size of the thumbnails in pixels")
+	output   = flag.String("o", "", "write the sheet to this file instead of displaying it")
+	webpOut  = flag.Bool("webp", false, "encode the sheet as lossless WebP, which is smaller than PNG")
+	workers  = flag.Int("j", runtime.NumCPU(), "number of images decoded concurrently")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the sheet displayed, written to the terminal instead")
+)
+
+// Formats of the images included in sheets.
+var sheetFormats = map[imgcat.Format]bool{imgcat.PNG: true, imgcat.JPEG: true, imgcat.GIF: true}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] [dir]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	if *output == "" && !imgcat.IsSupported() {
+		log.Fatal("contactsheet is only supported with iTerm2, use -o to write the sheet to a file")
+	}
+
+	thumbs, err := thumbnails(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(thumbs) == 0 {
+		log.Fatalf("no images in %s", dir)
+	}
+	buf := new(bytes.Buffer)
+	encode := png.Encode
+	if *webpOut {
+		encode = webp.Encode
+	}
+	if err := encode(buf, imgcat.ContactSheet(thumbs, *cols, *size)); err != nil {
+		log.Fatalf("could not encode sheet: %v", err)
+	}
+
+	if *output != "" {
+		if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.PaneWidth(100), imgcat.PreserveAspectRatio(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := enc.Encode(buf); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// thumbnails returns the images in dir, sorted by name and scaled down to the
+// size of the thumbnails, using at most -j goroutines. Files that are not
+// images are skipped.
+func thumbnails(dir string) ([]imgcat.Thumbnail, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list %s", dir)
+	}
+	var names []string
+	for _, info := range infos {
+		if info.Mode().IsRegular() && (*all || !strings.HasPrefix(info.Name(), ".")) {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+
+	thumbs := make([]imgcat.Thumbnail, len(names))
+	ok := make([]bool, len(names))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	n := *workers
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				img, err := thumbnail(filepath.Join(dir, names[i]))
+				if err != nil {
+					log.Print(err)
+					continue
+				}
+				if img != nil {
+					thumbs[i], ok[i] = imgcat.Thumbnail{Image: img, Label: names[i]}, true
+				}
+			}
+		}()
+	}
+	for i := range names {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	var images []imgcat.Thumbnail
+	for i, t := range thumbs {
+		if ok[i] {
+			images = append(images, t)
+		}
+	}
+	return images, nil
+}
+
+// thumbnail decodes the image in path and scales it down to the size of the
+// thumbnails, so full size images aren't all kept in memory. It returns nil
+// if the file isn't an image.
+func thumbnail(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	defer f.Close()
+
+	magic := make([]byte, 12)
+	n, _ := io.ReadFull(f, magic)
+	if !sheetFormats[imgcat.Sniff(magic[:n])] {
+		return nil, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", path)
+	}
+	return imgcat.Letterbox(img, *size, *size, color.Transparent), nil
+}
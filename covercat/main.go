@@ -0,0 +1,347 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// covercat draws the coverage of each package of a Go coverage profile as a
+// treemap of its files in the terminal, so poorly covered code can be spotted
+// without opening the HTML report.
+//
+// Usage:
+//
+//	go test -coverprofile cover.out ./... && covercat cover.out
+//	covercat [flags] < cover.out
+//
+// Each file is a rectangle whose area is its number of statements, colored
+// from red when none of them are covered to green when all of them are.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/campoy/tools/imgcat/colormap"
+	"github.com/pkg/errors"
+)
+
+var (
+	pkgFilter = flag.String("pkg", "", "draw only the packages whose import path contains this")
+	width     = flag.Int("width", chart.DefaultWidth, "width of the images in pixels")
+	height    = flag.Int("height", chart.DefaultHeight, "height of the images in pixels")
+	jsonFlag  = flag.Bool("json", false, "print a line of JSON describing each image displayed, written to the terminal instead")
+)
+
+// coverage colors files from uncovered to covered.
+var coverage = colormap.Gradient(
+	color.RGBA{0xf4, 0x6d, 0x43, 0xff},
+	color.RGBA{0xfe, 0xe0, 0x8b, 0xff},
+	color.RGBA{0x66, 0xbd, 0x63, 0xff},
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] cover.out\n\t%s [flags] < cover.out\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(name string) error {
+	in := io.Reader(os.Stdin)
+	if name != "" {
+		f, err := os.Open(name)
+		if err != nil {
+			return errors.Wrapf(err, "could not open %s", name)
+		}
+		defer f.Close()
+		in = f
+	}
+	files, err := parse(in)
+	if err != nil {
+		return err
+	}
+	pkgs := packages(files)
+	if len(pkgs) == 0 {
+		return errors.New("no statements found in the coverage profile")
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	shown := 0
+	for _, p := range pkgs {
+		if !strings.Contains(p.path, *pkgFilter) {
+			continue
+		}
+		shown++
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, p.draw(*width, *height)); err != nil {
+			return errors.Wrap(err, "could not encode treemap")
+		}
+		enc, err := imgcat.NewEncoder(out, append(options, imgcat.Name(path.Base(p.path)+".png"))...)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(buf); err != nil {
+			return errors.Wrapf(err, "could not display %s", p.path)
+		}
+	}
+	if shown == 0 {
+		return errors.Errorf("no package matches %q", *pkgFilter)
+	}
+	return nil
+}
+
+// The statements of a file, and how many of them are covered.
+type file struct {
+	name                string
+	statements, covered int
+}
+
+// percent returns the part of the statements covered in percents.
+func (f file) percent() float64 {
+	if f.statements == 0 {
+		return 0
+	}
+	return 100 * float64(f.covered) / float64(f.statements)
+}
+
+// parse reads a coverage profile, made of a mode line followed by blocks such
+// as github.com/campoy/tools/tree/main.go:12.34,15.2 3 1, and returns its
+// files sorted by name. Blocks listed more than once, as when profiles are
+// concatenated, are covered if any of them is.
+func parse(r io.Reader) ([]file, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]map[string]*block{}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// The name of the file can hold colons, the position can't.
+		i := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[i+1:])
+		if i < 0 || len(fields) != 3 {
+			return nil, errors.Errorf("line %d is not a block of a coverage profile: %q", n, line)
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return nil, errors.Errorf("line %d has a bad number of statements or count: %q", n, line)
+		}
+		name := line[:i]
+		if blocks[name] == nil {
+			blocks[name] = map[string]*block{}
+		}
+		b := blocks[name][fields[0]]
+		if b == nil {
+			b = &block{statements: statements}
+			blocks[name][fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read coverage profile")
+	}
+
+	var files []file
+	for name, bs := range blocks {
+		f := file{name: name}
+		for _, b := range bs {
+			f.statements += b.statements
+			if b.covered {
+				f.covered += b.statements
+			}
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+// A package and its files with statements.
+type pkg struct {
+	path  string
+	files []file
+	total file
+}
+
+// packages groups files by package, sorted by import path.
+func packages(files []file) []*pkg {
+	var pkgs []*pkg
+	byPath := map[string]*pkg{}
+	for _, f := range files {
+		if f.statements == 0 {
+			continue
+		}
+		dir := path.Dir(f.name)
+		p := byPath[dir]
+		if p == nil {
+			p = &pkg{path: dir}
+			byPath[dir] = p
+			pkgs = append(pkgs, p)
+		}
+		p.files = append(p.files, f)
+		p.total.statements += f.statements
+		p.total.covered += f.covered
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].path < pkgs[j].path })
+	return pkgs
+}
+
+// draw returns the treemap of the files of the package, under a title with
+// its coverage.
+func (p *pkg) draw(w, h int) image.Image {
+	scale := w / 400
+	if scale < 1 {
+		scale = 1
+	}
+	margin := 10 * scale
+	title := fmt.Sprintf("%s: %.1f%% of %d statements", p.path, p.total.percent(), p.total.statements)
+	top := margin + bitmapfont.Measure(title, scale).Y + margin
+	if h < top+margin+1 {
+		h = top + margin + 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	bitmapfont.Draw(img, truncate(title, w-2*margin, scale), image.Pt(margin, margin), color.Black, scale)
+
+	// Larger files first, which gives squarer rectangles.
+	files := append([]file(nil), p.files...)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].statements > files[j].statements })
+	area := rect{float64(margin), float64(top), float64(w - 2*margin), float64(h - top - margin)}
+	sizes := make([]float64, len(files))
+	for i, f := range files {
+		sizes[i] = float64(f.statements) / float64(p.total.statements) * area.w * area.h
+	}
+	for i, r := range squarify(sizes, area) {
+		f := files[i]
+		box := image.Rect(int(math.Round(r.x)), int(math.Round(r.y)), int(math.Round(r.x+r.w)), int(math.Round(r.y+r.h)))
+		// Rectangles are separated by white lines.
+		inner := image.Rect(box.Min.X+scale, box.Min.Y+scale, box.Max.X-scale, box.Max.Y-scale)
+		if inner.Empty() {
+			continue
+		}
+		draw.Draw(img, inner, image.NewUniform(coverage(f.percent()/100)), image.Point{}, draw.Src)
+		// The first label that fits is drawn, if any.
+		base, percent := path.Base(f.name), fmt.Sprintf("%.0f%%", f.percent())
+		short := truncate(base, inner.Dx()-2*scale, scale)
+		for _, label := range []string{base + " " + percent, base + "\n" + percent, short + "\n" + percent, short} {
+			if size := bitmapfont.Measure(label, scale); size.X+2*scale <= inner.Dx() && size.Y+2*scale <= inner.Dy() {
+				bitmapfont.Draw(img, label, inner.Min.Add(image.Pt(2*scale, 2*scale)), color.Black, scale)
+				break
+			}
+		}
+	}
+	return img
+}
+
+// A rect is a rectangle with fractional coordinates.
+type rect struct{ x, y, w, h float64 }
+
+// squarify lays out rectangles with the given areas, sorted from the largest,
+// in r, keeping them as square as possible: rectangles are stacked in rows
+// along the shorter side of what's left of r, as long as that makes the
+// longest of their sides shorter.
+func squarify(sizes []float64, r rect) []rect {
+	out := make([]rect, len(sizes))
+	for i := 0; i < len(sizes); {
+		side := math.Min(r.w, r.h)
+		j := i + 1
+		for j < len(sizes) && worst(sizes[i:j+1], side) <= worst(sizes[i:j], side) {
+			j++
+		}
+		var sum float64
+		for _, s := range sizes[i:j] {
+			sum += s
+		}
+		if r.w >= r.h {
+			w := sum / r.h
+			y := r.y
+			for k := i; k < j; k++ {
+				h := sizes[k] / w
+				out[k] = rect{r.x, y, w, h}
+				y += h
+			}
+			r.x, r.w = r.x+w, r.w-w
+		} else {
+			h := sum / r.w
+			x := r.x
+			for k := i; k < j; k++ {
+				w := sizes[k] / h
+				out[k] = rect{x, r.y, w, h}
+				x += w
+			}
+			r.y, r.h = r.y+h, r.h-h
+		}
+		i = j
+	}
+	return out
+}
+
+// worst returns the largest aspect ratio of the rectangles with the given
+// areas stacked along a side.
+func worst(row []float64, side float64) float64 {
+	var sum float64
+	max, min := 0.0, math.Inf(1)
+	for _, s := range row {
+		sum += s
+		max, min = math.Max(max, s), math.Min(min, s)
+	}
+	if sum == 0 || min == 0 {
+		return math.Inf(1)
+	}
+	s2, side2 := sum*sum, side*side
+	return math.Max(side2*max/s2, s2/(side2*min))
+}
+
+// truncate shortens s so it fits in w pixels at the given scale.
+func truncate(s string, w, scale int) string {
+	max := (w/scale + 1) / bitmapfont.Advance
+	r := []rune(s)
+	if len(r) <= max || max <= 3 {
+		return s
+	}
+	return string(r[:max-3]) + "..."
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tc := []struct {
+		name    string
+		profile string
+		files   []file
+	}{
+		{
+			name: "set",
+			profile: `mode: set
+github.com/campoy/tools/tree/main.go:12.34,15.2 3 1
+github.com/campoy/tools/tree/main.go:15.2,17.3 2 0
+github.com/campoy/tools/flags/color.go:10.1,11.2 1 1
+`,
+			files: []file{
+				{"github.com/campoy/tools/flags/color.go", 1, 1},
+				{"github.com/campoy/tools/tree/main.go", 5, 3},
+			},
+		},
+		{
+			name: "count",
+			profile: `mode: count
+a/b.go:1.1,2.2 4 17
+a/b.go:3.1,4.2 1 0
+`,
+			files: []file{{"a/b.go", 5, 4}},
+		},
+		{
+			name: "concatenated",
+			profile: `mode: atomic
+a/b.go:1.1,2.2 4 0
+a/b.go:3.1,4.2 1 0
+mode: atomic
+a/b.go:1.1,2.2 4 2
+
+a/b.go:3.1,4.2 1 0
+`,
+			files: []file{{"a/b.go", 5, 4}},
+		},
+		{
+			name:    "colon in name",
+			profile: "mode: set\nC:/src/a/b.go:1.1,2.2 2 1\n",
+			files:   []file{{"C:/src/a/b.go", 2, 2}},
+		},
+		{
+			name:    "no statements",
+			profile: "mode: set\na/b.go:1.1,1.2 0 0\n",
+			files:   []file{{"a/b.go", 0, 0}},
+		},
+		{
+			name:    "empty",
+			profile: "mode: set\n",
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := parse(strings.NewReader(tt.profile))
+			if err != nil {
+				t.Fatalf("could not parse: %v", err)
+			}
+			if !reflect.DeepEqual(files, tt.files) {
+				t.Errorf("expected files %v; got %v", tt.files, files)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tc := []struct {
+		name, profile, err string
+	}{
+		{"not a profile", "mode: set\nok  \tgithub.com/campoy/tools/tree\t0.01s\n", "line 2 is not a block"},
+		{"no position", "mode: set\na/b.go 2 1\n", "line 2 is not a block"},
+		{"missing count", "mode: set\na/b.go:1.1,2.2 2\n", "line 2 is not a block"},
+		{"extra field", "mode: set\na/b.go:1.1,2.2 2 1 1\n", "line 2 is not a block"},
+		{"bad statements", "a/b.go:1.1,2.2 two 1\n", "line 1 has a bad number"},
+		{"bad count", "mode: set\n\na/b.go:1.1,2.2 2 -\n", "line 3 has a bad number"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.profile))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPackages(t *testing.T) {
+	pkgs := packages([]file{
+		{"a/b/x.go", 4, 1},
+		{"a/b/y.go", 6, 6},
+		{"a/c/z.go", 2, 0},
+		{"a/d/empty.go", 0, 0},
+	})
+	if len(pkgs) != 2 {
+		t.Fatalf("expected 2 packages with statements; got %d", len(pkgs))
+	}
+	if p := pkgs[0]; p.path != "a/b" || len(p.files) != 2 || p.total.percent() != 70 {
+		t.Errorf("expected a/b with 2 files at 70%%; got %s with %d at %v%%", p.path, len(p.files), p.total.percent())
+	}
+	if p := pkgs[1]; p.path != "a/c" || p.total.percent() != 0 {
+		t.Errorf("expected a/c at 0%%; got %s at %v%%", p.path, p.total.percent())
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// A panel is a labeled image in a comparison.
+type panel struct {
+	label string
+	img   image.Image
+}
+
+var (
+	background = color.RGBA{0x30, 0x30, 0x30, 0xff}
+	changed    = color.RGBA{0xff, 0x00, 0x40, 0xff}
+)
+
+// sideBySide draws the given panels next to each other, with their labels on
+// top, in a single image.
+func sideBySide(panels []panel) image.Image {
+	const margin = 8
+	labelH := bitmapfont.Height*2 + margin
+	w, h := margin, 0
+	for _, p := range panels {
+		b := p.img.Bounds()
+		w += b.Dx() + margin
+		if b.Dy() > h {
+			h = b.Dy()
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h+labelH+2*margin))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	x := margin
+	for _, p := range panels {
+		b := p.img.Bounds()
+		bitmapfont.Draw(dst, p.label, image.Pt(x, margin), color.White, 2)
+		r := image.Rect(x, margin+labelH, x+b.Dx(), margin+labelH+b.Dy())
+		draw.Draw(dst, r, checkerboard{}, r.Min, draw.Src)
+		draw.Draw(dst, r, p.img, b.Min, draw.Over)
+		x += b.Dx() + margin
+	}
+	return dst
+}
+
+// pixelDiff returns an image that covers both a and b, where changed pixels are
+// highlighted over a faded version of b, and the number of changed pixels.
+// Pixels that are only in one of the images are considered changed.
+func pixelDiff(a, b image.Image) (image.Image, int) {
+	ab, bb := a.Bounds(), b.Bounds()
+	w, h := ab.Dx(), ab.Dy()
+	if bb.Dx() > w {
+		w = bb.Dx()
+	}
+	if bb.Dy() > h {
+		h = bb.Dy()
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	n := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pa, pb := image.Pt(ab.Min.X+x, ab.Min.Y+y), image.Pt(bb.Min.X+x, bb.Min.Y+y)
+			inA, inB := pa.In(ab), pb.In(bb)
+			if inA && inB && equal(a.At(pa.X, pa.Y), b.At(pb.X, pb.Y)) {
+				g := color.GrayModel.Convert(b.At(pb.X, pb.Y)).(color.Gray).Y
+				g = 0x60 + g/4
+				dst.SetRGBA(x, y, color.RGBA{g, g, g, 0xff})
+				continue
+			}
+			dst.SetRGBA(x, y, changed)
+			n++
+		}
+	}
+	return dst, n
+}
+
+func equal(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// checkerboard is drawn behind images so transparency is visible.
+type checkerboard struct{}
+
+func (checkerboard) ColorModel() color.Model { return color.RGBAModel }
+func (checkerboard) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+func (checkerboard) At(x, y int) color.Color {
+	if (x/8+y/8)%2 == 0 {
+		return color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	}
+	return color.RGBA{0x99, 0x99, 0x99, 0xff}
+}
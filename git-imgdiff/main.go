@@ -0,0 +1,192 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// git-imgdiff shows the differences between two versions of an image in the
+// terminal: the old and new images next to each other, followed by the pixels
+// that changed. Files that are not images are described by their size and hash.
+//
+// It can be used as a git difftool, as an external diff driver, or to convert
+// images to text in diffs. Run git-imgdiff -config to learn how to set it up.
+//
+// With -wipe, it compares two images interactively instead: old on the left
+// and new on the right of a line moved with the arrow keys.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	// Register the formats we can compare.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+var (
+	textconv = flag.Bool("textconv", false, "describe the given image as text, to be used as a textconv filter")
+	config   = flag.Bool("config", false, "print instructions to integrate with git")
+	wipeFlag = flag.Bool("wipe", false, "compare two images interactively, moving a line between them with the arrow keys")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the comparison displayed, with the diff written to the terminal instead")
+)
+
+const instructions = `To use git-imgdiff as a difftool:
+
+	git config --global difftool.imgdiff.cmd 'git-imgdiff "$LOCAL" "$REMOTE"'
+	git difftool -t imgdiff HEAD~1 -- '*.png'
+
+To use it for every git diff of image files, add a diff driver:
+
+	git config --global diff.image.command git-imgdiff
+	echo '*.png diff=image' >> .gitattributes
+	echo '*.jpg diff=image' >> .gitattributes
+
+To only get a text description of image changes, for instance in git log -p,
+use it as a textconv filter instead of a command:
+
+	git config --global diff.image.textconv 'git-imgdiff -textconv'
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [-json] old new\n\t%s [-json] path old old-hex old-mode new new-hex new-mode\n\t%s -textconv file\n\t%s -wipe old new\n\t%s -config\n",
+			os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *jsonFlag && (*config || *wipeFlag || *textconv) {
+		log.Fatal("-json describes the comparisons of diffs, it doesn't apply to -config, -wipe, or -textconv")
+	}
+
+	switch args := flag.Args(); {
+	case *config:
+		fmt.Print(instructions)
+	case *wipeFlag && len(args) == 2:
+		if err := wipe(args[0], args[1]); err != nil {
+			log.Fatal(err)
+		}
+	case *textconv && len(args) == 1:
+		f, err := load(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(f.describe())
+	case len(args) == 2:
+		// git difftool: old new
+		if err := diff(args[1], args[0], args[1]); err != nil {
+			log.Fatal(err)
+		}
+	case len(args) == 7:
+		// git external diff: path old old-hex old-mode new new-hex new-mode
+		if err := diff(args[0], args[1], args[4]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// A file being compared.
+type file struct {
+	path string
+	data []byte
+	// img and format are set if the file is a decodable image.
+	img    image.Image
+	format string
+}
+
+// load reads the file at path. /dev/null, used by git for missing files, is
+// loaded as an empty file.
+func load(path string) (*file, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	f := &file{path: path, data: data}
+	if img, format, err := image.Decode(bytes.NewReader(data)); err == nil {
+		f.img, f.format = img, format
+	}
+	return f, nil
+}
+
+func (f *file) describe() string {
+	if len(f.data) == 0 {
+		return "empty"
+	}
+	if f.img == nil {
+		return fmt.Sprintf("%d bytes, sha256 %x", len(f.data), sha256.Sum256(f.data))
+	}
+	b := f.img.Bounds()
+	return fmt.Sprintf("%s image, %dx%d, %d bytes", f.format, b.Dx(), b.Dy(), len(f.data))
+}
+
+// diff shows the differences between the old and new versions of name.
+func diff(name, oldPath, newPath string) error {
+	old, err := load(oldPath)
+	if err != nil {
+		return err
+	}
+	cur, err := load(newPath)
+	if err != nil {
+		return err
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name(name), imgcat.PaneWidth(100)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	fmt.Fprintf(out, "%s\n  old: %s\n  new: %s\n", name, old.describe(), cur.describe())
+	if bytes.Equal(old.data, cur.data) {
+		fmt.Fprintln(out, "  no changes")
+		return nil
+	}
+	if old.img == nil && cur.img == nil {
+		return nil
+	}
+
+	var panels []panel
+	if old.img != nil {
+		panels = append(panels, panel{"old", old.img})
+	}
+	if cur.img != nil {
+		panels = append(panels, panel{"new", cur.img})
+	}
+	if old.img != nil && cur.img != nil {
+		d, n := pixelDiff(old.img, cur.img)
+		b := d.Bounds()
+		fmt.Fprintf(out, "  %d of %d pixels differ (%.2f%%)\n", n, b.Dx()*b.Dy(), 100*float64(n)/float64(b.Dx()*b.Dy()))
+		panels = append(panels, panel{"diff", d})
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, sideBySide(panels)); err != nil {
+		return errors.Wrap(err, "could not encode comparison")
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(buf)
+}
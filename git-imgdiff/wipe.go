@@ -0,0 +1,145 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/termquery"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+const (
+	// wipeStep is how much the wipe moves per key press, as a fraction of the
+	// width of the images.
+	wipeStep = 0.05
+	// wipeMaxSize is the largest side of the frames sent, larger images are
+	// scaled down so frames are sent quickly.
+	wipeMaxSize = 1280
+)
+
+// wipe compares the old and new images interactively, showing old on the left
+// and new on the right of a vertical line moved with the arrow keys.
+func wipe(oldPath, newPath string) error {
+	old, err := load(oldPath)
+	if err != nil {
+		return err
+	}
+	cur, err := load(newPath)
+	if err != nil {
+		return err
+	}
+	if old.img == nil || cur.img == nil {
+		return errors.New("both files must be images to compare them with -wipe")
+	}
+	a, b := wipeImages(old.img, cur.img)
+
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	rows := size.Rows - 1
+	if rows < 1 {
+		rows = 1
+	}
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true), imgcat.Width(imgcat.Cells(size.Cols)),
+		imgcat.Height(imgcat.Cells(rows)), imgcat.PreserveAspectRatio(true), imgcat.Newline(false))
+	if err != nil {
+		return err
+	}
+
+	// Use the alternate screen, without cursor.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	t := 0.5
+	for {
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, wipeFrame(a, b, t), &jpeg.Options{Quality: 90}); err != nil {
+			return errors.Wrap(err, "could not encode comparison")
+		}
+		fmt.Print("\x1b[H")
+		if err := enc.Encode(buf); err != nil {
+			return err
+		}
+		fmt.Printf("\x1b[%d;1H\x1b[2K← → move the wipe, q quits", size.Rows)
+
+		for redraw := false; !redraw; {
+			key, err := termquery.ReadKey()
+			if err != nil {
+				return err
+			}
+			switch key {
+			case termquery.KeyLeft, "h":
+				t, redraw = math.Max(0, t-wipeStep), true
+			case termquery.KeyRight, "l":
+				t, redraw = math.Min(1, t+wipeStep), true
+			case "q", termquery.KeyEscape, termquery.KeyInterrupt:
+				return nil
+			}
+		}
+	}
+}
+
+// wipeImages returns a and b scaled to the same size, covering both.
+func wipeImages(a, b image.Image) (image.Image, image.Image) {
+	ab, bb := a.Bounds(), b.Bounds()
+	w, h := math.Max(float64(ab.Dx()), float64(bb.Dx())), math.Max(float64(ab.Dy()), float64(bb.Dy()))
+	if scale := wipeMaxSize / math.Max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	fit := func(img image.Image) image.Image {
+		return imgcat.Letterbox(img, int(math.Max(1, w)), int(math.Max(1, h)), background)
+	}
+	return fit(a), fit(b)
+}
+
+// wipeFrame returns old on the left of a vertical line at a fraction t of the
+// width and cur on its right, with their labels.
+func wipeFrame(old, cur image.Image, t float64) image.Image {
+	frame := imgcat.Wipe(cur, old, t)
+	dst, ok := frame.(draw.Image)
+	if !ok {
+		dst = image.NewRGBA(frame.Bounds())
+		draw.Draw(dst, dst.Bounds(), frame, frame.Bounds().Min, draw.Src)
+	}
+	r := dst.Bounds()
+	x := r.Min.X + int(math.Round(t*float64(r.Dx())))
+	draw.Draw(dst, image.Rect(x-1, r.Min.Y, x+1, r.Max.Y).Intersect(r), image.NewUniform(changed), image.Point{}, draw.Src)
+
+	const margin = 4
+	label := func(s string, left bool) {
+		size := bitmapfont.Measure(s, 2)
+		at := image.Pt(r.Min.X+margin, r.Min.Y+margin)
+		if !left {
+			at.X = r.Max.X - margin - size.X
+		}
+		box := image.Rectangle{at, at.Add(size)}.Inset(-margin)
+		draw.Draw(dst, box, image.NewUniform(background), image.Point{}, draw.Src)
+		bitmapfont.Draw(dst, s, at, color.White, 2)
+	}
+	label("old", true)
+	label("new", false)
+	return dst
+}
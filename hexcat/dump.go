@@ -0,0 +1,70 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// dumpWidth is the number of columns of each line written by hexdump.
+const dumpWidth = 78
+
+// hexdump writes data to w in the format of hexdump -C, with 16 bytes per line
+// preceded by their offset and followed by their printable characters. Only n
+// bytes starting at offset are dumped, or all of them if n is zero.
+// It returns the number of lines written.
+func hexdump(w io.Writer, data []byte, offset, n int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	end := int64(len(data))
+	if n > 0 && offset+n < end {
+		end = offset + n
+	}
+
+	lines := 0
+	for off := offset; off < end; off += 16 {
+		row := data[off:min(off+16, end)]
+		fmt.Fprintf(w, "%08x  ", off)
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				fmt.Fprint(w, " ")
+			}
+			if i < len(row) {
+				fmt.Fprintf(w, "%02x ", row[i])
+			} else {
+				fmt.Fprint(w, "   ")
+			}
+		}
+		fmt.Fprint(w, " |")
+		for _, b := range row {
+			if b < ' ' || b > '~' {
+				b = '.'
+			}
+			fmt.Fprintf(w, "%c", b)
+		}
+		fmt.Fprintln(w, "|")
+		lines++
+	}
+	fmt.Fprintf(w, "%08x\n", end)
+	return lines + 1
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
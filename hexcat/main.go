@@ -0,0 +1,203 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// hexcat hexdumps files like hexdump -C and, when a file is an image, shows
+// its decoded preview beside the dump, or below it when there's no room. This
+// is handy to debug corrupt or truncated image files.
+//
+// Usage:
+//
+//	hexcat [flags] [file]*
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+var (
+	skip      = flag.Int64("s", 0, "offset of the first byte to dump")
+	length    = flag.Int64("n", 0, "maximum number of bytes to dump, zero means all")
+	below     = flag.Bool("below", false, "always show the preview below the dump")
+	noPreview = flag.Bool("no-preview", false, "don't show image previews")
+	jsonFlag  = flag.Bool("json", false, "print a line of JSON describing each preview displayed, with the dumps written to the terminal instead")
+)
+
+var (
+	// out is where the dumps and previews are written.
+	out io.Writer = os.Stdout
+	// logJSON describes the previews displayed with -json, nil otherwise.
+	logJSON imgcat.Option
+)
+
+// Sizes in character cells used to place the preview beside the dump.
+const (
+	gap        = 2  // columns between the dump and the preview.
+	minPreview = 16 // narrowest preview shown beside the dump.
+	maxPreview = 40 // widest preview, beside or below the dump.
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] [file]*\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *jsonFlag {
+		out, logJSON = imgcat.JSONMode()
+	}
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	for i, path := range paths {
+		if len(paths) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "%s:\n", path)
+		}
+		if err := hexcat(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func hexcat(path string) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+
+	dump := new(bytes.Buffer)
+	lines := hexdump(dump, data, *skip, *length)
+
+	format := imgcat.Sniff(data)
+	if *noPreview || format == "" || !imgcat.IsSupported() {
+		_, err := out.Write(dump.Bytes())
+		return err
+	}
+	options := []imgcat.Option{imgcat.Inline(true)}
+	if path != "-" {
+		options = append(options, imgcat.Name(filepath.Base(path)))
+	}
+	if logJSON != nil {
+		options = append(options, logJSON)
+	}
+
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	width := size.Cols - dumpWidth - gap
+	if width > maxPreview {
+		width = maxPreview
+	}
+	if *below || width < minPreview || lines >= size.Rows-1 {
+		return previewBelow(dump.Bytes(), data, format, size.Cols, options)
+	}
+	return previewBeside(dump.Bytes(), lines, data, format, width, size.Rows-2, options)
+}
+
+// describe returns what the image package makes of data, which is often more
+// useful than the preview itself when a file is corrupt.
+func describe(data []byte, format imgcat.Format) string {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Sprintf("%s: %v", format, err)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Sprintf("%s %dx%d: %v", format, cfg.Width, cfg.Height, err)
+	}
+	return fmt.Sprintf("%s %dx%d", format, cfg.Width, cfg.Height)
+}
+
+// previewBelow writes the dump followed by the description and preview of the
+// image, sent with the given options.
+func previewBelow(dump, data []byte, format imgcat.Format, cols int, options []imgcat.Option) error {
+	if _, err := out.Write(dump); err != nil {
+		return err
+	}
+	fmt.Fprintln(out, describe(data, format))
+	if cols > maxPreview {
+		cols = maxPreview
+	}
+	enc, err := imgcat.NewEncoder(out, append(options, imgcat.Width(imgcat.Cells(cols)))...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(bytes.NewReader(data))
+}
+
+// previewBeside writes the dump, then goes back to its first line to draw the
+// description and preview of the image on its right, and finally restores the
+// cursor below the dump. The dump must fit in the terminal, since lines that
+// scrolled out of the screen can't be reached anymore.
+func previewBeside(dump []byte, lines int, data []byte, format imgcat.Format, width, maxRows int, options []imgcat.Option) error {
+	if _, err := out.Write(dump); err != nil {
+		return err
+	}
+	// For short dumps, the preview grows the output so it isn't squashed.
+	// Cells are about twice as tall as they're wide.
+	rows := width / 2
+	if rows > maxRows {
+		rows = maxRows
+	}
+	if rows < lines {
+		rows = lines
+	}
+	if extra := rows + 1 - lines; extra > 0 {
+		fmt.Fprint(out, strings.Repeat("\n", extra))
+		lines += extra
+	}
+
+	col := dumpWidth + gap + 1
+	desc := describe(data, format)
+	if len(desc) > width {
+		desc = desc[:width]
+	}
+	// Save the cursor, move up and right, and write the description.
+	fmt.Fprintf(out, "\x1b7\x1b[%dA\x1b[%dG%s\x1b[1E\x1b[%dG", lines, col, desc, col)
+	enc, err := imgcat.NewEncoder(out, append(options, imgcat.Newline(false),
+		imgcat.Width(imgcat.Cells(width)), imgcat.Height(imgcat.Cells(rows-1)))...)
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	fmt.Fprint(out, "\x1b8")
+	return nil
+}
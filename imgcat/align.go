@@ -0,0 +1,91 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+)
+
+// An Alignment is where images are placed across the terminal, see Align.
+type Alignment int
+
+// Alignments.
+const (
+	// Left draws images where the cursor is. It's the default.
+	Left Alignment = iota
+	// Center draws images in the middle of the terminal.
+	Center
+	// Right draws images against the right edge of the terminal.
+	Right
+)
+
+// Align places images across the terminal, or the tmux pane, by moving the
+// cursor right before drawing them, so charts and photos can be centered
+// without counting columns. Images are expected to start at the beginning of
+// a line. As with MaxRows, the columns of images sent to the terminal are
+// estimated assuming cells of 8 by 16 pixels, and payloads that are not in a
+// known image format are not moved. Images drawn with ASCII are moved with
+// spaces, and the ones of custom renderers are not moved.
+func Align(a Alignment) Option {
+	return func(c *config) { c.align = a }
+}
+
+// place sets the height and the indent of the image read from r, given with
+// MaxRows and Align, and returns a reader for the payload.
+func (c *config) place(r io.Reader) io.Reader {
+	c.indent = 0
+	if !c.limitRows && c.align == Left {
+		return r
+	}
+	buf := new(bytes.Buffer)
+	ic, _, err := image.DecodeConfig(io.TeeReader(r, buf))
+	r = io.MultiReader(buf, r)
+	if err != nil {
+		return r
+	}
+	size := image.Pt(ic.Width, ic.Height)
+	c.fitRows(size)
+	cols, rows := c.cells()
+	cols, _ = fitCells(size, cellWidth, cellHeight, cols, rows, terminalCols(), c.preserveAspectRatio())
+	c.indent = c.indentFor(cols)
+	return r
+}
+
+// indentFor returns how many columns an image cols wide is moved right by to
+// be placed as given with Align.
+func (c *config) indentFor(cols int) int {
+	free := terminalCols() - cols
+	switch c.align {
+	case Center:
+		free /= 2
+	case Right:
+	default:
+		return 0
+	}
+	if free < 0 {
+		return 0
+	}
+	return free
+}
+
+// cursorRight returns the escape sequence moving the cursor n columns right.
+func cursorRight(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[%dC", n)
+}
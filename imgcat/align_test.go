@@ -0,0 +1,67 @@
+package imgcat
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	defer func(old func() int) { terminalCols = old }(terminalCols)
+	terminalCols = func() int { return 80 }
+
+	tc := []struct {
+		name    string
+		data    []byte
+		options []Option
+		prefix  string
+	}{
+		{"left", testPNG(t, 80, 16, color.White), []Option{Align(Left)}, ""},
+		{"center", testPNG(t, 80, 16, color.White), []Option{Align(Center)}, "\x1b[35C"},
+		{"right", testPNG(t, 80, 16, color.White), []Option{Align(Right)}, "\x1b[70C"},
+		{"width", testPNG(t, 80, 16, color.White), []Option{Width(Cells(40)), Align(Center)}, "\x1b[20C"},
+		{"too wide", testPNG(t, 80, 16, color.White), []Option{Width(Cells(100)), Align(Center)}, ""},
+		{"wider than the terminal", testPNG(t, 2000, 16, color.White), []Option{Align(Right)}, ""},
+		{"max rows", testPNG(t, 80, 80, color.White), []Option{Width(Cells(40)), MaxRows(5), Align(Center)}, "\x1b[35C"},
+		{"not an image", []byte("test"), []Option{Align(Center)}, ""},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := EncodeToBytes(bytes.NewReader(c.data), append(c.options, Passthrough(false))...)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			s := string(out)
+			if got := s[:strings.Index(s, "\x1b]")]; got != c.prefix {
+				t.Errorf("expected the image to be moved with %q; got %q", c.prefix, got)
+			}
+		})
+	}
+}
+
+func TestAlignText(t *testing.T) {
+	defer func(old func() int) { terminalCols = old }(terminalCols)
+	terminalCols = func() int { return 80 }
+
+	tc := []struct {
+		protocol Protocol
+		shift    string
+	}{
+		{HalfBlock, "\x1b[38C"},
+		{ASCII, strings.Repeat(" ", 38)},
+	}
+	for _, c := range tc {
+		buf := new(bytes.Buffer)
+		enc := &Encoder{out: buf, options: []Option{Align(Center)}, protocol: c.protocol}
+		if err := enc.Encode(bytes.NewReader(testPNG(t, 4, 4, color.White))); err != nil {
+			t.Fatalf("could not encode with %s: %v", c.protocol, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		for i, line := range lines {
+			if !strings.HasPrefix(line, c.shift) || strings.HasPrefix(line, c.shift+" ") {
+				t.Errorf("expected line %d drawn with %s to start with %q; got %q", i, c.protocol, c.shift, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// An Annotation is drawn over the image before it's displayed, see Annotate.
+type Annotation interface {
+	// Draw draws the annotation on dst. Its bounds start at the origin and
+	// match the size of the annotated image.
+	Draw(dst draw.Image)
+}
+
+// Annotate draws the given annotations, in order, over the image.
+// Coordinates are relative to the top left corner of the image.
+// Lines and text are scaled with the image so they stay visible on large ones.
+func Annotate(annotations ...Annotation) Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		if len(annotations) == 0 {
+			return img, nil
+		}
+		b := img.Bounds()
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+		for _, a := range annotations {
+			a.Draw(dst)
+		}
+		return dst, nil
+	})
+}
+
+// Box returns an Annotation drawing the outline of r in color c, such as the
+// bounding box of a detected object. If label is not empty, it's written over
+// the top left corner of the box.
+func Box(r image.Rectangle, c color.Color, label string) Annotation {
+	return box{r.Canon(), c, label}
+}
+
+type box struct {
+	r     image.Rectangle
+	c     color.Color
+	label string
+}
+
+func (b box) Draw(dst draw.Image) {
+	t := thickness(dst.Bounds())
+	r := b.r
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t), b.c)
+	fill(dst, image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y), b.c)
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Min.X+t, r.Max.Y), b.c)
+	fill(dst, image.Rect(r.Max.X-t, r.Min.Y, r.Max.X, r.Max.Y), b.c)
+	if b.label == "" {
+		return
+	}
+	// Place the label above the box, or inside it if there's no room.
+	size := labelSize(b.label, t)
+	p := image.Pt(r.Min.X, r.Min.Y-size.Y)
+	if p.Y < dst.Bounds().Min.Y {
+		p.Y = r.Min.Y
+	}
+	drawLabel(dst, b.label, p, ContrastColor(b.c), b.c, t)
+}
+
+// Point returns an Annotation marking p, such as a keypoint, with a dot of
+// color c. If label is not empty, it's written next to the dot.
+func Point(p image.Point, c color.Color, label string) Annotation {
+	return point{p, c, label}
+}
+
+type point struct {
+	p     image.Point
+	c     color.Color
+	label string
+}
+
+func (p point) Draw(dst draw.Image) {
+	t := thickness(dst.Bounds())
+	radius := 2*t + 1
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				dst.Set(p.p.X+x, p.p.Y+y, p.c)
+			}
+		}
+	}
+	if p.label != "" {
+		drawLabel(dst, p.label, p.p.Add(image.Pt(radius+t, -radius)), ContrastColor(p.c), p.c, t)
+	}
+}
+
+// Label returns an Annotation writing text in color c with its top left corner
+// at p. The text is written over a box of a contrasting color so it's readable
+// regardless of the image.
+func Label(p image.Point, text string, c color.Color) Annotation {
+	return label{p, text, c}
+}
+
+type label struct {
+	p    image.Point
+	text string
+	c    color.Color
+}
+
+func (l label) Draw(dst draw.Image) {
+	drawLabel(dst, l.text, l.p, l.c, ContrastColor(l.c), thickness(dst.Bounds()))
+}
+
+// Crosshair returns an Annotation drawing a horizontal and a vertical line of
+// color c through p, across the whole image, leaving p itself visible.
+func Crosshair(p image.Point, c color.Color) Annotation {
+	return crosshair{p, c}
+}
+
+type crosshair struct {
+	p image.Point
+	c color.Color
+}
+
+func (h crosshair) Draw(dst draw.Image) {
+	b := dst.Bounds()
+	t := thickness(b)
+	// gap is the distance from p to the ends of the lines.
+	gap := 2*t + 1
+	x0, y0 := h.p.X-t/2, h.p.Y-t/2
+	fill(dst, image.Rect(b.Min.X, y0, h.p.X-gap, y0+t), h.c)
+	fill(dst, image.Rect(h.p.X+gap+1, y0, b.Max.X, y0+t), h.c)
+	fill(dst, image.Rect(x0, b.Min.Y, x0+t, h.p.Y-gap), h.c)
+	fill(dst, image.Rect(x0, h.p.Y+gap+1, x0+t, b.Max.Y), h.c)
+}
+
+// labelSize returns the size of a label drawn at the given scale, with margins.
+func labelSize(text string, scale int) image.Point {
+	return bitmapfont.Measure(text, scale).Add(image.Pt(2*scale, 2*scale))
+}
+
+func drawLabel(dst draw.Image, text string, p image.Point, fg, bg color.Color, scale int) {
+	fill(dst, image.Rectangle{p, p.Add(labelSize(text, scale))}, bg)
+	bitmapfont.Draw(dst, text, p.Add(image.Pt(scale, scale)), fg, scale)
+}
+
+// thickness returns the width of lines, and scale of text, for annotations
+// drawn on an image with the given bounds.
+func thickness(b image.Rectangle) int {
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	if t := side / 250; t > 1 {
+		return t
+	}
+	return 1
+}
+
+func fill(dst draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(dst, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
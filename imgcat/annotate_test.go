@@ -0,0 +1,55 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAnnotate(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	in := testPNG(t, 40, 40, color.White)
+
+	tc := []struct {
+		name       string
+		annotation Annotation
+		set, unset []image.Point
+	}{
+		{"box", Box(image.Rect(30, 30, 10, 10), red, ""),
+			[]image.Point{{10, 10}, {29, 29}, {10, 20}, {20, 10}},
+			[]image.Point{{20, 20}, {9, 9}, {30, 30}}},
+		{"box with label", Box(image.Rect(10, 20, 30, 30), red, "A"),
+			[]image.Point{{10, 11}, {10, 20}},
+			[]image.Point{{10, 10}, {20, 25}}},
+		{"point", Point(image.Pt(20, 20), red, ""),
+			[]image.Point{{20, 20}, {17, 20}, {20, 23}},
+			[]image.Point{{17, 17}, {25, 20}}},
+		{"crosshair", Crosshair(image.Pt(20, 10), red),
+			[]image.Point{{0, 10}, {39, 10}, {20, 0}, {20, 39}, {16, 10}, {20, 14}},
+			[]image.Point{{20, 10}, {17, 10}, {20, 13}, {19, 20}, {10, 11}}},
+		{"label", Label(image.Pt(5, 5), "|", red),
+			[]image.Point{{8, 6}, {8, 12}},
+			[]image.Point{{7, 6}, {8, 5}}},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newConfig([]Option{Annotate(tt.annotation)}).prepare(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			img := decode(t, r)
+			for _, p := range tt.set {
+				if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got != red {
+					t.Errorf("expected %v at %v; got %v", red, p, got)
+				}
+			}
+			for _, p := range tt.unset {
+				if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got == red {
+					t.Errorf("unexpected %v at %v", red, p)
+				}
+			}
+		})
+	}
+}
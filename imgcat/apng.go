@@ -0,0 +1,244 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"io"
+	"time"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// maxDelay is the longest delay of a frame, in milliseconds, as stored in
+// fcTL chunks.
+const maxDelay = 1<<16 - 1
+
+// FramesToAPNG writes frames to w as an animated PNG, each frame shown for
+// delay, looping forever. iTerm2 plays APNG images natively, so an animation
+// can be sent as a single payload rather than redrawn frame by frame.
+//
+// The first frame sets the size of the animation. The following frames can
+// be smaller, and are then drawn at their position relative to the first one,
+// which is useful to only send the part of the image that changed.
+func FramesToAPNG(w io.Writer, frames []image.Image, delay time.Duration) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+	canvas := frames[0].Bounds()
+	if canvas.Empty() {
+		return fmt.Errorf("invalid image size %dx%d", canvas.Dx(), canvas.Dy())
+	}
+	ms := delay.Nanoseconds() / int64(time.Millisecond)
+	if ms < 0 {
+		ms = 0
+	}
+	if ms > maxDelay {
+		ms = maxDelay
+	}
+
+	aw := &apngWriter{w: bufio.NewWriter(w)}
+	aw.write([]byte(pngSignature))
+	aw.chunk("IHDR", be32(uint32(canvas.Dx())), be32(uint32(canvas.Dy())),
+		// 8 bits per sample, RGBA, default compression, filter, and no
+		// interlacing.
+		[]byte{8, 6, 0, 0, 0})
+	aw.chunk("acTL", be32(uint32(len(frames))), be32(0))
+	for i, frame := range frames {
+		b := frame.Bounds()
+		if b.Empty() || !b.In(canvas) {
+			return fmt.Errorf("frame %d at %v is not within the first frame at %v", i, b, canvas)
+		}
+		off := b.Min.Sub(canvas.Min)
+		aw.chunk("fcTL", be32(aw.seq), be32(uint32(b.Dx())), be32(uint32(b.Dy())),
+			be32(uint32(off.X)), be32(uint32(off.Y)),
+			be16(uint16(ms)), be16(1000),
+			// Leave the frame as it is when the next one is drawn, and
+			// replace the pixels of the canvas rather than blending them.
+			[]byte{0, 0})
+		aw.seq++
+
+		data, err := frameData(frame)
+		if err != nil {
+			return fmt.Errorf("could not encode frame %d: %v", i, err)
+		}
+		if i == 0 {
+			aw.chunk("IDAT", data)
+		} else {
+			aw.chunk("fdAT", be32(aw.seq), data)
+			aw.seq++
+		}
+	}
+	aw.chunk("IEND")
+	if aw.err != nil {
+		return aw.err
+	}
+	return aw.w.Flush()
+}
+
+// EncodeFrames displays frames as an animation, see FramesToAPNG.
+func (enc *Encoder) EncodeFrames(frames []image.Image, delay time.Duration) error {
+	buf := new(bytes.Buffer)
+	if err := FramesToAPNG(buf, frames, delay); err != nil {
+		return err
+	}
+	return enc.Encode(buf)
+}
+
+// apngWriter writes PNG chunks, keeping the first error and the sequence
+// number of the animation chunks.
+type apngWriter struct {
+	w   *bufio.Writer
+	seq uint32
+	err error
+}
+
+func (aw *apngWriter) write(parts ...[]byte) {
+	for _, p := range parts {
+		if aw.err == nil {
+			_, aw.err = aw.w.Write(p)
+		}
+	}
+}
+
+// chunk writes a chunk of the given type, whose data is the concatenation of
+// parts.
+func (aw *apngWriter) chunk(typ string, parts ...[]byte) {
+	n := 0
+	for _, p := range parts {
+		n += len(p)
+	}
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	for _, p := range parts {
+		crc.Write(p)
+	}
+	aw.write(be32(uint32(n)), []byte(typ))
+	aw.write(parts...)
+	aw.write(be32(crc.Sum32()))
+}
+
+// frameData returns the zlib compressed scanlines of img as 8-bit RGBA, each
+// with the filter that gives the smallest sum of absolute differences, as
+// image/png does.
+func frameData(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	rgba, ok := img.(*image.NRGBA)
+	if !ok {
+		rgba = image.NewNRGBA(b)
+		draw.Draw(rgba, b, img, b.Min, draw.Src)
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zlib.NewWriter(buf)
+	n := 4 * b.Dx()
+	prev := make([]byte, n)
+	var filtered [5][]byte
+	for f := range filtered {
+		filtered[f] = make([]byte, 1+n)
+		filtered[f][0] = byte(f)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := rgba.PixOffset(b.Min.X, y)
+		cur := rgba.Pix[i : i+n]
+		best, bestSum := 0, -1
+		for f := range filtered {
+			sum := pngFilter(filtered[f][1:], cur, prev, f)
+			if bestSum < 0 || sum < bestSum {
+				best, bestSum = f, sum
+			}
+		}
+		if _, err := zw.Write(filtered[best]); err != nil {
+			return nil, err
+		}
+		prev = cur
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pngFilter writes to dst the row cur filtered with the given PNG filter type,
+// given the previous row prev, and returns the sum of the absolute values of
+// the result as signed bytes.
+func pngFilter(dst, cur, prev []byte, typ int) int {
+	const bpp = 4
+	sum := 0
+	for i := range cur {
+		var a, b, c byte
+		if i >= bpp {
+			a, c = cur[i-bpp], prev[i-bpp]
+		}
+		b = prev[i]
+		var p byte
+		switch typ {
+		case 1:
+			p = a
+		case 2:
+			p = b
+		case 3:
+			p = byte((int(a) + int(b)) / 2)
+		case 4:
+			p = paeth(a, b, c)
+		}
+		d := cur[i] - p
+		dst[i] = d
+		if d < 0x80 {
+			sum += int(d)
+		} else {
+			sum += 0x100 - int(d)
+		}
+	}
+	return sum
+}
+
+// paeth is the predictor of the Paeth filter.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func be32(x uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, x)
+	return b
+}
+
+func be16(x uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, x)
+	return b
+}
@@ -0,0 +1,153 @@
+package imgcat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+func chunks(t *testing.T, b []byte) []pngChunk {
+	if !bytes.HasPrefix(b, []byte(pngSignature)) {
+		t.Fatalf("expected PNG signature")
+	}
+	b = b[len(pngSignature):]
+	var cs []pngChunk
+	for len(b) > 0 {
+		if len(b) < 12 {
+			t.Fatalf("truncated chunk")
+		}
+		n := binary.BigEndian.Uint32(b)
+		cs = append(cs, pngChunk{string(b[4:8]), b[8 : 8+n]})
+		b = b[12+n:]
+	}
+	return cs
+}
+
+// framePNG returns a PNG holding only the pixels of a frame.
+func framePNG(w, h uint32, data []byte) []byte {
+	buf := new(bytes.Buffer)
+	aw := &apngWriter{w: bufio.NewWriter(buf)}
+	aw.write([]byte(pngSignature))
+	aw.chunk("IHDR", be32(w), be32(h), []byte{8, 6, 0, 0, 0})
+	aw.chunk("IDAT", data)
+	aw.chunk("IEND")
+	aw.w.Flush()
+	return buf.Bytes()
+}
+
+func TestFramesToAPNG(t *testing.T) {
+	red, blue := color.NRGBA{0xff, 0, 0, 0xff}, color.NRGBA{0, 0, 0xff, 0x80}
+	fill := func(c color.Color) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		return img
+	}
+	frames := []image.Image{
+		fill(red),
+		fill(blue),
+		// A smaller frame, drawn at 4,2 over the previous one.
+		image.NewNRGBA(image.Rect(4, 2, 8, 6)),
+	}
+	buf := new(bytes.Buffer)
+	if err := FramesToAPNG(buf, frames, 250*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	// Decoders that don't know APNG show the first frame.
+	img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 16, 8) || img.At(3, 3) != color.Color(red) {
+		t.Fatalf("expected red 16x8 first frame; got %v at %v", img.At(3, 3), img.Bounds())
+	}
+
+	var types []string
+	var seq uint32
+	var controls [][]byte
+	var data [][]byte
+	for _, c := range chunks(t, buf.Bytes()) {
+		types = append(types, c.typ)
+		switch c.typ {
+		case "acTL":
+			if n := binary.BigEndian.Uint32(c.data); n != 3 {
+				t.Errorf("expected 3 frames; got %d", n)
+			}
+		case "fcTL", "fdAT":
+			if got := binary.BigEndian.Uint32(c.data); got != seq {
+				t.Errorf("expected sequence number %d; got %d", seq, got)
+			}
+			seq++
+			if c.typ == "fcTL" {
+				controls = append(controls, c.data)
+			} else {
+				data = append(data, c.data[4:])
+			}
+		}
+	}
+	want := "IHDR acTL fcTL IDAT fcTL fdAT fcTL fdAT IEND"
+	if got := strings.Join(types, " "); got != want {
+		t.Fatalf("expected chunks %s; got %s", want, got)
+	}
+
+	tc := []struct {
+		w, h, x, y uint32
+		c          color.Color
+	}{
+		{16, 8, 0, 0, blue},
+		{4, 4, 4, 2, color.NRGBA{}},
+	}
+	for i, tt := range tc {
+		fc := controls[i+1]
+		w, h := binary.BigEndian.Uint32(fc[4:]), binary.BigEndian.Uint32(fc[8:])
+		x, y := binary.BigEndian.Uint32(fc[12:]), binary.BigEndian.Uint32(fc[16:])
+		if w != tt.w || h != tt.h || x != tt.x || y != tt.y {
+			t.Errorf("frame %d: expected %dx%d at %d,%d; got %dx%d at %d,%d", i+1, tt.w, tt.h, tt.x, tt.y, w, h, x, y)
+		}
+		if num, den := binary.BigEndian.Uint16(fc[20:]), binary.BigEndian.Uint16(fc[22:]); num != 250 || den != 1000 {
+			t.Errorf("frame %d: expected delay of 250/1000; got %d/%d", i+1, num, den)
+		}
+		img, err := png.Decode(bytes.NewReader(framePNG(w, h, data[i])))
+		if err != nil {
+			t.Fatalf("frame %d: could not decode: %v", i+1, err)
+		}
+		if got := color.NRGBAModel.Convert(img.At(1, 1)); got != tt.c {
+			t.Errorf("frame %d: expected %v; got %v", i+1, tt.c, got)
+		}
+	}
+}
+
+func TestFramesToAPNGErrors(t *testing.T) {
+	tc := []struct {
+		name   string
+		frames []image.Image
+	}{
+		{"no frames", nil},
+		{"empty first frame", []image.Image{image.NewNRGBA(image.Rect(0, 0, 0, 0))}},
+		{"larger frame", []image.Image{uniform(4, 4, color.White), uniform(8, 4, color.White)}},
+		{"frame outside", []image.Image{uniform(4, 4, color.White), image.NewNRGBA(image.Rect(2, 2, 6, 6))}},
+	}
+	for _, tt := range tc {
+		if err := FramesToAPNG(new(bytes.Buffer), tt.frames, time.Second); err == nil {
+			t.Errorf("%s: expected error; got nothing", tt.name)
+		}
+	}
+}
+
+func TestFramesToAPNGBadWriter(t *testing.T) {
+	if err := FramesToAPNG(badWriter{}, []image.Image{uniform(4, 4, color.White)}, time.Second); err == nil {
+		t.Errorf("expected error; got nothing")
+	}
+}
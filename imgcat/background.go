@@ -0,0 +1,97 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/campoy/tools/imgcat/termquery"
+)
+
+// queryTimeout is how long to wait for the terminal to answer a query.
+const queryTimeout = termquery.DefaultTimeout
+
+// Can be swapped for testing.
+var queryTerminal = termquery.Query
+
+// Background queries the terminal for its background color with OSC 11.
+// It returns an error if the terminal doesn't answer in time, which is the case
+// for many terminals that don't support the query.
+func Background() (color.Color, error) {
+	reply, err := queryTerminal("\x1b]11;?\a", queryTimeout, termquery.OSC)
+	if err != nil {
+		return nil, err
+	}
+	return parseOSCColor(reply)
+}
+
+// Can be swapped for testing.
+var terminalBackground = onceBackground()
+
+// onceBackground returns a function querying Background the first time it's
+// called, and returning the same result afterwards.
+func onceBackground() func() (color.Color, error) {
+	var once sync.Once
+	var bg color.Color
+	var err error
+	return func() (color.Color, error) {
+		once.Do(func() { bg, err = Background() })
+		return bg, err
+	}
+}
+
+// parseOSCColor parses replies such as "\x1b]11;rgb:ffff/ffff/ffff\x1b\\".
+func parseOSCColor(b []byte) (color.Color, error) {
+	s := strings.TrimSuffix(strings.TrimSuffix(string(b), "\a"), "\x1b\\")
+	i := strings.Index(s, "rgb:")
+	if i < 0 {
+		return nil, fmt.Errorf("unexpected color reply %q", b)
+	}
+	parts := strings.Split(s[i+len("rgb:"):], "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected color reply %q", b)
+	}
+	var rgb [3]uint8
+	for i, p := range parts {
+		if len(p) == 0 || len(p) > 4 {
+			return nil, fmt.Errorf("bad color component %q", p)
+		}
+		v, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("bad color component %q: %v", p, err)
+		}
+		// Components have 1 to 4 hex digits, scale them to 8 bits.
+		max := uint64(1)<<(4*uint(len(p))) - 1
+		rgb[i] = uint8(v * 0xff / max)
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, nil
+}
+
+// IsDark reports whether c is a dark color, based on its perceived luminance.
+func IsDark(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y < 0x80
+}
+
+// ContrastColor returns black or white, whichever stands out more against c.
+// It is useful to pick colors for text and borders drawn over the background.
+func ContrastColor(c color.Color) color.Color {
+	if IsDark(c) {
+		return color.White
+	}
+	return color.Black
+}
@@ -0,0 +1,92 @@
+package imgcat
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestParseOSCColor(t *testing.T) {
+	tc := []struct {
+		in  string
+		out color.Color
+		ok  bool
+	}{
+		{"\x1b]11;rgb:ffff/ffff/ffff\x1b\\", color.RGBA{0xff, 0xff, 0xff, 0xff}, true},
+		{"\x1b]11;rgb:0000/0000/0000\a", color.RGBA{0, 0, 0, 0xff}, true},
+		{"\x1b]11;rgb:1e1e/2020/2828\a", color.RGBA{0x1e, 0x20, 0x28, 0xff}, true},
+		{"\x1b]11;rgb:f/8/0\a", color.RGBA{0xff, 0x88, 0, 0xff}, true},
+		{"\x1b]11;rgb:ff/80/00\a", color.RGBA{0xff, 0x80, 0, 0xff}, true},
+		{"\x1b]11;rgb:ffff/ffff\a", nil, false},
+		{"\x1b]11;rgb:fffff/0/0\a", nil, false},
+		{"\x1b]11;rgb:zz/00/00\a", nil, false},
+		{"\x1b]11;?\a", nil, false},
+	}
+
+	for _, tt := range tc {
+		c, err := parseOSCColor([]byte(tt.in))
+		if (err == nil) != tt.ok {
+			t.Errorf("parseOSCColor(%q): unexpected error %v", tt.in, err)
+			continue
+		}
+		if c != tt.out {
+			t.Errorf("parseOSCColor(%q): expected %v; got %v", tt.in, tt.out, c)
+		}
+	}
+}
+
+func FuzzParseOSCColor(f *testing.F) {
+	f.Add([]byte("\x1b]11;rgb:ffff/ffff/ffff\x1b\\"))
+	f.Add([]byte("\x1b]11;rgb:f/8/0\a"))
+	f.Add([]byte("\x1b]11;rgb:/-1/+0\a"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		c, err := parseOSCColor(b)
+		if err != nil {
+			return
+		}
+		if _, _, _, a := c.RGBA(); a != 0xffff {
+			t.Fatalf("expected an opaque color from %q; got %v", b, c)
+		}
+	})
+}
+
+func TestBackground(t *testing.T) {
+	defer func(old func(string, time.Duration, func([]byte) bool) ([]byte, error)) { queryTerminal = old }(queryTerminal)
+
+	var req string
+	queryTerminal = func(r string, _ time.Duration, done func([]byte) bool) ([]byte, error) {
+		req = r
+		reply := []byte("\x1b]11;rgb:0000/0000/0000\x1b\\")
+		if !done(reply) {
+			t.Errorf("reply %q should be complete", reply)
+		}
+		return reply, nil
+	}
+	c, err := Background()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req != "\x1b]11;?\a" {
+		t.Errorf("unexpected query %q", req)
+	}
+	if !IsDark(c) || ContrastColor(c) != color.White {
+		t.Errorf("expected %v to be dark", c)
+	}
+
+	queryTerminal = func(string, time.Duration, func([]byte) bool) ([]byte, error) {
+		return nil, errors.New("timeout")
+	}
+	if _, err := Background(); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
+func TestContrastColor(t *testing.T) {
+	if got := ContrastColor(color.White); got != color.Black {
+		t.Errorf("expected black over white; got %v", got)
+	}
+	if got := ContrastColor(color.RGBA{0x10, 0x10, 0x40, 0xff}); got != color.White {
+		t.Errorf("expected white over navy; got %v", got)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+
+	"github.com/campoy/tools/imgcat/badge"
+)
+
+// EncodeBadge displays a status badge, see the badge package. This is useful to
+// report the results of builds or deployments in CI wrappers and scripts.
+func (enc *Encoder) EncodeBadge(b badge.Badge) error {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, b.Draw()); err != nil {
+		return fmt.Errorf("could not encode badge: %v", err)
+	}
+	return enc.Encode(buf)
+}
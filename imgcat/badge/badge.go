@@ -0,0 +1,166 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package badge draws status badges in the style of shields.io, with a label
+// on the left and a colored message on the right, without any network access.
+package badge
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// DefaultScale is the scale used if none is given, which gives badges of 26
+// pixels high.
+const DefaultScale = 2
+
+// Default colors of badges.
+var (
+	DefaultColor      = Colors["lightgrey"]
+	DefaultLabelColor = color.RGBA{0x55, 0x55, 0x55, 0xff}
+)
+
+// Colors holds the named colors of shields.io, including the semantic ones.
+var Colors = map[string]color.Color{
+	"brightgreen": hex(0x44cc11),
+	"green":       hex(0x97ca00),
+	"yellowgreen": hex(0xa4a61d),
+	"yellow":      hex(0xdfb317),
+	"orange":      hex(0xfe7d37),
+	"red":         hex(0xe05d44),
+	"blue":        hex(0x007ec6),
+	"lightgrey":   hex(0x9f9f9f),
+	"grey":        hex(0x555555),
+
+	"success":       hex(0x44cc11),
+	"important":     hex(0xfe7d37),
+	"critical":      hex(0xe05d44),
+	"informational": hex(0x007ec6),
+	"inactive":      hex(0x9f9f9f),
+}
+
+// Color returns the color with the given name, or given as six hexadecimal
+// digits like "ff8800" or "#ff8800".
+func Color(name string) (color.Color, error) {
+	if c, ok := Colors[strings.ToLower(name)]; ok {
+		return c, nil
+	}
+	s := strings.TrimPrefix(name, "#")
+	if len(s) == 6 {
+		if v, err := strconv.ParseUint(s, 16, 32); err == nil {
+			return hex(uint32(v)), nil
+		}
+	}
+	names := make([]string, 0, len(Colors))
+	for name := range Colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown color %q, use a hex code or one of %s", name, strings.Join(names, ", "))
+}
+
+func hex(v uint32) color.Color {
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+// A Badge shows a message, such as the status of a build, next to a label.
+type Badge struct {
+	// Label is written on the left side, which is omitted if it's empty.
+	Label   string
+	Message string
+	// Color of the message side, DefaultColor if nil.
+	Color color.Color
+	// Color of the label side, DefaultLabelColor if nil.
+	LabelColor color.Color
+	// Scale of the badge, DefaultScale if zero.
+	Scale int
+}
+
+// Status returns a badge with the given label, reading passing in green if ok
+// is true or failing in red otherwise.
+func Status(label string, ok bool) Badge {
+	if ok {
+		return Badge{Label: label, Message: "passing", Color: Colors["success"]}
+	}
+	return Badge{Label: label, Message: "failing", Color: Colors["critical"]}
+}
+
+// Draw draws the badge.
+func (b Badge) Draw() *image.RGBA {
+	s := b.Scale
+	if s <= 0 {
+		s = DefaultScale
+	}
+	msgColor, labelColor := b.Color, b.LabelColor
+	if msgColor == nil {
+		msgColor = DefaultColor
+	}
+	if labelColor == nil {
+		labelColor = DefaultLabelColor
+	}
+
+	pad := 4 * s
+	height := (bitmapfont.Height + 6) * s
+	var labelW int
+	if b.Label != "" {
+		labelW = bitmapfont.Measure(b.Label, s).X + 2*pad
+	}
+	msgW := bitmapfont.Measure(b.Message, s).X + 2*pad
+
+	dst := image.NewRGBA(image.Rect(0, 0, labelW+msgW, height))
+	draw.Draw(dst, image.Rect(0, 0, labelW, height), image.NewUniform(labelColor), image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(labelW, 0, labelW+msgW, height), image.NewUniform(msgColor), image.Point{}, draw.Src)
+	round(dst, 2*s)
+
+	text(dst, b.Label, image.Pt(pad, 3*s), s)
+	text(dst, b.Message, image.Pt(labelW+pad, 3*s), s)
+	return dst
+}
+
+// text draws s in white with a shadow, like shields.io does.
+func text(dst draw.Image, s string, pt image.Point, scale int) {
+	offset := scale / 2
+	if offset < 1 {
+		offset = 1
+	}
+	bitmapfont.Draw(dst, s, pt.Add(image.Pt(0, offset)), color.RGBA{0x01, 0x01, 0x01, 0x4c}, scale)
+	bitmapfont.Draw(dst, s, pt, color.White, scale)
+}
+
+// round makes the corners of img transparent, with the given radius.
+func round(img *image.RGBA, r int) {
+	b := img.Bounds()
+	for y := 0; y < r; y++ {
+		for x := 0; x < r; x++ {
+			dx, dy := r-x, r-y
+			if dx*dx+dy*dy <= r*r {
+				continue
+			}
+			for _, p := range []image.Point{
+				{b.Min.X + x, b.Min.Y + y},
+				{b.Max.X - 1 - x, b.Min.Y + y},
+				{b.Min.X + x, b.Max.Y - 1 - y},
+				{b.Max.X - 1 - x, b.Max.Y - 1 - y},
+			} {
+				img.SetRGBA(p.X, p.Y, color.RGBA{})
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package badge
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+func TestColor(t *testing.T) {
+	tc := []struct {
+		name string
+		out  color.Color
+		ok   bool
+	}{
+		{"brightgreen", hex(0x44cc11), true},
+		{"Critical", hex(0xe05d44), true},
+		{"ff8800", color.RGBA{0xff, 0x88, 0, 0xff}, true},
+		{"#0000ff", color.RGBA{0, 0, 0xff, 0xff}, true},
+		{"fff", nil, false},
+		{"purple", nil, false},
+	}
+	for _, tt := range tc {
+		c, err := Color(tt.name)
+		if (err == nil) != tt.ok {
+			t.Errorf("Color(%q): unexpected error %v", tt.name, err)
+			continue
+		}
+		if c != tt.out {
+			t.Errorf("Color(%q): expected %v; got %v", tt.name, tt.out, c)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	tc := []struct {
+		name  string
+		badge Badge
+		// width of the label side, and of the whole badge, at scale 1.
+		labelW, width int
+		scale         int
+	}{
+		{"label", Badge{Label: "ab", Message: "c", Color: red, Scale: 1}, 11 + 8, 11 + 8 + 5 + 8, 1},
+		{"no label", Badge{Message: "c", Color: red, Scale: 1}, 0, 5 + 8, 1},
+		{"default scale", Badge{Label: "ab", Message: "c", Color: red}, 11 + 8, 11 + 8 + 5 + 8, DefaultScale},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			img := tt.badge.Draw()
+			height := (bitmapfont.Height + 6) * tt.scale
+			if want := image.Rect(0, 0, tt.width*tt.scale, height); img.Bounds() != want {
+				t.Fatalf("expected bounds %v; got %v", want, img.Bounds())
+			}
+			// Corners are transparent, sides are filled with their colors.
+			if c := img.RGBAAt(0, 0); c.A != 0 {
+				t.Errorf("expected transparent corner; got %v", c)
+			}
+			if c := img.RGBAAt(tt.width*tt.scale-2, height/2); c != red {
+				t.Errorf("expected message color %v; got %v", red, c)
+			}
+			if tt.labelW > 0 {
+				if c := img.RGBAAt(1, height/2); c != DefaultLabelColor {
+					t.Errorf("expected label color %v; got %v", DefaultLabelColor, c)
+				}
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	if b := Status("build", true); b.Message != "passing" || b.Color != Colors["success"] {
+		t.Errorf("unexpected passing badge %+v", b)
+	}
+	if b := Status("build", false); b.Message != "failing" || b.Color != Colors["critical"] {
+		t.Errorf("unexpected failing badge %+v", b)
+	}
+}
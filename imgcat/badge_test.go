@@ -0,0 +1,25 @@
+package imgcat
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/badge"
+)
+
+func TestEncodeBadge(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Inline(true))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.EncodeBadge(badge.Status("build", true)); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("inline=1;size=")) || !bytes.Contains(buf.Bytes(), []byte(":iVBORw0KGgo")) {
+		t.Fatalf("expected an inline PNG; got %q", buf.Bytes()[:40])
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+
+	"github.com/campoy/tools/imgcat/barcode"
+)
+
+// barcodeScale is the size in pixels of the modules of barcodes shown by
+// EncodeBarcode.
+const barcodeScale = 4
+
+// EncodeBarcode displays a barcode, see the barcode package. This is useful in
+// logistics and warehouse tools, to show labels that can be scanned right from
+// the screen. Use barcode.Text for terminals that don't support images.
+func (enc *Encoder) EncodeBarcode(b barcode.Barcode) error {
+	buf := new(bytes.Buffer)
+	if err := barcode.WritePNG(buf, b, barcodeScale); err != nil {
+		return err
+	}
+	return enc.Encode(buf)
+}
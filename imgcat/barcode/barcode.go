@@ -0,0 +1,139 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package barcode encodes data as QR codes, Code 128, EAN-13, and Data Matrix
+// barcodes, which can be drawn as images or as text made of Unicode block
+// characters.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// ErrTooLong is returned when data doesn't fit in the largest symbol.
+var ErrTooLong = errors.New("data too long for a barcode")
+
+// A Barcode is a grid of dark and light modules.
+type Barcode interface {
+	// Bounds returns the area covered by the barcode in modules, including the
+	// light margin, or quiet zone, needed by scanners around it.
+	Bounds() image.Rectangle
+	// Black reports whether the module at x, y is dark. Modules outside of the
+	// symbol, in its quiet zone, are light.
+	Black(x, y int) bool
+}
+
+// Image draws b, including its quiet zone, with modules of scale by scale
+// pixels.
+func Image(b Barcode, scale int) *image.Gray {
+	if scale < 1 {
+		scale = 1
+	}
+	r := b.Bounds()
+	img := image.NewGray(image.Rect(0, 0, r.Dx()*scale, r.Dy()*scale))
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			v := uint8(0xff)
+			if b.Black(r.Min.X+x/scale, r.Min.Y+y/scale) {
+				v = 0
+			}
+			img.SetGray(x, y, color.Gray{v})
+		}
+	}
+	return img
+}
+
+// WritePNG writes b to w as a PNG image, see Image.
+func WritePNG(w io.Writer, b Barcode, scale int) error {
+	if err := png.Encode(w, Image(b, scale)); err != nil {
+		return fmt.Errorf("could not encode barcode: %v", err)
+	}
+	return nil
+}
+
+// Text draws b, including its quiet zone, with Unicode half blocks, so it can
+// be shown on terminals that don't support images. It uses escape sequences to
+// draw black on white regardless of the terminal colors.
+func Text(b Barcode) string {
+	r := b.Bounds()
+	var s strings.Builder
+	for y := r.Min.Y; y < r.Max.Y; y += 2 {
+		s.WriteString("\x1b[30;107m")
+		for x := r.Min.X; x < r.Max.X; x++ {
+			// The bottom half of the last line is outside odd sized codes.
+			switch top, bottom := b.Black(x, y), y+1 < r.Max.Y && b.Black(x, y+1); {
+			case top && bottom:
+				s.WriteString("█")
+			case top:
+				s.WriteString("▀")
+			case bottom:
+				s.WriteString("▄")
+			default:
+				s.WriteString(" ")
+			}
+		}
+		s.WriteString("\x1b[0m\n")
+	}
+	return s.String()
+}
+
+// A Linear barcode is made of vertical bars, such as Code 128 or EAN-13.
+type Linear struct {
+	// bars holds whether each column is dark.
+	bars   []bool
+	height int
+	// width of the quiet zone on each side.
+	quiet int
+}
+
+// linearMargin is the height in modules of the light margin above and below
+// linear barcodes.
+const linearMargin = 2
+
+// Bounds returns the area covered by the barcode and its quiet zone.
+func (l *Linear) Bounds() image.Rectangle {
+	return image.Rect(-l.quiet, -linearMargin, len(l.bars)+l.quiet, l.height+linearMargin)
+}
+
+// Black reports whether the module at x, y is dark.
+func (l *Linear) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= len(l.bars) || y >= l.height {
+		return false
+	}
+	return l.bars[x]
+}
+
+// appendWidths appends alternating dark and light bars, starting with a dark
+// one, with the widths in modules given as digits of s.
+func appendWidths(bars []bool, s string) []bool {
+	for i, w := range s {
+		for j := 0; j < int(w-'0'); j++ {
+			bars = append(bars, i%2 == 0)
+		}
+	}
+	return bars
+}
+
+// appendModules appends one bar per character of s, dark for 1 and light for 0.
+func appendModules(bars []bool, s string) []bool {
+	for _, m := range s {
+		bars = append(bars, m == '1')
+	}
+	return bars
+}
@@ -0,0 +1,92 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// checker is a 3 by 3 barcode with dark corners and center, and a quiet zone of
+// one module.
+type checker struct{}
+
+func (checker) Bounds() image.Rectangle { return image.Rect(-1, -1, 4, 4) }
+
+func (checker) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < 3 && y < 3 && (x+y)%2 == 0
+}
+
+func TestImage(t *testing.T) {
+	img := Image(checker{}, 2)
+	if want := image.Rect(0, 0, 10, 10); img.Bounds() != want {
+		t.Fatalf("expected bounds %v; got %v", want, img.Bounds())
+	}
+	tc := []struct {
+		x, y  int
+		black bool
+	}{
+		{0, 0, false},
+		{2, 2, true},
+		{3, 3, true},
+		{4, 2, false},
+		{4, 4, true},
+		{8, 8, false},
+	}
+	for _, tt := range tc {
+		if black := img.GrayAt(tt.x, tt.y).Y == 0; black != tt.black {
+			t.Errorf("expected pixel %d,%d black to be %v", tt.x, tt.y, tt.black)
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := WritePNG(buf, checker{}, 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	if want := image.Rect(0, 0, 15, 15); img.Bounds() != want {
+		t.Fatalf("expected bounds %v; got %v", want, img.Bounds())
+	}
+}
+
+func TestText(t *testing.T) {
+	want := "" +
+		"\x1b[30;107m ▄ ▄ \x1b[0m\n" +
+		"\x1b[30;107m ▄▀▄ \x1b[0m\n" +
+		"\x1b[30;107m     \x1b[0m\n"
+	if got := Text(checker{}); got != want {
+		t.Fatalf("expected text\n%s; got\n%s", want, got)
+	}
+}
+
+func TestLinear(t *testing.T) {
+	l := &Linear{bars: appendWidths(nil, "2131"), height: 3, quiet: 2}
+	if got := barString(l); got != "1101110" {
+		t.Fatalf("expected bars 1101110; got %s", got)
+	}
+	if want := image.Rect(-2, -linearMargin, 9, 3+linearMargin); l.Bounds() != want {
+		t.Fatalf("expected bounds %v; got %v", want, l.Bounds())
+	}
+	if !l.Black(0, 2) || l.Black(0, 3) || l.Black(-1, 0) {
+		t.Errorf("unexpected modules outside of the bars")
+	}
+}
+
+// barString returns the modules of l as 1 for dark and 0 for light.
+func barString(l *Linear) string {
+	var b strings.Builder
+	for _, bar := range l.bars {
+		if bar {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+import "fmt"
+
+// Special values of Code 128 symbols.
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code 128 code sets.
+const (
+	setA = iota
+	setB
+	setC
+)
+
+// code128QuietZone is the width in modules of the quiet zone of Code 128.
+const code128QuietZone = 10
+
+// EncodeCode128 encodes data, made of ASCII characters, as a Code 128 barcode.
+// Code 128 is the usual barcode on shipping labels, as GS1-128, and on
+// warehouse bins and pallets. Runs of digits are packed two per symbol.
+func EncodeCode128(data string) (*Linear, error) {
+	values, err := code128Values(data)
+	if err != nil {
+		return nil, err
+	}
+	var bars []bool
+	for _, v := range values {
+		bars = appendWidths(bars, code128Patterns[v])
+	}
+	height := len(bars) * 15 / 100
+	if height < 30 {
+		height = 30
+	}
+	return &Linear{bars: bars, height: height, quiet: code128QuietZone}, nil
+}
+
+// code128Values returns the values of the symbols encoding data, from the start
+// symbol to the stop one, included.
+func code128Values(data string) ([]int, error) {
+	for i := 0; i < len(data); i++ {
+		if data[i] > 127 {
+			return nil, fmt.Errorf("could not encode %q at position %d in Code 128", data[i], i)
+		}
+	}
+
+	var values []int
+	set := -1
+	for i := 0; i < len(data); {
+		// Runs of at least four digits are worth switching to code C, as well
+		// as a whole input made of two digits. Odd runs start after one digit.
+		if n := digits(data[i:]); n >= 4 || n == 2 && len(data) == 2 {
+			if n%2 == 0 || set != -1 {
+				if n%2 == 1 {
+					values = append(values, code128Value(set, data[i]))
+					i++
+				}
+				if set == -1 {
+					values = append(values, code128StartC)
+				} else if set != setC {
+					values = append(values, code128CodeC)
+				}
+				set = setC
+				for ; i+1 < len(data) && digits(data[i:i+2]) == 2; i += 2 {
+					values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+				}
+				continue
+			}
+		}
+
+		next := setB
+		if needsSetA(data[i:]) {
+			next = setA
+		}
+		switch {
+		case set == -1 && next == setA:
+			values = append(values, code128StartA)
+		case set == -1:
+			values = append(values, code128StartB)
+		case set == setA && data[i] >= 96, set == setC && next == setB:
+			values = append(values, code128CodeB)
+			next = setB
+		case set == setB && data[i] < 32, set == setC && next == setA:
+			values = append(values, code128CodeA)
+			next = setA
+		default:
+			next = set
+		}
+		set = next
+		values = append(values, code128Value(set, data[i]))
+		i++
+	}
+	if set == -1 {
+		values = append(values, code128StartB)
+	}
+
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	return append(values, sum%103, code128Stop), nil
+}
+
+// code128Value returns the value of c in code set A or B.
+func code128Value(set int, c byte) int {
+	if set == setA && c < 32 {
+		return int(c) + 64
+	}
+	return int(c) - 32
+}
+
+// needsSetA reports whether a control character comes in s before any lower
+// case letter, so code set A is a better choice than B.
+func needsSetA(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] < 32:
+			return true
+		case s[i] >= 96:
+			return false
+		}
+	}
+	return false
+}
+
+// digits returns the number of leading ASCII digits in s.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// code128Patterns holds the widths of the bars and spaces of each symbol, by
+// value.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
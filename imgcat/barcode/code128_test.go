@@ -0,0 +1,89 @@
+package barcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCode128Patterns(t *testing.T) {
+	seen := map[string]bool{}
+	for v, p := range code128Patterns {
+		width, want := 0, 11
+		if v == code128Stop {
+			want = 13
+		}
+		for _, w := range p {
+			width += int(w - '0')
+		}
+		if width != want {
+			t.Errorf("pattern %d is %d modules wide; expected %d", v, width, want)
+		}
+		if seen[p] {
+			t.Errorf("pattern %d is duplicated", v)
+		}
+		seen[p] = true
+	}
+}
+
+func TestCode128Values(t *testing.T) {
+	tc := []struct {
+		data   string
+		values []int
+	}{
+		{"", []int{104}},
+		{"12", []int{105, 12}},
+		{"1234", []int{105, 12, 34}},
+		{"12345", []int{104, 17, 99, 23, 45}},
+		{"Ab", []int{104, 33, 66}},
+		{"AB\t", []int{103, 33, 34, 73}},
+		{"a\tb", []int{104, 65, 101, 73, 100, 66}},
+		{"ABC123456", []int{104, 33, 34, 35, 99, 12, 34, 56}},
+		{"123456A", []int{105, 12, 34, 56, 100, 33}},
+	}
+
+	for _, tt := range tc {
+		t.Run(fmt.Sprintf("%q", tt.data), func(t *testing.T) {
+			values, err := code128Values(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The checksum and stop symbols are checked below.
+			if n := len(values) - 2; n < 0 || !equalInts(tt.values, values[:n]) {
+				t.Fatalf("expected values %v; got %v", tt.values, values)
+			}
+			if values[len(values)-1] != code128Stop {
+				t.Fatalf("expected stop symbol; got %v", values)
+			}
+		})
+	}
+
+	// (104 + 17 + 2*99 + 3*23 + 4*45) % 103
+	values, err := code128Values("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := values[len(values)-2]; sum != 53 {
+		t.Errorf("expected checksum 53; got %d", sum)
+	}
+
+	if _, err := code128Values("café"); err == nil {
+		t.Errorf("expected error encoding non ASCII characters; got nothing")
+	}
+}
+
+func TestEncodeCode128(t *testing.T) {
+	l, err := EncodeCode128("1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Start C, two symbols, checksum, and stop.
+	if want := 4*11 + 13; len(l.bars) != want {
+		t.Fatalf("expected %d modules; got %d", want, len(l.bars))
+	}
+	if got := barString(l)[:11]; got != "11010011100" {
+		t.Errorf("expected start C symbol 11010011100; got %s", got)
+	}
+	if got := barString(l)[len(l.bars)-13:]; got != "1100011101011" {
+		t.Errorf("expected stop symbol 1100011101011; got %s", got)
+	}
+}
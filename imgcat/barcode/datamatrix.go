@@ -0,0 +1,290 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+import "image"
+
+// dataMatrixQuietZone is the width in modules of the quiet zone of Data Matrix
+// codes.
+const dataMatrixQuietZone = 1
+
+// A DataMatrix is a square ECC 200 Data Matrix code, holding up to 1556 bytes.
+// Data Matrix codes are common on small parts and packaging, where they are
+// more compact than QR codes.
+type DataMatrix struct {
+	size    int
+	modules []bool
+}
+
+// A dataMatrixSize describes one of the square Data Matrix symbols.
+type dataMatrixSize struct {
+	// number of modules on each side of the symbol and of its data regions.
+	size, region int
+	// number of codewords of data and error correction.
+	data, ecc int
+	// number of interleaved error correction blocks.
+	blocks int
+}
+
+var dataMatrixSizes = [...]dataMatrixSize{
+	{10, 8, 3, 5, 1},
+	{12, 10, 5, 7, 1},
+	{14, 12, 8, 10, 1},
+	{16, 14, 12, 12, 1},
+	{18, 16, 18, 14, 1},
+	{20, 18, 22, 18, 1},
+	{22, 20, 30, 20, 1},
+	{24, 22, 36, 24, 1},
+	{26, 24, 44, 28, 1},
+	{32, 14, 62, 36, 1},
+	{36, 16, 86, 42, 1},
+	{40, 18, 114, 48, 1},
+	{44, 20, 144, 56, 1},
+	{48, 22, 174, 68, 1},
+	{52, 24, 204, 84, 2},
+	{64, 14, 280, 112, 2},
+	{72, 16, 368, 144, 4},
+	{80, 18, 456, 192, 4},
+	{88, 20, 576, 224, 4},
+	{96, 22, 696, 272, 4},
+	{104, 24, 816, 336, 6},
+	{120, 18, 1050, 408, 6},
+	{132, 20, 1304, 496, 8},
+	{144, 22, 1558, 620, 10},
+}
+
+// EncodeDataMatrix encodes data in the smallest square Data Matrix code, using
+// the ASCII encodation, which packs pairs of digits in a single codeword.
+func EncodeDataMatrix(data []byte) (*DataMatrix, error) {
+	codewords := dataMatrixASCII(data)
+	var s dataMatrixSize
+	for _, s = range dataMatrixSizes {
+		if len(codewords) <= s.data {
+			break
+		}
+	}
+	if len(codewords) > s.data {
+		return nil, ErrTooLong
+	}
+
+	codewords = dataMatrixPad(codewords, s.data)
+	codewords = append(codewords, dataMatrixECC(codewords, s)...)
+
+	m := &DataMatrix{size: s.size, modules: make([]bool, s.size*s.size)}
+	m.drawFinders(s.region)
+	n := s.size / (s.region + 2) * s.region
+	for i, v := range dataMatrixPlacement(n, n) {
+		black := v == 1
+		if v >= 10 {
+			black = codewords[v/10-1]&(0x80>>uint(v%10-1)) != 0
+		}
+		// Skip the finder patterns around each data region.
+		row, col := i/n, i%n
+		row += 2*(row/s.region) + 1
+		col += 2*(col/s.region) + 1
+		m.modules[row*m.size+col] = black
+	}
+	return m, nil
+}
+
+// Bounds returns the area covered by the code and its quiet zone.
+func (m *DataMatrix) Bounds() image.Rectangle {
+	return image.Rect(0, 0, m.size, m.size).Inset(-dataMatrixQuietZone)
+}
+
+// Black reports whether the module at x, y is dark.
+func (m *DataMatrix) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.size || y >= m.size {
+		return false
+	}
+	return m.modules[y*m.size+x]
+}
+
+// drawFinders draws the solid left and bottom edges and the alternating top
+// and right edges of each data region.
+func (m *DataMatrix) drawFinders(region int) {
+	side := region + 2
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			rx, ry := x%side, y%side
+			switch {
+			case rx == 0 || ry == side-1:
+				m.modules[y*m.size+x] = true
+			case ry == 0:
+				m.modules[y*m.size+x] = rx%2 == 0
+			case rx == side-1:
+				m.modules[y*m.size+x] = ry%2 == 1
+			}
+		}
+	}
+}
+
+// dataMatrixASCII returns the codewords encoding data in ASCII encodation.
+func dataMatrixASCII(data []byte) []byte {
+	var out []byte
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case digits(string(data[i:min(i+2, len(data))])) == 2:
+			out = append(out, 130+(c-'0')*10+data[i+1]-'0')
+			i++
+		case c >= 128:
+			// Upper shift, for extended ASCII.
+			out = append(out, 235, c-127)
+		default:
+			out = append(out, c+1)
+		}
+	}
+	return out
+}
+
+// dataMatrixPad pads codewords to the capacity of the symbol, with a first pad
+// codeword followed by scrambled ones.
+func dataMatrixPad(codewords []byte, capacity int) []byte {
+	if len(codewords) < capacity {
+		codewords = append(codewords, 129)
+	}
+	for len(codewords) < capacity {
+		v := 129 + (149*(len(codewords)+1))%253 + 1
+		if v > 254 {
+			v -= 254
+		}
+		codewords = append(codewords, byte(v))
+	}
+	return codewords
+}
+
+// dataMatrixECC returns the error correction codewords of data, interleaved
+// between the blocks of the symbol.
+func dataMatrixECC(data []byte, s dataMatrixSize) []byte {
+	perBlock := s.ecc / s.blocks
+	divisor := dataMatrixField.divisor(perBlock, 1)
+	out := make([]byte, s.ecc)
+	for b := 0; b < s.blocks; b++ {
+		var block []byte
+		for i := b; i < len(data); i += s.blocks {
+			block = append(block, data[i])
+		}
+		for i, v := range dataMatrixField.remainder(block, divisor) {
+			out[i*s.blocks+b] = v
+		}
+	}
+	return out
+}
+
+// dataMatrixPlacement returns the placement of codewords in a mapping matrix of
+// nrow by ncol modules, following ISO/IEC 16022 Annex F. Each module holds
+// either 10*codeword + bit, with both starting at 1 and bit 1 being the most
+// significant one, or 1 and 0 for the fixed dark and light modules of the
+// bottom right corner.
+func dataMatrixPlacement(nrow, ncol int) []int {
+	p := &placement{nrow: nrow, ncol: ncol, array: make([]int, nrow*ncol)}
+	chr, row, col := 1, 4, 0
+	for {
+		switch {
+		case row == nrow && col == 0:
+			p.corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, 1}, {nrow - 1, 2}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		case row == nrow-2 && col == 0 && ncol%4 != 0:
+			p.corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 4}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}})
+			chr++
+		case row == nrow-2 && col == 0 && ncol%8 == 4:
+			p.corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		case row == nrow+4 && col == 2 && ncol%8 == 0:
+			p.corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, ncol - 1}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 3}, {1, ncol - 2}, {1, ncol - 1}})
+			chr++
+		}
+
+		// Sweep upward diagonally to the right.
+		for {
+			if row < nrow && col >= 0 && p.array[row*ncol+col] == 0 {
+				p.utah(row, col, chr)
+				chr++
+			}
+			row, col = row-2, col+2
+			if row < 0 || col >= ncol {
+				break
+			}
+		}
+		row, col = row+1, col+3
+
+		// Sweep downward diagonally to the left.
+		for {
+			if row >= 0 && col < ncol && p.array[row*ncol+col] == 0 {
+				p.utah(row, col, chr)
+				chr++
+			}
+			row, col = row+2, col-2
+			if row >= nrow || col < 0 {
+				break
+			}
+		}
+		row, col = row+3, col+1
+
+		if row >= nrow && col >= ncol {
+			break
+		}
+	}
+
+	// Fill the bottom right corner left unused by some sizes.
+	if p.array[nrow*ncol-1] == 0 {
+		p.array[nrow*ncol-1], p.array[nrow*ncol-ncol-2] = 1, 1
+	}
+	return p.array
+}
+
+// A placement holds the mapping matrix being filled by dataMatrixPlacement.
+type placement struct {
+	nrow, ncol int
+	array      []int
+}
+
+// module places the given bit of a codeword, wrapping around the edges.
+func (p *placement) module(row, col, chr, bit int) {
+	if row < 0 {
+		row += p.nrow
+		col += 4 - (p.nrow+4)%8
+	}
+	if col < 0 {
+		col += p.ncol
+		row += 4 - (p.ncol+4)%8
+	}
+	p.array[row*p.ncol+col] = 10*chr + bit
+}
+
+// utah places a codeword in the standard shape, with its last bit at row, col.
+func (p *placement) utah(row, col, chr int) {
+	p.module(row-2, col-2, chr, 1)
+	p.module(row-2, col-1, chr, 2)
+	p.module(row-1, col-2, chr, 3)
+	p.module(row-1, col-1, chr, 4)
+	p.module(row-1, col, chr, 5)
+	p.module(row, col-2, chr, 6)
+	p.module(row, col-1, chr, 7)
+	p.module(row, col, chr, 8)
+}
+
+// corner places a codeword in one of the special shapes used at the corners.
+func (p *placement) corner(chr int, pos [8][2]int) {
+	for i, rc := range pos {
+		p.module(rc[0], rc[1], chr, i+1)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
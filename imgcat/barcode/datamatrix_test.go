@@ -0,0 +1,148 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestDataMatrixASCII(t *testing.T) {
+	tc := []struct {
+		data string
+		want []byte
+	}{
+		{"A", []byte{66}},
+		{"123456", []byte{142, 164, 186}},
+		{"1A23", []byte{50, 66, 153}},
+		{"\xe9", []byte{235, 106}},
+	}
+	for _, tt := range tc {
+		if got := dataMatrixASCII([]byte(tt.data)); !bytes.Equal(got, tt.want) {
+			t.Errorf("expected codewords of %q to be %v; got %v", tt.data, tt.want, got)
+		}
+	}
+}
+
+func TestDataMatrixPad(t *testing.T) {
+	if got, want := dataMatrixPad([]byte{66}, 3), []byte{66, 129, 70}; !bytes.Equal(got, want) {
+		t.Fatalf("expected padded codewords %v; got %v", want, got)
+	}
+}
+
+func TestDataMatrixECC(t *testing.T) {
+	// The usual worked example, 123456 in a 10x10 symbol.
+	data := []byte{142, 164, 186}
+	want := []byte{114, 25, 5, 88, 102}
+	if got := dataMatrixECC(data, dataMatrixSizes[0]); !bytes.Equal(got, want) {
+		t.Fatalf("expected error correction %v; got %v", want, got)
+	}
+}
+
+func TestDataMatrixSizes(t *testing.T) {
+	for _, s := range dataMatrixSizes {
+		n := s.size / (s.region + 2)
+		if n*(s.region+2) != s.size {
+			t.Errorf("%dx%d: regions of %d modules don't fit", s.size, s.size, s.region)
+		}
+		if bits := (n * s.region) * (n * s.region); bits/8 != s.data+s.ecc {
+			t.Errorf("%dx%d: expected %d codewords; got %d", s.size, s.size, bits/8, s.data+s.ecc)
+		}
+		if s.ecc%s.blocks != 0 {
+			t.Errorf("%dx%d: %d error correction codewords can't be split in %d blocks", s.size, s.size, s.ecc, s.blocks)
+		}
+	}
+}
+
+func TestDataMatrixPlacement(t *testing.T) {
+	for _, s := range dataMatrixSizes {
+		t.Run(fmt.Sprintf("%dx%d", s.size, s.size), func(t *testing.T) {
+			n := s.size / (s.region + 2) * s.region
+			seen := map[int]bool{}
+			for i, v := range dataMatrixPlacement(n, n) {
+				if v < 10 {
+					continue
+				}
+				if seen[v] {
+					t.Fatalf("bit %d of codeword %d placed twice, again at %d", v%10, v/10, i)
+				}
+				seen[v] = true
+			}
+			if want := 8 * (s.data + s.ecc); len(seen) != want {
+				t.Fatalf("expected %d bits placed; got %d", want, len(seen))
+			}
+		})
+	}
+}
+
+func TestEncodeDataMatrix(t *testing.T) {
+	tc := []struct {
+		n    int
+		size int
+	}{
+		{0, 10},
+		{3, 10},
+		{4, 12},
+		{1556, 144},
+	}
+	for _, tt := range tc {
+		m, err := EncodeDataMatrix(bytes.Repeat([]byte("a"), tt.n))
+		if err != nil {
+			t.Fatalf("could not encode %d bytes: %v", tt.n, err)
+		}
+		if m.size != tt.size {
+			t.Errorf("expected %d bytes in a %dx%d symbol; got %dx%d", tt.n, tt.size, tt.size, m.size, m.size)
+		}
+	}
+	if _, err := EncodeDataMatrix(bytes.Repeat([]byte("a"), 1559)); err != ErrTooLong {
+		t.Fatalf("expected error %v; got %v", ErrTooLong, err)
+	}
+}
+
+func TestDataMatrixRoundTrip(t *testing.T) {
+	for _, data := range []string{"123456", "Hello, world!", "https://example.com/parts/A1234-0001?lot=42"} {
+		m, err := EncodeDataMatrix([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s dataMatrixSize
+		for _, s = range dataMatrixSizes {
+			if s.size == m.size {
+				break
+			}
+		}
+
+		n := m.size / (s.region + 2) * s.region
+		codewords := make([]byte, s.data+s.ecc)
+		for i, v := range dataMatrixPlacement(n, n) {
+			row, col := i/n, i%n
+			row += 2*(row/s.region) + 1
+			col += 2*(col/s.region) + 1
+			if v >= 10 && m.Black(col, row) {
+				codewords[v/10-1] |= 0x80 >> uint(v%10-1)
+			}
+		}
+
+		want := dataMatrixPad(dataMatrixASCII([]byte(data)), s.data)
+		if got := codewords[:s.data]; !bytes.Equal(got, want) {
+			t.Errorf("%q: expected data codewords %v; got %v", data, want, got)
+		}
+		if got, want := codewords[s.data:], dataMatrixECC(want, s); !bytes.Equal(got, want) {
+			t.Errorf("%q: expected error correction codewords %v; got %v", data, want, got)
+		}
+	}
+}
+
+func TestDataMatrixFinders(t *testing.T) {
+	m, err := EncodeDataMatrix([]byte("123456"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < m.size; i++ {
+		if !m.Black(0, i) || !m.Black(i, m.size-1) {
+			t.Fatalf("expected solid left and bottom edges")
+		}
+		if m.Black(i, 0) != (i%2 == 0) || m.Black(m.size-1, i) != (i%2 == 1) {
+			t.Fatalf("expected alternating top and right edges")
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dimensions in modules of EAN-13 barcodes, at their nominal size.
+const (
+	ean13Height    = 69
+	ean13QuietZone = 11
+)
+
+// EncodeEAN13 encodes an EAN-13 barcode, as found on retail products. digits
+// holds either the 12 digits of the number, and the check digit is computed, or
+// all 13 of them, and the check digit is verified.
+func EncodeEAN13(digits string) (*Linear, error) {
+	if len(digits) != 12 && len(digits) != 13 || strings.Trim(digits, "0123456789") != "" {
+		return nil, fmt.Errorf("EAN-13 requires 12 or 13 digits, got %q", digits)
+	}
+	check := ean13Check(digits[:12])
+	if len(digits) == 13 && digits[12] != check {
+		return nil, fmt.Errorf("wrong check digit in %s, expected %c", digits, check)
+	}
+	digits = digits[:12] + string(check)
+
+	bars := appendModules(nil, "101")
+	parity := ean13Parity[digits[0]-'0']
+	for i, d := range digits[1:7] {
+		code := ean13L[d-'0']
+		if parity[i] == 'G' {
+			code = ean13G(code)
+		}
+		bars = appendModules(bars, code)
+	}
+	bars = appendModules(bars, "01010")
+	for _, d := range digits[7:] {
+		bars = appendModules(bars, ean13R(ean13L[d-'0']))
+	}
+	bars = appendModules(bars, "101")
+	return &Linear{bars: bars, height: ean13Height, quiet: ean13QuietZone}, nil
+}
+
+// ean13Check returns the check digit of the first 12 digits of a number.
+func ean13Check(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		w := 1
+		if i%2 == 1 {
+			w = 3
+		}
+		sum += w * int(digits[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ean13R returns the right hand code of a digit, the complement of its L code.
+func ean13R(l string) string {
+	r := []byte(l)
+	for i := range r {
+		r[i] ^= '0' ^ '1'
+	}
+	return string(r)
+}
+
+// ean13G returns the even parity code of a digit, its R code reversed.
+func ean13G(l string) string {
+	r := []byte(ean13R(l))
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// ean13L holds the odd parity codes of each digit, for the left half.
+var ean13L = [...]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// ean13Parity holds the parities of the left half, given by the first digit.
+var ean13Parity = [...]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
@@ -0,0 +1,56 @@
+package barcode
+
+import "testing"
+
+func TestEAN13Check(t *testing.T) {
+	tc := []struct {
+		digits string
+		check  byte
+	}{
+		{"400638133393", '1'},
+		{"590123412345", '7'},
+		{"000000000000", '0'},
+	}
+	for _, tt := range tc {
+		if got := ean13Check(tt.digits); got != tt.check {
+			t.Errorf("expected check digit of %s to be %c; got %c", tt.digits, tt.check, got)
+		}
+	}
+}
+
+func TestEncodeEAN13(t *testing.T) {
+	l, err := EncodeEAN13("400638133393")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bars := barString(l)
+	if len(bars) != 95 {
+		t.Fatalf("expected 95 modules; got %d", len(bars))
+	}
+	tc := []struct {
+		name  string
+		start int
+		want  string
+	}{
+		{"start guard", 0, "101"},
+		{"first digit 0 with odd parity", 3, "0001101"},
+		{"second digit 0 with even parity", 10, "0100111"},
+		{"center guard", 45, "01010"},
+		{"check digit 1", 85, "1100110"},
+		{"end guard", 92, "101"},
+	}
+	for _, tt := range tc {
+		if got := bars[tt.start : tt.start+len(tt.want)]; got != tt.want {
+			t.Errorf("expected %s %s; got %s", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := EncodeEAN13("4006381333931"); err != nil {
+		t.Errorf("unexpected error with a valid check digit: %v", err)
+	}
+	for _, digits := range []string{"4006381333932", "40063813339", "40063813339a"} {
+		if _, err := EncodeEAN13(digits); err == nil {
+			t.Errorf("expected error encoding %s; got nothing", digits)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+import (
+	"errors"
+	"image"
+)
+
+// A Level of error correction, which trades capacity for robustness.
+type Level int
+
+// Supported error correction levels, recovering from about 7%, 15%, 25%, and
+// 30% of damage respectively.
+const (
+	Low Level = iota
+	Medium
+	Quartile
+	High
+)
+
+// A QRCode is a two dimensional barcode holding up to 2953 bytes.
+type QRCode struct {
+	// number of modules on each side.
+	size    int
+	version int
+	modules []bool
+	// function modules are not part of the data.
+	function []bool
+}
+
+// EncodeQR encodes data in the smallest QR code with the given error
+// correction level. Data is encoded in byte mode, which works for any input
+// such as URLs or otpauth:// provisioning URIs.
+func EncodeQR(data []byte, level Level) (*QRCode, error) {
+	if level < Low || level > High {
+		return nil, errors.New("invalid error correction level")
+	}
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v, level) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	var bits bitBuffer
+	bits.append(0x4, 4) // byte mode
+	bits.append(uint(len(data)), countBits(version))
+	for _, b := range data {
+		bits.append(uint(b), 8)
+	}
+	capacity := 8 * dataCodewords(version, level)
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := uint(0xec); len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(interleave(bits.bytes(), version, level))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(level, mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(level, best)
+	return c, nil
+}
+
+// qrQuietZone is the width in modules of the light border around QR codes.
+const qrQuietZone = 4
+
+// Bounds returns the area covered by the code and its quiet zone.
+func (c *QRCode) Bounds() image.Rectangle {
+	return image.Rect(0, 0, c.size, c.size).Inset(-qrQuietZone)
+}
+
+// Black reports whether the module at x, y is dark.
+func (c *QRCode) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.size || y >= c.size {
+		return false
+	}
+	return c.modules[y*c.size+x]
+}
+
+// countBits returns the size of the character count in byte mode.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// A bitBuffer holds bits, one per element.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v uint, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>uint(i)&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, (len(b)+7)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return out
+}
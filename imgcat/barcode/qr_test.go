@@ -0,0 +1,293 @@
+package barcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// HELLO WORLD encoded in a 1-M code, from the thonky.com QR tutorial.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := qrField.remainder(data, qrField.divisor(len(want), 0)); !bytes.Equal(got, want) {
+		t.Fatalf("expected error correction %v; got %v", want, got)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	tc := []struct {
+		level Level
+		mask  int
+		bits  string
+	}{
+		{Low, 0, "111011111000100"},
+		{Low, 1, "111001011110011"},
+		{Medium, 0, "101010000010010"},
+		{Quartile, 0, "011010101011111"},
+		{High, 0, "001011010001001"},
+	}
+	for _, tt := range tc {
+		var b bitBuffer
+		b.append(formatBits(tt.level, tt.mask), 15)
+		if got := bitString(b); got != tt.bits {
+			t.Errorf("level %d mask %d: expected %s; got %s", tt.level, tt.mask, tt.bits, got)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	for version, want := range map[int]uint{7: 0x07c94, 8: 0x085bc, 40: 0x28c69} {
+		if got := versionBits(version); got != want {
+			t.Errorf("version %d: expected %x; got %x", version, want, got)
+		}
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tc := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		32: {6, 34, 60, 86, 112, 138},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for version, want := range tc {
+		if got := alignmentPositions(version); !equalInts(got, want) {
+			t.Errorf("version %d: expected %v; got %v", version, want, got)
+		}
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	tc := []struct {
+		n       int
+		level   Level
+		version int
+	}{
+		{17, Low, 1},
+		{18, Low, 2},
+		{14, Medium, 1},
+		{7, High, 1},
+		{2953, Low, 40},
+		{1273, High, 40},
+	}
+	for _, tt := range tc {
+		c, err := EncodeQR(make([]byte, tt.n), tt.level)
+		if err != nil {
+			t.Errorf("%d bytes at level %d: %v", tt.n, tt.level, err)
+			continue
+		}
+		if c.version != tt.version || c.size != 4*tt.version+17 {
+			t.Errorf("%d bytes at level %d: expected version %d; got %d", tt.n, tt.level, tt.version, c.version)
+		}
+	}
+	if _, err := EncodeQR(make([]byte, 2954), Low); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong; got %v", err)
+	}
+}
+
+// TestRoundTrip reads back the codes, checking their format information and
+// error correction, and that they hold the encoded data.
+func TestRoundTrip(t *testing.T) {
+	tc := []struct {
+		data  string
+		level Level
+	}{
+		{"hello", Medium},
+		{"otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example", Quartile},
+		{strings.Repeat("https://example.com/device ", 10), High},
+		{strings.Repeat("x", 1000), Low},
+	}
+	for _, tt := range tc {
+		c, err := EncodeQR([]byte(tt.data), tt.level)
+		if err != nil {
+			t.Fatalf("could not encode %q: %v", tt.data, err)
+		}
+		level, mask := readFormat(t, c)
+		if level != tt.level {
+			t.Errorf("expected level %d; got %d", tt.level, level)
+		}
+		c.applyMask(mask)
+		data := deinterleave(t, readCodewords(c), c.version, level)
+		c.applyMask(mask)
+
+		n := countBits(c.version)
+		bits := bitString(bitBufferOf(data))
+		if bits[:4] != "0100" {
+			t.Fatalf("expected byte mode; got %s", bits[:4])
+		}
+		length := parseBits(bits[4 : 4+n])
+		if length != len(tt.data) {
+			t.Fatalf("expected length %d; got %d", len(tt.data), length)
+		}
+		got := make([]byte, length)
+		for i := range got {
+			got[i] = byte(parseBits(bits[4+n+8*i : 4+n+8*i+8]))
+		}
+		if string(got) != tt.data {
+			t.Errorf("expected data %q; got %q", tt.data, got)
+		}
+	}
+}
+
+func readFormat(t *testing.T, c *QRCode) (Level, int) {
+	var first, second uint
+	for i := 0; i <= 5; i++ {
+		first |= b2u(c.Black(8, i)) << uint(i)
+	}
+	first |= b2u(c.Black(8, 7))<<6 | b2u(c.Black(8, 8))<<7 | b2u(c.Black(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b2u(c.Black(14-i, 8)) << uint(i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= b2u(c.Black(c.size-1-i, 8)) << uint(i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= b2u(c.Black(8, c.size-15+i)) << uint(i)
+	}
+	if first != second {
+		t.Fatalf("format information copies differ: %015b and %015b", first, second)
+	}
+	for level := Low; level <= High; level++ {
+		for mask := 0; mask < 8; mask++ {
+			if formatBits(level, mask) == first {
+				return level, mask
+			}
+		}
+	}
+	t.Fatalf("invalid format information %015b", first)
+	return 0, 0
+}
+
+func readCodewords(c *QRCode) []byte {
+	var bits bitBuffer
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !c.function[y*c.size+x] {
+					bits = append(bits, c.Black(x, y))
+				}
+			}
+		}
+	}
+	return bits.bytes()[:rawCodewords(c.version)]
+}
+
+// deinterleave splits codewords in blocks, checks their error correction, and
+// returns their data.
+func deinterleave(t *testing.T, codewords []byte, version int, level Level) []byte {
+	n, ecc := numBlocks[level][version], eccPerBlock[level][version]
+	blocks := make([][]byte, n)
+	short := n - len(codewords)%n
+	dataLen := len(codewords)/n - ecc
+	i := 0
+	for k := 0; k < dataLen+1; k++ {
+		for b := range blocks {
+			if k == dataLen && b < short {
+				continue
+			}
+			blocks[b] = append(blocks[b], codewords[i])
+			i++
+		}
+	}
+	eccs := make([][]byte, n)
+	for k := 0; k < ecc; k++ {
+		for b := range blocks {
+			eccs[b] = append(eccs[b], codewords[i])
+			i++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		if rem := qrField.remainder(block, qrField.divisor(ecc, 0)); !bytes.Equal(rem, eccs[b]) {
+			t.Fatalf("bad error correction in block %d", b)
+		}
+		data = append(data, block...)
+	}
+	return data
+}
+
+func TestQRText(t *testing.T) {
+	c, err := EncodeQR([]byte("hi"), Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(Text(c), "\n"), "\n")
+	if want := (c.size + 2*qrQuietZone + 1) / 2; len(lines) != want {
+		t.Fatalf("expected %d lines; got %d", want, len(lines))
+	}
+	// The first two lines are the quiet zone, the third one starts with the
+	// top two rows of the finder pattern.
+	if want := "\x1b[30;107m    █▀▀▀▀▀█ "; !strings.HasPrefix(lines[2], want) {
+		t.Errorf("expected line starting with %q; got %q", want, lines[2])
+	}
+}
+
+func TestQRImage(t *testing.T) {
+	c, err := EncodeQR([]byte("hi"), Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := Image(c, 2)
+	if side := (c.size + 2*qrQuietZone) * 2; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Fatalf("expected %dx%d image; got %v", side, side, img.Bounds())
+	}
+	if img.GrayAt(0, 0).Y != 0xff || img.GrayAt(2*qrQuietZone, 2*qrQuietZone).Y != 0 || img.GrayAt(2*qrQuietZone+3, 2*qrQuietZone+3).Y != 0xff {
+		t.Errorf("unexpected quiet zone or finder pattern")
+	}
+}
+
+func bitString(b bitBuffer) string {
+	s := make([]byte, len(b))
+	for i, bit := range b {
+		s[i] = '0'
+		if bit {
+			s[i] = '1'
+		}
+	}
+	return string(s)
+}
+
+func bitBufferOf(data []byte) bitBuffer {
+	var b bitBuffer
+	for _, v := range data {
+		b.append(uint(v), 8)
+	}
+	return b
+}
+
+func parseBits(s string) int {
+	v := 0
+	for _, c := range s {
+		v = v<<1 | int(c-'0')
+	}
+	return v
+}
+
+func b2u(b bool) uint {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+import "strings"
+
+func newCode(version int) *QRCode {
+	size := 4*version + 17
+	return &QRCode{
+		size:     size,
+		version:  version,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+}
+
+func (c *QRCode) set(x, y int, black bool) {
+	c.modules[y*c.size+x] = black
+	c.function[y*c.size+x] = true
+}
+
+func (c *QRCode) drawFunctionPatterns() {
+	for i := 0; i < c.size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	for _, p := range [][2]int{{3, 3}, {c.size - 4, 3}, {3, c.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := p[0]+dx, p[1]+dy
+				if x < 0 || y < 0 || x >= c.size || y >= c.size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				c.set(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	pos := alignmentPositions(c.version)
+	for i, x := range pos {
+		for j, y := range pos {
+			// Skip the ones overlapping the finder patterns.
+			last := len(pos) - 1
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas, drawn once the mask is chosen.
+	c.drawFormat(0, 0)
+
+	if c.version >= 7 {
+		bits := versionBits(c.version)
+		for i := 0; i < 18; i++ {
+			black := bits>>uint(i)&1 != 0
+			a, b := c.size-11+i%3, i/3
+			c.set(a, b, black)
+			c.set(b, a, black)
+		}
+	}
+}
+
+// alignmentPositions returns the coordinates of the centers of the alignment
+// patterns, in both directions.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	pos := make([]int, n)
+	pos[0] = 6
+	for i := n - 1; i > 0; i-- {
+		pos[i] = 4*version + 17 - 7 - (n-1-i)*step
+	}
+	return pos
+}
+
+// formatBits returns the 15 bits of format information, with their error
+// correction.
+func formatBits(level Level, mask int) uint {
+	// The levels are not encoded in order.
+	data := uint([]int{1, 0, 3, 2}[level]<<3 | mask)
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (c *QRCode) drawFormat(level Level, mask int) {
+	bits := formatBits(level, mask)
+	bit := func(i int) bool { return bits>>uint(i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(c.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.size-15+i, bit(i))
+	}
+	c.set(8, c.size-8, true)
+}
+
+// versionBits returns the 18 bits of version information, with their error
+// correction.
+func versionBits(version int) uint {
+	rem := uint(version)
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	return uint(version)<<12 | rem
+}
+
+// drawCodewords places the data in zigzag, two columns at a time from the
+// bottom right corner, skipping function modules.
+func (c *QRCode) drawCodewords(data []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skip the vertical timing pattern.
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.size; vert++ {
+			y := vert
+			if upward {
+				y = c.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y*c.size+x] || i >= 8*len(data) {
+					continue
+				}
+				c.modules[y*c.size+x] = data[i/8]>>uint(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// masks are the conditions under which each mask inverts a module.
+var masks = [8]func(x, y int) bool{
+	func(x, y int) bool { return (x+y)%2 == 0 },
+	func(x, y int) bool { return y%2 == 0 },
+	func(x, y int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (x+y)%3 == 0 },
+	func(x, y int) bool { return (x/3+y/2)%2 == 0 },
+	func(x, y int) bool { return x*y%2+x*y%3 == 0 },
+	func(x, y int) bool { return (x*y%2+x*y%3)%2 == 0 },
+	func(x, y int) bool { return ((x+y)%2+x*y%3)%2 == 0 },
+}
+
+// applyMask inverts the data modules selected by the given mask. Applying the
+// same mask twice restores the original modules.
+func (c *QRCode) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if i := y*c.size + x; !c.function[i] && masks[mask](x, y) {
+				c.modules[i] = !c.modules[i]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to scan, the lower the better.
+func (c *QRCode) penalty() int {
+	p := 0
+	for i := 0; i < c.size; i++ {
+		row := func(j int) bool { return c.modules[i*c.size+j] }
+		col := func(j int) bool { return c.modules[j*c.size+i] }
+		p += linePenalty(row, c.size) + linePenalty(col, c.size)
+	}
+
+	dark := 0
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			b := c.Black(x, y)
+			if b {
+				dark++
+			}
+			if x+1 < c.size && y+1 < c.size && b == c.Black(x+1, y) && b == c.Black(x, y+1) && b == c.Black(x+1, y+1) {
+				p += 3
+			}
+		}
+	}
+
+	total := c.size * c.size
+	k := (abs(20*dark-10*total)+total-1)/total - 1
+	return p + 10*k
+}
+
+// finderLike are the patterns that look like finders, penalized when found in
+// rows or columns.
+var finderLike = []string{"10111010000", "00001011101"}
+
+// linePenalty scores a row or column of n modules, penalizing long runs of a
+// single color and patterns similar to the finders.
+func linePenalty(at func(int) bool, n int) int {
+	p := 0
+	run := 1
+	for i := 1; i <= n; i++ {
+		if i < n && at(i) == at(i-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			p += 3 + run - 5
+		}
+		run = 1
+	}
+
+	line := make([]byte, n)
+	for i := range line {
+		line[i] = '0'
+		if at(i) {
+			line[i] = '1'
+		}
+	}
+	for _, pattern := range finderLike {
+		p += 40 * strings.Count(string(line), pattern)
+	}
+	return p
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Error correction codewords per block, indexed by level and version.
+var eccPerBlock = [4][41]int{
+	{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// Number of error correction blocks, indexed by level and version.
+var numBlocks = [4][41]int{
+	{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// rawCodewords returns the number of codewords that fit in a code, once the
+// function patterns are drawn.
+func rawCodewords(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n / 8
+}
+
+// dataCodewords returns the number of codewords available for data.
+func dataCodewords(version int, level Level) int {
+	return rawCodewords(version) - eccPerBlock[level][version]*numBlocks[level][version]
+}
+
+// interleave splits data in blocks, adds their error correction codewords, and
+// interleaves them.
+func interleave(data []byte, version int, level Level) []byte {
+	blocks := numBlocks[level][version]
+	ecc := eccPerBlock[level][version]
+	raw := rawCodewords(version)
+	short := blocks - raw%blocks
+	shortLen := raw / blocks
+
+	divisor := qrField.divisor(ecc, 0)
+	var all [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - ecc
+		if i >= short {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		rem := qrField.remainder(block, divisor)
+		if i < short {
+			// Padding to align the blocks, skipped when interleaving.
+			block = append(block, 0)
+		}
+		all = append(all, append(block, rem...))
+	}
+
+	out := make([]byte, 0, raw)
+	for i := range all[0] {
+		for j, block := range all {
+			if i != shortLen-ecc || j >= short {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package barcode
+
+// A field is a Galois field GF(2^8), defined by its reducing polynomial.
+type field uint
+
+// Fields used by QR codes and Data Matrix codes.
+const (
+	qrField         field = 0x11d // x^8 + x^4 + x^3 + x^2 + 1
+	dataMatrixField field = 0x12d // x^8 + x^5 + x^3 + x^2 + 1
+)
+
+// divisor returns the generator polynomial of the Reed-Solomon code with the
+// given degree, whose roots are consecutive powers of 2 starting at 2^first,
+// without its leading term.
+func (f field) divisor(degree, first int) []byte {
+	out := make([]byte, degree)
+	out[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < first; i++ {
+		root = f.mul(root, 2)
+	}
+	for i := 0; i < degree; i++ {
+		for j := range out {
+			out[j] = f.mul(out[j], root)
+			if j+1 < len(out) {
+				out[j] ^= out[j+1]
+			}
+		}
+		root = f.mul(root, 2)
+	}
+	return out
+}
+
+// remainder returns the error correction codewords for data.
+func (f field) remainder(data, divisor []byte) []byte {
+	out := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ out[0]
+		copy(out, out[1:])
+		out[len(out)-1] = 0
+		for i, coef := range divisor {
+			out[i] ^= f.mul(coef, factor)
+		}
+	}
+	return out
+}
+
+func (f field) mul(x, y byte) byte {
+	var z uint
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*uint(f)
+		z ^= uint(y>>uint(i)&1) * uint(x)
+	}
+	return byte(z)
+}
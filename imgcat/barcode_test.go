@@ -0,0 +1,29 @@
+package imgcat
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/barcode"
+)
+
+func TestEncodeBarcode(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	b, err := barcode.EncodeCode128("PALLET-0042")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Inline(true))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.EncodeBarcode(b); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("inline=1;size=")) || !bytes.Contains(buf.Bytes(), []byte(":iVBORw0KGgo")) {
+		t.Fatalf("expected an inline PNG; got %q", buf.Bytes()[:40])
+	}
+}
@@ -0,0 +1,49 @@
+package bitmapfont
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+	tc := []struct {
+		in    string
+		scale int
+		out   image.Point
+	}{
+		{"", 1, image.Pt(0, 7)},
+		{"a", 1, image.Pt(5, 7)},
+		{"ab", 1, image.Pt(11, 7)},
+		{"ab", 2, image.Pt(22, 14)},
+		{"abc\nd", 1, image.Pt(17, 16)},
+		{"a", 0, image.Pt(5, 7)},
+	}
+
+	for _, tt := range tc {
+		if got := Measure(tt.in, tt.scale); got != tt.out {
+			t.Errorf("Measure(%q, %d): expected %v; got %v", tt.in, tt.scale, tt.out, got)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	Draw(img, "|", image.Pt(1, 1), color.White, 2)
+
+	// The vertical bar is the middle column of the glyph, 7 pixels high.
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			want := x >= 5 && x < 7 && y >= 1 && y < 15
+			if got := img.GrayAt(x, y).Y != 0; got != want {
+				t.Fatalf("pixel at %d,%d: expected set %v; got %v", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestUnknownRune(t *testing.T) {
+	if glyph('é') != glyph('?') {
+		t.Fatalf("unknown runes should be drawn as question marks")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// ErrOverBudget is returned by Encode when an image is dropped because its
+// Budget is spent, see WithinBudget.
+var ErrOverBudget = errors.New("over budget")
+
+// minBudgetPayload is the smallest payload images are shrunk to in order to fit
+// in a Budget, smaller ones are dropped.
+const minBudgetPayload = 1024
+
+// A Budget caps the bytes per second written to the terminal by the Encoders
+// sharing it, such as the panels of a dashboard, see WithinBudget. It allows
+// bursts of up to a second worth of bytes, and is safe for concurrent use.
+type Budget struct {
+	rate int
+	mu   sync.Mutex
+	// bytes that can be written right away, as of last.
+	left float64
+	last time.Time
+}
+
+// NewBudget returns a Budget of the given number of bytes per second, starting
+// full.
+func NewBudget(bytesPerSecond int) *Budget {
+	return &Budget{rate: bytesPerSecond, left: float64(bytesPerSecond), last: now()}
+}
+
+// Can be swapped for testing.
+var now = time.Now
+
+// take spends n bytes of the budget if they're left, and returns whether it
+// did along with how many bytes were left.
+func (b *Budget) take(n int) (bool, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := now()
+	b.left += t.Sub(b.last).Seconds() * float64(b.rate)
+	if b.left > float64(b.rate) {
+		b.left = float64(b.rate)
+	}
+	b.last = t
+	if float64(n) > b.left {
+		return false, int(b.left)
+	}
+	b.left -= float64(n)
+	return true, int(b.left)
+}
+
+// WithinBudget makes images spend b by the size of their base64 payload, which
+// is about what's written to the terminal. Images that don't fit in what's
+// left are re-encoded as smaller JPEG images, as with TargetBytes, and
+// dropped with ErrOverBudget if they'd be smaller than a kilobyte or can't be
+// re-encoded. Dashboards can ignore ErrOverBudget and send the next frame.
+func WithinBudget(b *Budget) Option {
+	return func(c *config) { c.budget = b }
+}
+
+// spend takes the payload read from r from the configured budget, shrinking
+// it to what's left if needed.
+func (c *config) spend(r io.Reader) (io.Reader, error) {
+	if c.budget == nil {
+		return r, nil
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	ok, left := c.budget.take(base64.StdEncoding.EncodedLen(len(data)))
+	if ok {
+		return bytes.NewReader(data), nil
+	}
+	// Shrinking is only worth it for images that stay recognizable.
+	n := base64.StdEncoding.DecodedLen(left)
+	if n < minBudgetPayload {
+		c.trace.notef("dropped with %d bytes left in the budget", left)
+		return nil, fmt.Errorf("%d bytes left for a payload of %d: %w", left, len(data), ErrOverBudget)
+	}
+	buf, err := c.shrink(data, n)
+	if err == image.ErrFormat {
+		err = fmt.Errorf("%d bytes left for a payload of %d: %w", left, len(data), ErrOverBudget)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Other Encoders may have spent the budget meanwhile.
+	if ok, left = c.budget.take(base64.StdEncoding.EncodedLen(buf.Len())); !ok {
+		c.trace.notef("dropped with %d bytes left in the budget", left)
+		return nil, fmt.Errorf("%d bytes left for a payload of %d: %w", left, buf.Len(), ErrOverBudget)
+	}
+	return buf, nil
+}
@@ -0,0 +1,99 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	clock := time.Unix(0, 0)
+	now = func() time.Time { return clock }
+
+	b := NewBudget(100)
+	tc := []struct {
+		name    string
+		elapsed time.Duration
+		n       int
+		ok      bool
+		left    int
+	}{
+		{"starts full", 0, 60, true, 40},
+		{"not enough left", 0, 60, false, 40},
+		{"refills", 200 * time.Millisecond, 60, true, 0},
+		{"burst of a second", time.Hour, 100, true, 0},
+		{"more than a second", time.Hour, 101, false, 100},
+	}
+	for _, tt := range tc {
+		clock = clock.Add(tt.elapsed)
+		if ok, left := b.take(tt.n); ok != tt.ok || left != tt.left {
+			t.Errorf("%s: expected %v with %d left; got %v with %d", tt.name, tt.ok, tt.left, ok, left)
+		}
+	}
+}
+
+func TestWithinBudget(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	clock := time.Unix(0, 0)
+	now = func() time.Time { return clock }
+
+	in := noisePNG(t, 64, 64)
+	cost := base64.StdEncoding.EncodedLen(len(in))
+	b := NewBudget(cost + cost/2)
+	encode := func(r *bytes.Reader) (*bytes.Buffer, error) {
+		buf := new(bytes.Buffer)
+		enc := &Encoder{out: buf, options: []Option{Passthrough(false), WithinBudget(b)}}
+		return buf, enc.Encode(r)
+	}
+
+	// The first image fits, the second one is shrunk to what's left.
+	buf, err := encode(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if !strings.Contains(buf.String(), "size="+strconv.Itoa(len(in))+":") {
+		t.Fatalf("expected the image sent as is; got %d bytes", buf.Len())
+	}
+	buf, err = encode(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if buf.Len() == 0 || buf.Len() > cost/2+64 {
+		t.Fatalf("expected the image shrunk to %d bytes; got %d", cost/2, buf.Len())
+	}
+
+	// Later ones are smaller and smaller, until they're dropped.
+	last := buf.Len()
+	for i := 0; ; i++ {
+		buf, err = encode(bytes.NewReader(in))
+		if err != nil {
+			break
+		}
+		if buf.Len() >= last || i > 10 {
+			t.Fatalf("expected images shrinking below %d bytes; got %d", last, buf.Len())
+		}
+		last = buf.Len()
+	}
+	if !errors.Is(err, ErrOverBudget) || buf.Len() != 0 {
+		t.Fatalf("expected ErrOverBudget and nothing written; got %v and %d bytes", err, buf.Len())
+	}
+	clock = clock.Add(time.Second)
+	if _, err := encode(bytes.NewReader(in)); err != nil {
+		t.Fatalf("could not encode once refilled: %v", err)
+	}
+
+	// Payloads that are not images can't be shrunk.
+	b = NewBudget(4)
+	if _, err := encode(bytes.NewReader(make([]byte, 2*minBudgetPayload))); !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("expected ErrOverBudget; got %v", err)
+	}
+	b = NewBudget(4 * minBudgetPayload)
+	if _, err := encode(bytes.NewReader(make([]byte, 4*minBudgetPayload))); !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("expected ErrOverBudget; got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chart
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// A Bar is a labeled value in a bar chart.
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// A BarChart draws values as horizontal bars, one per row, which leaves room
+// for long labels.
+type BarChart struct {
+	Title string
+	Bars  []Bar
+	// Width of the image in pixels, DefaultWidth if zero. The height depends
+	// on the number of bars.
+	Width int
+	// Format formats values written next to the bars, if nil they're written
+	// with strconv.FormatFloat.
+	Format func(float64) string
+	// Color of the bars, the first color of the default palette if nil.
+	Color color.Color
+	// BarColor picks the color of each bar, such as red for regressions and
+	// green for improvements. Color is used if nil, or if it returns nil.
+	BarColor func(Bar) color.Color
+	// Background of the chart, white if nil.
+	Background color.Color
+}
+
+func (c *BarChart) format(v float64) string {
+	if c.Format == nil {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return c.Format(v)
+}
+
+// Draw draws the chart. Bars are scaled so the largest value, or the lowest if
+// all of them are negative, fills the available width.
+func (c *BarChart) Draw() *image.RGBA {
+	w := c.Width
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	scale := w / 400
+	if scale < 1 {
+		scale = 1
+	}
+	margin := 10 * scale
+	row := bitmapfont.Leading * scale * 3 / 2
+	top := margin
+	if c.Title != "" {
+		top += bitmapfont.Measure(c.Title, scale).Y + margin
+	}
+	cv := newCanvas(w, top+len(c.Bars)*row+margin, c.Background)
+	if c.Title != "" {
+		size := bitmapfont.Measure(c.Title, scale)
+		bitmapfont.Draw(cv.img, c.Title, image.Pt((w-size.X)/2, margin), cv.fg, scale)
+	}
+
+	var labelW, valueW int
+	var max float64
+	for _, b := range c.Bars {
+		if lw := bitmapfont.Measure(b.Label, scale).X; lw > labelW {
+			labelW = lw
+		}
+		if vw := bitmapfont.Measure(c.format(b.Value), scale).X; vw > valueW {
+			valueW = vw
+		}
+		max = math.Max(max, math.Abs(b.Value))
+	}
+	// Labels take at most half of the width.
+	maxLabelW := (w - 2*margin) / 2
+	if labelW > maxLabelW {
+		labelW = maxLabelW
+	}
+	barX := margin + labelW + margin
+	barW := w - barX - valueW - 2*margin
+
+	barColor := c.Color
+	if barColor == nil {
+		barColor = palette[0]
+	}
+	cv.fill(image.Rect(barX-scale, top, barX, top+len(c.Bars)*row), cv.fg)
+	for i, b := range c.Bars {
+		y := top + i*row
+		label := truncate(b.Label, labelW, scale)
+		bitmapfont.Draw(cv.img, label, image.Pt(barX-margin-bitmapfont.Measure(label, scale).X, y+row/4), cv.fg, scale)
+		length := 0
+		if max > 0 && barW > 0 {
+			length = int(math.Round(math.Abs(b.Value) / max * float64(barW)))
+		}
+		fill := barColor
+		if c.BarColor != nil {
+			if bc := c.BarColor(b); bc != nil {
+				fill = bc
+			}
+		}
+		cv.fill(image.Rect(barX, y+row/8, barX+length, y+row-row/8), fill)
+		bitmapfont.Draw(cv.img, c.format(b.Value), image.Pt(barX+length+margin/2, y+row/4), cv.fg, scale)
+	}
+	return cv.img
+}
+
+// truncate shortens s so it fits in w pixels at the given scale.
+func truncate(s string, w, scale int) string {
+	max := (w/scale + 1) / bitmapfont.Advance
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	if max <= 3 {
+		return string(r[:max])
+	}
+	return string(r[:max-3]) + "..."
+}
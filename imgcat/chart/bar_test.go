@@ -0,0 +1,87 @@
+package chart
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBarChart(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	c := &BarChart{
+		Title: "sizes",
+		Width: 400,
+		Color: red,
+		Bars:  []Bar{{"small", 1}, {"large", 4}, {"none", 0}},
+	}
+	img := c.Draw()
+	if got := img.Bounds().Dx(); got != 400 {
+		t.Fatalf("expected width 400; got %d", got)
+	}
+
+	// Measure the length of each bar on its middle row. At scale 1 rows are
+	// 13 pixels high and start after the 7 pixels high title and margins.
+	const top, row = 10 + 7 + 10, 13
+	var lengths []int
+	for i := range c.Bars {
+		y := top + i*row + row/2
+		n := 0
+		for x := 0; x < 400; x++ {
+			if img.RGBAAt(x, y) == red {
+				n++
+			}
+		}
+		lengths = append(lengths, n)
+	}
+	if lengths[2] != 0 || lengths[0] == 0 || lengths[1] < 3*lengths[0] {
+		t.Fatalf("unexpected bar lengths %v", lengths)
+	}
+}
+
+func TestBarColor(t *testing.T) {
+	red, green := color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0, 0xff, 0, 0xff}
+	c := &BarChart{
+		Width: 400,
+		Color: red,
+		Bars:  []Bar{{"slower", 10}, {"faster", -10}},
+		BarColor: func(b Bar) color.Color {
+			if b.Value < 0 {
+				return green
+			}
+			return nil
+		},
+	}
+	img := c.Draw()
+	// Without a title, rows start after the top margin.
+	const top, row = 10, 13
+	for i, want := range []color.RGBA{red, green} {
+		y := top + i*row + row/2
+		n := 0
+		for x := 0; x < 400; x++ {
+			if img.RGBAAt(x, y) == want {
+				n++
+			}
+		}
+		if n == 0 {
+			t.Errorf("expected bar %q drawn in %v", c.Bars[i].Label, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tc := []struct {
+		in  string
+		w   int
+		out string
+	}{
+		{"hello", 100, "hello"},
+		{"hello", 29, "hello"},
+		{"hello world", 29, "he..."},
+		{"hello", 12, "he"},
+	}
+
+	for _, tt := range tc {
+		if got := truncate(tt.in, tt.w, 1); got != tt.out {
+			t.Errorf("truncate(%q, %d): expected %q; got %q", tt.in, tt.w, tt.out, got)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chart draws simple charts as images, so they can be displayed in the
+// terminal with imgcat.
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"time"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/colormap"
+)
+
+// Kind is the way series are drawn.
+type Kind int
+
+// Kinds of charts.
+const (
+	Line Kind = iota
+	Scatter
+)
+
+// A Series is a sequence of points. Points with NaN coordinates are skipped,
+// breaking lines in two.
+type Series struct {
+	Name string
+	X, Y []float64
+}
+
+// Default size of charts in pixels.
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 480
+)
+
+// A Chart holds the data and settings of a chart.
+type Chart struct {
+	Title  string
+	Kind   Kind
+	Series []Series
+	// XTime formats x values as times, given in seconds since the Unix epoch.
+	XTime bool
+	// Size of the image in pixels, DefaultWidth and DefaultHeight if zero.
+	Width, Height int
+	// Colors picks the color of each series. If nil, a palette of ten
+	// distinct colors is used.
+	Colors colormap.Colormap
+	// Background of the chart, white if nil. Text and axes are drawn in
+	// black or white, whichever contrasts best.
+	Background color.Color
+}
+
+// palette is used when no colormap is given, it matches matplotlib's.
+var palette = []color.RGBA{
+	{0x1f, 0x77, 0xb4, 0xff}, {0xff, 0x7f, 0x0e, 0xff}, {0x2c, 0xa0, 0x2c, 0xff},
+	{0xd6, 0x27, 0x28, 0xff}, {0x94, 0x67, 0xbd, 0xff}, {0x8c, 0x56, 0x4b, 0xff},
+	{0xe3, 0x77, 0xc2, 0xff}, {0x7f, 0x7f, 0x7f, 0xff}, {0xbc, 0xbd, 0x22, 0xff},
+	{0x17, 0xbe, 0xcf, 0xff},
+}
+
+// color returns the color of the i-th series.
+func (c *Chart) color(i int) color.Color {
+	if c.Colors == nil {
+		return palette[i%len(palette)]
+	}
+	if len(c.Series) < 2 {
+		return c.Colors(0)
+	}
+	return c.Colors(float64(i) / float64(len(c.Series)-1))
+}
+
+// canvas holds what's needed while drawing a chart.
+type canvas struct {
+	img    *image.RGBA
+	fg     color.Color
+	grid   color.Color
+	scale  int
+	plot   image.Rectangle
+	xr, yr span
+}
+
+// span is a range of values.
+type span struct{ lo, hi float64 }
+
+func (s span) size() float64 { return s.hi - s.lo }
+
+func (c *Chart) size() (int, int) {
+	w, h := c.Width, c.Height
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if h <= 0 {
+		h = DefaultHeight
+	}
+	return w, h
+}
+
+// Draw draws the chart.
+func (c *Chart) Draw() *image.RGBA {
+	w, h := c.size()
+	cv := newCanvas(w, h, c.Background)
+
+	var xs, ys []float64
+	for _, s := range c.Series {
+		xs, ys = append(xs, s.X...), append(ys, s.Y...)
+	}
+	cv.xr = dataSpan(xs, 0)
+	cv.yr = dataSpan(ys, 0.05)
+	xticks := numTicks(cv.xr, 8)
+	if c.XTime {
+		xticks = timeTicks(cv.xr, 6)
+	}
+	yticks := numTicks(cv.yr, 6)
+
+	cv.layout(c.Title, yticks)
+	cv.axes(xticks, yticks, c.XTime)
+	for i, s := range c.Series {
+		cv.series(s, c.Kind, c.color(i))
+	}
+	if len(c.Series) > 1 {
+		cv.legend(c)
+	}
+	return cv.img
+}
+
+func newCanvas(w, h int, bg color.Color) *canvas {
+	if bg == nil {
+		bg = color.White
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	fg, grid := color.Color(color.Black), color.Color(color.RGBA{0xdd, 0xdd, 0xdd, 0xff})
+	if color.GrayModel.Convert(bg).(color.Gray).Y < 0x80 {
+		fg, grid = color.White, color.RGBA{0x44, 0x44, 0x44, 0xff}
+	}
+	scale := w / 400
+	if scale < 1 {
+		scale = 1
+	}
+	return &canvas{img: img, fg: fg, grid: grid, scale: scale}
+}
+
+// layout computes the area where data is plotted, leaving room for the title
+// and tick labels, and draws the title.
+func (cv *canvas) layout(title string, yticks []float64) {
+	s := cv.scale
+	margin := 10 * s
+	left := 0
+	for _, v := range yticks {
+		if w := bitmapfont.Measure(formatNum(v, yticks), s).X; w > left {
+			left = w
+		}
+	}
+	top := margin
+	if title != "" {
+		size := bitmapfont.Measure(title, s)
+		bitmapfont.Draw(cv.img, title, image.Pt((cv.img.Bounds().Dx()-size.X)/2, margin), cv.fg, s)
+		top += size.Y + margin
+	}
+	b := cv.img.Bounds()
+	bottom := bitmapfont.Leading*s + 2*margin
+	cv.plot = image.Rect(left+2*margin, top, b.Max.X-2*margin, b.Max.Y-bottom)
+}
+
+// axes draws the grid, the axes, and the tick labels.
+func (cv *canvas) axes(xticks, yticks []float64, xtime bool) {
+	s, p := cv.scale, cv.plot
+	for _, v := range xticks {
+		x := cv.px(v)
+		cv.fill(image.Rect(x, p.Min.Y, x+1, p.Max.Y), cv.grid)
+		label := formatNum(v, xticks)
+		if xtime {
+			label = formatTime(v, xticks)
+		}
+		size := bitmapfont.Measure(label, s)
+		bitmapfont.Draw(cv.img, label, image.Pt(x-size.X/2, p.Max.Y+5*s), cv.fg, s)
+	}
+	for _, v := range yticks {
+		y := cv.py(v)
+		cv.fill(image.Rect(p.Min.X, y, p.Max.X, y+1), cv.grid)
+		label := formatNum(v, yticks)
+		size := bitmapfont.Measure(label, s)
+		bitmapfont.Draw(cv.img, label, image.Pt(p.Min.X-5*s-size.X, y-size.Y/2), cv.fg, s)
+	}
+	cv.fill(image.Rect(p.Min.X-s, p.Min.Y, p.Min.X, p.Max.Y+s), cv.fg)
+	cv.fill(image.Rect(p.Min.X-s, p.Max.Y, p.Max.X, p.Max.Y+s), cv.fg)
+}
+
+// series draws the points of s in the given color.
+func (cv *canvas) series(s Series, kind Kind, c color.Color) {
+	t := cv.scale + 1
+	prev, ok := image.Point{}, false
+	for i := 0; i < len(s.X) && i < len(s.Y); i++ {
+		if math.IsNaN(s.X[i]) || math.IsNaN(s.Y[i]) {
+			ok = false
+			continue
+		}
+		pt := image.Pt(cv.px(s.X[i]), cv.py(s.Y[i]))
+		switch kind {
+		case Scatter:
+			cv.dot(pt, 2*t, c)
+		default:
+			if ok {
+				cv.line(prev, pt, t, c)
+			} else {
+				cv.dot(pt, t/2, c)
+			}
+		}
+		prev, ok = pt, true
+	}
+}
+
+// legend draws the name and color of each series on the top right corner.
+func (cv *canvas) legend(c *Chart) {
+	s := cv.scale
+	width := 0
+	for _, series := range c.Series {
+		if w := bitmapfont.Measure(series.Name, s).X; w > width {
+			width = w
+		}
+	}
+	swatch := bitmapfont.Height * s
+	x := cv.plot.Max.X - width - swatch - 10*s
+	y := cv.plot.Min.Y + 5*s
+	for i, series := range c.Series {
+		cv.fill(image.Rect(x, y, x+swatch, y+swatch), c.color(i))
+		bitmapfont.Draw(cv.img, series.Name, image.Pt(x+swatch+4*s, y), cv.fg, s)
+		y += bitmapfont.Leading * s
+	}
+}
+
+// px and py convert values to pixel coordinates.
+func (cv *canvas) px(v float64) int {
+	return cv.plot.Min.X + int(math.Round((v-cv.xr.lo)/cv.xr.size()*float64(cv.plot.Dx()-1)))
+}
+
+func (cv *canvas) py(v float64) int {
+	return cv.plot.Max.Y - 1 - int(math.Round((v-cv.yr.lo)/cv.yr.size()*float64(cv.plot.Dy()-1)))
+}
+
+func (cv *canvas) fill(r image.Rectangle, c color.Color) {
+	draw.Draw(cv.img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// dot draws a filled circle.
+func (cv *canvas) dot(p image.Point, radius int, c color.Color) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				cv.img.Set(p.X+x, p.Y+y, c)
+			}
+		}
+	}
+}
+
+// line draws a line t pixels thick from a to b.
+func (cv *canvas) line(a, b image.Point, t int, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := abs(dx)
+	if abs(dy) > steps {
+		steps = abs(dy)
+	}
+	for i := 0; i <= steps; i++ {
+		x, y := a.X, a.Y
+		if steps > 0 {
+			x += dx * i / steps
+			y += dy * i / steps
+		}
+		cv.fill(image.Rect(x-t/2, y-t/2, x-t/2+t, y-t/2+t), c)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// dataSpan returns the range of the given values, ignoring NaN, extended by
+// the given fraction on both sides. Empty ranges are widened so they can be
+// plotted.
+func dataSpan(vs []float64, pad float64) span {
+	s := span{math.Inf(1), math.Inf(-1)}
+	for _, v := range vs {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			s.lo, s.hi = math.Min(s.lo, v), math.Max(s.hi, v)
+		}
+	}
+	switch {
+	case s.lo > s.hi:
+		return span{0, 1}
+	case s.lo == s.hi:
+		d := math.Max(math.Abs(s.lo)*0.1, 1)
+		return span{s.lo - d, s.hi + d}
+	}
+	d := s.size() * pad
+	return span{s.lo - d, s.hi + d}
+}
+
+// numTicks returns about n round values in s, at regular intervals.
+func numTicks(s span, n int) []float64 {
+	raw := s.size() / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := 10 * mag
+	for _, m := range []float64{1, 2, 5} {
+		if m*mag >= raw {
+			step = m * mag
+			break
+		}
+	}
+	return stepTicks(s, step)
+}
+
+// timeSteps are the intervals, in seconds, used for ticks on time axes.
+var timeSteps = []float64{
+	1, 2, 5, 10, 15, 30,
+	60, 2 * 60, 5 * 60, 10 * 60, 15 * 60, 30 * 60,
+	3600, 2 * 3600, 3 * 3600, 6 * 3600, 12 * 3600,
+	86400, 2 * 86400, 7 * 86400, 14 * 86400, 30 * 86400, 91 * 86400, 365 * 86400,
+}
+
+// timeTicks is like numTicks for values in seconds, using intervals that are
+// round in time units.
+func timeTicks(s span, n int) []float64 {
+	raw := s.size() / float64(n)
+	for _, step := range timeSteps {
+		if step >= raw {
+			return stepTicks(s, step)
+		}
+	}
+	return numTicks(s, n)
+}
+
+func stepTicks(s span, step float64) []float64 {
+	var ticks []float64
+	for i := math.Ceil(s.lo / step); i*step <= s.hi; i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks
+}
+
+// formatNum formats v with enough decimals to tell the ticks apart.
+func formatNum(v float64, ticks []float64) string {
+	if v == 0 {
+		return "0"
+	}
+	if a := math.Abs(v); a >= 1e6 || a < 1e-4 {
+		return fmt.Sprintf("%.3g", v)
+	}
+	decimals := 0
+	if len(ticks) > 1 {
+		if d := -int(math.Floor(math.Log10(ticks[1] - ticks[0]))); d > 0 {
+			decimals = d
+		}
+	}
+	return fmt.Sprintf("%.*f", decimals, v)
+}
+
+// formatTime formats v, in seconds since the epoch, with the precision needed
+// to tell the ticks apart.
+func formatTime(v float64, ticks []float64) string {
+	t := time.Unix(int64(v), 0)
+	step := 1.0
+	if len(ticks) > 1 {
+		step = ticks[1] - ticks[0]
+	}
+	switch {
+	case step < 60:
+		return t.Format("15:04:05")
+	case step < 86400:
+		return t.Format("15:04")
+	case step < 365*86400:
+		return t.Format("Jan 2")
+	}
+	return t.Format("2006")
+}
@@ -0,0 +1,125 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNumTicks(t *testing.T) {
+	tc := []struct {
+		s     span
+		n     int
+		ticks []float64
+	}{
+		{span{0, 10}, 5, []float64{0, 2, 4, 6, 8, 10}},
+		{span{-0.3, 0.3}, 3, []float64{-0.2, 0, 0.2}},
+		{span{1, 9}, 2, []float64{5}},
+		{span{1230, 1770}, 5, []float64{1400, 1600}},
+	}
+
+	for _, tt := range tc {
+		if got := numTicks(tt.s, tt.n); !reflect.DeepEqual(got, tt.ticks) {
+			t.Errorf("numTicks(%v, %d): expected %v; got %v", tt.s, tt.n, tt.ticks, got)
+		}
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	got := timeTicks(span{0, 3600}, 6)
+	want := []float64{0, 600, 1200, 1800, 2400, 3000, 3600}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
+
+func TestFormatNum(t *testing.T) {
+	tc := []struct {
+		v     float64
+		ticks []float64
+		out   string
+	}{
+		{0, []float64{0, 1}, "0"},
+		{0.2, []float64{0, 0.2}, "0.2"},
+		{0.25, []float64{0, 0.05}, "0.25"},
+		{1500, []float64{1000, 1500}, "1500"},
+		{2e9, []float64{0, 1e9}, "2e+09"},
+	}
+
+	for _, tt := range tc {
+		if got := formatNum(tt.v, tt.ticks); got != tt.out {
+			t.Errorf("formatNum(%v): expected %q; got %q", tt.v, tt.out, got)
+		}
+	}
+}
+
+func TestDataSpan(t *testing.T) {
+	tc := []struct {
+		in  []float64
+		pad float64
+		out span
+	}{
+		{nil, 0, span{0, 1}},
+		{[]float64{math.NaN()}, 0, span{0, 1}},
+		{[]float64{5}, 0, span{4, 6}},
+		{[]float64{1, 3, math.NaN(), 2}, 0, span{1, 3}},
+		{[]float64{0, 10}, 0.1, span{-1, 11}},
+	}
+
+	for _, tt := range tc {
+		if got := dataSpan(tt.in, tt.pad); got != tt.out {
+			t.Errorf("dataSpan(%v): expected %v; got %v", tt.in, tt.out, got)
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	c := &Chart{
+		Title:  "test",
+		Width:  400,
+		Height: 300,
+		Series: []Series{
+			{Name: "up", X: []float64{0, 1, 2}, Y: []float64{0, 1, 2}},
+			{Name: "down", X: []float64{0, 1, 2}, Y: []float64{2, 1, 0}},
+		},
+	}
+	img := c.Draw()
+	if got := img.Bounds().Size(); got != image.Pt(400, 300) {
+		t.Fatalf("expected size 400x300; got %v", got)
+	}
+
+	count := map[color.RGBA]int{}
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			count[img.RGBAAt(x, y)]++
+		}
+	}
+	for i := range c.Series {
+		if count[palette[i]] == 0 {
+			t.Errorf("series %d was not drawn", i)
+		}
+	}
+	if count[color.RGBA{0xff, 0xff, 0xff, 0xff}] == 0 || count[color.RGBA{0, 0, 0, 0xff}] == 0 {
+		t.Errorf("expected black on white")
+	}
+}
+
+func TestDrawDark(t *testing.T) {
+	c := &Chart{Width: 100, Height: 100, Background: color.Black, Kind: Scatter,
+		Series: []Series{{X: []float64{1}, Y: []float64{1}}}}
+	img := c.Draw()
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Errorf("expected black background; got %v", got)
+	}
+	found := false
+	for y := 0; y < 100 && !found; y++ {
+		for x := 0; x < 100 && !found; x++ {
+			found = img.RGBAAt(x, y) == color.RGBA{0xff, 0xff, 0xff, 0xff}
+		}
+	}
+	if !found {
+		t.Errorf("expected white axes on a dark background")
+	}
+}
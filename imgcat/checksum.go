@@ -0,0 +1,47 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrChecksum is returned by Verify when the content doesn't match the checksum.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Checksum calls fn with the SHA-256 of the payload once it has been completely
+// sent. Long transfers in download mode, see Inline, can get silently corrupted
+// through tmux or ssh, so the reported sum can be used to verify the downloaded
+// file with Verify or any sha256sum tool.
+// If the payload is transformed before being sent, the sum is computed on the
+// transformed payload.
+func Checksum(fn func(sum []byte)) Option {
+	return func(c *config) { c.checksum = fn }
+}
+
+// Verify checks that the SHA-256 of the content read from r matches sum.
+func Verify(r io.Reader, sum []byte) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("could not read content: %v", err)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, sum) {
+		return fmt.Errorf("expected %x; got %x: %w", sum, got, ErrChecksum)
+	}
+	return nil
+}
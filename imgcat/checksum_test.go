@@ -0,0 +1,54 @@
+package imgcat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	var got []byte
+	enc, err := NewEncoder(new(bytes.Buffer), Inline(false), Checksum(func(sum []byte) { got = sum }))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	want := sha256.Sum256([]byte("test"))
+	if !bytes.Equal(got, want[:]) {
+		t.Fatalf("expected checksum %x; got %x", want, got)
+	}
+}
+
+func TestChecksumNotReportedOnFailure(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	called := false
+	enc, err := NewEncoder(badWriter{}, Checksum(func([]byte) { called = true }))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.Encode(strings.NewReader("test")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+	if called {
+		t.Fatalf("checksum reported for a failed transfer")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	sum := sha256.Sum256([]byte("test"))
+	if err := Verify(strings.NewReader("test"), sum[:]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Verify(strings.NewReader("tset"), sum[:]); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("expected checksum mismatch; got %v", err)
+	}
+}
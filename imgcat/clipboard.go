@@ -0,0 +1,42 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// MaxClipboardBytes is the largest payload CopyToClipboard sends. Terminals
+// drop larger OSC 52 sequences, or hang parsing them.
+const MaxClipboardBytes = 1 << 20
+
+// CopyToClipboard puts the payload read from r, such as an image displayed
+// with an Encoder, on the clipboard with OSC 52, which also works over SSH.
+// Payloads of more than MaxClipboardBytes are rejected with ErrTooLarge,
+// without writing anything. Terminals must allow programs to access the
+// clipboard, which iTerm2 asks for in its General preferences.
+func CopyToClipboard(r io.Reader) error {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxClipboardBytes+1))
+	if err != nil {
+		return fmt.Errorf("could not read payload: %v", err)
+	}
+	if len(data) > MaxClipboardBytes {
+		return fmt.Errorf("clipboard takes at most %d bytes: %w", MaxClipboardBytes, ErrTooLarge)
+	}
+	_, err = io.WriteString(stdout, passthrough("\x1b]52;c;"+base64.StdEncoding.EncodeToString(data)+"\a", IsTmux()))
+	return err
+}
@@ -0,0 +1,54 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	defer func(old io.Writer) { stdout = old }(stdout)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+
+	tc := []struct {
+		name string
+		tmux string
+		want string
+	}{
+		{"terminal", "false", "\x1b]52;c;dGVzdA==\a"},
+		{"tmux", "true", "\x1bPtmux;\x1b\x1b]52;c;dGVzdA==\a\x1b\\"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			check(t, os.Setenv("TMUX_TEST", tt.tmux))
+			buf := new(bytes.Buffer)
+			stdout = buf
+			if err := CopyToClipboard(strings.NewReader("test")); err != nil {
+				t.Fatalf("could not copy: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCopyToClipboardTooLarge(t *testing.T) {
+	defer func(old io.Writer) { stdout = old }(stdout)
+	buf := new(bytes.Buffer)
+	stdout = buf
+
+	err := CopyToClipboard(bytes.NewReader(make([]byte, MaxClipboardBytes+1)))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge; got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing written; got %d bytes", buf.Len())
+	}
+	if err := CopyToClipboard(bytes.NewReader(make([]byte, MaxClipboardBytes))); err != nil {
+		t.Fatalf("could not copy: %v", err)
+	}
+}
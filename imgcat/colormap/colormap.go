@@ -0,0 +1,134 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package colormap provides palettes commonly used in scientific plots to map
+// values to colors, such as the ones in matplotlib.
+package colormap
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// A Colormap maps values between 0 and 1 to colors.
+// Values out of that range get the color of the closest end.
+type Colormap func(t float64) color.Color
+
+// Standard colormaps.
+var (
+	// Viridis is perceptually uniform, going from dark purple to yellow.
+	Viridis = hex(0x440154, 0x482475, 0x414487, 0x355f8d, 0x2a788e, 0x21918c,
+		0x22a884, 0x44bf70, 0x7ad151, 0xbddf26, 0xfde725)
+
+	// Magma is perceptually uniform, going from black to light yellow.
+	Magma = hex(0x000004, 0x140e36, 0x3b0f70, 0x641a80, 0x8c2981, 0xb73779,
+		0xde4968, 0xf7705c, 0xfe9f6d, 0xfecf92, 0xfcfdbf)
+
+	// Plasma is perceptually uniform, going from dark blue to yellow.
+	Plasma = hex(0x0d0887, 0x41049d, 0x6a00a8, 0x8f0da4, 0xb12a90, 0xcc4778,
+		0xe16462, 0xf2844b, 0xfca636, 0xfcce25, 0xf0f921)
+
+	// Turbo is a rainbow colormap with smooth transitions, going from dark
+	// blue to dark red.
+	Turbo Colormap = turbo
+
+	// Coolwarm is diverging, going from blue to red through light gray.
+	// It's useful for values with a meaningful middle, such as zero.
+	Coolwarm = hex(0x3b4cc0, 0x6282ea, 0x8db0fe, 0xb8d0f9, 0xdddddd,
+		0xf5c4ad, 0xf49a7b, 0xde604d, 0xb40426)
+
+	// Grayscale goes from black to white.
+	Grayscale = Gradient(color.Black, color.White)
+)
+
+var byName = map[string]Colormap{
+	"viridis":   Viridis,
+	"magma":     Magma,
+	"plasma":    Plasma,
+	"turbo":     Turbo,
+	"coolwarm":  Coolwarm,
+	"grayscale": Grayscale,
+}
+
+// Lookup returns the standard colormap with the given name, in lower case.
+func Lookup(name string) (Colormap, error) {
+	if c, ok := byName[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown colormap %q, use one of %v", name, Names())
+}
+
+// Names returns the names of the standard colormaps, sorted.
+func Names() []string {
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reverse returns a colormap with the colors of c in reverse order.
+func Reverse(c Colormap) Colormap {
+	return func(t float64) color.Color { return c(1 - t) }
+}
+
+// Gradient returns a colormap interpolating linearly between the given colors,
+// placed at regular intervals.
+func Gradient(stops ...color.Color) Colormap {
+	rgba := make([]color.RGBA, len(stops))
+	for i, s := range stops {
+		rgba[i] = color.RGBAModel.Convert(s).(color.RGBA)
+	}
+	return func(t float64) color.Color {
+		if len(rgba) == 1 {
+			return rgba[0]
+		}
+		t = clamp(t) * float64(len(rgba)-1)
+		i := int(t)
+		if i == len(rgba)-1 {
+			i--
+		}
+		a, b, f := rgba[i], rgba[i+1], t-float64(i)
+		mix := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f)) }
+		return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+	}
+}
+
+// hex returns a gradient between colors given as 0xRRGGBB.
+func hex(stops ...uint32) Colormap {
+	colors := make([]color.Color, len(stops))
+	for i, s := range stops {
+		colors[i] = color.RGBA{uint8(s >> 16), uint8(s >> 8), uint8(s), 0xff}
+	}
+	return Gradient(colors...)
+}
+
+// turbo uses a polynomial approximation of the colormap.
+func turbo(t float64) color.Color {
+	t = clamp(t)
+	r := 0.13572138 + t*(4.61539260+t*(-42.66032258+t*(132.13108234+t*(-152.94239396+t*59.28637943))))
+	g := 0.09140261 + t*(2.19418839+t*(4.84296658+t*(-14.18503333+t*(4.27729857+t*2.82956604))))
+	b := 0.10667330 + t*(12.64194608+t*(-60.58204836+t*(110.36276771+t*(-89.90310912+t*27.34824973))))
+	c := func(v float64) uint8 { return uint8(math.Round(clamp(v) * 0xff)) }
+	return color.RGBA{c(r), c(g), c(b), 0xff}
+}
+
+func clamp(t float64) float64 {
+	if math.IsNaN(t) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, t))
+}
@@ -0,0 +1,52 @@
+package colormap
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColormaps(t *testing.T) {
+	tc := []struct {
+		name string
+		cmap Colormap
+		t    float64
+		out  color.RGBA
+	}{
+		{"viridis start", Viridis, 0, color.RGBA{0x44, 0x01, 0x54, 0xff}},
+		{"viridis end", Viridis, 1, color.RGBA{0xfd, 0xe7, 0x25, 0xff}},
+		{"viridis over", Viridis, 2, color.RGBA{0xfd, 0xe7, 0x25, 0xff}},
+		{"viridis under", Viridis, -1, color.RGBA{0x44, 0x01, 0x54, 0xff}},
+		{"magma middle", Magma, 0.5, color.RGBA{0xb7, 0x37, 0x79, 0xff}},
+		{"magma interpolated", Magma, 0.05, color.RGBA{0x0a, 0x07, 0x1d, 0xff}},
+		{"plasma start", Plasma, 0, color.RGBA{0x0d, 0x08, 0x87, 0xff}},
+		{"turbo start", Turbo, 0, color.RGBA{35, 23, 27, 0xff}},
+		{"turbo middle", Turbo, 0.5, color.RGBA{150, 250, 80, 0xff}},
+		{"turbo end", Turbo, 1, color.RGBA{144, 13, 0, 0xff}},
+		{"coolwarm middle", Coolwarm, 0.5, color.RGBA{0xdd, 0xdd, 0xdd, 0xff}},
+		{"grayscale middle", Grayscale, 0.5, color.RGBA{0x80, 0x80, 0x80, 0xff}},
+		{"reversed", Reverse(Grayscale), 0, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tt := range tc {
+		if got := tt.cmap(tt.t); got != tt.out {
+			t.Errorf("%s: expected %v; got %v", tt.name, tt.out, got)
+		}
+	}
+}
+
+func TestGradientSingleColor(t *testing.T) {
+	if got := Gradient(color.White)(0.3); got != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Fatalf("expected white; got %v", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		if _, err := Lookup(name); err != nil {
+			t.Errorf("could not lookup %s: %v", name, err)
+		}
+	}
+	if _, err := Lookup("jet"); err == nil {
+		t.Errorf("expected error for unknown colormap")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Computed returns an option resolved by calling fn with the payload of every
+// image sent, rather than once for the Encoder, for options that depend on
+// each image, such as Size or Name. It's resolved in order with the other
+// options, so the ones given after it win. fn must not read from r, and can
+// return nil for no option.
+func Computed(fn func(r io.Reader) Option) Option {
+	return func(c *config) {
+		if c.payload == nil {
+			return
+		}
+		if o := fn(c.payload); o != nil {
+			o(c)
+		}
+	}
+}
+
+// SizeFromReader sets the size of every image from its payload, for readers
+// that know how much is left to read, such as *bytes.Buffer, or that can
+// seek, such as files. These last ones get their size without it too.
+func SizeFromReader() Option {
+	return Computed(func(r io.Reader) Option {
+		if l, ok := r.(interface{ Len() int }); ok {
+			return Size(l.Len())
+		}
+		if size, err := seekSize(r); err == nil && size >= 0 {
+			return Size(int(size))
+		}
+		return nil
+	})
+}
+
+// NameFromFile sets the name of every image read from a regular file, such as
+// an *os.File, to the base of the file name.
+func NameFromFile() Option {
+	return Computed(func(r io.Reader) Option {
+		if name := fileName(r); name != "" {
+			return Name(name)
+		}
+		return nil
+	})
+}
+
+// fileName returns the base of the name of the regular file r reads, empty if
+// it doesn't read one.
+func fileName(r io.Reader) string {
+	if path := filePath(r); path != "" {
+		return filepath.Base(path)
+	}
+	return ""
+}
+
+// filePath returns the name of the regular file r reads, as given to open it,
+// empty if it doesn't read one.
+func filePath(r io.Reader) string {
+	f, ok := r.(interface {
+		Name() string
+		Stat() (os.FileInfo, error)
+	})
+	if !ok {
+		return ""
+	}
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return f.Name()
+}
@@ -0,0 +1,102 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputed(t *testing.T) {
+	calls := 0
+	width := Computed(func(r io.Reader) Option {
+		calls++
+		return Width(Cells(r.(*strings.Reader).Len()))
+	})
+	none := Computed(func(io.Reader) Option { return nil })
+	options := []Option{Inline(true), width, none, Height(Cells(2))}
+
+	for _, in := range []string{"test", "longer"} {
+		r := strings.NewReader(in)
+		cfg := configFor(r, options)
+		if _, err := cfg.prepare(r); err != nil {
+			t.Fatalf("could not prepare: %v", err)
+		}
+		want := fmt.Sprintf("inline=1;width=%d;height=2;size=%d", len(in), len(in))
+		if got := strings.Join(cfg.args, ";"); got != want {
+			t.Errorf("expected arguments %s; got %s", want, got)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected option to be computed for every image; got %d calls", calls)
+	}
+
+	// Options given after computed ones win.
+	r := strings.NewReader("test")
+	cfg := configFor(r, []Option{width, Width(Cells(1))})
+	if _, err := cfg.prepare(r); err != nil {
+		t.Fatalf("could not prepare: %v", err)
+	}
+	if got := strings.Join(cfg.args, ";"); got != "width=1;size=4" {
+		t.Errorf("expected arguments width=1;size=4; got %s", got)
+	}
+}
+
+func TestSizeFromReader(t *testing.T) {
+	tc := []struct {
+		name string
+		in   io.Reader
+		want string
+	}{
+		{"buffer", bytes.NewBufferString("test"), "size=4"},
+		{"seeker", strings.NewReader("test"), "size=4"},
+		{"pipe", struct{ io.Reader }{strings.NewReader("test")}, ""},
+	}
+	for _, tt := range tc {
+		cfg := configFor(tt.in, []Option{SizeFromReader()})
+		if _, err := cfg.prepare(tt.in); err != nil {
+			t.Fatalf("%s: could not prepare: %v", tt.name, err)
+		}
+		if got := strings.Join(cfg.args, ";"); got != tt.want {
+			t.Errorf("%s: expected arguments %q; got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNameFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "imgcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cat.png")
+	check(t, ioutil.WriteFile(path, []byte("test"), 0644))
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tc := []struct {
+		name string
+		in   io.Reader
+		want string
+	}{
+		{"file", f, "name=" + base64.StdEncoding.EncodeToString([]byte("cat.png")) + ";size=4"},
+		{"not a file", struct{ io.Reader }{strings.NewReader("test")}, ""},
+	}
+	for _, tt := range tc {
+		cfg := configFor(tt.in, []Option{NameFromFile()})
+		if _, err := cfg.prepare(tt.in); err != nil {
+			t.Fatalf("%s: could not prepare: %v", tt.name, err)
+		}
+		if got := strings.Join(cfg.args, ";"); got != tt.want {
+			t.Errorf("%s: expected arguments %q; got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/barcode"
+)
+
+// qrScale is the size in pixels of the modules of QR codes shown by ConfirmQR.
+const qrScale = 8
+
+// ConfirmImage shows img on w, followed by prompt, and waits for the user to
+// answer on in. This is useful in authentication flows, to show a provisioning
+// or verification image before continuing. If the terminal doesn't support
+// images, fallback is written instead.
+// It returns false if the answer starts with n or N, true otherwise.
+func ConfirmImage(w io.Writer, in io.Reader, img image.Image, fallback, prompt string) (bool, error) {
+	if IsSupported() {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, img); err != nil {
+			return false, fmt.Errorf("could not encode image: %v", err)
+		}
+		enc := &Encoder{out: w, options: []Option{Inline(true)}}
+		if err := enc.Encode(buf); err != nil {
+			return false, err
+		}
+	} else if _, err := io.WriteString(w, fallback); err != nil {
+		return false, err
+	}
+
+	if _, err := io.WriteString(w, prompt); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return false, errors.New("no answer from the user")
+	}
+	answer = strings.TrimSpace(answer)
+	return !strings.HasPrefix(strings.ToLower(answer), "n"), nil
+}
+
+// ConfirmQR shows data as a QR code, followed by prompt, and waits for the
+// user to answer, see ConfirmImage. This is useful for otpauth:// provisioning
+// URIs of TOTP secrets, or verification URLs of OAuth device flows. If the
+// terminal doesn't support images, the code is drawn with text, followed by
+// data itself so it can be copied.
+func ConfirmQR(w io.Writer, in io.Reader, data, prompt string) (bool, error) {
+	c, err := barcode.EncodeQR([]byte(data), barcode.Medium)
+	if err != nil {
+		return false, err
+	}
+	fallback := barcode.Text(c) + "\n" + data + "\n\n"
+	return ConfirmImage(w, in, barcode.Image(c, qrScale), fallback, prompt)
+}
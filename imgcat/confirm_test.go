@@ -0,0 +1,62 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfirmImage(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "false"))
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+
+	tc := []struct {
+		name      string
+		supported bool
+		answer    string
+		ok        bool
+		err       bool
+		prefix    string
+	}{
+		{"image", true, "\n", true, false, "\x1b]1337;File=inline=1;size=76:iVBOR"},
+		{"yes", true, "yes\n", true, false, "\x1b]1337;File=inline=1;size=76:iVBOR"},
+		{"no", true, "No\n", false, false, "\x1b]1337;File=inline=1;size=76:iVBOR"},
+		{"no newline", true, "n", false, false, "\x1b]1337;File=inline=1;size=76:iVBOR"},
+		{"fallback", false, "\n", true, false, "fallback"},
+		{"no answer", false, "", false, true, "fallback"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			isSupported = func() bool { return tt.supported }
+			buf := new(bytes.Buffer)
+			ok, err := ConfirmImage(buf, strings.NewReader(tt.answer), img, "fallback", "continue? ")
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.ok {
+				t.Errorf("expected confirmation %v; got %v", tt.ok, ok)
+			}
+			if out := buf.String(); !strings.HasPrefix(out, tt.prefix) || !strings.HasSuffix(out, "continue? ") {
+				t.Errorf("unexpected output %q", out)
+			}
+		})
+	}
+}
+
+func TestConfirmQR(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return false }
+
+	buf := new(bytes.Buffer)
+	uri := "otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP"
+	if _, err := ConfirmQR(buf, strings.NewReader("\n"), uri, "press enter "); err != nil {
+		t.Fatalf("could not confirm: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "█") || !strings.Contains(out, uri+"\n") {
+		t.Errorf("expected a text code followed by the data; got %q", out)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// Layout and colors of contact sheets.
+const sheetPadding = 8
+
+var (
+	sheetBackground = color.RGBA{0x20, 0x20, 0x20, 0xff}
+	sheetLabel      = color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+)
+
+// A Thumbnail is an image in a contact sheet, with the label written under it.
+type Thumbnail struct {
+	Image image.Image
+	Label string
+}
+
+// ContactSheet returns an image with the given thumbnails laid out in a grid
+// with the given number of columns, on a dark background. Each image is
+// scaled to fit in size by size pixels, and its label is shortened to fit
+// under it. Sending a single contact sheet is much faster than sending every
+// image, especially through tmux or SSH.
+func ContactSheet(thumbs []Thumbnail, cols, size int) image.Image {
+	if cols < 1 {
+		cols = 1
+	}
+	if len(thumbs) < cols {
+		cols = len(thumbs)
+	}
+	if size < 1 {
+		size = 1
+	}
+	rows := 0
+	if cols > 0 {
+		rows = (len(thumbs) + cols - 1) / cols
+	}
+	cellW, cellH := size+sheetPadding, size+sheetPadding+bitmapfont.Leading
+	dst := image.NewRGBA(image.Rect(0, 0, cols*cellW+sheetPadding, rows*cellH+sheetPadding))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+
+	for i, t := range thumbs {
+		at := image.Pt(sheetPadding+i%cols*cellW, sheetPadding+i/cols*cellH)
+		if t.Image != nil {
+			thumb := placeBackground(t.Image, image.Pt(size, size), BackgroundFit)
+			draw.Draw(dst, thumb.Bounds().Add(at), thumb, image.Point{}, draw.Over)
+		}
+		label := fitLabel(t.Label, size)
+		x := at.X + (size-bitmapfont.Measure(label, 1).X)/2
+		bitmapfont.Draw(dst, label, image.Pt(x, at.Y+size+2), sheetLabel, 1)
+	}
+	return dst
+}
+
+// fitLabel shortens s, replacing its end with an ellipsis, so it fits in width
+// pixels when drawn with bitmapfont.
+func fitLabel(s string, width int) string {
+	r := []rune(s)
+	if bitmapfont.Measure(s, 1).X <= width {
+		return s
+	}
+	for n := len(r) - 1; n > 0; n-- {
+		if short := string(r[:n]) + "..."; bitmapfont.Measure(short, 1).X <= width {
+			return short
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+func TestContactSheet(t *testing.T) {
+	red := uniform(20, 10, color.RGBA{0xff, 0, 0, 0xff})
+	thumbs := []Thumbnail{{red, "a.png"}, {red, "b.png"}, {red, "c.png"}}
+	img := ContactSheet(thumbs, 2, 40)
+
+	cellW, cellH := 40+sheetPadding, 40+sheetPadding+bitmapfont.Leading
+	if b := img.Bounds(); b != image.Rect(0, 0, 2*cellW+sheetPadding, 2*cellH+sheetPadding) {
+		t.Fatalf("expected 2x2 grid; got %v", b)
+	}
+	tc := []struct {
+		name string
+		p    image.Point
+		want color.Color
+	}{
+		{"padding", image.Pt(0, 0), sheetBackground},
+		{"first", image.Pt(sheetPadding+20, sheetPadding+20), color.RGBA{0xff, 0, 0, 0xff}},
+		{"letterbox", image.Pt(sheetPadding+20, sheetPadding+2), sheetBackground},
+		{"second", image.Pt(sheetPadding+cellW+20, sheetPadding+20), color.RGBA{0xff, 0, 0, 0xff}},
+		{"third", image.Pt(sheetPadding+20, sheetPadding+cellH+20), color.RGBA{0xff, 0, 0, 0xff}},
+		{"empty cell", image.Pt(sheetPadding+cellW+20, sheetPadding+cellH+20), sheetBackground},
+	}
+	for _, tt := range tc {
+		if c := img.At(tt.p.X, tt.p.Y); c != tt.want {
+			t.Errorf("%s: expected %v at %v; got %v", tt.name, tt.want, tt.p, c)
+		}
+	}
+
+	var label int
+	for y := sheetPadding + 40; y < cellH; y++ {
+		for x := sheetPadding; x < cellW; x++ {
+			if img.At(x, y) == color.Color(sheetLabel) {
+				label++
+			}
+		}
+	}
+	if label == 0 {
+		t.Errorf("expected a label under the first thumbnail")
+	}
+
+	if b := ContactSheet(thumbs[:1], 5, 40).Bounds(); b.Dx() != cellW+sheetPadding {
+		t.Errorf("expected a single column; got %v", b)
+	}
+	if b := ContactSheet(nil, 5, 40).Bounds(); b.Dx() != sheetPadding || b.Dy() != sheetPadding {
+		t.Errorf("expected empty sheet; got %v", b)
+	}
+}
+
+func TestFitLabel(t *testing.T) {
+	tc := []struct {
+		s     string
+		width int
+		label string
+	}{
+		{"cat.png", 60, "cat.png"},
+		{"a-long-file-name.png", 60, "a-long-..."},
+		{"abc", 5, ""},
+	}
+	for _, tt := range tc {
+		if got := fitLabel(tt.s, tt.width); got != tt.label {
+			t.Errorf("fitLabel(%q, %d): expected %q; got %q", tt.s, tt.width, tt.label, got)
+		}
+	}
+}
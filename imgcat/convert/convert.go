@@ -0,0 +1,162 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package convert converts images between formats, optionally resizing them,
+// for the simple cases that would otherwise need ImageMagick.
+// It reads PNG, JPEG, and GIF images, and writes PNG, JPEG, GIF, and lossless
+// WebP.
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/webp"
+)
+
+// DefaultQuality is the quality of JPEG images when none is given.
+const DefaultQuality = 90
+
+// ErrUnsupported is returned when converting to a format that can't be
+// written.
+var ErrUnsupported = errors.New("format not supported")
+
+// Options of a conversion. The zero value converts nothing, copying the image.
+type Options struct {
+	// Format of the output, defaults to the format of the input.
+	Format imgcat.Format
+	// Quality of JPEG output from 1 to 100, defaults to DefaultQuality.
+	// Setting it re-encodes JPEG images even if they keep their format.
+	Quality int
+	// Width and Height of the output in pixels. If only one of them is set,
+	// the other one keeps the aspect ratio of the image. If none is set, the
+	// image keeps its size.
+	Width, Height int
+}
+
+// Convert writes the image read from r to w, converted as given by opts.
+// Images that keep their format and size are copied as they are, unless a
+// quality is set.
+func Convert(w io.Writer, r io.Reader, opts Options) error {
+	buf := new(bytes.Buffer)
+	img, format, err := image.Decode(io.TeeReader(r, buf))
+	if err != nil {
+		return fmt.Errorf("could not decode image: %v", err)
+	}
+	if opts.Format == "" {
+		opts.Format = imgcat.Format(format)
+	}
+	if opts.Format == imgcat.Format(format) && opts.Width == 0 && opts.Height == 0 && opts.Quality == 0 {
+		_, err := io.Copy(w, io.MultiReader(buf, r))
+		return err
+	}
+	return Encode(w, Scale(img, opts.Width, opts.Height), opts.Format, opts.Quality)
+}
+
+// Scale returns img scaled to w by h pixels. If w or h is zero, it's computed
+// to keep the aspect ratio of img, and if both are, img is returned as is.
+func Scale(img image.Image, w, h int) image.Image {
+	b := img.Bounds()
+	size := ScaledSize(b.Size(), w, h)
+	if size == b.Size() {
+		return img
+	}
+	return imgcat.Resize(img, size.X, size.Y)
+}
+
+// ScaledSize returns the size of an image of the given size once scaled to w
+// by h pixels by Scale.
+func ScaledSize(size image.Point, w, h int) image.Point {
+	switch {
+	case w <= 0 && h <= 0:
+		return size
+	case w <= 0:
+		w = size.X * h / size.Y
+	case h <= 0:
+		h = size.Y * w / size.X
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return image.Pt(w, h)
+}
+
+// Encode writes img to w in the given format. The quality, from 1 to 100, is
+// only used by JPEG and defaults to DefaultQuality. JPEG has no transparency,
+// so transparent pixels are drawn over white.
+func Encode(w io.Writer, img image.Image, f imgcat.Format, quality int) error {
+	var err error
+	switch f {
+	case imgcat.PNG:
+		err = png.Encode(w, img)
+	case imgcat.JPEG:
+		if quality <= 0 {
+			quality = DefaultQuality
+		}
+		err = jpeg.Encode(w, flatten(img, color.White), &jpeg.Options{Quality: quality})
+	case imgcat.GIF:
+		err = gif.Encode(w, img, nil)
+	case imgcat.WebP:
+		err = webp.Encode(w, img)
+	default:
+		return fmt.Errorf("could not encode %s: %w", f, ErrUnsupported)
+	}
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %v", f, err)
+	}
+	return nil
+}
+
+// flatten returns img drawn over c.
+func flatten(img image.Image, c color.Color) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// FormatOf returns the format of a file named path, given by its extension,
+// or an empty string if it's not known.
+func FormatOf(path string) imgcat.Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return imgcat.PNG
+	case ".jpg", ".jpeg":
+		return imgcat.JPEG
+	case ".gif":
+		return imgcat.GIF
+	case ".bmp":
+		return imgcat.BMP
+	case ".tif", ".tiff":
+		return imgcat.TIFF
+	case ".webp":
+		return imgcat.WebP
+	case ".pdf":
+		return imgcat.PDF
+	}
+	return ""
+}
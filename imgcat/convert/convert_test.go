@@ -0,0 +1,151 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+func testPNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatalf("could not encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestConvert(t *testing.T) {
+	in := testPNG(t, 40, 20, color.NRGBA{0xff, 0, 0, 0x80})
+	tc := []struct {
+		name   string
+		opts   Options
+		format imgcat.Format
+		size   image.Point
+	}{
+		{"copy", Options{}, imgcat.PNG, image.Pt(40, 20)},
+		{"to jpeg", Options{Format: imgcat.JPEG}, imgcat.JPEG, image.Pt(40, 20)},
+		{"to gif", Options{Format: imgcat.GIF}, imgcat.GIF, image.Pt(40, 20)},
+		{"resized", Options{Width: 20, Height: 5}, imgcat.PNG, image.Pt(20, 5)},
+		{"width only", Options{Format: imgcat.JPEG, Width: 10}, imgcat.JPEG, image.Pt(10, 5)},
+		{"height only", Options{Height: 10}, imgcat.PNG, image.Pt(20, 10)},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			if err := Convert(out, bytes.NewReader(in), tt.opts); err != nil {
+				t.Fatalf("could not convert: %v", err)
+			}
+			if f := imgcat.Sniff(out.Bytes()); f != tt.format {
+				t.Fatalf("expected %s; got %q", tt.format, f)
+			}
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(out.Bytes()))
+			if err != nil {
+				t.Fatalf("could not decode output: %v", err)
+			}
+			if got := image.Pt(cfg.Width, cfg.Height); got != tt.size {
+				t.Errorf("expected size %v; got %v", tt.size, got)
+			}
+		})
+	}
+}
+
+func TestConvertCopies(t *testing.T) {
+	in := testPNG(t, 4, 4, color.White)
+	out := new(bytes.Buffer)
+	if err := Convert(out, bytes.NewReader(in), Options{Format: imgcat.PNG}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), in) {
+		t.Errorf("expected image to be copied")
+	}
+}
+
+func TestJPEGFlattened(t *testing.T) {
+	out := new(bytes.Buffer)
+	if err := Convert(out, bytes.NewReader(testPNG(t, 8, 8, color.Transparent)), Options{Format: imgcat.JPEG}); err != nil {
+		t.Fatal(err)
+	}
+	img, _, err := image.Decode(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := img.At(4, 4).RGBA(); r < 0xf000 || g < 0xf000 || b < 0xf000 {
+		t.Errorf("expected transparent pixels to be white; got %v", img.At(4, 4))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	in := testPNG(t, 4, 4, color.White)
+	if err := Convert(new(bytes.Buffer), bytes.NewReader(in), Options{Format: imgcat.TIFF}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported; got %v", err)
+	}
+	if err := Convert(new(bytes.Buffer), strings.NewReader("not an image"), Options{}); err == nil {
+		t.Errorf("expected decoding error; got nothing")
+	}
+}
+
+func TestWebP(t *testing.T) {
+	out := new(bytes.Buffer)
+	if err := Convert(out, bytes.NewReader(testPNG(t, 40, 20, color.White)), Options{Format: imgcat.WebP, Width: 10}); err != nil {
+		t.Fatalf("could not convert: %v", err)
+	}
+	b := out.Bytes()
+	if f := imgcat.Sniff(b); f != imgcat.WebP {
+		t.Fatalf("expected webp; got %q", f)
+	}
+	// The size follows the VP8L signature, as 14 bits each minus one.
+	if len(b) < 25 {
+		t.Fatalf("expected VP8L header; got %d bytes", len(b))
+	}
+	bits := uint32(b[21]) | uint32(b[22])<<8 | uint32(b[23])<<16 | uint32(b[24])<<24
+	if w, h := bits&0x3fff+1, bits>>14&0x3fff+1; w != 10 || h != 5 {
+		t.Errorf("expected size 10x5; got %dx%d", w, h)
+	}
+}
+
+func TestScaledSize(t *testing.T) {
+	tc := []struct {
+		w, h int
+		want image.Point
+	}{
+		{0, 0, image.Pt(40, 20)},
+		{10, 0, image.Pt(10, 5)},
+		{0, 10, image.Pt(20, 10)},
+		{10, 10, image.Pt(10, 10)},
+		{1, 0, image.Pt(1, 1)},
+	}
+	for _, tt := range tc {
+		if got := ScaledSize(image.Pt(40, 20), tt.w, tt.h); got != tt.want {
+			t.Errorf("%dx%d: expected %v; got %v", tt.w, tt.h, tt.want, got)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tc := map[string]imgcat.Format{
+		"a.png":        imgcat.PNG,
+		"b/c.JPG":      imgcat.JPEG,
+		"d.jpeg":       imgcat.JPEG,
+		"e.gif":        imgcat.GIF,
+		"f.webp":       imgcat.WebP,
+		"g.txt":        "",
+		"no-extension": "",
+	}
+	for path, want := range tc {
+		if got := FormatOf(path); got != want {
+			t.Errorf("%s: expected %q; got %q", path, want, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// Crop displays only the part of the image inside r. Coordinates are relative
+// to the top left corner of the image, and r is clipped to the image bounds.
+// Encode fails if r doesn't overlap the image.
+func Crop(r image.Rectangle) Option {
+	return transform(func(img image.Image) (image.Image, error) { return crop(img, r) })
+}
+
+// Region displays only the w by h pixels region whose top left corner is at
+// x, y. It's the same as Crop(image.Rect(x, y, x+w, y+h)).
+func Region(x, y, w, h int) Option {
+	return Crop(image.Rect(x, y, x+w, y+h))
+}
+
+func crop(img image.Image, r image.Rectangle) (image.Image, error) {
+	b := img.Bounds()
+	rect := r.Add(b.Min).Intersect(b)
+	if rect.Empty() {
+		return nil, fmt.Errorf("crop %v is outside the %dx%d image", r, b.Dx(), b.Dy())
+	}
+	if rect == b {
+		return img, nil
+	}
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(rect), nil
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst, nil
+}
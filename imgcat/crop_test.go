@@ -0,0 +1,69 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// quadrants returns a 4x4 image with a different color on each quadrant.
+func quadrants() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	colors := []color.NRGBA{{0xff, 0, 0, 0xff}, {0, 0xff, 0, 0xff}, {0, 0, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetNRGBA(x, y, colors[y/2*2+x/2])
+		}
+	}
+	return img
+}
+
+// opaque is an image without SubImage method.
+type opaque struct{ image.Image }
+
+func TestCrop(t *testing.T) {
+	tc := []struct {
+		name   string
+		img    image.Image
+		rect   image.Rectangle
+		size   image.Point
+		corner color.NRGBA
+	}{
+		{"top left", quadrants(), image.Rect(0, 0, 2, 2), image.Pt(2, 2), color.NRGBA{0xff, 0, 0, 0xff}},
+		{"bottom right", quadrants(), image.Rect(2, 2, 4, 4), image.Pt(2, 2), color.NRGBA{0xff, 0xff, 0xff, 0xff}},
+		{"clipped", quadrants(), image.Rect(2, 0, 10, 1), image.Pt(2, 1), color.NRGBA{0, 0xff, 0, 0xff}},
+		{"no SubImage", opaque{quadrants()}, image.Rect(0, 2, 2, 4), image.Pt(2, 2), color.NRGBA{0, 0, 0xff, 0xff}},
+		{"offset bounds", quadrants().SubImage(image.Rect(2, 2, 4, 4)), image.Rect(0, 0, 1, 1), image.Pt(1, 1), color.NRGBA{0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := crop(tt.img, tt.rect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := out.Bounds()
+			if b.Size() != tt.size {
+				t.Fatalf("expected size %v; got %v", tt.size, b.Size())
+			}
+			if got := color.NRGBAModel.Convert(out.At(b.Min.X, b.Min.Y)); got != tt.corner {
+				t.Fatalf("expected corner %v; got %v", tt.corner, got)
+			}
+		})
+	}
+
+	if _, err := crop(quadrants(), image.Rect(5, 5, 6, 6)); err == nil {
+		t.Fatalf("expected error for crop outside of the image")
+	}
+}
+
+func TestRegion(t *testing.T) {
+	r, err := newConfig([]Option{Region(1, 1, 2, 3)}).prepare(bytes.NewReader(testPNG(t, 4, 4, color.White)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := decode(t, r).Bounds().Size(); got != image.Pt(2, 3) {
+		t.Fatalf("expected size 2x3; got %v", got)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diagnose inspects broken image files. It walks the structure of PNG,
+// JPEG, and GIF files to report where they are corrupt or truncated, and
+// recovers whatever part of the image can still be decoded.
+package diagnose
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	_ "image/jpeg" // register the decoder used by image.Decode.
+	_ "image/png"  // register the decoder used by image.Decode.
+
+	"github.com/campoy/tools/imgcat"
+)
+
+// A Segment is a chunk of a PNG file, a marker segment of a JPEG file, or a
+// block of a GIF file.
+type Segment struct {
+	// Offset and Length of the segment in the file, in bytes.
+	Offset, Length int
+	// Name of the chunk, marker, or block, such as IDAT, SOS, or image 1.
+	Name string
+	// Err describes the problem found in the segment, nil if there's none.
+	Err error
+}
+
+func (s Segment) String() string {
+	str := fmt.Sprintf("%08x  %s (%d bytes)", s.Offset, s.Name, s.Length)
+	if s.Err != nil {
+		str += ": " + s.Err.Error()
+	}
+	return str
+}
+
+// A Report is the result of diagnosing an image file.
+type Report struct {
+	Format imgcat.Format
+	// Segments found in the file, in order. The walk stops at the first
+	// problem that prevents finding the next segment, such as truncation.
+	Segments []Segment
+	// Err is the error returned by the image package, nil if the file decodes.
+	Err error
+	// Image is the decoded image, or the part of it that could be recovered.
+	// It is nil if nothing could be recovered.
+	Image image.Image
+	// Recovered describes what part of Image could be recovered when the file
+	// doesn't decode, such as "120 of 480 rows".
+	Recovered string
+}
+
+// Problem returns the first segment with a problem, or nil if the structure
+// of the file is sound.
+func (r *Report) Problem() *Segment {
+	for i := range r.Segments {
+		if r.Segments[i].Err != nil {
+			return &r.Segments[i]
+		}
+	}
+	return nil
+}
+
+// Diagnose inspects data, the contents of an image file. Files in formats
+// other than PNG, JPEG, and GIF are only decoded, without walking their
+// structure nor trying to recover them.
+func Diagnose(data []byte) *Report {
+	r := &Report{Format: imgcat.Sniff(data)}
+	r.Image, _, r.Err = image.Decode(bytes.NewReader(data))
+
+	var recover func([]Segment) (image.Image, string)
+	switch r.Format {
+	case imgcat.PNG:
+		r.Segments = pngSegments(data)
+		recover = func(segs []Segment) (image.Image, string) { return recoverPNG(data, segs) }
+	case imgcat.JPEG:
+		r.Segments = jpegSegments(data)
+		recover = func(segs []Segment) (image.Image, string) { return recoverJPEG(data, segs) }
+	case imgcat.GIF:
+		r.Segments = gifSegments(data)
+		// image.Decode only decodes the first frame of animations.
+		if _, err := gif.DecodeAll(bytes.NewReader(data)); err != nil {
+			r.Err = err
+		}
+		recover = func(segs []Segment) (image.Image, string) { return recoverGIF(data, segs) }
+	}
+	if r.Err != nil && recover != nil {
+		r.Image, r.Recovered = recover(r.Segments)
+	}
+	return r
+}
+
+// errorf returns the segments found so far followed by one with a problem.
+func errorf(segs []Segment, offset, length int, name, format string, args ...interface{}) []Segment {
+	return append(segs, Segment{Offset: offset, Length: length, Name: name, Err: fmt.Errorf(format, args...)})
+}
@@ -0,0 +1,191 @@
+package diagnose
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// testImage returns a w by h image, red on top and blue at the bottom.
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{0xff, 0, 0, 0xff}
+			if y >= h/2 {
+				c = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, enc func(*bytes.Buffer, image.Image) error, img image.Image) []byte {
+	buf := new(bytes.Buffer)
+	if err := enc(buf, img); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(buf *bytes.Buffer, img image.Image) error {
+	// No compression, so truncating the file truncates the pixel data.
+	enc := png.Encoder{CompressionLevel: png.NoCompression}
+	return enc.Encode(buf, img)
+}
+
+func encodeJPEG(buf *bytes.Buffer, img image.Image) error {
+	return jpeg.Encode(buf, img, nil)
+}
+
+func encodeGIF(buf *bytes.Buffer, img image.Image) error {
+	pal := image.NewPaletted(img.Bounds(), color.Palette{color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff}})
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			pal.Set(x, y, img.At(x, y))
+		}
+	}
+	return gif.EncodeAll(buf, &gif.GIF{Image: []*image.Paletted{pal, pal}, Delay: []int{0, 0}})
+}
+
+func names(segs []Segment) string {
+	var s []string
+	for _, seg := range segs {
+		s = append(s, seg.Name)
+	}
+	return strings.Join(s, " ")
+}
+
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// cut returns the offset of the middle of the named segment of data.
+func cut(t *testing.T, data []byte, name string) int {
+	for _, s := range Diagnose(data).Segments {
+		if s.Name == name {
+			return s.Offset + s.Length/2
+		}
+	}
+	t.Fatalf("no %s segment found", name)
+	return 0
+}
+
+func TestValid(t *testing.T) {
+	tc := []struct {
+		name  string
+		enc   func(*bytes.Buffer, image.Image) error
+		names string
+	}{
+		{"png", encodePNG, "signature IHDR IDAT IEND"},
+		{"jpeg", encodeJPEG, "SOI DQT SOF0 DHT SOS scan data EOI"},
+		{"gif", encodeGIF, "header application extension image 1 image 2 trailer"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Diagnose(encode(t, tt.enc, testImage(32, 32)))
+			if r.Err != nil || r.Problem() != nil || r.Image == nil {
+				t.Fatalf("expected sound file; got error %v and problem %v", r.Err, r.Problem())
+			}
+			if got := names(r.Segments); got != tt.names {
+				t.Errorf("expected segments %q; got %q", tt.names, got)
+			}
+		})
+	}
+}
+
+func TestTruncatedPNG(t *testing.T) {
+	data := encode(t, encodePNG, testImage(32, 32))
+	r := Diagnose(data[:len(data)*3/4])
+	if r.Err == nil {
+		t.Fatalf("expected decoding error; got nothing")
+	}
+	p := r.Problem()
+	if p == nil || p.Name != "IDAT" || !strings.Contains(p.Err.Error(), "truncated") {
+		t.Fatalf("expected truncated IDAT; got %v", p)
+	}
+	if r.Image == nil {
+		t.Fatalf("expected recovered image")
+	}
+	if !strings.HasSuffix(r.Recovered, " of 32 rows") {
+		t.Errorf("expected rows recovered; got %q", r.Recovered)
+	}
+	// The top half is intact, the missing rows at the bottom are blank.
+	if c := r.Image.At(0, 31); !sameColor(c, color.Black) {
+		t.Errorf("expected blank missing rows; got %v", c)
+	}
+	if c := r.Image.At(0, 0); !sameColor(c, color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("expected red top rows; got %v", c)
+	}
+}
+
+func TestBadCRC(t *testing.T) {
+	data := encode(t, encodePNG, testImage(8, 8))
+	// Flip a bit in the CRC of the IHDR chunk.
+	data[len(pngSignature)+8+13] ^= 1
+	r := Diagnose(data)
+	p := r.Problem()
+	if p == nil || p.Name != "IHDR" || !strings.Contains(p.Err.Error(), "bad CRC") {
+		t.Fatalf("expected bad CRC in IHDR; got %v", p)
+	}
+	if got := names(r.Segments); got != "signature IHDR IDAT IEND" {
+		t.Errorf("expected walk to continue after bad CRC; got %q", got)
+	}
+}
+
+func TestTruncatedJPEG(t *testing.T) {
+	data := encode(t, encodeJPEG, testImage(64, 64))
+	r := Diagnose(data[:cut(t, data, "scan data")])
+	if r.Err == nil {
+		t.Fatalf("expected decoding error; got nothing")
+	}
+	if p := r.Problem(); p == nil || p.Name != "scan data" {
+		t.Fatalf("expected truncated scan data; got %v", p)
+	}
+	if r.Image == nil || r.Image.Bounds() != image.Rect(0, 0, 64, 64) {
+		t.Fatalf("expected recovered 64x64 image; got %v", r.Image)
+	}
+	if red, _, blue, _ := r.Image.At(0, 0).RGBA(); red < 0xf000 || blue > 0x1000 {
+		t.Errorf("expected red top left pixel; got %v", r.Image.At(0, 0))
+	}
+}
+
+func TestJPEGHeader(t *testing.T) {
+	data := encode(t, encodeJPEG, testImage(8, 8))
+	r := Diagnose(data[:10])
+	if p := r.Problem(); p == nil || p.Offset != 2 || !strings.Contains(p.Err.Error(), "truncated") {
+		t.Fatalf("expected truncated segment at offset 2; got %v", p)
+	}
+	if r.Image != nil {
+		t.Errorf("expected nothing recovered; got %v", r.Image.Bounds())
+	}
+}
+
+func TestTruncatedGIF(t *testing.T) {
+	data := encode(t, encodeGIF, testImage(16, 16))
+	r := Diagnose(data[:cut(t, data, "image 2")])
+	if r.Err == nil {
+		t.Fatalf("expected decoding error; got nothing")
+	}
+	if p := r.Problem(); p == nil || p.Name != "image 2" {
+		t.Fatalf("expected truncated second image; got %v", p)
+	}
+	if r.Image == nil || r.Recovered != "1 complete images" {
+		t.Fatalf("expected first image recovered; got %q", r.Recovered)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	r := Diagnose([]byte("not an image"))
+	if r.Err == nil || r.Segments != nil || r.Image != nil {
+		t.Fatalf("expected only a decoding error; got %+v", r)
+	}
+}
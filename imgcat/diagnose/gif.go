@@ -0,0 +1,125 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnose
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+)
+
+// gifExtensions holds the names of GIF extension blocks, by label.
+var gifExtensions = map[byte]string{
+	0x01: "plain text extension",
+	0xf9: "graphic control extension",
+	0xfe: "comment extension",
+	0xff: "application extension",
+}
+
+// gifSegments walks the blocks of a GIF file.
+func gifSegments(data []byte) []Segment {
+	if len(data) < 13 {
+		return errorf(nil, 0, len(data), "header", "truncated header")
+	}
+	segs := []Segment{{Offset: 0, Length: 13, Name: "header"}}
+	off := 13
+	if data[10]&0x80 != 0 {
+		n := 3 * (2 << (data[10] & 7))
+		if off+n > len(data) {
+			return errorf(segs, off, len(data)-off, "global color table", "truncated, %d of %d bytes", len(data)-off, n)
+		}
+		segs = append(segs, Segment{Offset: off, Length: n, Name: "global color table"})
+		off += n
+	}
+
+	images := 0
+	for {
+		if off >= len(data) {
+			return errorf(segs, off, 0, "end of file", "missing trailer")
+		}
+		start := off
+		var name string
+		switch data[off] {
+		case 0x3b:
+			segs = append(segs, Segment{Offset: off, Length: 1, Name: "trailer"})
+			if off+1 < len(data) {
+				segs = append(segs, Segment{Offset: off + 1, Length: len(data) - off - 1, Name: "trailing data"})
+			}
+			return segs
+		case 0x21:
+			if off+2 > len(data) {
+				return errorf(segs, start, len(data)-start, "extension", "truncated extension")
+			}
+			name = gifExtensions[data[off+1]]
+			if name == "" {
+				name = fmt.Sprintf("extension %02x", data[off+1])
+			}
+			off += 2
+		case 0x2c:
+			images++
+			name = fmt.Sprintf("image %d", images)
+			if off+11 > len(data) {
+				return errorf(segs, start, len(data)-start, name, "truncated image descriptor")
+			}
+			if packed := data[off+9]; packed&0x80 != 0 {
+				off += 3 * (2 << (packed & 7))
+			}
+			// Descriptor and LZW minimum code size.
+			off += 11
+		default:
+			return errorf(segs, off, 1, "block", "unknown block type %02x", data[off])
+		}
+
+		// Both extensions and images are followed by data sub-blocks.
+		for {
+			if off >= len(data) {
+				return errorf(segs, start, len(data)-start, name, "truncated data")
+			}
+			n := int(data[off])
+			off += 1 + n
+			if n == 0 {
+				break
+			}
+		}
+		segs = append(segs, Segment{Offset: start, Length: off - start, Name: name})
+	}
+}
+
+// recoverGIF keeps the complete images before the first problem, and decodes
+// the first one.
+func recoverGIF(data []byte, segs []Segment) (image.Image, string) {
+	end, images := 0, 0
+	for _, s := range segs {
+		if s.Err != nil {
+			break
+		}
+		if s.Offset+s.Length > len(data) {
+			break
+		}
+		if len(s.Name) > 6 && s.Name[:6] == "image " {
+			end = s.Offset + s.Length
+			images++
+		}
+	}
+	if images == 0 {
+		return nil, ""
+	}
+	fixed := append(append([]byte{}, data[:end]...), 0x3b)
+	g, err := gif.DecodeAll(bytes.NewReader(fixed))
+	if err != nil || len(g.Image) == 0 {
+		return nil, ""
+	}
+	return g.Image[0], fmt.Sprintf("%d complete images", len(g.Image))
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnose
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+)
+
+// jpegNames holds the names of the usual JPEG markers.
+var jpegNames = map[byte]string{
+	0xc0: "SOF0", 0xc1: "SOF1", 0xc2: "SOF2", 0xc4: "DHT",
+	0xd8: "SOI", 0xd9: "EOI", 0xda: "SOS", 0xdb: "DQT", 0xdd: "DRI", 0xfe: "COM",
+}
+
+func jpegName(m byte) string {
+	if name, ok := jpegNames[m]; ok {
+		return name
+	}
+	if m >= 0xe0 && m <= 0xef {
+		return fmt.Sprintf("APP%d", m-0xe0)
+	}
+	return fmt.Sprintf("marker %02X", m)
+}
+
+// jpegSegments walks the marker segments of a JPEG file, and the entropy coded
+// data following each SOS segment.
+func jpegSegments(data []byte) []Segment {
+	segs := []Segment{{Offset: 0, Length: 2, Name: "SOI"}}
+	off := 2
+	for {
+		if off >= len(data) {
+			return errorf(segs, off, 0, "end of file", "missing EOI marker")
+		}
+		if data[off] != 0xff {
+			return errorf(segs, off, 1, "marker", "expected a marker, found byte %02x", data[off])
+		}
+		start := off
+		// Markers can be preceded by any number of fill bytes.
+		for off < len(data) && data[off] == 0xff {
+			off++
+		}
+		if off == len(data) {
+			return errorf(segs, start, off-start, "marker", "truncated marker")
+		}
+		m := data[off]
+		off++
+		name := jpegName(m)
+		if m == 0xd9 {
+			segs = append(segs, Segment{Offset: start, Length: off - start, Name: name})
+			if off < len(data) {
+				segs = append(segs, Segment{Offset: off, Length: len(data) - off, Name: "trailing data"})
+			}
+			return segs
+		}
+		if m >= 0xd0 && m <= 0xd7 || m == 0x01 {
+			// Markers without a length.
+			segs = append(segs, Segment{Offset: start, Length: off - start, Name: name})
+			continue
+		}
+		if off+2 > len(data) {
+			return errorf(segs, start, len(data)-start, name, "truncated segment length")
+		}
+		n := int(data[off])<<8 | int(data[off+1])
+		if off+n > len(data) {
+			return errorf(segs, start, len(data)-start, name, "truncated, %d of %d bytes", len(data)-off, n)
+		}
+		off += n
+		segs = append(segs, Segment{Offset: start, Length: off - start, Name: name})
+		if m != 0xda {
+			continue
+		}
+
+		// Entropy coded data ends at the first marker other than RST, with
+		// 0xff bytes in the data followed by 0x00.
+		scan := off
+		for off < len(data) {
+			if data[off] == 0xff && off+1 < len(data) {
+				if next := data[off+1]; next != 0 && (next < 0xd0 || next > 0xd7) {
+					break
+				}
+			}
+			off++
+		}
+		if off >= len(data)-1 {
+			return errorf(segs, scan, len(data)-scan, "scan data", "truncated scan, missing EOI marker")
+		}
+		segs = append(segs, Segment{Offset: scan, Length: off - scan, Name: "scan data"})
+	}
+}
+
+// recoverJPEG cuts data at the first problem and completes it with blank scan
+// data and an EOI marker, so that the decoder fills the missing blocks.
+func recoverJPEG(data []byte, segs []Segment) (image.Image, string) {
+	end := len(data)
+	for _, s := range segs {
+		if s.Err != nil {
+			end = s.Offset
+			if s.Name == "scan data" {
+				end = s.Offset + s.Length
+			}
+			break
+		}
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data[:end]))
+	if err != nil {
+		return nil, ""
+	}
+	// Zero bits decode as the shortest Huffman codes, so this is always enough
+	// to fill the remaining blocks.
+	pad := make([]byte, cfg.Width*cfg.Height/8+1024)
+	fixed := append(append(append([]byte{}, data[:end]...), pad...), 0xff, 0xd9)
+	img, err := jpeg.Decode(bytes.NewReader(fixed))
+	if err != nil {
+		return nil, ""
+	}
+	return img, fmt.Sprintf("the first %d bytes", end)
+}
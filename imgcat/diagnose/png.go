@@ -0,0 +1,182 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnose
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io/ioutil"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// maxRawSize is the largest pixel data rebuilt by recoverPNG, since corrupt
+// headers can claim huge images.
+const maxRawSize = 256 << 20
+
+// pngSegments walks the chunks of a PNG file, checking their lengths and CRCs.
+func pngSegments(data []byte) []Segment {
+	segs := []Segment{{Offset: 0, Length: len(pngSignature), Name: "signature"}}
+	off := len(pngSignature)
+	for {
+		if off == len(data) {
+			return errorf(segs, off, 0, "end of file", "missing IEND chunk")
+		}
+		if off+8 > len(data) {
+			return errorf(segs, off, len(data)-off, "chunk", "truncated chunk header")
+		}
+		n := int(binary.BigEndian.Uint32(data[off:]))
+		name := string(data[off+4 : off+8])
+		if n < 0 || off+12+n > len(data) {
+			return errorf(segs, off, len(data)-off, name, "truncated, %d of %d bytes of data", max(len(data)-off-8, 0), n)
+		}
+		chunk := data[off+4 : off+8+n]
+		seg := Segment{Offset: off, Length: 12 + n, Name: name}
+		// Corrupt data doesn't prevent walking through the next chunks.
+		if crc := binary.BigEndian.Uint32(data[off+8+n:]); crc != crc32.ChecksumIEEE(chunk) {
+			seg.Err = fmt.Errorf("bad CRC %08x, expected %08x", crc, crc32.ChecksumIEEE(chunk))
+		}
+		segs = append(segs, seg)
+		off += 12 + n
+		if name == "IEND" {
+			if off < len(data) {
+				segs = append(segs, Segment{Offset: off, Length: len(data) - off, Name: "trailing data"})
+			}
+			return segs
+		}
+	}
+}
+
+// recoverPNG rebuilds a valid PNG file from the chunks needed to decode the
+// image, ignoring their CRCs, and padding the pixel data that couldn't be
+// decompressed with blank rows.
+func recoverPNG(data []byte, segs []Segment) (image.Image, string) {
+	var header, palette, transparency []byte
+	compressed := new(bytes.Buffer)
+	for _, s := range segs {
+		if s.Offset+8 > len(data) {
+			continue
+		}
+		// Truncated chunks can still hold some pixel data.
+		n := int(binary.BigEndian.Uint32(data[s.Offset:]))
+		body := data[s.Offset+8 : min(s.Offset+8+n, len(data))]
+		switch s.Name {
+		case "IHDR":
+			header = body
+		case "PLTE":
+			palette = body
+		case "tRNS":
+			transparency = body
+		case "IDAT":
+			compressed.Write(body)
+		}
+	}
+	if len(header) != 13 {
+		return nil, ""
+	}
+	width := int(binary.BigEndian.Uint32(header[0:]))
+	height := int(binary.BigEndian.Uint32(header[4:]))
+	size, stride := pngRawSize(width, height, header[8], header[9], header[12])
+	if size <= 0 || size > maxRawSize {
+		return nil, ""
+	}
+
+	// Decompress as much as possible, the error is the reason it doesn't decode.
+	zr, err := zlib.NewReader(compressed)
+	if err != nil {
+		return nil, ""
+	}
+	raw, _ := ioutil.ReadAll(zr)
+	if len(raw) > size {
+		raw = raw[:size]
+	}
+	rows := len(raw) / stride
+	raw = append(raw, make([]byte, size-len(raw))...)
+
+	out := new(bytes.Buffer)
+	out.WriteString(pngSignature)
+	writeChunk(out, "IHDR", header)
+	if palette != nil {
+		writeChunk(out, "PLTE", palette)
+	}
+	if transparency != nil {
+		writeChunk(out, "tRNS", transparency)
+	}
+	idat := new(bytes.Buffer)
+	zw := zlib.NewWriter(idat)
+	zw.Write(raw)
+	zw.Close()
+	writeChunk(out, "IDAT", idat.Bytes())
+	writeChunk(out, "IEND", nil)
+
+	img, err := png.Decode(out)
+	if err != nil {
+		return nil, ""
+	}
+	if header[12] != 0 {
+		return img, "part of the interlaced pixel data"
+	}
+	return img, fmt.Sprintf("%d of %d rows", min(rows, height), height)
+}
+
+// pngRawSize returns the size of the decompressed pixel data of an image, and
+// the length of its rows when not interlaced, filter byte included.
+func pngRawSize(width, height int, depth, colorType, interlace byte) (size, stride int) {
+	channels := map[byte]int{0: 1, 2: 3, 3: 1, 4: 2, 6: 4}[colorType]
+	if channels == 0 || width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	rowBytes := func(w int) int { return 1 + (w*channels*int(depth)+7)/8 }
+	if interlace == 0 {
+		return height * rowBytes(width), rowBytes(width)
+	}
+	// Adam7 passes, as x0, y0, dx, dy.
+	for _, p := range [][4]int{{0, 0, 8, 8}, {4, 0, 8, 8}, {0, 4, 4, 8}, {2, 0, 4, 4}, {0, 2, 2, 4}, {1, 0, 2, 2}, {0, 1, 1, 2}} {
+		w := (width - p[0] + p[2] - 1) / p[2]
+		h := (height - p[1] + p[3] - 1) / p[3]
+		if w > 0 && h > 0 {
+			size += h * rowBytes(w)
+		}
+	}
+	return size, rowBytes(width)
+}
+
+func writeChunk(buf *bytes.Buffer, name string, body []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(body)))
+	buf.Write(n[:])
+	chunk := append([]byte(name), body...)
+	buf.Write(chunk)
+	binary.BigEndian.PutUint32(n[:], crc32.ChecksumIEEE(chunk))
+	buf.Write(n[:])
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display/wire"
+	"github.com/campoy/tools/imgcat/seal"
+)
+
+// An Image is sent to a display server.
+type Image struct {
+	// Data is the image, in any format the server's terminal displays.
+	Data []byte
+	// Name of the image, such as plot.png.
+	Name string
+	// Width and Height the image is displayed with, if not empty.
+	Width, Height imgcat.Length
+}
+
+// A Client sends images to a display server.
+type Client struct {
+	conn  net.Conn
+	r     io.Reader
+	w     io.Writer
+	flush func() error
+	close func() error
+	// Server is the name the server gave in its welcome message.
+	Server string
+}
+
+// Dial connects to the display server given in IMGCAT_SOCK, or returns
+// ErrNoServer if it's not set. Connections the server seals use the key of
+// seal.LoadKey.
+func Dial() (*Client, error) {
+	addr := os.Getenv(EnvSock)
+	if addr == "" {
+		return nil, ErrNoServer
+	}
+	return DialAddr(addr, nil)
+}
+
+// DialAddr connects to the display server at the given address, as given in
+// IMGCAT_SOCK. Connections the server seals use key, or the key of
+// seal.LoadKey if nil.
+func DialAddr(addr string, key *seal.Key) (*Client, error) {
+	netw, address := network(addr)
+	conn, err := net.Dial(netw, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to display server: %v", err)
+	}
+	c, err := handshake(conn, key)
+	if err != nil {
+		// The failed handshake is what's worth reporting.
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake greets the server connected with conn.
+func handshake(conn net.Conn, key *seal.Key) (*Client, error) {
+	hello := wire.Header{Type: wire.Hello, Version: wire.Version, Client: filepath.Base(os.Args[0])}
+	if err := wire.Write(conn, &wire.Message{Header: hello}); err != nil {
+		return nil, fmt.Errorf("could not greet display server: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	h, err := wire.Read(br, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not greet display server: %v", err)
+	}
+	switch {
+	case h.Type == wire.Error:
+		return nil, fmt.Errorf("display server refused connection: %s", h.Error)
+	case h.Type != wire.Welcome:
+		return nil, fmt.Errorf("unexpected %s message from display server", h.Type)
+	}
+
+	c := &Client{conn: conn, r: br, w: conn, Server: h.Server}
+	c.flush = func() error { return nil }
+	c.close = func() error { return nil }
+	if h.Seal {
+		if key == nil {
+			k, err := seal.LoadKey()
+			if err != nil {
+				return nil, fmt.Errorf("display server seals connections: %w", err)
+			}
+			key = &k
+		}
+		sw, err := seal.NewWriter(conn, *key)
+		if err != nil {
+			return nil, err
+		}
+		c.r, c.w, c.flush, c.close = seal.NewReader(br, *key), sw, sw.Flush, sw.Close
+	}
+	return c, nil
+}
+
+// Send sends img to the server, and returns once it's displayed, or the
+// error the server gave if it couldn't be.
+func (c *Client) Send(img Image) error {
+	h := wire.Header{Type: wire.Image, Name: img.Name, Width: string(img.Width), Height: string(img.Height)}
+	if err := wire.Write(c.w, &wire.Message{Header: h, Body: img.Data}); err != nil {
+		return fmt.Errorf("could not send image: %v", err)
+	}
+	if err := c.flush(); err != nil {
+		return fmt.Errorf("could not send image: %v", err)
+	}
+	reply, err := wire.Read(c.r, 0)
+	if err != nil {
+		if errors.Is(err, seal.ErrCorrupted) {
+			return fmt.Errorf("could not read reply, the keys may differ: %v", err)
+		}
+		return fmt.Errorf("could not read reply: %v", err)
+	}
+	if reply.Type == wire.Error {
+		return fmt.Errorf("display server: %s", reply.Error)
+	}
+	if reply.Type != wire.OK {
+		return fmt.Errorf("unexpected %s message from display server", reply.Type)
+	}
+	return nil
+}
+
+// Close disconnects from the server.
+func (c *Client) Close() error {
+	err := c.close()
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
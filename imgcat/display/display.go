@@ -0,0 +1,81 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package display lets programs show images in the terminal of the user
+// through a display server, such as the one run by imgcat -serve, when they
+// can't write to it themselves: child processes whose output is captured,
+// containers with the socket of the server mounted, or programs written in
+// other languages.
+//
+// The address of the server is given to its clients in the IMGCAT_SOCK
+// variable: the path of a Unix socket, or tcp://host:port for servers on
+// other machines. Clients connect, and exchange the messages of the wire
+// package with the server: a hello answered with a welcome, and then images,
+// each answered with an ok or an error. Connections can be sealed with the
+// seal package, when the server has a key.
+package display
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvSock is the variable giving the address of the display server.
+const EnvSock = "IMGCAT_SOCK"
+
+// ErrNoServer is returned by Dial when no display server is advertised.
+var ErrNoServer = errors.New("no display server, " + EnvSock + " is not set")
+
+// network splits an address given in IMGCAT_SOCK into the network and the
+// address to dial.
+func network(addr string) (string, string) {
+	if strings.HasPrefix(addr, "tcp://") {
+		return "tcp", strings.TrimPrefix(addr, "tcp://")
+	}
+	return "unix", strings.TrimPrefix(addr, "unix://")
+}
+
+// Listen listens on a new Unix socket, in a directory only the user can
+// read, and returns the listener and its address for IMGCAT_SOCK. Closing
+// the listener removes them.
+func Listen() (net.Listener, string, error) {
+	dir, err := ioutil.TempDir("", "imgcat-display")
+	if err != nil {
+		return nil, "", fmt.Errorf("could not create socket: %v", err)
+	}
+	path := filepath.Join(dir, "display.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("could not create socket: %v", err)
+	}
+	return socket{l, dir}, path, nil
+}
+
+// socket is a listener on a Unix socket in its own directory.
+type socket struct {
+	net.Listener
+	dir string
+}
+
+// Close closes the listener, and removes the directory of the socket.
+func (s socket) Close() error {
+	err := s.Listener.Close()
+	os.RemoveAll(s.dir)
+	return err
+}
@@ -0,0 +1,202 @@
+package display
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display/wire"
+	"github.com/campoy/tools/imgcat/seal"
+)
+
+// safeBuffer is a bytes.Buffer safe for concurrent use.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// serve starts s on a new socket, for a terminal supporting images, and
+// returns its address and a function stopping it.
+func serve(t *testing.T, s *Server) (string, func()) {
+	protocol := os.Getenv(imgcat.EnvProtocol)
+	os.Setenv(imgcat.EnvProtocol, "iterm2")
+	l, addr, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	return addr, func() {
+		l.Close()
+		os.Setenv(imgcat.EnvProtocol, protocol)
+	}
+}
+
+func TestServer(t *testing.T) {
+	key, err := seal.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := seal.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		name      string
+		serverKey *seal.Key
+		clientKey *seal.Key
+		dialErr   bool
+		sendErr   bool
+	}{
+		{"plain", nil, nil, false, false},
+		{"sealed", &key, &key, false, false},
+		{"other key", &key, &other, false, true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(safeBuffer)
+			addr, stop := serve(t, &Server{Out: out, Options: []imgcat.Option{imgcat.Passthrough(false)}, Key: tt.serverKey, MaxBytes: 10})
+			defer stop()
+
+			c, err := DialAddr(addr, tt.clientKey)
+			if err != nil {
+				t.Fatalf("could not dial: %v", err)
+			}
+			defer c.Close()
+			if !strings.HasPrefix(c.Server, "imgcat ") {
+				t.Errorf("expected the name of the server; got %q", c.Server)
+			}
+			err = c.Send(Image{Data: []byte("test"), Name: "a.png", Width: imgcat.Cells(10)})
+			if tt.sendErr {
+				if err == nil {
+					t.Fatalf("expected an error sending; got nothing")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not send: %v", err)
+			}
+			if want := "\x1b]1337;File=name=YS5wbmc=;width=10;size=4:dGVzdA==\a\n"; out.String() != want {
+				t.Fatalf("expected %q; got %q", want, out.String())
+			}
+
+			// Errors are reported for each image.
+			if err := c.Send(Image{Data: []byte("test"), Height: "tall"}); err == nil || !strings.Contains(err.Error(), "invalid length") {
+				t.Fatalf("expected an invalid length; got %v", err)
+			}
+			if err := c.Send(Image{Data: []byte("test")}); err != nil {
+				t.Fatalf("could not send after an error: %v", err)
+			}
+			if err := c.Send(Image{Data: []byte("more than ten bytes")}); err == nil || !strings.Contains(err.Error(), "larger than 10 bytes") {
+				t.Fatalf("expected an image too large; got %v", err)
+			}
+		})
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	addr, stop := serve(t, &Server{Out: new(safeBuffer)})
+	defer stop()
+
+	conn, err := net.Dial("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := wire.Write(conn, &wire.Message{Header: wire.Header{Type: wire.Hello, Version: wire.Version + 1}}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := wire.Read(conn, 0)
+	if err != nil || m.Type != wire.Error || !strings.Contains(m.Error, "version") {
+		t.Fatalf("expected a version error; got %+v, %v", m, err)
+	}
+}
+
+func TestDial(t *testing.T) {
+	defer os.Setenv(EnvSock, os.Getenv(EnvSock))
+	defer os.Setenv(seal.EnvKey, os.Getenv(seal.EnvKey))
+
+	os.Unsetenv(EnvSock)
+	if _, err := Dial(); err != ErrNoServer {
+		t.Fatalf("expected ErrNoServer; got %v", err)
+	}
+
+	key, err := seal.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(safeBuffer)
+	addr, stop := serve(t, &Server{Out: out, Options: []imgcat.Option{imgcat.Passthrough(false)}, Key: &key})
+	defer stop()
+
+	// The key of sealed connections is loaded from IMGCAT_KEY.
+	os.Setenv(EnvSock, "unix://"+addr)
+	os.Setenv(seal.EnvKey, "bad")
+	if _, err := Dial(); err == nil {
+		t.Fatalf("expected an error with a bad key; got nothing")
+	}
+	os.Setenv(seal.EnvKey, key.String())
+	c, err := Dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	if err := c.Send(Image{Data: []byte("test")}); err != nil {
+		t.Fatalf("could not send: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("could not close: %v", err)
+	}
+	if want := "\x1b]1337;File=size=4:dGVzdA==\a\n"; out.String() != want {
+		t.Fatalf("expected %q; got %q", want, out.String())
+	}
+
+	os.Setenv(EnvSock, addr+".missing")
+	if _, err := Dial(); err == nil {
+		t.Fatalf("expected an error for a missing server; got nothing")
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	tc := []struct{ addr, network, address string }{
+		{"/tmp/display.sock", "unix", "/tmp/display.sock"},
+		{"unix:///tmp/display.sock", "unix", "/tmp/display.sock"},
+		{"tcp://10.0.0.1:9393", "tcp", "10.0.0.1:9393"},
+	}
+	for _, tt := range tc {
+		n, a := network(tt.addr)
+		if n != tt.network || a != tt.address {
+			t.Errorf("expected network(%q) to be %s %s; got %s %s", tt.addr, tt.network, tt.address, n, a)
+		}
+	}
+}
+
+func TestListen(t *testing.T) {
+	l, addr, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(addr); err != nil {
+		t.Fatalf("expected a socket; got %v", err)
+	}
+	l.Close()
+	if _, err := os.Stat(addr); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the socket to be removed; got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display/wire"
+	"github.com/campoy/tools/imgcat/seal"
+)
+
+// DefaultMaxBytes is the size of the largest image a Server accepts by
+// default.
+const DefaultMaxBytes = 32 << 20
+
+// A Server displays the images its clients send.
+type Server struct {
+	// Out is where images are displayed, such as os.Stdout.
+	Out io.Writer
+	// Options of the encoders displaying images, before the name and size
+	// given by clients.
+	Options []imgcat.Option
+	// Key, if not nil, seals the connections with clients, see the seal
+	// package. It should be set for servers listening on TCP.
+	Key *seal.Key
+	// MaxBytes is the size of the largest image accepted, DefaultMaxBytes
+	// if zero.
+	MaxBytes int64
+	// ErrorLog, if not nil, is called with the errors of connections.
+	ErrorLog func(error)
+
+	// mu is held while displaying an image, so they don't overlap.
+	mu sync.Mutex
+}
+
+// Serve accepts connections on l, and serves each one in its own goroutine,
+// until l fails, such as when it's closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			// Clients are gone once served, whatever closing says.
+			defer func() { _ = conn.Close() }()
+			if err := s.ServeConn(conn); err != nil && s.ErrorLog != nil {
+				s.ErrorLog(err)
+			}
+		}()
+	}
+}
+
+// ServeConn serves a single client connected with conn, until it
+// disconnects.
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	br := bufio.NewReader(conn)
+	hello, err := wire.Read(br, 0)
+	if err == nil && (hello.Type != wire.Hello || hello.Version != wire.Version) {
+		err = fmt.Errorf("unsupported %s message of version %d, expected hello of version %d", hello.Type, hello.Version, wire.Version)
+	}
+	if err != nil {
+		reply := wire.Header{Type: wire.Error, Version: wire.Version, Error: err.Error()}
+		if werr := wire.Write(conn, &wire.Message{Header: reply}); werr != nil {
+			return fmt.Errorf("could not greet client: %v, nor reply: %v", err, werr)
+		}
+		return fmt.Errorf("could not greet client: %v", err)
+	}
+	welcome := wire.Header{Type: wire.Welcome, Version: wire.Version, Server: fmt.Sprintf("imgcat %d", os.Getpid()), Seal: s.Key != nil}
+	if err := wire.Write(conn, &wire.Message{Header: welcome}); err != nil {
+		return err
+	}
+
+	var r io.Reader = br
+	var w io.Writer = conn
+	flush := func() error { return nil }
+	if s.Key != nil {
+		sw, err := seal.NewWriter(conn, *s.Key)
+		if err != nil {
+			return err
+		}
+		r, w, flush = seal.NewReader(br, *s.Key), sw, sw.Flush
+	}
+	max := s.MaxBytes
+	if max <= 0 {
+		max = DefaultMaxBytes
+	}
+	for {
+		m, err := wire.Read(r, max)
+		if err == io.EOF {
+			return nil
+		}
+		tooLarge := errors.Is(err, wire.ErrTooLarge) && m != nil
+		reply := wire.Header{Type: wire.OK}
+		switch {
+		case tooLarge:
+			reply = wire.Header{Type: wire.Error, Error: fmt.Sprintf("image of %d bytes is larger than %d bytes", m.Size, max)}
+		case err != nil:
+			return fmt.Errorf("could not read message of %s: %v", hello.Client, err)
+		case m.Type != wire.Image:
+			reply = wire.Header{Type: wire.Error, Error: fmt.Sprintf("unknown message type %q", m.Type)}
+		default:
+			if err := s.display(m); err != nil {
+				reply = wire.Header{Type: wire.Error, Error: err.Error()}
+			}
+		}
+		if err := wire.Write(w, &wire.Message{Header: reply}); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		// The body of images too large was not read, so nothing else can be.
+		if tooLarge {
+			return nil
+		}
+	}
+}
+
+// display displays the image of a message.
+func (s *Server) display(m *wire.Message) error {
+	options := append([]imgcat.Option(nil), s.Options...)
+	if m.Name != "" {
+		options = append(options, imgcat.Name(m.Name))
+	}
+	for _, dim := range []struct {
+		value  string
+		option func(imgcat.Length) imgcat.Option
+	}{{m.Width, imgcat.Width}, {m.Height, imgcat.Height}} {
+		if dim.value == "" {
+			continue
+		}
+		l, err := imgcat.ParseLength(dim.value)
+		if err != nil {
+			return err
+		}
+		options = append(options, dim.option(l))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc, err := imgcat.NewEncoder(s.Out, options...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(bytes.NewReader(m.Body))
+}
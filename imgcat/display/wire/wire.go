@@ -0,0 +1,239 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wire defines the messages exchanged by display servers and their
+// clients, see the display package, so clients in other languages can be
+// written against it.
+//
+// # Framing
+//
+// Each message is the length n of its header, as 4 bytes in big-endian
+// order, n bytes of the header, a JSON object, and as many bytes as its size
+// field gives, which are the body:
+//
+//	00 00 00 35 {"type":"image","name":"a.png","width":"10","size":4} 74 65 73 74
+//
+// Headers are at most 64KiB. Fields that are zero, empty, or false are left
+// out, and readers ignore fields they don't know, to stay compatible with
+// later versions. The order of fields doesn't matter.
+//
+// # Messages
+//
+// The client starts with a hello, answered with a welcome or an error if the
+// server doesn't speak the version of the client:
+//
+//	{"type":"hello","version":1,"client":"plot.py"}
+//	{"type":"welcome","version":1,"server":"imgcat 4242","seal":true}
+//
+// When the welcome has seal set, what follows in each direction is sealed
+// with the seal package. The client then sends images, whose body is the
+// data of the image, each answered with an ok, or an error with a message
+// for the user:
+//
+//	{"type":"image","name":"plot.png","width":"50%","height":"10","size":5123}
+//	{"type":"ok"}
+//	{"type":"error","error":"image of 40000000 bytes is larger than 33554432 bytes"}
+//
+// Width and height are given as to imgcat.ParseLength. Servers close the
+// connection after an error about an image too large, whose body they don't
+// read.
+//
+// # Conformance
+//
+// The cases in testdata/conformance.json hold messages encoded in hex, and
+// either the message they decode to, or the kind of error reading them
+// gives: truncated, too_large, or invalid. Implementations should decode each
+// valid case to the same message, decode what they encode from it to the
+// same message again, and fail on the others.
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version is the version of the protocol.
+const Version = 1
+
+// MaxHeader is the size of the largest header.
+const MaxHeader = 64 << 10
+
+// A Type of message.
+type Type string
+
+// Types of messages.
+const (
+	Hello   Type = "hello"
+	Welcome Type = "welcome"
+	Image   Type = "image"
+	OK      Type = "ok"
+	Error   Type = "error"
+)
+
+// Errors returned by Read, and by Message.UnmarshalBinary, which wrap them.
+var (
+	// ErrTruncated is returned for messages cut before their end.
+	ErrTruncated = errors.New("truncated message")
+	// ErrTooLarge is returned for bodies larger than allowed, and for
+	// headers larger than MaxHeader.
+	ErrTooLarge = errors.New("message too large")
+	// ErrInvalid is returned for headers that can't be parsed, or lack
+	// fields their type needs.
+	ErrInvalid = errors.New("invalid message")
+)
+
+// Header is the header of a message. Which fields are set depends on its
+// type.
+type Header struct {
+	Type Type `json:"type"`
+	// Version of the protocol, of hello, welcome, and error messages.
+	Version int `json:"version,omitempty"`
+	// Client is the name of the client, in hello messages.
+	Client string `json:"client,omitempty"`
+	// Server is the name of the server, in welcome messages.
+	Server string `json:"server,omitempty"`
+	// Seal, in welcome messages, tells what follows is sealed.
+	Seal bool `json:"seal,omitempty"`
+	// Name, Width, and Height of the image of image messages.
+	Name   string `json:"name,omitempty"`
+	Width  string `json:"width,omitempty"`
+	Height string `json:"height,omitempty"`
+	// Error is the message of error messages.
+	Error string `json:"error,omitempty"`
+	// Size is the number of bytes of the body.
+	Size int64 `json:"size,omitempty"`
+}
+
+// Validate returns an error wrapping ErrInvalid if h lacks fields its type
+// needs. Messages of unknown types are valid, for later versions.
+func (h Header) Validate() error {
+	var missing string
+	switch {
+	case h.Type == "":
+		missing = "type"
+	case (h.Type == Hello || h.Type == Welcome) && h.Version <= 0:
+		missing = "version"
+	case h.Type == Error && h.Error == "":
+		missing = "error"
+	}
+	if missing != "" {
+		return fmt.Errorf("%s message without %s: %w", h.Type, missing, ErrInvalid)
+	}
+	if h.Size < 0 {
+		return fmt.Errorf("negative size %d: %w", h.Size, ErrInvalid)
+	}
+	return nil
+}
+
+// A Message is a header and its body.
+type Message struct {
+	Header
+	Body []byte
+}
+
+// MarshalBinary encodes m, with the size of its header set to the length of
+// its body.
+func (m *Message) MarshalBinary() ([]byte, error) {
+	h := m.Header
+	h.Size = int64(len(m.Body))
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > MaxHeader {
+		return nil, fmt.Errorf("header of %d bytes: %w", len(b), ErrTooLarge)
+	}
+	msg := make([]byte, 4, 4+len(b)+len(m.Body))
+	binary.BigEndian.PutUint32(msg, uint32(len(b)))
+	return append(append(msg, b...), m.Body...), nil
+}
+
+// UnmarshalBinary decodes a message encoded by MarshalBinary, with a body of
+// any size, and nothing after it.
+func (m *Message) UnmarshalBinary(b []byte) error {
+	r := bytes.NewReader(b)
+	read, err := Read(r, int64(len(b)))
+	if err == io.EOF {
+		err = ErrTruncated
+	}
+	if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("%d bytes after the message: %w", r.Len(), ErrInvalid)
+	}
+	*m = *read
+	return nil
+}
+
+// Write writes m to w.
+func Write(w io.Writer, m *Message) error {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Read reads a message whose body is at most max bytes from r. It returns
+// io.EOF if r ends before the message starts. For bodies too large, it
+// returns the message without body and an error wrapping ErrTooLarge,
+// leaving the body unread.
+func Read(r io.Reader, max int64) (*Message, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = ErrTruncated
+		}
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n > MaxHeader {
+		return nil, fmt.Errorf("header of %d bytes: %w", n, ErrTooLarge)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, truncated(err)
+	}
+	m := new(Message)
+	if err := json.Unmarshal(b, &m.Header); err != nil {
+		return nil, fmt.Errorf("could not parse header: %v: %w", err, ErrInvalid)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if m.Size > max {
+		return m, fmt.Errorf("body of %d bytes is larger than %d bytes: %w", m.Size, max, ErrTooLarge)
+	}
+	m.Body = make([]byte, m.Size)
+	if _, err := io.ReadFull(r, m.Body); err != nil {
+		return nil, truncated(err)
+	}
+	return m, nil
+}
+
+// truncated returns the error of a message cut while reading it.
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
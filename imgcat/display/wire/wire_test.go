@@ -0,0 +1,127 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// A conformance case of testdata/conformance.json.
+type conformance struct {
+	Name    string `json:"name"`
+	Hex     string `json:"hex"`
+	Message *struct {
+		Header Header `json:"header"`
+		Body   []byte `json:"body"`
+	} `json:"message"`
+	Canonical bool   `json:"canonical"`
+	Max       *int64 `json:"max"`
+	Error     string `json:"error"`
+}
+
+var conformanceErrors = map[string]error{
+	"truncated": ErrTruncated,
+	"too_large": ErrTooLarge,
+	"invalid":   ErrInvalid,
+}
+
+func TestConformance(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/conformance.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []conformance
+	if err := json.Unmarshal(b, &cases); err != nil {
+		t.Fatalf("could not parse conformance cases: %v", err)
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.Hex)
+			if err != nil {
+				t.Fatalf("bad hex: %v", err)
+			}
+			var m Message
+			if tt.Max != nil {
+				var got *Message
+				if got, err = Read(bytes.NewReader(data), *tt.Max); got != nil {
+					m = *got
+				}
+			} else {
+				err = m.UnmarshalBinary(data)
+			}
+
+			if tt.Message == nil {
+				want, ok := conformanceErrors[tt.Error]
+				if !ok {
+					t.Fatalf("unknown error %q", tt.Error)
+				}
+				if !errors.Is(err, want) {
+					t.Fatalf("expected %v; got %v", want, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not decode: %v", err)
+			}
+			want := Message{Header: tt.Message.Header, Body: tt.Message.Body}
+			if want.Body == nil {
+				want.Body = []byte{}
+			}
+			if !reflect.DeepEqual(m, want) {
+				t.Fatalf("expected %+v; got %+v", want, m)
+			}
+
+			encoded, err := m.MarshalBinary()
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if tt.Canonical && !bytes.Equal(encoded, data) {
+				t.Fatalf("expected %x; got %x", data, encoded)
+			}
+			var again Message
+			if err := again.UnmarshalBinary(encoded); err != nil || !reflect.DeepEqual(again, want) {
+				t.Fatalf("expected %+v again; got %+v, %v", want, again, err)
+			}
+		})
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	buf := new(bytes.Buffer)
+	msgs := []*Message{
+		{Header: Header{Type: Hello, Version: Version, Client: "test"}, Body: []byte{}},
+		{Header: Header{Type: Image, Name: "a.png"}, Body: []byte("test")},
+	}
+	for _, m := range msgs {
+		if err := Write(buf, m); err != nil {
+			t.Fatalf("could not write: %v", err)
+		}
+	}
+	for _, want := range msgs {
+		got, err := Read(buf, 4)
+		want.Size = int64(len(want.Body))
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %+v; got %+v, %v", want, got, err)
+		}
+	}
+	if _, err := Read(buf, 4); err != io.EOF {
+		t.Fatalf("expected io.EOF at the end; got %v", err)
+	}
+
+	// Bodies too large are left unread, with the header returned.
+	Write(buf, msgs[1])
+	m, err := Read(buf, 3)
+	if !errors.Is(err, ErrTooLarge) || m == nil || m.Name != "a.png" || buf.Len() != 4 {
+		t.Fatalf("expected the header of a message too large; got %+v, %v", m, err)
+	}
+
+	// Invalid messages are not written.
+	if err := Write(buf, &Message{Header: Header{Type: Error}}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid; got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Environment variables read by DefaultOptionsFromEnv and IsSupported.
+const (
+	// EnvProtocol forces the protocol: iterm2 to send images even when the
+	// terminal isn't detected as iTerm2, such as in other terminals
+	// implementing its protocol, or none to never send them. Encoders with a
+	// FallbackChain also take kitty or sixel, and all Encoders take the name
+	// of a renderer, see RegisterRenderer.
+	EnvProtocol = "IMGCAT_PROTOCOL"
+	// EnvMaxPixels sets MaxPixels.
+	EnvMaxPixels = "IMGCAT_MAX_PIXELS"
+	// EnvMaxBytes sets TargetBytes.
+	EnvMaxBytes = "IMGCAT_MAX_BYTES"
+	// EnvWidth and EnvHeight set the Width and Height, as a number of cells,
+	// pixels such as 100px, a percentage such as 50%, or auto.
+	EnvWidth  = "IMGCAT_WIDTH"
+	EnvHeight = "IMGCAT_HEIGHT"
+)
+
+// protocols holds the values of EnvProtocol, and whether each one sends images
+// with the protocol of iTerm2, see IsSupported.
+var protocols = map[string]bool{"iterm2": true, "kitty": false, "sixel": false, "none": false}
+
+// lengthPattern matches the values accepted by Width and Height.
+var lengthPattern = regexp.MustCompile(`^([0-9]+(px|%)?|auto)$`)
+
+// DefaultOptionsFromEnv returns the options set by the IMGCAT_* environment
+// variables, so users can configure all programs displaying images the same
+// way. It returns an error if any of the variables has an invalid value.
+func DefaultOptionsFromEnv() ([]Option, error) {
+	return optionsFromEnv(os.Getenv)
+}
+
+func optionsFromEnv(getenv func(string) string) ([]Option, error) {
+	var options []Option
+	if v := getenv(EnvProtocol); v != "" {
+		if _, ok := protocols[v]; !ok && rendererOf(Protocol(v)) == nil {
+			return nil, fmt.Errorf("unknown protocol %q in %s, use iterm2, kitty, sixel, none, or a registered renderer", v, EnvProtocol)
+		}
+	}
+	for _, key := range []string{EnvMaxPixels, EnvMaxBytes} {
+		v := getenv(key)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a number", key, v)
+		}
+		if key == EnvMaxPixels {
+			options = append(options, MaxPixels(n))
+		} else {
+			options = append(options, TargetBytes(n))
+		}
+	}
+	for _, key := range []string{EnvWidth, EnvHeight} {
+		v := getenv(key)
+		if v == "" {
+			continue
+		}
+		l, err := ParseLength(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected cells, pixels, a percentage, or auto", key, v)
+		}
+		if key == EnvWidth {
+			options = append(options, Width(l))
+		} else {
+			options = append(options, Height(l))
+		}
+	}
+	return options, nil
+}
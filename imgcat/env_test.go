@@ -0,0 +1,45 @@
+package imgcat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	tc := []struct {
+		name string
+		env  map[string]string
+		args string
+		max  int
+		ok   bool
+	}{
+		{"empty", nil, "", 0, true},
+		{"sizes", map[string]string{EnvWidth: "50%", EnvHeight: "10"}, "width=50%;height=10", 0, true},
+		{"limits", map[string]string{EnvMaxPixels: "1000", EnvMaxBytes: "2000"}, "", 1000, true},
+		{"protocol", map[string]string{EnvProtocol: "iterm2"}, "", 0, true},
+		{"bad protocol", map[string]string{EnvProtocol: "regis"}, "", 0, false},
+		{"bad width", map[string]string{EnvWidth: "wide"}, "", 0, false},
+		{"bad max pixels", map[string]string{EnvMaxPixels: "-1"}, "", 0, false},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := optionsFromEnv(func(key string) string { return tt.env[key] })
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("expected error; got nothing")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := newConfig(options)
+			if args := strings.Join(c.args, ";"); args != tt.args {
+				t.Errorf("expected header %q; got %q", tt.args, args)
+			}
+			if c.maxPixels != tt.max {
+				t.Errorf("expected max pixels %d; got %d", tt.max, c.maxPixels)
+			}
+		})
+	}
+}
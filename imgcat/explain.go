@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// An Explanation describes how an image would be displayed, see Explain.
+type Explanation struct {
+	// Terminal is the value of TERM_PROGRAM, and Supported reports whether
+	// IsSupported is true.
+	Terminal  string
+	Supported bool
+	// Remote reports whether IsRemote is true.
+	Remote bool
+	// Args holds the header arguments computed from the options, or the ones
+	// sent once the payload is known.
+	Args []string
+	// MaxPixels and TargetBytes are the limits set by the options, zero means
+	// no limit.
+	MaxPixels, TargetBytes int
+	// Event describes what sending the image would do.
+	Event
+}
+
+// Explain goes through everything Encode would do to display the image read
+// from r with the given options, without writing anything. This is the first
+// thing to look at when images don't show up. Unlike NewEncoder, it doesn't
+// check whether the current terminal is supported.
+func Explain(r io.Reader, options ...Option) *Explanation {
+	var e Event
+	options = append(options[:len(options):len(options)], Trace(TracerFunc(func(ev Event) { e = ev })))
+	enc := &Encoder{out: ioutil.Discard, options: options}
+	// The error is part of the event.
+	_ = enc.Encode(r)
+
+	cfg := newConfig(options)
+	args := cfg.args
+	if e.Args != nil {
+		args = e.Args
+	}
+	return &Explanation{
+		Terminal:    os.Getenv("TERM_PROGRAM"),
+		Supported:   IsSupported(),
+		Remote:      IsRemote(),
+		Args:        args,
+		MaxPixels:   cfg.maxPixels,
+		TargetBytes: cfg.targetBytes,
+		Event:       e,
+	}
+}
+
+func (x *Explanation) String() string {
+	buf := new(bytes.Buffer)
+	line := func(key, format string, args ...interface{}) {
+		fmt.Fprintf(buf, "%-14s%s\n", key+":", fmt.Sprintf(format, args...))
+	}
+	yesNo := map[bool]string{true: "yes", false: "no"}
+
+	terminal := x.Terminal
+	if terminal == "" {
+		terminal = "unknown, TERM_PROGRAM is not set"
+	}
+	line("terminal", "%s", terminal)
+	line("supported", "%s", yesNo[x.Supported])
+	line("tmux", "%s", yesNo[x.Tmux])
+	line("remote", "%s", yesNo[x.Remote])
+	line("protocol", "%s", x.Protocol)
+	line("arguments", "%s", strings.Join(x.Args, ";"))
+	limit := func(n int) string {
+		if n <= 0 {
+			return "none"
+		}
+		return fmt.Sprint(n)
+	}
+	line("max pixels", "%s", limit(x.MaxPixels))
+	line("target bytes", "%s", limit(x.TargetBytes))
+	format := string(x.Format)
+	if format == "" {
+		format = "unknown format"
+	}
+	line("payload", "%s, %d bytes read, %d sent", format, x.Input, x.Payload)
+	line("sequence", "%d bytes", x.Sequence)
+	for _, note := range x.Notes {
+		line("note", "%s", note)
+	}
+	if x.Err != nil {
+		line("error", "%v", x.Err)
+	}
+	return buf.String()
+}
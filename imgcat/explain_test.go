@@ -0,0 +1,53 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	defer func(old func() bool) { isRemote = old }(isRemote)
+	isSupported = func() bool { return false }
+	isRemote = func() bool { return false }
+
+	in := testPNG(t, 4, 4, color.White)
+	options := []Option{Inline(true), Width(Cells(10)), Passthrough(false)}
+	x := Explain(bytes.NewReader(in), options...)
+	if x.Supported || x.Tmux || x.Protocol != "iterm2" || x.Format != PNG || x.Payload != int64(len(in)) || x.Err != nil {
+		t.Fatalf("unexpected explanation %+v", x)
+	}
+	if args := strings.Join(x.Args, ";"); args != fmt.Sprintf("inline=1;width=10;size=%d", len(in)) {
+		t.Errorf("expected arguments inline=1;width=10;size=%d; got %q", len(in), args)
+	}
+	want, err := EncodeToBytes(bytes.NewReader(in), options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Sequence != int64(len(want)) {
+		t.Errorf("expected sequence of %d bytes; got %d", len(want), x.Sequence)
+	}
+	if len(options) != 3 {
+		t.Errorf("expected options to be left untouched")
+	}
+
+	for _, s := range []string{"supported:    no\n", "arguments:    inline=1;width=10;size=", "payload:      png, "} {
+		if !strings.Contains(x.String(), s) {
+			t.Errorf("expected explanation to contain %q; got\n%s", s, x)
+		}
+	}
+}
+
+func TestExplainError(t *testing.T) {
+	x := Explain(bytes.NewReader(testPNG(t, 4, 4, color.White)), MaxPixels(10))
+	if !errors.Is(x.Err, ErrTooLarge) {
+		t.Fatalf("expected error %v; got %v", ErrTooLarge, x.Err)
+	}
+	if !strings.Contains(x.String(), "error:        4x4 exceeds 10 pixels") {
+		t.Errorf("expected error in explanation; got\n%s", x)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// A Filter modifies the color of each pixel of an image.
+type Filter func(color.NRGBA) color.NRGBA
+
+// Filters applies the given filters, in order, to every pixel of the image.
+func Filters(filters ...Filter) Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		if len(filters) == 0 {
+			return img, nil
+		}
+		b := img.Bounds()
+		dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+				for _, f := range filters {
+					c = f(c)
+				}
+				dst.SetNRGBA(x, y, c)
+			}
+		}
+		return dst, nil
+	})
+}
+
+// Grayscale converts colors to shades of gray with the same luminance.
+func Grayscale() Filter {
+	return func(c color.NRGBA) color.NRGBA {
+		y := color.GrayModel.Convert(color.RGBA{c.R, c.G, c.B, 0xff}).(color.Gray).Y
+		return color.NRGBA{y, y, y, c.A}
+	}
+}
+
+// Invert replaces colors with their complement, preserving transparency.
+// It is useful to display charts with a light background on dark terminals.
+func Invert() Filter {
+	return func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{0xff - c.R, 0xff - c.G, 0xff - c.B, c.A}
+	}
+}
+
+// Brightness multiplies the intensity of colors by f.
+// Values over 1 brighten the image, and values under 1 darken it.
+func Brightness(f float64) Filter {
+	return channels(func(v float64) float64 { return v * f })
+}
+
+// Contrast scales the difference of colors from the middle gray by f.
+// Values over 1 increase the contrast, and values under 1 reduce it.
+func Contrast(f float64) Filter {
+	return channels(func(v float64) float64 { return (v-0.5)*f + 0.5 })
+}
+
+// channels returns a Filter applying fn to the red, green, and blue channels,
+// given as values between 0 and 1.
+func channels(fn func(float64) float64) Filter {
+	apply := func(v uint8) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, fn(float64(v)/0xff))) * 0xff))
+	}
+	return func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{apply(c.R), apply(c.G), apply(c.B), c.A}
+	}
+}
@@ -0,0 +1,43 @@
+package imgcat
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	tc := []struct {
+		name   string
+		filter Filter
+		in     color.NRGBA
+		out    color.NRGBA
+	}{
+		{"grayscale", Grayscale(), color.NRGBA{0xff, 0xff, 0xff, 0x80}, color.NRGBA{0xff, 0xff, 0xff, 0x80}},
+		{"grayscale red", Grayscale(), color.NRGBA{0xff, 0, 0, 0xff}, color.NRGBA{0x4c, 0x4c, 0x4c, 0xff}},
+		{"invert", Invert(), color.NRGBA{0xff, 0x80, 0, 0x40}, color.NRGBA{0, 0x7f, 0xff, 0x40}},
+		{"brighten", Brightness(2), color.NRGBA{0x40, 0x80, 0xff, 0xff}, color.NRGBA{0x80, 0xff, 0xff, 0xff}},
+		{"darken", Brightness(0.5), color.NRGBA{0x40, 0x80, 0xff, 0xff}, color.NRGBA{0x20, 0x40, 0x80, 0xff}},
+		{"more contrast", Contrast(1.5), color.NRGBA{0x20, 0x60, 0xa0, 0xff}, color.NRGBA{0x00, 0x50, 0xb0, 0xff}},
+		{"no contrast", Contrast(0), color.NRGBA{0x00, 0x80, 0xff, 0xff}, color.NRGBA{0x80, 0x80, 0x80, 0xff}},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(tt.in); got != tt.out {
+				t.Fatalf("expected %v; got %v", tt.out, got)
+			}
+		})
+	}
+}
+
+func TestFilters(t *testing.T) {
+	r, err := newConfig([]Option{Filters(Grayscale(), Invert())}).prepare(bytes.NewReader(testPNG(t, 2, 2, color.White)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := color.NRGBA{0, 0, 0, 0xff}
+	if got := color.NRGBAModel.Convert(decode(t, r).At(0, 0)); got != want {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A Format identifies the file format of a payload.
+// Names match the ones used by the image package.
+type Format string
+
+// Formats recognized by Sniff.
+const (
+	PNG  Format = "png"
+	JPEG Format = "jpeg"
+	GIF  Format = "gif"
+	BMP  Format = "bmp"
+	TIFF Format = "tiff"
+	WebP Format = "webp"
+	PDF  Format = "pdf"
+)
+
+// ErrFormat is returned by Encode when the payload is not in one of the formats
+// allowed by the Formats option.
+var ErrFormat = errors.New("format not allowed")
+
+// A signature is the sequence of magic bytes that starts a format.
+// A '?' in magic matches any byte.
+type signature struct {
+	magic  string
+	format Format
+}
+
+var signatures = []signature{
+	{"\x89PNG\r\n\x1a\n", PNG},
+	{"\xff\xd8\xff", JPEG},
+	{"GIF87a", GIF},
+	{"GIF89a", GIF},
+	{"BM", BMP},
+	{"II*\x00", TIFF},
+	{"MM\x00*", TIFF},
+	{"RIFF????WEBP", WebP},
+	{"%PDF-", PDF},
+}
+
+// sniffLen is the number of bytes needed to recognize any format.
+const sniffLen = 12
+
+// Sniff returns the format of the payload starting with the given bytes,
+// based on their magic bytes, or an empty string if it is not recognized.
+func Sniff(b []byte) Format {
+	for _, s := range signatures {
+		if match(s.magic, b) {
+			return s.format
+		}
+	}
+	return ""
+}
+
+func match(magic string, b []byte) bool {
+	if len(b) < len(magic) {
+		return false
+	}
+	for i := range magic {
+		if magic[i] != '?' && magic[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Formats restricts the payloads sent to the terminal to the given formats.
+// Formats are detected from the magic bytes at the start of the payload, so
+// a file can't be passed off as an image by its name alone.
+// Payloads in any other format are rejected with ErrFormat.
+func Formats(formats ...Format) Option {
+	return func(c *config) { c.formats = formats }
+}
+
+// sniff checks the payload read from r against the allowed formats and returns
+// a reader for the whole payload.
+func (c *config) sniff(r io.Reader) (io.Reader, error) {
+	if c.formats == nil {
+		return r, nil
+	}
+	br := bufio.NewReader(r)
+	b, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not read payload: %v", err)
+	}
+	f := Sniff(b)
+	if f == "" {
+		return nil, fmt.Errorf("unknown format: %w", ErrFormat)
+	}
+	for _, allowed := range c.formats {
+		if f == allowed {
+			return br, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", f, ErrFormat)
+}
@@ -0,0 +1,72 @@
+package imgcat
+
+import (
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tc := []struct {
+		in  string
+		out Format
+	}{
+		{"\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR", PNG},
+		{"\xff\xd8\xff\xe0\x00\x10JFIF", JPEG},
+		{"GIF89a\x01\x00", GIF},
+		{"GIF87a\x01\x00", GIF},
+		{"BM\x00\x00", BMP},
+		{"II*\x00\x08\x00", TIFF},
+		{"MM\x00*\x00\x08", TIFF},
+		{"RIFF\x24\x00\x00\x00WEBPVP8 ", WebP},
+		{"RIFF\x24\x00\x00\x00WAVEfmt ", ""},
+		{"%PDF-1.4", PDF},
+		{"test", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tc {
+		if got := Sniff([]byte(tt.in)); got != tt.out {
+			t.Errorf("Sniff(%q): expected %q; got %q", tt.in, tt.out, got)
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	img := string(testPNG(t, 2, 2, color.Black))
+
+	tc := []struct {
+		name    string
+		in      string
+		options []Option
+		err     error
+	}{
+		{"any format", "test", nil, nil},
+		{"allowed", img, []Option{Formats(PNG)}, nil},
+		{"one of many allowed", img, []Option{Formats(JPEG, PNG)}, nil},
+		{"not allowed", img, []Option{Formats(JPEG, GIF)}, ErrFormat},
+		{"unknown", "test", []Option{Formats(PNG)}, ErrFormat},
+		{"empty", "", []Option{Formats(PNG)}, ErrFormat},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newConfig(tt.options).prepare(strings.NewReader(tt.in))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("could not read: %v", err)
+			}
+			if string(b) != tt.in {
+				t.Fatalf("payload was modified")
+			}
+		})
+	}
+}
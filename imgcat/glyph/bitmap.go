@@ -0,0 +1,216 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glyph
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// bitmap returns the color bitmap for glyph g that best fits an image of the
+// given size, or nil if the font has none.
+func (f *Font) bitmap(g, size int) (image.Image, error) {
+	data := f.sbix(g, size)
+	if data == nil {
+		data = f.cbdt(g, size)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode bitmap of glyph %d: %v", g, err)
+	}
+	return img, nil
+}
+
+// bestStrike returns the index of the strike whose size is the smallest one
+// at least as large as size, or the largest one.
+func bestStrike(n int, ppem func(i int) int, size int) int {
+	best := -1
+	for i := 0; i < n; i++ {
+		p := ppem(i)
+		switch {
+		case best < 0:
+			best = i
+		case p >= size && (ppem(best) < size || p < ppem(best)):
+			best = i
+		case ppem(best) < size && p > ppem(best):
+			best = i
+		}
+	}
+	return best
+}
+
+// sbix returns the PNG data for glyph g in the sbix table.
+func (f *Font) sbix(g, size int) []byte {
+	t := f.tables["sbix"]
+	if len(t) < 8 {
+		return nil
+	}
+	n := int(u32(t, 4))
+	if 8+4*n > len(t) {
+		return nil
+	}
+	strike := func(i int) []byte {
+		offset := int(u32(t, 8+4*i))
+		if offset+4 > len(t) {
+			return nil
+		}
+		return t[offset:]
+	}
+	ppem := func(i int) int {
+		if s := strike(i); s != nil {
+			return int(u16(s, 0))
+		}
+		return 0
+	}
+	best := bestStrike(n, ppem, size)
+	if best < 0 {
+		return nil
+	}
+	s := strike(best)
+	for depth := 0; s != nil && depth < maxDepth; depth++ {
+		if 4+4*g+8 > len(s) {
+			return nil
+		}
+		start, end := int(u32(s, 4+4*g)), int(u32(s, 4+4*g+4))
+		if start+8 > end || end > len(s) {
+			return nil
+		}
+		data := s[start:end]
+		switch string(data[4:8]) {
+		case "png ":
+			return data[8:]
+		case "dupe":
+			if len(data) < 10 {
+				return nil
+			}
+			g = int(u16(data, 8))
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// cbdt returns the PNG data for glyph g in the CBDT table, as indexed by the
+// CBLC table.
+func (f *Font) cbdt(g, size int) []byte {
+	cblc, cbdt := f.tables["CBLC"], f.tables["CBDT"]
+	if len(cblc) < 8 || len(cbdt) == 0 {
+		return nil
+	}
+	const sizeRecord = 48
+	n := int(u32(cblc, 4))
+	if 8+sizeRecord*n > len(cblc) {
+		return nil
+	}
+	ppem := func(i int) int { return int(cblc[8+sizeRecord*i+45]) }
+	best := bestStrike(n, ppem, size)
+	if best < 0 {
+		return nil
+	}
+	rec := 8 + sizeRecord*best
+	array, subtables := int(u32(cblc, rec)), int(u32(cblc, rec+8))
+
+	for i := 0; i < subtables; i++ {
+		entry := array + 8*i
+		if entry+8 > len(cblc) {
+			return nil
+		}
+		firstGlyph, lastGlyph := int(u16(cblc, entry)), int(u16(cblc, entry+2))
+		if g < firstGlyph || g > lastGlyph {
+			continue
+		}
+		sub := array + int(u32(cblc, entry+4))
+		if sub+8 > len(cblc) {
+			return nil
+		}
+		indexFormat, imageFormat := u16(cblc, sub), u16(cblc, sub+2)
+		imageData := int(u32(cblc, sub+4))
+
+		var start, end int
+		switch indexFormat {
+		case 1:
+			at := sub + 8 + 4*(g-firstGlyph)
+			if at+8 > len(cblc) {
+				return nil
+			}
+			start, end = int(u32(cblc, at)), int(u32(cblc, at+4))
+		case 2:
+			if sub+12 > len(cblc) {
+				return nil
+			}
+			imageSize := int(u32(cblc, sub+8))
+			start = imageSize * (g - firstGlyph)
+			end = start + imageSize
+		case 3:
+			at := sub + 8 + 2*(g-firstGlyph)
+			if at+4 > len(cblc) {
+				return nil
+			}
+			start, end = int(u16(cblc, at)), int(u16(cblc, at+2))
+		case 4:
+			if sub+12 > len(cblc) {
+				return nil
+			}
+			count := int(u32(cblc, sub+8))
+			for j := 0; j < count; j++ {
+				at := sub + 12 + 4*j
+				if at+8 > len(cblc) {
+					return nil
+				}
+				if int(u16(cblc, at)) == g {
+					start, end = int(u16(cblc, at+2)), int(u16(cblc, at+6))
+					break
+				}
+			}
+		default:
+			return nil
+		}
+		start += imageData
+		end += imageData
+		if start >= end || end > len(cbdt) {
+			return nil
+		}
+		return pngData(cbdt[start:end], imageFormat)
+	}
+	return nil
+}
+
+// pngData returns the PNG image in a CBDT glyph using the given image format.
+func pngData(b []byte, format uint16) []byte {
+	var metrics int
+	switch format {
+	case 17:
+		metrics = 5
+	case 18:
+		metrics = 8
+	case 19:
+		metrics = 0
+	default:
+		return nil
+	}
+	if metrics+4 > len(b) {
+		return nil
+	}
+	n := int(u32(b, metrics))
+	if metrics+4+n > len(b) {
+		return nil
+	}
+	return b[metrics+4 : metrics+4+n]
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package glyph renders single glyphs of TrueType fonts, including emoji and
+// icon fonts such as Nerd Fonts, to images at any resolution.
+//
+// Outlines are read from glyf tables, and color emoji from the PNG bitmaps in
+// sbix (Apple) or CBDT (Google) tables. Fonts with CFF outlines are not
+// supported.
+package glyph
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"math"
+)
+
+var (
+	// ErrNoGlyph is returned when a font has no glyph for a rune.
+	ErrNoGlyph = errors.New("no glyph for rune")
+	// ErrUnsupported is returned when parsing fonts in an unsupported format.
+	ErrUnsupported = errors.New("unsupported font format")
+)
+
+// A Font is a parsed TrueType font.
+type Font struct {
+	tables     map[string][]byte
+	unitsPerEm int
+	longLoca   bool
+	numGlyphs  int
+	ascent     int
+	descent    int
+	numMetrics int
+	// cmap subtable used to map runes to glyphs.
+	cmap []byte
+}
+
+// Load reads and parses the font at the given path. For collections, the
+// first font is used.
+func Load(path string) (*Font, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse parses a TrueType font, or the first font of a TrueType collection.
+func Parse(b []byte) (*Font, error) {
+	if len(b) < 12 {
+		return nil, errors.New("font too short")
+	}
+	offset := 0
+	switch string(b[:4]) {
+	case "ttcf":
+		if len(b) < 16 {
+			return nil, errors.New("font collection too short")
+		}
+		offset = int(u32(b, 12))
+	case "OTTO":
+		return nil, fmt.Errorf("CFF outlines: %w", ErrUnsupported)
+	case "\x00\x01\x00\x00", "true":
+	default:
+		return nil, ErrUnsupported
+	}
+
+	if offset+12 > len(b) {
+		return nil, errors.New("invalid font offset")
+	}
+	f := &Font{tables: make(map[string][]byte)}
+	n := int(u16(b, offset+4))
+	for i := 0; i < n; i++ {
+		rec := offset + 12 + 16*i
+		if rec+16 > len(b) {
+			return nil, errors.New("truncated table directory")
+		}
+		start, length := int(u32(b, rec+8)), int(u32(b, rec+12))
+		if start < 0 || length < 0 || start+length > len(b) {
+			return nil, fmt.Errorf("table %q out of bounds", b[rec:rec+4])
+		}
+		f.tables[string(b[rec:rec+4])] = b[start : start+length]
+	}
+
+	head, hhea, maxp := f.tables["head"], f.tables["hhea"], f.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errors.New("missing or invalid head, hhea, or maxp table")
+	}
+	f.unitsPerEm = int(u16(head, 18))
+	f.longLoca = u16(head, 50) != 0
+	f.numGlyphs = int(u16(maxp, 4))
+	f.ascent = int(int16(u16(hhea, 4)))
+	f.descent = int(int16(u16(hhea, 6)))
+	f.numMetrics = int(u16(hhea, 34))
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("invalid units per em")
+	}
+	if f.ascent <= f.descent {
+		f.ascent, f.descent = f.unitsPerEm*4/5, -f.unitsPerEm/5
+	}
+
+	cmap, err := findCmap(f.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	return f, nil
+}
+
+// Render renders the glyph for r in a square image of size by size pixels,
+// with the glyph centered and scaled to fit. Outline glyphs are filled with
+// the given color, color emoji keep their own colors.
+func (f *Font) Render(r rune, size int, c color.Color) (image.Image, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	g := f.index(r)
+	if g == 0 {
+		return nil, fmt.Errorf("%w %U", ErrNoGlyph, r)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	if bitmap, err := f.bitmap(g, size); err != nil {
+		return nil, err
+	} else if bitmap != nil {
+		fit(dst, bitmap)
+		return dst, nil
+	}
+
+	contours, err := f.outline(g, 0)
+	if err != nil {
+		return nil, err
+	}
+	// The em box, extended to include the outline if it's larger.
+	box := bounds{0, float64(f.descent), float64(f.advance(g)), float64(f.ascent)}
+	for _, c := range contours {
+		for _, p := range c {
+			box.add(p.x, p.y)
+		}
+	}
+	w, h := box.maxX-box.minX, box.maxY-box.minY
+	scale := float64(size) / math.Max(w, h)
+	dx := (float64(size) - w*scale) / 2
+	dy := (float64(size) - h*scale) / 2
+
+	var ras rasterizer
+	ras.reset(size, size)
+	for _, c := range contours {
+		ras.contour(c, func(x, y float64) (float64, float64) {
+			return dx + (x-box.minX)*scale, dy + (box.maxY-y)*scale
+		})
+	}
+	draw.DrawMask(dst, dst.Bounds(), image.NewUniform(c), image.Point{}, ras.mask(), image.Point{}, draw.Over)
+	return dst, nil
+}
+
+// fit draws src centered in dst, scaled to fit.
+func fit(dst *image.RGBA, src image.Image) {
+	sb, db := src.Bounds(), dst.Bounds()
+	scale := math.Min(float64(db.Dx())/float64(sb.Dx()), float64(db.Dy())/float64(sb.Dy()))
+	w, h := int(float64(sb.Dx())*scale), int(float64(sb.Dy())*scale)
+	x0, y0 := (db.Dx()-w)/2, (db.Dy()-h)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx := sb.Min.X + int((float64(x)+0.5)/scale)
+			sy := sb.Min.Y + int((float64(y)+0.5)/scale)
+			dst.Set(x0+x, y0+y, src.At(sx, sy))
+		}
+	}
+}
+
+// advance returns the advance width of glyph g.
+func (f *Font) advance(g int) int {
+	hmtx := f.tables["hmtx"]
+	if f.numMetrics == 0 {
+		return f.unitsPerEm
+	}
+	if g >= f.numMetrics {
+		g = f.numMetrics - 1
+	}
+	if 4*g+2 > len(hmtx) {
+		return f.unitsPerEm
+	}
+	return int(u16(hmtx, 4*g))
+}
+
+type bounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+func (b *bounds) add(x, y float64) {
+	b.minX, b.maxX = math.Min(b.minX, x), math.Max(b.maxX, x)
+	b.minY, b.maxY = math.Min(b.minY, y), math.Max(b.maxY, y)
+}
+
+func u16(b []byte, i int) uint16 { return binary.BigEndian.Uint16(b[i:]) }
+func u32(b []byte, i int) uint32 { return binary.BigEndian.Uint32(b[i:]) }
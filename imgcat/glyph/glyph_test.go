@@ -0,0 +1,203 @@
+package glyph
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+	"testing"
+)
+
+// sfnt builds a font file with the given tables.
+func sfnt(tables map[string][]byte) []byte {
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	buf := new(bytes.Buffer)
+	write := func(v interface{}) { _ = binary.Write(buf, binary.BigEndian, v) }
+	write(uint32(0x00010000))
+	write(uint16(len(tags)))
+	write([3]uint16{})
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		buf.WriteString(tag)
+		write([3]uint32{0, uint32(offset), uint32(len(tables[tag]))})
+		offset += len(tables[tag])
+	}
+	for _, tag := range tags {
+		buf.Write(tables[tag])
+	}
+	return buf.Bytes()
+}
+
+func be(values ...interface{}) []byte {
+	buf := new(bytes.Buffer)
+	for _, v := range values {
+		_ = binary.Write(buf, binary.BigEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// testTables returns the tables of a font with 1000 units per em and two
+// glyphs: a square for 'A', and a composite made of the square for 'B'.
+func testTables() map[string][]byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], uint16(0x10000-200))
+	binary.BigEndian.PutUint16(hhea[34:], 3)
+
+	square := be(int16(1), [4]int16{100, 0, 900, 800}, uint16(3), uint16(0),
+		[4]uint8{flagOnCurve, flagOnCurve, flagOnCurve, flagOnCurve},
+		[4]int16{100, 800, 0, -800}, [4]int16{0, 0, 800, 0})
+	composite := be(int16(-1), [4]int16{100, 0, 900, 800}, uint16(flagWords|flagXYValues), uint16(1), [2]int16{0, 0})
+	glyf := append(square, composite...)
+	loca := be([4]uint16{0, 0, uint16(len(square) / 2), uint16(len(glyf) / 2)})
+
+	// Format 4 cmap with a segment for 'A'-'B' and the final one.
+	cmap := be(uint16(0), uint16(1), uint16(3), uint16(1), uint32(12),
+		uint16(4), uint16(32), uint16(0), uint16(4), [3]uint16{},
+		[2]uint16{'B', 0xffff}, uint16(0), [2]uint16{'A', 0xffff},
+		[2]uint16{uint16(0x10000 + 1 - 'A'), 1}, [2]uint16{0, 0})
+
+	return map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"maxp": be(uint32(0x5000), uint16(3)),
+		"hmtx": be([6]uint16{1000, 0, 1000, 0, 1000, 0}),
+		"cmap": cmap,
+		"loca": loca,
+		"glyf": glyf,
+	}
+}
+
+func TestRender(t *testing.T) {
+	f, err := Parse(sfnt(testTables()))
+	if err != nil {
+		t.Fatalf("could not parse font: %v", err)
+	}
+	for _, r := range "AB" {
+		img, err := f.Render(r, 10, color.Black)
+		if err != nil {
+			t.Fatalf("could not render %q: %v", r, err)
+		}
+		if got := img.Bounds(); got != image.Rect(0, 0, 10, 10) {
+			t.Fatalf("expected 10x10 image; got %v", got)
+		}
+		// The square covers from x=1 to 9, and y=0 to 8.
+		tc := []struct {
+			x, y  int
+			alpha uint8
+		}{
+			{0, 0, 0}, {1, 0, 0xff}, {8, 7, 0xff}, {9, 7, 0}, {5, 8, 0}, {5, 4, 0xff},
+		}
+		for _, tt := range tc {
+			if _, _, _, a := img.At(tt.x, tt.y).RGBA(); uint8(a>>8) != tt.alpha {
+				t.Errorf("%q: expected alpha %d at %d,%d; got %d", r, tt.alpha, tt.x, tt.y, a>>8)
+			}
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	f, err := Parse(sfnt(testTables()))
+	if err != nil {
+		t.Fatalf("could not parse font: %v", err)
+	}
+	if _, err := f.Render('Z', 10, color.Black); !errors.Is(err, ErrNoGlyph) {
+		t.Errorf("expected ErrNoGlyph; got %v", err)
+	}
+	if _, err := f.Render('A', 0, color.Black); err == nil {
+		t.Errorf("expected error for empty size; got nothing")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tables := testTables()
+	delete(tables, "cmap")
+	tc := []struct {
+		name string
+		in   []byte
+		err  error
+	}{
+		{"valid", sfnt(testTables()), nil},
+		{"cff", append([]byte("OTTO"), make([]byte, 12)...), ErrUnsupported},
+		{"unknown", []byte("this is not a font"), ErrUnsupported},
+		{"short", []byte("true"), errors.New("font too short")},
+		{"no cmap", sfnt(tables), errors.New("missing or invalid cmap table")},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			switch {
+			case tt.err == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err == ErrUnsupported && !errors.Is(err, ErrUnsupported):
+				t.Fatalf("expected ErrUnsupported; got %v", err)
+			case tt.err != nil && tt.err != ErrUnsupported && (err == nil || err.Error() != tt.err.Error()):
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSbix(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	for i := 0; i < 16; i++ {
+		img.Set(i%4, i/4, red)
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	// A single strike, where only glyph 1 has a bitmap.
+	data := append(be([2]int16{0, 0}), "png "...)
+	data = append(data, buf.Bytes()...)
+	strike := append(be(uint16(4), uint16(72), [4]uint32{20, 20, 20 + uint32(len(data)), 20 + uint32(len(data))}), data...)
+	tables := testTables()
+	tables["sbix"] = append(be(uint16(1), uint16(0), uint32(1), uint32(12)), strike...)
+
+	f, err := Parse(sfnt(tables))
+	if err != nil {
+		t.Fatalf("could not parse font: %v", err)
+	}
+	out, err := f.Render('A', 8, color.Black)
+	if err != nil {
+		t.Fatalf("could not render: %v", err)
+	}
+	if got := color.RGBAModel.Convert(out.At(7, 7)); got != red {
+		t.Errorf("expected bitmap color %v; got %v", red, got)
+	}
+	// 'B' has no bitmap and uses its outline.
+	out, err = f.Render('B', 10, color.Black)
+	if err != nil {
+		t.Fatalf("could not render: %v", err)
+	}
+	if got := color.RGBAModel.Convert(out.At(5, 4)); got != (color.RGBA{0, 0, 0, 0xff}) {
+		t.Errorf("expected outline color; got %v", got)
+	}
+}
+
+func TestBestStrike(t *testing.T) {
+	sizes := []int{20, 64, 160, 40}
+	ppem := func(i int) int { return sizes[i] }
+	tc := []struct {
+		size, want int
+	}{
+		{10, 0}, {20, 0}, {30, 3}, {64, 1}, {100, 2}, {500, 2},
+	}
+	for _, tt := range tc {
+		if got := bestStrike(len(sizes), ppem, tt.size); got != tt.want {
+			t.Errorf("size %d: expected strike %d; got %d", tt.size, tt.want, got)
+		}
+	}
+}
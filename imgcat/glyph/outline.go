@@ -0,0 +1,335 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glyph
+
+import (
+	"errors"
+	"fmt"
+)
+
+// findCmap returns the best Unicode subtable of the given cmap table.
+func findCmap(cmap []byte) ([]byte, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("missing or invalid cmap table")
+	}
+	var best []byte
+	bestScore := 0
+	n := int(u16(cmap, 2))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform, encoding, offset := u16(cmap, rec), u16(cmap, rec+2), int(u32(cmap, rec+4))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		format := u16(cmap, offset)
+		score := 0
+		switch {
+		case format == 12 && (platform == 0 || platform == 3 && encoding == 10):
+			score = 3
+		case format == 4 && (platform == 0 || platform == 3 && encoding == 1):
+			score = 2
+		case format == 4 && platform == 3 && encoding == 0:
+			// Symbol fonts, like many icon fonts.
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = cmap[offset:], score
+		}
+	}
+	if best == nil {
+		return nil, errors.New("no unicode cmap subtable")
+	}
+	return best, nil
+}
+
+// index returns the glyph index for r, or 0 if there's none.
+func (f *Font) index(r rune) int {
+	c := f.cmap
+	switch u16(c, 0) {
+	case 4:
+		if r > 0xffff || len(c) < 14 {
+			return 0
+		}
+		segs := int(u16(c, 6)) / 2
+		if len(c) < 16+8*segs {
+			return 0
+		}
+		ends, starts, deltas, ranges := 14, 16+2*segs, 16+4*segs, 16+6*segs
+		for i := 0; i < segs; i++ {
+			end, start := rune(u16(c, ends+2*i)), rune(u16(c, starts+2*i))
+			if r > end {
+				continue
+			}
+			if r < start {
+				return 0
+			}
+			delta, offset := int(u16(c, deltas+2*i)), int(u16(c, ranges+2*i))
+			if offset == 0 {
+				return (int(r) + delta) & 0xffff
+			}
+			at := ranges + 2*i + offset + 2*int(r-start)
+			if at+2 > len(c) {
+				return 0
+			}
+			g := int(u16(c, at))
+			if g == 0 {
+				return 0
+			}
+			return (g + delta) & 0xffff
+		}
+	case 12:
+		if len(c) < 16 {
+			return 0
+		}
+		n := int(u32(c, 12))
+		for i := 0; i < n && 16+12*i+12 <= len(c); i++ {
+			group := 16 + 12*i
+			start, end := rune(u32(c, group)), rune(u32(c, group+4))
+			if r >= start && r <= end {
+				return int(u32(c, group+8)) + int(r-start)
+			}
+		}
+	}
+	return 0
+}
+
+// glyf returns the data for glyph g in the glyf table, which is empty for
+// glyphs with no outline.
+func (f *Font) glyf(g int) ([]byte, error) {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	if g < 0 || g >= f.numGlyphs {
+		return nil, fmt.Errorf("invalid glyph %d", g)
+	}
+	var start, end int
+	if f.longLoca {
+		if 4*g+8 > len(loca) {
+			return nil, errors.New("invalid loca table")
+		}
+		start, end = int(u32(loca, 4*g)), int(u32(loca, 4*g+4))
+	} else {
+		if 2*g+4 > len(loca) {
+			return nil, errors.New("invalid loca table")
+		}
+		start, end = 2*int(u16(loca, 2*g)), 2*int(u16(loca, 2*g+2))
+	}
+	if start > end || end > len(glyf) {
+		return nil, fmt.Errorf("glyph %d out of bounds", g)
+	}
+	return glyf[start:end], nil
+}
+
+// A point of an outline, in font units.
+type point struct {
+	x, y    float64
+	onCurve bool
+}
+
+// maxDepth limits the nesting of composite glyphs.
+const maxDepth = 8
+
+// outline returns the contours of glyph g.
+func (f *Font) outline(g, depth int) ([][]point, error) {
+	b, err := f.glyf(g)
+	if err != nil || len(b) == 0 {
+		return nil, err
+	}
+	if len(b) < 10 {
+		return nil, fmt.Errorf("glyph %d too short", g)
+	}
+	n := int(int16(u16(b, 0)))
+	if n >= 0 {
+		return simple(b, n)
+	}
+	if depth >= maxDepth {
+		return nil, errors.New("composite glyphs nested too deeply")
+	}
+	return f.composite(b, depth)
+}
+
+// Flags of simple glyph points.
+const (
+	flagOnCurve = 1 << iota
+	flagShortX
+	flagShortY
+	flagRepeat
+	flagSameX
+	flagSameY
+)
+
+func simple(b []byte, n int) ([][]point, error) {
+	errTruncated := errors.New("truncated glyph")
+	i := 10
+	if i+2*n+2 > len(b) {
+		return nil, errTruncated
+	}
+	ends := make([]int, n)
+	for c := range ends {
+		ends[c] = int(u16(b, i)) + 1
+		i += 2
+	}
+	npoints := 0
+	if n > 0 {
+		npoints = ends[n-1]
+	}
+	i += 2 + int(u16(b, i))
+
+	flags := make([]byte, 0, npoints)
+	for len(flags) < npoints {
+		if i >= len(b) {
+			return nil, errTruncated
+		}
+		flag := b[i]
+		i++
+		flags = append(flags, flag)
+		if flag&flagRepeat != 0 {
+			if i >= len(b) {
+				return nil, errTruncated
+			}
+			for r := 0; r < int(b[i]) && len(flags) < npoints; r++ {
+				flags = append(flags, flag)
+			}
+			i++
+		}
+	}
+
+	points := make([]point, npoints)
+	coords := func(short, same byte, set func(p *point, v float64)) error {
+		v := 0
+		for p, flag := range flags {
+			switch {
+			case flag&short != 0:
+				if i >= len(b) {
+					return errTruncated
+				}
+				if flag&same != 0 {
+					v += int(b[i])
+				} else {
+					v -= int(b[i])
+				}
+				i++
+			case flag&same == 0:
+				if i+2 > len(b) {
+					return errTruncated
+				}
+				v += int(int16(u16(b, i)))
+				i += 2
+			}
+			set(&points[p], float64(v))
+		}
+		return nil
+	}
+	if err := coords(flagShortX, flagSameX, func(p *point, v float64) { p.x = v }); err != nil {
+		return nil, err
+	}
+	if err := coords(flagShortY, flagSameY, func(p *point, v float64) { p.y = v }); err != nil {
+		return nil, err
+	}
+	for p, flag := range flags {
+		points[p].onCurve = flag&flagOnCurve != 0
+	}
+
+	contours := make([][]point, 0, n)
+	start := 0
+	for _, end := range ends {
+		if end < start || end > npoints {
+			return nil, errors.New("invalid glyph contours")
+		}
+		contours = append(contours, points[start:end])
+		start = end
+	}
+	return contours, nil
+}
+
+// Flags of composite glyph components.
+const (
+	flagWords    = 0x0001
+	flagXYValues = 0x0002
+	flagScale    = 0x0008
+	flagMore     = 0x0020
+	flagXYScale  = 0x0040
+	flagTwoByTwo = 0x0080
+)
+
+func (f *Font) composite(b []byte, depth int) ([][]point, error) {
+	var contours [][]point
+	errTruncated := errors.New("truncated composite glyph")
+	i := 10
+	for {
+		if i+4 > len(b) {
+			return nil, errTruncated
+		}
+		flags, g := u16(b, i), int(u16(b, i+2))
+		i += 4
+
+		var dx, dy float64
+		if flags&flagWords != 0 {
+			if i+4 > len(b) {
+				return nil, errTruncated
+			}
+			dx, dy = float64(int16(u16(b, i))), float64(int16(u16(b, i+2)))
+			i += 4
+		} else {
+			if i+2 > len(b) {
+				return nil, errTruncated
+			}
+			dx, dy = float64(int8(b[i])), float64(int8(b[i+1]))
+			i += 2
+		}
+		if flags&flagXYValues == 0 {
+			// Components positioned by matching points are not supported.
+			dx, dy = 0, 0
+		}
+
+		a, bb, c, d := 1.0, 0.0, 0.0, 1.0
+		f2dot14 := func(j int) float64 { return float64(int16(u16(b, i+2*j))) / (1 << 14) }
+		switch {
+		case flags&flagScale != 0:
+			if i+2 > len(b) {
+				return nil, errTruncated
+			}
+			a, d = f2dot14(0), f2dot14(0)
+			i += 2
+		case flags&flagXYScale != 0:
+			if i+4 > len(b) {
+				return nil, errTruncated
+			}
+			a, d = f2dot14(0), f2dot14(1)
+			i += 4
+		case flags&flagTwoByTwo != 0:
+			if i+8 > len(b) {
+				return nil, errTruncated
+			}
+			a, bb, c, d = f2dot14(0), f2dot14(1), f2dot14(2), f2dot14(3)
+			i += 8
+		}
+
+		component, err := f.outline(g, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, contour := range component {
+			moved := make([]point, len(contour))
+			for j, p := range contour {
+				moved[j] = point{a*p.x + c*p.y + dx, bb*p.x + d*p.y + dy, p.onCurve}
+			}
+			contours = append(contours, moved)
+		}
+		if flags&flagMore == 0 {
+			return contours, nil
+		}
+	}
+}
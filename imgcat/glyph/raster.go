@@ -0,0 +1,185 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glyph
+
+import (
+	"image"
+	"math"
+)
+
+// A rasterizer fills outlines with anti-aliasing, accumulating the signed area
+// covered by each edge on every pixel.
+type rasterizer struct {
+	w, h int
+	acc  []float64
+}
+
+func (r *rasterizer) reset(w, h int) {
+	r.w, r.h = w, h
+	// Coverage can spill to the right of the last pixel.
+	r.acc = make([]float64, w*h+2)
+}
+
+// contour adds a closed TrueType contour, with off curve points as quadratic
+// control points, after converting its points to pixels with tr.
+func (r *rasterizer) contour(c []point, tr func(x, y float64) (float64, float64)) {
+	n := len(c)
+	if n == 0 {
+		return
+	}
+	pts := make([]point, n)
+	for i, p := range c {
+		x, y := tr(p.x, p.y)
+		pts[i] = point{x, y, p.onCurve}
+	}
+
+	first := -1
+	for i, p := range pts {
+		if p.onCurve {
+			first = i
+			break
+		}
+	}
+	var start point
+	var seq []point
+	if first < 0 {
+		// Without on curve points, the contour starts between the first and
+		// last control points.
+		start = mid(pts[n-1], pts[0])
+		seq = pts
+	} else {
+		start = pts[first]
+		seq = append(append(seq, pts[first+1:]...), pts[:first]...)
+	}
+
+	cur := start
+	var ctrl *point
+	for i := range seq {
+		p := seq[i]
+		if p.onCurve {
+			if ctrl != nil {
+				r.quad(cur, *ctrl, p)
+				ctrl = nil
+			} else {
+				r.line(cur, p)
+			}
+			cur = p
+			continue
+		}
+		if ctrl != nil {
+			m := mid(*ctrl, p)
+			r.quad(cur, *ctrl, m)
+			cur = m
+		}
+		ctrl = &seq[i]
+	}
+	if ctrl != nil {
+		r.quad(cur, *ctrl, start)
+	} else {
+		r.line(cur, start)
+	}
+}
+
+func mid(a, b point) point { return point{(a.x + b.x) / 2, (a.y + b.y) / 2, true} }
+
+// quad adds a quadratic Bézier curve, approximated with lines.
+func (r *rasterizer) quad(p0, p1, p2 point) {
+	dev := math.Hypot(p0.x-2*p1.x+p2.x, p0.y-2*p1.y+p2.y)
+	n := 1 + int(math.Sqrt(dev)*2)
+	if n > 64 {
+		n = 64
+	}
+	prev := p0
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		p := point{
+			x: u*u*p0.x + 2*u*t*p1.x + t*t*p2.x,
+			y: u*u*p0.y + 2*u*t*p1.y + t*t*p2.y,
+		}
+		r.line(prev, p)
+		prev = p
+	}
+}
+
+// line adds a line going from p0 to p1.
+func (r *rasterizer) line(p0, p1 point) {
+	if p0.y == p1.y {
+		return
+	}
+	dir := 1.0
+	if p0.y > p1.y {
+		dir, p0, p1 = -1, p1, p0
+	}
+	clampX := func(x float64) float64 { return math.Max(0, math.Min(float64(r.w), x)) }
+	p0.x, p1.x = clampX(p0.x), clampX(p1.x)
+
+	dxdy := (p1.x - p0.x) / (p1.y - p0.y)
+	x := p0.x
+	y0 := int(math.Max(0, math.Floor(p0.y)))
+	if p0.y < 0 {
+		x -= p0.y * dxdy
+	}
+	y1 := int(math.Min(float64(r.h), math.Ceil(p1.y)))
+	for y := y0; y < y1; y++ {
+		row := y * r.w
+		dy := math.Min(float64(y+1), p1.y) - math.Max(float64(y), p0.y)
+		next := x + dxdy*dy
+		d := dy * dir
+		x0, x1 := x, next
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0floor := math.Floor(x0)
+		x0i := int(x0floor)
+		x1ceil := math.Ceil(x1)
+		x1i := int(x1ceil)
+		if x1i <= x0i+1 {
+			xm := 0.5*(x+next) - x0floor
+			r.acc[row+x0i] += d - d*xm
+			r.acc[row+x0i+1] += d * xm
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1ceil + 1
+			am := 0.5 * s * x1f * x1f
+			r.acc[row+x0i] += d * a0
+			if x1i == x0i+2 {
+				r.acc[row+x0i+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				r.acc[row+x0i+1] += d * (a1 - a0)
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					r.acc[row+xi] += d * s
+				}
+				a2 := a1 + float64(x1i-x0i-3)*s
+				r.acc[row+x1i-1] += d * (1 - a2 - am)
+			}
+			r.acc[row+x1i] += d * am
+		}
+		x = next
+	}
+}
+
+// mask returns the coverage of the added contours.
+func (r *rasterizer) mask() *image.Alpha {
+	m := image.NewAlpha(image.Rect(0, 0, r.w, r.h))
+	sum := 0.0
+	for i := range m.Pix {
+		sum += r.acc[i]
+		m.Pix[i] = uint8(math.Min(1, math.Abs(sum))*0xff + 0.5)
+	}
+	return m
+}
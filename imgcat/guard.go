@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+
+	// Register the formats understood by the terminal so they can be checked.
+	_ "image/gif"
+	_ "image/jpeg"
+)
+
+// DefaultMaxPixels is a sensible limit for MaxPixels, a hundred megapixels.
+const DefaultMaxPixels = 100 * 1000 * 1000
+
+// ErrTooLarge is returned by Encode when an image has more pixels than allowed
+// by MaxPixels.
+var ErrTooLarge = errors.New("image too large")
+
+// MaxPixels rejects images whose dimensions, as declared by their header, add up
+// to more than n pixels. The check happens before any pixel data is decoded, so
+// it protects against decompression bombs when displaying untrusted files.
+// Payloads that are not in a known image format are sent unchanged.
+func MaxPixels(n int) Option {
+	return func(c *config) { c.maxPixels = n }
+}
+
+// Downsample scales images with more than n pixels down to fit in n pixels,
+// preserving the aspect ratio.
+// Since the image needs to be decoded, combine it with MaxPixels when the input
+// is not trusted.
+func Downsample(n int) Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		b := img.Bounds()
+		pixels := int64(b.Dx()) * int64(b.Dy())
+		if n <= 0 || pixels <= int64(n) {
+			return img, nil
+		}
+		scale := math.Sqrt(float64(n) / float64(pixels))
+		w := int(math.Max(1, math.Floor(float64(b.Dx())*scale)))
+		h := int(math.Max(1, math.Floor(float64(b.Dy())*scale)))
+		return Resize(img, w, h), nil
+	})
+}
+
+// guard checks the image read from r against the configured limits and returns
+// a reader for the payload to be sent.
+func (c *config) guard(r io.Reader) (io.Reader, error) {
+	if c.maxPixels <= 0 {
+		return r, nil
+	}
+
+	buf := new(bytes.Buffer)
+	ic, _, err := image.DecodeConfig(io.TeeReader(r, buf))
+	r = io.MultiReader(buf, r)
+	if err != nil {
+		// Not an image we understand, the terminal will decide what to do.
+		return r, nil
+	}
+
+	pixels := int64(ic.Width) * int64(ic.Height)
+	if pixels > int64(c.maxPixels) {
+		return nil, fmt.Errorf("%dx%d exceeds %d pixels: %w", ic.Width, ic.Height, c.maxPixels, ErrTooLarge)
+	}
+	return r, nil
+}
@@ -0,0 +1,89 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// testPNG returns a w by h PNG image filled with c.
+func testPNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("could not encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGuard(t *testing.T) {
+	img := testPNG(t, 20, 10, color.White)
+
+	tc := []struct {
+		name    string
+		in      []byte
+		options []Option
+		err     error
+		size    image.Point
+	}{
+		{"no limits", img, nil, nil, image.Pt(20, 10)},
+		{"under the limit", img, []Option{MaxPixels(200)}, nil, image.Pt(20, 10)},
+		{"over the limit", img, []Option{MaxPixels(199)}, ErrTooLarge, image.Point{}},
+		{"not downsampled", img, []Option{Downsample(200)}, nil, image.Pt(20, 10)},
+		{"downsampled", img, []Option{Downsample(50)}, nil, image.Pt(10, 5)},
+		{"limit wins over downsample", img, []Option{MaxPixels(100), Downsample(50)}, ErrTooLarge, image.Point{}},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newConfig(tt.options).prepare(bytes.NewReader(tt.in))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			ic, _, err := image.DecodeConfig(r)
+			if err != nil {
+				t.Fatalf("could not decode output: %v", err)
+			}
+			if got := image.Pt(ic.Width, ic.Height); got != tt.size {
+				t.Fatalf("expected size %v; got %v", tt.size, got)
+			}
+		})
+	}
+}
+
+func TestGuardUnknownFormat(t *testing.T) {
+	r, err := newConfig([]Option{MaxPixels(1)}).guard(strings.NewReader("test"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if string(b) != "test" {
+		t.Fatalf("expected payload %q; got %q", "test", b)
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 255})
+	img.Set(1, 0, color.NRGBA{255, 255, 255, 255})
+	got := Resize(img, 1, 1).NRGBAAt(0, 0)
+	if want := (color.NRGBA{127, 127, 127, 255}); got != want {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"github.com/campoy/tools/imgcat/colormap"
+)
+
+// A Matrix is a two dimensional array of values, indexed by row and column.
+// It is satisfied by the Matrix interface of gonum.org/v1/gonum/mat.
+type Matrix interface {
+	Dims() (r, c int)
+	At(i, j int) float64
+}
+
+// Grid adapts a slice of rows to the Matrix interface. Rows can have different
+// lengths, missing values are NaN.
+type Grid [][]float64
+
+// Dims returns the number of rows and the length of the longest row.
+func (g Grid) Dims() (r, c int) {
+	for _, row := range g {
+		if len(row) > c {
+			c = len(row)
+		}
+	}
+	return len(g), c
+}
+
+// At returns the value at row i and column j.
+func (g Grid) At(i, j int) float64 {
+	if j >= len(g[i]) {
+		return math.NaN()
+	}
+	return g[i][j]
+}
+
+// minHeatmapSide is the minimum length in pixels of the longest side of a
+// heatmap. Small matrices are scaled up so they're not displayed as a few dots.
+const minHeatmapSide = 256
+
+// Heatmap renders m as an image where each value is a square of a single color
+// picked by cmap, see the colormap package. Values are normalized to the range from lo to hi, and values
+// out of the range get the color of the closest end. If lo and hi are equal,
+// the range of the values in m is used. NaN values are transparent.
+func Heatmap(m Matrix, cmap colormap.Colormap, lo, hi float64) image.Image {
+	rows, cols := m.Dims()
+	if lo == hi {
+		lo, hi = valueRange(m)
+	}
+	side := rows
+	if cols > side {
+		side = cols
+	}
+	scale := 1
+	if side > 0 && side < minHeatmapSide {
+		scale = minHeatmapSide / side
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cols*scale, rows*scale))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			v := m.At(i, j)
+			if math.IsNaN(v) {
+				continue
+			}
+			t := 0.5
+			if hi != lo {
+				t = (v - lo) / (hi - lo)
+			}
+			c := cmap(t)
+			for y := i * scale; y < (i+1)*scale; y++ {
+				for x := j * scale; x < (j+1)*scale; x++ {
+					img.Set(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// valueRange returns the minimum and maximum values in m, ignoring NaN.
+func valueRange(m Matrix) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	rows, cols := m.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if v := m.At(i, j); !math.IsNaN(v) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+	if lo > hi {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+// EncodeHeatmap displays m as a heatmap, see Heatmap.
+// This is useful to look at weights, activations, or any other tensor while
+// debugging machine learning code in the terminal.
+func (enc *Encoder) EncodeHeatmap(m Matrix, cmap colormap.Colormap, lo, hi float64) error {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, Heatmap(m, cmap, lo, hi)); err != nil {
+		return fmt.Errorf("could not encode heatmap: %v", err)
+	}
+	return enc.Encode(buf)
+}
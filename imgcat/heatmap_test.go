@@ -0,0 +1,65 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/colormap"
+)
+
+func TestGrid(t *testing.T) {
+	g := Grid{{1, 2, 3}, {4}}
+	if r, c := g.Dims(); r != 2 || c != 3 {
+		t.Fatalf("expected 2x3; got %dx%d", r, c)
+	}
+	if v := g.At(1, 0); v != 4 {
+		t.Fatalf("expected 4; got %v", v)
+	}
+	if v := g.At(1, 2); !math.IsNaN(v) {
+		t.Fatalf("expected NaN; got %v", v)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	g := Grid{{0, 1}, {2, math.NaN()}}
+	img := Heatmap(g, colormap.Viridis, 0, 0)
+	if got := img.Bounds().Size(); got != image.Pt(256, 256) {
+		t.Fatalf("expected size 256x256; got %v", got)
+	}
+	if got, want := img.At(0, 0), colormap.Viridis(0); got != want {
+		t.Errorf("expected %v for the minimum; got %v", want, got)
+	}
+	if got, want := img.At(200, 10), colormap.Viridis(0.5); got != want {
+		t.Errorf("expected %v for the middle; got %v", want, got)
+	}
+	if got, want := img.At(10, 200), colormap.Viridis(1); got != want {
+		t.Errorf("expected %v for the maximum; got %v", want, got)
+	}
+	if _, _, _, a := img.At(200, 200).RGBA(); a != 0 {
+		t.Errorf("expected NaN to be transparent")
+	}
+
+	img = Heatmap(g, colormap.Viridis, 0, 4)
+	if got, want := img.At(10, 200), colormap.Viridis(0.5); got != want {
+		t.Errorf("expected %v with a fixed range; got %v", want, got)
+	}
+}
+
+func TestEncodeHeatmap(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Inline(true))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.EncodeHeatmap(Grid{{1, 2}}, colormap.Magma, 0, 0); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("inline=1;size=")) || !bytes.Contains(buf.Bytes(), []byte(":iVBORw0KGgo")) {
+		t.Fatalf("expected an inline PNG; got %q", buf.Bytes()[:40])
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// A Histogram counts the pixels of an image by the value of their red, green,
+// and blue channels, and of their luma.
+type Histogram struct {
+	Red, Green, Blue, Luma [256]int
+}
+
+// HistogramOf returns the histogram of img. Transparent pixels are not counted.
+func HistogramOf(img image.Image) *Histogram {
+	h := new(Histogram)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				continue
+			}
+			h.Red[c.R]++
+			h.Green[c.G]++
+			h.Blue[c.B]++
+			h.Luma[color.GrayModel.Convert(color.RGBA{c.R, c.G, c.B, 0xff}).(color.Gray).Y]++
+		}
+	}
+	return h
+}
+
+// Draw returns a w by h chart of the histogram, with the channels drawn over
+// each other on a translucent dark background and the luma as a white line.
+// Every bin is scaled to the largest one, so the shape is visible even when
+// most pixels have the same value.
+func (h *Histogram) Draw(w, ht int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, ht))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA{0x10, 0x10, 0x10, 0xc0}), image.Point{}, draw.Src)
+	if w <= 0 || ht <= 0 {
+		return dst
+	}
+
+	peak := 1
+	for _, bins := range []*[256]int{&h.Red, &h.Green, &h.Blue, &h.Luma} {
+		for _, n := range bins {
+			if n > peak {
+				peak = n
+			}
+		}
+	}
+	// height returns how many rows tall a bin covering column x is.
+	height := func(bins *[256]int, x int) int {
+		lo, hi := x*256/w, (x+1)*256/w
+		if hi <= lo {
+			hi = lo + 1
+		}
+		n := 0
+		for i := lo; i < hi; i++ {
+			n += bins[i]
+		}
+		return n * ht / ((hi - lo) * peak)
+	}
+
+	for x := 0; x < w; x++ {
+		r, g, b := height(&h.Red, x), height(&h.Green, x), height(&h.Blue, x)
+		for y := 0; y < ht; y++ {
+			up := ht - y
+			var c color.RGBA
+			if up <= r {
+				c.R = 0xd0
+			}
+			if up <= g {
+				c.G = 0xd0
+			}
+			if up <= b {
+				c.B = 0xd0
+			}
+			if c != (color.RGBA{}) {
+				c.A = 0xff
+				dst.SetRGBA(x, y, c)
+			}
+		}
+		luma := height(&h.Luma, x)
+		if luma > ht-1 {
+			luma = ht - 1
+		}
+		dst.SetRGBA(x, ht-1-luma, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	}
+	return dst
+}
+
+// ShowHistogram draws the histogram of the image over its bottom right corner,
+// to check the exposure of photos without leaving the terminal.
+// The chart takes a third of the width of the image, and at least 128 pixels
+// unless the image is smaller than that.
+func ShowHistogram() Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		b := img.Bounds()
+		w := b.Dx() / 3
+		if w < 128 {
+			w = 128
+		}
+		if w > b.Dx() {
+			w = b.Dx()
+		}
+		ht := w / 2
+		if ht > b.Dy() {
+			ht = b.Dy()
+		}
+		chart := HistogramOf(img).Draw(w, ht)
+
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+		at := image.Pt(b.Dx()-w, b.Dy()-ht)
+		draw.Draw(dst, chart.Bounds().Add(at), chart, image.Point{}, draw.Over)
+		return dst, nil
+	})
+}
@@ -0,0 +1,100 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestHistogramOf(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(img, image.Rect(0, 0, 4, 2), image.NewUniform(color.NRGBA{0xff, 0, 0, 0xff}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 2, 4, 3), image.NewUniform(color.NRGBA{0x80, 0x80, 0x80, 0xff}), image.Point{}, draw.Src)
+	// The bottom row is transparent.
+	h := HistogramOf(img)
+
+	tc := []struct {
+		name  string
+		bins  *[256]int
+		value int
+		n     int
+	}{
+		{"red", &h.Red, 0xff, 8},
+		{"red", &h.Red, 0x80, 4},
+		{"green", &h.Green, 0, 8},
+		{"blue", &h.Blue, 0x80, 4},
+		{"luma", &h.Luma, 76, 8},
+		{"luma", &h.Luma, 0x80, 4},
+		{"luma", &h.Luma, 0, 0},
+	}
+	for _, tt := range tc {
+		if got := tt.bins[tt.value]; got != tt.n {
+			t.Errorf("expected %d pixels with %s %d; got %d", tt.n, tt.name, tt.value, got)
+		}
+	}
+}
+
+func TestHistogramDraw(t *testing.T) {
+	h := HistogramOf(uniform(4, 4, color.RGBA{0xff, 0, 0, 0xff}))
+	img := h.Draw(256, 100)
+	if img.Bounds() != image.Rect(0, 0, 256, 100) {
+		t.Fatalf("expected 256x100 chart; got %v", img.Bounds())
+	}
+	tc := []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		// The red bin is full height, and so are the green and blue ones at zero.
+		{255, 0, color.RGBA{0xd0, 0, 0, 0xff}},
+		{0, 50, color.RGBA{0, 0xd0, 0xd0, 0xff}},
+		{128, 50, color.RGBA{0x10, 0x10, 0x10, 0xc0}},
+		// The luma is a line at the top of its bins.
+		{76, 0, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{128, 99, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tc {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.c {
+			t.Errorf("expected %v at %d,%d; got %v", tt.c, tt.x, tt.y, got)
+		}
+	}
+}
+
+func TestShowHistogram(t *testing.T) {
+	tc := []struct {
+		name   string
+		w, h   int
+		chart  image.Rectangle
+		inside image.Point
+	}{
+		{"large", 600, 400, image.Rect(400, 300, 600, 400), image.Pt(10, 10)},
+		{"small", 200, 200, image.Rect(72, 136, 200, 200), image.Pt(10, 10)},
+		{"tiny", 50, 10, image.Rect(0, 0, 50, 10), image.Pt(-1, -1)},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig([]Option{ShowHistogram()})
+			out, err := cfg.transforms[0].Apply(uniform(tt.w, tt.h, color.White))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Bounds() != image.Rect(0, 0, tt.w, tt.h) {
+				t.Fatalf("expected size to be kept; got %v", out.Bounds())
+			}
+			white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+			if c := color.RGBAModel.Convert(out.At(tt.chart.Min.X, tt.chart.Min.Y+tt.chart.Dy()/2)); c == white {
+				t.Errorf("expected chart in %v", tt.chart)
+			}
+			if tt.chart.Min.X > 0 {
+				if c := color.RGBAModel.Convert(out.At(tt.chart.Min.X-1, tt.chart.Min.Y)); c != white {
+					t.Errorf("expected image left of the chart; got %v", c)
+				}
+			}
+			if p := tt.inside; p.X >= 0 {
+				if c := color.RGBAModel.Convert(out.At(p.X, p.Y)); c != white {
+					t.Errorf("expected image at %v; got %v", p, c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpcache provides an implementation of http.RoundTripper that
+// caches responses, so that displaying the same remote images again, such as
+// badges and avatars, doesn't download them again.
+//
+// It follows the Cache-Control, Expires, ETag, and Last-Modified headers of
+// responses as a private cache does: fresh responses are returned from the
+// cache, and stale ones are revalidated with conditional requests when they
+// can be. Only successful responses to GET requests are cached, and responses
+// varying with request headers are not.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromCache is the header set on responses returned from the cache.
+const FromCache = "X-From-Cache"
+
+// A Store holds cached responses, by key.
+type Store interface {
+	// Get returns the value stored under key, and whether there was one.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key.
+	Set(key string, value []byte)
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// Transport satisfies http.RoundTripper.
+type Transport struct {
+	transport http.RoundTripper
+	store     Store
+}
+
+// NewTransport returns a new Transport that uses the given RoundTripper, or
+// http.DefaultTransport if nil, and caches responses in store.
+func NewTransport(rt http.RoundTripper, store Store) *Transport {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &Transport{rt, store}
+}
+
+// Client returns a new http.Client using the given transport.
+func (t *Transport) Client() *http.Client { return &http.Client{Transport: t} }
+
+// Can be swapped for testing.
+var now = time.Now
+
+// RoundTrip so Transport satisfies http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || req.Header.Get("Range") != "" {
+		return t.transport.RoundTrip(req)
+	}
+	key := req.URL.String()
+	cached := t.load(key, req)
+	if cached != nil && fresh(cached.Header) && !noCache(req.Header) {
+		return cached, nil
+	}
+
+	if cached != nil {
+		// Revalidate the cached response, on a copy of the request as
+		// RoundTrippers must not modify it.
+		etag, modified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+		if etag != "" || modified != "" {
+			r := new(http.Request)
+			*r = *req
+			r.Header = req.Header.Clone()
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+			if etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+			if modified != "" {
+				r.Header.Set("If-Modified-Since", modified)
+			}
+			req = r
+		}
+	}
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		for _, h := range []string{"Date", "Cache-Control", "Expires", "ETag", "Last-Modified"} {
+			if v := res.Header.Get(h); v != "" {
+				cached.Header.Set(h, v)
+			}
+		}
+		if err := t.save(key, cached); err != nil {
+			return nil, err
+		}
+		cached.Header.Set(FromCache, "1")
+		return cached, nil
+	}
+	if !storable(res) {
+		if res.StatusCode == http.StatusOK {
+			t.store.Delete(key)
+		}
+		return res, nil
+	}
+	if res.Header.Get("Date") == "" {
+		res.Header.Set("Date", now().UTC().Format(http.TimeFormat))
+	}
+	// Saving reads the body, and replaces it with what was read.
+	if err := t.save(key, res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+// load returns the response to req stored under key, or nil if there's none.
+func (t *Transport) load(key string, req *http.Request) *http.Response {
+	b, ok := t.store.Get(key)
+	if !ok {
+		return nil
+	}
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+	if err != nil {
+		// Corrupt entries are dropped.
+		t.store.Delete(key)
+		return nil
+	}
+	res.Header.Set(FromCache, "1")
+	return res
+}
+
+// save stores res under key, reading its body.
+func (t *Transport) save(key string, res *http.Response) error {
+	res.Header.Del(FromCache)
+	b, err := httputil.DumpResponse(res, true)
+	if err != nil {
+		return err
+	}
+	t.store.Set(key, b)
+	return nil
+}
+
+// storable reports whether res can be cached.
+func storable(res *http.Response) bool {
+	if res.StatusCode != http.StatusOK || res.Header.Get("Vary") != "" {
+		return false
+	}
+	cc := cacheControl(res.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	// Responses that can't be revalidated nor be fresh are useless.
+	_, maxAge := cc["max-age"]
+	return maxAge || res.Header.Get("Expires") != "" || res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""
+}
+
+// fresh reports whether the response with the given header can be used
+// without revalidation.
+func fresh(h http.Header) bool {
+	cc := cacheControl(h)
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		return false
+	}
+	if v, ok := cc["max-age"]; ok {
+		age, err := strconv.Atoi(v)
+		return err == nil && now().Before(date.Add(time.Duration(age)*time.Second))
+	}
+	expires, err := http.ParseTime(h.Get("Expires"))
+	return err == nil && now().Before(expires)
+}
+
+// noCache reports whether the request with the given header asks for a
+// response that is not cached.
+func noCache(h http.Header) bool {
+	_, ok := cacheControl(h)["no-cache"]
+	return ok || h.Get("Pragma") == "no-cache"
+}
+
+// cacheControl returns the directives of the Cache-Control header in h, by
+// name, with their values if any.
+func cacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, d := range strings.Split(h.Get("Cache-Control"), ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		name, value := d, ""
+		if i := strings.Index(d, "="); i >= 0 {
+			name, value = d[:i], strings.Trim(d[i+1:], `"`)
+		}
+		cc[strings.ToLower(name)] = value
+	}
+	return cc
+}
@@ -0,0 +1,158 @@
+package httpcache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server returns a test server answering with body and the given headers,
+// along with the number of requests it got.
+func server(header http.Header, body string) (*httptest.Server, *int32) {
+	var hits int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		for k, v := range header {
+			w.Header()[http.CanonicalHeaderKey(k)] = v
+		}
+		if etag := w.Header().Get("ETag"); etag != "" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	return s, &hits
+}
+
+// get fetches url with client, and returns the body and whether it came from
+// the cache.
+func get(t *testing.T, client *http.Client, url string) (string, bool) {
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("could not fetch: %v", err)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read body: %v", err)
+	}
+	return string(b), res.Header.Get(FromCache) != ""
+}
+
+func TestTransport(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	start := time.Now()
+	var clock time.Time
+	now = func() time.Time { return clock }
+
+	tc := []struct {
+		name   string
+		header http.Header
+		// wait before the second request.
+		wait time.Duration
+		// requests the server gets for two fetches.
+		hits   int32
+		cached bool
+	}{
+		{"fresh", http.Header{"Cache-Control": {"max-age=60"}}, time.Second, 1, true},
+		{"stale", http.Header{"Cache-Control": {"max-age=60"}}, time.Hour, 2, false},
+		{"expires", http.Header{"Expires": {start.Add(time.Minute).UTC().Format(http.TimeFormat)}}, 0, 1, true},
+		{"revalidated", http.Header{"ETag": {`"v1"`}}, 0, 2, true},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache"}, "ETag": {`"v1"`}}, 0, 2, true},
+		{"no-store", http.Header{"Cache-Control": {"no-store, max-age=60"}}, 0, 2, false},
+		{"vary", http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept"}}, 0, 2, false},
+		{"no validators", nil, 0, 2, false},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			clock = start
+			s, hits := server(tt.header, "image")
+			defer s.Close()
+			client := NewTransport(nil, NewMemoryStore()).Client()
+
+			if body, cached := get(t, client, s.URL); body != "image" || cached {
+				t.Fatalf("expected image fetched; got %q, cached %v", body, cached)
+			}
+			clock = clock.Add(tt.wait)
+			body, cached := get(t, client, s.URL)
+			if body != "image" || cached != tt.cached {
+				t.Fatalf("expected image, cached %v; got %q, cached %v", tt.cached, body, cached)
+			}
+			if n := atomic.LoadInt32(hits); n != tt.hits {
+				t.Fatalf("expected %d requests; got %d", tt.hits, n)
+			}
+		})
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	var hits int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		http.NotFound(w, r)
+	}))
+	defer s.Close()
+	client := NewTransport(nil, NewMemoryStore()).Client()
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(s.URL)
+		if err != nil {
+			t.Fatalf("could not fetch: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected not found; got %s", res.Status)
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("expected errors not cached; got %d requests", hits)
+	}
+
+	// Corrupt entries are dropped.
+	store := NewMemoryStore()
+	store.Set(s.URL, []byte("garbage"))
+	client = NewTransport(nil, store).Client()
+	if res, err := client.Get(s.URL); err != nil {
+		t.Fatalf("could not fetch: %v", err)
+	} else {
+		res.Body.Close()
+	}
+	if _, ok := store.Get(s.URL); ok {
+		t.Fatalf("expected the corrupt entry dropped")
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, hits := server(http.Header{"Cache-Control": {"max-age=60"}}, "image")
+	defer s.Close()
+	for i := 0; i < 2; i++ {
+		// Every process opens the store again.
+		store, err := NewDiskStore(dir)
+		if err != nil {
+			t.Fatalf("could not open store: %v", err)
+		}
+		body, cached := get(t, NewTransport(nil, store).Client(), s.URL)
+		if body != "image" || cached != (i == 1) {
+			t.Fatalf("unexpected response %d: %q, cached %v", i, body, cached)
+		}
+	}
+	if *hits != 1 {
+		t.Fatalf("expected a single request; got %d", *hits)
+	}
+
+	store, _ := NewDiskStore(dir)
+	store.Delete(s.URL)
+	if _, ok := store.Get(s.URL); ok {
+		t.Fatalf("expected the entry deleted")
+	}
+}
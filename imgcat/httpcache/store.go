@@ -0,0 +1,112 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpcache
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Dir returns the default directory of disk stores, imgcat/http in the user's
+// cache directory: $XDG_CACHE_HOME or ~/.cache on Linux, and ~/Library/Caches
+// on macOS.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "imgcat", "http"), nil
+}
+
+// DiskStore is a Store keeping values in files of a directory, which can be
+// shared by several processes.
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore returns a DiskStore keeping values in dir, which is created if
+// needed.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create cache: %v", err)
+	}
+	return &DiskStore{dir}, nil
+}
+
+func (s *DiskStore) path(key string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+}
+
+// Get returns the value stored under key, and whether there was one.
+func (s *DiskStore) Get(key string) ([]byte, bool) {
+	b, err := ioutil.ReadFile(s.path(key))
+	return b, err == nil
+}
+
+// Set stores value under key. Values are written atomically, so concurrent
+// readers never see part of them. Failing to write only makes a cache miss.
+func (s *DiskStore) Set(key string, value []byte) {
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(value)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes the value stored under key, if any.
+func (s *DiskStore) Delete(key string) { os.Remove(s.path(key)) }
+
+// MemoryStore is a Store keeping values in memory, safe for concurrent use.
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore { return &MemoryStore{values: map[string][]byte{}} }
+
+// Get returns the value stored under key, and whether there was one.
+func (s *MemoryStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (s *MemoryStore) Set(key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes the value stored under key, if any.
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
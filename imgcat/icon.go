@@ -0,0 +1,43 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+
+	"github.com/campoy/tools/imgcat/glyph"
+)
+
+// IconSize is the size in pixels at which EncodeIcon renders glyphs, large
+// enough to be crisp at any cell size.
+const IconSize = 128
+
+// EncodeIcon displays the glyph for r in the given font, such as an emoji or a
+// Nerd Font symbol, as an image. This gives crisp status icons in prompts and
+// dashboards even when the terminal lacks the font or its fallback is ugly.
+// Use Height(Cells(1)) and Newline(false) to display icons inline with text.
+func (enc *Encoder) EncodeIcon(f *glyph.Font, r rune, c color.Color) error {
+	img, err := f.Render(r, IconSize, c)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return fmt.Errorf("could not encode icon: %v", err)
+	}
+	return enc.Encode(buf)
+}
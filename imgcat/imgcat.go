@@ -12,20 +12,153 @@
 // limitations under the License.
 
 // Package imgcat provides a writer useful to show images directly into iterm2.
+// It also provides helpers for other iTerm2 proprietary commands, to set the
+// badge, background, or user variables of the current session.
 // Tmux support works best using iterm2 tmux integration.
 package imgcat
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"strings"
 )
 
-// An Option modifies how an image is displayed.
-type Option string
+// An Option modifies how an image is displayed. Options are applied in order,
+// so when the same option is given more than once, such as a default Width
+// and another one for a single image, the last one wins. Options adding header
+// arguments keep them where they were first given.
+type Option func(*config)
+
+// config holds the settings built from a list of options.
+type config struct {
+	// header arguments, in the order they were given.
+	args []string
+	// payload of the image the options are applied for, used by computed
+	// options, nil if unknown.
+	payload io.Reader
+	// maximum number of pixels allowed, zero means no limit.
+	maxPixels int
+	// transformations applied to the image, in order.
+	transforms []Transform
+	// format of the images re-encoded after transforms, empty means PNG.
+	reencode Format
+	// formats allowed to be sent, nil means any.
+	formats []Format
+	// receives a copy of the original payload, if not nil.
+	tee io.Writer
+	// called with the SHA-256 of the payload once it has been sent.
+	checksum func(sum []byte)
+	// size of the parts in multipart transfers, zero means no multipart.
+	partSize int
+	// whether escape sequences are wrapped for tmux, nil means IsTmux.
+	passthrough *bool
+	// whether to omit the newline written after each image.
+	noNewline bool
+	// number of newlines written after each image instead, zero means one.
+	padRows int
+	// maximum size of the payload in bytes, zero means no limit.
+	targetBytes int
+	// maximum height of images in rows, relative to the terminal when zero
+	// or negative, used only if limitRows is set.
+	maxRows   int
+	limitRows bool
+	// horizontal placement of images, see Align.
+	align Alignment
+	// columns images are moved right by, set by place.
+	indent int
+	// bandwidth shared with other Encoders, if not nil.
+	budget *Budget
+	// told about every image sent, if not nil.
+	tracer Tracer
+	// counts the images sent, if not nil.
+	metrics *Metrics
+	// collects the event of the image being traced, nil if not traced.
+	trace *trace
+	// first option that couldn't be applied, returned by Encode.
+	err error
+	// protocols NewEncoder picks from, nil means only ITerm2.
+	chain []Protocol
+	// whether NewEncoder falls back to Summary instead of failing.
+	silent bool
+	// URL images are hyperlinked to, empty means none.
+	link string
+	// fetches the images of EncodeURL, nil means http.DefaultClient.
+	client *http.Client
+	// how images are transmitted with the Kitty protocol, empty means Direct.
+	medium Medium
+	// whether Kitty images are sent as compressed pixels when smaller.
+	compress bool
+}
+
+func newConfig(options []Option) *config { return configFor(nil, options) }
+
+// configFor returns the config for sending the payload read from r, which
+// computed options are resolved with.
+func configFor(r io.Reader, options []Option) *config {
+	c := &config{payload: r}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ErrOption is returned by Encode when an option has a value that can't be
+// sent in the header of the escape sequence, such as a Length made of control
+// characters or header separators.
+var ErrOption = errors.New("invalid option")
+
+// header returns an Option that sets key=value in the escape sequence header.
+func header(key, value string) Option {
+	return func(c *config) {
+		if !safeArg(value) {
+			if c.err == nil {
+				c.err = fmt.Errorf("%s=%q: %w", key, value, ErrOption)
+			}
+			return
+		}
+		c.setArg(key, value)
+	}
+}
+
+// safeArg reports whether s can be sent as is in an escape sequence, as the
+// value of a header argument: it's made of printable ASCII characters, other
+// than the ; and : separating arguments and the payload.
+func safeArg(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' || s[i] == ';' || s[i] == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// setArg sets key=value in the header, replacing the value given before for
+// the same key if any.
+func (c *config) setArg(key, value string) {
+	if i := c.argIndex(key); i >= 0 {
+		c.args[i] = key + "=" + value
+		return
+	}
+	c.args = append(c.args, key+"="+value)
+}
+
+// argIndex returns the index of the header argument for key, or -1.
+func (c *config) argIndex(key string) int {
+	for i, arg := range c.args {
+		if strings.HasPrefix(arg, key+"=") {
+			return i
+		}
+	}
+	return -1
+}
 
 // Length is used by the Width and Height options.
 type Length string
@@ -42,6 +175,15 @@ func Percent(x int) Length { return Length(fmt.Sprintf("%d%%", x)) }
 // Auto keeps the image to its inherent size.
 func Auto() Length { return Length("auto") }
 
+// ParseLength parses a length written as by Cells, Pixels, Percent, or Auto,
+// such as 40, 400px, 50%, or auto.
+func ParseLength(s string) (Length, error) {
+	if !lengthPattern.MatchString(s) {
+		return "", fmt.Errorf("invalid length %q, expected cells, pixels, a percentage, or auto", s)
+	}
+	return Length(s), nil
+}
+
 // Name sents the filename for the image. Defaults to "Unnamed file".
 func Name(name string) Option {
 	buf := new(bytes.Buffer)
@@ -50,22 +192,40 @@ func Name(name string) Option {
 	if err := enc.Close(); err != nil {
 		log.Fatalf("could not encode to buffer: %v", err)
 	}
-	return Option(fmt.Sprintf("name=%s", buf))
+	return header("name", buf.String())
 }
 
 // Size sets the file size in bytes. It's only used by the progress indicator.
+// When it's not set, the size is found by seeking payloads read from an
+// io.Seeker, such as files, and known for payloads that are re-encoded.
 func Size(size int) Option {
-	return Option(fmt.Sprintf("size=%d", size))
+	return header("size", fmt.Sprint(size))
+}
+
+// Arg adds an argument given as key=value to the header of the escape
+// sequence, for the arguments of iTerm2 that no other option sets. Option
+// used to be a string holding such an argument: Arg(s) replaces conversions
+// such as Option(s).
+func Arg(arg string) Option {
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok || key == "" || !safeArg(key) {
+		return func(c *config) {
+			if c.err == nil {
+				c.err = fmt.Errorf("%q is not key=value: %w", arg, ErrOption)
+			}
+		}
+	}
+	return header(key, value)
 }
 
 // Width to render, it can be in cells, pixels, percentage, or auto.
 func Width(l Length) Option {
-	return Option(fmt.Sprintf("width=%s", l))
+	return header("width", string(l))
 }
 
 // Height to render, it can be in cells, pixels, percentage, or auto.
 func Height(l Length) Option {
-	return Option(fmt.Sprintf("height=%s", l))
+	return header("height", string(l))
 }
 
 func boolToInt(b bool) int {
@@ -80,7 +240,7 @@ func boolToInt(b bool) int {
 // specified width and height as much as possible without stretching.
 // Defaults to true.
 func PreserveAspectRatio(b bool) Option {
-	return Option(fmt.Sprintf("preserveAspectRatio=%d", boolToInt(b)))
+	return header("preserveAspectRatio", fmt.Sprint(boolToInt(b)))
 }
 
 // Inline set to true causes the to be displayed inline.
@@ -88,14 +248,57 @@ func PreserveAspectRatio(b bool) Option {
 // representation in the terminal session.
 // Defaults to false.
 func Inline(b bool) Option {
-	return Option(fmt.Sprintf("inline=%d", boolToInt(b)))
+	return header("inline", fmt.Sprint(boolToInt(b)))
+}
+
+// Passthrough sets whether escape sequences are wrapped for tmux. By default
+// they are wrapped when IsTmux reports true. This is useful when the output is
+// read by a program running inside tmux that displays the images itself and
+// takes care of the wrapping, such as the preview window of fzf.
+func Passthrough(b bool) Option {
+	return func(c *config) { c.passthrough = &b }
+}
+
+// Newline set to false omits the newline written after each image, leaving the
+// cursor right after it. Defaults to true.
+func Newline(b bool) Option {
+	return func(c *config) { c.noNewline = !b }
+}
+
+// MoveCursor set to false draws the image without moving the cursor, which is
+// left where the image starts. This is supported by iTerm2 3.5 and later.
+// Defaults to true.
+func MoveCursor(b bool) Option {
+	return header("doNotMoveCursor", fmt.Sprint(boolToInt(!b)))
+}
+
+func (c *config) tmux() bool {
+	if c.passthrough != nil {
+		return *c.passthrough
+	}
+	return IsTmux()
+}
+
+func (c *config) newline() string {
+	if c.padRows > 0 {
+		return strings.Repeat("\n", c.padRows)
+	}
+	if c.noNewline {
+		return ""
+	}
+	return "\n"
 }
 
 // IsSupported check whether imgcat works in the current terminal.
+// The detection can be overridden with the IMGCAT_PROTOCOL environment
+// variable, see EnvProtocol.
 func IsSupported() bool { return isSupported() }
 
 // Can be swapped for testing.
 var isSupported = func() bool {
+	if ok, forced := protocols[os.Getenv(EnvProtocol)]; forced {
+		return ok
+	}
 	return os.Getenv("TERM_PROGRAM") == "iTerm.app"
 }
 
@@ -120,51 +323,99 @@ var isTmux = func() bool {
 }
 
 //set the header string depending on whether we are in tmux.
-func headerEscape() string {
-	if IsTmux() {
+func headerEscape(tmux bool) string {
+	if tmux {
 		return "\x1bPtmux;\x1b\x1b]1337;File="
 	}
 	return "\x1b]1337;File="
 }
 
 //set the header string depending on whether we are in tmux.
-func footerEscape() string {
-	if IsTmux() {
-		return "\a\x1b\\\n"
+func footerEscape(tmux bool) string {
+	if tmux {
+		return "\a\x1b\\"
 	}
-	return "\a\n"
+	return "\a"
 }
 
-// NewEncoder returns a encoder that encodes images for iterm2.
+// NewEncoder returns a encoder that encodes images for iterm2, or with the
+// first protocol supported in its FallbackChain, or with the renderer named by
+// EnvProtocol, see RegisterRenderer.
 func NewEncoder(w io.Writer, options ...Option) (*Encoder, error) {
-	if !IsSupported() {
-		return nil, fmt.Errorf("imgcat is only supported with iTerm2")
+	cfg := newConfig(options)
+	p, err := ITerm2, error(nil)
+	if forced := Protocol(os.Getenv(EnvProtocol)); cfg.chain == nil && rendererOf(forced) != nil {
+		p = forced
+	} else if cfg.chain == nil {
+		if !IsSupported() {
+			err = fmt.Errorf("imgcat is only supported with iTerm2")
+		}
+	} else {
+		p, err = pickProtocol(cfg.chain, w)
 	}
-
-	enc := &Encoder{out: w, options: options}
-
-	return enc, nil
+	if err != nil {
+		if !cfg.silent {
+			return nil, err
+		}
+		p = Summary
+	}
+	return &Encoder{out: w, options: options, protocol: p}, nil
 }
 
 // An Encoder is used to encode images to iterm2.
 type Encoder struct {
 	out     io.Writer
 	options []Option
+	// protocol images are displayed with, empty means ITerm2.
+	protocol Protocol
+	// multipart transfer that was interrupted, if any.
+	pending *transfer
 }
 
 // Encode encodes the given image into the output.
 func (enc *Encoder) Encode(r io.Reader) error {
-	header := new(bytes.Buffer)
-	fmt.Fprint(header, headerEscape())
-	for i, option := range enc.options {
-		fmt.Fprintf(header, "%s", option)
-		if i < len(enc.options)-1 {
-			fmt.Fprintf(header, ";")
-		}
+	enc.pending = nil
+	cfg := configFor(r, enc.options)
+	if cfg.tracer != nil || cfg.metrics != nil {
+		return enc.traced(cfg, r)
+	}
+	return enc.encode(cfg, r)
+}
+
+func (enc *Encoder) encode(cfg *config, r io.Reader) error {
+	var src *resumable
+	if cfg.partSize > 0 {
+		src = &resumable{r: r}
+		r = src
+	}
+	r, err := cfg.prepare(r)
+	if err != nil {
+		return err
+	}
+	if p := enc.Protocol(); p != ITerm2 {
+		return enc.render(cfg, p, r)
+	}
+	if cfg.partSize > 0 {
+		cfg.trace.notef("multipart transfer in parts of %d bytes", cfg.partSize)
+		enc.pending = newTransfer(cfg, src, r)
+		return enc.sendParts()
+	}
+
+	var sum hash.Hash
+	if cfg.checksum != nil {
+		sum = sha256.New()
+		r = io.TeeReader(r, sum)
 	}
+
+	header := new(bytes.Buffer)
+	fmt.Fprint(header, cursorRight(cfg.indent)+cfg.linkStart())
+	fmt.Fprint(header, headerEscape(cfg.tmux()))
+	fmt.Fprint(header, strings.Join(cfg.args, ";"))
 	fmt.Fprintf(header, ":")
 	pr, pw := io.Pipe()
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		enc := base64.NewEncoder(base64.StdEncoding, pw)
 		defer func() {
 			if err := enc.Close(); err != nil {
@@ -184,10 +435,125 @@ func (enc *Encoder) Encode(r io.Reader) error {
 		}
 	}()
 
-	footer := bytes.NewBufferString(footerEscape())
+	footer := bytes.NewBufferString(footerEscape(cfg.tmux()) + cfg.linkEnd() + cfg.newline())
+
+	_, err = io.Copy(enc.out, io.MultiReader(header, pr, footer))
+	// Stop reading the payload if writing failed. Traced encodes wait for it,
+	// so the bytes read are counted once Encode returns.
+	// always returns nil according to specs.
+	_ = pr.Close()
+	if cfg.trace != nil {
+		<-done
+	}
+	if err != nil {
+		return err
+	}
+	if sum != nil {
+		cfg.checksum(sum.Sum(nil))
+	}
+	return nil
+}
+
+// EncodeToBytes returns the escape sequence that displays the image read from r
+// with the given options, rather than writing it. This is useful to embed images
+// in other writers or templates, or to send them over custom transports.
+// Unlike NewEncoder, it doesn't check whether the current terminal is supported.
+func EncodeToBytes(r io.Reader, options ...Option) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: options}
+	if err := enc.Encode(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// prepare runs the payload read from r through the configured checks and
+// returns a reader for what needs to be sent.
+func (c *config) prepare(r io.Reader) (io.Reader, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	size, err := seekSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if r, err = c.sniff(r); err != nil {
+		return nil, err
+	}
+	if r, err = c.guard(r); err != nil {
+		return nil, err
+	}
+	r, accepted := c.teeReader(r)
+	if r, err = c.transform(r); err != nil {
+		return nil, err
+	}
+	if r, err = c.fit(r); err != nil {
+		return nil, err
+	}
+	if r, err = c.spend(r); err != nil {
+		return nil, err
+	}
+	if err := accepted(); err != nil {
+		return nil, err
+	}
+	// Transformed and re-encoded payloads are held in memory, so their size is
+	// known, as is the size of buffers given to Encode.
+	if b, ok := unwrap(r).(interface{ Len() int }); ok {
+		size = int64(b.Len())
+	}
+	c.setSize(size)
+	r = c.place(r)
+	return c.trace.count(r), nil
+}
+
+// seekSize returns how many bytes are left to read from r if it's an
+// io.Seeker, leaving it where it was, or -1 if r can't seek.
+func seekSize(r io.Reader) (int64, error) {
+	s, ok := unwrap(r).(io.Seeker)
+	if !ok {
+		return -1, nil
+	}
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// Not all files can seek, such as pipes.
+		return -1, nil
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, nil
+	}
+	if _, err := s.Seek(cur, io.SeekStart); err != nil {
+		return -1, fmt.Errorf("could not rewind payload: %v", err)
+	}
+	if end <= cur {
+		// Devices such as terminals seek without moving.
+		return -1, nil
+	}
+	return end - cur, nil
+}
+
+// unwrap returns the reader of the payload given to Encode, which is read
+// through a counter when traced, and can be resumed for multipart transfers.
+func unwrap(r io.Reader) io.Reader {
+	for {
+		switch w := r.(type) {
+		case *counter:
+			r = w.r
+		case *resumable:
+			r = w.r
+		default:
+			return r
+		}
+	}
+}
 
-	_, err := io.Copy(enc.out, io.MultiReader(header, pr, footer))
-	return err
+// setSize adds the size of the payload to the header, unless it's unknown or
+// was given with Size.
+func (c *config) setSize(size int64) {
+	if size < 0 || c.argIndex("size") >= 0 {
+		return
+	}
+	c.setArg("size", fmt.Sprint(size))
 }
 
 // Writer creates a writer that will encode whatever is written to it.
@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+// configKeys maps the keys of the configuration file to the environment
+// variables they set.
+var configKeys = map[string]string{
+	"protocol":   imgcat.EnvProtocol,
+	"max_pixels": imgcat.EnvMaxPixels,
+	"max_bytes":  imgcat.EnvMaxBytes,
+	"width":      imgcat.EnvWidth,
+	"height":     imgcat.EnvHeight,
+}
+
+// configPath returns the path of the configuration file, in the XDG config
+// directory.
+func configPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "imgcat", "config.toml")
+}
+
+// loadConfig reads the configuration file, if any, and sets the environment
+// variables for its keys, unless they're already set, so the environment
+// overrides the file and imgcat.DefaultOptionsFromEnv sees both.
+// The file holds key = value lines, a small subset of TOML.
+func loadConfig(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not open config")
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return errors.Errorf("%s:%d: expected key = value", path, n)
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		env, ok := configKeys[key]
+		if !ok {
+			return errors.Errorf("%s:%d: unknown key %q", path, n, key)
+		}
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return errors.Errorf("%s:%d: bad string for %s", path, n, key)
+			}
+		}
+		if _, set := os.LookupEnv(env); !set {
+			if err := os.Setenv(env, value); err != nil {
+				return err
+			}
+		}
+	}
+	return errors.Wrap(s.Err(), "could not read config")
+}
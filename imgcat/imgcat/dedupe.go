@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/imghash"
+	"github.com/pkg/errors"
+)
+
+// Layout of the contact sheets showing groups of similar images.
+const (
+	dedupeCols = 6
+	dedupeSize = 160
+)
+
+// dedupe groups the images in paths, and in the directories among them, whose
+// difference hashes are at most distance apart, and shows every group with the
+// paths of its images.
+func dedupe(paths []string, distance int) error {
+	files, err := imageFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var (
+		names  []string
+		hashes []imghash.Hash
+		thumbs []imgcat.Thumbnail
+	)
+	for _, path := range files {
+		img, err := load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			continue
+		}
+		names = append(names, path)
+		hashes = append(hashes, imghash.Difference(img))
+		thumbs = append(thumbs, imgcat.Thumbnail{
+			Image: imgcat.Letterbox(img, dedupeSize, dedupeSize, color.Transparent),
+			Label: filepath.Base(path),
+		})
+	}
+
+	// Images are in the same group if they're similar to any of its images,
+	// tracked with a union-find forest.
+	parent := make([]int, len(names))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if imghash.Distance(hashes[i], hashes[j]) <= distance {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+	var groups [][]int
+	index := map[int]int{}
+	for i := range names {
+		r := root(i)
+		g, ok := index[r]
+		if !ok {
+			g = len(groups)
+			index[r] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true))
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		n++
+		fmt.Printf("group %d: %d similar images\n", n, len(g))
+		var sheet []imgcat.Thumbnail
+		for _, i := range g {
+			sheet = append(sheet, thumbs[i])
+		}
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, imgcat.ContactSheet(sheet, dedupeCols, dedupeSize)); err != nil {
+			return errors.Wrap(err, "could not encode contact sheet")
+		}
+		if err := enc.Encode(buf); err != nil {
+			return err
+		}
+		for _, i := range g {
+			fmt.Printf("\t%s\n", names[i])
+		}
+	}
+	if n == 0 {
+		fmt.Printf("no similar images among %d\n", len(names))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io/ioutil"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/diagnose"
+	"github.com/pkg/errors"
+)
+
+// diagnoseFiles reports the structure of each file and where its decoding
+// fails, followed by the image or the part of it that could be recovered.
+// It returns an error if any of the files doesn't decode.
+func diagnoseFiles(paths []string) error {
+	broken := 0
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		ok, err := diagnoseFile(path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			broken++
+		}
+	}
+	if broken > 0 {
+		return errors.Errorf("%d of %d files don't decode", broken, len(paths))
+	}
+	return nil
+}
+
+func diagnoseFile(path string) (bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not read %s", path)
+	}
+	r := diagnose.Diagnose(data)
+	format := string(r.Format)
+	if format == "" {
+		format = "unknown format"
+	}
+	fmt.Printf("%s: %s, %d bytes\n", path, format, len(data))
+	for _, s := range r.Segments {
+		fmt.Printf("  %s\n", s)
+	}
+	if r.Err == nil {
+		fmt.Println("decodes correctly")
+	} else {
+		fmt.Printf("decoding failed: %v\n", r.Err)
+		if p := r.Problem(); p != nil {
+			fmt.Printf("first problem at offset %d (0x%x) in %s\n", p.Offset, p.Offset, p.Name)
+		}
+	}
+	if r.Image == nil {
+		if r.Err != nil {
+			fmt.Println("nothing could be recovered")
+		}
+		return r.Err == nil, nil
+	}
+	if r.Recovered != "" {
+		fmt.Printf("recovered %s\n", r.Recovered)
+	}
+
+	if !imgcat.IsSupported() {
+		return r.Err == nil, nil
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, r.Image); err != nil {
+		return false, errors.Wrap(err, "could not encode recovered image")
+	}
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true), imgcat.PaneWidth(100), imgcat.MaxPixels(imgcat.DefaultMaxPixels))
+	if err != nil {
+		return false, err
+	}
+	return r.Err == nil, enc.Encode(buf)
+}
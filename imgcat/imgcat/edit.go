@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/convert"
+	"github.com/pkg/errors"
+)
+
+// Colors of what's drawn with the inspector.
+var (
+	annotationColor = color.RGBA{0xff, 0x30, 0x30, 0xff}
+	selectionColor  = color.RGBA{0xff, 0xd0, 0x00, 0xff}
+)
+
+// edits are the annotations drawn and the crop selected in the inspector, in
+// the coordinates of the image relative to its top left corner.
+type edits struct {
+	annotations []imgcat.Annotation
+	// crop is the part of the image that's saved, empty means all of it.
+	crop image.Rectangle
+}
+
+// add adds an annotation.
+func (e *edits) add(a imgcat.Annotation) { e.annotations = append(e.annotations, a) }
+
+// undo removes the last annotation, and reports whether there was one.
+func (e *edits) undo() bool {
+	if len(e.annotations) == 0 {
+		return false
+	}
+	e.annotations = e.annotations[:len(e.annotations)-1]
+	return true
+}
+
+// apply returns img with the annotations drawn over it, not cropped.
+func (e *edits) apply(img image.Image) image.Image {
+	if len(e.annotations) == 0 {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		copy(dst.Pix[y*dst.Stride:], rgbaRow(img, b.Min.Y+y))
+	}
+	for _, a := range e.annotations {
+		a.Draw(dst)
+	}
+	return dst
+}
+
+// rgbaRow returns row y of img as the bytes of an image.RGBA.
+func rgbaRow(img image.Image, y int) []byte {
+	b := img.Bounds()
+	row := make([]byte, 4*b.Dx())
+	for x := b.Min.X; x < b.Max.X; x++ {
+		r, g, bl, a := img.At(x, y).RGBA()
+		i := 4 * (x - b.Min.X)
+		row[i], row[i+1], row[i+2], row[i+3] = uint8(r>>8), uint8(g>>8), uint8(bl>>8), uint8(a>>8)
+	}
+	return row
+}
+
+// save writes img with the edits to path, in the format given by its
+// extension.
+func (e *edits) save(img image.Image, path string) error {
+	f := convert.FormatOf(path)
+	if f == "" {
+		return errors.Errorf("unknown format of %s, use .png, .jpg, .gif, or .webp", path)
+	}
+	out := e.apply(img)
+	if !e.crop.Empty() {
+		b := out.Bounds()
+		out = out.(interface {
+			SubImage(image.Rectangle) image.Image
+		}).SubImage(e.crop.Add(b.Min))
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not save to %s", path)
+	}
+	if err := convert.Encode(file, out, f, 0); err != nil {
+		file.Close()
+		return err
+	}
+	return errors.Wrapf(file.Close(), "could not save to %s", path)
+}
+
+// editedPath returns where the edits of the image in path are saved by
+// default, next to it and in the same format when possible.
+func editedPath(path string) string {
+	ext := filepath.Ext(path)
+	switch convert.FormatOf(path) {
+	case imgcat.PNG, imgcat.JPEG, imgcat.GIF, imgcat.WebP:
+	default:
+		// Formats that can't be written are saved as PNG.
+		ext = ".png"
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "-edited" + ext
+}
+
+// selection returns the rectangle of pixels between two corners, both
+// included.
+func selection(a, b image.Point) image.Rectangle {
+	r := image.Rectangle{a, b}.Canon()
+	r.Max = r.Max.Add(image.Pt(1, 1))
+	return r
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+// Exit codes of the file manager preview mode. lf caches the output of
+// previewers that exit with 0, which would prevent images from being drawn
+// again, so images are always reported as not cacheable.
+const (
+	exitNoCache = 1
+	exitUsage   = 2
+)
+
+// fileManager implements the -preview and -clear modes, which follow the
+// conventions of lf previewer and cleaner scripts: they are called with the
+// file path followed by the width, height, and position of the preview pane
+// in cells. The output of these scripts is captured by the file manager, so
+// images are written directly to the terminal.
+func fileManager(clear bool, args []string) {
+	if len(args) != 5 && !(clear && len(args) == 0) {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s -preview path width height x y\n\t%s -clear [path width height x y]\n", os.Args[0], os.Args[0])
+		os.Exit(exitUsage)
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(exitNoCache)
+	}
+	// Only writing to the terminal can fail in a way that matters.
+	defer func() { _ = tty.Close() }()
+
+	if clear && len(args) == 0 {
+		// Clear the whole screen, used by file managers previewing in their
+		// own pane, such as the preview-tui plugin of nnn.
+		if err := clearScreen(tty); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitNoCache)
+		}
+		return
+	}
+
+	var pane [4]int
+	for i, arg := range args[1:] {
+		if pane[i], err = strconv.Atoi(arg); err != nil || pane[i] < 0 {
+			fmt.Printf("invalid pane dimension %q\n", arg)
+			os.Exit(exitUsage)
+		}
+	}
+	width, height, x, y := pane[0], pane[1], pane[2], pane[3]
+
+	if clear {
+		if err := blank(tty, width, height, x, y); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitNoCache)
+		}
+		return
+	}
+	if err := draw(tty, args[0], width, height, x, y); err != nil {
+		// The file manager displays the output as the preview.
+		fmt.Println(err)
+	}
+	os.Exit(exitNoCache)
+}
+
+// draw displays the image at path in the given pane, leaving the cursor where
+// it was.
+func draw(w io.Writer, path string, width, height, x, y int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", path)
+	}
+	defer func() { _ = f.Close() }()
+
+	enc, err := previewEncoder(w,
+		imgcat.Inline(true),
+		imgcat.Width(imgcat.Cells(width)),
+		imgcat.Height(imgcat.Cells(height)),
+		imgcat.PreserveAspectRatio(true),
+		imgcat.Newline(false),
+		imgcat.MaxPixels(imgcat.DefaultMaxPixels))
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\x1b7\x1b[%d;%dH", y+1, x+1); err != nil {
+		return err
+	}
+	err = enc.Encode(f)
+	if _, werr := io.WriteString(w, "\x1b8"); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// previewEncoder returns an Encoder writing to w with the first graphics
+// protocol the terminal supports.
+func previewEncoder(w io.Writer, options ...imgcat.Option) (*imgcat.Encoder, error) {
+	chain := imgcat.FallbackChain(imgcat.ITerm2, imgcat.Kitty, imgcat.Sixel)
+	return imgcat.NewEncoder(w, append([]imgcat.Option{chain}, options...)...)
+}
+
+// blank erases the images drawn in the given pane. Kitty deletes its images
+// when told to, while iTerm2 and sixel terminals have no such command, but
+// their images are removed with the cells they cover.
+func blank(w io.Writer, width, height, x, y int) error {
+	if enc, err := previewEncoder(w); err == nil && enc.Protocol() == imgcat.Kitty {
+		return enc.DeleteImages()
+	}
+	var b strings.Builder
+	b.WriteString("\x1b7")
+	for row := 0; row < height; row++ {
+		fmt.Fprintf(&b, "\x1b[%d;%dH\x1b[%dX", y+row+1, x+1, width)
+	}
+	b.WriteString("\x1b8")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// clearScreen erases the whole screen, and the images Kitty keeps on it.
+func clearScreen(w io.Writer) error {
+	if enc, err := previewEncoder(w); err == nil {
+		if err := enc.DeleteImages(); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\x1b[2J\x1b[H")
+	return err
+}
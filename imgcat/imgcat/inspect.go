@@ -0,0 +1,292 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"unicode"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termquery"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/campoy/tools/imgcat/textwidth"
+	"github.com/pkg/errors"
+)
+
+// Settings of the pixel inspector.
+const (
+	// loupeRows is the height of the loupe in cells, and loupeRadius and
+	// loupeScale set how many pixels it shows and how large.
+	loupeRows   = 10
+	loupeRadius = 7
+	loupeScale  = 16
+	// fastStep is how many pixels the crosshair moves with H, J, K, and L.
+	fastStep = 10
+)
+
+// inspect displays the image in path with a crosshair moved with the arrow
+// keys, and a magnified loupe around it, reporting the coordinates and color
+// of the pixel under the crosshair. Rectangles selected with the crosshair can
+// be boxed or cropped, labels and points added, and the result saved to save.
+func inspect(path, save string) error {
+	img, err := load(path)
+	if err != nil {
+		return err
+	}
+	b := img.Bounds()
+
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	// The image takes what's left above the loupe and the status line.
+	rows := size.Rows - loupeRows - 1
+	if rows < 1 {
+		rows = 1
+	}
+	// Scale the image down to about the size it's displayed at, so frames
+	// are sent quickly. It's never scaled up, so pixels are not blurred.
+	w, h := size.Cols*cellWidth, rows*cellHeight
+	if w > maxFrameWidth {
+		w = maxFrameWidth
+	}
+	scale := math.Min(1, math.Min(float64(w)/float64(b.Dx()), float64(h)/float64(b.Dy())))
+	vw, vh := int(math.Max(1, math.Round(float64(b.Dx())*scale))), int(math.Max(1, math.Round(float64(b.Dy())*scale)))
+	render := func(src image.Image) *image.RGBA {
+		view := image.NewRGBA(image.Rect(0, 0, vw, vh))
+		scaled := imgcat.Letterbox(src, vw, vh, color.Black)
+		for y := 0; y < vh; y++ {
+			for x := 0; x < vw; x++ {
+				view.Set(x, y, scaled.At(scaled.Bounds().Min.X+x, scaled.Bounds().Min.Y+y))
+			}
+		}
+		return view
+	}
+	view := render(img)
+	// toView returns where a point of the image, relative to its top left
+	// corner, is in the view, and viewRect does the same for rectangles.
+	toView := func(q image.Point) image.Point {
+		return image.Pt(int(float64(q.X)*scale+scale/2), int(float64(q.Y)*scale+scale/2))
+	}
+	viewRect := func(r image.Rectangle) image.Rectangle {
+		return image.Rect(int(float64(r.Min.X)*scale), int(float64(r.Min.Y)*scale),
+			int(math.Ceil(float64(r.Max.X)*scale)), int(math.Ceil(float64(r.Max.Y)*scale)))
+	}
+
+	out := func(options ...imgcat.Option) (*imgcat.Encoder, error) {
+		options = append(options, imgcat.Inline(true), imgcat.PreserveAspectRatio(true), imgcat.Newline(false))
+		return imgcat.NewEncoder(os.Stdout, options...)
+	}
+	viewEnc, err := out(imgcat.Width(imgcat.Cells(size.Cols)), imgcat.Height(imgcat.Cells(rows)))
+	if err != nil {
+		return err
+	}
+	loupeEnc, err := out(imgcat.Height(imgcat.Cells(loupeRows)))
+	if err != nil {
+		return err
+	}
+
+	// Use the alternate screen, without cursor.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	status := func(format string, args ...interface{}) {
+		line := textwidth.Truncate(fmt.Sprintf(format, args...), size.Cols)
+		fmt.Printf("\x1b[%d;1H\x1b[2K%s", size.Rows, line)
+	}
+
+	var e edits
+	// mark is the first corner of the selection, if selecting.
+	var mark *image.Point
+	// message replaces the list of keys in the status line until the next
+	// key is pressed.
+	var message string
+	p := image.Pt(b.Min.X+b.Dx()/2, b.Min.Y+b.Dy()/2)
+	for {
+		frame := &image.RGBA{Pix: append([]uint8(nil), view.Pix...), Stride: view.Stride, Rect: view.Rect}
+		rel := p.Sub(b.Min)
+		if !e.crop.Empty() {
+			imgcat.Box(viewRect(e.crop), selectionColor, "crop").Draw(frame)
+		}
+		if mark != nil {
+			r := selection(*mark, rel)
+			imgcat.Box(viewRect(r), selectionColor, fmt.Sprintf("%dx%d", r.Dx(), r.Dy())).Draw(frame)
+		}
+		imgcat.Crosshair(toView(rel), color.RGBA{0xff, 0, 0xff, 0xff}).Draw(frame)
+
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, frame, &jpeg.Options{Quality: 90}); err != nil {
+			return errors.Wrap(err, "could not encode frame")
+		}
+		fmt.Print("\x1b[H\x1b[J")
+		if err := viewEnc.Encode(buf); err != nil {
+			return err
+		}
+		buf.Reset()
+		if err := png.Encode(buf, imgcat.Loupe(img, p, loupeRadius, loupeScale)); err != nil {
+			return errors.Wrap(err, "could not encode loupe")
+		}
+		fmt.Printf("\x1b[%d;1H", rows+1)
+		if err := loupeEnc.Encode(buf); err != nil {
+			return err
+		}
+		c := color.NRGBAModel.Convert(img.At(p.X, p.Y)).(color.NRGBA)
+		if message == "" {
+			message = "arrows move, HJKL faster, space selects, b box, c crop, p point, t text, u undo, s save, q quits"
+			if mark != nil {
+				message = "move to the other corner, then b to box or c to crop, space or escape cancels"
+			}
+		}
+		status("x=%d y=%d rgba(%d, %d, %d, %d) %s    %s", p.X, p.Y, c.R, c.G, c.B, c.A, imgcat.Hex(c), message)
+		message = ""
+
+		for changed := false; !changed; {
+			key, err := termquery.ReadKey()
+			if err != nil {
+				return err
+			}
+			// edited is set by the keys that change the annotations.
+			edited := false
+			d := image.Point{}
+			switch key {
+			case termquery.KeyLeft, "h":
+				d.X = -1
+			case termquery.KeyRight, "l":
+				d.X = 1
+			case termquery.KeyUp, "k":
+				d.Y = -1
+			case termquery.KeyDown, "j":
+				d.Y = 1
+			case "H":
+				d.X = -fastStep
+			case "L":
+				d.X = fastStep
+			case "K":
+				d.Y = -fastStep
+			case "J":
+				d.Y = fastStep
+			case " ":
+				if mark == nil {
+					mark = &rel
+				} else {
+					mark = nil
+				}
+				changed = true
+			case "b", "c":
+				if mark == nil {
+					message, changed = "select a rectangle with space first", true
+					break
+				}
+				if r := selection(*mark, rel); key == "b" {
+					e.add(imgcat.Box(r, annotationColor, ""))
+					edited = true
+				} else {
+					e.crop = r
+				}
+				mark, changed = nil, true
+			case "p":
+				e.add(imgcat.Point(rel, annotationColor, ""))
+				edited = true
+			case "t":
+				text, err := readLine(status, "label: ")
+				if err != nil {
+					return err
+				}
+				if text != "" {
+					e.add(imgcat.Label(rel, text, annotationColor))
+					edited = true
+				}
+				changed = true
+			case "u":
+				if e.undo() {
+					edited = true
+				} else if !e.crop.Empty() {
+					e.crop, changed = image.Rectangle{}, true
+				}
+			case "s":
+				if err := e.save(img, save); err != nil {
+					message = err.Error()
+				} else {
+					message = "saved to " + save
+				}
+				changed = true
+			case "q", termquery.KeyEscape, termquery.KeyInterrupt:
+				if key == termquery.KeyEscape && mark != nil {
+					mark, changed = nil, true
+					break
+				}
+				return nil
+			}
+			if edited {
+				view, changed = render(e.apply(img)), true
+			}
+			if q := clampPoint(p.Add(d), b); q != p {
+				p, changed = q, true
+			}
+		}
+	}
+}
+
+// readLine reads a line of text typed by the user, shown in the status line
+// after label. It returns an empty string if cancelled with escape.
+func readLine(status func(string, ...interface{}), label string) (string, error) {
+	var text []rune
+	for {
+		status("%s%s_    enter accepts, escape cancels", label, string(text))
+		key, err := termquery.ReadKey()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case termquery.KeyEnter:
+			return string(text), nil
+		case termquery.KeyEscape, termquery.KeyInterrupt:
+			return "", nil
+		case termquery.KeyBackspace, "\b":
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		default:
+			for _, r := range key {
+				if unicode.IsPrint(r) {
+					text = append(text, r)
+				}
+			}
+		}
+	}
+}
+
+// clampPoint returns the point of r closest to p.
+func clampPoint(p image.Point, r image.Rectangle) image.Point {
+	if p.X < r.Min.X {
+		p.X = r.Min.X
+	}
+	if p.X >= r.Max.X {
+		p.X = r.Max.X - 1
+	}
+	if p.Y < r.Min.Y {
+		p.Y = r.Min.Y
+	}
+	if p.Y >= r.Max.Y {
+		p.Y = r.Max.Y - 1
+	}
+	return p
+}
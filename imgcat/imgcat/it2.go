@@ -0,0 +1,184 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/it2"
+	"github.com/pkg/errors"
+)
+
+// EnvCompat set to it2 makes imgcat run as the imgcat script of iTerm2, like
+// the -it2 flag.
+const EnvCompat = "IMGCAT_COMPAT"
+
+const it2Usage = `usage: imgcat -it2 [-p] [-n] [-W width] [-H height] [-r] [-s] [-l] [-t type] [-u] [-f] filename ...
+       cat filename | imgcat -it2 [-W width] [-H height] [-r] [-s] [-l] [-t type]
+
+Runs as the imgcat script of iTerm2, writing the same bytes, with its flags:
+  -h, --help: display this message
+  -p, --print: print the filename or URL after each image
+  -n, --no-print: don't print the filename or URL after each image
+  -u, --url: the following arguments are URLs
+  -f, --file: the following arguments are files
+  -t, --type file-type: type hint, such as image/png or .png
+  -r, --preserve-aspect-ratio: preserve the aspect ratio of scaled images
+  -s, --stretch: stretch images to the given width and height
+  -l, --legacy: send images in a single sequence instead of in parts
+  -W, --width N: width in cells, pixels such as 100px, percents such as 50%, or auto
+  -H, --height N: height in cells, pixels, percents, or auto
+`
+
+// it2SizePattern matches the sizes taken by -W and -H.
+var it2SizePattern = regexp.MustCompile(`^([0-9]+(px|%)?|auto)$`)
+
+// imgcatScript runs imgcat as the imgcat script of iTerm2 given args, and returns the
+// exit status, so dotfiles calling the script keep working.
+func imgcatScript(args []string) int {
+	fail := func(status int, format string, a ...interface{}) int {
+		fmt.Fprintf(os.Stderr, "ERROR: "+format+"\n", a...)
+		return status
+	}
+	var o it2.Options
+	o.Tmux = it2.Tmux(os.Getenv("TERM"))
+	stdin := !imgcat.IsTerminal(os.Stdin)
+	if !stdin && len(args) == 0 {
+		fmt.Fprint(os.Stderr, it2Usage)
+		return 0
+	}
+	urls := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// Flags are taken with one or two dashes.
+		name := arg
+		if strings.HasPrefix(name, "--") {
+			name = name[1:]
+		}
+		value := func() (string, bool) {
+			if i+1 >= len(args) {
+				return "", false
+			}
+			i++
+			return args[i], true
+		}
+		switch name {
+		case "-h", "-help":
+			fmt.Fprint(os.Stderr, it2Usage)
+			return 0
+		case "-p", "-print":
+			o.PrintName = true
+		case "-n", "-no-print":
+			o.PrintName = false
+		case "-W", "-width", "-H", "-height":
+			v, ok := value()
+			if !ok || !it2SizePattern.MatchString(v) {
+				fmt.Fprintf(os.Stderr, "ERROR: Invalid image sizing unit - '%s'\n%s", v, it2Usage)
+				return 1
+			}
+			if name == "-W" || name == "-width" {
+				o.Width = v
+			} else {
+				o.Height = v
+			}
+		case "-r", "-preserve-aspect-ratio":
+			o.AspectRatio = it2.Preserve
+		case "-s", "-stretch":
+			o.AspectRatio = it2.Stretch
+		case "-l", "-legacy":
+			o.Legacy = true
+		case "-t", "-type":
+			o.Type, _ = value()
+		case "-f", "-file":
+			stdin, urls = false, false
+		case "-u", "-url":
+			stdin, urls = false, true
+		default:
+			if strings.HasPrefix(arg, "-") {
+				fmt.Fprintf(os.Stderr, "ERROR: Unknown option flag: %s\n%s", arg, it2Usage)
+				return 1
+			}
+			var data []byte
+			var err error
+			if urls {
+				if data, err = it2Download(arg); err != nil {
+					return fail(2, "Could not retrieve image from URL %s: %v", arg, err)
+				}
+			} else if data, err = ioutil.ReadFile(arg); err != nil {
+				return fail(2, "imgcat: %s: No such file or directory", arg)
+			}
+			stdin = false
+			o.Name = arg
+			if err := it2.Imgcat(os.Stdout, data, o); err != nil {
+				return fail(1, "%v", err)
+			}
+		}
+	}
+	if stdin {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fail(1, "could not read standard input: %v", err)
+		}
+		o.Name, o.PrintName = "", false
+		if err := it2.Imgcat(os.Stdout, data, o); err != nil {
+			return fail(1, "%v", err)
+		}
+	}
+	return 0
+}
+
+// it2Download returns the image at url, for -it2 -u.
+func it2Download(url string) ([]byte, error) {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, errors.New(res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// downloadScript runs imgcat as the it2dl script of iTerm2 given args, sending the
+// files to be downloaded by iTerm2, and returns the exit status.
+func downloadScript(args []string) int {
+	if len(args) < 1 {
+		fmt.Println("Usage: it2dl file ...")
+		return 1
+	}
+	tmux := it2.Tmux(os.Getenv("TERM"))
+	for _, path := range args {
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			fmt.Printf("%s is a directory\n", path)
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Printf("File %s does not exist or is not readable.\n", path)
+			continue
+		}
+		if err := it2.Download(os.Stdout, path, data, tmux); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+	}
+	return 0
+}
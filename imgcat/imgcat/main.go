@@ -14,40 +14,647 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
 	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display"
+	"github.com/campoy/tools/imgcat/httpcache"
+	"github.com/campoy/tools/imgcat/ocr"
+	"github.com/campoy/tools/imgcat/source"
+	"github.com/campoy/tools/imgcat/termsize"
 	"github.com/pkg/errors"
-	"os"
 )
 
+var (
+	previewPane  = flag.Bool("preview-pane", false, "fit the image in the preview window of fzf or skim")
+	previewFile  = flag.Bool("preview", false, "preview an image in a file manager like lf")
+	clearFile    = flag.Bool("clear", false, "clear the images previewed in a file manager")
+	rectFlag     = flag.String("rect", "", "draw the images in the x,y,width,height rectangle of cells")
+	idFlag       = flag.String("id", "", "record the rectangle given with -rect under this id")
+	eraseFlag    = flag.String("erase", "", "erase the rectangle recorded under the given id")
+	background   = flag.String("background", "", "set the image as the session background: stretch, tile, fill, or fit; none clears it")
+	promptShell  = flag.String("prompt", "", "display the image in the prompt of the given shell, bash or zsh, or print how to set it up")
+	adaptive     = flag.Bool("adaptive", true, "downsample and compress images in SSH sessions")
+	probe        = flag.Bool("probe", false, "size images after the measured latency to the terminal")
+	targetBytes  = flag.Int("target-bytes", 0, "re-encode images as JPEG of at most this many bytes, zero means no limit")
+	teePath      = flag.String("tee", "", "also save the image displayed to this file, such as an image read from /dev/stdin")
+	webpFlag     = flag.Bool("webp", false, "send the images that are transformed or downsampled as lossless WebP instead of PNG")
+	verbose      = flag.Bool("v", false, "log how every image is sent to standard error")
+	rendererName = flag.String("renderer", "", "display images with this protocol, such as kitty or halfblock, or with the command imgcat-renderer-NAME found in PATH")
+	_            = flag.Bool("it2", false, "run as the imgcat script of iTerm2, with its flags and the same output, see -it2 -h; must come first")
+	jsonFlag     = flag.Bool("json", false, "print a line of JSON describing each image displayed or the error, with the images written to the terminal")
+	explain      = flag.Bool("explain", false, "print how the images would be sent, without sending them")
+	selfTest     = flag.Bool("selftest", false, "display a test card with every backend, and report which ones the terminal displayed")
+	force        = flag.Bool("force", false, "send images even when the output is not a terminal")
+	pagerRows    = flag.Int("pager", 0, "display images in this many rows, for paging with less -R")
+	alignFlag    = flag.String("align", "left", "where images are placed across the terminal: left, center, or right")
+	maxRows      = flag.Int("max-rows", 0, "display images at most this many rows tall, or as tall as the terminal but this many rows when negative; zero means no limit")
+	slideDelay   = flag.Duration("slideshow", 0, "display the images full screen one after the other, waiting this long between them")
+	shuffle      = flag.Bool("shuffle", false, "shuffle the images of the slideshow")
+	transition   = flag.String("transition", "fade", "transition between the images of the slideshow: fade, dissolve, wipe, or none")
+	inspectFlag  = flag.Bool("inspect", false, "inspect the pixels of an image with a crosshair and a magnified loupe, and crop or annotate it")
+	saveFlag     = flag.String("save", "", "file the image edited with -inspect is saved to, defaults to name-edited.ext next to it")
+	ocrFlag      = flag.Bool("ocr", false, "print the text recognized in the images with tesseract below them")
+	ocrLanguages = flag.String("ocr-lang", "", "languages of the text recognized with -ocr, such as eng+deu")
+	paletteSize  = flag.Int("palette", 0, "print swatches of this many dominant colors of the images below them")
+	dedupeFlag   = flag.Bool("dedupe", false, "group visually similar images found in the given files and directories")
+	dedupeDist   = flag.Int("dedupe-distance", 8, "largest distance between the perceptual hashes of images grouped by -dedupe")
+	histogram    = flag.Bool("histogram", false, "draw the RGB and luma histogram of the images over their bottom right corner")
+	diagnoseFlag = flag.Bool("diagnose", false, "report where broken images fail to decode, and show what can be recovered")
+	linkFlag     = flag.Bool("link", false, "make the images hyperlinks to their files, opened with a click in terminals supporting it")
+	copyFlag     = flag.Bool("copy", false, "also copy the image to the clipboard with OSC 52, the last one if several are given")
+	urlsFlag     = flag.Bool("urls", false, "display a gallery of the images at the given URLs, or the ones read from standard input one per line")
+	jobs         = flag.Int("j", 8, "number of images downloaded concurrently with -urls")
+	serveFlag    = flag.Bool("serve", false, "run a display server showing the images sent by the given command, or by any program until interrupted")
+	listenAddr   = flag.String("listen", "", "address the display server of -serve listens on with TCP, such as :9393, instead of a Unix socket")
+	mcpFlag      = flag.Bool("mcp", false, "serve a display_image tool to coding agents with the Model Context Protocol on standard input and output")
+	keygenFlag   = flag.Bool("keygen", false, "create a key sealing the connections to display servers, kept in the keychain and printed for IMGCAT_KEY")
+)
+
+// alignments by the name given to -align.
+var alignments = map[string]imgcat.Alignment{
+	"left":   imgcat.Left,
+	"center": imgcat.Center,
+	"right":  imgcat.Right,
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "usage:\n\t%s [image_path]*\n", os.Args[0])
+	// Dotfiles calling the scripts of iTerm2 get the same bytes, with the
+	// same flags, through a link named it2dl, -it2, or IMGCAT_COMPAT.
+	switch {
+	case strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == "it2dl":
+		os.Exit(downloadScript(os.Args[1:]))
+	case len(os.Args) > 1 && (os.Args[1] == "-it2" || os.Args[1] == "--it2"):
+		os.Exit(imgcatScript(os.Args[2:]))
+	case os.Getenv(EnvCompat) == "it2":
+		os.Exit(imgcatScript(os.Args[1:]))
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [-preview-pane] [-ocr [-ocr-lang eng]] [-palette n] [image_path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -preview path width height x y\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -clear [path width height x y]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -rect x,y,width,height [-id id] [image_path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -erase id\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -background stretch|tile|fill|fit|none [image_path]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -prompt bash|zsh [image_path]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -explain [image_path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -selftest\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -serve [-listen addr] [command [args]*]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -keygen\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -mcp\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -it2 [flags of the imgcat script of iTerm2] [image_path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -diagnose [image_path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -inspect [-save path] image_path\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -dedupe [-dedupe-distance n] [path]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -urls [-j n] [url]*\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\t%s -slideshow delay [-shuffle] [-transition fade|dissolve|wipe|none] [path]*\n", os.Args[0])
+	}
+	flag.Parse()
+	if err := loadConfig(configPath()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
+	if *previewFile || *clearFile {
+		fileManager(*clearFile, flag.Args())
+		return
+	}
+	if *eraseFlag != "" {
+		if err := erase(*eraseFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *background != "" {
+		if err := setBackground(*background, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *promptShell != "" {
+		if err := prompt(*promptShell, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *selfTest {
+		failed := false
+		for _, r := range imgcat.SelfTest(os.Stdout) {
+			fmt.Println(r)
+			failed = failed || r.Err != nil || r.Checked && !r.Displayed
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+	if *keygenFlag {
+		if err := keygen(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.NArg() < 1 && !*serveFlag && !*mcpFlag {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *diagnoseFlag {
+		if err := diagnoseFiles(flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *inspectFlag {
+		save := *saveFlag
+		if save == "" {
+			save = editedPath(flag.Arg(0))
+		}
+		if err := inspect(flag.Arg(0), save); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *dedupeFlag {
+		if err := dedupe(flag.Args(), *dedupeDist); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *slideDelay > 0 {
+		if err := slideshow(flag.Args(), *slideDelay, *shuffle, *transition); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *rectFlag != "" {
+		if err := placement(*rectFlag, *idFlag, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	enc, err := imgcat.NewEncoder(os.Stdout,
+	options := []imgcat.Option{
 		imgcat.Inline(true),
-		imgcat.Width(imgcat.Percent(100)))
+		imgcat.MaxPixels(imgcat.DefaultMaxPixels),
+		imgcat.NameFromFile(),
+	}
+	align, ok := alignments[*alignFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown alignment %q, use left, center, or right\n", *alignFlag)
+		os.Exit(2)
+	}
+	// Images fill the pane unless they're aligned, which needs room around
+	// them.
+	if os.Getenv(imgcat.EnvWidth) == "" && align == imgcat.Left {
+		options = append(options, imgcat.PaneWidth(100))
+	}
+	options = append(options, imgcat.Align(align))
+	env, err := imgcat.DefaultOptionsFromEnv()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
+	options = append(options, env...)
+	if *rendererName != "" {
+		opt, err := useRenderer(*rendererName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(2)
+		}
+		options = append(options, opt)
+	}
+	args := flag.Args()
+	if *urlsFlag && len(args) == 0 {
+		if args, err = readLines(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "could not read URLs: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, arg := range args {
+		if *urlsFlag || isURL(arg) {
+			httpClient = cachedClient()
+			options = append(options, imgcat.HTTPClient(httpClient))
+			break
+		}
+	}
+	if *previewPane {
+		options = append(options, preview()...)
+	}
+	if *adaptive {
+		options = append(options, imgcat.Adaptive())
+	}
+	if *probe {
+		options = append(options, imgcat.Probe())
+	}
+	if *targetBytes > 0 {
+		options = append(options, imgcat.TargetBytes(*targetBytes))
+	}
+	if *webpFlag {
+		options = append(options, imgcat.Reencode(imgcat.WebP))
+	}
+	if *pagerRows > 0 {
+		options = append(options, imgcat.Pager(*pagerRows))
+	}
+	if *maxRows != 0 {
+		options = append(options, imgcat.MaxRows(*maxRows))
+	}
+	if *histogram {
+		options = append(options, imgcat.ShowHistogram())
+	}
+	if *linkFlag {
+		options = append(options, imgcat.LinkToFile())
+	}
+	if *verbose {
+		options = append(options, imgcat.Log(log.New(os.Stderr, "", 0)))
+	}
+
+	if *serveFlag {
+		if err := serve(*listenAddr, flag.Args(), options); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	for _, path := range os.Args[1:] {
-		if err := cat(enc, path); err != nil {
+	if *mcpFlag {
+		if err := serveMCP(options); err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *explain {
+		for _, path := range flag.Args() {
+			if err := explainFile(path, options); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+			}
+		}
+		return
+	}
+
+	// The standard output carries the descriptions of the images with -json.
+	out := io.Writer(os.Stdout)
+	var metrics *imgcat.Metrics
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		metrics = new(imgcat.Metrics)
+		options = append(options, opt, imgcat.Measure(metrics))
+	}
+
+	// Programs whose output is not a terminal can still display images
+	// through a display server.
+	if !*jsonFlag && !*force && !imgcat.IsTerminal(os.Stdout) && os.Getenv(display.EnvSock) != "" {
+		if err := push(flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Escape sequences piped to other programs end up as garbage, except for
+	// fzf, which displays the images in its preview window, and pagers.
+	if !*jsonFlag && !*force && !*previewPane && *pagerRows == 0 && !imgcat.IsTerminal(os.Stdout) {
+		for _, path := range flag.Args() {
+			if err := placeholder(path); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+			}
+		}
+		return
+	}
+
+	if *teePath != "" {
+		if flag.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "-tee saves a single image\n")
+			os.Exit(2)
+		}
+		f, err := os.Create(*teePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		options = append(options, imgcat.Tee(f))
+	}
+
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	// report reports the error of an image, on the standard output with
+	// -json unless the image was already described by the Encoder.
+	report := func(name string, err error, described bool) {
+		switch {
+		case metrics == nil:
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+		case !described:
+			b, _ := json.Marshal(imgcat.Event{Name: name, Err: err})
+			fmt.Printf("%s\n", b)
+		}
+	}
+	images := func() int64 {
+		if metrics == nil {
+			return 0
+		}
+		return metrics.Snapshot().Images
+	}
+
+	var below []func(io.Reader) error
+	if *paletteSize > 0 {
+		below = append(below, func(r io.Reader) error { return palette(r, *paletteSize) })
+	}
+	if *ocrFlag {
+		t := ocr.Tesseract{}
+		if *ocrLanguages != "" {
+			t.Languages = strings.Split(*ocrLanguages, "+")
 		}
+		below = append(below, func(r io.Reader) error { return recognize(r, t) })
 	}
+	if *copyFlag {
+		below = append(below, imgcat.CopyToClipboard)
+	}
+	if *urlsFlag {
+		if len(below) > 0 {
+			fmt.Fprintf(os.Stderr, "-palette, -ocr, and -copy don't apply to -urls\n")
+			os.Exit(2)
+		}
+		if err := enc.EncodeURLs(args, *jobs); err != nil {
+			report("", err, false)
+			os.Exit(1)
+		}
+		return
+	}
+	for _, path := range flag.Args() {
+		n := images()
+		if err := cat(enc, path, below); err != nil {
+			report(path, err, images() > n)
+		}
+	}
+}
+
+// readLines returns the lines read from r that aren't blank, trimmed.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, s.Err()
 }
 
-func cat(enc *imgcat.Encoder, path string) error {
+// preview returns the options to display an image in the preview window of
+// fzf or skim, whose size is given in FZF_PREVIEW_COLUMNS and LINES.
+func preview() []imgcat.Option {
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	if cols, err := strconv.Atoi(os.Getenv("FZF_PREVIEW_COLUMNS")); err == nil {
+		size.Cols = cols
+	}
+	if lines, err := strconv.Atoi(os.Getenv("FZF_PREVIEW_LINES")); err == nil {
+		size.Rows = lines
+	}
+	// An image touching the bottom of the window scrolls the preview.
+	if size.Rows > 1 {
+		size.Rows--
+	}
+
+	options := []imgcat.Option{
+		imgcat.Width(imgcat.Cells(size.Cols)),
+		imgcat.Height(imgcat.Cells(size.Rows)),
+		imgcat.PreserveAspectRatio(true),
+		imgcat.Newline(false),
+	}
+	// fzf displays the images in its preview window itself and wraps them
+	// for tmux if needed, also when running in a tmux popup.
+	if os.Getenv("FZF_PREVIEW_COLUMNS") != "" {
+		options = append(options, imgcat.Passthrough(false))
+	}
+	return options
+}
+
+// cat displays the image in path, followed by what each of the below functions
+// prints about it, given the file read again from the start.
+func cat(enc *imgcat.Encoder, path string, below []func(io.Reader) error) error {
+	if isURL(path) {
+		return catURL(enc, path, below)
+	}
+	if !isLocal(path) {
+		return catSource(enc, path, below, func() (io.ReadCloser, error) { return openPath(path) })
+	}
 	f, err := os.Open(path)
 	if err != nil {
 		return errors.Wrapf(err, "could not open %s", path)
 	}
+	defer f.Close()
 	if err := enc.Encode(f); err != nil {
 		return err
 	}
-	return f.Close()
+	for _, fn := range below {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return errors.Wrapf(err, "could not read %s again", path)
+		}
+		if err := fn(f); err != nil {
+			return errors.Wrapf(err, "%s", path)
+		}
+	}
+	return nil
+}
+
+// isURL reports whether arg is the URL of a remote image rather than a path.
+func isURL(arg string) bool {
+	return strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://")
+}
+
+// isLocal reports whether path is the one of a local file, rather than a URL,
+// a reference to a source such as s3://bucket/key.png, or a member of an
+// archive such as photos.zip:1.jpg.
+func isLocal(path string) bool {
+	if _, ok := source.Lookup(path); ok || isURL(path) {
+		return false
+	}
+	_, _, ok := source.SplitArchive(path)
+	return !ok
+}
+
+// openPath opens the image in path, which can be anything cat displays.
+func openPath(path string) (io.ReadCloser, error) {
+	if isURL(path) {
+		return download(path)
+	}
+	if _, ok := source.Lookup(path); ok {
+		return source.Open(context.Background(), path)
+	}
+	if archive, member, ok := source.SplitArchive(path); ok {
+		return source.OpenMember(archive, member)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	return f, nil
+}
+
+// download returns the body of the response to a GET of url.
+func download(url string) (io.ReadCloser, error) {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download %s", url)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, errors.Errorf("could not download %s: %s", url, res.Status)
+	}
+	return res.Body, nil
+}
+
+// catURL is cat for the image at a URL, downloaded again for the below
+// functions unless it was cached.
+func catURL(enc *imgcat.Encoder, url string, below []func(io.Reader) error) error {
+	if err := enc.EncodeURL(url); err != nil {
+		return err
+	}
+	if len(below) == 0 {
+		return nil
+	}
+	body, err := download(url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return errors.Wrapf(err, "could not download %s", url)
+	}
+	return printBelow(url, data, below)
+}
+
+// catSource is cat for an image that's not a local file, such as
+// s3://bucket/key.png or photos.zip:1.jpg, opened with open.
+func catSource(enc *imgcat.Encoder, ref string, below []func(io.Reader) error, open func() (io.ReadCloser, error)) error {
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", ref)
+	}
+	if err := enc.Encode(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	return printBelow(ref, data, below)
+}
+
+// printBelow calls each of the below functions with the image data read from
+// ref.
+func printBelow(ref string, data []byte, below []func(io.Reader) error) error {
+	for _, fn := range below {
+		if err := fn(bytes.NewReader(data)); err != nil {
+			return errors.Wrapf(err, "%s", ref)
+		}
+	}
+	return nil
+}
+
+// httpClient downloads remote images.
+var httpClient = http.DefaultClient
+
+// cachedClient returns a client caching what it downloads in the user's cache
+// directory, or the default client if there's none.
+func cachedClient() *http.Client {
+	dir, err := httpcache.Dir()
+	if err != nil {
+		return http.DefaultClient
+	}
+	store, err := httpcache.NewDiskStore(dir)
+	if err != nil {
+		return http.DefaultClient
+	}
+	return httpcache.NewTransport(nil, store).Client()
+}
+
+// recognize prints the text engine recognizes in the image read from r.
+func recognize(r io.Reader, engine ocr.Engine) error {
+	text, err := engine.Recognize(r)
+	if err != nil {
+		return errors.Wrap(err, "could not recognize text")
+	}
+	if text != "" {
+		fmt.Println(text)
+	}
+	return nil
+}
+
+// palette prints swatches of the n dominant colors of the image read from r,
+// with their hex codes below.
+func palette(r io.Reader, n int) error {
+	colors, err := imgcat.Palette(r, n)
+	if err != nil {
+		return err
+	}
+	var swatches, codes strings.Builder
+	for _, c := range colors {
+		rgba := color.RGBAModel.Convert(c).(color.RGBA)
+		fmt.Fprintf(&swatches, "\x1b[48;2;%d;%d;%dm        \x1b[0m ", rgba.R, rgba.G, rgba.B)
+		// Palette colors are opaque, print them without alpha.
+		fmt.Fprintf(&codes, "%-9s", imgcat.Hex(c)[:7])
+	}
+	fmt.Println(strings.TrimRight(swatches.String(), " "))
+	fmt.Println(strings.TrimRight(codes.String(), " "))
+	return nil
+}
+
+func explainFile(path string, options []imgcat.Option) error {
+	rc, err := openPath(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n%s", path, imgcat.Explain(rc, options...))
+	return rc.Close()
+}
+
+// placeholder prints a line describing the image in path, to stand in for it
+// when the output is not a terminal, see imgcat.Placeholder.
+func placeholder(path string) error {
+	rc, err := openPath(path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	line, err := imgcat.Placeholder(rc)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+	fmt.Println(line)
+	return nil
 }
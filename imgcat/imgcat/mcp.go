@@ -0,0 +1,91 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display"
+	"github.com/campoy/tools/imgcat/mcp"
+	"github.com/campoy/tools/imgcat/source"
+	"github.com/pkg/errors"
+)
+
+// serveMCP serves the display_image tool to an agent on the standard input
+// and output. Images are sent to the display server given in IMGCAT_SOCK if
+// any, or written to the terminal, since the standard output carries the
+// responses.
+func serveMCP(options []imgcat.Option) error {
+	s := &mcp.Server{
+		Display: func(img mcp.Image) error { return showMCP(img, options) },
+		Open:    openMCP,
+		Name:    "imgcat",
+	}
+	return s.Serve(os.Stdin, os.Stdout)
+}
+
+// showMCP displays an image given to the display_image tool.
+func showMCP(img mcp.Image, options []imgcat.Option) error {
+	if os.Getenv(display.EnvSock) != "" {
+		c, err := display.Dial()
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		return c.Send(display.Image{Data: img.Data, Name: img.Name, Width: img.Width, Height: img.Height})
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "no terminal to display images in, run a display server with -serve")
+	}
+	defer tty.Close()
+	options = options[:len(options):len(options)]
+	if img.Name != "" {
+		options = append(options, imgcat.Name(img.Name))
+	}
+	if img.Width != "" {
+		options = append(options, imgcat.Width(img.Width))
+	}
+	if img.Height != "" {
+		options = append(options, imgcat.Height(img.Height))
+	}
+	enc, err := imgcat.NewEncoder(tty, options...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(bytes.NewReader(img.Data))
+}
+
+// openMCP opens the images given with URLs to the display_image tool, such as
+// https://example.com/plot.png or s3://bucket/shot.png.
+func openMCP(url string) (io.ReadCloser, error) {
+	if !isURL(url) {
+		return source.Open(context.Background(), url)
+	}
+	res, err := cachedClient().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, errors.New(res.Status)
+	}
+	return res.Body, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+// A rectangle of cells, with 0 based coordinates.
+type rect struct {
+	X, Y, W, H int
+}
+
+func parseRect(s string) (rect, error) {
+	var r rect
+	if _, err := fmt.Sscanf(s, "%d,%d,%d,%d", &r.X, &r.Y, &r.W, &r.H); err != nil {
+		return rect{}, errors.Errorf("invalid rectangle %q, expected x,y,width,height", s)
+	}
+	if r.X < 0 || r.Y < 0 || r.W <= 0 || r.H <= 0 {
+		return rect{}, errors.Errorf("invalid rectangle %q", s)
+	}
+	return r, nil
+}
+
+// placement draws the images at paths in the rectangle given with -rect, and
+// records it under -id so it can be erased later with -erase. This is meant to
+// be used by editor plugins, which forward the output to their terminal.
+func placement(rectFlag, id string, paths []string) error {
+	if err := checkNvim(); err != nil {
+		return err
+	}
+	r, err := parseRect(rectFlag)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := draw(os.Stdout, path, r.W, r.H, r.X, r.Y); err != nil {
+			return err
+		}
+	}
+	if id == "" {
+		return nil
+	}
+	return updatePlacements(func(p map[string]rect) { p[id] = r })
+}
+
+// erase blanks the rectangle recorded under the given id.
+func erase(id string) error {
+	if err := checkNvim(); err != nil {
+		return err
+	}
+	return updatePlacements(func(p map[string]rect) {
+		if r, ok := p[id]; ok {
+			blank(os.Stdout, r.W, r.H, r.X, r.Y)
+			delete(p, id)
+		}
+	})
+}
+
+// checkNvim fails when running inside a Neovim terminal, which drops image
+// escape sequences. Plugins run imgcat as a job instead, whose output is not a
+// terminal.
+func checkNvim() error {
+	if os.Getenv("NVIM") == "" {
+		return nil
+	}
+	if _, err := termsize.Terminal(os.Stdout); err == nil {
+		return errors.New("images can't be displayed in a Neovim terminal, run imgcat from a plugin instead")
+	}
+	return nil
+}
+
+// placementsFile returns the file where placements are recorded, which is
+// different for each Neovim instance. It's kept in a directory only the user
+// can access, imgcat in $XDG_RUNTIME_DIR or else in the user's cache
+// directory, so other users can neither read nor replace it.
+func placementsFile() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserCacheDir(); err != nil {
+			return "", errors.Wrap(err, "could not find a directory for placements")
+		}
+	}
+	dir = filepath.Join(dir, "imgcat")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", errors.Wrap(err, "could not create a directory for placements")
+	}
+	name := "placements.json"
+	if nvim := os.Getenv("NVIM"); nvim != "" {
+		name = fmt.Sprintf("placements-%s.json", filepath.Base(nvim))
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func updatePlacements(fn func(map[string]rect)) error {
+	path, err := placementsFile()
+	if err != nil {
+		return err
+	}
+	p := make(map[string]rect)
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(b, &p); err != nil {
+			return errors.Wrapf(err, "could not parse %s", path)
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+
+	fn(p)
+
+	if len(p) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "could not remove %s", path)
+		}
+		return nil
+	}
+	if b, err = json.Marshal(p); err != nil {
+		return errors.Wrap(err, "could not encode placements")
+	}
+	return errors.Wrapf(writeFile(path, b), "could not write %s", path)
+}
+
+// writeFile replaces the file at path with data atomically, writing it to a
+// new file of the same directory first, so readers never see part of it and
+// no existing file or link is written through.
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+// promptCells is the width of images in prompts.
+const promptCells = 2
+
+// Shell snippets that display the image in $IMGCAT_PROMPT_IMAGE in the prompt,
+// rendering it again before every prompt.
+var promptInit = map[string]string{
+	"bash": `# Add to ~/.bashrc, and set IMGCAT_PROMPT_IMAGE to the image to display.
+_imgcat_prompt() {
+	IMGCAT_PROMPT="$(imgcat -prompt bash "$IMGCAT_PROMPT_IMAGE" 2>/dev/null)"
+}
+PROMPT_COMMAND="_imgcat_prompt${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+PS1='${IMGCAT_PROMPT} '"$PS1"
+`,
+	"zsh": `# Add to ~/.zshrc, and set IMGCAT_PROMPT_IMAGE to the image to display.
+_imgcat_prompt() {
+	IMGCAT_PROMPT="$(imgcat -prompt zsh "$IMGCAT_PROMPT_IMAGE" 2>/dev/null)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _imgcat_prompt
+setopt prompt_subst
+PROMPT='${IMGCAT_PROMPT} '"$PROMPT"
+`,
+}
+
+// prompt prints the image at path for the prompt of the given shell or, if no
+// path is given, the snippet to add to the shell configuration.
+func prompt(shell string, paths []string) error {
+	if len(paths) == 0 {
+		snippet, ok := promptInit[shell]
+		if !ok {
+			return errors.Errorf("unsupported shell %q", shell)
+		}
+		fmt.Print(snippet)
+		return nil
+	}
+	if len(paths) != 1 {
+		return errors.New("a single image can be displayed in the prompt")
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", paths[0])
+	}
+	defer f.Close()
+	s, err := imgcat.Prompt(f, shell, promptCells)
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+// rendererPrefix is the prefix of the commands found in PATH by -renderer,
+// such as imgcat-renderer-foo for -renderer foo.
+const rendererPrefix = "imgcat-renderer-"
+
+// builtinProtocols are the protocols -renderer takes besides renderers.
+var builtinProtocols = []imgcat.Protocol{
+	imgcat.ITerm2, imgcat.Kitty, imgcat.Sixel, imgcat.HalfBlock, imgcat.ASCII, imgcat.Summary, imgcat.Skip,
+}
+
+// useRenderer returns the option displaying images with the protocol or the
+// renderer name, registering the command imgcat-renderer-name found in PATH
+// as one if needed.
+func useRenderer(name string) (imgcat.Option, error) {
+	p := imgcat.Protocol(name)
+	for _, b := range builtinProtocols {
+		if p == b {
+			return imgcat.FallbackChain(p), nil
+		}
+	}
+	for _, r := range imgcat.Renderers() {
+		if r == name {
+			return imgcat.FallbackChain(p), nil
+		}
+	}
+	path, err := exec.LookPath(rendererPrefix + name)
+	if err != nil {
+		return nil, errors.Errorf("unknown renderer %q: not a protocol, and no %s%s command found", name, rendererPrefix, name)
+	}
+	imgcat.RegisterRenderer(name, commandRenderer(path))
+	return imgcat.FallbackChain(p), nil
+}
+
+// commandRenderer returns a renderer running the command in path, with the
+// image on its standard input, and what's known about it in environment
+// variables. What the command writes is written to the terminal.
+func commandRenderer(path string) imgcat.Renderer {
+	return func(w io.Writer, p imgcat.Payload) error {
+		cmd := exec.Command(path)
+		cmd.Stdin = bytes.NewReader(p.Data)
+		cmd.Stdout = w
+		stderr := new(bytes.Buffer)
+		cmd.Stderr = stderr
+		cmd.Env = append(os.Environ(),
+			"IMGCAT_RENDER_NAME="+p.Name,
+			"IMGCAT_RENDER_FORMAT="+string(p.Format),
+			fmt.Sprintf("IMGCAT_RENDER_COLS=%d", p.Cols),
+			fmt.Sprintf("IMGCAT_RENDER_ROWS=%d", p.Rows),
+			fmt.Sprintf("IMGCAT_RENDER_PRESERVE_ASPECT_RATIO=%t", p.PreserveAspectRatio),
+			fmt.Sprintf("IMGCAT_RENDER_TMUX=%t", p.Tmux),
+		)
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return errors.Errorf("%s failed: %s", path, msg)
+			}
+			return errors.Wrapf(err, "%s failed", path)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/display"
+	"github.com/campoy/tools/imgcat/seal"
+	"github.com/pkg/errors"
+)
+
+// serve runs a display server showing what its clients send with options,
+// on a new Unix socket, or on addr with TCP if not empty. If a command is
+// given, it's run with IMGCAT_SOCK set, until it exits. Otherwise the server
+// runs until interrupted.
+func serve(addr string, command []string, options []imgcat.Option) error {
+	key, err := seal.LoadKey()
+	sealed := err == nil
+	switch {
+	case err == seal.ErrNoKey && addr != "":
+		return errors.New("a key is needed to listen on TCP, create one with -keygen")
+	case err == seal.ErrNoKey:
+		// The socket is in a directory only the user can read.
+	case err != nil:
+		return err
+	}
+	var l net.Listener
+	if addr != "" {
+		if l, err = net.Listen("tcp", addr); err != nil {
+			return errors.Wrap(err, "could not listen")
+		}
+		addr = "tcp://" + l.Addr().String()
+	} else if l, addr, err = display.Listen(); err != nil {
+		return err
+	}
+	// Serve returns once l is closed, with nothing else to report.
+	defer func() { _ = l.Close() }()
+
+	s := &display.Server{
+		Out:      os.Stdout,
+		Options:  options,
+		ErrorLog: func(err error) { log.Print(err) },
+	}
+	if sealed {
+		s.Key = &key
+	}
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+
+	if len(command) == 0 {
+		fmt.Fprintf(os.Stderr, "export %s=%s\n", display.EnvSock, addr)
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		select {
+		case <-interrupt:
+			return nil
+		case err := <-served:
+			return errors.Wrap(err, "display server failed")
+		}
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), display.EnvSock+"="+addr)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case err := <-served:
+		// The command can't display anything anymore.
+		_ = cmd.Process.Kill()
+		<-done
+		return errors.Wrap(err, "display server failed")
+	}
+}
+
+// push sends the images in paths to the display server given in
+// IMGCAT_SOCK. They're read as displayed ones are, so they can be URLs,
+// sources, archive members, or /dev/stdin.
+func push(paths []string) (err error) {
+	c, err := display.Dial()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	for _, path := range paths {
+		data, err := readPath(path)
+		if err != nil {
+			return err
+		}
+		img := display.Image{
+			Data:   data,
+			Name:   filepath.Base(path),
+			Width:  imgcat.Length(os.Getenv(imgcat.EnvWidth)),
+			Height: imgcat.Length(os.Getenv(imgcat.EnvHeight)),
+		}
+		if err := c.Send(img); err != nil {
+			return errors.Wrapf(err, "could not display %s", path)
+		}
+	}
+	return nil
+}
+
+// readPath returns the content of the image in path, opened with openPath.
+func readPath(path string) ([]byte, error) {
+	r, err := openPath(path)
+	if err != nil {
+		return nil, err
+	}
+	// Only reading can fail in a way that matters.
+	defer func() { _ = r.Close() }()
+	data, err := ioutil.ReadAll(r)
+	return data, errors.Wrapf(err, "could not read %s", path)
+}
+
+// keygen creates a key for sealed display servers, keeps it in the keychain,
+// and prints it to be given to other machines in IMGCAT_KEY.
+func keygen() error {
+	key, err := seal.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err := seal.StoreKey(key); err != nil {
+		log.Printf("could not keep the key in the keychain, set %s instead: %v", seal.EnvKey, err)
+	}
+	fmt.Printf("%s=%s\n", seal.EnvKey, key)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+var backgroundModes = map[string]imgcat.BackgroundMode{
+	"stretch": imgcat.BackgroundStretch,
+	"tile":    imgcat.BackgroundTile,
+	"fill":    imgcat.BackgroundFill,
+	"fit":     imgcat.BackgroundFit,
+}
+
+// setBackground sets the image at the given path as the session background,
+// or clears it if mode is none.
+func setBackground(mode string, paths []string) error {
+	if mode == "none" {
+		return imgcat.ClearBackground()
+	}
+	m, ok := backgroundModes[mode]
+	if !ok {
+		return errors.Errorf("unknown background mode %q", mode)
+	}
+	if len(paths) != 1 {
+		return errors.New("a single image is needed to set the background")
+	}
+	f, err := os.Open(paths[0])
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", paths[0])
+	}
+	defer f.Close()
+	return imgcat.SetBackground(f, m)
+}
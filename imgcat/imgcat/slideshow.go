@@ -0,0 +1,198 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+// Settings of the slideshow.
+const (
+	// transitionFrames is the number of intermediate frames in transitions,
+	// sent over transitionTime.
+	transitionFrames = 8
+	transitionTime   = 500 * time.Millisecond
+	// cellWidth and cellHeight are the assumed size of a cell in pixels, used
+	// to pick the size of the frames, which are scaled by the terminal anyway.
+	cellWidth, cellHeight = 8, 16
+	// maxFrameWidth limits the size of the frames sent.
+	maxFrameWidth = 1920
+)
+
+var transitions = map[string]imgcat.Transition{
+	"none":     nil,
+	"fade":     imgcat.Fade,
+	"dissolve": imgcat.Dissolve,
+	"wipe":     imgcat.Wipe,
+}
+
+// imageFiles returns the files in paths, and the images in the directories
+// among them, sorted by name, for slideshows and deduplication.
+func imageFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not open %s", path)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not list %s", path)
+		}
+		var names []string
+		for _, info := range infos {
+			switch strings.ToLower(filepath.Ext(info.Name())) {
+			case ".png", ".jpg", ".jpeg", ".gif":
+				if !info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
+					names = append(names, filepath.Join(path, info.Name()))
+				}
+			}
+		}
+		sort.Strings(names)
+		files = append(files, names...)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no images to show")
+	}
+	return files, nil
+}
+
+// slideshow displays the images in paths, and in the directories among them,
+// full screen one after the other every delay, until interrupted. Images are
+// shuffled every round if asked, and replaced with the given transition.
+func slideshow(paths []string, delay time.Duration, shuffle bool, transition string) error {
+	blend, ok := transitions[transition]
+	if !ok {
+		return errors.Errorf("unknown transition %q, use fade, dissolve, wipe, or none", transition)
+	}
+	files, err := imageFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	// The last row is left empty so drawing the image doesn't scroll.
+	rows := size.Rows - 1
+	if rows < 1 {
+		rows = 1
+	}
+	w, h := size.Cols*cellWidth, rows*cellHeight
+	if w > maxFrameWidth {
+		w, h = maxFrameWidth, h*maxFrameWidth/w
+	}
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true), imgcat.Width(imgcat.Cells(size.Cols)),
+		imgcat.Height(imgcat.Cells(rows)), imgcat.PreserveAspectRatio(true), imgcat.Newline(false))
+	if err != nil {
+		return err
+	}
+
+	// Use the alternate screen, without cursor, and restore the terminal
+	// when interrupted.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	show := func(img image.Image) error {
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return errors.Wrap(err, "could not encode frame")
+		}
+		fmt.Print("\x1b[H")
+		return enc.Encode(buf)
+	}
+	wait := func(d time.Duration) bool {
+		select {
+		case <-interrupt:
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var prev image.Image
+	for {
+		if shuffle {
+			random.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+		}
+		shown := 0
+		for _, path := range files {
+			img, err := load(path)
+			if err != nil {
+				// Skip what can't be shown, the screen is being used.
+				continue
+			}
+			frame := imgcat.Letterbox(img, w, h, color.Black)
+			if prev != nil && blend != nil {
+				for i := 1; i < transitionFrames; i++ {
+					if err := show(blend(prev, frame, float64(i)/transitionFrames)); err != nil {
+						return err
+					}
+					if !wait(transitionTime / transitionFrames) {
+						return nil
+					}
+				}
+			}
+			if err := show(frame); err != nil {
+				return err
+			}
+			prev = frame
+			shown++
+			if !wait(delay) {
+				return nil
+			}
+		}
+		if shown == 0 {
+			return errors.New("none of the files could be decoded")
+		}
+	}
+}
+
+func load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", path)
+	}
+	return img, nil
+}
@@ -2,7 +2,11 @@ package imgcat
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -32,20 +36,26 @@ func TestEncode(t *testing.T) {
 		out     string
 	}{
 		// {"empty", "", nil, "\x1b]1337;File=:\a\n"},
-		{"test", "test", nil, "\x1b]1337;File=:dGVzdA==\a\n"},
-		{"test inline", "test", []Option{Inline(true)}, "\x1b]1337;File=inline=1:dGVzdA==\a\n"},
-		{"test outline", "test", []Option{Inline(false)}, "\x1b]1337;File=inline=0:dGVzdA==\a\n"},
-		{"test with name", "test", []Option{Name("test")}, "\x1b]1337;File=name=dGVzdA==:dGVzdA==\a\n"},
-		{"test width 10 cells", "test", []Option{Width(Cells(10))}, "\x1b]1337;File=width=10:dGVzdA==\a\n"},
-		{"test width 10px", "test", []Option{Width(Pixels(10))}, "\x1b]1337;File=width=10px:dGVzdA==\a\n"},
-		{"test width 10%", "test", []Option{Width(Percent(10))}, "\x1b]1337;File=width=10%:dGVzdA==\a\n"},
-		{"test width auto", "test", []Option{Width(Auto())}, "\x1b]1337;File=width=auto:dGVzdA==\a\n"},
+		{"test", "test", nil, "\x1b]1337;File=size=4:dGVzdA==\a\n"},
+		{"test inline", "test", []Option{Inline(true)}, "\x1b]1337;File=inline=1;size=4:dGVzdA==\a\n"},
+		{"test outline", "test", []Option{Inline(false)}, "\x1b]1337;File=inline=0;size=4:dGVzdA==\a\n"},
+		{"test with name", "test", []Option{Name("test")}, "\x1b]1337;File=name=dGVzdA==;size=4:dGVzdA==\a\n"},
+		{"test width 10 cells", "test", []Option{Width(Cells(10))}, "\x1b]1337;File=width=10;size=4:dGVzdA==\a\n"},
+		{"test width 10px", "test", []Option{Width(Pixels(10))}, "\x1b]1337;File=width=10px;size=4:dGVzdA==\a\n"},
+		{"test width 10%", "test", []Option{Width(Percent(10))}, "\x1b]1337;File=width=10%;size=4:dGVzdA==\a\n"},
+		{"test width auto", "test", []Option{Width(Auto())}, "\x1b]1337;File=width=auto;size=4:dGVzdA==\a\n"},
 		{"test with size", "test", []Option{Size(42)}, "\x1b]1337;File=size=42:dGVzdA==\a\n"},
-		{"test height 10 cells", "test", []Option{Height(Cells(10))}, "\x1b]1337;File=height=10:dGVzdA==\a\n"},
-		{"test height 10px", "test", []Option{Height(Pixels(10))}, "\x1b]1337;File=height=10px:dGVzdA==\a\n"},
-		{"test height 10%", "test", []Option{Height(Percent(10))}, "\x1b]1337;File=height=10%:dGVzdA==\a\n"},
-		{"test preserve aspect ration", "test", []Option{PreserveAspectRatio(true)}, "\x1b]1337;File=preserveAspectRatio=1:dGVzdA==\a\n"},
-		{"test don't preserve aspect ration", "test", []Option{PreserveAspectRatio(false)}, "\x1b]1337;File=preserveAspectRatio=0:dGVzdA==\a\n"},
+		{"test height 10 cells", "test", []Option{Height(Cells(10))}, "\x1b]1337;File=height=10;size=4:dGVzdA==\a\n"},
+		{"test height 10px", "test", []Option{Height(Pixels(10))}, "\x1b]1337;File=height=10px;size=4:dGVzdA==\a\n"},
+		{"test height 10%", "test", []Option{Height(Percent(10))}, "\x1b]1337;File=height=10%;size=4:dGVzdA==\a\n"},
+		{"test preserve aspect ration", "test", []Option{PreserveAspectRatio(true)}, "\x1b]1337;File=preserveAspectRatio=1;size=4:dGVzdA==\a\n"},
+		{"test don't preserve aspect ration", "test", []Option{PreserveAspectRatio(false)}, "\x1b]1337;File=preserveAspectRatio=0;size=4:dGVzdA==\a\n"},
+		{"test without moving the cursor", "test", []Option{MoveCursor(false)}, "\x1b]1337;File=doNotMoveCursor=1;size=4:dGVzdA==\a\n"},
+		{"test without newline", "test", []Option{Newline(false)}, "\x1b]1337;File=size=4:dGVzdA==\a"},
+		{"test with passthrough", "test", []Option{Passthrough(true)}, "\x1bPtmux;\x1b\x1b]1337;File=size=4:dGVzdA==\a\x1b\\\n"},
+		{"last option wins", "test", []Option{Width(Cells(10)), Inline(true), Width(Cells(20))}, "\x1b]1337;File=width=20;inline=1;size=4:dGVzdA==\a\n"},
+		{"given size wins", "test", []Option{Size(42), Size(7)}, "\x1b]1337;File=size=7:dGVzdA==\a\n"},
+		{"raw argument", "test", []Option{Arg("type=image/png"), Inline(true)}, "\x1b]1337;File=type=image/png;inline=1;size=4:dGVzdA==\a\n"},
 		{"all options together", "test", []Option{
 			Inline(true), Name("test"), Width(Percent(10)), Height(Percent(10)), PreserveAspectRatio(false), Size(42),
 		}, "\x1b]1337;File=inline=1;name=dGVzdA==;width=10%;height=10%;preserveAspectRatio=0;size=42:dGVzdA==\a\n"},
@@ -68,6 +78,150 @@ func TestEncode(t *testing.T) {
 	}
 }
 
+func TestSize(t *testing.T) {
+	in := testPNG(t, 2, 2, color.White)
+	pipe := func() io.Reader { return struct{ io.Reader }{bytes.NewReader(in)} }
+	tc := []struct {
+		name    string
+		in      io.Reader
+		options []Option
+		// size is the size argument expected, -1 for none and 0 for the
+		// size of the payload.
+		size int
+	}{
+		{"seeker", bytes.NewReader(in), nil, len(in)},
+		{"pipe", pipe(), nil, -1},
+		{"given", bytes.NewReader(in), []Option{Size(42)}, 42},
+		{"transformed", pipe(), []Option{FlipH()}, 0},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig(tt.options)
+			r, err := cfg.prepare(tt.in)
+			if err != nil {
+				t.Fatalf("could not prepare: %v", err)
+			}
+			payload, _ := ioutil.ReadAll(r)
+			var want []string
+			switch {
+			case tt.size > 0:
+				want = []string{fmt.Sprintf("size=%d", tt.size)}
+			case tt.size == 0:
+				want = []string{fmt.Sprintf("size=%d", len(payload))}
+			}
+			if got := strings.Join(cfg.args, ";"); got != strings.Join(want, ";") {
+				t.Errorf("expected arguments %q; got %q", want, cfg.args)
+			}
+		})
+	}
+
+	// Only what's left to read is counted, and the payload is rewound.
+	r := bytes.NewReader(in)
+	r.Seek(8, io.SeekStart)
+	cfg := newConfig(nil)
+	if _, err := cfg.prepare(r); err != nil {
+		t.Fatalf("could not prepare: %v", err)
+	}
+	if want := fmt.Sprintf("size=%d", len(in)-8); len(cfg.args) != 1 || cfg.args[0] != want {
+		t.Errorf("expected arguments [%s]; got %q", want, cfg.args)
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 8 {
+		t.Errorf("expected payload at offset 8; got %d", pos)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	tc := []struct {
+		name    string
+		options []Option
+	}{
+		{"separator in length", []Option{Width(Length("10;inline=0"))}},
+		{"payload separator", []Option{Height(Length("1:dGVzdA=="))}},
+		{"bell", []Option{Width(Length("10\a"))}},
+		{"escape", []Option{Width(Cells(10)), Height(Length("\x1b\\"))}},
+		{"string terminator", []Option{Width(Length("10\u009c"))}},
+		{"argument without value", []Option{Arg("inline")}},
+		{"argument without key", []Option{Arg("=1")}},
+		{"separator in argument", []Option{Arg("inline=1;width=10")}},
+	}
+	for _, tt := range tc {
+		b, err := EncodeToBytes(strings.NewReader("test"), append(tt.options, Passthrough(false))...)
+		if !errors.Is(err, ErrOption) {
+			t.Errorf("%s: expected invalid option; got %q and error %v", tt.name, b, err)
+		}
+	}
+
+	// Names are base64 encoded, so any name is fine.
+	b, err := EncodeToBytes(strings.NewReader("test"), Name("a;b:c\a\x1b"), Passthrough(false))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if want := "\x1b]1337;File=name=YTtiOmMHGw==;size=4:dGVzdA==\a\n"; string(b) != want {
+		t.Errorf("expected output %q; got %q", want, b)
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	tc := []struct {
+		in  string
+		out Length
+		err bool
+	}{
+		{"40", Cells(40), false},
+		{"400px", Pixels(400), false},
+		{"50%", Percent(50), false},
+		{"auto", Auto(), false},
+		{"", "", true},
+		{"wide", "", true},
+		{"10;inline=0", "", true},
+	}
+	for _, tt := range tc {
+		l, err := ParseLength(tt.in)
+		if (err != nil) != tt.err || l != tt.out {
+			t.Errorf("expected ParseLength(%q) to be %q, error %v; got %q, %v", tt.in, tt.out, tt.err, l, err)
+		}
+	}
+}
+
+func FuzzOptions(f *testing.F) {
+	f.Add("cat.png", "10px", "auto")
+	f.Add("a;b:c\a\x1b\\", "10;inline=0", "1:dGVzdA==")
+	f.Add("\u009c", "\x9c", "")
+	f.Fuzz(func(t *testing.T, name, width, height string) {
+		b, err := EncodeToBytes(strings.NewReader("test"),
+			Name(name), Width(Length(width)), Height(Length(height)), Passthrough(false))
+		if err != nil {
+			if !errors.Is(err, ErrOption) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		}
+		// Whatever the options, the output is a single sequence with the
+		// same arguments and payload.
+		s := string(b)
+		if !strings.HasPrefix(s, "\x1b]1337;File=") || !strings.HasSuffix(s, "\a\n") {
+			t.Fatalf("unexpected sequence %q", s)
+		}
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "\x1b]1337;File="), "\a\n")
+		for i := 0; i < len(s); i++ {
+			if s[i] < ' ' || s[i] > '~' {
+				t.Fatalf("unexpected control character %q in %q", s[i], b)
+			}
+		}
+		parts := strings.Split(s, ":")
+		if len(parts) != 2 || parts[1] != "dGVzdA==" {
+			t.Fatalf("unexpected payload in %q", b)
+		}
+		var keys []string
+		for _, arg := range strings.Split(parts[0], ";") {
+			keys = append(keys, strings.SplitN(arg, "=", 2)[0])
+		}
+		if got := strings.Join(keys, ";"); got != "name;width;height;size" {
+			t.Fatalf("unexpected arguments %q in %q", got, b)
+		}
+	})
+}
+
 type badWriter struct{}
 
 func (badWriter) Write(p []byte) (int, error) {
@@ -162,6 +316,25 @@ func TestBadWriter(t *testing.T) {
 	}
 }
 
+func TestEncodeToBytes(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	isSupported = func() bool { return false }
+	check(t, os.Setenv("TMUX_TEST", "false"))
+
+	b, err := EncodeToBytes(strings.NewReader("test"), Inline(true))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if want := "\x1b]1337;File=inline=1;size=4:dGVzdA==\a\n"; string(b) != want {
+		t.Fatalf("expected output %q; got %q", want, b)
+	}
+
+	if _, err := EncodeToBytes(strings.NewReader("test"), Formats(PNG)); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
 func check(t *testing.T, err error) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -0,0 +1,176 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imghash computes perceptual hashes of images: 64 bit fingerprints
+// that stay close when images are resized, recompressed, or slightly edited,
+// so visually similar images can be found by comparing their hashes.
+//
+// Average is the fastest and Perceptual the most robust to edits, while
+// Difference is a good compromise. Hashes of different kinds must not be
+// compared with each other.
+package imghash
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// A Hash is a 64 bit perceptual hash of an image.
+type Hash uint64
+
+// String returns the hash as 16 hexadecimal digits.
+func (h Hash) String() string { return fmt.Sprintf("%016x", uint64(h)) }
+
+// Distance returns the number of bits that differ between a and b, from 0 for
+// identical hashes to 64. Images with hashes less than about 10 apart usually
+// look the same.
+func Distance(a, b Hash) int { return bits.OnesCount64(uint64(a ^ b)) }
+
+// Average returns the average hash of img: every bit tells whether a pixel of
+// the image shrunk to 8 by 8 is brighter than the mean.
+func Average(img image.Image) Hash {
+	g := gray(img, 8, 8)
+	mean := 0.0
+	for _, v := range g {
+		mean += v
+	}
+	mean /= float64(len(g))
+	return threshold(g, mean)
+}
+
+// Difference returns the difference hash of img: every bit tells whether a
+// pixel of the image shrunk to 9 by 8 is brighter than its right neighbour.
+func Difference(img image.Image) Hash {
+	g := gray(img, 9, 8)
+	var h Hash
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if g[y*9+x] > g[y*9+x+1] {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// Perceptual returns the perceptual hash of img: every bit tells whether one
+// of the 64 lowest frequencies of the discrete cosine transform of the image
+// shrunk to 32 by 32 is above their median.
+func Perceptual(img image.Image) Hash {
+	const n, k = 32, 8
+	g := gray(img, n, n)
+
+	// Separable DCT-II, keeping only the k lowest frequencies of each axis.
+	cos := make([]float64, k*n)
+	for u := 0; u < k; u++ {
+		for x := 0; x < n; x++ {
+			cos[u*n+x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * n))
+		}
+	}
+	rows := make([]float64, n*k)
+	for y := 0; y < n; y++ {
+		for u := 0; u < k; u++ {
+			s := 0.0
+			for x := 0; x < n; x++ {
+				s += g[y*n+x] * cos[u*n+x]
+			}
+			rows[y*k+u] = s
+		}
+	}
+	freqs := make([]float64, k*k)
+	for v := 0; v < k; v++ {
+		for u := 0; u < k; u++ {
+			s := 0.0
+			for y := 0; y < n; y++ {
+				s += rows[y*k+u] * cos[v*n+y]
+			}
+			freqs[v*k+u] = s
+		}
+	}
+
+	// The first coefficient is the mean brightness, which would skew the
+	// median, so it's left out of it.
+	sorted := append([]float64(nil), freqs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	return threshold(freqs, median)
+}
+
+// threshold returns the hash with a bit set for every value above t.
+func threshold(vs []float64, t float64) Hash {
+	var h Hash
+	for _, v := range vs {
+		h <<= 1
+		if v > t {
+			h |= 1
+		}
+	}
+	return h
+}
+
+// gray returns the luma of img shrunk to w by h pixels, row by row. Every
+// pixel is the average of the area of img it covers, so the result doesn't
+// depend on how the size of img divides the grid. Transparent pixels count as
+// black.
+func gray(img image.Image, w, h int) []float64 {
+	b := img.Bounds()
+	xs, ys := coverage(b.Dx(), w), coverage(b.Dy(), h)
+	sums := make([]float64, w*h)
+	weights := make([]float64, w*h)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			for _, cy := range ys[y-b.Min.Y] {
+				for _, cx := range xs[x-b.Min.X] {
+					wt := cx.weight * cy.weight
+					sums[cy.cell*w+cx.cell] += v * wt
+					weights[cy.cell*w+cx.cell] += wt
+				}
+			}
+		}
+	}
+	for i := range sums {
+		if weights[i] > 0 {
+			sums[i] /= weights[i]
+		}
+	}
+	return sums
+}
+
+// A share is the part of a cell of the grid covered by a pixel.
+type share struct {
+	cell   int
+	weight float64
+}
+
+// coverage returns, for each of n pixels, the shares of the m cells of the
+// grid it covers.
+func coverage(n, m int) [][]share {
+	shares := make([][]share, n)
+	for i := range shares {
+		// The pixel spans [lo, hi) in units of cells.
+		lo, hi := float64(i*m)/float64(n), float64((i+1)*m)/float64(n)
+		for c := int(lo); c < m && float64(c) < hi; c++ {
+			w := math.Min(hi, float64(c+1)) - math.Max(lo, float64(c))
+			if w > 0 {
+				shares[i] = append(shares[i], share{c, w})
+			}
+		}
+	}
+	return shares
+}
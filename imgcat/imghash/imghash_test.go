@@ -0,0 +1,117 @@
+package imghash
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient returns a w by h image getting brighter to the right, with a dark
+// square in the top left corner if marked.
+func gradient(w, h int, marked bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if marked && x < w/3 && y < h/3 {
+				v = 0xff
+			}
+			img.SetGray(x, y, color.Gray{v})
+		}
+	}
+	return img
+}
+
+// mirror returns img flipped horizontally.
+func mirror(img image.Image) image.Image {
+	b := img.Bounds()
+	dst := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.X-1-x+b.Min.X, y, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func TestDistance(t *testing.T) {
+	tc := []struct {
+		a, b Hash
+		d    int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xff00, 0x00ff, 16},
+		{0, ^Hash(0), 64},
+	}
+	for _, tt := range tc {
+		if got := Distance(tt.a, tt.b); got != tt.d {
+			t.Errorf("distance between %v and %v: expected %d; got %d", tt.a, tt.b, tt.d, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := Hash(0xabc).String(); got != "0000000000000abc" {
+		t.Errorf("expected 0000000000000abc; got %s", got)
+	}
+}
+
+func TestAverage(t *testing.T) {
+	// The right half of a gradient is brighter than the mean.
+	if got, want := Average(gradient(64, 64, false)), Hash(0x0f0f0f0f0f0f0f0f); got != want {
+		t.Errorf("expected %v; got %v", want, got)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	// Every pixel of a gradient is darker than its right neighbour.
+	if got := Difference(gradient(90, 80, false)); got != 0 {
+		t.Errorf("expected %v; got %v", Hash(0), got)
+	}
+	// Getting darker to the right sets every bit.
+	img := image.NewGray(image.Rect(0, 0, 9, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 9; x++ {
+			img.SetGray(x, y, color.Gray{uint8(255 - 20*x)})
+		}
+	}
+	if got := Difference(img); got != ^Hash(0) {
+		t.Errorf("expected %v; got %v", ^Hash(0), got)
+	}
+}
+
+// TestSimilar checks that hashes of resized images are near, and those of
+// different images far.
+func TestSimilar(t *testing.T) {
+	hashes := []struct {
+		name string
+		hash func(image.Image) Hash
+	}{
+		{"average", Average},
+		{"difference", Difference},
+		{"perceptual", Perceptual},
+	}
+	for _, h := range hashes {
+		t.Run(h.name, func(t *testing.T) {
+			a, b := h.hash(gradient(256, 256, true)), h.hash(gradient(100, 100, true))
+			if d := Distance(a, b); d > 4 {
+				t.Errorf("expected resized images to be near; got distance %d", d)
+			}
+			c := h.hash(mirror(gradient(256, 256, true)))
+			if d := Distance(a, c); d < 16 {
+				t.Errorf("expected different images to be far; got distance %d", d)
+			}
+		})
+	}
+}
+
+func TestSmallImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(1, 0, color.Gray{0xff})
+	if got, want := Average(img), Hash(0x0f0f0f0f0f0f0f0f); got != want {
+		t.Errorf("expected %v; got %v", want, got)
+	}
+	// Empty images don't panic.
+	Perceptual(image.NewGray(image.Rect(0, 0, 0, 0)))
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package it2 writes the same bytes as the imgcat and it2dl scripts of
+// iTerm2 3.5 for the same inputs, so dotfiles and scripts relying on them can
+// use the imgcat command instead, see imgcat -it2.
+//
+// The imgcat script sends images in parts, with MultipartFile, FilePart, and
+// FileEnd sequences, or in a single File sequence when given -l. The it2dl
+// script sends files to be downloaded rather than displayed.
+package it2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PartLen is the length of the base64 in each FilePart sequence sent by the
+// imgcat script, 150 bytes of the image.
+const PartLen = 200
+
+// LineLen is the length of the lines of base64 sent by the it2dl script, as
+// printed by GNU base64.
+const LineLen = 76
+
+// An AspectRatio is set with the -r and -s flags of the imgcat script.
+type AspectRatio int
+
+// Aspect ratios.
+const (
+	// DefaultAspectRatio leaves it to iTerm2, which preserves it.
+	DefaultAspectRatio AspectRatio = iota
+	// Preserve sends preserveAspectRatio=1, as with -r.
+	Preserve
+	// Stretch sends preserveAspectRatio=0, as with -s.
+	Stretch
+)
+
+// Options of an image, given to the imgcat script with flags.
+type Options struct {
+	// Name of the image as given to the script, a path or a URL, empty for
+	// images read from the standard input.
+	Name string
+	// Width and Height given with -W and -H, such as 40, 100px, 50%, or
+	// auto.
+	Width, Height string
+	// AspectRatio set with -r or -s.
+	AspectRatio AspectRatio
+	// Type hint given with -t, such as image/png or .png.
+	Type string
+	// PrintName prints the name below the image, as with -p.
+	PrintName bool
+	// Legacy sends the image in a single File sequence, as with -l.
+	Legacy bool
+	// Tmux wraps the sequences for tmux, see Tmux.
+	Tmux bool
+}
+
+// Tmux reports whether the scripts wrap their sequences for tmux given the
+// value of TERM: they do for screen and tmux ones, since TERM is passed
+// through SSH unlike TMUX.
+func Tmux(term string) bool {
+	return strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux")
+}
+
+// osc and st start and end the sequences of the scripts.
+func osc(tmux bool) string {
+	if tmux {
+		return "\x1bPtmux;\x1b\x1b]"
+	}
+	return "\x1b]"
+}
+
+func st(tmux bool) string {
+	if tmux {
+		return "\a\x1b\\"
+	}
+	return "\a"
+}
+
+// Imgcat writes the image in data as the imgcat script does.
+func Imgcat(w io.Writer, data []byte, o Options) error {
+	b := new(strings.Builder)
+	cmd := "MultipartFile"
+	if o.Legacy {
+		cmd = "File"
+	}
+	fmt.Fprintf(b, "%s1337;%s=inline=1;size=%d", osc(o.Tmux), cmd, len(data))
+	if o.Name != "" {
+		fmt.Fprintf(b, ";name=%s", base64.StdEncoding.EncodeToString([]byte(o.Name)))
+	}
+	if o.Width != "" {
+		fmt.Fprintf(b, ";width=%s", o.Width)
+	}
+	if o.Height != "" {
+		fmt.Fprintf(b, ";height=%s", o.Height)
+	}
+	switch o.AspectRatio {
+	case Preserve:
+		b.WriteString(";preserveAspectRatio=1")
+	case Stretch:
+		b.WriteString(";preserveAspectRatio=0")
+	}
+	if o.Type != "" {
+		fmt.Fprintf(b, ";type=%s", o.Type)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if o.Legacy {
+		b.WriteString(":" + encoded + st(o.Tmux))
+	} else {
+		b.WriteString(st(o.Tmux))
+		for len(encoded) > 0 {
+			n := PartLen
+			if n > len(encoded) {
+				n = len(encoded)
+			}
+			b.WriteString(osc(o.Tmux) + "1337;FilePart=" + encoded[:n] + st(o.Tmux))
+			encoded = encoded[n:]
+		}
+		b.WriteString(osc(o.Tmux) + "1337;FileEnd" + st(o.Tmux))
+	}
+	b.WriteString("\n")
+	if o.PrintName {
+		b.WriteString(o.Name + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Download writes the file in data as the it2dl script does, for iTerm2 to
+// download it as name.
+func Download(w io.Writer, name string, data []byte, tmux bool) error {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%s1337;File=name=%s;size=%d:", osc(tmux), base64.StdEncoding.EncodeToString([]byte(name)), len(data))
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := LineLen
+		if n > len(encoded) {
+			n = len(encoded)
+		}
+		b.WriteString(encoded[:n] + "\n")
+		encoded = encoded[n:]
+	}
+	b.WriteString(st(tmux))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,164 @@
+package it2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImgcat(t *testing.T) {
+	hello := []byte("hello")
+	long := bytes.Repeat([]byte("a"), 151)
+	tc := []struct {
+		name string
+		data []byte
+		o    Options
+		want string
+	}{
+		{"stdin", hello, Options{},
+			"\x1b]1337;MultipartFile=inline=1;size=5\a\x1b]1337;FilePart=aGVsbG8=\a\x1b]1337;FileEnd\a\n"},
+		{"file", hello, Options{Name: "a.png"},
+			"\x1b]1337;MultipartFile=inline=1;size=5;name=YS5wbmc=\a\x1b]1337;FilePart=aGVsbG8=\a\x1b]1337;FileEnd\a\n"},
+		{"flags", hello, Options{Name: "dir/a.png", Width: "50%", Height: "10", AspectRatio: Stretch, Type: "image/png", PrintName: true},
+			"\x1b]1337;MultipartFile=inline=1;size=5;name=ZGlyL2EucG5n;width=50%;height=10;preserveAspectRatio=0;type=image/png\a" +
+				"\x1b]1337;FilePart=aGVsbG8=\a\x1b]1337;FileEnd\a\ndir/a.png\n"},
+		{"preserve", hello, Options{AspectRatio: Preserve, Width: "100px"},
+			"\x1b]1337;MultipartFile=inline=1;size=5;width=100px;preserveAspectRatio=1\a\x1b]1337;FilePart=aGVsbG8=\a\x1b]1337;FileEnd\a\n"},
+		{"legacy", hello, Options{Name: "a.png", Legacy: true},
+			"\x1b]1337;File=inline=1;size=5;name=YS5wbmc=:aGVsbG8=\a\n"},
+		{"tmux", hello, Options{Tmux: true},
+			"\x1bPtmux;\x1b\x1b]1337;MultipartFile=inline=1;size=5\a\x1b\\" +
+				"\x1bPtmux;\x1b\x1b]1337;FilePart=aGVsbG8=\a\x1b\\" +
+				"\x1bPtmux;\x1b\x1b]1337;FileEnd\a\x1b\\\n"},
+		{"legacy tmux", hello, Options{Legacy: true, Tmux: true},
+			"\x1bPtmux;\x1b\x1b]1337;File=inline=1;size=5:aGVsbG8=\a\x1b\\\n"},
+		{"parts", long, Options{},
+			"\x1b]1337;MultipartFile=inline=1;size=151\a" +
+				"\x1b]1337;FilePart=" + strings.Repeat("YWFh", 50) + "\a" +
+				"\x1b]1337;FilePart=YQ==\a\x1b]1337;FileEnd\a\n"},
+		{"empty", nil, Options{Name: "empty.png"},
+			"\x1b]1337;MultipartFile=inline=1;size=0;name=ZW1wdHkucG5n\a\x1b]1337;FileEnd\a\n"},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := Imgcat(buf, c.data, c.o); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != c.want {
+				t.Errorf("expected %q; got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	tc := []struct {
+		name string
+		file string
+		data []byte
+		tmux bool
+		want string
+	}{
+		{"short", "a.txt", []byte("hello"), false,
+			"\x1b]1337;File=name=YS50eHQ=;size=5:aGVsbG8=\n\a"},
+		{"lines", "a.txt", bytes.Repeat([]byte("a"), 60), false,
+			"\x1b]1337;File=name=YS50eHQ=;size=60:" + strings.Repeat("YWFh", 19) + "\n" + strings.Repeat("YWFh", 1) + "\n\a"},
+		{"tmux", "a.txt", []byte("hello"), true,
+			"\x1bPtmux;\x1b\x1b]1337;File=name=YS50eHQ=;size=5:aGVsbG8=\n\a\x1b\\"},
+		{"empty", "a.txt", nil, false,
+			"\x1b]1337;File=name=YS50eHQ=;size=0:\a"},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := Download(buf, c.file, c.data, c.tmux); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != c.want {
+				t.Errorf("expected %q; got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTmux(t *testing.T) {
+	tc := []struct {
+		term string
+		want bool
+	}{
+		{"screen", true},
+		{"screen-256color", true},
+		{"tmux-256color", true},
+		{"xterm-256color", false},
+		{"", false},
+	}
+	for _, c := range tc {
+		if got := Tmux(c.term); got != c.want {
+			t.Errorf("Tmux(%q) is %v; expected %v", c.term, got, c.want)
+		}
+	}
+}
+
+// TestScripts compares the output of the scripts of iTerm2 with the ones of
+// this package, when IT2_UTILITIES is the directory holding them, such as the
+// utilities directory of the iTerm2 repository.
+func TestScripts(t *testing.T) {
+	dir := os.Getenv("IT2_UTILITIES")
+	if dir == "" {
+		t.Skip("IT2_UTILITIES is not set")
+	}
+	tmp, err := ioutil.TempDir("", "it2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, "a.png")
+	data := bytes.Repeat([]byte("imgcat\x00\xff"), 100)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		args []string
+		o    Options
+	}{
+		{nil, Options{Name: path}},
+		{[]string{"-W", "50%", "-H", "10"}, Options{Name: path, Width: "50%", Height: "10"}},
+		{[]string{"-r", "-p"}, Options{Name: path, AspectRatio: Preserve, PrintName: true}},
+		{[]string{"-s", "-t", "image/png"}, Options{Name: path, AspectRatio: Stretch, Type: "image/png"}},
+		{[]string{"-l"}, Options{Name: path, Legacy: true}},
+	}
+	for _, term := range []string{"xterm-256color", "screen"} {
+		for _, c := range tc {
+			cmd := exec.Command("bash", append(append([]string{filepath.Join(dir, "imgcat")}, c.args...), path)...)
+			cmd.Env = append(os.Environ(), "TERM="+term)
+			got, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("imgcat %q failed: %v", c.args, err)
+			}
+			c.o.Tmux = Tmux(term)
+			want := new(bytes.Buffer)
+			Imgcat(want, data, c.o)
+			if !bytes.Equal(got, want.Bytes()) {
+				t.Errorf("imgcat %q with TERM=%s wrote %q; expected %q", c.args, term, got, want)
+			}
+		}
+
+		cmd := exec.Command("bash", filepath.Join(dir, "it2dl"), path)
+		cmd.Env = append(os.Environ(), "TERM="+term)
+		got, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("it2dl failed: %v", err)
+		}
+		want := new(bytes.Buffer)
+		Download(want, path, data, Tmux(term))
+		if !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("it2dl with TERM=%s wrote %q; expected %q", term, got, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// kittyChunk is the largest number of base64 bytes the kitty graphics
+// protocol accepts in a single sequence.
+const kittyChunk = 4096
+
+// A Medium is how images are transmitted with the kitty graphics protocol, see
+// KittyMedium.
+type Medium string
+
+// Transmission media of the kitty graphics protocol.
+const (
+	// Direct sends images in the escape sequences, encoded in base64, which
+	// works everywhere. It's the default.
+	Direct Medium = "direct"
+	// TempFile writes images to temporary files, which the terminal reads and
+	// deletes.
+	TempFile Medium = "file"
+	// SharedMemory writes images to POSIX shared memory objects, which the
+	// terminal reads and unlinks. Where there's no /dev/shm, such as on
+	// macOS, TempFile is used instead.
+	SharedMemory Medium = "shm"
+)
+
+// KittyMedium sets how images are transmitted with the Kitty protocol.
+// TempFile and SharedMemory are much faster than Direct for large images, but
+// only work when the terminal runs on the same machine: in remote sessions, as
+// reported by IsRemote, and when the file can't be written, images are sent
+// directly.
+func KittyMedium(m Medium) Option {
+	return func(c *config) { c.medium = m }
+}
+
+// Compress set to true sends images with the Kitty protocol as raw pixels
+// compressed with zlib, when that's smaller than their PNG payload, as it
+// often is for large flat-color images such as charts and screenshots. This
+// saves bytes through tmux and SSH, for the time it takes to compress them.
+func Compress(b bool) Option {
+	return func(c *config) { c.compress = b }
+}
+
+// Can be swapped for testing.
+var shmDir = "/dev/shm"
+
+// DeleteImages deletes the images displayed with the Kitty protocol, which
+// stay on the screen until they're deleted, unlike the images of other
+// protocols, which are removed with the cells they cover. It writes nothing
+// with other protocols.
+func (enc *Encoder) DeleteImages() error {
+	if enc.Protocol() != Kitty {
+		return nil
+	}
+	cfg := newConfig(enc.options)
+	_, err := io.WriteString(enc.out, passthrough("\x1b_Ga=d,d=A,q=2\x1b\\", cfg.tmux()))
+	return err
+}
+
+// kittySequence returns the sequences displaying img, whose encoded payload is
+// data, with the kitty graphics protocol, over cols by rows cells. The
+// protocol only takes PNG images, others are re-encoded.
+func kittySequence(data []byte, img image.Image, cols, rows int, cfg *config) (string, error) {
+	if Sniff(data) != PNG {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, img); err != nil {
+			return "", fmt.Errorf("could not encode image: %v", err)
+		}
+		data = buf.Bytes()
+	}
+
+	format := "f=100"
+	if cfg.compress {
+		if pix, keys, err := kittyPixels(img); err == nil && len(pix) < len(data) {
+			data, format = pix, keys
+		}
+	}
+
+	// Transmit and display the image, without replies from the terminal.
+	control := fmt.Sprintf("a=T,%s,q=2,c=%d,r=%d", format, cols, rows)
+	if !cfg.moveCursor() {
+		control += ",C=1"
+	}
+	if medium := cfg.kittyMedium(); medium != Direct {
+		if key, name, err := kittyWrite(medium, data); err == nil {
+			control += fmt.Sprintf(",t=%s,S=%d", key, len(data))
+			data = []byte(name)
+		}
+	}
+	payload := base64.StdEncoding.EncodeToString(data)
+	var s strings.Builder
+	for first := true; first || len(payload) > 0; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunk {
+			chunk = chunk[:kittyChunk]
+		}
+		payload = payload[len(chunk):]
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		keys := fmt.Sprintf("m=%d", more)
+		if first {
+			keys = control + "," + keys
+		}
+		s.WriteString(passthrough("\x1b_G"+keys+";"+chunk+"\x1b\\", cfg.tmux()))
+	}
+	return s.String(), nil
+}
+
+// kittyPixels returns the pixels of img compressed with zlib, and the keys
+// giving their format: RGB for opaque images, and RGBA otherwise.
+func kittyPixels(img image.Image) ([]byte, string, error) {
+	b := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	pix, bits := nrgba.Pix, 32
+	if nrgba.Opaque() {
+		rgb := make([]byte, 0, len(pix)/4*3)
+		for i := 0; i < len(pix); i += 4 {
+			rgb = append(rgb, pix[i:i+3]...)
+		}
+		pix, bits = rgb, 24
+	}
+	buf := new(bytes.Buffer)
+	w, err := zlib.NewWriterLevel(buf, zlib.BestCompression)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := w.Write(pix); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), fmt.Sprintf("f=%d,s=%d,v=%d,o=z", bits, b.Dx(), b.Dy()), nil
+}
+
+// kittyMedium returns the medium images are transmitted with, see KittyMedium.
+func (c *config) kittyMedium() Medium {
+	if c.medium == "" || IsRemote() {
+		return Direct
+	}
+	return c.medium
+}
+
+// kittyWrite writes data for the terminal to read with the given medium, and
+// returns the value of the t key of the medium used, and the name sent
+// instead of data. Files are created with a random name and mode 0600, and
+// the ones in the temporary directory are named so that kitty agrees to
+// delete them.
+func kittyWrite(m Medium, data []byte) (key, name string, err error) {
+	if m == SharedMemory {
+		if info, err := os.Stat(shmDir); err == nil && info.IsDir() {
+			path, err := writeTemp(shmDir, "imgcat-", data)
+			if err == nil {
+				// Shared memory objects are named relative to /dev/shm.
+				return "s", "/" + filepath.Base(path), nil
+			}
+		}
+	}
+	path, err := writeTemp("", "tty-graphics-protocol-", data)
+	if err != nil {
+		return "", "", err
+	}
+	return "t", path, nil
+}
+
+// writeTemp writes data to a new file of dir, the temporary directory if
+// empty, whose name starts with prefix, and returns its path.
+func writeTemp(dir, prefix string, data []byte) (string, error) {
+	f, err := ioutil.TempFile(dir, prefix)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// The file is of no use, and err says why.
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,269 @@
+package imgcat
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestKittySequence(t *testing.T) {
+	data := noisePNG(t, 64, 64)
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	out, err := kittySequence(data, img, 8, 4, newConfig([]Option{Passthrough(false)}))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+
+	chunks := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(out, -1)
+	want := (base64.StdEncoding.EncodedLen(len(data)) + kittyChunk - 1) / kittyChunk
+	if len(chunks) != want || want < 2 {
+		t.Fatalf("expected %d chunks; got %d", want, len(chunks))
+	}
+	var payload string
+	for i, c := range chunks {
+		keys := "m=1"
+		if i == len(chunks)-1 {
+			keys = "m=0"
+		}
+		if i == 0 {
+			keys = "a=T,f=100,q=2,c=8,r=4," + keys
+		}
+		if c[1] != keys {
+			t.Errorf("expected keys %q in chunk %d; got %q", keys, i, c[1])
+		}
+		if len(c[2]) > kittyChunk {
+			t.Errorf("chunk %d is %d bytes long", i, len(c[2]))
+		}
+		payload += c[2]
+	}
+	if got, _ := base64.StdEncoding.DecodeString(payload); !bytes.Equal(got, data) {
+		t.Fatalf("payload was not sent as is")
+	}
+}
+
+func TestKittySequenceOptions(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.White)
+
+	// Payloads that are not PNG are re-encoded.
+	out, err := kittySequence([]byte("GIF89a"), img, 1, 1, newConfig([]Option{MoveCursor(false), Passthrough(true)}))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if !strings.HasPrefix(out, "\x1bPtmux;\x1b\x1b_Ga=T,f=100,q=2,c=1,r=1,C=1,m=0;") {
+		t.Fatalf("unexpected sequence %q", out)
+	}
+	payload := out[strings.Index(out, ";iVBOR")+1 : strings.Index(out, "\x1b\x1b\\")]
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatalf("could not decode payload: %v", err)
+	}
+	if Sniff(data) != PNG {
+		t.Fatalf("expected a PNG payload; got %q", data)
+	}
+}
+
+func TestKittyMedium(t *testing.T) {
+	defer func(old func() bool) { isRemote = old }(isRemote)
+	defer func(old string) { shmDir = old }(shmDir)
+	tmp, shm := t.TempDir(), t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	data := noisePNG(t, 8, 8)
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+
+	tc := []struct {
+		name   string
+		medium Medium
+		remote bool
+		shmDir string
+		// key is the value of the t key, empty for direct transmissions.
+		key string
+		// dir the file is written to, and the prefix of its name.
+		dir, prefix string
+	}{
+		{"direct", Direct, false, shm, "", "", ""},
+		{"temporary file", TempFile, false, shm, "t", tmp, "tty-graphics-protocol-"},
+		{"shared memory", SharedMemory, false, shm, "s", shm, "imgcat-"},
+		{"no shared memory", SharedMemory, false, filepath.Join(shm, "missing"), "t", tmp, "tty-graphics-protocol-"},
+		{"remote", SharedMemory, true, shm, "", "", ""},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			isRemote = func() bool { return tt.remote }
+			shmDir = tt.shmDir
+			out, err := kittySequence(data, img, 1, 1, newConfig([]Option{KittyMedium(tt.medium), Passthrough(false)}))
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			m := regexp.MustCompile("^\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindStringSubmatch(out)
+			if m == nil {
+				t.Fatalf("unexpected sequence %q", out)
+			}
+			payload, err := base64.StdEncoding.DecodeString(m[2])
+			if err != nil {
+				t.Fatalf("could not decode payload: %v", err)
+			}
+			if tt.key == "" {
+				if strings.Contains(m[1], ",t=") || !bytes.Equal(payload, data) {
+					t.Fatalf("expected a direct transmission; got keys %q", m[1])
+				}
+				return
+			}
+			if want := fmt.Sprintf(",t=%s,S=%d,m=0", tt.key, len(data)); !strings.HasSuffix(m[1], want) {
+				t.Errorf("expected keys ending with %q; got %q", want, m[1])
+			}
+			path := string(payload)
+			if tt.key == "s" {
+				path = filepath.Join(tt.shmDir, path)
+			}
+			if dir, name := filepath.Split(path); filepath.Clean(dir) != tt.dir || !strings.HasPrefix(name, tt.prefix) {
+				t.Errorf("expected a file starting with %s in %s; got %s", tt.prefix, tt.dir, path)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("could not find the file sent: %v", err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("expected a file with mode 0600; got %v", perm)
+			}
+			if got, err := ioutil.ReadFile(path); err != nil || !bytes.Equal(got, data) {
+				t.Errorf("the file sent doesn't hold the image, err: %v", err)
+			}
+		})
+	}
+}
+
+func TestKittyCompress(t *testing.T) {
+	flat := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, image.Rect(20, 20, 80, 60), image.NewUniform(color.NRGBA{0xd6, 0x27, 0x28, 0xff}), image.Point{}, draw.Src)
+	clear := image.NewNRGBA(flat.Bounds())
+	draw.Draw(clear, image.Rect(20, 20, 80, 60), image.NewUniform(color.NRGBA{0, 0, 0xff, 0x80}), image.Point{}, draw.Src)
+	// Gradients compress better with the filters of PNG.
+	gradient := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			gradient.Set(x, y, color.NRGBA{uint8(4 * x), uint8(4 * y), uint8(x * y / 16), 0xff})
+		}
+	}
+
+	// Payloads of the default PNG encoder, as the iTerm2 protocol sends them.
+	encode := func(img image.Image) []byte {
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, img); err != nil {
+			t.Fatalf("could not encode: %v", err)
+		}
+		return buf.Bytes()
+	}
+	tc := []struct {
+		name string
+		img  image.Image
+		data []byte
+		// format is the f key sent, and bytes the size of a pixel, zero for
+		// PNG payloads.
+		format string
+		bytes  int
+	}{
+		{"opaque", flat, encode(flat), "f=24,s=200,v=100,o=z", 3},
+		{"transparent", clear, encode(clear), "f=32,s=200,v=100,o=z", 4},
+		{"gradient", gradient, encode(gradient), "f=100", 0},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := kittySequence(tt.data, tt.img, 1, 1, newConfig([]Option{Compress(true), Passthrough(false)}))
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			chunks := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(out, -1)
+			if len(chunks) == 0 {
+				t.Fatalf("unexpected sequence %q", out)
+			}
+			if want := "a=T," + tt.format + ",q=2,"; !strings.HasPrefix(chunks[0][1], want) {
+				t.Fatalf("expected keys starting with %q; got %q", want, chunks[0][1])
+			}
+			var payload string
+			for _, c := range chunks {
+				payload += c[2]
+			}
+			data, err := base64.StdEncoding.DecodeString(payload)
+			if err != nil {
+				t.Fatalf("could not decode payload: %v", err)
+			}
+			if tt.bytes == 0 {
+				if !bytes.Equal(data, tt.data) {
+					t.Fatalf("payload was not sent as is")
+				}
+				return
+			}
+			if len(data) >= len(tt.data) {
+				t.Errorf("expected fewer than the %d bytes of the PNG payload; got %d", len(tt.data), len(data))
+			}
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("could not decompress: %v", err)
+			}
+			pix, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("could not decompress: %v", err)
+			}
+			b := tt.img.Bounds()
+			if len(pix) != b.Dx()*b.Dy()*tt.bytes {
+				t.Fatalf("expected %d bytes of pixels; got %d", b.Dx()*b.Dy()*tt.bytes, len(pix))
+			}
+			// The pixel at 30,30 is in the block.
+			i := (30*b.Dx() + 30) * tt.bytes
+			c := color.NRGBAModel.Convert(tt.img.At(30, 30)).(color.NRGBA)
+			want := []byte{c.R, c.G, c.B, c.A}[:tt.bytes]
+			if !bytes.Equal(pix[i:i+tt.bytes], want) {
+				t.Errorf("expected pixel %v; got %v", want, pix[i:i+tt.bytes])
+			}
+		})
+	}
+}
+
+func TestDeleteImages(t *testing.T) {
+	tc := []struct {
+		name     string
+		protocol Protocol
+		options  []Option
+		out      string
+	}{
+		{"kitty", Kitty, []Option{Passthrough(false)}, "\x1b_Ga=d,d=A,q=2\x1b\\"},
+		{"kitty in tmux", Kitty, []Option{Passthrough(true)}, "\x1bPtmux;\x1b\x1b_Ga=d,d=A,q=2\x1b\x1b\\\x1b\\"},
+		{"iterm2", ITerm2, nil, ""},
+		{"sixel", Sixel, nil, ""},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := &Encoder{out: buf, options: tt.options, protocol: tt.protocol}
+			if err := enc.DeleteImages(); err != nil {
+				t.Fatalf("could not delete images: %v", err)
+			}
+			if buf.String() != tt.out {
+				t.Errorf("expected %q; got %q", tt.out, buf)
+			}
+		})
+	}
+	enc := &Encoder{out: badWriter{}, protocol: Kitty}
+	if err := enc.DeleteImages(); err == nil {
+		t.Errorf("expected error; got nothing")
+	}
+}
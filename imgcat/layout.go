@@ -0,0 +1,119 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/campoy/tools/imgcat/textwidth"
+)
+
+// besideGap is the number of cells between an image and the text beside it.
+const besideGap = 1
+
+// Flow wraps text in lines of at most width cells, breaking at spaces, except
+// for the first rows lines, which are indent cells narrower to leave room for
+// an image on their left. Newlines in text start new paragraphs, and words
+// longer than a line are broken. Widths are measured by the textwidth package,
+// so text can hold colors and wide characters.
+func Flow(text string, width, indent, rows int) []string {
+	var lines []string
+	lineWidth := func() int {
+		w := width
+		if len(lines) < rows {
+			w -= indent
+		}
+		if w < 1 {
+			w = 1
+		}
+		return w
+	}
+	for _, para := range strings.Split(text, "\n") {
+		line, n := "", 0
+		for _, word := range strings.Fields(para) {
+			wn := textwidth.Width(word)
+			if n > 0 && n+1+wn > lineWidth() {
+				lines = append(lines, line)
+				line, n = "", 0
+			}
+			for wn > lineWidth() && n == 0 {
+				head := textwidth.Truncate(word, lineWidth())
+				if textwidth.Width(head) == 0 {
+					// A wide character in a one cell line.
+					_, size := utf8.DecodeRuneInString(word[len(head):])
+					head = word[:len(head)+size]
+				}
+				if head == word {
+					break
+				}
+				lines = append(lines, head)
+				word, wn = word[len(head):], wn-textwidth.Width(head)
+			}
+			if wn == 0 {
+				continue
+			}
+			if n > 0 {
+				line, n = line+" ", n+1
+			}
+			line, n = line+word, n+wn
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// EncodeBeside displays the image read from r in cols by rows cells at the
+// left of the terminal, with text flowing to its right, see Flow. Lines beyond
+// the height of the image take the whole width, given in cells; if it's zero,
+// the width of the tmux pane or 80 is used. The cursor is left at the start
+// of the line under both the image and the text.
+//
+// The image is drawn without moving the cursor, which needs iTerm2 3.5 or
+// later.
+func (enc *Encoder) EncodeBeside(r io.Reader, text string, cols, rows, width int) error {
+	if width <= 0 {
+		width = 80
+		if s, err := paneSize(); err == nil {
+			width = s.Cols
+		}
+	}
+	lines := Flow(text, width, cols+besideGap, rows)
+
+	// Reserve the rows first, so the terminal doesn't scroll while the image
+	// and the text next to it are drawn.
+	if _, err := fmt.Fprintf(enc.out, "%s\x1b[%dA\r", strings.Repeat("\n", rows), rows); err != nil {
+		return err
+	}
+	img := &Encoder{out: enc.out, options: append(enc.options[:len(enc.options):len(enc.options)],
+		Width(Cells(cols)), Height(Cells(rows)), MoveCursor(false), Newline(false))}
+	if err := img.Encode(r); err != nil {
+		return err
+	}
+	for i := 0; i < len(lines) || i < rows; i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		if i < rows && line != "" {
+			line = fmt.Sprintf("\x1b[%dC%s", cols+besideGap, line)
+		}
+		if _, err := fmt.Fprintln(enc.out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
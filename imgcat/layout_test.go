@@ -0,0 +1,68 @@
+package imgcat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/termsize"
+)
+
+func TestFlow(t *testing.T) {
+	tc := []struct {
+		name                string
+		text                string
+		width, indent, rows int
+		lines               []string
+	}{
+		{"no image", "the quick brown fox jumps", 10, 0, 0, []string{"the quick", "brown fox", "jumps"}},
+		{"beside image", "the quick brown fox jumps over", 12, 4, 2, []string{"the", "quick", "brown fox", "jumps over"}},
+		{"paragraphs", "one\n\ntwo", 10, 0, 0, []string{"one", "", "two"}},
+		{"long word", "abcdefghij k", 4, 0, 0, []string{"abcd", "efgh", "ij k"}},
+		{"unicode", "ñandú ñandú", 5, 0, 0, []string{"ñandú", "ñandú"}},
+		{"narrow", "ab", 3, 5, 1, []string{"a", "b"}},
+		{"empty", "", 10, 2, 3, []string{""}},
+		{"colors", "\x1b[1mbold\x1b[0m text", 4, 0, 0, []string{"\x1b[1mbold\x1b[0m", "text"}},
+		{"wide", "日本語 ok", 5, 0, 0, []string{"日本", "語 ok"}},
+		{"wide in one cell", "日本", 1, 0, 0, []string{"日", "本"}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Flow(tt.text, tt.width, tt.indent, tt.rows)
+			if strings.Join(got, "|") != strings.Join(tt.lines, "|") {
+				t.Errorf("expected lines %q; got %q", tt.lines, got)
+			}
+		})
+	}
+}
+
+func TestEncodeBeside(t *testing.T) {
+	defer func(old func() (termsize.Size, error)) { paneSize = old }(paneSize)
+	paneSize = func() (termsize.Size, error) { return termsize.Size{Cols: 12, Rows: 24}, nil }
+
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Inline(true), Passthrough(false)}}
+	if err := enc.EncodeBeside(strings.NewReader("test"), "the quick brown fox jumps over", 3, 2, 0); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	want := "\n\n\x1b[2A\r" +
+		"\x1b]1337;File=inline=1;width=3;height=2;doNotMoveCursor=1;size=4:dGVzdA==\a" +
+		"\x1b[4Cthe\n\x1b[4Cquick\nbrown fox\njumps over\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected output %q; got %q", want, got)
+	}
+
+	buf.Reset()
+	if err := enc.EncodeBeside(strings.NewReader("test"), "hi", 3, 3, 20); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if got := buf.String(); !strings.HasSuffix(got, "\a\x1b[4Chi\n\n\n") {
+		t.Errorf("expected text padded to the height of the image; got %q", got)
+	}
+
+	enc = &Encoder{out: badWriter{}}
+	if err := enc.EncodeBeside(strings.NewReader("test"), "hi", 3, 3, 20); err == nil || err.Error() != "bad writer" {
+		t.Errorf("expected bad writer error; got %v", err)
+	}
+
+}
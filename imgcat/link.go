@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Link makes images hyperlinks to the given URL with OSC 8, so they can be
+// opened with a click, or Cmd-click in iTerm2, in terminals supporting it.
+// This covers the text drawn by protocols other than ITerm2 too, such as the
+// placeholders of Summary. The URL must be made of printable ASCII
+// characters, as escaped by net/url; an empty one means no link.
+func Link(u string) Option {
+	return func(c *config) {
+		for i := 0; i < len(u); i++ {
+			if u[i] < ' ' || u[i] > '~' {
+				if c.err == nil {
+					c.err = fmt.Errorf("link %q: %w", u, ErrOption)
+				}
+				return
+			}
+		}
+		c.link = u
+	}
+}
+
+// LinkToFile makes every image read from a regular file, such as an *os.File,
+// a hyperlink to that file, see Link.
+func LinkToFile() Option {
+	return Computed(func(r io.Reader) Option {
+		path := filePath(r)
+		if path == "" {
+			return nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil
+		}
+		return Link(fileURL(abs))
+	})
+}
+
+// fileURL returns the file URL of the absolute path, with the host name as
+// OSC 8 wants, so terminals can tell the files of remote hosts apart.
+func fileURL(path string) string {
+	host, _ := os.Hostname()
+	return (&url.URL{Scheme: "file", Host: host, Path: filepath.ToSlash(path)}).String()
+}
+
+// linkStart returns the sequence starting the hyperlink of images, if any.
+// Terminal multiplexers such as tmux handle OSC 8 themselves, so it's never
+// wrapped for passthrough.
+func (c *config) linkStart() string {
+	if c.link == "" {
+		return ""
+	}
+	return "\x1b]8;;" + c.link + "\x1b\\"
+}
+
+// linkEnd returns the sequence ending the hyperlink of images, if any.
+func (c *config) linkEnd() string {
+	if c.link == "" {
+		return ""
+	}
+	return "\x1b]8;;\x1b\\"
+}
@@ -0,0 +1,78 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	const (
+		start = "\x1b]8;;https://example.com/a.png\x1b\\"
+		end   = "\x1b]8;;\x1b\\"
+	)
+	tc := []struct {
+		name     string
+		protocol Protocol
+		options  []Option
+		out      string
+	}{
+		{"iterm2", ITerm2, []Option{Link("https://example.com/a.png")},
+			start + "\x1b]1337;File=size=4:dGVzdA==\a" + end + "\n"},
+		{"passthrough", ITerm2, []Option{Link("https://example.com/a.png"), Passthrough(true), Newline(false)},
+			start + "\x1bPtmux;\x1b\x1b]1337;File=size=4:dGVzdA==\a\x1b\\" + end},
+		{"multipart", ITerm2, []Option{Link("https://example.com/a.png"), Multipart(4)},
+			start + "\x1b]1337;MultipartFile=size=4\a\x1b]1337;FilePart=dGVz\a\x1b]1337;FilePart=dA==\a\x1b]1337;FileEnd\a" + end + "\n"},
+		{"placeholder", Summary, []Option{Link("https://example.com/a.png")},
+			start + "[image: 4 B]" + end + "\n"},
+		{"removed", ITerm2, []Option{Link("https://example.com/a.png"), Link("")},
+			"\x1b]1337;File=size=4:dGVzdA==\a\n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := &Encoder{out: buf, options: append([]Option{Passthrough(false)}, tt.options...), protocol: tt.protocol}
+			if err := enc.Encode(strings.NewReader("test")); err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if got := buf.String(); got != tt.out {
+				t.Fatalf("expected %q; got %q", tt.out, got)
+			}
+		})
+	}
+
+	enc := &Encoder{out: new(bytes.Buffer), options: []Option{Link("https://example.com/\x1b\\")}}
+	if err := enc.Encode(strings.NewReader("test")); !errors.Is(err, ErrOption) {
+		t.Fatalf("expected ErrOption; got %v", err)
+	}
+}
+
+func TestLinkToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a b.png")
+	if err := ioutil.WriteFile(path, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c := configFor(f, []Option{LinkToFile()})
+	host, _ := os.Hostname()
+	if want := "file://" + host + filepath.ToSlash(dir) + "/a%20b.png"; c.link != want {
+		t.Fatalf("expected link %q; got %q", want, c.link)
+	}
+	if c := configFor(strings.NewReader("test"), []Option{LinkToFile()}); c.link != "" {
+		t.Fatalf("expected no link; got %q", c.link)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Colors of the checkerboard drawn behind transparent pixels, and outside the
+// image, by Loupe.
+var (
+	loupeLight = color.RGBA{0x66, 0x66, 0x66, 0xff}
+	loupeDark  = color.RGBA{0x44, 0x44, 0x44, 0xff}
+)
+
+// Loupe returns the pixels of img within radius of p, magnified scale times,
+// with the one at p outlined. The result is 2*radius+1 pixels of img wide and
+// tall. Transparent pixels, and those outside the image, show a checkerboard.
+// This is useful to check the exact output of renderers and shaders.
+func Loupe(img image.Image, p image.Point, radius, scale int) *image.RGBA {
+	if radius < 0 {
+		radius = 0
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	side := (2*radius + 1) * scale
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+
+	// The checkerboard has two squares per pixel, so transparency can be
+	// told apart from gray pixels.
+	square := scale / 2
+	if square < 1 {
+		square = 1
+	}
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			c := loupeLight
+			if (x/square+y/square)%2 == 1 {
+				c = loupeDark
+			}
+			dst.SetRGBA(x, y, c)
+		}
+	}
+
+	b := img.Bounds()
+	for j := -radius; j <= radius; j++ {
+		for i := -radius; i <= radius; i++ {
+			q := p.Add(image.Pt(i, j))
+			if !q.In(b) {
+				continue
+			}
+			at := image.Pt(i+radius, j+radius).Mul(scale)
+			r := image.Rectangle{at, at.Add(image.Pt(scale, scale))}
+			draw.Draw(dst, r, image.NewUniform(img.At(q.X, q.Y)), image.Point{}, draw.Over)
+		}
+	}
+
+	// Outline the pixel at p, inside its square so it's still visible.
+	t := scale / 8
+	if t < 1 {
+		t = 1
+	}
+	at := image.Pt(radius, radius).Mul(scale)
+	r := image.Rectangle{at, at.Add(image.Pt(scale, scale))}
+	c := ContrastColor(dst.At(at.X+scale/2, at.Y+scale/2))
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t), c)
+	fill(dst, image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y), c)
+	fill(dst, image.Rect(r.Min.X, r.Min.Y, r.Min.X+t, r.Max.Y), c)
+	fill(dst, image.Rect(r.Max.X-t, r.Min.Y, r.Max.X, r.Max.Y), c)
+	return dst
+}
+
+// Hex returns c as a #rrggbbaa string, without alpha premultiplication.
+func Hex(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
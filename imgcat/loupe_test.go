@@ -0,0 +1,58 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestLoupe(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	img := image.NewRGBA(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetRGBA(x, y, red)
+		}
+	}
+	img.SetRGBA(2, 2, blue)
+
+	// Three pixels wide, centered on the bottom right corner.
+	l := Loupe(img, image.Pt(2, 2), 1, 10)
+	if l.Bounds() != image.Rect(0, 0, 30, 30) {
+		t.Fatalf("expected 30x30 loupe; got %v", l.Bounds())
+	}
+	tc := []struct {
+		name string
+		x, y int
+		c    color.RGBA
+	}{
+		{"red neighbour", 5, 5, red},
+		{"magnified center", 15, 15, blue},
+		{"outlined center", 10, 15, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{"checkerboard outside", 25, 25, loupeLight},
+		{"checkerboard outside", 29, 21, loupeDark},
+	}
+	for _, tt := range tc {
+		if got := l.RGBAAt(tt.x, tt.y); got != tt.c {
+			t.Errorf("%s: expected %v at %d,%d; got %v", tt.name, tt.c, tt.x, tt.y, got)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	tc := []struct {
+		c   color.Color
+		hex string
+	}{
+		{color.Black, "#000000ff"},
+		{color.RGBA{0xff, 0x80, 0, 0xff}, "#ff8000ff"},
+		{color.RGBA{0x40, 0, 0, 0x80}, "#7f000080"},
+		{color.Transparent, "#00000000"},
+	}
+	for _, tt := range tc {
+		if got := Hex(tt.c); got != tt.hex {
+			t.Errorf("expected %s for %v; got %s", tt.hex, tt.c, got)
+		}
+	}
+}
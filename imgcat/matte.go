@@ -0,0 +1,62 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Matte composites transparent images over c, which should be opaque, so they
+// look the same regardless of the background they are displayed on.
+// Opaque images are sent unchanged.
+func Matte(c color.Color) Option {
+	return transform(func(img image.Image) (image.Image, error) { return matte(img, c), nil })
+}
+
+// MatteBackground is like Matte, using the terminal background color as
+// reported by Background. If the terminal doesn't report it, images are sent
+// unchanged.
+func MatteBackground() Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		if isOpaque(img) {
+			return img, nil
+		}
+		bg, err := Background()
+		if err != nil {
+			return img, nil
+		}
+		return matte(img, bg), nil
+	})
+}
+
+func matte(img image.Image, c color.Color) image.Image {
+	if isOpaque(img) {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(c), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
+
+// isOpaque reports whether img is known to be fully opaque.
+func isOpaque(img image.Image) bool {
+	o, ok := img.(interface {
+		Opaque() bool
+	})
+	return ok && o.Opaque()
+}
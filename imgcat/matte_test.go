@@ -0,0 +1,85 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decode decodes the image read from r, failing the test on error.
+func decode(t *testing.T, r io.Reader) image.Image {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		t.Fatalf("could not decode output: %v", err)
+	}
+	return img
+}
+
+func TestMatte(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	r, err := newConfig([]Option{Matte(red)}).prepare(bytes.NewReader(testPNG(t, 2, 2, color.Transparent)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := color.RGBAModel.Convert(decode(t, r).At(1, 1)); got != red {
+		t.Fatalf("expected %v; got %v", red, got)
+	}
+}
+
+func TestMatteOpaque(t *testing.T) {
+	in := testPNG(t, 2, 2, color.White)
+	r, err := newConfig([]Option{Matte(color.Black)}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if !bytes.Equal(b, in) {
+		t.Fatalf("opaque image should be sent unchanged")
+	}
+}
+
+func TestMatteBackground(t *testing.T) {
+	defer func(old func(string, time.Duration, func([]byte) bool) ([]byte, error)) { queryTerminal = old }(queryTerminal)
+	in := testPNG(t, 2, 2, color.Transparent)
+
+	queryTerminal = func(string, time.Duration, func([]byte) bool) ([]byte, error) {
+		return []byte("\x1b]11;rgb:0000/ffff/0000\a"), nil
+	}
+	r, err := newConfig([]Option{MatteBackground()}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := color.RGBAModel.Convert(decode(t, r).At(0, 0)), (color.RGBA{0, 0xff, 0, 0xff}); got != want {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+
+	queryTerminal = func(string, time.Duration, func([]byte) bool) ([]byte, error) {
+		return nil, errors.New("no reply")
+	}
+	r, err = newConfig([]Option{MatteBackground()}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := ioutil.ReadAll(r); !bytes.Equal(b, in) {
+		t.Fatalf("image should be sent unchanged when the background is unknown")
+	}
+}
+
+func TestTransformUnknownFormat(t *testing.T) {
+	r, err := newConfig([]Option{Matte(color.Black)}).prepare(strings.NewReader("test"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := ioutil.ReadAll(r); string(b) != "test" {
+		t.Fatalf("expected payload %q; got %q", "test", b)
+	}
+}
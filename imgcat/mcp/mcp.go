@@ -0,0 +1,338 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mcp lets coding agents and chat bots running in a terminal show
+// images, such as the plots they made or the screenshots they took, to the
+// user of the terminal.
+//
+// Server is a tool server of the Model Context Protocol, speaking JSON-RPC
+// 2.0 with messages on lines of its standard input and output. It has a
+// single tool, display_image, also callable as a method of the same name,
+// taking the image from a file, a URL, or base64 data:
+//
+//	{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {
+//		"name": "display_image", "arguments": {"path": "plot.png", "width": "50%"}}}
+//
+// See https://modelcontextprotocol.io for the protocol.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+// ProtocolVersion is the version of the Model Context Protocol spoken, given
+// to clients asking for any other.
+const ProtocolVersion = "2024-11-05"
+
+// DefaultMaxBytes is the size of the largest image displayed by default.
+const DefaultMaxBytes = 32 << 20
+
+// ToolName is the name of the tool displaying images.
+const ToolName = "display_image"
+
+// An Image to display.
+type Image struct {
+	// Data of the image, in any format the terminal displays.
+	Data []byte
+	// Name of the image, such as plot.png.
+	Name string
+	// Width and Height the image is displayed with, if not empty.
+	Width, Height imgcat.Length
+}
+
+// A Server serves requests to display images.
+type Server struct {
+	// Display shows an image to the user, such as by sending it to a
+	// display server or writing it to the terminal. It's called an image at
+	// a time.
+	Display func(img Image) error
+	// Open opens the images given with a URL, if not nil.
+	Open func(url string) (io.ReadCloser, error)
+	// Name and Version of the server, given to clients.
+	Name, Version string
+	// MaxBytes is the size of the largest image, DefaultMaxBytes if zero.
+	MaxBytes int64
+}
+
+// Serve reads requests from r, one per line, and writes responses to w, one
+// per line, until r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if res := s.handle(line); res != nil {
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// JSON-RPC error codes.
+const (
+	codeParse          = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// handle returns the response to the request in line, or nil for
+// notifications.
+func (s *Server) handle(line []byte) *response {
+	if line[0] == '[' {
+		return errorResponse(json.RawMessage("null"), codeInvalidRequest, "batches of requests are not supported")
+	}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParse, "could not parse request: "+err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return errorResponse(id, codeInvalidRequest, "not a JSON-RPC 2.0 request")
+	}
+	// Notifications, such as notifications/initialized, have no id and get
+	// no response.
+	if req.ID == nil {
+		return nil
+	}
+
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		result = s.initialize()
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = map[string]interface{}{"tools": []interface{}{tool}}
+	case "tools/call":
+		var call struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &call); err != nil || call.Name == "" {
+			return errorResponse(req.ID, codeInvalidParams, "expected the name and arguments of a tool")
+		}
+		if call.Name != ToolName {
+			return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf("unknown tool %q", call.Name))
+		}
+		result = s.call(call.Arguments)
+	case ToolName:
+		result = s.call(req.Params)
+	default:
+		return errorResponse(req.ID, codeMethodNotFound, fmt.Sprintf("unknown method %q", req.Method))
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{code, msg}}
+}
+
+// initialize returns the result of the initialize request.
+func (s *Server) initialize() interface{} {
+	name, version := s.Name, s.Version
+	if name == "" {
+		name = "imgcat"
+	}
+	if version == "" {
+		version = "1"
+	}
+	return map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{"tools": struct{}{}},
+		"serverInfo":      map[string]string{"name": name, "version": version},
+	}
+}
+
+// tool describes the display_image tool.
+var tool = map[string]interface{}{
+	"name": ToolName,
+	"description": "Display an image, such as a plot or a screenshot, to the user in their terminal. " +
+		"Give exactly one of path, url, or data.",
+	"inputSchema": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path":   map[string]string{"type": "string", "description": "path of an image file"},
+			"url":    map[string]string{"type": "string", "description": "URL of an image"},
+			"data":   map[string]string{"type": "string", "description": "image encoded with base64"},
+			"name":   map[string]string{"type": "string", "description": "name of the image, such as plot.png"},
+			"width":  map[string]string{"type": "string", "description": "width in cells such as 40, pixels such as 400px, or a percentage of the terminal such as 50%"},
+			"height": map[string]string{"type": "string", "description": "height in cells, pixels, or a percentage of the terminal"},
+		},
+	},
+}
+
+// arguments of display_image.
+type arguments struct {
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	Data   string `json:"data"`
+	Name   string `json:"name"`
+	Width  string `json:"width"`
+	Height string `json:"height"`
+}
+
+// call calls display_image, and returns the result of the tool: a line
+// describing the image displayed, or the error, which agents read.
+func (s *Server) call(params json.RawMessage) interface{} {
+	text, err := s.displayImage(params)
+	if err != nil {
+		text = err.Error()
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": err != nil,
+	}
+}
+
+func (s *Server) displayImage(params json.RawMessage) (string, error) {
+	var args arguments
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %v", err)
+		}
+	}
+	img := Image{Name: args.Name}
+	for _, dim := range []struct {
+		value string
+		l     *imgcat.Length
+	}{{args.Width, &img.Width}, {args.Height, &img.Height}} {
+		if dim.value == "" {
+			continue
+		}
+		l, err := imgcat.ParseLength(dim.value)
+		if err != nil {
+			return "", err
+		}
+		*dim.l = l
+	}
+
+	given := 0
+	for _, v := range []string{args.Path, args.URL, args.Data} {
+		if v != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return "", errors.New("give exactly one of path, url, or data")
+	}
+
+	var err error
+	switch {
+	case args.Path != "":
+		img.Data, err = s.read(args.Path, func() (io.ReadCloser, error) { return os.Open(args.Path) })
+		if img.Name == "" {
+			img.Name = filepath.Base(args.Path)
+		}
+	case args.URL != "":
+		if s.Open == nil {
+			return "", errors.New("images can't be given with URLs")
+		}
+		img.Data, err = s.read(args.URL, func() (io.ReadCloser, error) { return s.Open(args.URL) })
+		if img.Name == "" {
+			img.Name = path.Base(strings.SplitN(args.URL, "?", 2)[0])
+		}
+	default:
+		img.Data, err = base64.StdEncoding.DecodeString(args.Data)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 data: %v", err)
+		}
+		if int64(len(img.Data)) > s.maxBytes() {
+			return "", fmt.Errorf("image larger than %d bytes", s.maxBytes())
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	desc := fmt.Sprintf("%d bytes", len(img.Data))
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(img.Data)); err == nil {
+		desc = fmt.Sprintf("%dx%d %s", cfg.Width, cfg.Height, strings.ToUpper(format))
+	}
+	if s.Display == nil {
+		return "", errors.New("no terminal to display images in")
+	}
+	if err := s.Display(img); err != nil {
+		return "", fmt.Errorf("could not display image: %v", err)
+	}
+	if img.Name != "" {
+		return fmt.Sprintf("Displayed %s (%s) to the user.", img.Name, desc), nil
+	}
+	return fmt.Sprintf("Displayed the image (%s) to the user.", desc), nil
+}
+
+// read reads the image opened by open, of at most MaxBytes.
+func (s *Server) read(what string, open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", what, err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rc, s.maxBytes()+1))
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", what, err)
+	}
+	if int64(len(data)) > s.maxBytes() {
+		return nil, fmt.Errorf("%s is larger than %d bytes", what, s.maxBytes())
+	}
+	return data, nil
+}
+
+func (s *Server) maxBytes() int64 {
+	if s.MaxBytes > 0 {
+		return s.MaxBytes
+	}
+	return DefaultMaxBytes
+}
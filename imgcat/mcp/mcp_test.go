@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.Black)
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// serve sends the requests to s, one per line, and returns the responses.
+func serve(t *testing.T, s *Server, requests ...string) []map[string]interface{} {
+	out := new(bytes.Buffer)
+	if err := s.Serve(strings.NewReader(strings.Join(requests, "\n")), out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var responses []map[string]interface{}
+	dec := json.NewDecoder(out)
+	for {
+		var res map[string]interface{}
+		if err := dec.Decode(&res); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("could not decode response: %v", err)
+		}
+		responses = append(responses, res)
+	}
+	return responses
+}
+
+func TestSession(t *testing.T) {
+	s := &Server{Display: func(Image) error { return nil }, Name: "test", Version: "1.2"}
+	res := serve(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"agent"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":"two","method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	if len(res) != 3 {
+		t.Fatalf("got %d responses, expected 3: %v", len(res), res)
+	}
+	for i, id := range []interface{}{1.0, "two", 3.0} {
+		if res[i]["id"] != id || res[i]["jsonrpc"] != "2.0" || res[i]["error"] != nil {
+			t.Errorf("response %d is %v, expected a result with id %v", i, res[i], id)
+		}
+	}
+	init := res[0]["result"].(map[string]interface{})
+	if init["protocolVersion"] != ProtocolVersion {
+		t.Errorf("protocolVersion is %v, expected %s", init["protocolVersion"], ProtocolVersion)
+	}
+	if info := init["serverInfo"].(map[string]interface{}); info["name"] != "test" || info["version"] != "1.2" {
+		t.Errorf("serverInfo is %v", info)
+	}
+	tools := res[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 1 || tools[0].(map[string]interface{})["name"] != ToolName {
+		t.Errorf("tools are %v, expected %s", tools, ToolName)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tc := []struct {
+		name    string
+		request string
+		code    float64
+	}{
+		{"not JSON", `{"jsonrpc":`, codeParse},
+		{"no version", `{"id":1,"method":"ping"}`, codeInvalidRequest},
+		{"batch", `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, codeInvalidRequest},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`, codeMethodNotFound},
+		{"unknown tool", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"rm"}}`, codeInvalidParams},
+		{"no tool", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{}}`, codeInvalidParams},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			res := serve(t, &Server{}, c.request)
+			if len(res) != 1 {
+				t.Fatalf("got %d responses, expected 1", len(res))
+			}
+			e, ok := res[0]["error"].(map[string]interface{})
+			if !ok || e["code"] != c.code {
+				t.Errorf("got %v, expected error code %v", res[0], c.code)
+			}
+		})
+	}
+}
+
+func TestDisplayImage(t *testing.T) {
+	data := testPNG(t, 4, 3)
+	dir, err := ioutil.TempDir("", "mcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plot.png")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	open := func(url string) (io.ReadCloser, error) {
+		if url != "https://example.com/img/shot.png?v=2" {
+			return nil, errors.New("404 Not Found")
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	b64 := base64.StdEncoding.EncodeToString(data)
+
+	tc := []struct {
+		name    string
+		method  string
+		args    string
+		text    string
+		isError bool
+		shown   Image
+	}{
+		{"path", "tools/call", `{"path":"` + path + `","width":"50%"}`,
+			"Displayed plot.png (4x3 PNG) to the user.", false,
+			Image{Name: "plot.png", Width: imgcat.Percent(50)}},
+		{"url", "tools/call", `{"url":"https://example.com/img/shot.png?v=2","height":"10"}`,
+			"Displayed shot.png (4x3 PNG) to the user.", false,
+			Image{Name: "shot.png", Height: imgcat.Cells(10)}},
+		{"data", "tools/call", `{"data":"` + b64 + `"}`,
+			"Displayed the image (4x3 PNG) to the user.", false, Image{}},
+		{"method", ToolName, `{"data":"` + b64 + `","name":"chart","width":"200px"}`,
+			"Displayed chart (4x3 PNG) to the user.", false,
+			Image{Name: "chart", Width: imgcat.Pixels(200)}},
+		{"not an image", "tools/call", `{"data":"aGVsbG8="}`,
+			"Displayed the image (5 bytes) to the user.", false, Image{}},
+		{"nothing", "tools/call", `{}`, "give exactly one of path, url, or data", true, Image{}},
+		{"two", "tools/call", `{"path":"a.png","data":"aGVsbG8="}`, "give exactly one of path, url, or data", true, Image{}},
+		{"missing file", "tools/call", `{"path":"` + filepath.Join(dir, "nope.png") + `"}`, "could not open", true, Image{}},
+		{"missing url", "tools/call", `{"url":"https://example.com/nope.png"}`, "404 Not Found", true, Image{}},
+		{"bad base64", "tools/call", `{"data":"!!"}`, "invalid base64 data", true, Image{}},
+		{"bad width", "tools/call", `{"data":"` + b64 + `","width":"wide"}`, "wide", true, Image{}},
+		{"bad arguments", "tools/call", `[1]`, "invalid arguments", true, Image{}},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			var shown []Image
+			s := &Server{Display: func(img Image) error { shown = append(shown, img); return nil }, Open: open}
+			params := `{"name":"` + ToolName + `","arguments":` + c.args + `}`
+			if c.method == ToolName {
+				params = c.args
+			}
+			res := serve(t, s, `{"jsonrpc":"2.0","id":7,"method":"`+c.method+`","params":`+params+`}`)
+			if len(res) != 1 || res[0]["error"] != nil {
+				t.Fatalf("got %v, expected a result", res)
+			}
+			result := res[0]["result"].(map[string]interface{})
+			text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+			if !strings.Contains(text, c.text) || result["isError"] != c.isError {
+				t.Errorf("got %q with isError %v, expected %q with %v", text, result["isError"], c.text, c.isError)
+			}
+			if c.isError {
+				if len(shown) != 0 {
+					t.Errorf("%d images displayed after an error", len(shown))
+				}
+				return
+			}
+			if len(shown) != 1 {
+				t.Fatalf("%d images displayed, expected 1", len(shown))
+			}
+			got := shown[0]
+			if got.Name != c.shown.Name || got.Width != c.shown.Width || got.Height != c.shown.Height {
+				t.Errorf("displayed %+v, expected %+v", Image{Name: got.Name, Width: got.Width, Height: got.Height}, c.shown)
+			}
+		})
+	}
+}
+
+func TestDisplayFails(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString(testPNG(t, 2, 2))
+	tc := []struct {
+		name   string
+		server *Server
+		text   string
+	}{
+		{"no display", &Server{}, "no terminal"},
+		{"display fails", &Server{Display: func(Image) error { return errors.New("broken pipe") }}, "could not display image: broken pipe"},
+		{"too large", &Server{Display: func(Image) error { return nil }, MaxBytes: 10}, "larger than 10 bytes"},
+		{"no urls", &Server{Display: func(Image) error { return nil }}, "URLs"},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			args := `{"data":"` + b64 + `"}`
+			if c.name == "no urls" {
+				args = `{"url":"https://example.com/a.png"}`
+			}
+			res := serve(t, c.server, `{"jsonrpc":"2.0","id":1,"method":"display_image","params":`+args+`}`)
+			result := res[0]["result"].(map[string]interface{})
+			text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+			if !strings.Contains(text, c.text) || result["isError"] != true {
+				t.Errorf("got %q, expected an error containing %q", text, c.text)
+			}
+		})
+	}
+}
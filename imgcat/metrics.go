@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Metrics counts the images sent by Encoders, the bytes they wrote, and the
+// time it took, so long running programs such as TUIs and servers can monitor
+// the terminal bandwidth used by images. A Metrics can be shared by several
+// Encoders, and is safe for concurrent use.
+//
+// It's an expvar.Var, published with expvar.Publish("imgcat", m), and serves
+// the Prometheus text format over HTTP, see WritePrometheus.
+type Metrics struct {
+	mu sync.Mutex
+	s  MetricsSnapshot
+}
+
+// A MetricsSnapshot holds the values of Metrics at some point.
+type MetricsSnapshot struct {
+	// Images is the number of calls to Encode, and Errors the number of them
+	// that failed.
+	Images, Errors int64
+	// InputBytes, PayloadBytes, and SequenceBytes add up the Input, Payload,
+	// and Sequence of every Event.
+	InputBytes, PayloadBytes, SequenceBytes int64
+	// Duration adds up the time spent in Encode.
+	Duration time.Duration
+}
+
+// Measure adds every image sent to m.
+func Measure(m *Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// add counts the image e describes, if m is not nil.
+func (m *Metrics) add(e Event) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.s.Images++
+	if e.Err != nil {
+		m.s.Errors++
+	}
+	m.s.InputBytes += e.Input
+	m.s.PayloadBytes += e.Payload
+	m.s.SequenceBytes += e.Sequence
+	m.s.Duration += e.Duration
+}
+
+// Snapshot returns the current values of m.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.s
+}
+
+// String returns the values of m as a JSON object, as expvar.Var requires,
+// with the duration in seconds.
+func (m *Metrics) String() string {
+	s := m.Snapshot()
+	b, _ := json.Marshal(map[string]interface{}{
+		"images":         s.Images,
+		"errors":         s.Errors,
+		"input_bytes":    s.InputBytes,
+		"payload_bytes":  s.PayloadBytes,
+		"sequence_bytes": s.SequenceBytes,
+		"seconds":        s.Duration.Seconds(),
+	})
+	return string(b)
+}
+
+// WritePrometheus writes the values of m to w as counters in the Prometheus
+// text format, named imgcat_images_total and so on.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	s := m.Snapshot()
+	counters := []struct {
+		name, help string
+		value      interface{}
+	}{
+		{"images_total", "Images sent.", s.Images},
+		{"errors_total", "Images that failed to be sent.", s.Errors},
+		{"input_bytes_total", "Bytes of the images read.", s.InputBytes},
+		{"payload_bytes_total", "Bytes of the images sent, once transformed or re-encoded.", s.PayloadBytes},
+		{"sequence_bytes_total", "Bytes written to the terminal, escape sequences included.", s.SequenceBytes},
+		{"encode_seconds_total", "Time spent sending images.", s.Duration.Seconds()},
+	}
+	for _, c := range counters {
+		if _, err := fmt.Fprintf(w, "# HELP imgcat_%s %s\n# TYPE imgcat_%s counter\nimgcat_%s %v\n", c.name, c.help, c.name, c.name, c.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP writes the values of m in the Prometheus text format, so m can be
+// scraped when registered as a handler, such as on /metrics.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	// The client went away if this fails, there's nobody to tell.
+	_ = m.WritePrometheus(w)
+}
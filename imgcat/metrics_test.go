@@ -0,0 +1,102 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var _ expvar.Var = new(Metrics)
+
+func TestMetrics(t *testing.T) {
+	m := new(Metrics)
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Passthrough(false), Measure(m)}}
+	for i := 0; i < 2; i++ {
+		if err := enc.Encode(strings.NewReader("test")); err != nil {
+			t.Fatalf("could not encode: %v", err)
+		}
+	}
+	enc = &Encoder{out: badWriter{}, options: []Option{Measure(m)}}
+	if err := enc.Encode(strings.NewReader("test")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+
+	s := m.Snapshot()
+	// The failed image may have been read, or not.
+	if s.Images != 3 || s.Errors != 1 || s.InputBytes < 8 || s.PayloadBytes < 8 || s.SequenceBytes != int64(buf.Len()) || s.Duration <= 0 {
+		t.Fatalf("unexpected metrics %+v", s)
+	}
+
+	var values map[string]float64
+	if err := json.Unmarshal([]byte(m.String()), &values); err != nil {
+		t.Fatalf("could not parse %s: %v", m, err)
+	}
+	if values["images"] != 3 || values["errors"] != 1 || values["sequence_bytes"] != float64(buf.Len()) {
+		t.Fatalf("unexpected values %v", values)
+	}
+}
+
+func TestMetricsWithTracer(t *testing.T) {
+	m := new(Metrics)
+	traced := 0
+	tracer := TracerFunc(func(Event) { traced++ })
+	enc := &Encoder{out: new(bytes.Buffer), options: []Option{Trace(tracer), Measure(m)}}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if traced != 1 || m.Snapshot().Images != 1 {
+		t.Fatalf("expected the image traced and counted; got %d traces and %+v", traced, m.Snapshot())
+	}
+}
+
+func TestMetricsConcurrent(t *testing.T) {
+	m := new(Metrics)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enc := &Encoder{out: new(bytes.Buffer), options: []Option{Measure(m)}}
+			for j := 0; j < 10; j++ {
+				if err := enc.Encode(strings.NewReader("test")); err != nil {
+					t.Errorf("could not encode: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := m.Snapshot().Images; n != 80 {
+		t.Fatalf("expected 80 images; got %d", n)
+	}
+}
+
+func TestMetricsPrometheus(t *testing.T) {
+	m := new(Metrics)
+	enc := &Encoder{out: new(bytes.Buffer), options: []Option{Passthrough(false), Measure(m)}}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE imgcat_images_total counter\nimgcat_images_total 1\n",
+		"imgcat_errors_total 0\n",
+		"imgcat_input_bytes_total 4\n",
+		"imgcat_sequence_bytes_total 29\n",
+		"# TYPE imgcat_encode_seconds_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in:\n%s", want, body)
+		}
+	}
+}
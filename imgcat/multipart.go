@@ -0,0 +1,183 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ErrNoTransfer is returned by Resume when there is no transfer to resume.
+var ErrNoTransfer = errors.New("no interrupted transfer")
+
+// Multipart sends the payload in parts of about n bytes, using the multipart
+// file transfers supported by iTerm2 3.5 and later, instead of as a single
+// escape sequence. If a transfer fails, Resume continues it from the last part
+// that was written successfully rather than starting again.
+func Multipart(n int) Option {
+	// Parts are base64 encoded independently, so they must not need padding.
+	n -= n % 3
+	if n < 3 {
+		n = 3
+	}
+	return func(c *config) { c.partSize = n }
+}
+
+// transfer holds the state of a multipart transfer.
+type transfer struct {
+	cfg *config
+	// src is the reader given to Encode, and r the payload prepared from it.
+	src *resumable
+	r   io.Reader
+	// whether the opening sequence has been written.
+	started bool
+	// number of payload bytes written so far, and the part that couldn't be.
+	sent int64
+	part []byte
+	sum  hash.Hash
+}
+
+func newTransfer(cfg *config, src *resumable, r io.Reader) *transfer {
+	t := &transfer{cfg: cfg, src: src, r: r}
+	if cfg.checksum != nil {
+		t.sum = sha256.New()
+	}
+	return t
+}
+
+// A resumable reader reads the payload given to Encode for a multipart
+// transfer, and can be swapped for the reader given to Resume, which is read
+// from where the first one stopped.
+type resumable struct {
+	r io.Reader
+	// n is how many bytes were read, and skip how many of them are still to
+	// be skipped in r since it was swapped.
+	n, skip int64
+}
+
+func (s *resumable) Read(p []byte) (int, error) {
+	if s.skip > 0 {
+		var err error
+		if sk, ok := s.r.(io.Seeker); ok {
+			_, err = sk.Seek(s.skip, io.SeekCurrent)
+		} else {
+			_, err = io.CopyN(ioutil.Discard, s.r, s.skip)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not skip sent parts: %v", err)
+		}
+		s.skip = 0
+	}
+	n, err := s.r.Read(p)
+	s.n += int64(n)
+	return n, err
+}
+
+// swap makes s read from r what's left of the payload.
+func (s *resumable) swap(r io.Reader) { s.r, s.skip = r, s.n }
+
+// Resume continues the multipart transfer interrupted during the last call to
+// Encode. The given reader must provide the same content given to Encode. The
+// payload prepared by Encode is sent from where it stopped, so it's neither
+// checked, transformed, nor taken from a Budget again, and the reader is only
+// read past what was read from the first one, which is all of it for payloads
+// held in memory.
+func (enc *Encoder) Resume(r io.Reader) error {
+	t := enc.pending
+	if t == nil {
+		return ErrNoTransfer
+	}
+	t.src.swap(r)
+	return enc.sendParts()
+}
+
+// sendParts writes the rest of the pending transfer.
+func (enc *Encoder) sendParts() error {
+	t := enc.pending
+	if !t.started {
+		args := strings.Join(t.cfg.args, ";")
+		if _, err := io.WriteString(enc.out, cursorRight(t.cfg.indent)+t.cfg.linkStart()+sequence("MultipartFile="+args, t.cfg.tmux())); err != nil {
+			return err
+		}
+		t.started = true
+	}
+
+	if t.part != nil {
+		if err := enc.sendPart(t.part); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, t.cfg.partSize)
+	for {
+		n, err := io.ReadFull(t.r, buf)
+		if n > 0 {
+			if err := enc.sendPart(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(enc.out, sequence("FileEnd", t.cfg.tmux())+t.cfg.linkEnd()+t.cfg.newline()); err != nil {
+		return err
+	}
+	enc.pending = nil
+	if t.sum != nil {
+		t.cfg.checksum(t.sum.Sum(nil))
+	}
+	return nil
+}
+
+// sendPart writes a part of the pending transfer, which is kept to be written
+// again by Resume if that fails.
+func (enc *Encoder) sendPart(p []byte) error {
+	t := enc.pending
+	part := sequence("FilePart="+base64.StdEncoding.EncodeToString(p), t.cfg.tmux())
+	if _, err := io.WriteString(enc.out, part); err != nil {
+		t.part = append(t.part[:0], p...)
+		return err
+	}
+	t.part = nil
+	t.sent += int64(len(p))
+	if t.sum != nil {
+		// never returns an error according to specs.
+		_, _ = t.sum.Write(p)
+	}
+	return nil
+}
+
+// sequence wraps the given iTerm2 proprietary command in an escape sequence,
+// with tmux passthrough if requested.
+func sequence(cmd string, tmux bool) string {
+	return passthrough("\x1b]1337;"+cmd+"\a", tmux)
+}
+
+// passthrough wraps seq so tmux forwards it to the terminal, if requested.
+func passthrough(seq string, tmux bool) string {
+	if !tmux {
+		return seq
+	}
+	return "\x1bPtmux;" + strings.Replace(seq, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+}
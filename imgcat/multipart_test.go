@@ -0,0 +1,210 @@
+package imgcat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyWriter fails every write after the first n ones.
+type flakyWriter struct {
+	w io.Writer
+	n int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	if f.n == 0 {
+		return 0, fmt.Errorf("connection lost")
+	}
+	f.n--
+	return f.w.Write(p)
+}
+
+func TestMultipart(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	isSupported = func() bool { return true }
+	check(t, os.Setenv("TMUX_TEST", "false"))
+
+	const want = "\x1b]1337;MultipartFile=inline=1;size=8\a" +
+		"\x1b]1337;FilePart=dGVz\a" +
+		"\x1b]1337;FilePart=dGRh\a" +
+		"\x1b]1337;FilePart=dGE=\a" +
+		"\x1b]1337;FileEnd\a\n"
+
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, Inline(true), Multipart(4))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.Encode(strings.NewReader("testdata")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Fatalf("expected output %q; got %q", want, got)
+	}
+
+	for n := 0; n < 5; n++ {
+		t.Run(fmt.Sprintf("interrupted after %d writes", n), func(t *testing.T) {
+			var buf bytes.Buffer
+			var sum []byte
+			enc, err := NewEncoder(&flakyWriter{&buf, n}, Inline(true), Multipart(3),
+				Checksum(func(s []byte) { sum = s }))
+			if err != nil {
+				t.Fatalf("could not create encoder: %v", err)
+			}
+			if err := enc.Encode(strings.NewReader("testdata")); err == nil {
+				t.Fatalf("expected error; got nothing")
+			}
+			enc.out = &buf
+			if err := enc.Resume(strings.NewReader("testdata")); err != nil {
+				t.Fatalf("could not resume: %v", err)
+			}
+			if got := buf.String(); got != want {
+				t.Fatalf("expected output %q; got %q", want, got)
+			}
+			if want := sha256.Sum256([]byte("testdata")); !bytes.Equal(sum, want[:]) {
+				t.Fatalf("expected checksum %x; got %x", want, sum)
+			}
+			if err := enc.Resume(strings.NewReader("testdata")); err != ErrNoTransfer {
+				t.Fatalf("expected %v; got %v", ErrNoTransfer, err)
+			}
+		})
+	}
+}
+
+func TestResumePrepared(t *testing.T) {
+	defer func(old func() time.Time) { now = old }(now)
+	now = func() time.Time { return time.Unix(0, 0) }
+
+	payload := strings.Repeat("testdata", 128)
+	var buf bytes.Buffer
+	// The budget is enough for a single payload, which was taken by Encode.
+	enc := &Encoder{out: &flakyWriter{&buf, 3}, options: []Option{Passthrough(false), Inline(true), Multipart(300),
+		WithinBudget(NewBudget(base64.StdEncoding.EncodedLen(len(payload))))}}
+	if err := enc.Encode(strings.NewReader(payload)); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+	enc.out = &buf
+	// The payload is held in memory, so the reader isn't read again.
+	if err := enc.Resume(badReader{}); err != nil {
+		t.Fatalf("could not resume: %v", err)
+	}
+	cmds := splitSequences(t, strings.TrimSuffix(buf.String(), "\n"), false)
+	var data string
+	for _, cmd := range cmds[1 : len(cmds)-1] {
+		data += strings.TrimPrefix(cmd, "1337;FilePart=")
+	}
+	if got, err := base64.StdEncoding.DecodeString(data); err != nil || string(got) != payload {
+		t.Fatalf("expected the payload sent once; got %q, %v", got, err)
+	}
+}
+
+func FuzzEncode(f *testing.F) {
+	f.Add([]byte("test"), 0, false)
+	f.Add([]byte("testdata"), 4, true)
+	f.Add([]byte("\x1b]1337;File=:\a\x1b\\"), 3, true)
+	f.Fuzz(func(t *testing.T, payload []byte, partSize int, tmux bool) {
+		options := []Option{Inline(true), Passthrough(tmux)}
+		if partSize > 0 {
+			options = append(options, Multipart(partSize%64))
+		}
+		b, err := EncodeToBytes(bytes.NewReader(payload), options...)
+		if err != nil {
+			t.Fatalf("could not encode: %v", err)
+		}
+		cmds := splitSequences(t, strings.TrimSuffix(string(b), "\n"), tmux)
+
+		// Whatever the payload, it's sent in well formed sequences it can be
+		// decoded back from.
+		var data string
+		switch {
+		case len(cmds) == 1 && strings.HasPrefix(cmds[0], "1337;File="):
+			i := strings.Index(cmds[0], ":")
+			if i < 0 {
+				t.Fatalf("no payload in %q", cmds[0])
+			}
+			data = cmds[0][i+1:]
+		case len(cmds) >= 2 && strings.HasPrefix(cmds[0], "1337;MultipartFile=") && cmds[len(cmds)-1] == "1337;FileEnd":
+			for _, cmd := range cmds[1 : len(cmds)-1] {
+				part := strings.TrimPrefix(cmd, "1337;FilePart=")
+				if part == cmd {
+					t.Fatalf("unexpected sequence %q", cmd)
+				}
+				data += part
+			}
+		default:
+			t.Fatalf("unexpected sequences %q", cmds)
+		}
+		got, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			t.Fatalf("could not decode payload: %v", err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("expected payload %q; got %q", payload, got)
+		}
+	})
+}
+
+// splitSequences returns the commands of the OSC sequences s is made of,
+// unwrapping tmux passthrough, and fails if anything else is found, or if any
+// command has control characters.
+func splitSequences(t *testing.T, s string, tmux bool) []string {
+	var cmds []string
+	for len(s) > 0 {
+		seq := s
+		if tmux {
+			if !strings.HasPrefix(s, "\x1bPtmux;") {
+				t.Fatalf("expected tmux passthrough; got %q", s)
+			}
+			// Escape characters are doubled up to the string terminator.
+			end := -1
+			for i := len("\x1bPtmux;"); i+1 < len(s); i++ {
+				if s[i] != '\x1b' {
+					continue
+				}
+				if s[i+1] == '\\' {
+					end = i
+					break
+				}
+				if s[i+1] != '\x1b' {
+					t.Fatalf("lone escape character in %q", s)
+				}
+				i++
+			}
+			if end < 0 {
+				t.Fatalf("unterminated passthrough %q", s)
+			}
+			seq = strings.Replace(s[len("\x1bPtmux;"):end], "\x1b\x1b", "\x1b", -1)
+			s = s[end+2:]
+		}
+		if !strings.HasPrefix(seq, "\x1b]") {
+			t.Fatalf("expected OSC sequence; got %q", seq)
+		}
+		end := strings.IndexByte(seq, '\a')
+		if end < 0 {
+			t.Fatalf("unterminated sequence %q", seq)
+		}
+		cmd := seq[2:end]
+		for i := 0; i < len(cmd); i++ {
+			if cmd[i] < ' ' || cmd[i] > '~' {
+				t.Fatalf("unexpected control character %q in %q", cmd[i], cmd)
+			}
+		}
+		cmds = append(cmds, cmd)
+		if tmux {
+			if end != len(seq)-1 {
+				t.Fatalf("unexpected %q after sequence", seq[end+1:])
+			}
+		} else {
+			s = s[end+1:]
+		}
+	}
+	return cmds
+}
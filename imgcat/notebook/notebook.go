@@ -0,0 +1,84 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notebook lets code displaying images run both in a terminal and in
+// Go notebook kernels for Jupyter, such as gonb.
+//
+// In a terminal, images are written to the standard output as iTerm2 escape
+// sequences. In a notebook, they are returned as a MIME bundle that the kernel
+// can publish as display data.
+package notebook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+// A Bundle maps MIME types to the representations of an image, as expected by
+// Jupyter display_data messages. Binary data is base64 encoded.
+type Bundle map[string]string
+
+// Can be swapped for testing.
+var (
+	inNotebook = func() bool {
+		// Set by Jupyter for every kernel it starts.
+		return os.Getenv("JPY_PARENT_PID") != ""
+	}
+	stdout io.Writer = os.Stdout
+)
+
+// InNotebook reports whether the program is running as a Jupyter kernel.
+func InNotebook() bool { return inNotebook() }
+
+// Show displays img. In a notebook, it returns the bundle to be displayed by
+// the kernel, with PNG, HTML, and text representations. Otherwise, it writes
+// the image to the standard output with the given options and returns nil.
+func Show(img image.Image, options ...imgcat.Option) (Bundle, error) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("could not encode image: %v", err)
+	}
+
+	if !inNotebook() {
+		enc, err := imgcat.NewEncoder(stdout, append([]imgcat.Option{imgcat.Inline(true)}, options...)...)
+		if err != nil {
+			return nil, err
+		}
+		return nil, enc.Encode(buf)
+	}
+
+	b := img.Bounds()
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	return Bundle{
+		"image/png":  data,
+		"text/html":  fmt.Sprintf(`<img src="data:image/png;base64,%s" width="%d" height="%d">`, data, b.Dx(), b.Dy()),
+		"text/plain": fmt.Sprintf("<image %dx%d>", b.Dx(), b.Dy()),
+	}, nil
+}
+
+// DataURI returns the image in the bundle as a data URI, which can be used as
+// the source of an HTML image. It returns an empty string if there's no image.
+func (b Bundle) DataURI() string {
+	data, ok := b["image/png"]
+	if !ok {
+		return ""
+	}
+	return "data:image/png;base64," + data
+}
@@ -0,0 +1,72 @@
+package notebook
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.White)
+	return img
+}
+
+func TestShowNotebook(t *testing.T) {
+	defer func(old func() bool) { inNotebook = old }(inNotebook)
+	inNotebook = func() bool { return true }
+
+	b, err := Show(testImage())
+	if err != nil {
+		t.Fatalf("could not show image: %v", err)
+	}
+	if got, want := b["text/plain"], "<image 3x2>"; got != want {
+		t.Errorf("expected text %q; got %q", want, got)
+	}
+	data, err := base64.StdEncoding.DecodeString(b["image/png"])
+	if err != nil {
+		t.Fatalf("could not decode png data: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not decode png: %v", err)
+	}
+	if got := img.Bounds(); got != image.Rect(0, 0, 3, 2) {
+		t.Errorf("expected bounds 3x2; got %v", got)
+	}
+	if uri := b.DataURI(); !strings.HasPrefix(uri, "data:image/png;base64,") || !strings.Contains(b["text/html"], uri) {
+		t.Errorf("unexpected data uri %q in html %q", uri, b["text/html"])
+	}
+}
+
+func TestShowTerminal(t *testing.T) {
+	defer func(old func() bool) { inNotebook = old }(inNotebook)
+	defer func(old string) { os.Setenv("TERM_PROGRAM", old) }(os.Getenv("TERM_PROGRAM"))
+	defer func() { os.Unsetenv("TMUX_TEST") }()
+	defer func(old io.Writer) { stdout = old }(stdout)
+	inNotebook = func() bool { return false }
+	os.Setenv("TERM_PROGRAM", "iTerm.app")
+	os.Setenv("TMUX_TEST", "false")
+	buf := new(bytes.Buffer)
+	stdout = buf
+
+	b, err := Show(testImage())
+	if err != nil {
+		t.Fatalf("could not show image: %v", err)
+	}
+	if b != nil {
+		t.Errorf("expected no bundle; got %v", b)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "\x1b]1337;File=inline=1;size=") {
+		t.Errorf("expected escape sequence; got %q", out)
+	}
+	if uri := b.DataURI(); uri != "" {
+		t.Errorf("expected no data uri; got %q", uri)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ocr recognizes the text in images, such as screenshots of error
+// messages, so it can be printed along with them.
+//
+// Engines are pluggable through the Engine interface. Tesseract runs the
+// tesseract command, which needs to be installed.
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotInstalled is returned by engines whose program can't be found.
+var ErrNotInstalled = errors.New("ocr engine not installed")
+
+// An Engine recognizes the text in the image read from r.
+type Engine interface {
+	Recognize(r io.Reader) (string, error)
+}
+
+// EngineFunc adapts an ordinary function to the Engine interface.
+type EngineFunc func(r io.Reader) (string, error)
+
+// Recognize calls f(r).
+func (f EngineFunc) Recognize(r io.Reader) (string, error) { return f(r) }
+
+// Tesseract is an Engine running the tesseract command.
+// See https://github.com/tesseract-ocr/tesseract.
+type Tesseract struct {
+	// Path of the tesseract command, defaults to looking it up in PATH.
+	Path string
+	// Languages of the text, such as "eng" or "deu", defaults to what
+	// tesseract picks, usually English.
+	Languages []string
+}
+
+// Recognize runs tesseract on the image read from r and returns the text it
+// found, without trailing spaces or blank lines.
+func (t Tesseract) Recognize(r io.Reader) (string, error) {
+	path := t.Path
+	if path == "" {
+		path = "tesseract"
+	}
+	if _, err := exec.LookPath(path); err != nil {
+		return "", ErrNotInstalled
+	}
+	args := []string{"stdin", "stdout"}
+	if len(t.Languages) > 0 {
+		args = append(args, "-l", strings.Join(t.Languages, "+"))
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = r
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("tesseract failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("tesseract failed: %v", err)
+	}
+	return clean(stdout.String()), nil
+}
+
+// clean removes the trailing spaces of every line of s, the form feed tesseract
+// writes after every page, and blank lines at its start and end.
+func clean(s string) string {
+	lines := strings.Split(strings.Replace(s, "\f", "", -1), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,83 @@
+package ocr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tc := []struct {
+		in, out string
+	}{
+		{"", ""},
+		{"hello\n\f", "hello"},
+		{"\n\npanic: oops  \n\ngoroutine 1 \n\n\f", "panic: oops\n\ngoroutine 1"},
+		{"one\r\ntwo\r\n", "one\ntwo"},
+	}
+	for _, tt := range tc {
+		if got := clean(tt.in); got != tt.out {
+			t.Errorf("clean(%q): expected %q; got %q", tt.in, tt.out, got)
+		}
+	}
+}
+
+// fakeTesseract writes a script standing in for tesseract, which prints its
+// arguments and input.
+func fakeTesseract(t *testing.T, script string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell")
+	}
+	dir, err := ioutil.TempDir("", "ocr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "tesseract")
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTesseract(t *testing.T) {
+	path := fakeTesseract(t, "echo \"$@\"\ncat\nprintf '\\n\\f'\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	tc := []struct {
+		name      string
+		languages []string
+		out       string
+	}{
+		{"default language", nil, "stdin stdout\nimage"},
+		{"languages", []string{"eng", "deu"}, "stdin stdout -l eng+deu\nimage"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Tesseract{Path: path, Languages: tt.languages}.Recognize(strings.NewReader("image"))
+			if err != nil {
+				t.Fatalf("could not recognize: %v", err)
+			}
+			if got != tt.out {
+				t.Errorf("expected %q; got %q", tt.out, got)
+			}
+		})
+	}
+}
+
+func TestTesseractErrors(t *testing.T) {
+	path := fakeTesseract(t, "echo 'Error in pixReadStream' >&2\nexit 1\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	_, err := Tesseract{Path: path}.Recognize(strings.NewReader("not an image"))
+	if err == nil || !strings.Contains(err.Error(), "pixReadStream") {
+		t.Errorf("expected error with tesseract's message; got %v", err)
+	}
+
+	_, err = Tesseract{Path: filepath.Join(filepath.Dir(path), "missing")}.Recognize(strings.NewReader(""))
+	if err != ErrNotInstalled {
+		t.Errorf("expected ErrNotInstalled; got %v", err)
+	}
+}
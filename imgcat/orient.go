@@ -0,0 +1,55 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import "image"
+
+// Rotate90 rotates the image 90 degrees clockwise.
+func Rotate90() Option { return remap(true, func(w, h, x, y int) (int, int) { return y, h - 1 - x }) }
+
+// Rotate180 rotates the image 180 degrees.
+func Rotate180() Option {
+	return remap(false, func(w, h, x, y int) (int, int) { return w - 1 - x, h - 1 - y })
+}
+
+// Rotate270 rotates the image 270 degrees clockwise, or 90 counterclockwise.
+func Rotate270() Option { return remap(true, func(w, h, x, y int) (int, int) { return w - 1 - y, x }) }
+
+// FlipH flips the image horizontally, mirroring it left to right.
+func FlipH() Option { return remap(false, func(w, h, x, y int) (int, int) { return w - 1 - x, y }) }
+
+// FlipV flips the image vertically, mirroring it top to bottom.
+func FlipV() Option { return remap(false, func(w, h, x, y int) (int, int) { return x, h - 1 - y }) }
+
+// remap returns an Option that builds a new image where each pixel at x, y is
+// taken from the coordinates returned by src, given the width and height of the
+// source image. If swap is true the dimensions of the new image are swapped.
+func remap(swap bool, src func(w, h, x, y int) (int, int)) Option {
+	return transform(func(img image.Image) (image.Image, error) {
+		b := img.Bounds()
+		w, h := b.Dx(), b.Dy()
+		dw, dh := w, h
+		if swap {
+			dw, dh = h, w
+		}
+		dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+		for y := 0; y < dh; y++ {
+			for x := 0; x < dw; x++ {
+				sx, sy := src(w, h, x, y)
+				dst.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+			}
+		}
+		return dst, nil
+	})
+}
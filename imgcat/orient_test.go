@@ -0,0 +1,49 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOrientation(t *testing.T) {
+	// A 3x2 image where each pixel has a different red value:
+	//   0 1 2
+	//   3 4 5
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		img.SetNRGBA(i%3, i/3, color.NRGBA{uint8(i), 0, 0, 0xff})
+	}
+
+	tc := []struct {
+		name   string
+		option Option
+		out    [][]uint8
+	}{
+		{"rotate 90", Rotate90(), [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{"rotate 180", Rotate180(), [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{"rotate 270", Rotate270(), [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+		{"flip horizontally", FlipH(), [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{"flip vertically", FlipV(), [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := newConfig([]Option{tt.option}).transforms[0].Apply(img)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b := out.Bounds()
+			if b.Dx() != len(tt.out[0]) || b.Dy() != len(tt.out) {
+				t.Fatalf("expected size %dx%d; got %v", len(tt.out[0]), len(tt.out), b.Size())
+			}
+			for y, row := range tt.out {
+				for x, want := range row {
+					if got := color.NRGBAModel.Convert(out.At(x, y)).(color.NRGBA).R; got != want {
+						t.Errorf("pixel at %d,%d: expected %d; got %d", x, y, want, got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+// Pager displays images in the given number of rows, for output read through
+// a pager such as less -R. Pagers don't know how many rows images cover, so
+// images are drawn without moving the cursor and followed by one newline per
+// row, which keeps the text that follows in the right place while paging.
+// The cursor is left where images are drawn by iTerm2 3.5 and later.
+func Pager(rows int) Option {
+	if rows < 1 {
+		rows = 1
+	}
+	return func(c *config) {
+		Height(Cells(rows))(c)
+		PreserveAspectRatio(true)(c)
+		MoveCursor(false)(c)
+		c.padRows = rows
+	}
+}
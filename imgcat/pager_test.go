@@ -0,0 +1,30 @@
+package imgcat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPager(t *testing.T) {
+	tc := []struct {
+		name    string
+		options []Option
+		out     string
+	}{
+		{"three rows", []Option{Pager(3)}, "\x1b]1337;File=height=3;preserveAspectRatio=1;doNotMoveCursor=1;size=4:dGVzdA==\a\n\n\n"},
+		{"at least a row", []Option{Pager(0)}, "\x1b]1337;File=height=1;preserveAspectRatio=1;doNotMoveCursor=1;size=4:dGVzdA==\a\n"},
+		{"despite newline", []Option{Newline(false), Pager(2)}, "\x1b]1337;File=height=2;preserveAspectRatio=1;doNotMoveCursor=1;size=4:dGVzdA==\a\n\n"},
+		{"multipart", []Option{Pager(2), Multipart(3)}, "\x1b]1337;MultipartFile=height=2;preserveAspectRatio=1;doNotMoveCursor=1;size=4\a\x1b]1337;FilePart=dGVz\a\x1b]1337;FilePart=dA==\a\x1b]1337;FileEnd\a\n\n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := EncodeToBytes(strings.NewReader("test"), append(tt.options, Passthrough(false))...)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if string(b) != tt.out {
+				t.Errorf("expected output %q; got %q", tt.out, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// Settings of the palette extraction.
+const (
+	// paletteSamples is about the largest number of pixels clustered, larger
+	// images are sampled.
+	paletteSamples = 1 << 16
+	// paletteIterations bounds the rounds of k-means.
+	paletteIterations = 20
+)
+
+// Palette returns the n dominant colors of the image read from r, found by
+// k-means clustering of its pixels, from the most to the least common.
+// Transparent pixels are ignored. Fewer colors are returned when the image
+// has fewer distinct ones.
+func Palette(r io.Reader, n int) ([]color.Color, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("palette needs at least one color, got %d", n)
+	}
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %v", err)
+	}
+	clusters := kmeans(samples(img), n)
+	colors := make([]color.Color, len(clusters))
+	for i, c := range clusters {
+		colors[i] = c.color()
+	}
+	return colors, nil
+}
+
+// An rgbPoint is a color in RGB space.
+type rgbPoint [3]float64
+
+func (p rgbPoint) dist(q rgbPoint) float64 {
+	d0, d1, d2 := p[0]-q[0], p[1]-q[1], p[2]-q[2]
+	return d0*d0 + d1*d1 + d2*d2
+}
+
+// samples returns the colors of the non transparent pixels of img, sampling a
+// grid of them in large images.
+func samples(img image.Image) []rgbPoint {
+	b := img.Bounds()
+	step := 1
+	for b.Dx()/step*(b.Dy()/step) > paletteSamples {
+		step++
+	}
+	var ps []rgbPoint
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				continue
+			}
+			ps = append(ps, rgbPoint{float64(c.R), float64(c.G), float64(c.B)})
+		}
+	}
+	return ps
+}
+
+type cluster struct {
+	center rgbPoint
+	size   int
+}
+
+func (c cluster) color() color.Color {
+	round := func(v float64) uint8 { return uint8(v + 0.5) }
+	return color.RGBA{round(c.center[0]), round(c.center[1]), round(c.center[2]), 0xff}
+}
+
+// kmeans groups ps in at most k clusters, sorted by decreasing size.
+// The centers start on the points farthest from the ones picked before, so
+// the result doesn't depend on chance.
+func kmeans(ps []rgbPoint, k int) []cluster {
+	if len(ps) == 0 {
+		return nil
+	}
+	centers := []rgbPoint{ps[0]}
+	nearest := make([]float64, len(ps))
+	for i, p := range ps {
+		nearest[i] = p.dist(ps[0])
+	}
+	for len(centers) < k {
+		far := 0
+		for i := range ps {
+			if nearest[i] > nearest[far] {
+				far = i
+			}
+		}
+		if nearest[far] == 0 {
+			// All points are on a center already.
+			break
+		}
+		centers = append(centers, ps[far])
+		for i, p := range ps {
+			if d := p.dist(ps[far]); d < nearest[i] {
+				nearest[i] = d
+			}
+		}
+	}
+
+	assign := make([]int, len(ps))
+	sizes := make([]int, len(centers))
+	for iter := 0; iter < paletteIterations; iter++ {
+		changed := false
+		for i, p := range ps {
+			best := 0
+			for j, c := range centers {
+				if p.dist(c) < p.dist(centers[best]) {
+					best = j
+				}
+			}
+			if iter == 0 || assign[i] != best {
+				assign[i], changed = best, true
+			}
+		}
+		if !changed {
+			break
+		}
+		sums := make([]rgbPoint, len(centers))
+		for j := range sizes {
+			sizes[j] = 0
+		}
+		for i, p := range ps {
+			j := assign[i]
+			sizes[j]++
+			for d := range p {
+				sums[j][d] += p[d]
+			}
+		}
+		for j := range centers {
+			if sizes[j] == 0 {
+				continue
+			}
+			for d := range sums[j] {
+				centers[j][d] = sums[j][d] / float64(sizes[j])
+			}
+		}
+	}
+
+	var clusters []cluster
+	for j, c := range centers {
+		if sizes[j] > 0 {
+			clusters = append(clusters, cluster{c, sizes[j]})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].size > clusters[j].size })
+	return clusters
+}
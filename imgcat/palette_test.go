@@ -0,0 +1,75 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPalette(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	green := color.RGBA{0, 0x80, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	// Half red, then green, then blue, and a transparent column.
+	img := image.NewRGBA(image.Rect(0, 0, 11, 10))
+	draw.Draw(img, image.Rect(0, 0, 10, 5), image.NewUniform(red), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 5, 10, 8), image.NewUniform(green), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 8, 10, 10), image.NewUniform(blue), image.Point{}, draw.Src)
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		n    int
+		want []color.Color
+	}{
+		{1, []color.Color{color.RGBA{0x80, 0x26, 0x33, 0xff}}},
+		{3, []color.Color{red, green, blue}},
+		{5, []color.Color{red, green, blue}},
+	}
+	for _, tt := range tc {
+		got, err := Palette(bytes.NewReader(buf.Bytes()), tt.n)
+		if err != nil {
+			t.Fatalf("could not extract palette: %v", err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("%d colors: expected %v; got %v", tt.n, tt.want, got)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%d colors: expected %v; got %v", tt.n, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestPaletteErrors(t *testing.T) {
+	if _, err := Palette(bytes.NewReader(testPNG(t, 2, 2, color.White)), 0); err == nil {
+		t.Errorf("expected error for no colors; got nothing")
+	}
+	if _, err := Palette(strings.NewReader("not an image"), 3); err == nil {
+		t.Errorf("expected decoding error; got nothing")
+	}
+	got, err := Palette(bytes.NewReader(testPNG(t, 2, 2, color.Transparent)), 3)
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected no colors in transparent image; got %v, %v", got, err)
+	}
+}
+
+func TestKmeans(t *testing.T) {
+	// Two groups of nearby colors are averaged.
+	ps := []rgbPoint{{0, 0, 0}, {10, 10, 10}, {0, 0, 10}, {250, 250, 250}, {240, 240, 240}}
+	cs := kmeans(ps, 2)
+	if len(cs) != 2 || cs[0].size != 3 || cs[1].size != 2 {
+		t.Fatalf("expected clusters of 3 and 2; got %v", cs)
+	}
+	if want := (rgbPoint{245, 245, 245}); cs[1].center != want {
+		t.Errorf("expected center %v; got %v", want, cs[1].center)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"errors"
+
+	"github.com/campoy/tools/imgcat/termsize"
+)
+
+// Can be swapped for testing.
+var paneSize = func() (termsize.Size, error) {
+	if !IsTmux() {
+		return termsize.Size{}, errors.New("not in tmux")
+	}
+	return termsize.Pane()
+}
+
+// PaneWidth sets the width to x percent of the current tmux pane, in cells.
+// Percent is relative to the whole iTerm2 session, which is wrong inside panes.
+// Outside of tmux, or if the pane size is unknown, it behaves as
+// Width(Percent(x)). The pane is measured every time an image is encoded.
+func PaneWidth(x int) Option {
+	return func(c *config) { Width(panePercent(x, func(s termsize.Size) int { return s.Cols }))(c) }
+}
+
+// PaneHeight sets the height to x percent of the current tmux pane, in cells.
+// See PaneWidth for more details.
+func PaneHeight(x int) Option {
+	return func(c *config) { Height(panePercent(x, func(s termsize.Size) int { return s.Rows }))(c) }
+}
+
+func panePercent(x int, dim func(termsize.Size) int) Length {
+	s, err := paneSize()
+	if err != nil {
+		return Percent(x)
+	}
+	cells := dim(s) * x / 100
+	if cells < 1 {
+		cells = 1
+	}
+	return Cells(cells)
+}
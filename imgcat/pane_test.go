@@ -0,0 +1,34 @@
+package imgcat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat/termsize"
+)
+
+func TestPaneSizing(t *testing.T) {
+	defer func(old func() (termsize.Size, error)) { paneSize = old }(paneSize)
+
+	tc := []struct {
+		name    string
+		size    termsize.Size
+		err     error
+		options []Option
+		args    string
+	}{
+		{"half the pane", termsize.Size{Cols: 80, Rows: 24}, nil, []Option{PaneWidth(50), PaneHeight(50)}, "width=40;height=12"},
+		{"at least one cell", termsize.Size{Cols: 80, Rows: 24}, nil, []Option{PaneWidth(1)}, "width=1"},
+		{"not in tmux", termsize.Size{}, errors.New("not in tmux"), []Option{PaneWidth(50), PaneHeight(10)}, "width=50%;height=10%"},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			paneSize = func() (termsize.Size, error) { return tt.size, tt.err }
+			if got := strings.Join(newConfig(tt.options).args, ";"); got != tt.args {
+				t.Fatalf("expected args %q; got %q", tt.args, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// placeholderColors is the number of dominant colors in the swatch of
+// placeholders.
+const placeholderColors = 3
+
+// Placeholder returns a line describing the image read from r, to show instead
+// of it where images can't be displayed, such as
+//
+//	██████ [image: foo.png 800x600 PNG 12.3 kB]
+//
+// starting with a swatch of its dominant colors drawn with 24-bit ANSI colors.
+// The name is known when r reads a regular file, such as an *os.File. What
+// isn't known is left out, such as the dimensions and swatch of payloads that
+// don't decode.
+func Placeholder(r io.Reader) (string, error) {
+	name := fileName(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("could not read payload: %v", err)
+	}
+	return placeholder(name, data, true), nil
+}
+
+// placeholder returns the line describing the image with the given name and
+// payload, with a swatch of its colors if wanted.
+func placeholder(name string, data []byte, swatch bool) string {
+	var parts []string
+	if name != "" {
+		parts = append(parts, name)
+	}
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		parts = append(parts, fmt.Sprintf("%dx%d %s", cfg.Width, cfg.Height, strings.ToUpper(format)))
+	}
+	parts = append(parts, byteCount(len(data)))
+	line := "[image: " + strings.Join(parts, " ") + "]"
+	if !swatch {
+		return line
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return line
+	}
+	var s strings.Builder
+	for _, c := range kmeans(samples(img), placeholderColors) {
+		rgb := c.color().(color.RGBA)
+		// Two blocks make about a square.
+		fmt.Fprintf(&s, "\x1b[38;2;%d;%d;%dm██", rgb.R, rgb.G, rgb.B)
+	}
+	if s.Len() == 0 {
+		return line
+	}
+	return s.String() + "\x1b[0m " + line
+}
+
+// byteCount returns n bytes in a human readable form, such as 12.3 kB.
+func byteCount(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, units := float64(n)/1000, "kMGT"
+	for v >= 1000 && len(units) > 1 {
+		v, units = v/1000, units[1:]
+	}
+	return fmt.Sprintf("%.1f %cB", v, units[0])
+}
@@ -0,0 +1,91 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholder(t *testing.T) {
+	img := testPNG(t, 4, 2, color.RGBA{0xff, 0, 0, 0xff})
+	size := byteCount(len(img))
+	tc := []struct {
+		name    string
+		payload []byte
+		want    string
+	}{
+		{"image", img, "\x1b[38;2;255;0;0m██\x1b[0m [image: 4x2 PNG " + size + "]"},
+		{"transparent", testPNG(t, 4, 2, color.Transparent), "[image: 4x2 PNG "},
+		{"not an image", []byte("test"), "[image: 4 B]"},
+		{"empty", nil, "[image: 0 B]"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Placeholder(bytes.NewReader(tt.payload))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(got, tt.want) {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := Placeholder(badReader{}); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
+type badReader struct{}
+
+func (badReader) Read([]byte) (int, error) { return 0, errors.New("broken") }
+
+func TestPlaceholderFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "placeholder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "foo.png")
+	img := testPNG(t, 3, 3, color.White)
+	if err := ioutil.WriteFile(path, img, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := Placeholder(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := " [image: foo.png 3x3 PNG " + byteCount(len(img)) + "]"; !strings.HasSuffix(got, want) {
+		t.Fatalf("expected %q at the end of %q", want, got)
+	}
+}
+
+func TestByteCount(t *testing.T) {
+	tc := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 kB"},
+		{12345, "12.3 kB"},
+		{1500000, "1.5 MB"},
+		{3000000000, "3.0 GB"},
+	}
+	for _, tt := range tc {
+		if got := byteCount(tt.n); got != tt.want {
+			t.Errorf("expected %q for %d; got %q", tt.want, tt.n, got)
+		}
+	}
+}
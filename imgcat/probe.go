@@ -0,0 +1,89 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/campoy/tools/imgcat/termquery"
+)
+
+// Assumptions used by PayloadBudget to turn latency into bandwidth.
+const (
+	// probeWindow is the amount of data in flight per round trip, the usual
+	// TCP window when it isn't scaled.
+	probeWindow = 64 * 1024
+	// probeDelay is how long a user is willing to wait for an image.
+	probeDelay = time.Second
+	// minBudget is the smallest budget, enough for a decent thumbnail.
+	minBudget = 32 * 1024
+)
+
+// Latency measures the round trip time to the terminal, by sending a Primary
+// Device Attributes query, which all terminals answer, and waiting for the
+// reply. Over SSH, it includes the network latency.
+func Latency() (time.Duration, error) {
+	start := time.Now()
+	if _, err := queryTerminal("\x1b[c", queryTimeout, termquery.CSI('c')); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// PayloadBudget estimates how many bytes can be sent to the terminal in about
+// a second, given the round trip time measured by Latency.
+func PayloadBudget(rtt time.Duration) int {
+	if rtt <= 0 {
+		rtt = time.Millisecond
+	}
+	budget := float64(probeWindow) * float64(probeDelay) / float64(rtt)
+	if budget > float64(1<<30) {
+		return 1 << 30
+	}
+	if budget < minBudget {
+		return minBudget
+	}
+	return int(budget)
+}
+
+// Probe measures the latency to the terminal, once per process, and sets
+// TargetBytes to the payload budget it allows, see PayloadBudget. Local
+// sessions are barely affected, while slow links get smaller payloads. Put it
+// after Adaptive to replace its fixed budget with the measured one.
+// If the terminal doesn't answer, it does nothing.
+func Probe() Option {
+	return func(c *config) {
+		rtt, err := probeLatency()
+		if err != nil {
+			return
+		}
+		TargetBytes(PayloadBudget(rtt))(c)
+	}
+}
+
+// Can be swapped for testing.
+var probeLatency = onceLatency()
+
+// onceLatency returns a function measuring Latency the first time it's called,
+// and returning the same result afterwards.
+func onceLatency() func() (time.Duration, error) {
+	var once sync.Once
+	var rtt time.Duration
+	var err error
+	return func() (time.Duration, error) {
+		once.Do(func() { rtt, err = Latency() })
+		return rtt, err
+	}
+}
@@ -0,0 +1,64 @@
+package imgcat
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLatency(t *testing.T) {
+	defer func(old func(string, time.Duration, func([]byte) bool) ([]byte, error)) { queryTerminal = old }(queryTerminal)
+	queryTerminal = func(req string, timeout time.Duration, done func([]byte) bool) ([]byte, error) {
+		if req != "\x1b[c" {
+			t.Fatalf("unexpected query %q", req)
+		}
+		time.Sleep(10 * time.Millisecond)
+		reply := []byte("\x1b[?62;4c")
+		if !done(reply) {
+			t.Fatalf("reply %q not recognized as complete", reply)
+		}
+		return reply, nil
+	}
+	rtt, err := Latency()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rtt < 10*time.Millisecond {
+		t.Fatalf("expected latency of at least 10ms; got %v", rtt)
+	}
+}
+
+func TestPayloadBudget(t *testing.T) {
+	tc := []struct {
+		rtt  time.Duration
+		want int
+	}{
+		{0, 64 * 1024 * 1000},
+		{time.Microsecond, 1 << 30},
+		{time.Millisecond, 64 * 1024 * 1000},
+		{100 * time.Millisecond, 640 * 1024},
+		{time.Second, 64 * 1024},
+		{10 * time.Second, minBudget},
+	}
+	for _, tt := range tc {
+		if got := PayloadBudget(tt.rtt); got != tt.want {
+			t.Errorf("expected budget %d for %v; got %d", tt.want, tt.rtt, got)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	defer func(old func() (time.Duration, error)) { probeLatency = old }(probeLatency)
+	defer func(old func() bool) { isRemote = old }(isRemote)
+	isRemote = func() bool { return true }
+
+	probeLatency = func() (time.Duration, error) { return 100 * time.Millisecond, nil }
+	if c := newConfig([]Option{Adaptive(), Probe()}); c.targetBytes != 640*1024 {
+		t.Errorf("expected target of %d bytes; got %d", 640*1024, c.targetBytes)
+	}
+
+	probeLatency = func() (time.Duration, error) { return 0, errors.New("no reply") }
+	if c := newConfig([]Option{Adaptive(), Probe()}); c.targetBytes != RemoteBytes {
+		t.Errorf("expected target of %d bytes without reply; got %d", RemoteBytes, c.targetBytes)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profgraph
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/colormap"
+)
+
+// Sizes of the drawing, in pixels at scale 1.
+const (
+	scale   = 2
+	padding = 4
+	margin  = 10
+	colGap  = 12
+	rowGap  = 30
+)
+
+// heat colors functions from the coldest to the ones in every sample.
+var heat = colormap.Gradient(color.RGBA{0xf0, 0xf0, 0xf0, 0xff}, color.RGBA{0xfc, 0x8d, 0x59, 0xff}, color.RGBA{0xd7, 0x30, 0x27, 0xff})
+
+// Colors of the drawing.
+var (
+	edgeColor   = color.RGBA{0x80, 0x80, 0x80, 0xff}
+	borderColor = color.RGBA{0x40, 0x40, 0x40, 0xff}
+)
+
+// Draw draws the call graph of the maxNodes functions with the largest
+// cumulative weights, DefaultNodes if zero, from the outermost ones at the
+// top. Functions in less than half a percent of the total are left out.
+// Functions are labeled with their flat and cumulative weights, and colored
+// by the cumulative one, and calls are thicker for heavier samples.
+func (g *Graph) Draw(maxNodes int) image.Image {
+	if maxNodes <= 0 {
+		maxNodes = DefaultNodes
+	}
+	keep := map[*Node]bool{}
+	var nodes []*Node
+	for _, n := range g.Nodes {
+		if len(nodes) == maxNodes || (len(nodes) > 0 && float64(n.Cum) < nodeFraction*float64(g.Total)) {
+			break
+		}
+		keep[n] = true
+		nodes = append(nodes, n)
+	}
+	var edges []*Edge
+	callers, callees := map[*Node][]*Edge{}, map[*Node][]*Edge{}
+	for _, e := range g.Edges {
+		if keep[e.Caller] && keep[e.Callee] {
+			edges = append(edges, e)
+			callees[e.Caller] = append(callees[e.Caller], e)
+			callers[e.Callee] = append(callers[e.Callee], e)
+		}
+	}
+	layers := layout(nodes, callers, callees)
+
+	title := fmt.Sprintf("%s: %s", g.Name, g.format(g.Total))
+	titleSize := bitmapfont.Measure(title, scale)
+	top := 2*margin*scale + titleSize.Y
+	boxH := bitmapfont.Measure("x\nx", scale).Y + 2*padding*scale
+
+	// Position of the box of every function, centering layers.
+	boxes := map[*Node]image.Rectangle{}
+	widths := make([]int, len(layers))
+	width := titleSize.X + 2*margin*scale
+	for i, layer := range layers {
+		for j, n := range layer {
+			if j > 0 {
+				widths[i] += colGap * scale
+			}
+			widths[i] += bitmapfont.Measure(g.label(n), scale).X + 2*padding*scale
+		}
+		if w := widths[i] + 2*margin*scale; w > width {
+			width = w
+		}
+	}
+	y := top
+	for i, layer := range layers {
+		x := (width - widths[i]) / 2
+		for _, n := range layer {
+			w := bitmapfont.Measure(g.label(n), scale).X + 2*padding*scale
+			boxes[n] = image.Rect(x, y, x+w, y+boxH)
+			x += w + colGap*scale
+		}
+		y += boxH + rowGap*scale
+	}
+	height := y - rowGap*scale + margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	bitmapfont.Draw(img, title, image.Pt(margin*scale, margin*scale), color.Black, scale)
+	for _, e := range edges {
+		from, to := boxes[e.Caller], boxes[e.Callee]
+		t := scale
+		if g.Total > 0 {
+			t += int(4 * scale * e.Weight / g.Total)
+		}
+		line(img, image.Pt((from.Min.X+from.Max.X)/2, from.Max.Y), image.Pt((to.Min.X+to.Max.X)/2, to.Min.Y), t, edgeColor)
+	}
+	for _, n := range nodes {
+		b := boxes[n]
+		fill := heat(0)
+		if g.Total > 0 {
+			fill = heat(float64(n.Cum) / float64(g.Total))
+		}
+		draw.Draw(img, b, image.NewUniform(borderColor), image.Point{}, draw.Src)
+		draw.Draw(img, b.Inset(scale), image.NewUniform(fill), image.Point{}, draw.Src)
+		bitmapfont.Draw(img, g.label(n), b.Min.Add(image.Pt(padding*scale, padding*scale)), color.Black, scale)
+	}
+	return img
+}
+
+// layout returns the functions in layers, by their distance from the
+// outermost functions, the ones without callers. Functions of cycles that
+// can't be reached from them start layers of their own. Functions are sorted
+// by the mean position of their callers in the previous layer.
+func layout(nodes []*Node, callers, callees map[*Node][]*Edge) [][]*Node {
+	depth := map[*Node]int{}
+	var queue []*Node
+	visit := func(roots []*Node) {
+		for _, n := range roots {
+			if _, ok := depth[n]; !ok {
+				depth[n] = 0
+				queue = append(queue, n)
+			}
+		}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			for _, e := range callees[n] {
+				if _, ok := depth[e.Callee]; !ok {
+					depth[e.Callee] = depth[n] + 1
+					queue = append(queue, e.Callee)
+				}
+			}
+		}
+	}
+	var roots []*Node
+	for _, n := range nodes {
+		if len(callers[n]) == 0 {
+			roots = append(roots, n)
+		}
+	}
+	visit(roots)
+	// Nodes are sorted by weight, so the heaviest of a cycle starts it.
+	for _, n := range nodes {
+		visit([]*Node{n})
+	}
+
+	var layers [][]*Node
+	for _, n := range nodes {
+		d := depth[n]
+		for len(layers) <= d {
+			layers = append(layers, nil)
+		}
+		layers[d] = append(layers[d], n)
+	}
+	for d := 1; d < len(layers); d++ {
+		pos := map[*Node]int{}
+		for i, n := range layers[d-1] {
+			pos[n] = i
+		}
+		mean := func(n *Node) float64 {
+			sum, count := 0, 0
+			for _, e := range callers[n] {
+				if i, ok := pos[e.Caller]; ok {
+					sum += i
+					count++
+				}
+			}
+			if count == 0 {
+				return float64(len(pos))
+			}
+			return float64(sum) / float64(count)
+		}
+		layer := layers[d]
+		sort.SliceStable(layer, func(i, j int) bool { return mean(layer[i]) < mean(layer[j]) })
+	}
+	return layers
+}
+
+// label returns the text drawn for a function: its name without the path of
+// its package, above its flat and cumulative weights.
+func (g *Graph) label(n *Node) string {
+	return fmt.Sprintf("%s\n%s of %s", shortName(n.Func), g.part(n.Flat), g.part(n.Cum))
+}
+
+// part formats a weight and the part of the total it is, without the unit of
+// counts, given in the title.
+func (g *Graph) part(w int64) string {
+	s := strconv.FormatInt(w, 10)
+	if g.Unit == "bytes" {
+		s = g.format(w)
+	}
+	if g.Total == 0 {
+		return s
+	}
+	return fmt.Sprintf("%s (%.1f%%)", s, 100*float64(w)/float64(g.Total))
+}
+
+// format formats a weight in the unit of the graph.
+func (g *Graph) format(w int64) string {
+	if g.Unit != "bytes" {
+		return fmt.Sprintf("%d %s", w, g.Unit)
+	}
+	const unit = 1000
+	if w < unit {
+		return fmt.Sprintf("%d B", w)
+	}
+	div, exp := int64(unit), 0
+	for x := w / unit; x >= unit; x /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(w)/float64(div), "kMGTPE"[exp])
+}
+
+// shortName returns the name of a function without the path of its package,
+// such as pprof.(*Profile).WriteTo for runtime/pprof.(*Profile).WriteTo.
+func shortName(f string) string {
+	// Type parameters can hold slashes.
+	end := len(f)
+	if i := strings.Index(f, "["); i >= 0 {
+		end = i
+	}
+	return f[strings.LastIndex(f[:end], "/")+1:]
+}
+
+// line draws a line t pixels thick from a to b.
+func line(img *image.RGBA, a, b image.Point, t int, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := abs(dx)
+	if abs(dy) > steps {
+		steps = abs(dy)
+	}
+	for i := 0; i <= steps; i++ {
+		p := a
+		if steps > 0 {
+			p = p.Add(image.Pt(dx*i/steps, dy*i/steps))
+		}
+		draw.Draw(img, image.Rect(p.X-t/2, p.Y-t/2, p.X-t/2+t, p.Y-t/2+t), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package profgraph draws the call graph of the profiles of runtime/pprof,
+// such as the goroutines or the heap of the running program, and displays it
+// in the terminal, for a quick look while debugging without leaving it.
+//
+// For instance, to display where goroutines are blocked whenever the program
+// receives SIGUSR1:
+//
+//	stop := profgraph.OnSignal(syscall.SIGUSR1, "goroutine")
+//	defer stop()
+//
+// Graphs are drawn from the text format written by runtime/pprof with debug
+// set to 1, also served by net/http/pprof with ?debug=1, so Parse can draw the
+// profiles of other programs too.
+package profgraph
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+// DefaultNodes is the number of functions drawn when Graph.Draw is given zero.
+const DefaultNodes = 30
+
+// nodeFraction is the smallest part of the total that functions are drawn for.
+const nodeFraction = 0.005
+
+// A Graph holds the functions of the stacks of a profile, and the calls
+// between them, weighted by the samples they're part of.
+type Graph struct {
+	// Name of the profile, such as goroutine or heap.
+	Name string
+	// Unit of the weights, goroutines, threads, or bytes.
+	Unit string
+	// Total is the sum of the weights of the samples.
+	Total int64
+	// Nodes holds the functions, sorted by decreasing cumulative weight.
+	Nodes []*Node
+	// Edges holds the calls between functions.
+	Edges []*Edge
+}
+
+// A Node is a function of a profile.
+type Node struct {
+	Func string
+	// Flat is the weight of the samples of which it's the innermost function,
+	// and Cum the weight of the samples it's part of.
+	Flat, Cum int64
+}
+
+// An Edge is a call from a function to another, with the weight of the
+// samples it's part of.
+type Edge struct {
+	Caller, Callee *Node
+	Weight         int64
+}
+
+// Lookup returns the graph of the profile of the running program with the
+// given name, as in pprof.Lookup. The goroutine, threadcreate, heap, and
+// allocs profiles are supported; the heap profile is weighted by the bytes in
+// use, and the allocs one by the bytes allocated.
+func Lookup(name string) (*Graph, error) {
+	p := pprof.Lookup(name)
+	if p == nil {
+		return nil, fmt.Errorf("no profile named %q", name)
+	}
+	buf := new(bytes.Buffer)
+	if err := p.WriteTo(buf, 1); err != nil {
+		return nil, fmt.Errorf("could not write %s profile: %v", name, err)
+	}
+	return parse(buf, name, name == "allocs")
+}
+
+// Parse reads a profile in the text format written by runtime/pprof with
+// debug set to 1, such as goroutine or heap profiles. Heap profiles are
+// weighted by the bytes in use.
+func Parse(r io.Reader) (*Graph, error) { return parse(r, "", false) }
+
+// parse reads a profile, weighting heap profiles by the bytes allocated
+// rather than in use if allocated is set.
+func parse(r io.Reader, name string, allocated bool) (*Graph, error) {
+	g := &Graph{Name: name}
+	nodes := map[string]*Node{}
+	edges := map[[2]*Node]*Edge{}
+	var weight int64
+	var stack []string
+	// Whether the lines read are the frames of a sample.
+	inSample := false
+	flush := func() {
+		inSample = false
+		if len(stack) == 0 {
+			return
+		}
+		g.Total += weight
+		// Frames are listed from the innermost one. Recursive functions
+		// and calls are counted once per sample.
+		seen := map[*Node]bool{}
+		seenEdge := map[*Edge]bool{}
+		var callee *Node
+		for i, f := range stack {
+			n := nodes[f]
+			if n == nil {
+				n = &Node{Func: f}
+				nodes[f] = n
+				g.Nodes = append(g.Nodes, n)
+			}
+			if i == 0 {
+				n.Flat += weight
+			}
+			if !seen[n] {
+				seen[n] = true
+				n.Cum += weight
+			}
+			if callee != nil && callee != n {
+				e := edges[[2]*Node{n, callee}]
+				if e == nil {
+					e = &Edge{Caller: n, Callee: callee}
+					edges[[2]*Node{n, callee}] = e
+					g.Edges = append(g.Edges, e)
+				}
+				if !seenEdge[e] {
+					seenEdge[e] = true
+					e.Weight += weight
+				}
+			}
+			callee = n
+		}
+		stack = nil
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			flush()
+		case n == 1:
+			if err := g.header(fields); err != nil {
+				return nil, err
+			}
+		case fields[0] == "#":
+			// A frame such as # 0x4cc7d0 main.main+0x12f /tmp/main.go:4,
+			// rather than the memory statistics ending heap profiles.
+			if inSample && len(fields) >= 3 && strings.HasPrefix(fields[1], "0x") {
+				stack = append(stack, funcName(fields[2]))
+			}
+		case strings.Contains(line, " @ "):
+			flush()
+			w, err := sampleWeight(fields, g.Unit == "bytes", allocated)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			weight, inSample = w, true
+		}
+	}
+	flush()
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("could not read profile: %v", err)
+	}
+	if g.Unit == "" {
+		return nil, fmt.Errorf("not a profile written with debug set to 1")
+	}
+	sort.SliceStable(g.Nodes, func(i, j int) bool { return g.Nodes[i].Cum > g.Nodes[j].Cum })
+	return g, nil
+}
+
+// header reads the first line of a profile, such as
+// goroutine profile: total 4 or heap profile: 3: 1024 [10: 4096] @ heap/1048576.
+func (g *Graph) header(fields []string) error {
+	if len(fields) < 2 || fields[1] != "profile:" {
+		return fmt.Errorf("not a profile written with debug set to 1: %q", strings.Join(fields, " "))
+	}
+	if g.Name == "" {
+		g.Name = fields[0]
+	}
+	switch fields[0] {
+	case "goroutine":
+		g.Unit = "goroutines"
+	case "threadcreate":
+		g.Unit = "threads"
+	case "heap":
+		g.Unit = "bytes"
+	default:
+		return fmt.Errorf("%s profiles are not supported, use goroutine, threadcreate, heap, or allocs", fields[0])
+	}
+	return nil
+}
+
+// sampleWeight returns the weight of a sample line, such as 2 @ 0x43a 0x44b,
+// or 1: 512 [5: 2048] @ 0x43a for heap profiles, whose weight is the bytes in
+// use or allocated.
+func sampleWeight(fields []string, heap, allocated bool) (int64, error) {
+	i := 0
+	if heap {
+		i = 1
+		if allocated {
+			i = 3
+		}
+	}
+	if i >= len(fields) {
+		return 0, fmt.Errorf("bad sample %q", strings.Join(fields, " "))
+	}
+	w, err := strconv.ParseInt(strings.Trim(fields[i], "[]:"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad sample %q", strings.Join(fields, " "))
+	}
+	return w, nil
+}
+
+// funcName returns the name of the function of a frame such as
+// main.main+0x12f.
+func funcName(frame string) string {
+	if i := strings.LastIndex(frame, "+0x"); i > 0 {
+		return frame[:i]
+	}
+	return frame
+}
+
+// Show draws the graph of the profile of the running program with the given
+// name, see Lookup, and displays it in w with the given options.
+func Show(w io.Writer, name string, options ...imgcat.Option) error {
+	g, err := Lookup(name)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, g.Draw(0)); err != nil {
+		return fmt.Errorf("could not encode graph: %v", err)
+	}
+	enc, err := imgcat.NewEncoder(w, append([]imgcat.Option{imgcat.Inline(true), imgcat.Name(name + ".png")}, options...)...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(buf)
+}
+
+// Can be swapped for testing.
+var terminal = func() (io.WriteCloser, error) { return os.OpenFile("/dev/tty", os.O_WRONLY, 0) }
+
+// OnSignal displays the graph of the profile with the given name, see Show,
+// every time the program receives sig, such as syscall.SIGUSR1. Graphs are
+// written to the terminal of the program, or to its standard error if it has
+// none, where errors are reported too. Calling stop stops it.
+func OnSignal(sig os.Signal, name string, options ...imgcat.Option) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sig)
+	go func() {
+		for {
+			select {
+			case <-c:
+			case <-done:
+				return
+			}
+			w, err := terminal()
+			if err != nil {
+				w = nopCloser{os.Stderr}
+			}
+			if err := Show(w, name, options...); err != nil {
+				fmt.Fprintf(os.Stderr, "profgraph: %v\n", err)
+			}
+			w.Close()
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
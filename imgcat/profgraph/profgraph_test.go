@@ -0,0 +1,190 @@
+package profgraph
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+const goroutines = `goroutine profile: total 3
+2 @ 0x47d82a 0x480925 0x4de8bd 0x483561
+#	0x480924	time.Sleep+0x164	/usr/local/go/src/runtime/time.go:368
+#	0x4de8bc	main.worker+0x1c	/tmp/main.go:12
+#	0x4de8bc	main.main.func1+0x1c	/tmp/main.go:8
+
+1 @ 0x440e11 0x4cc7d1 0x4de850 0x44aa27
+#	0x4cc7d0	runtime/pprof.writeGoroutine+0x44	/usr/local/go/src/runtime/pprof/pprof.go:781
+#	0x4de84f	main.main+0x12f				/tmp/main.go:4
+#	0x44aa26	runtime.main+0x426			/usr/local/go/src/runtime/proc.go:302
+`
+
+const heap = `heap profile: 3: 1536 [10: 8192] @ heap/1048576
+2: 1024 [8: 4096] @ 0x47c0ba 0x4de765
+#	0x4de764	main.grow+0x44		/tmp/main.go:20
+#	0x4de764	main.main+0x44		/tmp/main.go:4
+
+1: 512 [2: 4096] @ 0x47c0ba 0x4de766
+#	0x4de765	main.main+0x45		/tmp/main.go:5
+
+
+# runtime.MemStats
+# Alloc = 6611240
+# PauseNs = [19702 0 0]
+`
+
+func TestParse(t *testing.T) {
+	type node struct {
+		flat, cum int64
+	}
+	tc := []struct {
+		name      string
+		profile   string
+		allocated bool
+		unit      string
+		total     int64
+		nodes     map[string]node
+		edges     map[string]int64
+	}{
+		{"goroutine", goroutines, false, "goroutines", 3,
+			map[string]node{
+				"time.Sleep": {2, 2}, "main.worker": {0, 2}, "main.main.func1": {0, 2},
+				"runtime/pprof.writeGoroutine": {1, 1}, "main.main": {0, 1}, "runtime.main": {0, 1},
+			},
+			map[string]int64{
+				"main.worker time.Sleep": 2, "main.main.func1 main.worker": 2,
+				"main.main runtime/pprof.writeGoroutine": 1, "runtime.main main.main": 1,
+			}},
+		{"heap", heap, false, "bytes", 1536,
+			map[string]node{"main.grow": {1024, 1024}, "main.main": {512, 1536}},
+			map[string]int64{"main.main main.grow": 1024}},
+		{"allocs", heap, true, "bytes", 8192,
+			map[string]node{"main.grow": {4096, 4096}, "main.main": {4096, 8192}},
+			map[string]int64{"main.main main.grow": 4096}},
+		{"recursion", "goroutine profile: total 1\n1 @ 0x1 0x2 0x3\n#\t0x1\tmain.f+0x1\ta.go:1\n#\t0x2\tmain.f+0x2\ta.go:1\n#\t0x3\tmain.main+0x3\ta.go:2\n", false, "goroutines", 1,
+			map[string]node{"main.f": {1, 1}, "main.main": {0, 1}},
+			map[string]int64{"main.main main.f": 1}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := parse(strings.NewReader(tt.profile), "", tt.allocated)
+			if err != nil {
+				t.Fatalf("could not parse: %v", err)
+			}
+			if g.Unit != tt.unit || g.Total != tt.total {
+				t.Errorf("expected %d %s; got %d %s", tt.total, tt.unit, g.Total, g.Unit)
+			}
+			nodes := map[string]node{}
+			for i, n := range g.Nodes {
+				nodes[n.Func] = node{n.Flat, n.Cum}
+				if i > 0 && n.Cum > g.Nodes[i-1].Cum {
+					t.Errorf("expected nodes sorted by cumulative weight; got %s after %s", n.Func, g.Nodes[i-1].Func)
+				}
+			}
+			if len(nodes) != len(tt.nodes) {
+				t.Errorf("expected nodes %v; got %v", tt.nodes, nodes)
+			}
+			for f, want := range tt.nodes {
+				if got := nodes[f]; got != want {
+					t.Errorf("expected %s to weigh %v; got %v", f, want, got)
+				}
+			}
+			edges := map[string]int64{}
+			for _, e := range g.Edges {
+				edges[e.Caller.Func+" "+e.Callee.Func] = e.Weight
+			}
+			if len(edges) != len(tt.edges) {
+				t.Errorf("expected edges %v; got %v", tt.edges, edges)
+			}
+			for call, want := range tt.edges {
+				if got := edges[call]; got != want {
+					t.Errorf("expected call %s to weigh %d; got %d", call, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tc := []struct {
+		name, profile, err string
+	}{
+		{"empty", "", "not a profile"},
+		{"proto", "\x1f\x8b\x08\x00", "not a profile"},
+		{"mutex", "--- mutex:\ncycles/second=1000\n", "not a profile"},
+		{"block", "block profile: total 1\n", "not supported"},
+		{"bad sample", "goroutine profile: total 1\nx @ 0x1\n", "line 2: bad sample"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.profile))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	// a calls b, which calls c, which calls b back; x and y call each other.
+	a, b, c, x, y := &Node{Func: "a"}, &Node{Func: "b"}, &Node{Func: "c"}, &Node{Func: "x"}, &Node{Func: "y"}
+	callers, callees := map[*Node][]*Edge{}, map[*Node][]*Edge{}
+	for _, e := range []*Edge{{Caller: a, Callee: b}, {Caller: b, Callee: c}, {Caller: c, Callee: b}, {Caller: x, Callee: y}, {Caller: y, Callee: x}} {
+		callers[e.Callee] = append(callers[e.Callee], e)
+		callees[e.Caller] = append(callees[e.Caller], e)
+	}
+	layers := layout([]*Node{a, b, x, c, y}, callers, callees)
+	var got []string
+	for _, layer := range layers {
+		var names []string
+		for _, n := range layer {
+			names = append(names, n.Func)
+		}
+		got = append(got, strings.Join(names, " "))
+	}
+	if want := []string{"a x", "b y", "c"}; strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected layers %q; got %q", want, got)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	g, err := Parse(strings.NewReader(goroutines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, one := g.Draw(0).Bounds(), g.Draw(1).Bounds()
+	if all.Dy() <= one.Dy() {
+		t.Errorf("expected the graph of every function to be taller than the one of one function; got %v and %v", all, one)
+	}
+}
+
+func TestShortName(t *testing.T) {
+	tc := []struct{ in, out string }{
+		{"main.main", "main.main"},
+		{"runtime/pprof.(*Profile).WriteTo", "pprof.(*Profile).WriteTo"},
+		{"github.com/campoy/tools/imgcat.(*Encoder).Encode", "imgcat.(*Encoder).Encode"},
+		{"example.com/m.F[go.shape.interface { M() a/b.T }]", "m.F[go.shape.interface { M() a/b.T }]"},
+	}
+	for _, tt := range tc {
+		if got := shortName(tt.in); got != tt.out {
+			t.Errorf("shortName(%q): expected %q; got %q", tt.in, tt.out, got)
+		}
+	}
+}
+
+func TestShow(t *testing.T) {
+	t.Setenv(imgcat.EnvProtocol, "iterm2")
+	buf := new(bytes.Buffer)
+	if err := Show(buf, "goroutine", imgcat.Passthrough(false)); err != nil {
+		t.Fatalf("could not show: %v", err)
+	}
+	name := base64.StdEncoding.EncodeToString([]byte("goroutine.png"))
+	if !strings.HasPrefix(buf.String(), "\x1b]1337;File=inline=1;name="+name) {
+		t.Errorf("expected an image named goroutine.png; got %.60q", buf)
+	}
+	if err := Show(buf, "nothing"); err == nil {
+		t.Errorf("expected an error for an unknown profile")
+	}
+}
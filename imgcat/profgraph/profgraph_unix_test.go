@@ -0,0 +1,46 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package profgraph
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+type closeNotifier struct {
+	*bytes.Buffer
+	closed chan struct{}
+}
+
+func (c closeNotifier) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestOnSignal(t *testing.T) {
+	t.Setenv(imgcat.EnvProtocol, "iterm2")
+	out := closeNotifier{new(bytes.Buffer), make(chan struct{})}
+	defer func(old func() (io.WriteCloser, error)) { terminal = old }(terminal)
+	terminal = func() (io.WriteCloser, error) { return out, nil }
+
+	stop := OnSignal(syscall.SIGUSR1, "goroutine", imgcat.Passthrough(false))
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-out.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the graph displayed after the signal")
+	}
+	if !strings.HasPrefix(out.String(), "\x1b]1337;File=") {
+		t.Errorf("expected an image; got %.60q", out)
+	}
+}
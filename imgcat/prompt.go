@@ -0,0 +1,62 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// promptPixels is the maximum number of pixels of images in prompts.
+const promptPixels = 64 * 64
+
+// Prompt returns the text that displays the image read from r in a shell
+// prompt, one line high and the given number of cells wide, so it can be
+// rendered again for every prompt without adding lines to the scrollback.
+// Shells are told the image takes as many columns as it does, so line editing
+// isn't affected. The supported shells are bash and zsh.
+func Prompt(r io.Reader, shell string, cells int) (string, error) {
+	var start, end string
+	switch shell {
+	case "bash":
+		// Markers of invisible characters understood by readline, which
+		// unlike \[ and \] are honored in expanded variables.
+		start, end = "\x01", "\x02"
+	case "zsh":
+		start, end = "%{", "%}"
+	default:
+		return "", fmt.Errorf("unsupported shell %q", shell)
+	}
+	if cells <= 0 {
+		return "", fmt.Errorf("invalid width %d", cells)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{
+		Inline(true),
+		Width(Cells(cells)),
+		Height(Cells(1)),
+		PreserveAspectRatio(true),
+		Newline(false),
+		MaxPixels(DefaultMaxPixels),
+		Downsample(promptPixels),
+	}}
+	if err := enc.Encode(r); err != nil {
+		return "", err
+	}
+	// The spaces are counted by the shell, and the image is drawn over them.
+	return fmt.Sprintf("%s%s\x1b[%dD%s%s", strings.Repeat(" ", cells), start, cells, buf, end), nil
+}
@@ -0,0 +1,43 @@
+package imgcat
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "false"))
+	img := testPNG(t, 4, 4, color.White)
+
+	tc := []struct {
+		shell  string
+		cells  int
+		prefix string
+		suffix string
+	}{
+		{"bash", 2, "  \x01\x1b[2D\x1b]1337;File=inline=1;width=2;height=1;", "\a\x02"},
+		{"zsh", 3, "   %{\x1b[3D\x1b]1337;File=inline=1;width=3;height=1;", "\a%}"},
+		{"fish", 2, "", ""},
+		{"zsh", 0, "", ""},
+	}
+	for _, tt := range tc {
+		out, err := Prompt(bytes.NewReader(img), tt.shell, tt.cells)
+		if tt.prefix == "" {
+			if err == nil {
+				t.Errorf("%s %d: expected error; got %q", tt.shell, tt.cells, out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %d: unexpected error: %v", tt.shell, tt.cells, err)
+			continue
+		}
+		if !strings.HasPrefix(out, tt.prefix) || !strings.HasSuffix(out, tt.suffix) {
+			t.Errorf("%s %d: expected output starting with %q and ending with %q; got %q", tt.shell, tt.cells, tt.prefix, tt.suffix, out)
+		}
+	}
+}
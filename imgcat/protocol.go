@@ -0,0 +1,357 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/termsize"
+)
+
+// A Protocol is a way of displaying images in a terminal, see FallbackChain.
+type Protocol string
+
+// Protocols, from the best looking to the most widely supported.
+const (
+	// ITerm2 sends images with the inline images protocol of iTerm2, also
+	// implemented by WezTerm and others. It's the default.
+	ITerm2 Protocol = "iterm2"
+	// Kitty sends images with the graphics protocol of kitty, also
+	// implemented by Ghostty, WezTerm, and Konsole.
+	Kitty Protocol = "kitty"
+	// Sixel sends images as sixels, supported by foot, mlterm, and xterm
+	// started with -ti vt340 among others.
+	Sixel Protocol = "sixel"
+	// HalfBlock draws images with colored Unicode half blocks, two pixels per
+	// cell, in any terminal with 24-bit colors.
+	HalfBlock Protocol = "halfblock"
+	// ASCII draws images with characters as dense as the pixels are bright,
+	// which even works in logs.
+	ASCII Protocol = "ascii"
+	// Summary writes a line such as [image: foo.png 800x600 PNG 12.3 kB]
+	// instead of each image, for logs, see Placeholder. In terminals, it
+	// starts with a swatch of the colors of the image.
+	Summary Protocol = "summary"
+	// Skip writes nothing.
+	Skip Protocol = "skip"
+)
+
+// graphics reports whether p sends images to the terminal, rather than
+// drawing them with text.
+func (p Protocol) graphics() bool { return p == ITerm2 || p == Kitty || p == Sixel }
+
+// FallbackChain sets the protocols NewEncoder picks from, in order of
+// preference: the first one the terminal supports is used for every image,
+// and NewEncoder fails only if none is supported. For instance,
+//
+//	FallbackChain(ITerm2, Kitty, Sixel, HalfBlock, ASCII, Skip)
+//
+// uses the best protocol available, draws images with text when the output is
+// a terminal without images, and with characters elsewhere, and finally writes
+// nothing. Detection of the graphics protocols can be overridden with
+// EnvProtocol, and none turns them all off. Without a fallback chain, only
+// ITerm2 is used.
+func FallbackChain(protocols ...Protocol) Option {
+	return func(c *config) { c.chain = append([]Protocol(nil), protocols...) }
+}
+
+// SilentOnUnsupported makes NewEncoder return an Encoder writing a line such
+// as [image: foo.png 800x600 PNG 12.3 kB] for each image with the Summary
+// protocol, rather than an error, when the terminal supports none of the
+// protocols it can use. This lets the same code display images in terminals
+// and log them in CI.
+func SilentOnUnsupported() Option {
+	return func(c *config) { c.silent = true }
+}
+
+// NewNopEncoder returns an Encoder that reads images and displays nothing,
+// with the Skip protocol.
+func NewNopEncoder() *Encoder {
+	return &Encoder{out: ioutil.Discard, protocol: Skip}
+}
+
+// ErrNoProtocol is returned by NewEncoder when the terminal supports none of
+// the protocols it can use.
+var ErrNoProtocol = errors.New("no supported protocol")
+
+// pickProtocol returns the first protocol in chain supported when writing to
+// w.
+func pickProtocol(chain []Protocol, w io.Writer) (Protocol, error) {
+	for _, p := range chain {
+		if supports(p, w) {
+			return p, nil
+		}
+	}
+	names := make([]string, len(chain))
+	for i, p := range chain {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("none of %s works in this terminal: %w", strings.Join(names, ", "), ErrNoProtocol)
+}
+
+// Can be swapped for testing.
+var supports = func(p Protocol, w io.Writer) bool {
+	custom := rendererOf(p) != nil
+	if forced := os.Getenv(EnvProtocol); forced != "" && (p.graphics() || custom) {
+		return forced == string(p)
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch p {
+	case ITerm2:
+		return isSupported()
+	case Kitty:
+		return term == "xterm-kitty" || term == "xterm-ghostty" || os.Getenv("KITTY_WINDOW_ID") != "" ||
+			program == "WezTerm" || program == "ghostty"
+	case Sixel:
+		return strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel")
+	case HalfBlock:
+		return IsTerminal(w)
+	case ASCII, Summary, Skip:
+		return true
+	}
+	return custom
+}
+
+// Protocol returns the protocol the Encoder displays images with.
+func (enc *Encoder) Protocol() Protocol {
+	if enc.protocol == "" {
+		return ITerm2
+	}
+	return enc.protocol
+}
+
+// render displays the payload read from r with a protocol other than ITerm2.
+func (enc *Encoder) render(cfg *config, p Protocol, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read payload: %v", err)
+	}
+	switch p {
+	case Skip:
+		cfg.trace.notef("image skipped")
+		return nil
+	case Summary:
+		line := placeholder(cfg.name(), data, IsTerminal(enc.out))
+		_, err := io.WriteString(enc.out, cfg.linkStart()+line+cfg.linkEnd()+"\n")
+		return err
+	}
+	if r := rendererOf(p); r != nil {
+		if err := enc.renderCustom(cfg, r, data); err != nil {
+			return err
+		}
+		cfg.sum(data)
+		return nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("could not decode image: %v", err)
+	}
+	cols, rows := cfg.cells()
+	size, preserve, max := img.Bounds().Size(), cfg.preserveAspectRatio(), terminalCols()
+
+	var out, shift string
+	switch p {
+	case Kitty:
+		cols, rows = fitCells(size, cellWidth, cellHeight, cols, rows, max, preserve)
+		out, err = kittySequence(data, img, cols, rows, cfg)
+		shift = cursorRight(cfg.indentFor(cols))
+	case Sixel:
+		if cols > 0 || rows > 0 {
+			cols, rows = fitCells(size, cellWidth, cellHeight, cols, rows, max, preserve)
+			img = Resize(img, cols*cellWidth, rows*cellHeight)
+		} else if size.X > max*cellWidth {
+			img = Resize(img, max*cellWidth, size.Y*max*cellWidth/size.X)
+		}
+		out = passthrough(sixelSequence(img), cfg.tmux())
+		shift = cursorRight(cfg.indentFor((img.Bounds().Dx() + cellWidth - 1) / cellWidth))
+	case HalfBlock:
+		// Each cell covers two pixels, one above the other.
+		cols, rows = fitCells(size, 1, 2, cols, rows, max, preserve)
+		cols, rows = cfg.limitCells(size, 1, 2, cols, rows, max)
+		img = Resize(img, cols, 2*rows)
+		// Translucent pixels are blended with the background, when the
+		// terminal tells it, rather than rounded to opaque or transparent.
+		if bg, err := enc.background(); err == nil {
+			img = matte(img, bg)
+		}
+		out = halfBlocks(img)
+		shift = cursorRight(cfg.indentFor(cols))
+		out = strings.Replace(out, "\n", "\n"+shift, -1)
+	case ASCII:
+		cols, rows = fitCells(size, 1, 2, cols, rows, max, preserve)
+		cols, rows = cfg.limitCells(size, 1, 2, cols, rows, max)
+		// Backgrounds are assumed dark unless the terminal tells otherwise.
+		dark := true
+		if bg, err := enc.background(); err == nil {
+			dark = IsDark(bg)
+		}
+		out = asciiArt(Resize(img, cols, rows), dark)
+		// Spaces rather than cursor moves, which would be garbage in logs.
+		shift = strings.Repeat(" ", cfg.indentFor(cols))
+		out = strings.Replace(out, "\n", "\n"+shift, -1)
+	default:
+		return fmt.Errorf("unknown protocol %q", p)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(enc.out, shift+cfg.linkStart()+out+cfg.linkEnd()+cfg.newline()); err != nil {
+		return err
+	}
+	cfg.sum(data)
+	return nil
+}
+
+// background returns the background color of the terminal images are written
+// to, and an error if they're not written to a terminal or it doesn't tell.
+func (enc *Encoder) background() (color.Color, error) {
+	if !IsTerminal(enc.out) {
+		return nil, errors.New("not a terminal")
+	}
+	return terminalBackground()
+}
+
+// sum gives the checksum of data, the payload that was sent, to the function
+// given with Checksum if any.
+func (c *config) sum(data []byte) {
+	if c.checksum != nil {
+		sum := sha256.Sum256(data)
+		c.checksum(sum[:])
+	}
+}
+
+// name returns the name given with the Name option, empty if none.
+func (c *config) name() string {
+	i := c.argIndex("name")
+	if i < 0 {
+		return ""
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(c.args[i], "name="))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Sizes of a cell in pixels assumed to convert lengths given in pixels.
+const (
+	cellWidth  = 8
+	cellHeight = 16
+)
+
+// cells returns the width and height of images in cells given by the Width
+// and Height options, zero when not given or auto, for protocols that don't
+// take lengths in pixels or percents like ITerm2.
+func (c *config) cells() (cols, rows int) {
+	var size *termsize.Size
+	length := func(key string, cell int, dim func(termsize.Size) int) int {
+		i := c.argIndex(key)
+		if i < 0 {
+			return 0
+		}
+		v := strings.TrimPrefix(c.args[i], key+"=")
+		switch {
+		case strings.HasSuffix(v, "px"):
+			n, _ := strconv.Atoi(strings.TrimSuffix(v, "px"))
+			return (n + cell - 1) / cell
+		case strings.HasSuffix(v, "%"):
+			n, _ := strconv.Atoi(strings.TrimSuffix(v, "%"))
+			if size == nil {
+				s, err := termsize.Get()
+				if err != nil {
+					s = termsize.Size{Cols: 80, Rows: 24}
+				}
+				size = &s
+			}
+			return dim(*size) * n / 100
+		}
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	cols = length("width", cellWidth, func(s termsize.Size) int { return s.Cols })
+	rows = length("height", cellHeight, func(s termsize.Size) int { return s.Rows })
+	return cols, rows
+}
+
+// moveCursor reports whether the cursor is moved after images, see
+// MoveCursor.
+func (c *config) moveCursor() bool {
+	i := c.argIndex("doNotMoveCursor")
+	return i < 0 || c.args[i] != "doNotMoveCursor=1"
+}
+
+// preserveAspectRatio reports whether images keep their aspect ratio when
+// both their width and height are given, see PreserveAspectRatio.
+func (c *config) preserveAspectRatio() bool {
+	i := c.argIndex("preserveAspectRatio")
+	return i < 0 || c.args[i] != "preserveAspectRatio=0"
+}
+
+// fitCells returns the size in cells of an image of the given size in pixels,
+// drawn with cells covering cw by ch of its pixels, given the width and
+// height wanted in cells, zero meaning any. Without either, the image is at
+// most maxCols wide. With both, the image fits in them if preserve is true,
+// and fills them otherwise.
+func fitCells(size image.Point, cw, ch, cols, rows, maxCols int, preserve bool) (int, int) {
+	if size.X <= 0 || size.Y <= 0 {
+		return 1, 1
+	}
+	rowsFor := func(cols int) int { return (cols*cw*size.Y/size.X + ch - 1) / ch }
+	colsFor := func(rows int) int { return rows * ch * size.X / size.Y / cw }
+	if cols <= 0 && rows <= 0 {
+		cols = (size.X + cw - 1) / cw
+		if cols > maxCols {
+			cols = maxCols
+		}
+	}
+	switch {
+	case rows <= 0:
+		rows = rowsFor(cols)
+	case cols <= 0:
+		cols = colsFor(rows)
+	case preserve:
+		if r := rowsFor(cols); r <= rows {
+			rows = r
+		} else {
+			cols = colsFor(rows)
+		}
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return cols, rows
+}
+
+// terminalCols returns the width of the terminal in cells, or 80 if unknown.
+// Can be swapped for testing.
+var terminalCols = func() int {
+	if s, err := termsize.Get(); err == nil && s.Cols > 0 {
+		return s.Cols
+	}
+	return 80
+}
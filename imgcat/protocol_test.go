@@ -0,0 +1,298 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFallbackChain(t *testing.T) {
+	defer func(old func(Protocol, io.Writer) bool) { supports = old }(supports)
+
+	tc := []struct {
+		name      string
+		chain     []Protocol
+		supported map[Protocol]bool
+		want      Protocol
+		err       error
+	}{
+		{"first supported", []Protocol{ITerm2, Kitty, Sixel}, map[Protocol]bool{Kitty: true, Sixel: true}, Kitty, nil},
+		{"preferred", []Protocol{Sixel, Kitty}, map[Protocol]bool{Kitty: true, Sixel: true}, Sixel, nil},
+		{"text", []Protocol{ITerm2, HalfBlock, ASCII}, map[Protocol]bool{HalfBlock: true, ASCII: true}, HalfBlock, nil},
+		{"none", []Protocol{ITerm2, Kitty}, nil, "", ErrNoProtocol},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			supports = func(p Protocol, w io.Writer) bool { return tt.supported[p] }
+			enc, err := NewEncoder(new(bytes.Buffer), FallbackChain(tt.chain...))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+			if err == nil && enc.Protocol() != tt.want {
+				t.Fatalf("expected protocol %s; got %s", tt.want, enc.Protocol())
+			}
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	tc := []struct {
+		name  string
+		env   map[string]string
+		p     Protocol
+		works bool
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, Kitty, true},
+		{"ghostty", map[string]string{"TERM_PROGRAM": "ghostty"}, Kitty, true},
+		{"kitty elsewhere", map[string]string{"TERM": "xterm-256color"}, Kitty, false},
+		{"foot", map[string]string{"TERM": "foot-extra"}, Sixel, true},
+		{"sixel elsewhere", map[string]string{"TERM": "xterm-256color"}, Sixel, false},
+		{"forced", map[string]string{"TERM": "xterm-256color", EnvProtocol: "sixel"}, Sixel, true},
+		{"forced other", map[string]string{"TERM": "xterm-kitty", EnvProtocol: "sixel"}, Kitty, false},
+		{"forced off", map[string]string{"TERM": "xterm-kitty", EnvProtocol: "none"}, Kitty, false},
+		{"text forced off", map[string]string{EnvProtocol: "none"}, ASCII, true},
+		{"halfblock not terminal", nil, HalfBlock, false},
+		{"skip", nil, Skip, true},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"TERM", "TERM_PROGRAM", "KITTY_WINDOW_ID", EnvProtocol} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := supports(tt.p, new(bytes.Buffer)); got != tt.works {
+				t.Fatalf("expected %s supported to be %v; got %v", tt.p, tt.works, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	img := testPNG(t, 16, 32, color.White)
+	tc := []struct {
+		p      Protocol
+		opts   []Option
+		prefix string
+		suffix string
+	}{
+		{Kitty, []Option{Passthrough(false)}, "\x1b_Ga=T,f=100,q=2,c=2,r=2,m=0;", "\x1b\\\n"},
+		{Kitty, []Option{Passthrough(true), MoveCursor(false), Newline(false)}, "\x1bPtmux;\x1b\x1b_Ga=T,f=100,q=2,c=2,r=2,C=1,m=0;", "\x1b\\"},
+		{Sixel, []Option{Passthrough(false)}, "\x1bP0;1;0q\"1;1;16;32", "\x1b\\\n"},
+		{Sixel, []Option{Passthrough(false), Width(Cells(1))}, "\x1bP0;1;0q\"1;1;8;16", "\x1b\\\n"},
+		{HalfBlock, []Option{Width(Cells(2))}, "\x1b[38;2;255;255;255;48;2;255;255;255m▀", "▀\x1b[0m\n"},
+		{ASCII, []Option{Width(Cells(2))}, "@@\n@@\n", ""},
+		{Skip, nil, "", ""},
+	}
+	for _, tt := range tc {
+		t.Run(string(tt.p), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := &Encoder{out: buf, options: tt.opts, protocol: tt.p}
+			if err := enc.Encode(bytes.NewReader(img)); err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			got := buf.String()
+			if !strings.HasPrefix(got, tt.prefix) || !strings.HasSuffix(got, tt.suffix) {
+				t.Fatalf("expected %q...%q; got %q", tt.prefix, tt.suffix, got)
+			}
+			if tt.p == Skip && got != "" {
+				t.Fatalf("expected nothing; got %q", got)
+			}
+		})
+	}
+
+	// Text protocols need images they can decode.
+	enc := &Encoder{out: new(bytes.Buffer), protocol: ASCII}
+	if err := enc.Encode(strings.NewReader("test")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
+// fakeTerminal is a buffer that IsTerminal takes for a terminal.
+type fakeTerminal struct{ bytes.Buffer }
+
+func (*fakeTerminal) Stat() (os.FileInfo, error) { return os.Stat(os.DevNull) }
+
+func TestRenderBackground(t *testing.T) {
+	defer func(old func() (color.Color, error)) { terminalBackground = old }(terminalBackground)
+
+	gray := testPNG(t, 2, 4, color.Gray{0x40})
+	red := testPNG(t, 2, 4, color.NRGBA{0xff, 0, 0, 0x80})
+	tc := []struct {
+		name     string
+		p        Protocol
+		img      []byte
+		bg       color.Color
+		terminal bool
+		prefix   string
+	}{
+		{"ascii dark", ASCII, gray, color.Black, true, "::\n::\n"},
+		{"ascii light", ASCII, gray, color.White, true, "##\n##\n"},
+		{"ascii unknown", ASCII, gray, nil, true, "::\n::\n"},
+		{"ascii not terminal", ASCII, gray, color.White, false, "::\n::\n"},
+		{"halfblock light", HalfBlock, red, color.White, true, "\x1b[38;2;255;127;127;48;2;255;127;127m▀"},
+		{"halfblock unknown", HalfBlock, red, nil, true, "\x1b[38;2;255;0;0;48;2;255;0;0m▀"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			terminalBackground = func() (color.Color, error) {
+				if tt.bg == nil {
+					return nil, errors.New("no reply")
+				}
+				return tt.bg, nil
+			}
+			var w interface {
+				io.Writer
+				String() string
+			} = new(bytes.Buffer)
+			if tt.terminal {
+				w = new(fakeTerminal)
+			}
+			enc := &Encoder{out: w, options: []Option{Width(Cells(2))}, protocol: tt.p}
+			if err := enc.Encode(bytes.NewReader(tt.img)); err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if got := w.String(); !strings.HasPrefix(got, tt.prefix) {
+				t.Fatalf("expected %q...; got %q", tt.prefix, got)
+			}
+		})
+	}
+}
+
+func TestRenderTrace(t *testing.T) {
+	var events []Event
+	tracer := TracerFunc(func(e Event) { events = append(events, e) })
+	enc := &Encoder{out: new(bytes.Buffer), options: []Option{Trace(tracer), Passthrough(true)}, protocol: HalfBlock}
+	if err := enc.Encode(bytes.NewReader(testPNG(t, 2, 2, color.Black))); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if e := events[0]; e.Protocol != "halfblock" || e.Tmux {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	events = nil
+	enc = &Encoder{out: new(bytes.Buffer), options: []Option{Trace(tracer)}, protocol: Skip}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if e := events[0]; e.Protocol != "skip" || len(e.Notes) != 1 || e.Notes[0] != "image skipped" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+
+func TestCells(t *testing.T) {
+	tc := []struct {
+		opts       []Option
+		cols, rows int
+	}{
+		{nil, 0, 0},
+		{[]Option{Width(Cells(10)), Height(Auto())}, 10, 0},
+		{[]Option{Width(Pixels(20)), Height(Pixels(32))}, 3, 2},
+		{[]Option{Height(Cells(5)), Height(Cells(7))}, 0, 7},
+	}
+	for _, tt := range tc {
+		cols, rows := newConfig(tt.opts).cells()
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("expected %dx%d cells for %v; got %dx%d", tt.cols, tt.rows, newConfig(tt.opts).args, cols, rows)
+		}
+	}
+}
+
+func TestFitCells(t *testing.T) {
+	tc := []struct {
+		name       string
+		size       image.Point
+		cols, rows int
+		preserve   bool
+		wantCols   int
+		wantRows   int
+	}{
+		{"natural", image.Pt(40, 64), 0, 0, true, 5, 4},
+		{"too wide", image.Pt(1600, 160), 0, 0, true, 80, 4},
+		{"width", image.Pt(40, 64), 10, 0, true, 10, 8},
+		{"height", image.Pt(40, 64), 0, 2, true, 2, 2},
+		{"fit width", image.Pt(80, 16), 5, 5, true, 5, 1},
+		{"fit height", image.Pt(16, 160), 5, 5, true, 1, 5},
+		{"fill", image.Pt(16, 160), 5, 5, false, 5, 5},
+		{"tiny", image.Pt(1, 1), 0, 0, true, 1, 1},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := fitCells(tt.size, cellWidth, cellHeight, tt.cols, tt.rows, 80, tt.preserve)
+			if cols != tt.wantCols || rows != tt.wantRows {
+				t.Fatalf("expected %dx%d; got %dx%d", tt.wantCols, tt.wantRows, cols, rows)
+			}
+		})
+	}
+}
+
+func TestSilentOnUnsupported(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return false }
+	defer func(old func(Protocol, io.Writer) bool) { supports = old }(supports)
+	supports = func(Protocol, io.Writer) bool { return false }
+
+	if _, err := NewEncoder(new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+	if _, err := NewEncoder(new(bytes.Buffer), FallbackChain(Kitty)); !errors.Is(err, ErrNoProtocol) {
+		t.Fatalf("expected ErrNoProtocol; got %v", err)
+	}
+
+	img := testPNG(t, 800, 600, color.White)
+	size := byteCount(len(img))
+	tc := []struct {
+		name    string
+		opts    []Option
+		payload []byte
+		want    string
+	}{
+		{"named", []Option{Name("foo.png")}, img, "[image: foo.png 800x600 PNG " + size + "]\n"},
+		{"unnamed", nil, img, "[image: 800x600 PNG " + size + "]\n"},
+		{"not an image", []Option{Name("notes.txt")}, []byte("test"), "[image: notes.txt 4 B]\n"},
+		{"only size", nil, []byte("test"), "[image: 4 B]\n"},
+		{"chain", []Option{FallbackChain(Kitty), Name("foo.png")}, img, "[image: foo.png 800x600 PNG " + size + "]\n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc, err := NewEncoder(buf, append(tt.opts, SilentOnUnsupported())...)
+			if err != nil {
+				t.Fatalf("could not create encoder: %v", err)
+			}
+			if enc.Protocol() != Summary {
+				t.Fatalf("expected protocol summary; got %s", enc.Protocol())
+			}
+			if err := enc.Encode(bytes.NewReader(tt.payload)); err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+
+	// Supported protocols are still used.
+	supports = func(p Protocol, w io.Writer) bool { return p == ASCII }
+	enc, err := NewEncoder(new(bytes.Buffer), FallbackChain(Kitty, ASCII), SilentOnUnsupported())
+	if err != nil || enc.Protocol() != ASCII {
+		t.Fatalf("expected ascii encoder; got %v, %v", enc, err)
+	}
+}
+
+func TestNopEncoder(t *testing.T) {
+	enc := NewNopEncoder()
+	if enc.Protocol() != Skip {
+		t.Fatalf("expected protocol skip; got %s", enc.Protocol())
+	}
+	r := bytes.NewReader(testPNG(t, 2, 2, color.White))
+	if err := enc.Encode(r); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if r.Len() != 0 {
+		t.Fatalf("expected the payload to be read; %d bytes left", r.Len())
+	}
+}
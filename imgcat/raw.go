@@ -0,0 +1,98 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// A PixelFormat describes the memory layout of the pixels of raw buffers.
+type PixelFormat int
+
+// Supported pixel formats, with 8 bits per channel. Alpha is not
+// premultiplied.
+const (
+	RGBA PixelFormat = iota
+	RGB
+	BGRA // Common in framebuffers and screen capture APIs.
+	Gray
+)
+
+func (f PixelFormat) bytesPerPixel() int {
+	switch f {
+	case RGBA, BGRA:
+		return 4
+	case RGB:
+		return 3
+	case Gray:
+		return 1
+	}
+	return 0
+}
+
+// Raw returns the w by h image stored in pix, with rows stored one after the
+// other in the given pixel format. RGBA and Gray buffers are used in place,
+// other formats are converted.
+func Raw(pix []byte, w, h int, format PixelFormat) (image.Image, error) {
+	bpp := format.bytesPerPixel()
+	if bpp == 0 {
+		return nil, fmt.Errorf("unknown pixel format %d", format)
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid image size %dx%d", w, h)
+	}
+	if n := w * h * bpp; len(pix) < n {
+		return nil, fmt.Errorf("raw buffer too short for a %dx%d image: got %d bytes, expected %d", w, h, len(pix), n)
+	}
+
+	rect := image.Rect(0, 0, w, h)
+	switch format {
+	case RGBA:
+		return &image.NRGBA{Pix: pix, Stride: 4 * w, Rect: rect}, nil
+	case Gray:
+		return &image.Gray{Pix: pix, Stride: w, Rect: rect}, nil
+	}
+	img := image.NewNRGBA(rect)
+	for i, j := 0, 0; i < len(img.Pix); i, j = i+4, j+bpp {
+		switch format {
+		case RGB:
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = pix[j], pix[j+1], pix[j+2], 0xff
+		case BGRA:
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = pix[j+2], pix[j+1], pix[j], pix[j+3]
+		}
+	}
+	return img, nil
+}
+
+// rawEncoder favors speed, since raw buffers are usually frames of a stream.
+var rawEncoder = png.Encoder{CompressionLevel: png.BestSpeed}
+
+// EncodeRaw displays a raw pixel buffer, see Raw, which is losslessly encoded
+// as PNG. This is useful to stream frames from emulators, renderers, or screen
+// captures, without a round trip through an image file.
+// iTerm2 only accepts image files, so the buffer can't be sent as is.
+func (enc *Encoder) EncodeRaw(pix []byte, w, h int, format PixelFormat) error {
+	img, err := Raw(pix, w, h, format)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := rawEncoder.Encode(buf, img); err != nil {
+		return fmt.Errorf("could not encode raw image: %v", err)
+	}
+	return enc.Encode(buf)
+}
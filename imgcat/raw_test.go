@@ -0,0 +1,81 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	want := color.NRGBA{0x10, 0x20, 0x30, 0x80}
+	tc := []struct {
+		name   string
+		pix    []byte
+		format PixelFormat
+		want   color.Color
+	}{
+		{"rgba", []byte{0x10, 0x20, 0x30, 0x80}, RGBA, want},
+		{"bgra", []byte{0x30, 0x20, 0x10, 0x80}, BGRA, want},
+		{"rgb", []byte{0x10, 0x20, 0x30}, RGB, color.NRGBA{0x10, 0x20, 0x30, 0xff}},
+		{"gray", []byte{0x40}, Gray, color.Gray{0x40}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			// A 2x1 image whose second pixel is the one being checked.
+			pix := append(make([]byte, len(tt.pix)), tt.pix...)
+			img, err := Raw(pix, 2, 1, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.At(1, 0); got != tt.want {
+				t.Fatalf("expected %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRawErrors(t *testing.T) {
+	tc := []struct {
+		name   string
+		pix    []byte
+		w, h   int
+		format PixelFormat
+	}{
+		{"short buffer", make([]byte, 15), 2, 2, RGBA},
+		{"empty image", nil, 0, 2, RGB},
+		{"unknown format", make([]byte, 16), 2, 2, PixelFormat(42)},
+	}
+	for _, tt := range tc {
+		if _, err := Raw(tt.pix, tt.w, tt.h, tt.format); err == nil {
+			t.Errorf("%s: expected error; got nothing", tt.name)
+		}
+	}
+}
+
+func TestEncodeRaw(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Passthrough(false), Newline(false)}}
+	pix := []byte{0xff, 0, 0, 0xff, 0, 0xff, 0, 0xff}
+	if err := enc.EncodeRaw(pix, 2, 1, RGBA); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	out := strings.TrimSuffix(strings.TrimPrefix(buf.String(), "\x1b]1337;File="), "\a")
+	i := strings.Index(out, ":")
+	if i < 0 {
+		t.Fatalf("unexpected output %q", buf)
+	}
+	b, err := base64.StdEncoding.DecodeString(out[i+1:])
+	if err != nil {
+		t.Fatalf("could not decode payload: %v", err)
+	}
+	if want := fmt.Sprintf("size=%d", len(b)); out[:i] != want {
+		t.Errorf("expected arguments %s; got %s", want, out[:i])
+	}
+	img := decode(t, bytes.NewReader(b))
+	if got := color.NRGBAModel.Convert(img.At(1, 0)); got != (color.NRGBA{0, 0xff, 0, 0xff}) {
+		t.Fatalf("expected green pixel; got %v", got)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import "os"
+
+// Defaults applied by Adaptive to remote sessions.
+const (
+	// RemotePixels is the number of pixels images are downsampled to, enough
+	// to fill a 1080p screen.
+	RemotePixels = 1920 * 1080
+	// RemoteBytes is the size images are re-encoded to fit in.
+	RemoteBytes = 512 * 1024
+)
+
+// IsRemote reports whether the session runs over SSH, in which case images are
+// sent over the network to reach the terminal.
+func IsRemote() bool { return isRemote() }
+
+// Can be swapped for testing.
+var isRemote = func() bool {
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Adaptive picks defaults depending on where the terminal is. Local sessions
+// get images at full quality, while remote ones, as reported by IsRemote, get
+// them downsampled to RemotePixels and re-encoded to fit in RemoteBytes.
+// Use TargetBytes after Adaptive to change the size budget.
+func Adaptive() Option {
+	return func(c *config) {
+		if !IsRemote() {
+			return
+		}
+		Downsample(RemotePixels)(c)
+		TargetBytes(RemoteBytes)(c)
+	}
+}
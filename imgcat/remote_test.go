@@ -0,0 +1,45 @@
+package imgcat
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsRemote(t *testing.T) {
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		defer func(key, old string) { check(t, os.Setenv(key, old)) }(key, os.Getenv(key))
+		check(t, os.Unsetenv(key))
+	}
+	if IsRemote() {
+		t.Fatalf("expected local session without SSH variables")
+	}
+	check(t, os.Setenv("SSH_TTY", "/dev/pts/3"))
+	if !IsRemote() {
+		t.Fatalf("expected remote session with SSH_TTY set")
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	defer func(old func() bool) { isRemote = old }(isRemote)
+
+	tc := []struct {
+		name       string
+		remote     bool
+		options    []Option
+		transforms int
+		target     int
+	}{
+		{"local", false, []Option{Adaptive()}, 0, 0},
+		{"remote", true, []Option{Adaptive()}, 1, RemoteBytes},
+		{"remote with target", true, []Option{Adaptive(), TargetBytes(1000)}, 1, 1000},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			isRemote = func() bool { return tt.remote }
+			c := newConfig(tt.options)
+			if len(c.transforms) != tt.transforms || c.targetBytes != tt.target {
+				t.Fatalf("expected %d transforms and target %d; got %d and %d", tt.transforms, tt.target, len(c.transforms), c.targetBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// A Renderer displays images with a protocol imgcat doesn't implement, such as
+// the one of a proprietary terminal, by writing p to w, see RegisterRenderer.
+type Renderer func(w io.Writer, p Payload) error
+
+// A Payload is an image given to a Renderer, once transformed, re-encoded,
+// or scaled down as the options of the Encoder say.
+type Payload struct {
+	// Data of the image, in Format.
+	Data   []byte
+	Format Format
+	// Name of the image, given with the Name option, or empty.
+	Name string
+	// Cols and Rows are the width and height the image is displayed with in
+	// cells, given with the Width and Height options, zero meaning any.
+	Cols, Rows int
+	// PreserveAspectRatio reports whether the image keeps its aspect ratio
+	// when both Cols and Rows are given.
+	PreserveAspectRatio bool
+	// Tmux reports whether escape sequences need to be wrapped for tmux, see
+	// Passthrough.
+	Tmux bool
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[Protocol]Renderer{}
+)
+
+// builtin lists the protocols implemented by imgcat.
+var builtin = map[Protocol]bool{
+	ITerm2: true, Kitty: true, Sixel: true, HalfBlock: true, ASCII: true, Summary: true, Skip: true,
+}
+
+// RegisterRenderer registers r as the protocol name, used by Encoders whose
+// FallbackChain has Protocol(name), or by all Encoders when EnvProtocol is
+// name. Unlike the graphics protocols, a renderer can't be detected, so it's
+// used whenever it comes up in a fallback chain, unless EnvProtocol forces
+// another protocol. RegisterRenderer replaces the renderer registered as
+// name if any, and panics if name is a protocol implemented by imgcat.
+func RegisterRenderer(name string, r Renderer) {
+	p := Protocol(name)
+	if builtin[p] || name == "none" || name == "" {
+		panic(fmt.Sprintf("imgcat: can't register a renderer as %q", name))
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if r == nil {
+		delete(renderers, p)
+		return
+	}
+	renderers[p] = r
+}
+
+// Renderers returns the names of the registered renderers, sorted.
+func Renderers() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	var names []string
+	for p := range renderers {
+		names = append(names, string(p))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rendererOf returns the renderer registered as p, nil if none.
+func rendererOf(p Protocol) Renderer {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	return renderers[p]
+}
+
+// renderCustom displays the image in data with the renderer r.
+func (enc *Encoder) renderCustom(cfg *config, r Renderer, data []byte) error {
+	cols, rows := cfg.cells()
+	p := Payload{
+		Data:                data,
+		Format:              Sniff(data),
+		Name:                cfg.name(),
+		Cols:                cols,
+		Rows:                rows,
+		PreserveAspectRatio: cfg.preserveAspectRatio(),
+		Tmux:                cfg.tmux(),
+	}
+	if _, err := io.WriteString(enc.out, cfg.linkStart()); err != nil {
+		return err
+	}
+	if err := r(enc.out, p); err != nil {
+		return err
+	}
+	_, err := io.WriteString(enc.out, cfg.linkEnd()+cfg.newline())
+	return err
+}
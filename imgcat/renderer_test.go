@@ -0,0 +1,122 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// register registers a renderer writing what it's given as "mine", until the
+// test ends.
+func register(t *testing.T, got *[]Payload) {
+	RegisterRenderer("mine", func(w io.Writer, p Payload) error {
+		*got = append(*got, p)
+		_, err := fmt.Fprintf(w, "<%s %dx%d>", p.Format, p.Cols, p.Rows)
+		return err
+	})
+	t.Cleanup(func() { RegisterRenderer("mine", nil) })
+}
+
+func TestRenderer(t *testing.T) {
+	var got []Payload
+	register(t, &got)
+	if names := Renderers(); !reflect.DeepEqual(names, []string{"mine"}) {
+		t.Fatalf("expected the renderers [mine]; got %v", names)
+	}
+
+	data := testPNG(t, 8, 8, color.White)
+	var sum []byte
+	buf := new(bytes.Buffer)
+	t.Setenv(EnvProtocol, "")
+	enc, err := NewEncoder(buf, FallbackChain("mine", ASCII), Name("a.png"), Width(Cells(10)), Height(Cells(4)),
+		Passthrough(false), Checksum(func(s []byte) { sum = s }))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if enc.Protocol() != "mine" {
+		t.Fatalf("expected the renderer to be picked; got %s", enc.Protocol())
+	}
+	if err := enc.Encode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if want := "<png 10x4>\n"; buf.String() != want {
+		t.Errorf("expected %q; got %q", want, buf.String())
+	}
+	want := Payload{Data: data, Format: PNG, Name: "a.png", Cols: 10, Rows: 4, PreserveAspectRatio: true}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("expected the renderer to be given %+v; got %+v", want, got)
+	}
+	if sum == nil {
+		t.Errorf("expected the checksum to be reported")
+	}
+}
+
+func TestRendererPicked(t *testing.T) {
+	var got []Payload
+	register(t, &got)
+	tc := []struct {
+		name   string
+		env    string
+		chain  []Protocol
+		picked Protocol
+	}{
+		{"in the chain", "", []Protocol{"mine", ITerm2}, "mine"},
+		{"after another", "", []Protocol{ASCII, "mine"}, ASCII},
+		{"forced", "mine", []Protocol{ITerm2, "mine"}, "mine"},
+		{"another forced", "iterm2", []Protocol{"mine", ITerm2}, ITerm2},
+		{"none forced", "none", []Protocol{"mine", ASCII}, ASCII},
+		{"forced without chain", "mine", nil, "mine"},
+		{"unregistered", "", []Protocol{"theirs", ASCII}, ASCII},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(EnvProtocol, c.env)
+			options := []Option{}
+			if c.chain != nil {
+				options = append(options, FallbackChain(c.chain...))
+			}
+			enc, err := NewEncoder(new(bytes.Buffer), options...)
+			if err != nil {
+				t.Fatalf("could not create encoder: %v", err)
+			}
+			if enc.Protocol() != c.picked {
+				t.Errorf("expected %s to be picked; got %s", c.picked, enc.Protocol())
+			}
+		})
+	}
+
+	t.Setenv(EnvProtocol, "mine")
+	if _, err := DefaultOptionsFromEnv(); err != nil {
+		t.Errorf("expected a registered renderer in %s to be valid; got %v", EnvProtocol, err)
+	}
+	t.Setenv(EnvProtocol, "theirs")
+	if _, err := DefaultOptionsFromEnv(); err == nil {
+		t.Errorf("expected an unregistered renderer in %s to be invalid", EnvProtocol)
+	}
+}
+
+func TestRendererFails(t *testing.T) {
+	RegisterRenderer("broken", func(io.Writer, Payload) error { return errors.New("broken") })
+	defer RegisterRenderer("broken", nil)
+	enc := &Encoder{out: new(bytes.Buffer), protocol: "broken"}
+	if err := enc.Encode(bytes.NewReader(testPNG(t, 2, 2, color.Black))); err == nil || err.Error() != "broken" {
+		t.Errorf("expected the error of the renderer; got %v", err)
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	for _, name := range []string{"iterm2", "ascii", "none", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected registering %q to panic", name)
+				}
+			}()
+			RegisterRenderer(name, func(io.Writer, Payload) error { return nil })
+		}()
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report composes headings, text, tables, and images into documents
+// printed to the terminal with consistent margins, such as the visual
+// summaries of test runs or benchmarks. Parts of a report can be laid out
+// side by side in columns.
+//
+//	r := &report.Report{Margin: 2}
+//	r.Heading("Benchmarks").Text("Allocations went down by 12%.")
+//	r.Columns(new(report.Report).Image(chart, 10), new(report.Report).Table(results))
+//	err := r.Render(os.Stdout)
+//
+// Images are sent with the imgcat package, and drawn without moving the
+// cursor, which needs iTerm2 3.5 or later.
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/campoy/tools/imgcat/termtable"
+	"github.com/campoy/tools/imgcat/textwidth"
+)
+
+// columnGap is the number of cells between columns.
+const columnGap = 2
+
+// A Report is a document made of sections, rendered one below the other with
+// a blank line between them. Its methods adding sections return the report,
+// so they can be chained.
+type Report struct {
+	// Width is the width of the report in cells, margins included. Zero
+	// means the width of the terminal, or of the tmux pane, or 80 if
+	// unknown. Reports in columns take the width of their column.
+	Width int
+	// Margin is the number of cells left blank on the left and on the right
+	// of every section.
+	Margin int
+	// Options are used to encode the images, in addition to the ones sizing
+	// them, such as imgcat.MaxPixels. Reports in columns use the options of
+	// the report holding them too.
+	Options []imgcat.Option
+
+	sections []section
+}
+
+// A section returns the lines of a part of a report, each covering exactly
+// width cells, with images encoded with options.
+type section func(width int, options []imgcat.Option) ([]string, error)
+
+// Can be swapped for testing.
+var terminalWidth = func() int {
+	if s, err := termsize.Get(); err == nil && s.Cols > 0 {
+		return s.Cols
+	}
+	return 80
+}
+
+// Heading adds a title in bold, underlined across the report.
+func (r *Report) Heading(title string) *Report {
+	return r.add(func(width int, _ []imgcat.Option) ([]string, error) {
+		title := textwidth.Truncate(title, width)
+		return []string{
+			"\x1b[1m" + title + "\x1b[0m" + pad(title, width),
+			strings.Repeat("─", width),
+		}, nil
+	})
+}
+
+// Text adds text wrapped to the width of the report, see imgcat.Flow.
+// Newlines start new paragraphs.
+func (r *Report) Text(text string) *Report {
+	return r.add(func(width int, _ []imgcat.Option) ([]string, error) {
+		lines := imgcat.Flow(text, width, 0, 0)
+		for i, line := range lines {
+			lines[i] = line + pad(line, width)
+		}
+		return lines, nil
+	})
+}
+
+// Table adds a table. It's narrowed to the width of the report if its
+// MaxWidth is zero or wider, and its images are encoded with the options of
+// the report followed by its own.
+func (r *Report) Table(t *termtable.Table) *Report {
+	return r.add(func(width int, options []imgcat.Option) ([]string, error) {
+		table := *t
+		if table.MaxWidth <= 0 || table.MaxWidth > width {
+			table.MaxWidth = width
+		}
+		table.Options = append(options[:len(options):len(options)], t.Options...)
+		buf := new(bytes.Buffer)
+		if err := table.Render(buf); err != nil {
+			return nil, err
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		// Rows holding images skip the cells they cover, so lines are padded
+		// after the top border, which is only text.
+		padding := pad(lines[0], width)
+		for i := range lines {
+			lines[i] += padding
+		}
+		return lines, nil
+	})
+}
+
+// Image adds the given image file, such as a PNG or JPEG, rows cells high and
+// at most as wide as the report, keeping its aspect ratio.
+func (r *Report) Image(data []byte, rows int) *Report {
+	if rows < 1 {
+		rows = 1
+	}
+	return r.add(func(width int, options []imgcat.Option) ([]string, error) {
+		options = append(options[:len(options):len(options)],
+			imgcat.Inline(true), imgcat.Width(imgcat.Cells(width)), imgcat.Height(imgcat.Cells(rows)),
+			imgcat.PreserveAspectRatio(true), imgcat.MoveCursor(false), imgcat.Newline(false))
+		img, err := imgcat.EncodeToBytes(bytes.NewReader(data), options...)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode image: %v", err)
+		}
+		// Skip the cells covered by the image, rather than overwriting them
+		// with spaces.
+		skip := fmt.Sprintf("\x1b[%dC", width)
+		lines := make([]string, rows)
+		for i := range lines {
+			lines[i] = skip
+		}
+		lines[0] = string(img) + skip
+		return lines, nil
+	})
+}
+
+// Columns adds the given reports side by side, in columns of the same width
+// with two cells between them. Shorter columns are padded with blank lines.
+func (r *Report) Columns(reports ...*Report) *Report {
+	return r.add(func(width int, options []imgcat.Option) ([]string, error) {
+		n := len(reports)
+		if n == 0 {
+			return nil, nil
+		}
+		colWidth := (width - columnGap*(n-1)) / n
+		if colWidth < 1 {
+			colWidth = 1
+		}
+		cols, widths, height := make([][]string, n), make([]int, n), 0
+		for i, c := range reports {
+			widths[i] = colWidth
+			if i == n-1 {
+				// The last column takes the cells left by the division.
+				widths[i] = width - (colWidth+columnGap)*(n-1)
+			}
+			col, err := c.lines(widths[i], options)
+			if err != nil {
+				return nil, fmt.Errorf("column %d: %v", i+1, err)
+			}
+			if len(col) > height {
+				height = len(col)
+			}
+			cols[i] = col
+		}
+		gap := strings.Repeat(" ", columnGap)
+		lines := make([]string, height)
+		for y := range lines {
+			for i, col := range cols {
+				if i > 0 {
+					lines[y] += gap
+				}
+				if y < len(col) {
+					lines[y] += col[y]
+				} else {
+					lines[y] += strings.Repeat(" ", widths[i])
+				}
+			}
+		}
+		return lines, nil
+	})
+}
+
+// Render writes the report to w.
+func (r *Report) Render(w io.Writer) error {
+	width := r.Width
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	lines, err := r.lines(width, nil)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	for _, line := range lines {
+		buf.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func (r *Report) add(s section) *Report {
+	r.sections = append(r.sections, s)
+	return r
+}
+
+// lines returns the lines of the report in width cells, with images encoded
+// with options followed by the ones of the report.
+func (r *Report) lines(width int, options []imgcat.Option) ([]string, error) {
+	options = append(options[:len(options):len(options)], r.Options...)
+	inner := width - 2*r.Margin
+	if inner < 1 {
+		inner = 1
+	}
+	margin := strings.Repeat(" ", r.Margin)
+	right := strings.Repeat(" ", width-inner-r.Margin)
+	var lines []string
+	for i, s := range r.sections {
+		section, err := s(inner, options)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %v", i+1, err)
+		}
+		if i > 0 {
+			lines = append(lines, strings.Repeat(" ", width))
+		}
+		for _, line := range section {
+			lines = append(lines, margin+line+right)
+		}
+	}
+	return lines, nil
+}
+
+// pad returns the spaces completing s to width cells.
+func pad(s string, width int) string {
+	if n := width - textwidth.Width(s); n > 0 {
+		return strings.Repeat(" ", n)
+	}
+	return ""
+}
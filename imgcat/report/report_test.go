@@ -0,0 +1,130 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termtable"
+)
+
+func testPNG(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderText(t *testing.T) {
+	tc := []struct {
+		name   string
+		report *Report
+		out    string
+	}{
+		{"heading", (&Report{Width: 12}).Heading("Results").Text("all tests passed"), `
+` + "\x1b[1mResults\x1b[0m" + `
+────────────
+
+all tests
+passed
+`},
+		{"margin", (&Report{Width: 13, Margin: 2}).Text("the quick brown fox"), `
+  the quick
+  brown fox
+`},
+		{"long heading", (&Report{Width: 4}).Heading("benchmarks"), "\n\x1b[1mbenc\x1b[0m\n────\n"},
+		{"columns", (&Report{Width: 10}).Columns(
+			new(Report).Text("a b c d"),
+			new(Report).Text("e"),
+		), `
+a b   e
+c d
+`},
+		{"columns with margins", (&Report{Width: 14, Margin: 1}).Columns(
+			new(Report).Text("x"),
+			(&Report{Margin: 1}).Text("y z w"),
+		), `
+ x       y z
+         w
+`},
+		{"table", (&Report{Width: 20, Margin: 2}).Table(&termtable.Table{
+			Rows: [][]termtable.Cell{{termtable.Text("name"), termtable.Text("ns/op")}},
+		}), `
+  ┌──────┬───────┐
+  │ name │ ns/op │
+  └──────┴───────┘
+`},
+		{"narrowed table", (&Report{Width: 12}).Table(&termtable.Table{
+			Rows: [][]termtable.Cell{{termtable.Text("a"), termtable.Text("the quick")}},
+		}), `
+┌───┬──────┐
+│ a │ the  │
+│   │ quic │
+│   │ k    │
+└───┴──────┘
+`},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := tt.report.Render(buf); err != nil {
+				t.Fatalf("could not render: %v", err)
+			}
+			if want := strings.TrimPrefix(tt.out, "\n"); buf.String() != want {
+				t.Errorf("expected report\n%q\ngot\n%q", want, buf)
+			}
+		})
+	}
+}
+
+func TestRenderImages(t *testing.T) {
+	r := &Report{Width: 20, Margin: 1, Options: []imgcat.Option{imgcat.Passthrough(false)}}
+	r.Columns(new(Report).Image(testPNG(t), 2), new(Report).Text("cat"))
+	buf := new(bytes.Buffer)
+	if err := r.Render(buf); err != nil {
+		t.Fatalf("could not render: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines; got %q", lines)
+	}
+	img, err := imgcat.EncodeToBytes(bytes.NewReader(testPNG(t)), imgcat.Passthrough(false),
+		imgcat.Inline(true), imgcat.Width(imgcat.Cells(8)), imgcat.Height(imgcat.Cells(2)),
+		imgcat.PreserveAspectRatio(true), imgcat.MoveCursor(false), imgcat.Newline(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " " + string(img) + "\x1b[8C  cat"; lines[0] != want {
+		t.Errorf("expected the image beside the text\n%q\ngot\n%q", want, lines[0])
+	}
+	// The cells covered by the image are skipped, not overwritten.
+	if want := " \x1b[8C"; lines[1] != want {
+		t.Errorf("expected the second line to skip the image %q; got %q", want, lines[1])
+	}
+}
+
+func TestRenderWidth(t *testing.T) {
+	defer func(old func() int) { terminalWidth = old }(terminalWidth)
+	terminalWidth = func() int { return 6 }
+
+	buf := new(bytes.Buffer)
+	if err := new(Report).Text("one two three").Render(buf); err != nil {
+		t.Fatalf("could not render: %v", err)
+	}
+	if want := "one\ntwo\nthree\n"; buf.String() != want {
+		t.Errorf("expected text wrapped to the terminal %q; got %q", want, buf)
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	r := new(Report).Text("ok").Columns(new(Report), new(Report).Image(testPNG(t), 2))
+	r.Options = []imgcat.Option{imgcat.MaxPixels(1)}
+	err := r.Render(new(bytes.Buffer))
+	if err == nil || !strings.Contains(err.Error(), "section 2: column 2") {
+		t.Errorf("expected an error locating the image; got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+)
+
+// Resize scales img to w by h pixels. Each destination pixel is the average of
+// the source pixels it covers, which gives good results when shrinking.
+func Resize(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := span(b.Min.Y, b.Dy(), y, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := span(b.Min.X, b.Dx(), x, w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// span returns the range of source coordinates covered by the i-th of n
+// destination pixels, given a source starting at min with the given length.
+func span(min, length, i, n int) (int, int) {
+	from := min + i*length/n
+	to := min + (i+1)*length/n
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
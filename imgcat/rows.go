@@ -0,0 +1,85 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+
+	"github.com/campoy/tools/imgcat/termsize"
+)
+
+// MaxRows keeps images at most n rows tall, so they never push the prompt
+// out of view: taller ones get a height of n cells, and are scaled down
+// keeping their aspect ratio. When n is zero or negative, the limit is the
+// number of rows of the terminal, or of the tmux pane, minus -n, such as
+// MaxRows(-2) to keep two rows for the prompt.
+//
+// The rows an image sent to the terminal takes are estimated from its size in
+// pixels and its width, assuming cells of 8 by 16 pixels. Payloads that are
+// not in a known image format are sent unchanged.
+func MaxRows(n int) Option {
+	return func(c *config) {
+		c.maxRows = n
+		c.limitRows = true
+	}
+}
+
+// Can be swapped for testing.
+var terminalRows = func() int {
+	if s, err := termsize.Get(); err == nil && s.Rows > 0 {
+		return s.Rows
+	}
+	return 24
+}
+
+// rowLimit returns the height limit given with MaxRows, and whether there is
+// one.
+func (c *config) rowLimit() (int, bool) {
+	if !c.limitRows {
+		return 0, false
+	}
+	max := c.maxRows
+	if max <= 0 {
+		max += terminalRows()
+	}
+	if max < 1 {
+		max = 1
+	}
+	return max, true
+}
+
+// fitRows sets the height of an image of the given size in pixels to the
+// limit given with MaxRows, if it would be taller.
+func (c *config) fitRows(size image.Point) {
+	max, ok := c.rowLimit()
+	if !ok {
+		return
+	}
+	cols, rows := c.cells()
+	_, rows = fitCells(size, cellWidth, cellHeight, cols, rows, terminalCols(), c.preserveAspectRatio())
+	if rows > max {
+		c.trace.notef("%d rows high, limited to %d", rows, max)
+		c.setArg("height", string(Cells(max)))
+	}
+}
+
+// limitCells returns the size in cells of an image drawn with text, as given
+// by fitCells, scaled down to the limit given with MaxRows if it's taller.
+// Such images take more rows than the estimate of fitRows.
+func (c *config) limitCells(size image.Point, cw, ch, cols, rows, maxCols int) (int, int) {
+	if max, ok := c.rowLimit(); ok && rows > max {
+		return fitCells(size, cw, ch, cols, max, maxCols, true)
+	}
+	return cols, rows
+}
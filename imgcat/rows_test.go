@@ -0,0 +1,71 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestMaxRows(t *testing.T) {
+	defer func(old func() int) { terminalRows = old }(terminalRows)
+	terminalRows = func() int { return 24 }
+
+	tc := []struct {
+		name    string
+		data    []byte
+		options []Option
+		height  string
+	}{
+		{"taller", testPNG(t, 80, 320, color.White), []Option{MaxRows(10)}, "height=10"},
+		{"short", testPNG(t, 16, 16, color.White), []Option{MaxRows(10)}, ""},
+		{"wide enough", testPNG(t, 400, 400, color.White), []Option{Width(Cells(40)), MaxRows(30)}, ""},
+		{"too wide", testPNG(t, 400, 400, color.White), []Option{Width(Cells(40)), MaxRows(15)}, "height=15"},
+		{"pixels", testPNG(t, 400, 400, color.White), []Option{Width(Pixels(400)), MaxRows(15)}, "height=15"},
+		{"relative", testPNG(t, 8, 640, color.White), []Option{MaxRows(-4)}, "height=20"},
+		{"whole terminal", testPNG(t, 8, 640, color.White), []Option{MaxRows(0)}, "height=24"},
+		{"height given", testPNG(t, 16, 16, color.White), []Option{Height(Cells(50)), MaxRows(10)}, "height=10"},
+		{"smaller height given", testPNG(t, 80, 320, color.White), []Option{Height(Cells(5)), MaxRows(10)}, "height=5"},
+		{"not an image", []byte("test"), []Option{MaxRows(1)}, ""},
+		{"no limit", testPNG(t, 8, 640, color.White), nil, ""},
+	}
+	for _, c := range tc {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := EncodeToBytes(bytes.NewReader(c.data), append(c.options, Passthrough(false))...)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			s := string(out)
+			header := s[:strings.Index(s, ":")]
+			var height string
+			for _, arg := range strings.Split(header[strings.Index(header, "=")+1:], ";") {
+				if strings.HasPrefix(arg, "height=") {
+					height = arg
+				}
+			}
+			if height != c.height {
+				t.Errorf("expected %q; got %q in %q", c.height, height, header)
+			}
+			payload := strings.TrimSuffix(s[len(header)+1:], "\a\n")
+			if want := base64.StdEncoding.EncodeToString(c.data); payload != want {
+				t.Errorf("expected the payload to be sent unchanged")
+			}
+		})
+	}
+}
+
+func TestMaxRowsText(t *testing.T) {
+	// Images drawn with text take two pixels per row, more than estimated for
+	// images sent to the terminal.
+	for _, p := range []Protocol{HalfBlock, ASCII} {
+		buf := new(bytes.Buffer)
+		enc := &Encoder{out: buf, options: []Option{MaxRows(5)}, protocol: p}
+		if err := enc.Encode(bytes.NewReader(testPNG(t, 8, 64, color.White))); err != nil {
+			t.Fatalf("could not encode with %s: %v", p, err)
+		}
+		if rows := strings.Count(buf.String(), "\n"); rows != 5 {
+			t.Errorf("expected 5 rows with %s; got %d", p, rows)
+		}
+	}
+}
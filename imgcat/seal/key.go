@@ -0,0 +1,195 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seal
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EnvKey is the variable holding the Key shared by the ends of relays,
+// encoded with base64.
+const EnvKey = "IMGCAT_KEY"
+
+// ErrNoKey is returned by LoadKey when no key is configured.
+var ErrNoKey = errors.New("no key in " + EnvKey + " or the keychain")
+
+// A Key seals and opens streams.
+type Key [32]byte
+
+// GenerateKey returns a new random key.
+func GenerateKey() (Key, error) {
+	var k Key
+	if _, err := rand.Read(k[:]); err != nil {
+		return k, fmt.Errorf("could not generate key: %v", err)
+	}
+	return k, nil
+}
+
+// ParseKey parses a key encoded with base64, as given in IMGCAT_KEY.
+func ParseKey(s string) (Key, error) {
+	var k Key
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != len(k) {
+		return k, fmt.Errorf("invalid key, expected %d bytes encoded with base64", len(k))
+	}
+	copy(k[:], b)
+	return k, nil
+}
+
+// String returns the key encoded with base64, as given in IMGCAT_KEY.
+func (k Key) String() string { return base64.StdEncoding.EncodeToString(k[:]) }
+
+// LoadKey returns the key given in IMGCAT_KEY, or else the one kept in the
+// keychain of the system with StoreKey, or ErrNoKey if there's none.
+func LoadKey() (Key, error) {
+	if s := os.Getenv(EnvKey); s != "" {
+		return ParseKey(s)
+	}
+	s, err := keychain.load()
+	if err != nil {
+		return Key{}, err
+	}
+	if s == "" {
+		return Key{}, ErrNoKey
+	}
+	return ParseKey(s)
+}
+
+// StoreKey keeps k in the keychain of the system, replacing the key kept
+// there if any, for LoadKey.
+func StoreKey(k Key) error {
+	err := keychain.store(k.String())
+	if err == errNoTool {
+		return errors.New("no keychain: neither security nor secret-tool is installed")
+	}
+	return err
+}
+
+// Name of the keychain item of keys.
+const (
+	keyService = "imgcat"
+	keyAccount = "relay"
+)
+
+// A keychainTool keeps a secret in the keychain of the system.
+type keychainTool interface {
+	// load returns the secret, or nothing if there's none.
+	load() (string, error)
+	store(secret string) error
+}
+
+// Can be swapped for testing.
+var keychain keychainTool = systemKeychain()
+
+// systemKeychain returns the keychain of the system: the Keychain of macOS
+// with security, or the Secret Service of Linux desktops with secret-tool.
+func systemKeychain() keychainTool {
+	if runtime.GOOS == "darwin" {
+		return macKeychain{}
+	}
+	return secretTool{}
+}
+
+// macKeychain uses the security command of macOS.
+type macKeychain struct{}
+
+func (macKeychain) load() (string, error) {
+	return lookup(run(nil, "security", "find-generic-password", "-s", keyService, "-a", keyAccount, "-w"))
+}
+
+// store gives the command adding the secret to security -i on its standard
+// input, where other users can't see it as they could its arguments with ps.
+// Failed commands don't make security -i fail, so the secret is read back.
+func (k macKeychain) store(secret string) error {
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyService, keyAccount, secret)
+	if _, err := run(strings.NewReader(cmd), "security", "-i"); err != nil {
+		return err
+	}
+	stored, err := k.load()
+	if err != nil {
+		return err
+	}
+	if stored != secret {
+		return errors.New("security failed to add the key to the keychain")
+	}
+	return nil
+}
+
+// secretTool uses the secret-tool command of libsecret.
+type secretTool struct{}
+
+func (secretTool) load() (string, error) {
+	return lookup(run(nil, "secret-tool", "lookup", "service", keyService, "account", keyAccount))
+}
+
+func (secretTool) store(secret string) error {
+	_, err := run(strings.NewReader(secret), "secret-tool", "store", "--label=imgcat relay key", "service", keyService, "account", keyAccount)
+	return err
+}
+
+// errNoTool is returned by run for commands that are not installed.
+var errNoTool = errors.New("keychain command not found")
+
+// run runs the named command, with the given standard input, and returns its
+// output, or an error with what it wrote to its standard error.
+func run(stdin io.Reader, name string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, errNoTool
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = stdin
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, &toolError{name, msg, err}
+		}
+		return out, &toolError{name, err.Error(), err}
+	}
+	return out, nil
+}
+
+// A toolError is the failure of a keychain command.
+type toolError struct {
+	name, msg string
+	err       error
+}
+
+func (e *toolError) Error() string { return e.name + " failed: " + e.msg }
+
+// lookup returns the secret output by a keychain command, or nothing if
+// there's no keychain or the command found no secret, which it reports by
+// exiting with an error.
+func lookup(out []byte, err error) (string, error) {
+	if e, ok := err.(*toolError); err == errNoTool || (ok && isExit(e.err)) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(out)), err
+}
+
+func isExit(err error) bool {
+	_, ok := err.(*exec.ExitError)
+	return ok
+}
@@ -0,0 +1,148 @@
+package seal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// memKeychain is a keychain kept in memory.
+type memKeychain struct {
+	secret string
+	err    error
+}
+
+func (k *memKeychain) load() (string, error) { return k.secret, k.err }
+
+func (k *memKeychain) store(secret string) error {
+	k.secret = secret
+	return k.err
+}
+
+func TestParseKey(t *testing.T) {
+	k := testKey(t)
+	got, err := ParseKey(k.String() + "\n")
+	if err != nil || got != k {
+		t.Fatalf("expected the key back; got %v, %v", got, err)
+	}
+	for _, s := range []string{"", "not base64!", "dGVzdA=="} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("expected an error parsing %q; got nothing", s)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	defer os.Setenv(EnvKey, os.Getenv(EnvKey))
+	defer func(old keychainTool) { keychain = old }(keychain)
+	env, stored := testKey(t), testKey(t)
+
+	tc := []struct {
+		name  string
+		env   string
+		chain *memKeychain
+		key   Key
+		err   error
+	}{
+		{"environment", env.String(), &memKeychain{secret: stored.String()}, env, nil},
+		{"keychain", "", &memKeychain{secret: stored.String()}, stored, nil},
+		{"none", "", &memKeychain{}, Key{}, ErrNoKey},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv(EnvKey, tt.env)
+			keychain = tt.chain
+			k, err := LoadKey()
+			if err != tt.err || k != tt.key {
+				t.Fatalf("expected %v, %v; got %v, %v", tt.key, tt.err, k, err)
+			}
+		})
+	}
+
+	os.Setenv(EnvKey, "bad")
+	if _, err := LoadKey(); err == nil {
+		t.Fatalf("expected an error for a bad key; got nothing")
+	}
+	os.Setenv(EnvKey, "")
+	keychain = &memKeychain{err: errors.New("locked")}
+	if _, err := LoadKey(); err == nil || err == ErrNoKey {
+		t.Fatalf("expected the error of the keychain; got %v", err)
+	}
+}
+
+func TestStoreKey(t *testing.T) {
+	defer os.Setenv(EnvKey, os.Getenv(EnvKey))
+	defer func(old keychainTool) { keychain = old }(keychain)
+	os.Setenv(EnvKey, "")
+	keychain = &memKeychain{}
+
+	k := testKey(t)
+	if err := StoreKey(k); err != nil {
+		t.Fatalf("could not store key: %v", err)
+	}
+	if got, err := LoadKey(); err != nil || got != k {
+		t.Fatalf("expected the stored key; got %v, %v", got, err)
+	}
+}
+
+func TestKeychainArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake keychain commands are shell scripts")
+	}
+	// The fake commands log their arguments, keep what's given on the standard
+	// input of security -i and secret-tool store, and print it back.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" >> "$0.args"
+case "$1" in
+-i|store) cat > "$0.stdin" ;;
+*) sed 's/.* -w //' "$0.stdin" ;;
+esac
+`
+	for _, name := range []string{"security", "secret-tool"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	const secret = "c2VjcmV0IGtleSBvZiB0ZXN0cw=="
+	tc := []struct {
+		name string
+		k    keychainTool
+	}{
+		{"security", macKeychain{}},
+		{"secret-tool", secretTool{}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.k.store(secret); err != nil {
+				t.Fatalf("could not store secret: %v", err)
+			}
+			if got, err := tt.k.load(); err != nil || got != secret {
+				t.Fatalf("expected the stored secret; got %q, %v", got, err)
+			}
+			args, err := ioutil.ReadFile(filepath.Join(dir, tt.name+".args"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(args), secret) {
+				t.Errorf("expected the secret kept out of the arguments; got %q", args)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if s, err := lookup(nil, errNoTool); s != "" || err != nil {
+		t.Fatalf("expected no key without keychain; got %q, %v", s, err)
+	}
+	if s, err := lookup([]byte("secret\n"), nil); s != "secret" || err != nil {
+		t.Fatalf("expected the secret; got %q, %v", s, err)
+	}
+}
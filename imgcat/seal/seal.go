@@ -0,0 +1,233 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package seal encrypts the images relayed between machines, such as
+// screenshots sent to the display server of another host, so they aren't in
+// plaintext on the wire.
+//
+// Streams are cut in chunks of at most 64KiB, each sealed with AES-256-GCM
+// and a nonce made of a random prefix and the number of the chunk, flagged in
+// the last one. Readers detect chunks that are modified, reordered, replayed
+// from another stream, or missing at the end.
+//
+// Both ends share a Key, given in the IMGCAT_KEY variable or kept in the
+// keychain of the system, see LoadKey.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrCorrupted is returned by readers of streams that were modified or
+// truncated, or sealed with another key.
+var ErrCorrupted = errors.New("corrupted or forged stream")
+
+// Sizes of the parts of streams.
+const (
+	// prefixSize is the size of the random prefix of nonces, which starts
+	// streams.
+	prefixSize = 7
+	// chunkSize is the largest number of bytes sealed in a chunk.
+	chunkSize = 64 << 10
+	// lengthSize is the size of the length of each sealed chunk.
+	lengthSize = 4
+)
+
+func newAEAD(key Key) cipher.AEAD {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		// Keys always have a valid size.
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// nonce returns the nonce of chunk n of a stream with the given prefix.
+func nonce(prefix []byte, n uint32, last bool) []byte {
+	b := make([]byte, 12)
+	copy(b, prefix)
+	binary.BigEndian.PutUint32(b[prefixSize:], n)
+	if last {
+		b[11] = 1
+	}
+	return b
+}
+
+// A Writer seals what's written to it, see NewWriter.
+type Writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	buf    []byte
+	err    error
+}
+
+// NewWriter returns a Writer sealing what's written to it with key, and
+// writing it to w. It must be closed to write the last chunk, without which
+// readers fail.
+func NewWriter(w io.Writer, key Key) (*Writer, error) {
+	prefix := make([]byte, prefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %v", err)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, aead: newAEAD(key), prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+// Write seals p, in chunks written as they are full.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Flush writes what's buffered in a chunk, so that readers get it without
+// waiting for more, such as after each message of a protocol.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.flush(false)
+}
+
+// Close writes the last chunk. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	err := w.flush(true)
+	if err == nil {
+		w.err = errors.New("write to closed seal.Writer")
+	}
+	return err
+}
+
+// flush seals and writes the buffered chunk.
+func (w *Writer) flush(last bool) error {
+	sealed := make([]byte, lengthSize, lengthSize+len(w.buf)+w.aead.Overhead())
+	sealed = w.aead.Seal(sealed, nonce(w.prefix, w.n, last), w.buf, nil)
+	binary.BigEndian.PutUint32(sealed, uint32(len(sealed)-lengthSize))
+	if _, err := w.w.Write(sealed); err != nil {
+		w.err = err
+		return err
+	}
+	w.n++
+	if w.n == 0 {
+		w.err = errors.New("stream too long")
+		return w.err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// A Reader opens what a Writer sealed, see NewReader.
+type Reader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	n      uint32
+	buf    []byte
+	done   bool
+	err    error
+}
+
+// NewReader returns a Reader opening the stream read from r, sealed with
+// key. Reads fail with an error wrapping ErrCorrupted as soon as a chunk
+// can't be opened, so no forged byte is ever returned.
+func NewReader(r io.Reader, key Key) *Reader {
+	return &Reader{r: r, aead: newAEAD(key)}
+}
+
+// Read reads the opened stream.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next reads and opens the next chunk.
+func (r *Reader) next() error {
+	if r.prefix == nil {
+		r.prefix = make([]byte, prefixSize)
+		if _, err := io.ReadFull(r.r, r.prefix); err != nil {
+			return truncated(err)
+		}
+	}
+	var length [lengthSize]byte
+	if _, err := io.ReadFull(r.r, length[:]); err != nil {
+		return truncated(err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size < uint32(r.aead.Overhead()) || size > uint32(chunkSize+r.aead.Overhead()) {
+		return fmt.Errorf("chunk of %d bytes: %w", size, ErrCorrupted)
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.r, sealed); err != nil {
+		return truncated(err)
+	}
+	// The last chunk is the one that opens with the nonce flagged as last.
+	for _, last := range []bool{false, true} {
+		if plain, err := r.aead.Open(nil, nonce(r.prefix, r.n, last), sealed, nil); err == nil {
+			r.buf, r.done = plain, last
+			r.n++
+			return nil
+		}
+	}
+	return fmt.Errorf("chunk %d: %w", r.n, ErrCorrupted)
+}
+
+// truncated returns the error of a stream that ended before its last chunk.
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("truncated stream: %w", ErrCorrupted)
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package seal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+func testKey(t *testing.T) Key {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func seal(t *testing.T, key Key, data []byte) []byte {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Writes of odd sizes cross the chunks.
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := testKey(t)
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 5} {
+		data := make([]byte, size)
+		rand.Read(data)
+		sealed := seal(t, key, data)
+		if size > 16 && bytes.Contains(sealed, data[:16]) {
+			t.Errorf("expected sealed data of %d bytes not to contain the plaintext", size)
+		}
+		got, err := ioutil.ReadAll(NewReader(bytes.NewReader(sealed), key))
+		if err != nil {
+			t.Errorf("could not open %d bytes: %v", size, err)
+			continue
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("expected %d bytes back; got %d different ones", size, len(got))
+		}
+	}
+}
+
+func TestForged(t *testing.T) {
+	key := testKey(t)
+	data := make([]byte, 2*chunkSize+10)
+	rand.Read(data)
+	sealed := seal(t, key, data)
+	chunk := lengthSize + chunkSize + 16
+
+	tc := []struct {
+		name string
+		key  Key
+		data func() []byte
+	}{
+		{"flipped bit", key, func() []byte {
+			b := append([]byte(nil), sealed...)
+			b[prefixSize+chunk+100] ^= 1
+			return b
+		}},
+		{"missing last chunk", key, func() []byte { return sealed[:prefixSize+2*chunk] }},
+		{"cut chunk", key, func() []byte { return sealed[:len(sealed)-3] }},
+		{"reordered chunks", key, func() []byte {
+			b := append([]byte(nil), sealed[:prefixSize]...)
+			b = append(b, sealed[prefixSize+chunk:prefixSize+2*chunk]...)
+			b = append(b, sealed[prefixSize:prefixSize+chunk]...)
+			return append(b, sealed[prefixSize+2*chunk:]...)
+		}},
+		{"other stream", key, func() []byte {
+			other := seal(t, key, data)
+			return append(other[:prefixSize], sealed[prefixSize:]...)
+		}},
+		{"huge chunk", key, func() []byte {
+			b := append([]byte(nil), sealed...)
+			binary.BigEndian.PutUint32(b[prefixSize:], 1<<30)
+			return b
+		}},
+		{"wrong key", testKey(t), func() []byte { return sealed }},
+		{"empty", key, func() []byte { return nil }},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ioutil.ReadAll(NewReader(bytes.NewReader(tt.data()), tt.key))
+			if !errors.Is(err, ErrCorrupted) {
+				t.Fatalf("expected ErrCorrupted; got %v", err)
+			}
+		})
+	}
+}
+
+func TestClosedWriter(t *testing.T) {
+	w, err := NewWriter(ioutil.Discard, testKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("test")); err == nil {
+		t.Fatalf("expected an error writing after Close; got nothing")
+	}
+}
+
+func TestFlush(t *testing.T) {
+	key := testKey(t)
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReader(buf, key)
+	for _, msg := range []string{"hello", "", "world"} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if msg == "" {
+			continue
+		}
+		// What's flushed can be read before the stream ends.
+		got := make([]byte, len(msg))
+		if _, err := io.ReadFull(r, got); err != nil || string(got) != msg {
+			t.Fatalf("expected %q; got %q, %v", msg, got, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if rest, err := ioutil.ReadAll(r); err != nil || len(rest) != 0 {
+		t.Fatalf("expected the end of the stream; got %q, %v", rest, err)
+	}
+}
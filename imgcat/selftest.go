@@ -0,0 +1,171 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/colormap"
+	"github.com/campoy/tools/imgcat/termquery"
+)
+
+// Layout of the test card.
+const (
+	cardWidth  = 480
+	cardHeight = 270
+	cardCols   = 48   // width of the card in cells when sent by SelfTest.
+	cardSquare = 15   // side of the checkerboard squares, in pixels.
+	cardGray   = 0x80 // gray of the opaque checkerboard squares.
+)
+
+var hues = colormap.Gradient(
+	color.RGBA{0xff, 0, 0, 0xff}, color.RGBA{0xff, 0xff, 0, 0xff}, color.RGBA{0, 0xff, 0, 0xff},
+	color.RGBA{0, 0xff, 0xff, 0xff}, color.RGBA{0, 0, 0xff, 0xff}, color.RGBA{0xff, 0, 0xff, 0xff},
+	color.RGBA{0xff, 0, 0, 0xff},
+)
+
+// TestCard returns a w by h test pattern to check how images are displayed.
+// From top to bottom, it has a gradient of hues, a gradient of grays, text at
+// several sizes in black on white, and a checkerboard alternating opaque gray
+// squares and red ones going from transparent to opaque, which show whether
+// the terminal blends transparent images with its background.
+func TestCard(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	band := h / 4
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			t := float64(x) / float64(w-1)
+			var c color.Color
+			switch {
+			case y < band:
+				c = hues(t)
+			case y < 2*band:
+				c = colormap.Grayscale(t)
+			case y < 3*band:
+				c = color.White
+			case ((x/cardSquare)+(y-3*band)/cardSquare)%2 == 0:
+				c = color.Gray{cardGray}
+			default:
+				c = color.NRGBA{0xff, 0, 0, uint8(255 * t)}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	y := 2*band + 2
+	for scale := 1; scale <= 3; scale++ {
+		if y+bitmapfont.Height*scale > 3*band {
+			break
+		}
+		bitmapfont.Draw(img, fmt.Sprintf("imgcat test card %dx", scale), image.Pt(4, y), color.Black, scale)
+		y += (bitmapfont.Leading - 1) * scale
+	}
+	return img
+}
+
+// A SelfTestResult reports how the test card was displayed with a backend.
+type SelfTestResult struct {
+	// Backend used to send the card.
+	Backend string
+	// Err is set if the card could not be sent.
+	Err error
+	// Checked reports whether the terminal could be queried to tell whether
+	// the card was displayed, and Displayed whether it was.
+	Checked, Displayed bool
+}
+
+func (r SelfTestResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: failed: %v", r.Backend, r.Err)
+	case !r.Checked:
+		return fmt.Sprintf("%s: sent, check whether the card is displayed above", r.Backend)
+	case r.Displayed:
+		return fmt.Sprintf("%s: displayed", r.Backend)
+	}
+	return fmt.Sprintf("%s: not displayed", r.Backend)
+}
+
+// selfTestBackends lists the ways of sending images tested by SelfTest.
+var selfTestBackends = []struct {
+	name     string
+	protocol Protocol
+	options  []Option
+}{
+	{"iterm2", ITerm2, nil},
+	{"iterm2 multipart", ITerm2, []Option{Multipart(16 * 1024)}},
+	{"kitty", Kitty, nil},
+	{"sixel", Sixel, nil},
+	{"halfblock", HalfBlock, nil},
+}
+
+// SelfTest writes TestCard to w, which should be the terminal, with every
+// backend, from iTerm2 to half blocks, each one after a line with its name, and reports which
+// ones the terminal displayed.
+//
+// It tells by asking the controlling terminal for the cursor position before
+// and after each card: terminals displaying it move the cursor past it, while
+// others ignore the escape sequence. Under tmux, the position is asked to the
+// terminal tmux runs in. Under screen, it's the one known to screen, which
+// doesn't account for images, so results aren't checked.
+func SelfTest(w io.Writer) []SelfTestResult {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, TestCard(cardWidth, cardHeight)); err != nil {
+		return []SelfTestResult{{Backend: "all", Err: fmt.Errorf("could not encode test card: %v", err)}}
+	}
+	card := buf.Bytes()
+
+	var results []SelfTestResult
+	for _, b := range selfTestBackends {
+		res := SelfTestResult{Backend: b.name}
+		options := append([]Option{Inline(true), Width(Cells(cardCols)), PreserveAspectRatio(true), Newline(false)}, b.options...)
+		enc := &Encoder{out: w, options: options, protocol: b.protocol}
+		if _, err := fmt.Fprintf(w, "%s:\n", b.name); err != nil {
+			res.Err = err
+			results = append(results, res)
+			continue
+		}
+
+		before, err := cursorPosition()
+		res.Checked = err == nil && (!IsTmux() || os.Getenv("TMUX") != "")
+		if res.Err = enc.Encode(bytes.NewReader(card)); res.Err == nil {
+			after, err := cursorPosition()
+			res.Checked = res.Checked && err == nil
+			res.Displayed = res.Checked && after != before
+			_, res.Err = fmt.Fprintln(w)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// cursorPosition returns the position of the cursor, as told by the terminal
+// in reply to a Device Status Report.
+func cursorPosition() (image.Point, error) {
+	reply, err := queryTerminal("\x1b[6n", queryTimeout, termquery.CSI('R'))
+	if err != nil {
+		return image.Point{}, err
+	}
+	var p image.Point
+	if _, err := fmt.Sscanf(string(reply[bytes.Index(reply, []byte("\x1b[")):]), "\x1b[%d;%dR", &p.Y, &p.X); err != nil {
+		return image.Point{}, fmt.Errorf("unexpected cursor position reply %q", reply)
+	}
+	return p, nil
+}
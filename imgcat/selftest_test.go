@@ -0,0 +1,124 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTestCard(t *testing.T) {
+	img := TestCard(cardWidth, cardHeight)
+	band := cardHeight / 4
+	tc := []struct {
+		name string
+		x, y int
+		want color.Color
+	}{
+		{"red hue", 0, 0, color.RGBA{0xff, 0, 0, 0xff}},
+		{"black", 0, band, color.Black},
+		{"white", cardWidth - 1, band, color.White},
+		{"text background", cardWidth - 1, 2 * band, color.White},
+		{"gray square", 0, 3 * band, color.Gray{cardGray}},
+		{"transparent square", 0, 3*band + cardSquare, color.Transparent},
+		{"opaque square", cardWidth - 1, 3 * band, color.RGBA{0xff, 0, 0, 0xff}},
+	}
+	for _, tt := range tc {
+		r1, g1, b1, a1 := img.At(tt.x, tt.y).RGBA()
+		r2, g2, b2, a2 := tt.want.RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			t.Errorf("%s: expected %v at %d,%d; got %v", tt.name, tt.want, tt.x, tt.y, img.At(tt.x, tt.y))
+		}
+	}
+
+	var text int
+	for y := 2 * band; y < 3*band; y++ {
+		for x := 0; x < cardWidth; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r == 0 {
+				text++
+			}
+		}
+	}
+	if text == 0 {
+		t.Errorf("expected text on the card")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	defer func(old func(string, time.Duration, func([]byte) bool) ([]byte, error)) { queryTerminal = old }(queryTerminal)
+	defer func(old func() bool) { isTmux = old }(isTmux)
+	isTmux = func() bool { return false }
+
+	backends := []string{"iterm2", "iterm2 multipart", "kitty", "sixel", "halfblock"}
+	all := func(result string) string {
+		var s []string
+		for _, b := range backends {
+			s = append(s, b+": "+result)
+		}
+		return strings.Join(s, ";")
+	}
+	tc := []struct {
+		name    string
+		rows    []int
+		tmux    bool
+		env     string
+		results string
+	}{
+		{"displayed", []int{1, 10, 12, 21, 23, 32, 34, 43, 45, 54}, false, "", all("displayed")},
+		{"text only", []int{1, 1, 2, 2, 3, 3, 4, 4, 5, 14}, false, "", "iterm2: not displayed;iterm2 multipart: not displayed;kitty: not displayed;sixel: not displayed;halfblock: displayed"},
+		{"no reply", nil, false, "", all("sent, check whether the card is displayed above")},
+		// Queries go through tmux to the terminal, and not through screen.
+		{"tmux", []int{1, 10, 12, 21, 23, 32, 34, 43, 45, 54}, true, "/tmp/tmux-1000/default,1,0", all("displayed")},
+		{"screen", []int{1, 1, 2, 2, 3, 3, 4, 4, 5, 5}, true, "", all("sent, check whether the card is displayed above")},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			isTmux = func() bool { return tt.tmux }
+			t.Setenv("TMUX", tt.env)
+			rows := tt.rows
+			queryTerminal = func(req string, _ time.Duration, done func([]byte) bool) ([]byte, error) {
+				if req != "\x1b[6n" {
+					t.Fatalf("unexpected query %q", req)
+				}
+				if len(rows) == 0 {
+					return nil, errors.New("timeout")
+				}
+				reply := []byte(fmt.Sprintf("\x1b[%d;1R", rows[0]))
+				if !done(reply) {
+					t.Errorf("reply %q should be complete", reply)
+				}
+				rows = rows[1:]
+				return reply, nil
+			}
+
+			buf := new(bytes.Buffer)
+			var got []string
+			for _, r := range SelfTest(buf) {
+				got = append(got, r.String())
+			}
+			if s := strings.Join(got, ";"); s != tt.results {
+				t.Errorf("expected results %q; got %q", tt.results, s)
+			}
+			out := buf.String()
+			if !strings.HasPrefix(out, "iterm2:\n") || !strings.Contains(out, "]1337;File=inline=1;width=48;preserveAspectRatio=1;size=") || !strings.Contains(out, "iterm2 multipart:\n") || !strings.Contains(out, "]1337;MultipartFile=") {
+				t.Errorf("unexpected output %.80q", out)
+			}
+			for _, want := range []string{"kitty:\n", "\x1b_Ga=T,", "sixel:\n", "\x1bP0;1;0q", "halfblock:\n", "▀"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected %q in the output", want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelfTestError(t *testing.T) {
+	for _, r := range SelfTest(badWriter{}) {
+		if r.Err == nil || !strings.HasSuffix(r.String(), "failed: bad writer") {
+			t.Errorf("expected bad writer error; got %v", r)
+		}
+	}
+}
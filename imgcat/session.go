@@ -0,0 +1,244 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/termquery"
+)
+
+// Can be swapped for testing.
+var (
+	stdout        io.Writer = os.Stdout
+	backgroundDir           = func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "imgcat", "backgrounds"), nil
+	}
+)
+
+// command sends an iTerm2 proprietary command to the standard output.
+func command(cmd string) error {
+	_, err := io.WriteString(stdout, sequence(cmd, IsTmux()))
+	return err
+}
+
+// SetBadge sets the badge of the current session, a large text displayed in
+// its top right corner. The text can refer to session variables, such as
+// \(user.name) for user variables set with SetUserVar. An empty text removes
+// the badge.
+func SetBadge(text string) error {
+	return command("SetBadgeFormat=" + base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+// SetUserVar sets the user variable key of the current session to value.
+// User variables can be used in badges, titles, and status bar components
+// as \(user.key).
+func SetUserVar(key, value string) error {
+	if key == "" || !safeArg(key) || strings.Contains(key, "=") {
+		return fmt.Errorf("invalid user variable name %q", key)
+	}
+	return command("SetUserVar=" + key + "=" + base64.StdEncoding.EncodeToString([]byte(value)))
+}
+
+// RequestAttention bounces the iTerm2 icon in the dock until the application
+// is activated, which is useful to signal that a long task has finished.
+func RequestAttention() error {
+	return command("RequestAttention=yes")
+}
+
+// Notify shows thumbnail, if not nil, as a small inline image followed by
+// message, and posts message as a notification with OSC 9 and the bell.
+// This is useful to signal the end of long tasks, such as renders or training
+// runs, with a preview of their result.
+func Notify(message string, thumbnail io.Reader) error {
+	return (&Encoder{out: stdout}).Notify(message, thumbnail)
+}
+
+// Notify is like the Notify function, writing to the output of enc and
+// sending the thumbnail with its options after the ones of a thumbnail.
+func (enc *Encoder) Notify(message string, thumbnail io.Reader) error {
+	if thumbnail != nil {
+		thumb := &Encoder{out: enc.out, protocol: enc.protocol, options: append([]Option{
+			Inline(true),
+			Height(Cells(notifyRows)),
+			PreserveAspectRatio(true),
+			Newline(false),
+			MaxPixels(DefaultMaxPixels),
+			Downsample(notifyPixels),
+		}, enc.options...)}
+		if err := thumb.Encode(thumbnail); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(enc.out, " "); err != nil {
+			return err
+		}
+	}
+	message = strings.Map(func(r rune) rune {
+		// C1 controls, such as the string terminator, end sequences too.
+		if r < ' ' || r >= 0x7f && r <= 0x9f {
+			return ' '
+		}
+		return r
+	}, message)
+	_, err := fmt.Fprintf(enc.out, "%s\n%s\a", message, passthrough("\x1b]9;"+message+"\a", newConfig(enc.options).tmux()))
+	return err
+}
+
+// Size of the thumbnails shown by Notify.
+const (
+	notifyRows   = 3
+	notifyPixels = 256 * 256
+)
+
+// A BackgroundMode defines how SetBackground fits an image in the window.
+type BackgroundMode int
+
+// Supported background modes.
+const (
+	// BackgroundStretch scales the image to the size of the window.
+	BackgroundStretch BackgroundMode = iota
+	// BackgroundTile repeats the image at its original size.
+	BackgroundTile
+	// BackgroundFill scales the image to cover the window, cropping it.
+	BackgroundFill
+	// BackgroundFit scales the image to fit in the window.
+	BackgroundFit
+)
+
+// SetBackground sets the image read from r as the background of the current
+// iTerm2 session, placed according to mode, with the size of the window as
+// reported by the terminal. If the size is unknown, the image is sent as is and
+// placed according to the session's profile.
+// iTerm2 loads background images from files, so this only works when
+// running on the same machine as iTerm2, and not over SSH.
+func SetBackground(r io.Reader, mode BackgroundMode) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read background: %v", err)
+	}
+	if size, err := windowPixels(); err == nil {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("could not decode background: %v", err)
+		}
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, placeBackground(img, size, mode)); err != nil {
+			return fmt.Errorf("could not encode background: %v", err)
+		}
+		data = buf.Bytes()
+	}
+
+	path, err := writeBackground(data)
+	if err != nil {
+		return fmt.Errorf("could not write background: %v", err)
+	}
+	return command("SetBackgroundImageFile=" + base64.StdEncoding.EncodeToString([]byte(path)))
+}
+
+// writeBackground writes data to a file of imgcat/backgrounds in the user's
+// cache directory, only accessible by the user, and returns its path. Files
+// are named after their content so they can be shared by sessions, and
+// written to a new file renamed into place, so no existing file or link is
+// written through.
+func writeBackground(data []byte) (string, error) {
+	dir, err := backgroundDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%x", sha256.Sum256(data)))
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		// The error that matters is the one that left the file behind.
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, nil
+}
+
+// ClearBackground removes the background image of the current iTerm2 session.
+func ClearBackground() error {
+	return command("SetBackgroundImageFile=")
+}
+
+// windowPixels queries the terminal for the size of its window in pixels.
+func windowPixels() (image.Point, error) {
+	reply, err := queryTerminal("\x1b[14t", queryTimeout, termquery.CSI('t'))
+	if err != nil {
+		return image.Point{}, err
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(string(reply), "\x1b[4;%d;%dt", &h, &w); err != nil || w <= 0 || h <= 0 {
+		return image.Point{}, fmt.Errorf("unexpected window size reply %q", reply)
+	}
+	return image.Pt(w, h), nil
+}
+
+// placeBackground places img in a window of the given size.
+func placeBackground(img image.Image, size image.Point, mode BackgroundMode) image.Image {
+	b := img.Bounds()
+	if mode == BackgroundStretch {
+		return Resize(img, size.X, size.Y)
+	}
+
+	dst := image.NewNRGBA(image.Rectangle{Max: size})
+	if mode == BackgroundTile {
+		for y := 0; y < size.Y; y += b.Dy() {
+			for x := 0; x < size.X; x += b.Dx() {
+				draw.Draw(dst, image.Rect(x, y, x+b.Dx(), y+b.Dy()), img, b.Min, draw.Src)
+			}
+		}
+		return dst
+	}
+
+	sx, sy := float64(size.X)/float64(b.Dx()), float64(size.Y)/float64(b.Dy())
+	scale := math.Min(sx, sy)
+	if mode == BackgroundFill {
+		scale = math.Max(sx, sy)
+	}
+	w := int(math.Max(1, math.Round(float64(b.Dx())*scale)))
+	h := int(math.Max(1, math.Round(float64(b.Dy())*scale)))
+	scaled := Resize(img, w, h)
+	at := image.Pt((size.X-w)/2, (size.Y-h)/2)
+	draw.Draw(dst, scaled.Bounds().Add(at), scaled, image.Point{}, draw.Src)
+	return dst
+}
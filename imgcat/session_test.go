@@ -0,0 +1,218 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetBackground(t *testing.T) {
+	defer func(old func(string, time.Duration, func([]byte) bool) ([]byte, error)) { queryTerminal = old }(queryTerminal)
+	defer func(old io.Writer) { stdout = old }(stdout)
+	defer func(old func() (string, error)) { backgroundDir = old }(backgroundDir)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "false"))
+
+	dir, err := ioutil.TempDir("", "imgcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The directory of backgrounds is created if needed.
+	backgroundDir = func() (string, error) { return filepath.Join(dir, "backgrounds"), nil }
+
+	tc := []struct {
+		name  string
+		reply string
+		size  image.Point
+	}{
+		{"known window size", "\x1b[4;20;40t", image.Pt(40, 20)},
+		{"unknown window size", "", image.Pt(4, 2)},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			queryTerminal = func(req string, _ time.Duration, done func([]byte) bool) ([]byte, error) {
+				if req != "\x1b[14t" {
+					t.Errorf("unexpected query %q", req)
+				}
+				if tt.reply == "" {
+					return nil, errors.New("no reply")
+				}
+				return []byte(tt.reply), nil
+			}
+			buf := new(bytes.Buffer)
+			stdout = buf
+
+			if err := SetBackground(bytes.NewReader(testPNG(t, 4, 2, color.White)), BackgroundFit); err != nil {
+				t.Fatalf("could not set background: %v", err)
+			}
+			out := buf.String()
+			prefix := "\x1b]1337;SetBackgroundImageFile="
+			if !strings.HasPrefix(out, prefix) || !strings.HasSuffix(out, "\a") {
+				t.Fatalf("unexpected output %q", out)
+			}
+			path, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(out, prefix), "\a"))
+			if err != nil {
+				t.Fatalf("could not decode path: %v", err)
+			}
+			f, err := os.Open(string(path))
+			if err != nil {
+				t.Fatalf("could not open background: %v", err)
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("expected background with mode 0600; got %v", perm)
+			}
+			cfg, err := png.DecodeConfig(f)
+			if err != nil {
+				t.Fatalf("could not decode background: %v", err)
+			}
+			if got := image.Pt(cfg.Width, cfg.Height); got != tt.size {
+				t.Errorf("expected background of size %v; got %v", tt.size, got)
+			}
+		})
+	}
+}
+
+func TestClearBackground(t *testing.T) {
+	defer func(old io.Writer) { stdout = old }(stdout)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "true"))
+	buf := new(bytes.Buffer)
+	stdout = buf
+
+	if err := ClearBackground(); err != nil {
+		t.Fatalf("could not clear background: %v", err)
+	}
+	if want := "\x1bPtmux;\x1b\x1b]1337;SetBackgroundImageFile=\a\x1b\\"; buf.String() != want {
+		t.Fatalf("expected output %q; got %q", want, buf.String())
+	}
+}
+
+func TestPlaceBackground(t *testing.T) {
+	// A 2x1 image, red on the left and blue on the right.
+	red, blue := color.NRGBA{0xff, 0, 0, 0xff}, color.NRGBA{0, 0, 0xff, 0xff}
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	none := color.NRGBA{}
+	tc := []struct {
+		name string
+		mode BackgroundMode
+		// colors of the top row of a 4x4 window.
+		row [4]color.NRGBA
+	}{
+		{"stretch", BackgroundStretch, [4]color.NRGBA{red, red, blue, blue}},
+		{"tile", BackgroundTile, [4]color.NRGBA{red, blue, red, blue}},
+		{"fill", BackgroundFill, [4]color.NRGBA{red, red, blue, blue}},
+		{"fit", BackgroundFit, [4]color.NRGBA{none, none, none, none}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			img := placeBackground(src, image.Pt(4, 4), tt.mode)
+			if got := img.Bounds(); got != image.Rect(0, 0, 4, 4) {
+				t.Fatalf("expected 4x4 image; got %v", got)
+			}
+			for x, want := range tt.row {
+				if got := color.NRGBAModel.Convert(img.At(x, 0)); got != want {
+					t.Errorf("expected %v at %d,0; got %v", want, x, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	defer func(old io.Writer) { stdout = old }(stdout)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "false"))
+
+	tc := []struct {
+		name string
+		fn   func() error
+		out  string
+	}{
+		{"badge", func() error { return SetBadge("build #42") }, "\x1b]1337;SetBadgeFormat=YnVpbGQgIzQy\a"},
+		{"empty badge", func() error { return SetBadge("") }, "\x1b]1337;SetBadgeFormat=\a"},
+		{"user var", func() error { return SetUserVar("status", "ok") }, "\x1b]1337;SetUserVar=status=b2s=\a"},
+		{"invalid user var", func() error { return SetUserVar("a=b", "ok") }, ""},
+		{"control characters in user var", func() error { return SetUserVar("a\x9c\x1b]0;b", "ok") }, ""},
+		{"attention", RequestAttention, "\x1b]1337;RequestAttention=yes\a"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			stdout = buf
+			err := tt.fn()
+			if (err != nil) != (tt.out == "") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := buf.String(); got != tt.out {
+				t.Fatalf("expected output %q; got %q", tt.out, got)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	defer func(old io.Writer) { stdout = old }(stdout)
+	defer func() { check(t, os.Unsetenv("TMUX_TEST")) }()
+	check(t, os.Setenv("TMUX_TEST", "false"))
+
+	tc := []struct {
+		name      string
+		message   string
+		thumbnail io.Reader
+		prefix    string
+		suffix    string
+	}{
+		{"message", "done", nil, "done\n", "\x1b]9;done\a\a"},
+		{"control characters", "done\x1b]9;", nil, "done ]9;\n", "\x1b]9;done ]9;\a\a"},
+		{"string terminator", "done\u009c]0;", nil, "done ]0;\n", "\x1b]9;done ]0;\a\a"},
+		{"thumbnail", "done", bytes.NewReader(testPNG(t, 4, 4, color.White)),
+			"\x1b]1337;File=inline=1;height=3;preserveAspectRatio=1;size=122:iVBOR", "\a done\n\x1b]9;done\a\a"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			stdout = buf
+			if err := Notify(tt.message, tt.thumbnail); err != nil {
+				t.Fatalf("could not notify: %v", err)
+			}
+			if out := buf.String(); !strings.HasPrefix(out, tt.prefix) || !strings.HasSuffix(out, tt.suffix) {
+				t.Fatalf("expected output starting with %q and ending with %q; got %q", tt.prefix, tt.suffix, out)
+			}
+		})
+	}
+}
+
+func TestEncoderNotify(t *testing.T) {
+	var events []Event
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Name("out.png"), Passthrough(true), Trace(TracerFunc(func(e Event) { events = append(events, e) }))}}
+	if err := enc.Notify("done", bytes.NewReader(testPNG(t, 4, 4, color.White))); err != nil {
+		t.Fatalf("could not notify: %v", err)
+	}
+	prefix, suffix := "\x1bPtmux;\x1b\x1b]1337;File=inline=1;height=3;preserveAspectRatio=1;name=", "\a\x1b\\ done\n\x1bPtmux;\x1b\x1b]9;done\a\x1b\\\a"
+	if out := buf.String(); !strings.HasPrefix(out, prefix) || !strings.HasSuffix(out, suffix) {
+		t.Fatalf("expected output starting with %q and ending with %q; got %q", prefix, suffix, out)
+	}
+	if len(events) != 1 || events[0].Name != "out.png" || events[0].Width != 4 {
+		t.Fatalf("expected the thumbnail traced; got %+v", events)
+	}
+}
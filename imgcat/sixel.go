@@ -0,0 +1,106 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"strings"
+)
+
+// sixelSequence returns the sixel sequence drawing img, with colors dithered
+// to the web safe palette. Transparent pixels are left untouched.
+func sixelSequence(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	pal := image.NewPaletted(image.Rect(0, 0, w, h), palette.WebSafe)
+	draw.FloydSteinberg.Draw(pal, pal.Bounds(), img, b.Min)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if _, _, _, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA(); a < 0x8000 {
+				pal.SetColorIndex(x, y, transparentIndex)
+			}
+		}
+	}
+
+	var s strings.Builder
+	// The second parameter keeps the background of pixels without sixels,
+	// and the raster attributes give the size of the image.
+	fmt.Fprintf(&s, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	// Only the colors used are defined.
+	var defined [256]bool
+	for _, i := range pal.Pix {
+		defined[i] = true
+	}
+	for i, c := range palette.WebSafe {
+		if !defined[i] {
+			continue
+		}
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&s, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+	row := make([]byte, w)
+	for y0 := 0; y0 < h; y0 += 6 {
+		// Each band of six rows is drawn once for every color in it, going
+		// back to its start in between.
+		var used [256]bool
+		for y := y0; y < y0+6 && y < h; y++ {
+			for _, i := range pal.Pix[y*pal.Stride : y*pal.Stride+w] {
+				used[i] = true
+			}
+		}
+		for i := range palette.WebSafe {
+			if !used[i] {
+				continue
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && y0+dy < h; dy++ {
+					if pal.ColorIndexAt(x, y0+dy) == uint8(i) {
+						bits |= 1 << uint(dy)
+					}
+				}
+				row[x] = '?' + bits
+			}
+			fmt.Fprintf(&s, "#%d", i)
+			writeSixels(&s, row)
+			s.WriteByte('$')
+		}
+		s.WriteByte('-')
+	}
+	s.WriteString("\x1b\\")
+	return s.String()
+}
+
+// transparentIndex marks transparent pixels, it's past the colors of the web
+// safe palette.
+const transparentIndex = 0xff
+
+// writeSixels writes row to s, with runs of the same sixel compressed.
+func writeSixels(s *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		n := 1
+		for i+n < len(row) && row[i+n] == row[i] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(s, "!%d%c", n, row[i])
+		} else {
+			s.Write(row[i : i+n])
+		}
+		i += n
+	}
+}
@@ -0,0 +1,42 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestSixelSequence(t *testing.T) {
+	tc := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{"white", uniform(5, 7, color.White),
+			"\x1bP0;1;0q\"1;1;5;7#215;2;100;100;100#215!5~$-#215!5@$-\x1b\\"},
+		{"short runs", uniform(3, 1, color.Black),
+			"\x1bP0;1;0q\"1;1;3;1#0;2;0;0;0#0@@@$-\x1b\\"},
+		{"transparent", uniform(2, 2, color.Transparent),
+			"\x1bP0;1;0q\"1;1;2;2-\x1b\\"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sixelSequence(tt.img); got != tt.want {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSixelColors(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{0xff, 0, 0, 0xff})
+	img.Set(1, 0, color.NRGBA{0, 0, 0xff, 0xff})
+	got := sixelSequence(img)
+	for _, want := range []string{"#5;2;0;0;100", "#180;2;100;0;0", "#5?@$", "#180@?$"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Extensions of the archives SplitArchive recognizes.
+var archiveExts = []string{".zip", ".cbz", ".tar", ".tar.gz", ".tgz"}
+
+// SplitArchive splits ref, such as photos.zip:2024/a.jpg, into the path of a
+// zip or tar archive, possibly compressed with gzip, and the path of a file in
+// it. It reports false for paths of existing files, and for references whose
+// archive doesn't exist.
+func SplitArchive(ref string) (archive, member string, ok bool) {
+	if _, err := os.Stat(ref); err == nil {
+		return "", "", false
+	}
+	lower := strings.ToLower(ref)
+	for i := strings.Index(ref, ":"); i >= 0; {
+		for _, ext := range archiveExts {
+			if !strings.HasSuffix(lower[:i], ext) || i == len(ref)-1 {
+				continue
+			}
+			if info, err := os.Stat(ref[:i]); err == nil && info.Mode().IsRegular() {
+				return ref[:i], ref[i+1:], true
+			}
+		}
+		next := strings.Index(ref[i+1:], ":")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return "", "", false
+}
+
+// OpenMember opens the file with the given path in the zip or tar archive at
+// archive. Only what's needed to find it is read, the directory at the end of
+// zip archives or the files before it in tar archives, and nothing is
+// extracted.
+func OpenMember(archive, member string) (io.ReadCloser, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err == nil && string(magic[:]) == "PK\x03\x04" {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		rc, err := zipMember(f, info.Size(), member)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not open %s in %s: %w", member, archive, err)
+		}
+		return readCloser{rc, closers{rc, f}}, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := tarMember(f, member)
+	if err == nil && r == nil {
+		err = os.ErrNotExist
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not open %s in %s: %w", member, archive, err)
+	}
+	return readCloser{r, f}, nil
+}
+
+// zipMember opens the file with the given path in the zip archive read from
+// r, of the given size.
+func zipMember(r io.ReaderAt, size int64, member string) (io.ReadCloser, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if samePath(f.Name, member) && f.Mode().IsRegular() {
+			return f.Open()
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// tarMember returns a reader of the regular file with the given path in the
+// tar archive read from r, compressed with gzip or not, or nil if there's
+// none.
+func tarMember(r io.Reader, member string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	r = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if samePath(h.Name, member) && h.Typeflag == tar.TypeReg {
+			return tr, nil
+		}
+	}
+}
+
+// samePath reports whether the paths of files of archives are the same,
+// ignoring leading slashes and dots.
+func samePath(a, b string) bool {
+	return path.Clean("/"+a) == path.Clean("/"+b)
+}
+
+// readCloser reads from a reader and closes a closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// closers closes all of them, and returns the first error.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var first error
+	for _, c := range cs {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,125 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archives writes photos.zip, photos.tar, and photos.tar.gz to dir, holding
+// photos/1.jpg and photos/2.jpg.
+func archives(t *testing.T, dir string) {
+	files := []struct{ name, data string }{{"photos/1.jpg", "one"}, {"./photos/2.jpg", "two"}}
+
+	f, err := os.Create(filepath.Join(dir, "photos.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, file.data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, name := range []string{"photos.tar", "photos.tar.gz"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var w io.WriteCloser = f
+		if filepath.Ext(name) == ".gz" {
+			w = gzip.NewWriter(f)
+		}
+		tw := tar.NewWriter(w)
+		for _, file := range files {
+			tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.data)), Typeflag: tar.TypeReg})
+			io.WriteString(tw, file.data)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		f.Close()
+	}
+}
+
+func TestSplitArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	archives(t, dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "a.zip:b.png"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		ref             string
+		archive, member string
+		ok              bool
+	}{
+		{"photos.zip:photos/1.jpg", "photos.zip", "photos/1.jpg", true},
+		{"photos.tar.gz:photos/1.jpg", "photos.tar.gz", "photos/1.jpg", true},
+		{"photos.tar:a:b.jpg", "photos.tar", "a:b.jpg", true},
+		{"photos.zip:", "", "", false},
+		{"missing.zip:a.jpg", "", "", false},
+		{"a.zip:b.png", "", "", false},
+		{"photos.jpg", "", "", false},
+	}
+	for _, tt := range tc {
+		ref := filepath.Join(dir, tt.ref)
+		archive, member, ok := SplitArchive(ref)
+		if tt.ok {
+			tt.archive = filepath.Join(dir, tt.archive)
+		}
+		if archive != tt.archive || member != tt.member || ok != tt.ok {
+			t.Errorf("expected SplitArchive(%q) to be %q, %q, %v; got %q, %q, %v", tt.ref, tt.archive, tt.member, tt.ok, archive, member, ok)
+		}
+	}
+}
+
+func TestOpenMember(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	archives(t, dir)
+
+	for _, archive := range []string{"photos.zip", "photos.tar", "photos.tar.gz"} {
+		for member, data := range map[string]string{"photos/1.jpg": "one", "photos/2.jpg": "two", "/photos/2.jpg": "two"} {
+			rc, err := OpenMember(filepath.Join(dir, archive), member)
+			if err != nil {
+				t.Errorf("could not open %s in %s: %v", member, archive, err)
+				continue
+			}
+			b, err := ioutil.ReadAll(rc)
+			if err := rc.Close(); err != nil {
+				t.Errorf("could not close %s in %s: %v", member, archive, err)
+			}
+			if err != nil || string(b) != data {
+				t.Errorf("expected %q from %s in %s; got %q, %v", data, member, archive, b, err)
+			}
+		}
+		if _, err := OpenMember(filepath.Join(dir, archive), "photos"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected a not found error for a directory of %s; got %v", archive, err)
+		}
+	}
+	if _, err := OpenMember(filepath.Join(dir, "missing.zip"), "a.jpg"); !os.IsNotExist(err) {
+		t.Errorf("expected a not found error for a missing archive; got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GCS opens gs://bucket/object references with the JSON API of Google Cloud
+// Storage.
+//
+// Requests are authorized with Token, or the GOOGLE_OAUTH_ACCESS_TOKEN
+// variable, or else the token printed by gcloud auth print-access-token if
+// gcloud is installed. They are anonymous otherwise, which works for public
+// buckets.
+type GCS struct {
+	// Token is an OAuth 2.0 access token.
+	Token string
+	// Endpoint of the API, defaults to https://storage.googleapis.com.
+	Endpoint string
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Can be swapped for testing.
+var gcloudToken = func(ctx context.Context) string {
+	path, err := exec.LookPath("gcloud")
+	if err != nil {
+		return ""
+	}
+	out, err := exec.CommandContext(ctx, path, "auth", "print-access-token").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Open gets the object ref refers to.
+func (g *GCS) Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	bucket, object, err := bucketKey(ref, "gs")
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimSuffix(first(g.Endpoint, "https://storage.googleapis.com"), "/")
+	u := endpoint + "/storage/v1/b/" + url.PathEscape(bucket) + "/o/" + url.PathEscape(object) + "?alt=media"
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := first(g.Token, os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"))
+	if token == "" {
+		token = gcloudToken(ctx)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return get(ctx, g.Client, req, ref, gcsError)
+}
+
+// gcsError returns the message of an error response of Cloud Storage.
+func gcsError(body []byte) string {
+	var e struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	return e.Error.Message
+}
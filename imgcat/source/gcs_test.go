@@ -0,0 +1,67 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGCS(t *testing.T) {
+	defer os.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"))
+	os.Unsetenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	defer func(old func(context.Context) string) { gcloudToken = old }(gcloudToken)
+	gcloudToken = func(context.Context) string { return "" }
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/storage/v1/b/bucket/o/dir%2Fa.png" || r.URL.Query().Get("alt") != "media" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "message": "No such object: bucket/missing.png"}}`))
+			return
+		}
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer s.Close()
+
+	read := func(g *GCS, ref string) (string, error) {
+		rc, err := g.Open(context.Background(), ref)
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		b, err := ioutil.ReadAll(rc)
+		return string(b), err
+	}
+
+	tc := []struct {
+		name   string
+		token  string
+		env    string
+		gcloud string
+		authz  string
+	}{
+		{"anonymous", "", "", "", ""},
+		{"token", "a", "b", "c", "Bearer a"},
+		{"environment", "", "b", "c", "Bearer b"},
+		{"gcloud", "", "", "c", "Bearer c"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", tt.env)
+			gcloudToken = func(context.Context) string { return tt.gcloud }
+			got, err := read(&GCS{Endpoint: s.URL, Token: tt.token}, "gs://bucket/dir/a.png")
+			if err != nil || got != tt.authz {
+				t.Fatalf("expected authorization %q; got %q, %v", tt.authz, got, err)
+			}
+		})
+	}
+
+	_, err := read(&GCS{Endpoint: s.URL}, "gs://bucket/missing.png")
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "No such object") {
+		t.Fatalf("expected a not found error; got %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// OCI opens files of artifacts and images in container registries, given
+// references such as
+//
+//	oci://ghcr.io/acme/screenshots:v1.2#home.png
+//
+// with the repository, a tag or a @sha256: digest, and the path of the file
+// after a #. This is the title of a layer for artifacts pushed with tools
+// like oras, or the path of a file in the layers of a container image. The
+// path can be left out for artifacts of a single layer.
+//
+// Repositories without a registry, such as alpine, are in Docker Hub.
+// Registries on the local host are reached with plain HTTP, others with
+// HTTPS.
+type OCI struct {
+	// Username and Password authenticate with the registry, which is done
+	// anonymously if empty, for public repositories.
+	Username, Password string
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Media types of manifests accepted, from registries with OCI or Docker
+// images.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// A descriptor points to a manifest or a blob.
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+// A manifest is an image manifest, with layers, or an index of manifests.
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Manifests []descriptor `json:"manifests"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// titleAnnotation names the files of artifacts.
+const titleAnnotation = "org.opencontainers.image.title"
+
+// Open gets the file ref refers to.
+func (o *OCI) Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	r, err := parseOCI(ref)
+	if err != nil {
+		return nil, err
+	}
+	s := &registry{OCI: o, ctx: ctx, ref: r}
+	m, err := s.manifest(r.reference)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) > 0 {
+		d := pickPlatform(m.Manifests)
+		if m, err = s.manifest(d.Digest); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.Layers) == 0 {
+		return nil, fmt.Errorf("%s has no layers", ref)
+	}
+
+	file := strings.TrimPrefix(r.file, "/")
+	var titles []string
+	for _, l := range m.Layers {
+		if t := l.Annotations[titleAnnotation]; t != "" {
+			titles = append(titles, t)
+			if t == file || (file == "" && len(m.Layers) == 1) {
+				return s.blob(l.Digest)
+			}
+		}
+	}
+	if file == "" {
+		if len(m.Layers) == 1 {
+			return s.blob(m.Layers[0].Digest)
+		}
+		if len(titles) > 0 {
+			return nil, fmt.Errorf("%s has several files, add one of them after a #: %s", ref, strings.Join(titles, ", "))
+		}
+		return nil, fmt.Errorf("%s has several layers, add the path of a file after a #", ref)
+	}
+	// Files of upper layers replace the ones of lower layers.
+	for i := len(m.Layers) - 1; i >= 0; i-- {
+		if !strings.Contains(m.Layers[i].MediaType, "tar") {
+			continue
+		}
+		rc, err := s.member(m.Layers[i].Digest, file)
+		if err != nil || rc != nil {
+			return rc, err
+		}
+	}
+	return nil, fmt.Errorf("could not open %s: no file %s: %w", ref, file, os.ErrNotExist)
+}
+
+// An ociRef is a parsed oci:// reference.
+type ociRef struct {
+	raw             string
+	host, repo      string
+	reference, file string
+}
+
+// parseOCI parses an oci:// reference.
+func parseOCI(ref string) (ociRef, error) {
+	r := ociRef{raw: ref}
+	rest := strings.TrimPrefix(ref, "oci://")
+	if rest == ref || rest == "" {
+		return r, fmt.Errorf("%s is not an oci://repository:tag#file reference", ref)
+	}
+	if i := strings.Index(rest, "#"); i >= 0 {
+		rest, r.file = rest[:i], rest[i+1:]
+	}
+	r.reference = "latest"
+	if i := strings.Index(rest, "@"); i >= 0 {
+		rest, r.reference = rest[:i], rest[i+1:]
+	} else if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		rest, r.reference = rest[:i], rest[i+1:]
+	}
+	r.host, r.repo = "registry-1.docker.io", rest
+	if i := strings.Index(rest, "/"); i >= 0 {
+		if host := rest[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			r.host, r.repo = host, rest[i+1:]
+		}
+	}
+	if r.host == "registry-1.docker.io" && !strings.Contains(r.repo, "/") {
+		r.repo = "library/" + r.repo
+	}
+	if r.repo == "" || r.reference == "" {
+		return r, fmt.Errorf("%s is not an oci://repository:tag#file reference", ref)
+	}
+	return r, nil
+}
+
+// pickPlatform returns the manifest of an index for the platform of this
+// program, or the first one.
+func pickPlatform(manifests []descriptor) descriptor {
+	for _, d := range manifests {
+		if p := d.Platform; p != nil && p.OS == runtime.GOOS && p.Architecture == runtime.GOARCH {
+			return d
+		}
+	}
+	return manifests[0]
+}
+
+// A registry gets the manifests and blobs of a repository, with the token
+// it was given when asked to authenticate.
+type registry struct {
+	*OCI
+	ctx   context.Context
+	ref   ociRef
+	token string
+}
+
+// manifest returns the manifest the given tag or digest refers to.
+func (s *registry) manifest(reference string) (*manifest, error) {
+	res, err := s.get("manifests/"+reference, strings.Join(manifestTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	m := new(manifest)
+	if err := json.NewDecoder(io.LimitReader(res.Body, 4<<20)).Decode(m); err != nil {
+		return nil, fmt.Errorf("could not parse the manifest of %s: %v", s.ref.raw, err)
+	}
+	return m, nil
+}
+
+// blob returns the blob with the given digest, failing when it's all read
+// unless its digest matches.
+func (s *registry) blob(digest string) (io.ReadCloser, error) {
+	res, err := s.get("blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	want := strings.TrimPrefix(digest, "sha256:")
+	if want == digest {
+		return res.Body, nil
+	}
+	return &verifier{ReadCloser: res.Body, h: sha256.New(), want: want}, nil
+}
+
+// member returns the file with the given path in the tar layer with the
+// given digest, or nil if there's none.
+func (s *registry) member(digest, file string) (io.ReadCloser, error) {
+	layer, err := s.blob(digest)
+	if err != nil {
+		return nil, err
+	}
+	r, err := tarMember(layer, file)
+	if err != nil || r == nil {
+		layer.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read layer %s: %v", digest, err)
+		}
+		return nil, nil
+	}
+	return readCloser{r, layer}, nil
+}
+
+// get gets the resource of the repository at the given path, authenticating
+// when the registry asks to.
+func (s *registry) get(p, accept string) (*http.Response, error) {
+	scheme, host := "https", s.ref.host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		scheme = "http"
+	}
+	u := scheme + "://" + s.ref.host + "/v2/" + s.ref.repo + "/" + p
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	for retried := false; ; retried = true {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if s.token != "" {
+			req.Header.Set("Authorization", s.token)
+		}
+		res, err := client.Do(req.WithContext(s.ctx))
+		if err != nil {
+			return nil, fmt.Errorf("could not open %s: %v", s.ref.raw, err)
+		}
+		if res.StatusCode == http.StatusUnauthorized && !retried {
+			challenge := res.Header.Get("Www-Authenticate")
+			res.Body.Close()
+			if s.token, err = s.authenticate(challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if res.StatusCode == http.StatusOK {
+			return res, nil
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64<<10))
+		msg := ociError(body)
+		if msg == "" {
+			msg = res.Status
+		}
+		if res.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("could not open %s: %s: %w", s.ref.raw, msg, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("could not open %s: %s", s.ref.raw, msg)
+	}
+}
+
+// challengeParam matches the parameters of a WWW-Authenticate header.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate returns the Authorization header answering the challenge of
+// a WWW-Authenticate header: basic, or a bearer token asked to the server
+// given in the challenge.
+func (s *registry) authenticate(challenge string) (string, error) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte(s.Username+":"+s.Password))
+	fields := strings.SplitN(challenge, " ", 2)
+	switch {
+	case strings.EqualFold(fields[0], "basic") && s.Username != "":
+		return basic, nil
+	case !strings.EqualFold(fields[0], "bearer") || len(fields) < 2:
+		return "", fmt.Errorf("could not open %s: unauthorized", s.ref.raw)
+	}
+	params := map[string]string{}
+	for _, m := range challengeParam.FindAllStringSubmatch(fields[1], -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("could not open %s: bad authentication challenge %q", s.ref.raw, challenge)
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + s.ref.repo + ":pull"
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.Username != "" {
+		req.Header.Set("Authorization", basic)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req.WithContext(s.ctx))
+	if err != nil {
+		return "", fmt.Errorf("could not authenticate to %s: %v", s.ref.host, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not authenticate to %s: %s", s.ref.host, res.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("could not authenticate to %s: %v", s.ref.host, err)
+	}
+	return "Bearer " + first(token.Token, token.AccessToken), nil
+}
+
+// ociError returns the messages of an error response of a registry.
+func ociError(body []byte) string {
+	var e struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &e) != nil {
+		return ""
+	}
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Code+": "+err.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// A verifier fails at the end of a blob whose digest doesn't match.
+type verifier struct {
+	io.ReadCloser
+	h    hash.Hash
+	want string
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF {
+		if got := hex.EncodeToString(v.h.Sum(nil)); got != v.want {
+			return n, fmt.Errorf("corrupted blob: expected digest sha256:%s, got sha256:%s", v.want, got)
+		}
+	}
+	return n, err
+}
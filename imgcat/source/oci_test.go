@@ -0,0 +1,182 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseOCI(t *testing.T) {
+	tc := []struct {
+		ref  string
+		want ociRef
+		err  bool
+	}{
+		{"oci://ghcr.io/acme/shots:v1#home.png", ociRef{host: "ghcr.io", repo: "acme/shots", reference: "v1", file: "home.png"}, false},
+		{"oci://localhost:5000/shots#a/b.png", ociRef{host: "localhost:5000", repo: "shots", reference: "latest", file: "a/b.png"}, false},
+		{"oci://alpine:3.19#etc/os-release", ociRef{host: "registry-1.docker.io", repo: "library/alpine", reference: "3.19", file: "etc/os-release"}, false},
+		{"oci://acme/shots@sha256:abc", ociRef{host: "registry-1.docker.io", repo: "acme/shots", reference: "sha256:abc"}, false},
+		{"oci://", ociRef{}, true},
+		{"oci://ghcr.io/acme:#a.png", ociRef{}, true},
+		{"s3://bucket/key", ociRef{}, true},
+	}
+	for _, tt := range tc {
+		got, err := parseOCI(tt.ref)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected an error parsing %s; got nothing", tt.ref)
+			}
+			continue
+		}
+		tt.want.raw = tt.ref
+		if err != nil || got != tt.want {
+			t.Errorf("expected parseOCI(%q) to be %+v; got %+v, %v", tt.ref, tt.want, got, err)
+		}
+	}
+}
+
+// registryServer is a registry with a repository acme/shots, whose tag
+// artifact has files home.png and about.png, and tag image is an index of a
+// container image with two layers.
+func registryServer(t *testing.T) *httptest.Server {
+	blobs := map[string][]byte{}
+	add := func(data []byte) string {
+		d := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+		blobs[d] = data
+		return d
+	}
+	layer := func(files map[string]string) []byte {
+		buf := new(bytes.Buffer)
+		gz := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gz)
+		for name, data := range files {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+			tw.Write([]byte(data))
+		}
+		tw.Close()
+		gz.Close()
+		return buf.Bytes()
+	}
+	jsonOf := func(v interface{}) []byte {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	title := func(name string) map[string]string { return map[string]string{titleAnnotation: name} }
+
+	manifests := map[string][]byte{
+		"artifact": jsonOf(manifest{Layers: []descriptor{
+			{MediaType: "image/png", Digest: add([]byte("home")), Annotations: title("home.png")},
+			{MediaType: "image/png", Digest: add([]byte("about")), Annotations: title("about.png")},
+		}}),
+		"single": jsonOf(manifest{Layers: []descriptor{{MediaType: "image/png", Digest: add([]byte("single"))}}}),
+		"corrupt": jsonOf(manifest{Layers: []descriptor{
+			{MediaType: "image/png", Digest: "sha256:" + strings.Repeat("0", 64), Annotations: title("a.png")},
+		}}),
+	}
+	blobs["sha256:"+strings.Repeat("0", 64)] = []byte("corrupt")
+	image := add(jsonOf(manifest{Layers: []descriptor{
+		{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Digest: add(layer(map[string]string{"a.png": "lower", "b.png": "b"}))},
+		{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Digest: add(layer(map[string]string{"./a.png": "upper"}))},
+	}}))
+	manifests["image"] = jsonOf(manifest{Manifests: []descriptor{{Digest: image}}})
+	manifests[image] = blobs[image]
+
+	var s *httptest.Server
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:acme/shots:pull" || r.URL.Query().Get("service") != "test" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"token": "secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:acme/shots:pull"`, s.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		p := strings.TrimPrefix(r.URL.Path, "/v2/acme/shots/")
+		var data []byte
+		switch {
+		case strings.HasPrefix(p, "manifests/"):
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.manifest.v1+json") {
+				http.Error(w, "bad accept", http.StatusNotAcceptable)
+				return
+			}
+			data = manifests[strings.TrimPrefix(p, "manifests/")]
+		case strings.HasPrefix(p, "blobs/"):
+			data = blobs[strings.TrimPrefix(p, "blobs/")]
+		}
+		if data == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"code": "MANIFEST_UNKNOWN", "message": "manifest unknown"}]}`))
+			return
+		}
+		w.Write(data)
+	}))
+	return s
+}
+
+func TestOCI(t *testing.T) {
+	s := registryServer(t)
+	defer s.Close()
+	repo := "oci://" + strings.TrimPrefix(s.URL, "http://") + "/acme/shots"
+
+	tc := []struct {
+		ref  string
+		data string
+		err  string
+	}{
+		{repo + ":artifact#home.png", "home", ""},
+		{repo + ":artifact#about.png", "about", ""},
+		{repo + ":single", "single", ""},
+		{repo + ":image#a.png", "upper", ""},
+		{repo + ":image#/b.png", "b", ""},
+		{repo + ":artifact", "", "add one of them after a #: home.png, about.png"},
+		{repo + ":artifact#missing.png", "", "no file missing.png"},
+		{repo + ":missing#a.png", "", "MANIFEST_UNKNOWN: manifest unknown"},
+		{repo + ":corrupt#a.png", "", "corrupted blob"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.ref, func(t *testing.T) {
+			rc, err := Open(context.Background(), tt.ref)
+			var data []byte
+			if err == nil {
+				data, err = ioutil.ReadAll(rc)
+				rc.Close()
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q; got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not open: %v", err)
+			}
+			if string(data) != tt.data {
+				t.Fatalf("expected %q; got %q", tt.data, data)
+			}
+		})
+	}
+
+	_, err := Open(context.Background(), repo+":missing#a.png")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not found error; got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 opens s3://bucket/key references with the S3 API, which is also
+// implemented by MinIO, Cloudflare R2, and others.
+//
+// Fields left empty are taken from the variables used by the AWS command line
+// tools: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN,
+// AWS_REGION or AWS_DEFAULT_REGION, and AWS_ENDPOINT_URL. Requests are
+// anonymous without credentials, which works for public buckets.
+type S3 struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Region of the buckets, defaults to us-east-1.
+	Region string
+	// Endpoint, such as http://localhost:9000, of a service other than AWS.
+	// Buckets are then given in the path of requests rather than the host.
+	Endpoint string
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Can be swapped for testing.
+var now = time.Now
+
+// Open gets the object ref refers to.
+func (s *S3) Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	bucket, key, err := bucketKey(ref, "s3")
+	if err != nil {
+		return nil, err
+	}
+	region := first(s.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	u := &url.URL{Scheme: "https", Host: bucket + ".s3." + region + ".amazonaws.com", Path: "/" + key}
+	if endpoint := first(s.Endpoint, os.Getenv("AWS_ENDPOINT_URL")); endpoint != "" {
+		if u, err = url.Parse(endpoint); err != nil {
+			return nil, fmt.Errorf("could not parse endpoint: %v", err)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	keyID := first(s.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	if keyID != "" {
+		secret := first(s.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+		token := first(s.SessionToken, os.Getenv("AWS_SESSION_TOKEN"))
+		signV4(req, keyID, secret, token, region, now())
+	}
+	return get(ctx, s.Client, req, ref, s3Error)
+}
+
+// signV4 signs req, which has no body, for the S3 service with AWS Signature
+// Version 4. See
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func signV4(req *http.Request, keyID, secret, token, region string, t time.Time) {
+	const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	stamp := t.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", emptyHash)
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, k := range names {
+		canonical.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for k, vs := range query {
+		for _, v := range vs {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+
+	request := strings.Join([]string{
+		req.Method, uriEncode(req.URL.Path, false), strings.Join(params, "&"),
+		canonical.String(), signed, emptyHash,
+	}, "\n")
+	scope := stamp[:8] + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(request))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{stamp[:8], region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		keyID, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode escapes every byte of s but the unreserved characters of RFC 3986,
+// and slashes unless slash is set, as AWS expects.
+func uriEncode(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !slash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error returns the message of an error response of S3, such as
+// "NoSuchKey: The specified key does not exist.".
+func s3Error(body []byte) string {
+	var e struct {
+		Code    string
+		Message string
+	}
+	if xml.Unmarshal(body, &e) != nil || e.Code == "" {
+		return ""
+	}
+	return e.Code + ": " + e.Message
+}
+
+// get sends req with client, or http.DefaultClient if nil, and returns the
+// body of the response, or an error with the message parsed from it by
+// message for statuses other than 200 OK. Not found errors wrap
+// os.ErrNotExist.
+func get(ctx context.Context, client *http.Client, req *http.Request, ref string, message func([]byte) string) (io.ReadCloser, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", ref, err)
+	}
+	if res.StatusCode == http.StatusOK {
+		return res.Body, nil
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64<<10))
+	msg := message(body)
+	if msg == "" {
+		msg = res.Status
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("could not open %s: %s: %w", ref, msg, os.ErrNotExist)
+	}
+	return nil, fmt.Errorf("could not open %s: %s", ref, msg)
+}
+
+// first returns the first of values that's not empty.
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package source opens images kept elsewhere than in local files, such as in
+// object storage, given references like s3://bucket/key.png, so they can be
+// displayed without downloading them first.
+//
+// Sources are pluggable through the Source interface, and registered for
+// the scheme of the references they open. File, S3, GCS, and OCI are
+// registered for file://, s3://, gs://, and oci:// references.
+//
+// Files in local zip and tar archives, given as photos.zip:2024/a.jpg, are
+// opened with SplitArchive and OpenMember.
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownScheme is returned by Open for references whose scheme has no
+// registered Source.
+var ErrUnknownScheme = errors.New("unknown scheme")
+
+// A Source opens the object a reference, such as s3://bucket/key.png, refers
+// to.
+type Source interface {
+	Open(ctx context.Context, ref string) (io.ReadCloser, error)
+}
+
+// SourceFunc adapts an ordinary function to the Source interface.
+type SourceFunc func(ctx context.Context, ref string) (io.ReadCloser, error)
+
+// Open calls f(ctx, ref).
+func (f SourceFunc) Open(ctx context.Context, ref string) (io.ReadCloser, error) { return f(ctx, ref) }
+
+var (
+	mu      sync.RWMutex
+	sources = map[string]Source{
+		"file": File{},
+		"s3":   &S3{},
+		"gs":   &GCS{},
+		"oci":  &OCI{},
+	}
+)
+
+// Register makes s open the references with the given scheme, such as "s3",
+// replacing the Source registered for it if any.
+func Register(scheme string, s Source) {
+	mu.Lock()
+	defer mu.Unlock()
+	sources[strings.ToLower(scheme)] = s
+}
+
+// Schemes returns the schemes with a registered Source, sorted.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var schemes []string
+	for s := range sources {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Lookup returns the Source registered for the scheme of ref, if any.
+// References without a scheme, such as local paths, have none.
+func Lookup(ref string) (Source, bool) {
+	i := strings.Index(ref, "://")
+	if i <= 0 {
+		return nil, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	s, ok := sources[strings.ToLower(ref[:i])]
+	return s, ok
+}
+
+// Open opens ref with the Source registered for its scheme.
+func Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	s, ok := Lookup(ref)
+	if !ok {
+		return nil, fmt.Errorf("could not open %s: %w", ref, ErrUnknownScheme)
+	}
+	return s.Open(ctx, ref)
+}
+
+// File opens file:// references, such as file:///tmp/a.png, as local files.
+type File struct{}
+
+// Open opens the file ref refers to, which must be on the local host.
+func (File) Open(ctx context.Context, ref string) (io.ReadCloser, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", ref, err)
+	}
+	if u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+		return nil, fmt.Errorf("%s is not a local file:// reference", ref)
+	}
+	return os.Open(u.Path)
+}
+
+// bucketKey returns the bucket and key of a reference such as
+// s3://bucket/key.
+func bucketKey(ref, scheme string) (bucket, key string, err error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", "", fmt.Errorf("could not parse %s: %v", ref, err)
+	}
+	key = strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != scheme || u.Host == "" || key == "" {
+		return "", "", fmt.Errorf("%s is not a %s://bucket/key reference", ref, scheme)
+	}
+	return u.Host, key, nil
+}
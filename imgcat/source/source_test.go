@@ -0,0 +1,120 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tc := []struct {
+		ref string
+		ok  bool
+	}{
+		{"s3://bucket/a.png", true},
+		{"GS://bucket/a.png", true},
+		{"file:///tmp/a.png", true},
+		{"a.png", false},
+		{"/tmp/s3://a.png", false},
+		{"ftp://host/a.png", false},
+		{"://a.png", false},
+	}
+	for _, tt := range tc {
+		if _, ok := Lookup(tt.ref); ok != tt.ok {
+			t.Errorf("expected Lookup(%q) to be %v; got %v", tt.ref, tt.ok, ok)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(old map[string]Source) { sources = old }(sources)
+	sources = map[string]Source{}
+
+	Register("Mem", SourceFunc(func(ctx context.Context, ref string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(ref)), nil
+	}))
+	if got := Schemes(); !reflect.DeepEqual(got, []string{"mem"}) {
+		t.Fatalf("expected the mem scheme; got %v", got)
+	}
+	rc, err := Open(context.Background(), "mem://a.png")
+	if err != nil {
+		t.Fatalf("could not open: %v", err)
+	}
+	defer rc.Close()
+	if b, _ := ioutil.ReadAll(rc); string(b) != "mem://a.png" {
+		t.Fatalf("expected the reference; got %q", b)
+	}
+	if _, err := Open(context.Background(), "s3://bucket/a.png"); !errors.Is(err, ErrUnknownScheme) {
+		t.Fatalf("expected ErrUnknownScheme; got %v", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a b.png")
+	if err := ioutil.WriteFile(path, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := []struct {
+		ref  string
+		data string
+		err  bool
+	}{
+		{"file://" + filepath.ToSlash(path), "test", false},
+		{"file://localhost" + filepath.ToSlash(path), "test", false},
+		{"file://" + strings.Replace(filepath.ToSlash(path), " ", "%20", 1), "test", false},
+		{"file://remote" + filepath.ToSlash(path), "", true},
+		{"file://" + filepath.ToSlash(dir) + "/missing.png", "", true},
+		{"file://", "", true},
+	}
+	for _, tt := range tc {
+		rc, err := Open(context.Background(), tt.ref)
+		if tt.err {
+			if err == nil {
+				rc.Close()
+				t.Errorf("expected an error opening %s; got nothing", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("could not open %s: %v", tt.ref, err)
+			continue
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		if string(b) != tt.data {
+			t.Errorf("expected %q from %s; got %q", tt.data, tt.ref, b)
+		}
+	}
+}
+
+func TestBucketKey(t *testing.T) {
+	tc := []struct {
+		ref         string
+		bucket, key string
+		err         bool
+	}{
+		{"s3://bucket/a.png", "bucket", "a.png", false},
+		{"s3://bucket/dir/a b.png", "bucket", "dir/a b.png", false},
+		{"s3://bucket/", "", "", true},
+		{"s3:///a.png", "", "", true},
+		{"gs://bucket/a.png", "", "", true},
+	}
+	for _, tt := range tc {
+		bucket, key, err := bucketKey(tt.ref, "s3")
+		if (err != nil) != tt.err || bucket != tt.bucket || key != tt.key {
+			t.Errorf("expected bucketKey(%q) to be %q, %q, error %v; got %q, %q, %v", tt.ref, tt.bucket, tt.key, tt.err, bucket, key, err)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// targetQualities are the JPEG qualities tried by TargetBytes, in order.
+var targetQualities = []int{85, 70, 55, 40, 25, 10}
+
+// TargetBytes re-encodes images larger than n bytes as JPEG, at decreasing
+// quality and then at decreasing size, until they fit in n bytes. This keeps
+// large photos usable over high latency links, such as SSH sessions.
+// Transparent areas are composited over white, use Matte first to choose the
+// color, and only the first frame of animations is kept.
+// Payloads that are not in a known image format are sent unchanged.
+func TargetBytes(n int) Option {
+	return func(c *config) { c.targetBytes = n }
+}
+
+// fit re-encodes the image read from r to fit in the configured target size.
+func (c *config) fit(r io.Reader) (io.Reader, error) {
+	if c.targetBytes <= 0 {
+		return r, nil
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= c.targetBytes {
+		return bytes.NewReader(data), nil
+	}
+	buf, err := c.shrink(data, c.targetBytes)
+	if err == image.ErrFormat {
+		return bytes.NewReader(data), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// shrink re-encodes the image data holds as JPEG to fit in n bytes, or returns
+// image.ErrFormat if it's not in a known image format.
+func (c *config) shrink(data []byte, n int) (*bytes.Buffer, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %v", err)
+	}
+
+	img = matte(img, color.White)
+	buf := new(bytes.Buffer)
+	for {
+		for _, q := range targetQualities {
+			buf.Reset()
+			if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: q}); err != nil {
+				return nil, fmt.Errorf("could not encode image: %v", err)
+			}
+			if buf.Len() <= n {
+				c.trace.notef("re-encoded as %dx%d JPEG at quality %d to fit in %d bytes", img.Bounds().Dx(), img.Bounds().Dy(), q, n)
+				return buf, nil
+			}
+		}
+
+		// The size grows about linearly with the number of pixels, so scale
+		// the image down a bit more than the ratio to save a few attempts.
+		b := img.Bounds()
+		if b.Dx() == 1 && b.Dy() == 1 {
+			return nil, fmt.Errorf("could not fit image in %d bytes", n)
+		}
+		scale := math.Min(0.9*math.Sqrt(float64(n)/float64(buf.Len())), 0.9)
+		w := int(math.Max(1, float64(b.Dx())*scale))
+		h := int(math.Max(1, float64(b.Dy())*scale))
+		img = Resize(img, w, h)
+	}
+}
@@ -0,0 +1,76 @@
+package imgcat
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+// noisePNG returns a w by h PNG image of random pixels, which doesn't compress.
+func noisePNG(t *testing.T, w, h int) []byte {
+	rnd := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	rnd.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("could not encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestTargetBytes(t *testing.T) {
+	in := noisePNG(t, 128, 128)
+	tc := []struct {
+		name   string
+		target int
+		format Format
+	}{
+		{"large enough", len(in), PNG},
+		{"quality", len(in) / 4, JPEG},
+		{"smaller", 2000, JPEG},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newConfig([]Option{TargetBytes(tt.target)}).prepare(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(b) > tt.target {
+				t.Errorf("expected at most %d bytes; got %d", tt.target, len(b))
+			}
+			if f := Sniff(b); f != tt.format {
+				t.Errorf("expected format %q; got %q", tt.format, f)
+			}
+		})
+	}
+}
+
+func TestTargetBytesTooSmall(t *testing.T) {
+	// No JPEG file fits in 100 bytes, since headers alone are larger.
+	_, err := newConfig([]Option{TargetBytes(100)}).prepare(bytes.NewReader(testPNG(t, 4, 4, color.White)))
+	if err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
+func TestTargetBytesUnknown(t *testing.T) {
+	in := bytes.Repeat([]byte("not an image"), 100)
+	r, err := newConfig([]Option{TargetBytes(10)}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := ioutil.ReadAll(r); !bytes.Equal(b, in) {
+		t.Fatalf("expected payload unchanged")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"io"
+)
+
+// Tee writes the payload read by Encode to w as it's being sent, so an image
+// that's displayed can be saved without reading its source twice, for
+// instance when it's downloaded or generated. What's written is the original
+// payload, before any transforms, and not the escape sequence. Payloads that
+// are rejected, for instance by Formats, MaxPixels or WithinBudget, are not
+// written, and neither are the parts sent again by Resume.
+// Encode fails if writing to w fails.
+func Tee(w io.Writer) Option {
+	return func(c *config) { c.tee = w }
+}
+
+// teeReader returns a reader that writes what's read from r to the configured
+// writer, if any, and a function to call once the payload is accepted. Payloads
+// taken from a Budget are read whole before they can be dropped, so they're
+// only written then.
+func (c *config) teeReader(r io.Reader) (io.Reader, func() error) {
+	if c.tee == nil {
+		return r, func() error { return nil }
+	}
+	c.trace.notef("payload copied to a tee")
+	if c.budget == nil {
+		return io.TeeReader(r, c.tee), func() error { return nil }
+	}
+	buf := new(bytes.Buffer)
+	return io.TeeReader(r, buf), func() error {
+		_, err := c.tee.Write(buf.Bytes())
+		return err
+	}
+}
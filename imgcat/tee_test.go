@@ -0,0 +1,75 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	in := testPNG(t, 4, 2, color.White)
+	tc := []struct {
+		name    string
+		options []Option
+		saved   []byte
+		err     error
+	}{
+		{"sent as is", nil, in, nil},
+		{"original before transforms", []Option{FlipH()}, in, nil},
+		{"rejected", []Option{Formats(JPEG)}, nil, ErrFormat},
+		{"too large", []Option{MaxPixels(4)}, nil, ErrTooLarge},
+		{"over budget", []Option{WithinBudget(NewBudget(4))}, nil, ErrOverBudget},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := new(bytes.Buffer)
+			enc, err := NewEncoder(new(bytes.Buffer), append(tt.options, Tee(saved))...)
+			if err != nil {
+				t.Fatalf("could not create encoder: %v", err)
+			}
+			if err := enc.Encode(bytes.NewReader(in)); !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+			if !bytes.Equal(saved.Bytes(), tt.saved) {
+				t.Errorf("expected %d bytes saved; got %d", len(tt.saved), saved.Len())
+			}
+		})
+	}
+}
+
+func TestTeeBadWriter(t *testing.T) {
+	defer func(old func() bool) { isSupported = old }(isSupported)
+	isSupported = func() bool { return true }
+
+	enc, err := NewEncoder(new(bytes.Buffer), Tee(badWriter{}))
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := enc.Encode(bytes.NewReader(testPNG(t, 1, 1, color.White))); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+}
+
+func TestTeeResume(t *testing.T) {
+	const payload = "abcdefghijklmnopqrstuvwxyz0123456789"
+	for n := 0; n < 5; n++ {
+		saved := new(bytes.Buffer)
+		enc := &Encoder{out: &flakyWriter{new(bytes.Buffer), n}, options: []Option{Multipart(6), Tee(saved)}}
+		if err := enc.Encode(strings.NewReader(payload)); err == nil {
+			t.Fatalf("expected error; got nothing")
+		}
+		enc.out = new(bytes.Buffer)
+		if err := enc.Resume(strings.NewReader(payload)); err != nil {
+			t.Fatalf("could not resume: %v", err)
+		}
+		// Parts sent again are not saved again.
+		if saved.String() != payload {
+			t.Errorf("interrupted after %d writes: expected %q saved; got %q", n, payload, saved)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package termquery sends queries to the controlling terminal and reads its
+// replies, such as its background color or the position of the cursor.
+//
+// Replies are read from the terminal in raw mode, so they aren't echoed nor
+// mixed with the input of the program. Queries are serialized, so concurrent
+// callers don't read each other's replies.
+//
+// It also reads single key presses, see ReadKey.
+package termquery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned on platforms where the terminal can't be
+	// queried.
+	ErrUnsupported = errors.New("terminal queries not supported on this platform")
+	// ErrTimeout is returned when the terminal doesn't reply in time, which is
+	// how most terminals handle queries they don't support.
+	ErrTimeout = errors.New("terminal didn't reply in time")
+)
+
+// DefaultTimeout is used by Query when given no timeout. It's enough for
+// local terminals and most SSH sessions.
+const DefaultTimeout = 200 * time.Millisecond
+
+// A conn is a connection to the terminal in raw mode.
+type conn interface {
+	io.ReadWriteCloser
+	// discard drops the input not read yet, such as late replies to queries
+	// that timed out.
+	discard() error
+}
+
+var (
+	// mu serializes queries.
+	mu sync.Mutex
+	// Can be swapped for testing.
+	open = openTTY
+)
+
+// Query writes req to the controlling terminal and reads its reply until done
+// reports it's complete or the timeout expires, returning ErrTimeout.
+// Zero means DefaultTimeout.
+//
+// Input typed before the query is discarded, so it isn't taken for the reply.
+// Inside tmux, as told by $TMUX, req is wrapped with Passthrough so it reaches
+// the terminal tmux runs in, rather than tmux itself.
+func Query(req string, timeout time.Duration, done func([]byte) bool) ([]byte, error) {
+	if os.Getenv("TMUX") != "" {
+		req = Passthrough(req)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, err := open(false)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := exchange(c, req, timeout, done)
+	if cerr := c.Close(); cerr != nil && err == nil {
+		return nil, fmt.Errorf("could not restore terminal: %v", cerr)
+	}
+	return reply, err
+}
+
+func exchange(c conn, req string, timeout time.Duration, done func([]byte) bool) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if err := c.discard(); err != nil {
+		return nil, fmt.Errorf("could not discard input: %v", err)
+	}
+	if _, err := io.WriteString(c, req); err != nil {
+		return nil, fmt.Errorf("could not write query: %v", err)
+	}
+
+	var reply []byte
+	buf := make([]byte, 256)
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+		// Reads return nothing after a short while when there's no input.
+		n, err := c.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("could not read reply: %v", err)
+		}
+		if n > 0 {
+			reply = append(reply, buf[:n]...)
+			if done(reply) {
+				return reply, nil
+			}
+		}
+	}
+	return nil, ErrTimeout
+}
+
+// Keys returned by ReadKey.
+const (
+	KeyUp     = "\x1b[A"
+	KeyDown   = "\x1b[B"
+	KeyRight  = "\x1b[C"
+	KeyLeft   = "\x1b[D"
+	KeyEscape = "\x1b"
+	KeyEnter  = "\r"
+	// KeyBackspace is what most terminals send for backspace, others send
+	// "\b".
+	KeyBackspace = "\x7f"
+	// KeyInterrupt is Ctrl-C, which doesn't interrupt the program while
+	// ReadKey waits.
+	KeyInterrupt = "\x03"
+)
+
+// ReadKey waits for a key to be pressed on the controlling terminal, and
+// returns what the terminal sent for it, such as "q" or KeyLeft. This is
+// useful for simple interactive programs. The terminal is left in raw mode
+// only while waiting, and queries wait for ReadKey to return.
+func ReadKey() (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, err := open(true)
+	if err != nil {
+		return "", err
+	}
+	key, err := readKey(c)
+	if cerr := c.Close(); cerr != nil && err == nil {
+		return "", fmt.Errorf("could not restore terminal: %v", cerr)
+	}
+	return key, err
+}
+
+func readKey(c conn) (string, error) {
+	// Escape sequences sent for a key are read at once.
+	buf := make([]byte, 32)
+	for {
+		n, err := c.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("could not read key: %v", err)
+		}
+		if n > 0 {
+			return string(buf[:n]), nil
+		}
+	}
+}
+
+// Passthrough wraps req so tmux passes it to the terminal it runs in, rather
+// than answering it or dropping it. Replies come back through tmux unchanged.
+// Query wraps queries itself inside tmux. Not all terminals answer queries
+// coming through tmux.
+func Passthrough(req string) string {
+	return "\x1bPtmux;" + strings.Replace(req, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+}
+
+// OSC reports whether b holds a complete OSC reply, terminated by BEL or ST,
+// such as "\x1b]11;rgb:ffff/ffff/ffff\x1b\\". It can be given to Query.
+func OSC(b []byte) bool {
+	return bytes.Contains(b, []byte("\x1b]")) && (bytes.HasSuffix(b, []byte("\a")) || bytes.HasSuffix(b, []byte("\x1b\\")))
+}
+
+// CSI returns a function reporting whether b holds a complete CSI reply ending
+// with the given byte, such as "\x1b[?62;4c" for 'c'. It can be given to Query.
+func CSI(final byte) func(b []byte) bool {
+	return func(b []byte) bool {
+		return bytes.Contains(b, []byte("\x1b[")) && len(b) > 0 && b[len(b)-1] == final
+	}
+}
@@ -0,0 +1,232 @@
+package termquery
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConn replies with chunks, one per read, and nothing afterwards.
+type fakeConn struct {
+	chunks    []string
+	written   bytes.Buffer
+	discarded bool
+	readErr   error
+	closed    bool
+	onClose   func()
+}
+
+func (c *fakeConn) Read(p []byte) (int, error) {
+	if c.readErr != nil {
+		return 0, c.readErr
+	}
+	if len(c.chunks) == 0 {
+		time.Sleep(time.Millisecond)
+		return 0, nil
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks = c.chunks[1:]
+	return n, nil
+}
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	if !c.discarded {
+		return 0, errors.New("input not discarded before writing")
+	}
+	return c.written.Write(p)
+}
+
+func (c *fakeConn) discard() error { c.discarded = true; return nil }
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	if c.onClose != nil {
+		c.onClose()
+	}
+	return nil
+}
+
+func TestQuery(t *testing.T) {
+	defer func(old func(bool) (conn, error)) { open = old }(open)
+	t.Setenv("TMUX", "")
+
+	tc := []struct {
+		name    string
+		chunks  []string
+		readErr error
+		reply   string
+		err     error
+	}{
+		{"complete", []string{"\x1b[?62;4c"}, nil, "\x1b[?62;4c", nil},
+		{"in pieces", []string{"\x1b[?6", "2;", "4c"}, nil, "\x1b[?62;4c", nil},
+		{"no reply", nil, nil, "", ErrTimeout},
+		{"incomplete", []string{"\x1b[?62"}, nil, "", ErrTimeout},
+		{"read error", nil, errors.New("broken"), "", nil},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeConn{chunks: tt.chunks, readErr: tt.readErr}
+			open = func(bool) (conn, error) { return c, nil }
+			reply, err := Query("\x1b[c", 20*time.Millisecond, CSI('c'))
+			if tt.err != nil && err != tt.err || tt.readErr != nil && err == nil || tt.err == nil && tt.readErr == nil && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if string(reply) != tt.reply {
+				t.Errorf("expected reply %q; got %q", tt.reply, reply)
+			}
+			if c.written.String() != "\x1b[c" || !c.closed {
+				t.Errorf("expected query written and terminal restored; got %q written", c.written.String())
+			}
+		})
+	}
+
+	open = func(bool) (conn, error) { return nil, ErrUnsupported }
+	if _, err := Query("\x1b[c", 0, CSI('c')); err != ErrUnsupported {
+		t.Errorf("expected %v; got %v", ErrUnsupported, err)
+	}
+}
+
+func TestQueryTmux(t *testing.T) {
+	defer func(old func(bool) (conn, error)) { open = old }(open)
+
+	tc := []struct {
+		name, tmux, req, written string
+	}{
+		{"background", "/tmp/tmux-1000/default,1,0", "\x1b]11;?\a", "\x1bPtmux;\x1b\x1b]11;?\a\x1b\\"},
+		{"cursor", "/tmp/tmux-1000/default,1,0", "\x1b[6n", "\x1bPtmux;\x1b\x1b[6n\x1b\\"},
+		{"window size", "/tmp/tmux-1000/default,1,0", "\x1b[14t", "\x1bPtmux;\x1b\x1b[14t\x1b\\"},
+		{"outside tmux", "", "\x1b[6n", "\x1b[6n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TMUX", tt.tmux)
+			c := &fakeConn{}
+			open = func(bool) (conn, error) { return c, nil }
+			if _, err := Query(tt.req, 5*time.Millisecond, OSC); err != ErrTimeout {
+				t.Fatalf("expected %v; got %v", ErrTimeout, err)
+			}
+			if got := c.written.String(); got != tt.written {
+				t.Errorf("expected %q written; got %q", tt.written, got)
+			}
+		})
+	}
+}
+
+func TestQueryConcurrent(t *testing.T) {
+	defer func(old func(bool) (conn, error)) { open = old }(open)
+
+	var lock sync.Mutex
+	active, most := 0, 0
+	open = func(bool) (conn, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if active++; active > most {
+			most = active
+		}
+		return &fakeConn{chunks: []string{"\x1b[1;1R"}, onClose: func() {
+			lock.Lock()
+			defer lock.Unlock()
+			active--
+		}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Query("\x1b[6n", time.Second, CSI('R')); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if most != 1 {
+		t.Errorf("expected queries to be serialized; got %d at once", most)
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	if got, want := Passthrough("\x1b]11;?\a"), "\x1bPtmux;\x1b\x1b]11;?\a\x1b\\"; got != want {
+		t.Errorf("expected %q; got %q", want, got)
+	}
+}
+
+func FuzzPassthrough(f *testing.F) {
+	f.Add("\x1b]11;?\a")
+	f.Add("\x1b\\\x1b\x1b\\")
+	f.Fuzz(func(t *testing.T, req string) {
+		s := Passthrough(req)
+		if !strings.HasPrefix(s, "\x1bPtmux;") || !strings.HasSuffix(s, "\x1b\\") {
+			t.Fatalf("unexpected passthrough %q", s)
+		}
+		// tmux reads escape characters in pairs, so req can't end the
+		// passthrough early.
+		inner := s[len("\x1bPtmux;") : len(s)-2]
+		var b strings.Builder
+		for i := 0; i < len(inner); i++ {
+			if inner[i] == '\x1b' {
+				if i+1 >= len(inner) || inner[i+1] != '\x1b' {
+					t.Fatalf("lone escape character in %q", s)
+				}
+				i++
+			}
+			b.WriteByte(inner[i])
+		}
+		if b.String() != req {
+			t.Fatalf("expected %q once unwrapped; got %q", req, b.String())
+		}
+	})
+}
+
+func TestDone(t *testing.T) {
+	tc := []struct {
+		name  string
+		done  func([]byte) bool
+		reply string
+		ok    bool
+	}{
+		{"osc bel", OSC, "\x1b]11;rgb:0000/0000/0000\a", true},
+		{"osc st", OSC, "\x1b]11;rgb:0000/0000/0000\x1b\\", true},
+		{"osc partial", OSC, "\x1b]11;rgb:00", false},
+		{"osc st alone", OSC, "\x1b\\", false},
+		{"csi", CSI('R'), "\x1b[12;1R", true},
+		{"csi partial", CSI('R'), "\x1b[12;1", false},
+		{"csi other", CSI('R'), "\x1b[?62;4c", false},
+		{"csi typed", CSI('R'), "R", false},
+		{"empty", CSI('R'), "", false},
+	}
+	for _, tt := range tc {
+		if ok := tt.done([]byte(tt.reply)); ok != tt.ok {
+			t.Errorf("%s: expected %v for %q; got %v", tt.name, tt.ok, tt.reply, ok)
+		}
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	defer func(old func(bool) (conn, error)) { open = old }(open)
+
+	c := &fakeConn{chunks: []string{"", KeyLeft, "q"}}
+	var keys bool
+	open = func(k bool) (conn, error) { keys = k; return c, nil }
+	for _, want := range []string{KeyLeft, "q"} {
+		key, err := ReadKey()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if key != want {
+			t.Errorf("expected key %q; got %q", want, key)
+		}
+	}
+	if !keys || !c.closed {
+		t.Errorf("expected terminal opened to read keys and restored")
+	}
+
+	c = &fakeConn{readErr: errors.New("broken")}
+	if _, err := ReadKey(); err == nil {
+		t.Errorf("expected error; got nothing")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package termquery
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// tty is the controlling terminal, in raw mode until closed.
+type tty struct {
+	fd       int
+	old, raw syscall.Termios
+}
+
+// openTTY opens the terminal in raw mode. If keys is true, keys such as Ctrl-C
+// are read rather than sending signals.
+func openTTY(keys bool) (conn, error) {
+	// The tty is opened directly so reads aren't handled by the runtime poller
+	// and honor the VTIME timeout set below.
+	fd, err := syscall.Open("/dev/tty", syscall.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not open terminal: %v", err)
+	}
+	t := &tty{fd: fd}
+	if err := ioctl(fd, ioctlGetTermios, &t.old); err != nil {
+		_ = syscall.Close(fd)
+		return nil, fmt.Errorf("could not get terminal attributes: %v", err)
+	}
+	t.raw = t.old
+	t.raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	if keys {
+		t.raw.Lflag &^= syscall.ISIG
+	}
+	t.raw.Cc[syscall.VMIN] = 0
+	t.raw.Cc[syscall.VTIME] = 1 // tenths of a second.
+	if err := ioctl(fd, ioctlSetTermios, &t.raw); err != nil {
+		_ = syscall.Close(fd)
+		return nil, fmt.Errorf("could not set terminal attributes: %v", err)
+	}
+	return t, nil
+}
+
+func (t *tty) Read(p []byte) (int, error) {
+	n, err := syscall.Read(t.fd, p)
+	if err == syscall.EINTR {
+		return 0, nil
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+func (t *tty) Write(p []byte) (int, error) { return syscall.Write(t.fd, p) }
+
+// discard reads whatever input is pending without waiting for more.
+func (t *tty) discard() error {
+	now := t.raw
+	now.Cc[syscall.VTIME] = 0
+	if err := ioctl(t.fd, ioctlSetTermios, &now); err != nil {
+		return err
+	}
+	buf := make([]byte, 256)
+	for {
+		n, err := t.Read(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return ioctl(t.fd, ioctlSetTermios, &t.raw)
+}
+
+func (t *tty) Close() error {
+	err := ioctl(t.fd, ioctlSetTermios, &t.old)
+	if cerr := syscall.Close(t.fd); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func ioctl(fd int, req uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package termsize provides the size of the terminal, or of the current pane
+// when running inside tmux, in character cells.
+package termsize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnsupported is returned when the size can't be obtained on this platform.
+var ErrUnsupported = errors.New("terminal size not supported on this platform")
+
+// Size is the size of a terminal or pane in character cells.
+type Size struct {
+	Cols, Rows int
+}
+
+// Get returns the size of the current tmux pane if running inside tmux, or
+// the size of the terminal attached to stdout otherwise.
+func Get() (Size, error) {
+	if os.Getenv("TMUX") != "" {
+		if s, err := Pane(); err == nil {
+			return s, nil
+		}
+	}
+	return Terminal(os.Stdout)
+}
+
+// Pane returns the size of the current tmux pane.
+func Pane() (Size, error) {
+	if os.Getenv("TMUX") == "" {
+		return Size{}, errors.New("not running inside tmux")
+	}
+	out, err := exec.Command("tmux", "display-message", "-p", "#{pane_width}x#{pane_height}").Output()
+	if err != nil {
+		return Size{}, fmt.Errorf("could not query tmux: %v", err)
+	}
+	return parsePane(string(out))
+}
+
+func parsePane(s string) (Size, error) {
+	var size Size
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%dx%d", &size.Cols, &size.Rows); err != nil {
+		return Size{}, fmt.Errorf("could not parse pane size %q: %v", s, err)
+	}
+	if size.Cols <= 0 || size.Rows <= 0 {
+		return Size{}, fmt.Errorf("invalid pane size %q", s)
+	}
+	return size, nil
+}
+
+// Terminal returns the size of the terminal f is attached to.
+func Terminal(f *os.File) (Size, error) { return terminal(f) }
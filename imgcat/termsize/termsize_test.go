@@ -0,0 +1,27 @@
+package termsize
+
+import "testing"
+
+func TestParsePane(t *testing.T) {
+	tc := []struct {
+		in   string
+		size Size
+		ok   bool
+	}{
+		{"80x24\n", Size{80, 24}, true},
+		{"213x51", Size{213, 51}, true},
+		{"0x24", Size{}, false},
+		{"80", Size{}, false},
+		{"", Size{}, false},
+	}
+
+	for _, tt := range tc {
+		size, err := parsePane(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parsePane(%q): unexpected error %v", tt.in, err)
+		}
+		if size != tt.size {
+			t.Errorf("parsePane(%q): expected %v; got %v", tt.in, tt.size, size)
+		}
+	}
+}
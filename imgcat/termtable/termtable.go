@@ -0,0 +1,246 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package termtable renders tables in the terminal whose cells hold text or
+// images, such as product catalogs with thumbnails or samples of a dataset
+// with their labels.
+//
+// Images are sent with the imgcat package, and drawn without moving the
+// cursor, which needs iTerm2 3.5 or later.
+package termtable
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/textwidth"
+)
+
+// DefaultImageRows is the height of images when Table.ImageRows is zero.
+const DefaultImageRows = 3
+
+// minWidth is the narrowest text columns get to fit in Table.MaxWidth.
+const minWidth = 4
+
+// A Cell is the content of a table cell, text or an image.
+type Cell struct {
+	// Text of the cell, wrapped to the width of its column. It can hold
+	// colors and other escape sequences, see the textwidth package.
+	Text string
+	// Image holds an encoded image, such as a PNG or JPEG file, displayed
+	// instead of Text if not nil.
+	Image []byte
+}
+
+// Text returns a cell holding text.
+func Text(s string) Cell { return Cell{Text: s} }
+
+// Image returns a cell holding the given image file.
+func Image(data []byte) Cell { return Cell{Image: data} }
+
+// A Table holds cells in rows and columns.
+type Table struct {
+	// Header holds the titles of the columns, none if empty.
+	Header []string
+	// Rows holds the cells, row by row. Rows can have different lengths.
+	Rows [][]Cell
+	// ImageRows is the height in cells of rows holding images, and of the
+	// images in them. Zero means DefaultImageRows.
+	ImageRows int
+	// Widths holds the widths of the columns in cells. A missing or zero
+	// width makes the column as wide as its content, and columns holding
+	// images twice as wide as ImageRows, giving square cells in most fonts.
+	Widths []int
+	// MaxWidth is the width of the terminal, borders included. Columns that
+	// don't have a width in Widths are narrowed to fit if needed. Zero means
+	// no limit.
+	MaxWidth int
+	// Options are used to encode the images, in addition to the ones sizing
+	// them, such as imgcat.MaxPixels or imgcat.Downsample.
+	Options []imgcat.Option
+}
+
+// Render writes the table to w.
+func (t *Table) Render(w io.Writer) error {
+	imageRows := t.ImageRows
+	if imageRows <= 0 {
+		imageRows = DefaultImageRows
+	}
+	widths := t.widths(imageRows)
+
+	buf := new(bytes.Buffer)
+	border(buf, widths, "┌", "┬", "┐")
+	if len(t.Header) > 0 {
+		header := make([]Cell, len(t.Header))
+		for i, h := range t.Header {
+			header[i] = Text(h)
+		}
+		if err := t.row(buf, header, widths, imageRows); err != nil {
+			return err
+		}
+		border(buf, widths, "├", "┼", "┤")
+	}
+	for _, r := range t.Rows {
+		if err := t.row(buf, r, widths, imageRows); err != nil {
+			return err
+		}
+	}
+	border(buf, widths, "└", "┴", "┘")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// columns returns the number of columns of the table.
+func (t *Table) columns() int {
+	n := len(t.Header)
+	for _, r := range t.Rows {
+		if len(r) > n {
+			n = len(r)
+		}
+	}
+	return n
+}
+
+// widths returns the width of each column in cells.
+func (t *Table) widths(imageRows int) []int {
+	n := t.columns()
+	widths := make([]int, n)
+	fixed := make([]bool, n)
+	for i := range widths {
+		if i < len(t.Widths) && t.Widths[i] > 0 {
+			widths[i], fixed[i] = t.Widths[i], true
+		}
+	}
+	grow := func(i, w int) {
+		if !fixed[i] && w > widths[i] {
+			widths[i] = w
+		}
+	}
+	for i, h := range t.Header {
+		grow(i, textwidth.Width(h))
+	}
+	image := make([]bool, n)
+	for _, r := range t.Rows {
+		for i, c := range r {
+			if c.Image != nil {
+				image[i] = true
+				grow(i, 2*imageRows)
+				continue
+			}
+			for _, line := range strings.Split(c.Text, "\n") {
+				grow(i, textwidth.Width(line))
+			}
+		}
+	}
+	for i := range widths {
+		if widths[i] < 1 {
+			widths[i] = 1
+		}
+	}
+	if t.MaxWidth <= 0 {
+		return widths
+	}
+
+	// Narrow the widest text column until the table fits, borders included.
+	total := 1
+	for _, w := range widths {
+		total += w + 3
+	}
+	for total > t.MaxWidth {
+		widest := -1
+		for i, w := range widths {
+			if !fixed[i] && !image[i] && w > minWidth && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// row writes the cells of a row, and the borders around them.
+func (t *Table) row(buf *bytes.Buffer, cells []Cell, widths []int, imageRows int) error {
+	height := 1
+	lines := make([][]string, len(widths))
+	images := make([][]byte, len(widths))
+	for i, c := range cells {
+		if c.Image == nil {
+			lines[i] = imgcat.Flow(c.Text, widths[i], 0, 0)
+			if len(lines[i]) > height {
+				height = len(lines[i])
+			}
+			continue
+		}
+		if imageRows > height {
+			height = imageRows
+		}
+	}
+	for i, c := range cells {
+		if c.Image == nil {
+			continue
+		}
+		options := append(t.Options[:len(t.Options):len(t.Options)],
+			imgcat.Inline(true), imgcat.Width(imgcat.Cells(widths[i])), imgcat.Height(imgcat.Cells(imageRows)),
+			imgcat.PreserveAspectRatio(true), imgcat.MoveCursor(false), imgcat.Newline(false))
+		img, err := imgcat.EncodeToBytes(bytes.NewReader(c.Image), options...)
+		if err != nil {
+			return fmt.Errorf("could not encode image in column %d: %v", i+1, err)
+		}
+		images[i] = img
+	}
+
+	for y := 0; y < height; y++ {
+		buf.WriteString("│")
+		for i, w := range widths {
+			buf.WriteString(" ")
+			switch {
+			case images[i] != nil:
+				if y == 0 {
+					buf.Write(images[i])
+				}
+				// Skip the cells covered by the image, rather than
+				// overwriting them with spaces.
+				fmt.Fprintf(buf, "\x1b[%dC", w)
+			case y < len(lines[i]):
+				line := textwidth.Truncate(lines[i][y], w)
+				buf.WriteString(line)
+				buf.WriteString(strings.Repeat(" ", w-textwidth.Width(line)))
+			default:
+				buf.WriteString(strings.Repeat(" ", w))
+			}
+			buf.WriteString(" │")
+		}
+		buf.WriteString("\n")
+	}
+	return nil
+}
+
+// border writes a horizontal border, with the given characters on the left, at
+// the junctions between columns, and on the right.
+func border(buf *bytes.Buffer, widths []int, left, middle, right string) {
+	buf.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			buf.WriteString(middle)
+		}
+		buf.WriteString(strings.Repeat("─", w+2))
+	}
+	buf.WriteString(right + "\n")
+}
@@ -0,0 +1,104 @@
+package termtable
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+func testPNG(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRenderText(t *testing.T) {
+	tc := []struct {
+		name  string
+		table Table
+		out   string
+	}{
+		{"header", Table{
+			Header: []string{"name", "price"},
+			Rows:   [][]Cell{{Text("tea"), Text("2")}, {Text("日本茶")}},
+		}, `
+┌────────┬───────┐
+│ name   │ price │
+├────────┼───────┤
+│ tea    │ 2     │
+│ 日本茶 │       │
+└────────┴───────┘
+`},
+		{"wrapped", Table{
+			Rows:     [][]Cell{{Text("a"), Text("the quick brown fox")}},
+			MaxWidth: 15,
+		}, `
+┌───┬─────────┐
+│ a │ the     │
+│   │ quick   │
+│   │ brown   │
+│   │ fox     │
+└───┴─────────┘
+`},
+		{"fixed widths", Table{
+			Rows:   [][]Cell{{Text("abcdef"), Text("x")}},
+			Widths: []int{3},
+		}, `
+┌─────┬───┐
+│ abc │ x │
+│ def │   │
+└─────┴───┘
+`},
+		{"colors", Table{
+			Rows: [][]Cell{{Text("\x1b[31mred\x1b[0m")}, {Text("green")}},
+		}, "\n┌───────┐\n│ \x1b[31mred\x1b[0m   │\n│ green │\n└───────┘\n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := tt.table.Render(buf); err != nil {
+				t.Fatalf("could not render: %v", err)
+			}
+			if want := strings.TrimPrefix(tt.out, "\n"); buf.String() != want {
+				t.Errorf("expected table\n%s\ngot\n%s", want, buf)
+			}
+		})
+	}
+}
+
+func TestRenderImages(t *testing.T) {
+	table := Table{
+		Header:    []string{"image", "label"},
+		Rows:      [][]Cell{{Image(testPNG(t)), Text("cat")}},
+		ImageRows: 2,
+		Options:   []imgcat.Option{imgcat.Passthrough(false)},
+	}
+	buf := new(bytes.Buffer)
+	if err := table.Render(buf); err != nil {
+		t.Fatalf("could not render: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("expected 6 lines and a final newline; got %q", lines)
+	}
+	if want := "│ \x1b]1337;File=inline=1;width=5;height=2;preserveAspectRatio=1;doNotMoveCursor=1;size="; !strings.HasPrefix(lines[3], want) {
+		t.Errorf("expected image in the first line of the row; got %q", lines[3])
+	}
+	if want := "\a\x1b[5C │ cat   │"; !strings.HasSuffix(lines[3], want) {
+		t.Errorf("expected cells covered by the image to be skipped; got %q", lines[3])
+	}
+	if want := "│ \x1b[5C │       │"; lines[4] != want {
+		t.Errorf("expected second line %q; got %q", want, lines[4])
+	}
+
+	table.Options = []imgcat.Option{imgcat.MaxPixels(1)}
+	if err := table.Render(buf); err == nil || !strings.Contains(err.Error(), "column 1") {
+		t.Errorf("expected error encoding image; got %v", err)
+	}
+}
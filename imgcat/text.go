@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// halfBlocks draws img with upper half blocks, whose foreground is the color
+// of a pixel and background the one of the pixel below it. Transparent
+// pixels are left with the colors of the terminal.
+func halfBlocks(img image.Image) string {
+	b := img.Bounds()
+	var s strings.Builder
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		if y > b.Min.Y {
+			s.WriteString("\n")
+		}
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := opaqueAt(img, x, y)
+			bottom := color.NRGBA{}
+			if y+1 < b.Max.Y {
+				bottom = opaqueAt(img, x, y+1)
+			}
+			switch {
+			case top.A == 0 && bottom.A == 0:
+				s.WriteString("\x1b[0m ")
+			case bottom.A == 0:
+				fmt.Fprintf(&s, "\x1b[0;38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			case top.A == 0:
+				fmt.Fprintf(&s, "\x1b[0;38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			default:
+				fmt.Fprintf(&s, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		s.WriteString("\x1b[0m")
+	}
+	return s.String()
+}
+
+// opaqueAt returns the color of the pixel of img at x, y, either opaque or
+// transparent.
+func opaqueAt(img image.Image, x, y int) color.NRGBA {
+	c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	if c.A < 0x80 {
+		return color.NRGBA{}
+	}
+	c.A = 0xff
+	return c
+}
+
+// asciiRamp holds characters from the lightest to the densest.
+const asciiRamp = " .:-=+*#%@"
+
+// asciiArt draws img with a character per pixel and spaces for transparent
+// ones. Characters are denser for brighter pixels on a dark background, and
+// for darker ones on a light background.
+func asciiArt(img image.Image, dark bool) string {
+	b := img.Bounds()
+	var s strings.Builder
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		if y > b.Min.Y {
+			s.WriteString("\n")
+		}
+		line := make([]byte, 0, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := opaqueAt(img, x, y)
+			if c.A == 0 {
+				line = append(line, ' ')
+				continue
+			}
+			gray := color.GrayModel.Convert(c).(color.Gray).Y
+			if !dark {
+				gray = 0xff - gray
+			}
+			line = append(line, asciiRamp[int(gray)*len(asciiRamp)/256])
+		}
+		s.WriteString(strings.TrimRight(string(line), " "))
+	}
+	return s.String()
+}
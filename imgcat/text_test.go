@@ -0,0 +1,41 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestHalfBlocks(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 3))
+	img.Set(0, 0, color.NRGBA{0xff, 0, 0, 0xff})
+	img.Set(0, 1, color.NRGBA{0, 0, 0xff, 0xff})
+	img.Set(1, 1, color.White)
+	img.Set(1, 2, color.Black)
+
+	want := "\x1b[38;2;255;0;0;48;2;0;0;255m▀\x1b[0;38;2;255;255;255m▄\x1b[0m\n" +
+		"\x1b[0m \x1b[0;38;2;0;0;0m▀\x1b[0m"
+	if got := halfBlocks(img); got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+}
+
+func TestASCIIArt(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.White)
+	img.Set(1, 0, color.Gray{0x80})
+	img.Set(2, 0, color.Black)
+	img.Set(1, 1, color.White)
+
+	// Black like transparent pixels is a space, trimmed at the end of lines.
+	want := "@+\n @"
+	if got := asciiArt(img, true); got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+
+	// On light backgrounds, white is a space and black the densest.
+	want = " =@\n"
+	if got := asciiArt(img, false); got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+}
@@ -0,0 +1,94 @@
+package textwidth
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWidth(t *testing.T) {
+	tc := []struct {
+		name  string
+		s     string
+		width int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"color", "\x1b[1;31mred\x1b[0m", 3},
+		{"hyperlink", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"osc bel", "\x1b]0;title\adone", 4},
+		{"image", "\x1b]1337;File=inline=1:dGVzdA==\a!", 1},
+		{"save cursor", "\x1b7x\x1b8", 1},
+		{"unterminated", "ok\x1b[31", 2},
+		{"accents", "ñandú", 5},
+		{"combining", "n\u0303andu\u0301", 5},
+		{"cjk", "日本語", 6},
+		{"hangul", "한국어", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"emoji", "🎉ok", 4},
+		{"zero width joiner", "a\u200db", 2},
+		{"control", "a\tb\n", 2},
+	}
+	for _, tt := range tc {
+		if got := Width(tt.s); got != tt.width {
+			t.Errorf("%s: expected width %d for %q; got %d", tt.name, tt.width, tt.s, got)
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	if got := Strip("\x1b[1mbold\x1b[0m and \x1b]8;;x\a日本\x1b]8;;\a"); got != "bold and 日本" {
+		t.Errorf("unexpected stripped string %q", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tc := []struct {
+		s     string
+		n     int
+		short string
+	}{
+		{"hello", 3, "hel"},
+		{"hello", 10, "hello"},
+		{"hello", 0, ""},
+		{"\x1b[31mhello\x1b[0m", 2, "\x1b[31mhe"},
+		{"\x1b[31mhi\x1b[0m", 2, "\x1b[31mhi\x1b[0m"},
+		{"日本語", 3, "日"},
+		{"日本語", 4, "日本"},
+		{"ño", 1, "ñ"},
+	}
+	for _, tt := range tc {
+		if got := Truncate(tt.s, tt.n); got != tt.short {
+			t.Errorf("Truncate(%q, %d): expected %q; got %q", tt.s, tt.n, tt.short, got)
+		}
+	}
+}
+
+func FuzzScan(f *testing.F) {
+	f.Add("\x1b[1;31mred\x1b[0m")
+	f.Add("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\")
+	f.Add("日本\x1b]1337;File=:dGVzdA==\a🎉\x1b")
+	f.Fuzz(func(t *testing.T, s string) {
+		stripped := Strip(s)
+		if strings.Contains(stripped, "\x1b") {
+			t.Fatalf("escape character left in %q", stripped)
+		}
+		if Strip(stripped) != stripped {
+			t.Fatalf("expected %q to be stripped already", stripped)
+		}
+		w := Width(s)
+		// Stripping sequences can join the bytes of invalid UTF-8 into runes.
+		if utf8.ValidString(s) && Width(stripped) != w {
+			t.Fatalf("expected width %d for %q; got %d", w, stripped, Width(stripped))
+		}
+		for n := 0; n <= w; n++ {
+			prefix := Truncate(s, n)
+			if !strings.HasPrefix(s, prefix) || Width(prefix) > n {
+				t.Fatalf("Truncate(%q, %d): unexpected %q", s, n, prefix)
+			}
+		}
+		if got := Truncate(s, w); got != s {
+			t.Fatalf("Truncate(%q, %d): expected it whole; got %q", s, w, got)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package thumbcache generates thumbnails of image files and caches them on
+// disk, keyed by the path, modification time, and size of the files, so that
+// listing a directory again is instant. Once the cache grows past its limit,
+// the thumbnails used the least recently are removed.
+//
+// Thumbnails are PNG images, generated from PNG, JPEG, and GIF files, and from
+// the other formats registered with the image package.
+package thumbcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register the decoders of supported formats
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/convert"
+)
+
+// DefaultMaxBytes is a sensible limit for the size of a cache, 64MiB.
+const DefaultMaxBytes = 64 << 20
+
+// ErrNotImage is returned for files that are not images in a supported
+// format.
+var ErrNotImage = errors.New("not an image")
+
+// A Cache of thumbnails, safe for concurrent use by several goroutines and
+// processes.
+type Cache struct {
+	dir      string
+	size     int
+	maxBytes int64
+	// serializes pruning.
+	mu sync.Mutex
+}
+
+// Dir returns the default directory of caches, imgcat/thumbnails in the
+// user's cache directory: $XDG_CACHE_HOME or ~/.cache on Linux, and
+// ~/Library/Caches on macOS.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "imgcat", "thumbnails"), nil
+}
+
+// New returns a cache of thumbnails at most size pixels wide and high, stored
+// in dir, which is created if needed. The thumbnails used the least recently
+// are removed when the cache holds more than maxBytes of them.
+func New(dir string, size int, maxBytes int64) (*Cache, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("thumbnails must be at least a pixel large, got %d", size)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create cache: %v", err)
+	}
+	return &Cache{dir: dir, size: size, maxBytes: maxBytes}, nil
+}
+
+// Get returns the PNG thumbnail of the image file at path, from the cache if
+// the file didn't change since it was generated, or ErrNotImage if it's not
+// an image.
+func (c *Cache) Get(path string) ([]byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, ErrNotImage
+	}
+	entry := filepath.Join(c.dir, c.key(abs, info)+".png")
+	if thumb, err := ioutil.ReadFile(entry); err == nil {
+		// The modification time of entries tells when they were last used.
+		t := time.Now()
+		_ = os.Chtimes(entry, t, t)
+		return thumb, nil
+	}
+
+	thumb, err := c.generate(abs)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store(entry, thumb); err != nil {
+		return nil, err
+	}
+	return thumb, nil
+}
+
+// key returns the name of the entry of the file at path.
+func (c *Cache) key(path string, info os.FileInfo) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d\x00%d", path, info.ModTime().UnixNano(), info.Size(), c.size)))
+	return fmt.Sprintf("%x", sum[:16])
+}
+
+// generate returns the thumbnail of the image at path.
+func (c *Cache) generate(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	if cfg.Width*cfg.Height > imgcat.DefaultMaxPixels {
+		return nil, fmt.Errorf("%s has %dx%d pixels: %w", path, cfg.Width, cfg.Height, imgcat.ErrTooLarge)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s: %v", path, err)
+	}
+
+	b := img.Bounds()
+	if b.Dx() > c.size || b.Dy() > c.size {
+		if b.Dx() >= b.Dy() {
+			img = convert.Scale(img, c.size, 0)
+		} else {
+			img = convert.Scale(img, 0, c.size)
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("could not encode thumbnail of %s: %v", path, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// store writes the thumbnail to the entry, atomically so concurrent readers
+// never see part of it.
+func (c *Cache) store(entry string, thumb []byte) error {
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("could not write to cache: %v", err)
+	}
+	_, err = tmp.Write(thumb)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), entry)
+	}
+	if err != nil {
+		if rerr := os.Remove(tmp.Name()); rerr != nil {
+			return fmt.Errorf("could not write to cache: %v, nor remove %s: %v", err, tmp.Name(), rerr)
+		}
+		return fmt.Errorf("could not write to cache: %v", err)
+	}
+	return nil
+}
+
+// A Result is the thumbnail of a file, or the error generating it.
+type Result struct {
+	Path      string
+	Thumbnail []byte
+	Err       error
+}
+
+// Thumbnails returns the thumbnails of the files in paths, in the same order,
+// generated by at most workers goroutines, and then prunes the cache.
+func (c *Cache) Thumbnails(paths []string, workers int) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]Result, len(paths))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				thumb, err := c.Get(paths[i])
+				results[i] = Result{Path: paths[i], Thumbnail: thumb, Err: err}
+			}
+		}()
+	}
+	for i := range paths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	// Failing to prune only makes the cache larger than wanted.
+	_ = c.Prune()
+	return results
+}
+
+// Prune removes the thumbnails used the least recently until the cache holds
+// at most its maximum number of bytes.
+func (c *Cache) Prune() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	infos, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("could not list cache: %v", err)
+	}
+	var total int64
+	var entries []os.FileInfo
+	for _, info := range infos {
+		if info.Mode().IsRegular() && filepath.Ext(info.Name()) == ".png" {
+			entries = append(entries, info)
+			total += info.Size()
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ModTime().Before(entries[j].ModTime()) })
+	for _, info := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not prune cache: %v", err)
+		}
+		total -= info.Size()
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package thumbcache
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setup returns a cache in a temporary directory, along with a directory
+// holding the files given by name and contents, and a function removing both.
+func setup(t *testing.T, maxBytes int64, files map[string][]byte) (*Cache, string, func()) {
+	tmp, err := ioutil.TempDir("", "thumbcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmp, "files")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := New(filepath.Join(tmp, "cache"), 16, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, dir, func() { os.RemoveAll(tmp) }
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGet(t *testing.T) {
+	c, dir, cleanup := setup(t, DefaultMaxBytes, map[string][]byte{
+		"wide.png": testPNG(t, 64, 32),
+		"tall.png": testPNG(t, 8, 40),
+		"tiny.png": testPNG(t, 4, 4),
+		"notes":    []byte("not an image"),
+	})
+	defer cleanup()
+
+	tc := []struct {
+		name string
+		size image.Point
+		err  error
+	}{
+		{"wide.png", image.Pt(16, 8), nil},
+		{"tall.png", image.Pt(3, 16), nil},
+		{"tiny.png", image.Pt(4, 4), nil},
+		{"notes", image.Point{}, ErrNotImage},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := c.Get(filepath.Join(dir, tt.name))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v; got %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(thumb))
+			if err != nil {
+				t.Fatalf("could not decode thumbnail: %v", err)
+			}
+			if got := image.Pt(cfg.Width, cfg.Height); got != tt.size {
+				t.Fatalf("expected a %v thumbnail; got %v", tt.size, got)
+			}
+		})
+	}
+
+	if _, err := c.Get(dir); !errors.Is(err, ErrNotImage) {
+		t.Fatalf("expected ErrNotImage for a directory; got %v", err)
+	}
+	if _, err := c.Get(filepath.Join(dir, "missing.png")); err == nil {
+		t.Fatalf("expected error for a missing file; got nothing")
+	}
+}
+
+func TestGetCached(t *testing.T) {
+	c, dir, cleanup := setup(t, DefaultMaxBytes, map[string][]byte{"a.png": testPNG(t, 32, 32)})
+	defer cleanup()
+	path := filepath.Join(dir, "a.png")
+	if _, err := c.Get(path); err != nil {
+		t.Fatalf("could not get thumbnail: %v", err)
+	}
+
+	// Replace the cached thumbnail, to tell whether it's used.
+	entries, err := filepath.Glob(filepath.Join(c.dir, "*.png"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one entry; got %v, %v", entries, err)
+	}
+	if err := ioutil.WriteFile(entries[0], []byte("cached"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if thumb, err := c.Get(path); err != nil || string(thumb) != "cached" {
+		t.Fatalf("expected the cached thumbnail; got %q, %v", thumb, err)
+	}
+
+	// Files that change get a new thumbnail.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if thumb, err := c.Get(path); err != nil || string(thumb) == "cached" {
+		t.Fatalf("expected a new thumbnail; got %q, %v", thumb, err)
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	files := map[string][]byte{"notes": []byte("text")}
+	names := []string{"notes"}
+	for _, name := range []string{"a.png", "b.png", "c.png", "d.png"} {
+		files[name] = testPNG(t, 16, 16)
+		names = append(names, name)
+	}
+	c, dir, cleanup := setup(t, DefaultMaxBytes, files)
+	defer cleanup()
+
+	var paths []string
+	for _, name := range names {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	results := c.Thumbnails(paths, 3)
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results; got %d", len(paths), len(results))
+	}
+	for i, r := range results {
+		if r.Path != paths[i] {
+			t.Errorf("expected result %d for %s; got %s", i, paths[i], r.Path)
+		}
+		if wantErr := i == 0; (r.Err != nil) != wantErr || (r.Thumbnail == nil) != wantErr {
+			t.Errorf("unexpected result %d: %d bytes, %v", i, len(r.Thumbnail), r.Err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	c, dir, cleanup := setup(t, 0, map[string][]byte{
+		"old.png":    testPNG(t, 16, 16),
+		"recent.png": testPNG(t, 16, 16),
+	})
+	defer cleanup()
+	for _, name := range []string{"old.png", "recent.png"} {
+		if _, err := c.Get(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("could not get thumbnail: %v", err)
+		}
+	}
+	entries, _ := filepath.Glob(filepath.Join(c.dir, "*.png"))
+	info, err := os.Stat(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep room for a single thumbnail, and make one entry older.
+	c.maxBytes = info.Size()
+	long := time.Now().Add(-time.Hour)
+	old := filepath.Join(c.dir, keyOf(t, c, filepath.Join(dir, "old.png"))+".png")
+	if err := os.Chtimes(old, long, long); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Prune(); err != nil {
+		t.Fatalf("could not prune: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected the least recently used entry removed; got %v", err)
+	}
+	recent := filepath.Join(c.dir, keyOf(t, c, filepath.Join(dir, "recent.png"))+".png")
+	if _, err := os.Stat(recent); err != nil {
+		t.Fatalf("expected the recent entry kept; got %v", err)
+	}
+}
+
+// keyOf returns the key of the file at path in c.
+func keyOf(t *testing.T, c *Cache, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.key(abs, info)
+}
+
+func TestStore(t *testing.T) {
+	c, _, cleanup := setup(t, DefaultMaxBytes, nil)
+	defer cleanup()
+
+	// An entry that can't be replaced fails the store, which leaves nothing
+	// behind.
+	entry := filepath.Join(c.dir, "entry")
+	if err := os.MkdirAll(filepath.Join(entry, "file"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.store(entry, []byte("thumb")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+	names, err := filepath.Glob(filepath.Join(c.dir, ".tmp-*"))
+	if err != nil || len(names) != 0 {
+		t.Fatalf("expected no temporary files; got %q, %v", names, err)
+	}
+
+	if err := c.store(filepath.Join(c.dir, "other"), []byte("thumb")); err != nil {
+		t.Fatalf("could not store: %v", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(c.dir, "other")); err != nil || string(data) != "thumb" {
+		t.Fatalf("expected the thumbnail stored; got %q, %v", data, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(os.TempDir(), 0, DefaultMaxBytes); err == nil {
+		t.Fatalf("expected error for empty thumbnails; got nothing")
+	}
+	if dir, err := Dir(); err == nil && filepath.Base(dir) != "thumbnails" {
+		t.Fatalf("unexpected default directory %s", dir)
+	}
+}
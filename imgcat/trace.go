@@ -0,0 +1,266 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Event describes how an image was sent by an Encoder.
+type Event struct {
+	// Name of the image, as given with the Name option.
+	Name string
+	// Protocol used to send the image, such as iterm2, see Protocol.
+	Protocol string
+	// Tmux reports whether the escape sequences were wrapped for tmux.
+	Tmux bool
+	// Format of the payload sent, as detected by Sniff.
+	Format Format
+	// Width and Height of the payload in pixels, zero if they couldn't be
+	// decoded.
+	Width, Height int
+	// Input is the number of bytes read from the input, Payload the number of
+	// bytes of the image sent once transformed or re-encoded, and Sequence the
+	// number of bytes written, escape sequences included.
+	Input, Payload, Sequence int64
+	// Args holds the header arguments sent, including the size of the
+	// payload when it's known.
+	Args []string
+	// Notes lists the decisions taken along the way, such as re-encoding the
+	// image or splitting it in parts.
+	Notes []string
+	// Duration of the whole call to Encode.
+	Duration time.Duration
+	// Err is the error returned by Encode, if any.
+	Err error
+}
+
+// A Tracer is told about every image sent by an Encoder. This helps debugging
+// images that don't show up in complex stacks of terminals, tmux, and SSH.
+type Tracer interface {
+	Trace(Event)
+}
+
+// TracerFunc adapts an ordinary function to the Tracer interface.
+type TracerFunc func(Event)
+
+// Trace calls f(e).
+func (f TracerFunc) Trace(e Event) { f(e) }
+
+// Trace reports every image sent to t.
+func Trace(t Tracer) Option {
+	return func(c *config) { c.tracer = t }
+}
+
+// A Logger prints formatted messages, such as *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Log prints a line describing every image sent to l, see Trace.
+func Log(l Logger) Option {
+	return Trace(TracerFunc(func(e Event) { l.Printf("imgcat: %s", e) }))
+}
+
+// LogJSON writes a line of JSON describing every image sent to w, such as
+//
+//	{"name":"plot.png","protocol":"iterm2","format":"png","width":640,"height":480,...}
+//
+// for programs and tests scripting around commands, see Trace and
+// Event.MarshalJSON.
+func LogJSON(w io.Writer) Option {
+	var mu sync.Mutex
+	return Trace(TracerFunc(func(e Event) {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		// Tracers can't fail Encode, so failed writes only lose the event.
+		_, _ = w.Write(append(b, '\n'))
+	}))
+}
+
+// JSONMode returns where commands given a -json flag display images, and the
+// option describing them with LogJSON on the standard output, which is kept
+// free of escape sequences: the terminal opened with /dev/tty, or nothing if
+// there's none, such as in CI.
+func JSONMode() (io.Writer, Option) {
+	var out io.Writer = ioutil.Discard
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		out = tty
+	}
+	return out, LogJSON(os.Stdout)
+}
+
+// MarshalJSON returns e as an object with the fields name, protocol, tmux,
+// format, width, height, input_bytes, payload_bytes, bytes_sent, args,
+// notes, duration_ms, and error, the ones that are empty omitted.
+func (e Event) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Name     string   `json:"name,omitempty"`
+		Protocol string   `json:"protocol,omitempty"`
+		Tmux     bool     `json:"tmux,omitempty"`
+		Format   Format   `json:"format,omitempty"`
+		Width    int      `json:"width,omitempty"`
+		Height   int      `json:"height,omitempty"`
+		Input    int64    `json:"input_bytes"`
+		Payload  int64    `json:"payload_bytes"`
+		Sequence int64    `json:"bytes_sent"`
+		Args     []string `json:"args,omitempty"`
+		Notes    []string `json:"notes,omitempty"`
+		Duration float64  `json:"duration_ms"`
+		Err      string   `json:"error,omitempty"`
+	}{
+		Name: e.Name, Protocol: e.Protocol, Tmux: e.Tmux, Format: e.Format,
+		Width: e.Width, Height: e.Height,
+		Input: e.Input, Payload: e.Payload, Sequence: e.Sequence,
+		Args: e.Args, Notes: e.Notes,
+		Duration: float64(e.Duration) / float64(time.Millisecond),
+	}
+	if e.Err != nil {
+		v.Err = e.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+func (e Event) String() string {
+	format := string(e.Format)
+	if format == "" {
+		format = "unknown format"
+	}
+	s := fmt.Sprintf("%s payload of %d bytes, read %d, sent as %d using %s", format, e.Payload, e.Input, e.Sequence, e.Protocol)
+	if e.Tmux {
+		s += " with tmux passthrough"
+	}
+	s += fmt.Sprintf(" in %v", e.Duration)
+	if len(e.Notes) > 0 {
+		s += " (" + strings.Join(e.Notes, ", ") + ")"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// A trace collects the event of a single call to Encode.
+type trace struct {
+	start   time.Time
+	notes   []string
+	input   *counter
+	payload *counter
+	out     *counter
+}
+
+// notef adds a note to the event, if it's being traced.
+func (t *trace) notef(format string, args ...interface{}) {
+	if t != nil {
+		t.notes = append(t.notes, fmt.Sprintf(format, args...))
+	}
+}
+
+// traced encodes the image read from r, reporting it to the configured tracer
+// and metrics.
+func (enc *Encoder) traced(cfg *config, r io.Reader) error {
+	t := &trace{
+		start: time.Now(),
+		input: &counter{r: r},
+		out:   &counter{w: enc.out},
+	}
+	cfg.trace = t
+	out := enc.out
+	enc.out = t.out
+	err := enc.encode(cfg, t.input)
+	enc.out = out
+
+	e := Event{
+		Name:     cfg.name(),
+		Protocol: string(enc.Protocol()),
+		Tmux:     cfg.tmux() && enc.Protocol().graphics(),
+		Input:    t.input.n,
+		Sequence: t.out.n,
+		Args:     cfg.args,
+		Notes:    t.notes,
+		Duration: time.Since(t.start),
+		Err:      err,
+	}
+	if t.payload != nil {
+		e.Payload = t.payload.n
+		e.Format = Sniff(t.payload.head)
+		if c, _, err := image.DecodeConfig(bytes.NewReader(t.payload.head)); err == nil {
+			e.Width, e.Height = c.Width, c.Height
+		}
+	}
+	if cfg.tracer != nil {
+		cfg.tracer.Trace(e)
+	}
+	cfg.metrics.add(e)
+	return err
+}
+
+// count wraps the payload about to be sent to count it, if it's being traced.
+func (t *trace) count(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	t.payload = &counter{r: r, keep: configLen}
+	return t.payload
+}
+
+// configLen is the number of bytes of payloads kept to decode their size,
+// enough for the metadata found before the size in most JPEG files.
+const configLen = 64 << 10
+
+// A counter counts the bytes going through a reader or a writer, and keeps the
+// first ones read so their format can be detected, sniffLen of them unless
+// keep is set.
+type counter struct {
+	r    io.Reader
+	w    io.Writer
+	n    int64
+	keep int
+	head []byte
+}
+
+func (c *counter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	keep := c.keep
+	if keep == 0 {
+		keep = sniffLen
+	}
+	if missing := keep - len(c.head); missing > 0 {
+		if missing > n {
+			missing = n
+		}
+		c.head = append(c.head, p[:missing]...)
+	}
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
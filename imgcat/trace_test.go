@@ -0,0 +1,135 @@
+package imgcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var events []Event
+	tracer := TracerFunc(func(e Event) { events = append(events, e) })
+
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Passthrough(false), Trace(tracer)}}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event; got %d", len(events))
+	}
+	e := events[0]
+	if e.Protocol != "iterm2" || e.Tmux || e.Input != 4 || e.Payload != 4 || e.Sequence != int64(buf.Len()) || e.Err != nil {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	// The size of buffers, which can't seek, is known even when traced.
+	events = nil
+	b, err := EncodeToBytes(bytes.NewBufferString("test"), Passthrough(false), Trace(tracer))
+	if err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if want := "\x1b]1337;File=size=4:dGVzdA==\a\n"; string(b) != want {
+		t.Fatalf("expected %q; got %q", want, b)
+	}
+	if len(events) != 1 || strings.Join(events[0].Args, ";") != "size=4" {
+		t.Fatalf("expected event with size=4; got %+v", events)
+	}
+
+	// Re-encoded images are noted, with the format and size actually sent.
+	events = nil
+	in := noisePNG(t, 64, 64)
+	enc = &Encoder{out: new(bytes.Buffer), options: []Option{Trace(tracer), TargetBytes(len(in) / 2)}}
+	if err := enc.Encode(bytes.NewReader(in)); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	e = events[0]
+	if e.Format != JPEG || e.Input != int64(len(in)) || e.Payload > int64(len(in)/2) {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if len(e.Notes) != 1 || !strings.HasPrefix(e.Notes[0], "re-encoded as 64x64 JPEG") {
+		t.Fatalf("expected re-encoding note; got %q", e.Notes)
+	}
+
+	// Errors are reported too.
+	events = nil
+	enc = &Encoder{out: badWriter{}, options: []Option{Trace(tracer)}}
+	if err := enc.Encode(strings.NewReader("test")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+	if events[0].Err == nil {
+		t.Fatalf("expected error in event")
+	}
+}
+
+func TestLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: new(bytes.Buffer), options: []Option{Passthrough(true), Multipart(3), Log(log.New(buf, "", 0))}}
+	if err := enc.Encode(strings.NewReader("test")); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	want := "imgcat: unknown format payload of 4 bytes, read 4, sent as "
+	if got := buf.String(); !strings.HasPrefix(got, want) {
+		t.Fatalf("expected log starting with %q; got %q", want, got)
+	}
+	for _, s := range []string{"with tmux passthrough", "(multipart transfer in parts of 3 bytes)"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected log to contain %q; got %q", s, buf.String())
+		}
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	data := testPNG(t, 32, 16, color.White)
+	enc := &Encoder{out: out, options: []Option{Passthrough(false), Name("plot.png"), LogJSON(buf)}}
+	if err := enc.Encode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	enc = &Encoder{out: badWriter{}, options: []Option{LogJSON(buf)}}
+	if err := enc.Encode(strings.NewReader("test")); err == nil {
+		t.Fatalf("expected error; got nothing")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines; got %q", buf.String())
+	}
+	var events []map[string]interface{}
+	for _, line := range lines {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("could not decode %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	tc := []struct {
+		key  string
+		want interface{}
+	}{
+		{"name", "plot.png"},
+		{"protocol", "iterm2"},
+		{"format", "png"},
+		{"width", 32.0},
+		{"height", 16.0},
+		{"input_bytes", float64(len(data))},
+		{"payload_bytes", float64(len(data))},
+		{"bytes_sent", float64(out.Len())},
+		{"error", nil},
+	}
+	for _, c := range tc {
+		if got := events[0][c.key]; got != c.want {
+			t.Errorf("expected %s to be %v; got %v", c.key, c.want, got)
+		}
+	}
+	if _, ok := events[0]["duration_ms"].(float64); !ok {
+		t.Errorf("expected duration_ms; got %v", events[0])
+	}
+	if events[1]["error"] == nil || events[1]["width"] != nil {
+		t.Errorf("expected an error without size; got %v", events[1])
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/webp"
+)
+
+// A Transform processes an image before it's displayed, allowing callers to
+// inject their own processing such as watermarks or redaction.
+// Transforms that have nothing to do should return the image they were given,
+// so the original payload can be sent without being encoded again.
+type Transform interface {
+	Apply(image.Image) (image.Image, error)
+}
+
+// TransformFunc adapts an ordinary function to the Transform interface.
+type TransformFunc func(image.Image) (image.Image, error)
+
+// Apply calls f(img).
+func (f TransformFunc) Apply(img image.Image) (image.Image, error) { return f(img) }
+
+// Transforms applies the given transforms, in order, to the image before it's
+// sent. If any transform modifies the image, the result is sent as PNG.
+// Transforms are applied after the ones added by previous options, such as
+// Crop or Filters.
+func Transforms(ts ...Transform) Option {
+	return func(c *config) { c.transforms = append(c.transforms, ts...) }
+}
+
+// Reencode sets the format of the images re-encoded after being transformed:
+// PNG, the default, or WebP. Lossless WebP payloads are usually much smaller
+// than PNG ones for screenshots, which matters through tmux or SSH. iTerm2
+// displays WebP images on macOS 11 and later.
+func Reencode(f Format) Option {
+	return func(c *config) { c.reencode = f }
+}
+
+// transform returns an Option that adds fn to the transforms.
+func transform(fn func(image.Image) (image.Image, error)) Option {
+	return Transforms(TransformFunc(fn))
+}
+
+// transform applies the configured transformations to the image read from r.
+// Images that are left untouched are sent as they were, others as PNG or in
+// the format set by Reencode.
+// Payloads that are not in a known image format are sent unchanged.
+func (c *config) transform(r io.Reader) (io.Reader, error) {
+	if len(c.transforms) == 0 {
+		return r, nil
+	}
+
+	buf := new(bytes.Buffer)
+	img, _, err := image.Decode(io.TeeReader(r, buf))
+	if err == image.ErrFormat {
+		return io.MultiReader(buf, r), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode image: %v", err)
+	}
+
+	out := img
+	for _, t := range c.transforms {
+		if out, err = t.Apply(out); err != nil {
+			return nil, err
+		}
+	}
+	if same(out, img) {
+		return io.MultiReader(buf, r), nil
+	}
+
+	buf.Reset()
+	format := c.reencode
+	switch format {
+	case "", PNG:
+		format = PNG
+		err = png.Encode(buf, out)
+	case WebP:
+		err = webp.Encode(buf, out)
+	default:
+		return nil, fmt.Errorf("cannot re-encode images as %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not encode image: %v", err)
+	}
+	c.trace.notef("transformed and re-encoded as %s", strings.ToUpper(string(format)))
+	return buf, nil
+}
+
+// same reports whether a and b are the same image, without panicking when
+// their dynamic type is not comparable.
+func same(a, b image.Image) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta == tb && ta.Comparable() && a == b
+}
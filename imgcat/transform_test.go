@@ -0,0 +1,90 @@
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"testing"
+)
+
+// solid is a transform that replaces the image with a 1x1 image of its color.
+type solid struct{ color.Color }
+
+func (f solid) Apply(image.Image) (image.Image, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, f.Color)
+	return img, nil
+}
+
+// value is a transform returning a non comparable image.
+type value struct{ pix []uint8 }
+
+func (value) ColorModel() color.Model   { return color.GrayModel }
+func (value) Bounds() image.Rectangle   { return image.Rect(0, 0, 1, 1) }
+func (v value) At(x, y int) color.Color { return color.Gray{v.pix[0]} }
+
+func TestTransforms(t *testing.T) {
+	in := testPNG(t, 2, 2, color.White)
+	identity := TransformFunc(func(img image.Image) (image.Image, error) { return img, nil })
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+
+	r, err := newConfig([]Option{Transforms(identity)}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, _ := ioutil.ReadAll(r); !bytes.Equal(b, in) {
+		t.Fatalf("untouched image should be sent unchanged")
+	}
+
+	r, err = newConfig([]Option{FlipH(), Transforms(identity, solid{red})}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img := decode(t, r)
+	if img.Bounds().Size() != image.Pt(1, 1) || color.NRGBAModel.Convert(img.At(0, 0)) != red {
+		t.Fatalf("transforms were not applied in order")
+	}
+
+	fn := TransformFunc(func(image.Image) (image.Image, error) { return value{[]uint8{0x80}}, nil })
+	r, err = newConfig([]Option{Transforms(fn)}).prepare(bytes.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := color.GrayModel.Convert(decode(t, r).At(0, 0)); got != (color.Gray{0x80}) {
+		t.Fatalf("expected gray; got %v", got)
+	}
+
+	bad := errors.New("bad transform")
+	fail := TransformFunc(func(image.Image) (image.Image, error) { return nil, bad })
+	if _, err := newConfig([]Option{Transforms(fail)}).prepare(bytes.NewReader(in)); err != bad {
+		t.Fatalf("expected error %v; got %v", bad, err)
+	}
+}
+
+func TestReencode(t *testing.T) {
+	in := testPNG(t, 2, 2, color.White)
+	tc := []struct {
+		format Format
+		want   Format
+	}{
+		{"", PNG},
+		{PNG, PNG},
+		{WebP, WebP},
+	}
+	for _, tt := range tc {
+		r, err := newConfig([]Option{Reencode(tt.format), FlipH()}).prepare(bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.format, err)
+		}
+		b, _ := ioutil.ReadAll(r)
+		if got := Sniff(b); got != tt.want {
+			t.Errorf("%q: expected %s payload; got %q", tt.format, tt.want, got)
+		}
+	}
+
+	if _, err := newConfig([]Option{Reencode(GIF), FlipH()}).prepare(bytes.NewReader(in)); err == nil {
+		t.Errorf("expected error re-encoding as GIF; got nothing")
+	}
+}
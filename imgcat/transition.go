@@ -0,0 +1,101 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// A Transition returns the frame at t, between 0 and 1, of a transition from
+// image a to image b, which must have the same bounds. See Fade, Dissolve, and
+// Wipe.
+type Transition func(a, b image.Image, t float64) image.Image
+
+// Fade cross-fades a into b, blending their colors.
+func Fade(a, b image.Image, t float64) image.Image {
+	t = clamp01(t)
+	return blend(a, b, func(x, y int) float64 { return t })
+}
+
+// Dissolve replaces the pixels of a with the ones of b in a random looking,
+// but fixed, order, so that a fraction t of them come from b.
+func Dissolve(a, b image.Image, t float64) image.Image {
+	t = clamp01(t)
+	return blend(a, b, func(x, y int) float64 {
+		if rank(x, y) < t {
+			return 1
+		}
+		return 0
+	})
+}
+
+// Wipe uncovers b from left to right over a, the pixels left of a vertical
+// line at a fraction t of the width coming from b. With t moved interactively,
+// it's a way to compare two versions of an image.
+func Wipe(a, b image.Image, t float64) image.Image {
+	r := a.Bounds()
+	edge := r.Min.X + int(math.Round(clamp01(t)*float64(r.Dx())))
+	return blend(a, b, func(x, y int) float64 {
+		if x < edge {
+			return 1
+		}
+		return 0
+	})
+}
+
+// blend returns an image mixing a and b, using weight(x, y) of b.
+func blend(a, b image.Image, weight func(x, y int) float64) image.Image {
+	r := a.Bounds()
+	dst := image.NewRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			w := weight(x, y)
+			ar, ag, ab, aa := a.At(x, y).RGBA()
+			br, bg, bb, ba := b.At(x, y).RGBA()
+			mix := func(p, q uint32) uint8 { return uint8((float64(p)*(1-w) + float64(q)*w) / 0x101) }
+			dst.SetRGBA(x, y, color.RGBA{mix(ar, br), mix(ag, bg), mix(ab, bb), mix(aa, ba)})
+		}
+	}
+	return dst
+}
+
+// rank returns a pseudo-random number in [0, 1) for the pixel at x, y.
+func rank(x, y int) float64 {
+	h := uint32(x)*0x9e3779b1 ^ uint32(y)*0x85ebca77
+	h ^= h >> 15
+	h *= 0x2c1b3c6d
+	h ^= h >> 12
+	h *= 0x297a2d39
+	h ^= h >> 15
+	return float64(h) / (1 << 32)
+}
+
+func clamp01(t float64) float64 {
+	if t < 0 {
+		return 0
+	}
+	if t > 1 {
+		return 1
+	}
+	return t
+}
+
+// Letterbox scales img to fit in a w by h image, keeping its aspect ratio,
+// and centers it over c. It's useful to give images the same bounds before
+// a Transition.
+func Letterbox(img image.Image, w, h int, c color.Color) image.Image {
+	return matte(placeBackground(img, image.Pt(w, h), BackgroundFit), c)
+}
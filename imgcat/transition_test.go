@@ -0,0 +1,102 @@
+package imgcat
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func uniform(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestFade(t *testing.T) {
+	a, b := uniform(4, 4, color.Black), uniform(4, 4, color.White)
+	tc := []struct {
+		t    float64
+		gray uint8
+	}{
+		{0, 0}, {0.5, 0x7f}, {1, 0xff}, {-1, 0}, {2, 0xff},
+	}
+	for _, tt := range tc {
+		c := color.RGBAModel.Convert(Fade(a, b, tt.t).At(1, 1)).(color.RGBA)
+		if c != (color.RGBA{tt.gray, tt.gray, tt.gray, 0xff}) {
+			t.Errorf("fade at %v: expected gray %#x; got %v", tt.t, tt.gray, c)
+		}
+	}
+}
+
+func TestDissolve(t *testing.T) {
+	a, b := uniform(64, 64, color.Black), uniform(64, 64, color.White)
+	count := func(img image.Image) int {
+		n := 0
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				if r, _, _, _ := img.At(x, y).RGBA(); r == 0xffff {
+					n++
+				} else if r != 0 {
+					t.Fatalf("expected pixels from either image; got %v", img.At(x, y))
+				}
+			}
+		}
+		return n
+	}
+	if n := count(Dissolve(a, b, 0)); n != 0 {
+		t.Errorf("expected no pixel of b at the start; got %d", n)
+	}
+	if n := count(Dissolve(a, b, 1)); n != 64*64 {
+		t.Errorf("expected only pixels of b at the end; got %d", n)
+	}
+	quarter, half := count(Dissolve(a, b, 0.25)), count(Dissolve(a, b, 0.5))
+	if quarter < 900 || quarter > 1150 || half < 1900 || half > 2200 {
+		t.Errorf("expected about a quarter and a half of 4096 pixels; got %d and %d", quarter, half)
+	}
+	// Pixels from b stay during the transition.
+	q, h := Dissolve(a, b, 0.25), Dissolve(a, b, 0.5)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if r, _, _, _ := q.At(x, y).RGBA(); r != 0 {
+				if r, _, _, _ := h.At(x, y).RGBA(); r == 0 {
+					t.Fatalf("pixel %d,%d went back to a", x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestLetterbox(t *testing.T) {
+	img := Letterbox(uniform(10, 10, color.White), 40, 20, color.Black)
+	if b := img.Bounds(); b != image.Rect(0, 0, 40, 20) {
+		t.Fatalf("expected 40x20 image; got %v", b)
+	}
+	for _, p := range []image.Point{{5, 10}, {35, 10}} {
+		if r, _, _, a := img.At(p.X, p.Y).RGBA(); r != 0 || a != 0xffff {
+			t.Errorf("expected black bar at %v; got %v", p, img.At(p.X, p.Y))
+		}
+	}
+	if r, _, _, _ := img.At(20, 10).RGBA(); r != 0xffff {
+		t.Errorf("expected image in the middle; got %v", img.At(20, 10))
+	}
+}
+
+func TestWipe(t *testing.T) {
+	a, b := uniform(10, 2, color.Black), uniform(10, 2, color.White)
+	tc := []struct {
+		t    float64
+		edge int
+	}{
+		{0, 0}, {0.3, 3}, {0.5, 5}, {1, 10}, {2, 10},
+	}
+	for _, tt := range tc {
+		img := Wipe(a, b, tt.t)
+		for x := 0; x < 10; x++ {
+			r, _, _, _ := img.At(x, 1).RGBA()
+			if fromB := r == 0xffff; fromB != (x < tt.edge) {
+				t.Errorf("wipe at %v: unexpected pixel %v at x=%d", tt.t, img.At(x, 1), x)
+			}
+		}
+	}
+}
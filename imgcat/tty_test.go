@@ -0,0 +1,38 @@
+package imgcat
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+	f, err := ioutil.TempFile("", "imgcat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { check(t, os.Remove(f.Name())) }()
+	defer func() { check(t, f.Close()) }()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { check(t, r.Close()) }()
+	defer func() { check(t, w.Close()) }()
+
+	tc := []struct {
+		name string
+		w    io.Writer
+	}{
+		{"buffer", new(bytes.Buffer)},
+		{"file", f},
+		{"pipe", w},
+	}
+	for _, tt := range tc {
+		if IsTerminal(tt.w) {
+			t.Errorf("%s should not be a terminal", tt.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imgcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// HTTPClient sets the client EncodeURL fetches images with, which is
+// http.DefaultClient by default. The client of an httpcache.Transport caches
+// the images fetched, so displaying them again doesn't download them again.
+func HTTPClient(client *http.Client) Option {
+	return func(c *config) { c.client = client }
+}
+
+// EncodeURL fetches the image at the given URL and encodes it into the output,
+// named after the last element of the URL's path unless given a Name.
+func (enc *Encoder) EncodeURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return fmt.Errorf("could not parse URL: %v", err)
+	}
+	res, err := enc.fetch(u)
+	if err != nil {
+		return fmt.Errorf("could not fetch image: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not fetch %s: %s", u.Redacted(), res.Status)
+	}
+	return enc.encodeNamed(u, res.Body)
+}
+
+// EncodeURLs fetches the images at the given URLs, parallel of them at a time,
+// and encodes them into the output in the order given, each as soon as it and
+// those before it are fetched. An image that can't be fetched or encoded is
+// replaced by a line such as
+//
+//	[error: https://example.com/a.png: 404 Not Found]
+//
+// and the error returned once all are done says how many there were. Errors
+// writing to the output stop at once.
+func (enc *Encoder) EncodeURLs(urls []string, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	type result struct {
+		u    *url.URL
+		data []byte
+		err  error
+	}
+	results := make([]chan result, len(urls))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+	work := make(chan int)
+	done := make(chan struct{})
+	defer close(done)
+	for w := 0; w < parallel; w++ {
+		go func() {
+			for i := range work {
+				var r result
+				r.u, r.data, r.err = enc.download(urls[i])
+				results[i] <- r
+			}
+		}()
+	}
+	go func() {
+		defer close(work)
+		for i := range urls {
+			select {
+			case work <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	failed := 0
+	for i, rawurl := range urls {
+		r := <-results[i]
+		err := r.err
+		if err == nil {
+			err = enc.encodeNamed(r.u, bytes.NewReader(r.data))
+		}
+		if err == nil {
+			continue
+		}
+		failed++
+		if _, werr := fmt.Fprintf(enc.out, "[error: %s: %v]\n", redacted(rawurl), err); werr != nil {
+			return werr
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images could not be displayed", failed, len(urls))
+	}
+	return nil
+}
+
+// download returns the parsed URL and the image fetched from it, or an error
+// that doesn't repeat the URL.
+func (enc *Encoder) download(rawurl string) (*url.URL, []byte, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, nil, errors.New("invalid URL")
+	}
+	res, err := enc.fetch(u)
+	if ue, ok := err.(*url.Error); ok {
+		err = ue.Err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, errors.New(res.Status)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read image: %v", err)
+	}
+	return u, data, nil
+}
+
+// fetch gets u with the client given with HTTPClient.
+func (enc *Encoder) fetch(u *url.URL) (*http.Response, error) {
+	client := newConfig(enc.options).client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Get(u.String())
+}
+
+// encodeNamed encodes the image read from r, named after u.
+func (enc *Encoder) encodeNamed(u *url.URL, r io.Reader) error {
+	// Options given to the Encoder win over the name, as they come after.
+	named := *enc
+	if name := path.Base(u.Path); name != "." && name != "/" {
+		named.options = append([]Option{Name(name)}, enc.options...)
+	}
+	err := named.Encode(r)
+	enc.pending = named.pending
+	return err
+}
+
+// redacted returns rawurl with its password hidden, if it can be parsed.
+func redacted(rawurl string) string {
+	if u, err := url.Parse(rawurl); err == nil {
+		return u.Redacted()
+	}
+	return rawurl
+}
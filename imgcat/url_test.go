@@ -0,0 +1,133 @@
+package imgcat
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/campoy/tools/imgcat/httpcache"
+)
+
+func TestEncodeURL(t *testing.T) {
+	hits := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("test"))
+	}))
+	defer s.Close()
+
+	tc := []struct {
+		name    string
+		path    string
+		options []Option
+		out     string
+	}{
+		{"named after the path", "/badges/build.svg?branch=main", nil, "\x1b]1337;File=name=YnVpbGQuc3Zn:dGVzdA==\a\n"},
+		{"no name", "/", nil, "\x1b]1337;File=:dGVzdA==\a\n"},
+		{"given name", "/a.png", []Option{Name("test")}, "\x1b]1337;File=name=dGVzdA==:dGVzdA==\a\n"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			enc := &Encoder{out: buf, options: append([]Option{Passthrough(false)}, tt.options...)}
+			if err := enc.EncodeURL(s.URL + tt.path); err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			if got := buf.String(); got != tt.out {
+				t.Fatalf("expected %q; got %q", tt.out, got)
+			}
+		})
+	}
+
+	enc := &Encoder{out: new(bytes.Buffer)}
+	if err := enc.EncodeURL(s.URL + "/missing.png"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected not found error; got %v", err)
+	}
+	if err := enc.EncodeURL("http://[::1"); err == nil {
+		t.Fatalf("expected error for a bad URL; got nothing")
+	}
+
+	// Images are fetched with the given client.
+	hits = 0
+	client := httpcache.NewTransport(nil, httpcache.NewMemoryStore()).Client()
+	enc = &Encoder{out: new(bytes.Buffer), options: []Option{HTTPClient(client)}}
+	for i := 0; i < 3; i++ {
+		if err := enc.EncodeURL(s.URL + "/avatar.png"); err != nil {
+			t.Fatalf("could not encode: %v", err)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("expected a single request; got %d", hits)
+	}
+}
+
+func TestEncodeURLs(t *testing.T) {
+	var mu sync.Mutex
+	active, most := 0, 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > most {
+			most = active
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+		// The first images take the longest, but are displayed first.
+		switch r.URL.Path {
+		case "/a.png":
+			time.Sleep(30 * time.Millisecond)
+		case "/b.png":
+			time.Sleep(10 * time.Millisecond)
+		case "/missing.png":
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/")))
+	}))
+	defer s.Close()
+
+	buf := new(bytes.Buffer)
+	enc := &Encoder{out: buf, options: []Option{Passthrough(false)}}
+	urls := []string{s.URL + "/a.png", s.URL + "/missing.png", s.URL + "/b.png", "http://[::1", s.URL + "/c.png"}
+	err := enc.EncodeURLs(urls, 2)
+	if err == nil || !strings.Contains(err.Error(), "2 of 5") {
+		t.Fatalf("expected 2 of 5 images failing; got %v", err)
+	}
+	want := "\x1b]1337;File=name=YS5wbmc=;size=5:YS5wbmc=\a\n" +
+		"[error: " + s.URL + "/missing.png: 404 Not Found]\n" +
+		"\x1b]1337;File=name=Yi5wbmc=;size=5:Yi5wbmc=\a\n" +
+		"[error: http://[::1: invalid URL]\n" +
+		"\x1b]1337;File=name=Yy5wbmc=;size=5:Yy5wbmc=\a\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+	if most > 2 {
+		t.Fatalf("expected at most 2 concurrent requests; got %d", most)
+	}
+
+	buf.Reset()
+	if err := enc.EncodeURLs(urls[2:3], 0); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatalf("expected an image; got nothing")
+	}
+
+	enc = &Encoder{out: badWriter{}}
+	if err := enc.EncodeURLs(urls, 2); err == nil || strings.Contains(err.Error(), "of 5") {
+		t.Fatalf("expected a write error; got %v", err)
+	}
+}
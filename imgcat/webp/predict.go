@@ -0,0 +1,167 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webp
+
+// numPredictors is the number of prediction modes of the predictor transform.
+const numPredictors = 14
+
+// predict applies the predictor transform to the w by h pixels px, picking for
+// every block of 1<<predictorBits pixels the mode with the smallest residuals.
+// It returns the residuals, and the image of the modes, one pixel per block.
+func predict(px []uint32, w, h int) (residuals, modes []uint32) {
+	size := 1 << predictorBits
+	bw, bh := (w+size-1)/size, (h+size-1)/size
+	modes = make([]uint32, bw*bh)
+	residuals = make([]uint32, len(px))
+
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			best, bestCost := 0, -1
+			for mode := 0; mode < numPredictors; mode++ {
+				cost := 0
+				for y := by * size; y < h && y < (by+1)*size; y++ {
+					for x := bx * size; x < w && x < (bx+1)*size; x++ {
+						cost += residualCost(px[y*w+x], predictor(px, w, x, y, mode))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[by*bw+bx] = 0xff000000 | uint32(best)<<8
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			mode := int(modes[(y>>predictorBits)*bw+x>>predictorBits] >> 8 & 0xf)
+			residuals[y*w+x] = sub(px[y*w+x], predictor(px, w, x, y, mode))
+		}
+	}
+	return residuals, modes
+}
+
+// predictor returns the prediction of the pixel at x, y of px, w pixels wide,
+// for the given mode. The first row and column have fixed modes.
+func predictor(px []uint32, w, x, y, mode int) uint32 {
+	i := y*w + x
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return px[i-1]
+	case x == 0:
+		return px[i-w]
+	}
+	// The top right pixel of the last column is the first one of the row.
+	l, t, tl, tr := px[i-1], px[i-w], px[i-w-1], px[i-w+1]
+	switch mode {
+	case 0:
+		return 0xff000000
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 5:
+		return average(average(l, tr), t)
+	case 6:
+		return average(l, tl)
+	case 7:
+		return average(l, t)
+	case 8:
+		return average(tl, t)
+	case 9:
+		return average(t, tr)
+	case 10:
+		return average(average(l, tl), average(t, tr))
+	case 11:
+		return selectPixel(l, t, tl)
+	case 12:
+		return channels(func(c int) int { return clamp(ch(l, c) + ch(t, c) - ch(tl, c)) })
+	default:
+		a := average(l, t)
+		return channels(func(c int) int { return clamp(ch(a, c) + (ch(a, c)-ch(tl, c))/2) })
+	}
+}
+
+// ch returns the channel of p shifted by c bits.
+func ch(p uint32, c int) int { return int(p >> uint(c) & 0xff) }
+
+// channels returns the pixel whose channels, shifted by 0, 8, 16, and 24
+// bits, are given by f.
+func channels(f func(c int) int) uint32 {
+	var p uint32
+	for c := 0; c < 32; c += 8 {
+		p |= uint32(f(c)) << uint(c)
+	}
+	return p
+}
+
+// average returns the average of the channels of a and b, rounded down.
+func average(a, b uint32) uint32 {
+	return (a^b)&0xfefefefe>>1 + a&b
+}
+
+// selectPixel returns l or t, whichever is closest to l+t-tl.
+func selectPixel(l, t, tl uint32) uint32 {
+	pl, pt := 0, 0
+	for c := 0; c < 32; c += 8 {
+		pl += abs(ch(t, c) - ch(tl, c))
+		pt += abs(ch(l, c) - ch(tl, c))
+	}
+	if pl < pt {
+		return l
+	}
+	return t
+}
+
+// sub returns the difference of the channels of a and b, modulo 256.
+func sub(a, b uint32) uint32 {
+	// Every other channel is subtracted at once, borrowing from the unused
+	// bits in between.
+	ag := 0x00ff00ff + a&0xff00ff00 - b&0xff00ff00
+	rb := 0xff00ff00 + a&0x00ff00ff - b&0x00ff00ff
+	return ag&0xff00ff00 | rb&0x00ff00ff
+}
+
+// residualCost estimates the cost of coding the difference of p and its
+// prediction.
+func residualCost(p, prediction uint32) int {
+	cost := 0
+	for c := 0; c < 32; c += 8 {
+		cost += abs(int(int8(ch(p, c) - ch(prediction, c))))
+	}
+	return cost
+}
+
+func clamp(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 0xff {
+		return 0xff
+	}
+	return v
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
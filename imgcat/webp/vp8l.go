@@ -0,0 +1,534 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webp
+
+import "sort"
+
+// Constants of the VP8L bitstream, see RFC 9649.
+const (
+	vp8lSignature      = 0x2f
+	predictorTransform = 0
+	subtractGreen      = 2
+	numLengthCodes     = 24
+	numDistanceCodes   = 40
+	numLiterals        = 256
+	maxCodeLength      = 15
+	maxCodeLengthCode  = 7
+	numCodeLengthCodes = 19
+	// distanceOffset is added to distances that are not coded as one of the
+	// 120 short offsets in two dimensions.
+	distanceOffset = 120
+)
+
+// Settings of the encoder.
+const (
+	// cacheBits is the log2 of the number of colors in the color cache.
+	cacheBits = 10
+	// predictorBits is the log2 of the size of the blocks sharing a
+	// predictor.
+	predictorBits = 4
+	// minMatch and maxMatch bound the length of backward references, and
+	// window their distance, in pixels.
+	minMatch = 3
+	maxMatch = 4096
+	window   = 1 << 16
+	// hashBits is the size of the hash table finding matches, and chainDepth
+	// the number of candidates tried for every pixel.
+	hashBits   = 16
+	chainDepth = 32
+)
+
+// codeLengthOrder is the order in which the code lengths of the code lengths
+// are written.
+var codeLengthOrder = [numCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeVP8L returns the VP8L bitstream of the w by h ARGB pixels px.
+// It uses the subtract green and predictor transforms, backward references,
+// and a color cache, with a single set of prefix codes for the whole image.
+func encodeVP8L(px []uint32, w, h int) []byte {
+	alpha := uint32(0)
+	for i, p := range px {
+		if p>>24 != 0xff {
+			alpha = 1
+		}
+		// Subtract green from red and blue, as decoders add it back.
+		g := p >> 8 & 0xff
+		r := (p>>16 - g) & 0xff
+		b := (p - g) & 0xff
+		px[i] = p&0xff00ff00 | r<<16 | b
+	}
+
+	bw := new(bitWriter)
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(w-1), 14)
+	bw.write(uint32(h-1), 14)
+	bw.write(alpha, 1)
+	bw.write(0, 3)
+
+	bw.write(1, 1)
+	bw.write(subtractGreen, 2)
+
+	residuals, modes := predict(px, w, h)
+	bw.write(1, 1)
+	bw.write(predictorTransform, 2)
+	bw.write(predictorBits-2, 3)
+	size := 1 << predictorBits
+	writeImage(bw, modes, (w+size-1)/size, 0, false)
+	bw.write(0, 1)
+
+	writeImage(bw, residuals, w, cacheBits, true)
+	return bw.bytes()
+}
+
+// writeImage writes the entropy coded image of the pixels px, w pixels wide,
+// with a color cache of 1<<bits colors if bits is not zero. Only the main image
+// says it has no meta prefix codes.
+func writeImage(bw *bitWriter, px []uint32, w int, bits uint, main bool) {
+	tokens := lz77(px, w)
+	cacheSize := 0
+	if bits > 0 {
+		bw.write(1, 1)
+		bw.write(uint32(bits), 4)
+		tokens = cache(tokens, bits)
+		cacheSize = 1 << bits
+	} else {
+		bw.write(0, 1)
+	}
+	if main {
+		bw.write(0, 1)
+	}
+
+	hist := [5][]int{
+		make([]int, numLiterals+numLengthCodes+cacheSize),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numDistanceCodes),
+	}
+	for _, t := range tokens {
+		switch {
+		case t.length > 0:
+			lc, _, _ := prefixEncode(t.length)
+			dc, _, _ := prefixEncode(distanceCode(t.dist, w))
+			hist[0][numLiterals+lc]++
+			hist[4][dc]++
+		case t.index >= 0:
+			hist[0][numLiterals+numLengthCodes+t.index]++
+		default:
+			hist[0][t.argb>>8&0xff]++
+			hist[1][t.argb>>16&0xff]++
+			hist[2][t.argb&0xff]++
+			hist[3][t.argb>>24]++
+		}
+	}
+	var codes [5]*prefixCode
+	for i := range hist {
+		codes[i] = writeCode(bw, hist[i])
+	}
+
+	for _, t := range tokens {
+		switch {
+		case t.length > 0:
+			lc, lbits, lextra := prefixEncode(t.length)
+			codes[0].write(bw, numLiterals+lc)
+			bw.write(lextra, lbits)
+			dc, dbits, dextra := prefixEncode(distanceCode(t.dist, w))
+			codes[4].write(bw, dc)
+			bw.write(dextra, dbits)
+		case t.index >= 0:
+			codes[0].write(bw, numLiterals+numLengthCodes+t.index)
+		default:
+			codes[0].write(bw, int(t.argb>>8&0xff))
+			codes[1].write(bw, int(t.argb>>16&0xff))
+			codes[2].write(bw, int(t.argb&0xff))
+			codes[3].write(bw, int(t.argb>>24))
+		}
+	}
+}
+
+// A token is a literal pixel, a color cache index, or a backward reference.
+type token struct {
+	argb uint32
+	// index in the color cache, or -1.
+	index int
+	// length of the backward reference, or 0, and its distance, in pixels.
+	length, dist int
+}
+
+// lz77 returns the tokens coding px, an image w pixels wide, with backward
+// references wherever earlier pixels repeat.
+func lz77(px []uint32, w int) []token {
+	hash := func(i int) uint32 {
+		return (px[i]*0x1e35a7bd ^ px[i+1]*0x9e3779b1) >> (32 - hashBits)
+	}
+	head := make([]int32, 1<<hashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, len(px))
+	insert := func(i int) {
+		if i+1 < len(px) {
+			h := hash(i)
+			prev[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+	matchLen := func(i, j int) int {
+		n := 0
+		for i+n < len(px) && n < maxMatch && px[i+n] == px[j+n] {
+			n++
+		}
+		return n
+	}
+
+	var tokens []token
+	for i := 0; i < len(px); {
+		best, dist := 0, 0
+		try := func(j int) {
+			if j < 0 || i-j > window {
+				return
+			}
+			if n := matchLen(i, j); n > best {
+				best, dist = n, i-j
+			}
+		}
+		// The pixels on the left and above have the shortest distance codes.
+		try(i - 1)
+		try(i - w)
+		if i+1 < len(px) {
+			j := head[hash(i)]
+			for depth := 0; j >= 0 && depth < chainDepth; depth++ {
+				try(int(j))
+				j = prev[j]
+			}
+		}
+		if best < minMatch {
+			tokens = append(tokens, token{argb: px[i], index: -1})
+			insert(i)
+			i++
+			continue
+		}
+		tokens = append(tokens, token{index: -1, length: best, dist: dist})
+		for k := 0; k < best; k++ {
+			insert(i + k)
+		}
+		i += best
+	}
+	return tokens
+}
+
+// distanceCode returns the VP8L code of a distance in an image w pixels wide.
+func distanceCode(dist, w int) int {
+	switch dist {
+	case w:
+		// One row up.
+		return 1
+	case 1:
+		// One pixel to the left.
+		return 2
+	}
+	return dist + distanceOffset
+}
+
+// cache replaces the literals of tokens that are in a color cache of 1<<bits
+// colors with their index in it. Decoders insert every pixel in the cache, so
+// its contents follow the pixels coded so far.
+func cache(tokens []token, bits uint) []token {
+	colors := make([]uint32, 1<<bits)
+	valid := make([]bool, 1<<bits)
+	add := func(p uint32) {
+		k := (0x1e35a7bd * p) >> (32 - bits)
+		colors[k], valid[k] = p, true
+	}
+	var px []uint32
+	for i, t := range tokens {
+		if t.length > 0 {
+			// The pixels coded so far are kept to add the copied ones.
+			for k := 0; k < t.length; k++ {
+				p := px[len(px)-t.dist]
+				px = append(px, p)
+				add(p)
+			}
+			continue
+		}
+		k := (0x1e35a7bd * t.argb) >> (32 - bits)
+		if valid[k] && colors[k] == t.argb {
+			tokens[i].index = int(k)
+		}
+		px = append(px, t.argb)
+		add(t.argb)
+	}
+	return tokens
+}
+
+// prefixEncode returns the prefix code of v, a length or distance code of at
+// least 1, and its extra bits.
+func prefixEncode(v int) (code int, bits uint, extra uint32) {
+	d := uint32(v - 1)
+	if d < 4 {
+		return int(d), 0, 0
+	}
+	high := uint(31)
+	for d>>high == 0 {
+		high--
+	}
+	second := d >> (high - 1) & 1
+	bits = high - 1
+	return int(2*high + uint(second)), bits, d & (1<<bits - 1)
+}
+
+// A bitWriter writes bits least significant first, as VP8L does.
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint
+}
+
+// write writes the n low bits of v, n being at most 32.
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v&(1<<n-1)) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.n -= 8
+	}
+}
+
+// bytes returns what was written, padded with zero bits to a whole byte.
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.n = 0, 0
+	}
+	return w.buf
+}
+
+// A prefixCode is a canonical Huffman code.
+type prefixCode struct {
+	lengths []uint8
+	// codes are bit reversed, as they're written most significant bit first.
+	codes []uint32
+}
+
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	w.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// newPrefixCode returns the canonical code with the given code lengths.
+func newPrefixCode(lengths []uint8) *prefixCode {
+	var count [maxCodeLength + 1]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [maxCodeLength + 2]uint32
+	for l := 1; l <= maxCodeLength; l++ {
+		next[l+1] = (next[l] + count[l]) << 1
+	}
+	c := &prefixCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		code := next[l]
+		next[l]++
+		rev := uint32(0)
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | code>>i&1
+		}
+		c.codes[s] = rev
+	}
+	return c
+}
+
+// writeCode writes the prefix code of the given symbol counts, and returns it.
+func writeCode(w *bitWriter, counts []int) *prefixCode {
+	used, last := 0, 0
+	for s, n := range counts {
+		if n > 0 {
+			used, last = used+1, s
+		}
+	}
+	if used <= 1 && last < numLiterals {
+		// A simple code with a single symbol, coded with no bits at all.
+		w.write(1, 1)
+		w.write(0, 1)
+		if last < 2 {
+			w.write(0, 1)
+			w.write(uint32(last), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(last), 8)
+		}
+		return newPrefixCode(make([]uint8, len(counts)))
+	}
+
+	lengths := codeLengths(counts, maxCodeLength)
+	w.write(0, 1)
+
+	// Code lengths are run length coded: 16 repeats the previous length 3 to
+	// 6 times, 17 and 18 write 3 to 10 and 11 to 138 zeros.
+	type run struct {
+		symbol uint8
+		extra  uint32
+		bits   uint
+	}
+	var runs []run
+	for i := 0; i < len(lengths); {
+		v, n := lengths[i], 1
+		for i+n < len(lengths) && lengths[i+n] == v {
+			n++
+		}
+		i += n
+		if v == 0 {
+			for n >= 11 {
+				k := min(n, 138)
+				runs = append(runs, run{18, uint32(k - 11), 7})
+				n -= k
+			}
+			if n >= 3 {
+				runs = append(runs, run{17, uint32(n - 3), 3})
+				n = 0
+			}
+		} else {
+			runs = append(runs, run{v, 0, 0})
+			n--
+			for n >= 3 {
+				k := min(n, 6)
+				runs = append(runs, run{16, uint32(k - 3), 2})
+				n -= k
+			}
+		}
+		for ; n > 0; n-- {
+			runs = append(runs, run{v, 0, 0})
+		}
+	}
+
+	clCounts := make([]int, numCodeLengthCodes)
+	for _, r := range runs {
+		clCounts[r.symbol]++
+	}
+	cl := newPrefixCode(codeLengths(clCounts, maxCodeLengthCode))
+	n := numCodeLengthCodes
+	for n > 4 && cl.lengths[codeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	w.write(uint32(n-4), 4)
+	for _, s := range codeLengthOrder[:n] {
+		w.write(uint32(cl.lengths[s]), 3)
+	}
+	// All the symbols of the alphabet are coded.
+	w.write(0, 1)
+	for _, r := range runs {
+		cl.write(w, int(r.symbol))
+		w.write(r.extra, r.bits)
+	}
+	return newPrefixCode(lengths)
+}
+
+// codeLengths returns the lengths of a Huffman code for the given symbol
+// counts, of at most limit bits. At least two symbols get a code, so no
+// symbol is coded with zero bits.
+func codeLengths(counts []int, limit int) []uint8 {
+	var symbols []int
+	for s, n := range counts {
+		if n > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	for s := 0; len(symbols) < 2; s++ {
+		if counts[s] == 0 {
+			symbols = append(symbols, s)
+		}
+	}
+
+	lengths := make([]uint8, len(counts))
+	// Codes that are too long are avoided by raising the smallest counts
+	// until the tree is shallow enough.
+	for floor := 1; ; floor *= 2 {
+		weights := make([]int, len(symbols))
+		for i, s := range symbols {
+			weights[i] = max(counts[s], floor)
+		}
+		depths := huffman(weights)
+		deepest := 0
+		for _, d := range depths {
+			deepest = max(deepest, d)
+		}
+		if deepest <= limit {
+			for i, s := range symbols {
+				lengths[s] = uint8(depths[i])
+			}
+			return lengths
+		}
+	}
+}
+
+// huffman returns the depths of the leaves in a Huffman tree with the given
+// weights, of which there are at least two.
+func huffman(weights []int) []int {
+	n := len(weights)
+	// Nodes are the leaves, sorted by weight, followed by the internal nodes
+	// in the order they're created, which is also by weight.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return weights[order[i]] < weights[order[j]] })
+	weight := make([]int, 0, 2*n-1)
+	for _, i := range order {
+		weight = append(weight, weights[i])
+	}
+	parent := make([]int, 2*n-1)
+
+	leaf, inner := 0, n
+	pick := func() int {
+		if leaf < n && (inner >= len(weight) || weight[leaf] <= weight[inner]) {
+			leaf++
+			return leaf - 1
+		}
+		inner++
+		return inner - 1
+	}
+	for len(weight) < 2*n-1 {
+		a, b := pick(), pick()
+		parent[a], parent[b] = len(weight), len(weight)
+		weight = append(weight, weight[a]+weight[b])
+	}
+
+	depth := make([]int, 2*n-1)
+	for i := 2*n - 3; i >= 0; i-- {
+		depth[i] = depth[parent[i]] + 1
+	}
+	depths := make([]int, n)
+	for k, i := range order {
+		depths[i] = depth[k]
+	}
+	return depths
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webp encodes images in the lossless WebP format, in pure Go.
+//
+// WebP payloads are usually smaller than PNG ones, especially for screenshots
+// and other images with repeated areas, which matters when sending images
+// through tmux or SSH. iTerm2 displays them on macOS 11 and later.
+package webp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// MaxSize is the largest width and height of WebP images.
+const MaxSize = 1 << 14
+
+// ErrTooLarge is returned when encoding images wider or taller than MaxSize.
+var ErrTooLarge = errors.New("image too large for webp")
+
+// Encode writes img to w as a lossless WebP image.
+func Encode(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	if b.Dx() > MaxSize || b.Dy() > MaxSize {
+		return ErrTooLarge
+	}
+	if b.Empty() {
+		return errors.New("cannot encode an empty image as webp")
+	}
+	data := encodeVP8L(argb(img), b.Dx(), b.Dy())
+
+	// The RIFF container, with the VP8L chunk padded to an even size.
+	size := len(data)
+	buf := bufio.NewWriter(w)
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(4+8+size+size%2))
+	buf.WriteString("WEBPVP8L")
+	binary.Write(buf, binary.LittleEndian, uint32(size))
+	buf.Write(data)
+	if size%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Flush()
+}
+
+// argb returns the pixels of img, row by row, as non premultiplied ARGB.
+func argb(img image.Image) []uint32 {
+	b := img.Bounds()
+	px := make([]uint32, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			px = append(px, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+	return px
+}
@@ -0,0 +1,688 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// The transforms not used by the encoder.
+const (
+	crossColorTransform    = 1
+	colorIndexingTransform = 3
+)
+
+// decode is a VP8L decoder written after RFC 9649, to check the output of the
+// encoder and, on files written by libwebp, to check the decoder itself.
+func decode(data []byte) (*image.NRGBA, error) {
+	if len(data) < 20 || string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		return nil, errors.New("not a VP8L file")
+	}
+	if size := binary.LittleEndian.Uint32(data[4:]); int(size) != len(data)-8 {
+		return nil, fmt.Errorf("RIFF size %d for %d bytes", size, len(data)-8)
+	}
+	size := binary.LittleEndian.Uint32(data[16:])
+	if int(size+size%2) != len(data)-20 {
+		return nil, fmt.Errorf("chunk size %d for %d bytes", size, len(data)-20)
+	}
+	br := &bitReader{data: data[20 : 20+size]}
+	if br.read(8) != vp8lSignature {
+		return nil, errors.New("bad signature")
+	}
+	w, h := int(br.read(14))+1, int(br.read(14))+1
+	br.read(1)
+	if v := br.read(3); v != 0 {
+		return nil, fmt.Errorf("bad version %d", v)
+	}
+
+	// A transform, with the width of the image it applies to.
+	type transform struct {
+		kind, w int
+		bits    uint
+		data    []uint32
+	}
+	var transforms []transform
+	xsize := w
+	for br.read(1) == 1 {
+		t := transform{kind: int(br.read(2)), w: xsize}
+		for _, seen := range transforms {
+			if seen.kind == t.kind {
+				return nil, fmt.Errorf("transform %d used twice", t.kind)
+			}
+		}
+		var err error
+		switch t.kind {
+		case subtractGreen:
+		case predictorTransform, crossColorTransform:
+			t.bits = uint(br.read(3)) + 2
+			size := 1 << t.bits
+			t.data, err = decodeImage(br, (xsize+size-1)/size, (h+size-1)/size, false)
+		case colorIndexingTransform:
+			n := int(br.read(8)) + 1
+			if t.data, err = decodeImage(br, n, 1, false); err != nil {
+				break
+			}
+			// The palette is coded as differences with the previous color.
+			for i := 1; i < n; i++ {
+				t.data[i] = channels(func(c int) int { return (ch(t.data[i], c) + ch(t.data[i-1], c)) & 0xff })
+			}
+			switch {
+			case n <= 2:
+				t.bits = 3
+			case n <= 4:
+				t.bits = 2
+			case n <= 16:
+				t.bits = 1
+			}
+			xsize = xsizeOf(xsize, t.bits)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transform %d: %v", t.kind, err)
+		}
+		transforms = append(transforms, t)
+	}
+	px, err := decodeImage(br, xsize, h, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := len(transforms) - 1; k >= 0; k-- {
+		t := transforms[k]
+		size := 1 << t.bits
+		mw := (t.w + size - 1) / size
+		switch t.kind {
+		case subtractGreen:
+			for i, p := range px {
+				g := p >> 8 & 0xff
+				r, b := (p>>16+g)&0xff, (p+g)&0xff
+				px[i] = p&0xff00ff00 | r<<16 | b
+			}
+		case predictorTransform:
+			for y := 0; y < h; y++ {
+				for x := 0; x < t.w; x++ {
+					mode := int(t.data[(y/size)*mw+x/size]>>8) & 0xf
+					if mode >= numPredictors {
+						return nil, fmt.Errorf("bad predictor %d", mode)
+					}
+					pred := predictor(px, t.w, x, y, mode)
+					i := y*t.w + x
+					px[i] = channels(func(c int) int { return (ch(px[i], c) + ch(pred, c)) & 0xff })
+				}
+			}
+		case crossColorTransform:
+			delta := func(m, c uint32) uint32 { return uint32(int(int8(m))*int(int8(c))>>5) & 0xff }
+			for y := 0; y < h; y++ {
+				for x := 0; x < t.w; x++ {
+					m := t.data[(y/size)*mw+x/size]
+					i := y*t.w + x
+					p := px[i]
+					g := p >> 8 & 0xff
+					r := (p>>16 + delta(m, g)) & 0xff
+					b := (p + delta(m>>8, g) + delta(m>>16, r)) & 0xff
+					px[i] = p&0xff00ff00 | r<<16 | b
+				}
+			}
+		case colorIndexingTransform:
+			// Pixels are bundled, several indexes in the green of each one.
+			perPixel := 1 << t.bits
+			bits := uint(8 >> t.bits)
+			out := make([]uint32, t.w*h)
+			for y := 0; y < h; y++ {
+				for x := 0; x < t.w; x++ {
+					idx := int(px[y*xsizeOf(t.w, t.bits)+x/perPixel]>>8>>(uint(x%perPixel)*bits)) & (1<<bits - 1)
+					if idx < len(t.data) {
+						out[y*t.w+x] = t.data[idx]
+					}
+				}
+			}
+			px = out
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i, p := range px {
+		img.Pix[4*i], img.Pix[4*i+1], img.Pix[4*i+2], img.Pix[4*i+3] = uint8(p>>16), uint8(p>>8), uint8(p), uint8(p>>24)
+	}
+	return img, nil
+}
+
+// xsizeOf returns the width of an image w pixels wide once bundled 1<<bits
+// pixels to one.
+func xsizeOf(w int, bits uint) int { return (w + 1<<bits - 1) >> bits }
+
+// distances holds the offsets, in columns and rows, of the first 120 distance
+// codes.
+var distances = [120][2]int{
+	{0, 1}, {1, 0}, {1, 1}, {-1, 1}, {0, 2}, {2, 0}, {1, 2}, {-1, 2},
+	{2, 1}, {-2, 1}, {2, 2}, {-2, 2}, {0, 3}, {3, 0}, {1, 3}, {-1, 3},
+	{3, 1}, {-3, 1}, {2, 3}, {-2, 3}, {3, 2}, {-3, 2}, {0, 4}, {4, 0},
+	{1, 4}, {-1, 4}, {4, 1}, {-4, 1}, {3, 3}, {-3, 3}, {2, 4}, {-2, 4},
+	{4, 2}, {-4, 2}, {0, 5}, {3, 4}, {-3, 4}, {4, 3}, {-4, 3}, {5, 0},
+	{1, 5}, {-1, 5}, {5, 1}, {-5, 1}, {2, 5}, {-2, 5}, {5, 2}, {-5, 2},
+	{4, 4}, {-4, 4}, {3, 5}, {-3, 5}, {5, 3}, {-5, 3}, {0, 6}, {6, 0},
+	{1, 6}, {-1, 6}, {6, 1}, {-6, 1}, {2, 6}, {-2, 6}, {6, 2}, {-6, 2},
+	{4, 5}, {-4, 5}, {5, 4}, {-5, 4}, {3, 6}, {-3, 6}, {6, 3}, {-6, 3},
+	{0, 7}, {7, 0}, {1, 7}, {-1, 7}, {5, 5}, {-5, 5}, {7, 1}, {-7, 1},
+	{4, 6}, {-4, 6}, {6, 4}, {-6, 4}, {2, 7}, {-2, 7}, {7, 2}, {-7, 2},
+	{3, 7}, {-3, 7}, {7, 3}, {-7, 3}, {5, 6}, {-5, 6}, {6, 5}, {-6, 5},
+	{8, 0}, {4, 7}, {-4, 7}, {7, 4}, {-7, 4}, {8, 1}, {8, 2}, {6, 6},
+	{-6, 6}, {8, 3}, {5, 7}, {-5, 7}, {7, 5}, {-7, 5}, {8, 4}, {6, 7},
+	{-6, 7}, {7, 6}, {-7, 6}, {8, 5}, {7, 7}, {-7, 7}, {8, 6}, {8, 7},
+}
+
+// decodeImage decodes an entropy coded image of w by h pixels. Only the main
+// image may have meta prefix codes, picking the codes of every block.
+func decodeImage(br *bitReader, w, h int, main bool) ([]uint32, error) {
+	bits := uint(0)
+	if br.read(1) == 1 {
+		if bits = uint(br.read(4)); bits < 1 || bits > 11 {
+			return nil, fmt.Errorf("bad color cache bits %d", bits)
+		}
+	}
+	var groupBits uint
+	var groupImage []uint32
+	groups := 1
+	if main && br.read(1) == 1 {
+		groupBits = uint(br.read(3)) + 2
+		var err error
+		if groupImage, err = decodeImage(br, xsizeOf(w, groupBits), xsizeOf(h, groupBits), false); err != nil {
+			return nil, fmt.Errorf("meta prefix codes: %v", err)
+		}
+		for i, p := range groupImage {
+			groupImage[i] = p >> 8 & 0xffff
+			if int(groupImage[i]) >= groups {
+				groups = int(groupImage[i]) + 1
+			}
+		}
+	}
+	cacheSize := 0
+	if bits > 0 {
+		cacheSize = 1 << bits
+	}
+	sizes := []int{256 + 24 + cacheSize, 256, 256, 256, 40}
+	codes := make([][5]*testCode, groups)
+	for g := range codes {
+		for i, n := range sizes {
+			c, err := readCode(br, n)
+			if err != nil {
+				return nil, fmt.Errorf("code %d of group %d: %v", i, g, err)
+			}
+			codes[g][i] = c
+		}
+	}
+
+	cache := make([]uint32, cacheSize)
+	px := make([]uint32, 0, w*h)
+	add := func(p uint32) {
+		px = append(px, p)
+		if bits > 0 {
+			cache[(0x1e35a7bd*p)>>(32-bits)] = p
+		}
+	}
+	value := func(prefix int) int {
+		if prefix < 4 {
+			return prefix + 1
+		}
+		extra := uint((prefix - 2) >> 1)
+		offset := (2 + prefix&1) << extra
+		return offset + int(br.read(extra)) + 1
+	}
+	for len(px) < w*h {
+		if br.err != nil {
+			return nil, br.err
+		}
+		group := &codes[0]
+		if groupImage != nil {
+			x, y := len(px)%w, len(px)/w
+			group = &codes[groupImage[(y>>groupBits)*xsizeOf(w, groupBits)+x>>groupBits]]
+		}
+		s := group[0].read(br)
+		switch {
+		case s < 256:
+			g := uint32(s)
+			r, b, a := uint32(group[1].read(br)), uint32(group[2].read(br)), uint32(group[3].read(br))
+			add(a<<24 | r<<16 | g<<8 | b)
+		case s < 256+24:
+			length := value(s - 256)
+			code := value(group[4].read(br))
+			dist := code - 120
+			if code <= 120 {
+				d := distances[code-1]
+				if dist = d[0] + d[1]*w; dist < 1 {
+					dist = 1
+				}
+			}
+			if dist > len(px) || len(px)+length > w*h {
+				return nil, fmt.Errorf("bad backward reference %d, %d at %d", length, dist, len(px))
+			}
+			for k := 0; k < length; k++ {
+				add(px[len(px)-dist])
+			}
+		default:
+			add(cache[s-256-24])
+		}
+	}
+	return px, br.err
+}
+
+type bitReader struct {
+	data []byte
+	pos  uint
+	err  error
+}
+
+func (r *bitReader) read(n uint) uint32 {
+	v := uint32(0)
+	for i := uint(0); i < n; i++ {
+		if r.pos/8 >= uint(len(r.data)) {
+			r.err = errors.New("unexpected end of data")
+			return 0
+		}
+		v |= uint32(r.data[r.pos/8]>>(r.pos%8)&1) << i
+		r.pos++
+	}
+	return v
+}
+
+// A testCode decodes a canonical prefix code bit by bit.
+type testCode struct {
+	single  int
+	symbols map[[2]int]int
+}
+
+func newTestCode(lengths []int) (*testCode, error) {
+	c := &testCode{single: -1, symbols: map[[2]int]int{}}
+	var nonzero []int
+	for s, l := range lengths {
+		if l > 0 {
+			nonzero = append(nonzero, s)
+		}
+	}
+	if len(nonzero) == 0 {
+		return nil, errors.New("empty code")
+	}
+	if len(nonzero) == 1 {
+		c.single = nonzero[0]
+		return c, nil
+	}
+	// Codes must be complete.
+	kraft := 0
+	for _, s := range nonzero {
+		kraft += 1 << uint(15-lengths[s])
+	}
+	if kraft != 1<<15 {
+		return nil, fmt.Errorf("incomplete or oversubscribed code, kraft sum %d", kraft)
+	}
+	code := 0
+	for l := 1; l <= 15; l++ {
+		for s, sl := range lengths {
+			if sl == l {
+				c.symbols[[2]int{l, code}] = s
+				code++
+			}
+		}
+		code <<= 1
+	}
+	return c, nil
+}
+
+func (c *testCode) read(br *bitReader) int {
+	if c.single >= 0 {
+		return c.single
+	}
+	code := 0
+	for l := 1; l <= 15; l++ {
+		code = code<<1 | int(br.read(1))
+		if s, ok := c.symbols[[2]int{l, code}]; ok {
+			return s
+		}
+	}
+	br.err = errors.New("invalid code")
+	return 0
+}
+
+func readCode(br *bitReader, size int) (*testCode, error) {
+	lengths := make([]int, size)
+	if br.read(1) == 1 {
+		n := br.read(1) + 1
+		first := br.read(1)
+		s := int(br.read(1 + 7*uint(first)))
+		lengths[s] = 1
+		if n == 2 {
+			lengths[br.read(8)] = 1
+		}
+		return newTestCode(lengths)
+	}
+	n := int(br.read(4)) + 4
+	clLengths := make([]int, 19)
+	for i := 0; i < n; i++ {
+		clLengths[codeLengthOrder[i]] = int(br.read(3))
+	}
+	cl, err := newTestCode(clLengths)
+	if err != nil {
+		return nil, fmt.Errorf("code length code: %v", err)
+	}
+	maxSymbol := size
+	if br.read(1) == 1 {
+		bits := 2 + 2*uint(br.read(3))
+		maxSymbol = 2 + int(br.read(bits))
+	}
+	prev := 8
+	for s := 0; s < size; {
+		if maxSymbol == 0 {
+			break
+		}
+		maxSymbol--
+		l := cl.read(br)
+		switch {
+		case l < 16:
+			lengths[s] = l
+			s++
+			if l != 0 {
+				prev = l
+			}
+		default:
+			repeat, v := 0, 0
+			switch l {
+			case 16:
+				repeat, v = 3+int(br.read(2)), prev
+			case 17:
+				repeat = 3 + int(br.read(3))
+			case 18:
+				repeat = 11 + int(br.read(7))
+			}
+			if s+repeat > size {
+				return nil, errors.New("code lengths overflow")
+			}
+			for ; repeat > 0; repeat-- {
+				lengths[s] = v
+				s++
+			}
+		}
+		if br.err != nil {
+			return nil, br.err
+		}
+	}
+	return newTestCode(lengths)
+}
+
+func roundTrip(t *testing.T, img image.Image) {
+	buf := new(bytes.Buffer)
+	if err := Encode(buf, img); err != nil {
+		t.Fatalf("could not encode: %v", err)
+	}
+	got, err := decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	b := img.Bounds()
+	if got.Bounds().Size() != b.Size() {
+		t.Fatalf("expected size %v; got %v", b.Size(), got.Bounds().Size())
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			want := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y))
+			if c := got.NRGBAAt(x, y); c != want {
+				t.Fatalf("expected %v at %d,%d; got %v", want, x, y, c)
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	noise := image.NewNRGBA(image.Rect(0, 0, 67, 45))
+	random.Read(noise.Pix)
+
+	// Few colors with repeats, like a screenshot.
+	screen := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.NRGBA{0xf0, 0xf0, 0xf0, 0xff}
+			if y%20 < 12 && x%7 < 5 && (x/7+y/20)%3 != 0 {
+				c = color.NRGBA{0x20, 0x20, 0x40, 0xff}
+			}
+			if y < 16 {
+				c = color.NRGBA{0x30, 0x60, 0xc0, 0xff}
+			}
+			screen.SetNRGBA(x, y, c)
+		}
+	}
+
+	gradient := image.NewNRGBA(image.Rect(10, 10, 266, 30))
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 266; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y * 8), uint8(255 - x), uint8(x + y)})
+		}
+	}
+
+	single := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	single.SetNRGBA(0, 0, color.NRGBA{1, 2, 3, 4})
+
+	tc := []struct {
+		name string
+		img  image.Image
+	}{
+		{"single pixel", single},
+		{"uniform", image.NewUniform(color.RGBA{0x80, 0, 0, 0xff})},
+		{"noise", noise},
+		{"screenshot", screen},
+		{"gradient", gradient},
+		{"gray", image.NewGray(image.Rect(0, 0, 33, 1))},
+		{"column", image.NewNRGBA(image.Rect(0, 0, 1, 50))},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			img := tt.img
+			if _, ok := img.(*image.Uniform); ok {
+				u := image.NewRGBA(image.Rect(0, 0, 40, 30))
+				for i := range u.Pix {
+					u.Pix[i] = []uint8{0x80, 0, 0, 0xff}[i%4]
+				}
+				img = u
+			}
+			roundTrip(t, img)
+		})
+	}
+}
+
+func TestSmallerThanPNG(t *testing.T) {
+	screen := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.NRGBA{0xff, 0xff, 0xff, 0xff}
+			if (x/8+y/16)%5 == 0 {
+				c = color.NRGBA{0, 0, 0, 0xff}
+			}
+			screen.SetNRGBA(x, y, c)
+		}
+	}
+	p, w := new(bytes.Buffer), new(bytes.Buffer)
+	if err := png.Encode(p, screen); err != nil {
+		t.Fatal(err)
+	}
+	if err := Encode(w, screen); err != nil {
+		t.Fatal(err)
+	}
+	if w.Len() >= p.Len() {
+		t.Errorf("expected webp smaller than %d bytes of png; got %d bytes", p.Len(), w.Len())
+	}
+}
+
+func TestCodeLengths(t *testing.T) {
+	tc := []struct {
+		counts []int
+		limit  int
+	}{
+		{[]int{0, 5}, 15},
+		{[]int{1, 1, 1, 1}, 15},
+		{[]int{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048}, 7},
+		{[]int{1, 1, 2, 3, 5, 8, 13, 21, 34, 55, 89, 144, 233, 377, 610, 987, 1597, 2584, 4181, 6765}, 15},
+	}
+	for _, tt := range tc {
+		lengths := codeLengths(tt.counts, tt.limit)
+		kraft, used := 0, 0
+		for _, l := range lengths {
+			if int(l) > tt.limit {
+				t.Errorf("counts %v: code of %d bits, more than %d", tt.counts, l, tt.limit)
+			}
+			if l > 0 {
+				kraft += 1 << uint(15-l)
+				used++
+			}
+		}
+		if kraft != 1<<15 || used < 2 {
+			t.Errorf("counts %v: incomplete code %v", tt.counts, lengths)
+		}
+	}
+}
+
+func TestPrefixEncode(t *testing.T) {
+	for v := 1; v < 5000; v++ {
+		code, bits, extra := prefixEncode(v)
+		// Decode as described by the RFC.
+		got := code + 1
+		if code >= 4 {
+			eb := uint((code - 2) >> 1)
+			if eb != bits {
+				t.Fatalf("%d: expected %d extra bits; got %d", v, eb, bits)
+			}
+			got = (2+code&1)<<eb + int(extra) + 1
+		}
+		if got != v {
+			t.Fatalf("%d: encoded as %d, %d, decoding to %d", v, code, extra, got)
+		}
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	tc := []struct {
+		w, h  int
+		alpha bool
+	}{
+		{1, 1, false},
+		{1, 1, true},
+		{2, 3, false},
+		{33, 7, true},
+		{MaxSize, 1, false},
+		{1, MaxSize, true},
+	}
+	for _, tt := range tc {
+		img := image.NewNRGBA(image.Rect(0, 0, tt.w, tt.h))
+		for i := range img.Pix {
+			img.Pix[i] = uint8(i)
+			if i%4 == 3 && !tt.alpha {
+				img.Pix[i] = 0xff
+			}
+		}
+		buf := new(bytes.Buffer)
+		if err := Encode(buf, img); err != nil {
+			t.Fatalf("%dx%d: could not encode: %v", tt.w, tt.h, err)
+		}
+		b := buf.Bytes()
+		if len(b) < 25 || string(b[:4]) != "RIFF" || string(b[8:16]) != "WEBPVP8L" {
+			t.Fatalf("%dx%d: expected RIFF, WEBP, and VP8L; got %q", tt.w, tt.h, b[:16])
+		}
+		if size := binary.LittleEndian.Uint32(b[4:]); int(size) != len(b)-8 {
+			t.Errorf("%dx%d: expected RIFF size %d; got %d", tt.w, tt.h, len(b)-8, size)
+		}
+		// The chunk is padded to an even size, with a zero not counted in it.
+		size := int(binary.LittleEndian.Uint32(b[16:]))
+		if 20+size+size%2 != len(b) || size%2 == 1 && b[len(b)-1] != 0 {
+			t.Errorf("%dx%d: chunk of %d bytes in %d bytes", tt.w, tt.h, size, len(b)-20)
+		}
+		if b[20] != vp8lSignature {
+			t.Errorf("%dx%d: expected signature %#x; got %#x", tt.w, tt.h, vp8lSignature, b[20])
+		}
+		header := binary.LittleEndian.Uint32(b[21:])
+		w, h := int(header&0x3fff)+1, int(header>>14&0x3fff)+1
+		if w != tt.w || h != tt.h {
+			t.Errorf("%dx%d: expected size in header; got %dx%d", tt.w, tt.h, w, h)
+		}
+		if alpha := header>>28&1 == 1; alpha != tt.alpha {
+			t.Errorf("%dx%d: expected alpha %v; got %v", tt.w, tt.h, tt.alpha, alpha)
+		}
+		if v := header >> 29; v != 0 {
+			t.Errorf("%dx%d: expected version 0; got %d", tt.w, tt.h, v)
+		}
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	if err := Encode(new(bytes.Buffer), image.NewGray(image.Rect(0, 0, MaxSize+1, 1))); err != ErrTooLarge {
+		t.Errorf("expected ErrTooLarge; got %v", err)
+	}
+}
+
+// The files in testdata were encoded by libwebp 1.2.4, with
+// WebPEncodeLosslessRGBA, from the pixels below. Between them, they use every
+// transform, color caches, and meta prefix codes.
+func TestLibwebp(t *testing.T) {
+	// The colors of fully transparent pixels may be changed by libwebp.
+	same := func(a, b color.NRGBA) bool { return a == b || a.A == 0 && b.A == 0 }
+	noise := make([]color.NRGBA, 40*30)
+	s := uint32(1)
+	next := func() uint8 {
+		s = (s*1103515245 + 12345) & 0x7fffffff
+		return uint8(s >> 16)
+	}
+	for i := range noise {
+		noise[i] = color.NRGBA{next(), next(), next(), 0xff}
+	}
+	palette := []color.NRGBA{{0xff, 0, 0, 0xff}, {0, 0x80, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}, {}}
+	// Noise on the left, and a pattern of 12 colors on the right.
+	mixed := make([]color.NRGBA, 96*64)
+	s = 2
+	for i := range mixed {
+		if x, y := i%96, i/96; x < 48 {
+			mixed[i] = color.NRGBA{next(), next(), next(), 0xff}
+		} else {
+			c := uint8((x/5 ^ y/3) % 12)
+			mixed[i] = color.NRGBA{c * 16, 255 - c*16, c * c, 0xff}
+		}
+	}
+
+	tc := []struct {
+		name string
+		w, h int
+		at   func(x, y int) color.NRGBA
+	}{
+		{"gradient", 61, 37, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 255 / 60), uint8(y * 255 / 36), uint8((x + y) * 4), uint8(255 - x*2)}
+		}},
+		{"palette", 19, 13, func(x, y int) color.NRGBA { return palette[(x/3+y/2)%4] }},
+		{"noise", 40, 30, func(x, y int) color.NRGBA { return noise[y*40+x] }},
+		{"mixed", 96, 64, func(x, y int) color.NRGBA { return mixed[y*96+x] }},
+		{"pixel", 1, 1, func(x, y int) color.NRGBA { return color.NRGBA{12, 34, 56, 78} }},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(filepath.Join("testdata", tt.name+".webp"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := decode(b)
+			if err != nil {
+				t.Fatalf("could not decode: %v", err)
+			}
+			if size := img.Bounds().Size(); size != image.Pt(tt.w, tt.h) {
+				t.Fatalf("expected size %dx%d; got %v", tt.w, tt.h, size)
+			}
+			for y := 0; y < tt.h; y++ {
+				for x := 0; x < tt.w; x++ {
+					if want, got := tt.at(x, y), img.NRGBAAt(x, y); !same(want, got) {
+						t.Fatalf("expected %v at %d,%d; got %v", want, x, y, got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// imgconvert converts images between PNG, JPEG, and GIF, or to WebP,
+// optionally resizing them. The formats are given by the extensions of the
+// files, or by -f when writing to standard output.
+//
+// Usage:
+//
+//	imgconvert [flags] input output
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/convert"
+	"github.com/pkg/errors"
+)
+
+var (
+	format   = flag.String("f", "", "format of the output: png, jpeg, gif, or webp; defaults to the extension of the output")
+	quality  = flag.Int("q", 0, "quality of JPEG output from 1 to 100, defaults to 90")
+	width    = flag.Int("width", 0, "width of the output in pixels, keeping the aspect ratio if -height is not set")
+	height   = flag.Int("height", 0, "height of the output in pixels, keeping the aspect ratio if -width is not set")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the image written, or the error")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] input output\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "use - as input or output for standard input or output\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *jsonFlag && flag.Arg(1) == "-" {
+		log.Fatal("-json describes the output file, it doesn't apply to the standard output")
+	}
+	res, err := run(flag.Arg(0), flag.Arg(1))
+	if !*jsonFlag {
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err != nil {
+		res.Err = err.Error()
+	}
+	b, _ := json.Marshal(res)
+	fmt.Printf("%s\n", b)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// A result describes the image written, with fields named like the ones of
+// imgcat -json.
+type result struct {
+	Name   string        `json:"name"`
+	Format imgcat.Format `json:"format,omitempty"`
+	Width  int           `json:"width,omitempty"`
+	Height int           `json:"height,omitempty"`
+	Input  int64         `json:"input_bytes"`
+	Output int64         `json:"output_bytes"`
+	Err    string        `json:"error,omitempty"`
+}
+
+func run(input, output string) (*result, error) {
+	res := &result{Name: output}
+	opts := convert.Options{
+		Format:  imgcat.Format(*format),
+		Quality: *quality,
+		Width:   *width,
+		Height:  *height,
+	}
+	if *format == "jpg" {
+		opts.Format = imgcat.JPEG
+	}
+	if opts.Format == "" && output != "-" {
+		if opts.Format = convert.FormatOf(output); opts.Format == "" {
+			return res, errors.Errorf("unknown format of %s, use -f to set it", output)
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return res, errors.Wrapf(err, "could not open %s", input)
+		}
+		defer f.Close()
+		r = f
+	}
+	if output == "-" {
+		return res, convert.Convert(os.Stdout, r, opts)
+	}
+
+	// The input is kept to describe it with -json.
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return res, errors.Wrapf(err, "could not read %s", input)
+	}
+	res.Input = int64(len(data))
+	if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		size := convert.ScaledSize(image.Pt(cfg.Width, cfg.Height), opts.Width, opts.Height)
+		res.Width, res.Height, res.Format = size.X, size.Y, opts.Format
+		if res.Format == "" {
+			res.Format = imgcat.Format(format)
+		}
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return res, errors.Wrapf(err, "could not create %s", output)
+	}
+	if err := convert.Convert(f, bytes.NewReader(data), opts); err != nil {
+		f.Close()
+		os.Remove(output)
+		return res, errors.Wrapf(err, "could not convert %s", input)
+	}
+	info, err := f.Stat()
+	if err == nil {
+		res.Output = info.Size()
+	}
+	return res, f.Close()
+}
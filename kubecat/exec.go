@@ -0,0 +1,91 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// An execer runs commands in the containers of pods.
+type execer interface {
+	// exec runs the command in pod, and returns what it writes to its
+	// standard output as it runs. Closing it returns an error if the command
+	// failed.
+	exec(ctx context.Context, pod string, command ...string) (io.ReadCloser, error)
+}
+
+// kubectl is an execer running kubectl exec, which streams the output of
+// commands through the API server.
+type kubectl struct {
+	namespace, container, context string
+}
+
+func (k kubectl) exec(ctx context.Context, pod string, command ...string) (io.ReadCloser, error) {
+	path, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, errors.New("kubectl is needed to read files of pods, but was not found")
+	}
+	args := []string{"exec"}
+	if k.context != "" {
+		args = append(args, "--context", k.context)
+	}
+	if k.namespace != "" {
+		args = append(args, "-n", k.namespace)
+	}
+	args = append(args, pod)
+	if k.container != "" {
+		args = append(args, "-c", k.container)
+	}
+	args = append(append(args, "--"), command...)
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run kubectl")
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "could not run kubectl")
+	}
+	return &running{ReadCloser: out, cmd: cmd, stderr: stderr}, nil
+}
+
+// running is the output of a running command.
+type running struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+// Close waits for the command to exit, and returns an error with what it
+// wrote to its standard error if it failed. Commands whose output wasn't all
+// read fail.
+func (r *running) Close() error {
+	cerr := r.ReadCloser.Close()
+	err := r.cmd.Wait()
+	if err == nil {
+		return cerr
+	}
+	if msg := strings.TrimSpace(r.stderr.String()); msg != "" {
+		return errors.Errorf("kubectl failed: %s", msg)
+	}
+	return errors.Wrap(err, "kubectl failed")
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// kubecat displays image files of the containers of Kubernetes pods in the
+// terminal, such as charts or screenshots rendered by a job, without copying
+// them or forwarding ports.
+//
+// Usage:
+//
+//	kubecat [flags] pod:path...
+//
+// Files are read with cat run in the container through the API server, with
+// kubectl, so they are found in the namespace and context of kubectl unless
+// given with -n and -context. Pods can be given as resources too, as in
+// deploy/web:/tmp/chart.png.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+var (
+	namespace = flag.String("n", "", "namespace of the pods, defaults to the one of the current context")
+	container = flag.String("c", "", "container the files are in, defaults to the first one of the pod")
+	kubeCtx   = flag.String("context", "", "kubeconfig context of the cluster, defaults to the current one")
+	maxBytes  = flag.Int64("max-bytes", 64<<20, "largest file read from a pod")
+	jsonFlag  = flag.Bool("json", false, "print a line of JSON describing each image displayed, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] pod:path...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	x := kubectl{namespace: *namespace, container: *container, context: *kubeCtx}
+	failed := 0
+	for _, arg := range flag.Args() {
+		if err := show(context.Background(), x, arg); err != nil {
+			log.Print(err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// show displays the file given as pod:path, read with x.
+func show(ctx context.Context, x execer, arg string) error {
+	pod, file, err := parseTarget(arg)
+	if err != nil {
+		return err
+	}
+	rc, err := x.exec(ctx, pod, "cat", file)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(rc, *maxBytes+1))
+	if err == nil && int64(len(data)) > *maxBytes {
+		// kubectl fails since its output isn't all read, which goes without
+		// saying.
+		_ = rc.Close()
+		return errors.Errorf("%s is larger than %d bytes, see -max-bytes", arg, *maxBytes)
+	}
+	if cerr := rc.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", arg)
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name(path.Base(file)),
+		imgcat.MaxPixels(imgcat.DefaultMaxPixels), imgcat.PaneWidth(100), imgcat.PreserveAspectRatio(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(enc.Encode(bytes.NewReader(data)), "could not display %s", arg)
+}
+
+// parseTarget splits a target such as web-0:/tmp/chart.png into the pod and
+// the path of the file.
+func parseTarget(arg string) (pod, file string, err error) {
+	i := strings.Index(arg, ":")
+	if i <= 0 || i == len(arg)-1 {
+		return "", "", errors.Errorf("%q is not a pod:path target", arg)
+	}
+	return arg[:i], arg[i+1:], nil
+}
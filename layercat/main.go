@@ -0,0 +1,255 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// layercat shows the size of each layer of a container image as a bar chart in
+// the terminal, to spot what's bloating an image without leaving the shell.
+//
+// Usage:
+//
+//	layercat [flags] image
+//
+// The image is inspected with the docker command, or read from an archive
+// created by docker save when using the -archive flag.
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/pkg/errors"
+)
+
+var (
+	docker   = flag.String("docker", "docker", "docker compatible command used to inspect images, such as podman")
+	archive  = flag.Bool("archive", false, "read the image from an archive created by docker save")
+	empty    = flag.Bool("empty", false, "include layers that add no content")
+	width    = flag.Int("width", chart.DefaultWidth, "width of the chart in pixels")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the chart displayed, written to the terminal instead")
+)
+
+// A layer of a container image.
+type layer struct {
+	createdBy string
+	size      int64
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] image\n\t%s -archive [flags] image.tar\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	name := flag.Arg(0)
+
+	var layers []layer
+	var err error
+	if *archive {
+		layers, err = fromArchive(name)
+	} else {
+		layers, err = fromHistory(name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var total int64
+	c := &chart.BarChart{Width: *width, Format: func(v float64) string { return humanize(int64(v)) }}
+	for _, l := range layers {
+		total += l.size
+		if l.size == 0 && !*empty {
+			continue
+		}
+		c.Bars = append(c.Bars, chart.Bar{Label: command(l.createdBy), Value: float64(l.size)})
+	}
+	c.Title = fmt.Sprintf("%s: %s in %d layers", name, humanize(total), len(layers))
+	if len(c.Bars) == 0 {
+		log.Fatalf("%s has no layers with content", name)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, c.Draw()); err != nil {
+		log.Fatalf("could not encode chart: %v", err)
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name("layers.png")}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := enc.Encode(buf); err != nil {
+		log.Fatalf("could not display chart: %v", err)
+	}
+}
+
+// fromHistory returns the layers of the image, from the base one up, as
+// reported by docker history.
+func fromHistory(image string) ([]layer, error) {
+	cmd := exec.Command(*docker, "history", "--no-trunc", "--human=false", "--format", "{{.Size}}\t{{.CreatedBy}}", image)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get history of %s", image)
+	}
+
+	var layers []layer
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad layer size %q", fields[0])
+		}
+		layers = append(layers, layer{fields[1], size})
+	}
+	// History is listed from the most recent layer.
+	for i, j := 0, len(layers)-1; i < j; i, j = i+1, j-1 {
+		layers[i], layers[j] = layers[j], layers[i]
+	}
+	return layers, s.Err()
+}
+
+// manifest is the content of manifest.json in archives created by docker save.
+type manifest []struct {
+	Config string
+	Layers []string
+}
+
+// config is the part of the image configuration we need.
+type config struct {
+	History []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+	} `json:"history"`
+}
+
+// fromArchive returns the layers of the first image in an archive created by
+// docker save. Only the tar headers of the layers are read.
+func fromArchive(name string) ([]layer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", name)
+	}
+	defer func() { _ = f.Close() }()
+
+	var m manifest
+	var cfgs = map[string][]byte{}
+	sizes := map[string]int64{}
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read %s", name)
+		}
+		entry := path.Clean(h.Name)
+		sizes[entry] = h.Size
+		switch {
+		case entry == "manifest.json":
+			if err := json.NewDecoder(tr).Decode(&m); err != nil {
+				return nil, errors.Wrap(err, "could not decode manifest")
+			}
+		case strings.HasSuffix(entry, ".json") || strings.HasPrefix(entry, "blobs/"):
+			// Configurations are small, but blobs can be whole layers.
+			if h.Size > 1<<20 {
+				continue
+			}
+			b, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read %s", entry)
+			}
+			cfgs[entry] = b
+		}
+	}
+	if len(m) == 0 {
+		return nil, errors.Errorf("no manifest.json in %s, is it an archive created by docker save?", name)
+	}
+
+	var cfg config
+	if b, ok := cfgs[path.Clean(m[0].Config)]; ok {
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return nil, errors.Wrap(err, "could not decode image configuration")
+		}
+	}
+
+	var layers []layer
+	i := 0
+	for _, h := range cfg.History {
+		if h.EmptyLayer {
+			layers = append(layers, layer{h.CreatedBy, 0})
+			continue
+		}
+		if i < len(m[0].Layers) {
+			layers = append(layers, layer{h.CreatedBy, sizes[path.Clean(m[0].Layers[i])]})
+			i++
+		}
+	}
+	// Layers with no history, if any.
+	for ; i < len(m[0].Layers); i++ {
+		layers = append(layers, layer{m[0].Layers[i], sizes[path.Clean(m[0].Layers[i])]})
+	}
+	return layers, nil
+}
+
+// command returns a short version of the instruction that created a layer.
+func command(createdBy string) string {
+	s := strings.TrimSpace(createdBy)
+	s = strings.TrimPrefix(s, "/bin/sh -c ")
+	s = strings.TrimPrefix(s, "#(nop) ")
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "<unknown>"
+	}
+	return s
+}
+
+// humanize formats a size in bytes with a decimal unit.
+func humanize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for x := n / unit; x >= unit; x /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// lsimg lists the contents of directories like ls, showing a small inline
+// thumbnail next to the name of each image.
+//
+// Usage:
+//
+//	lsimg [flags] [dir]*
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/campoy/tools/imgcat/textwidth"
+	"github.com/campoy/tools/imgcat/thumbcache"
+	"github.com/pkg/errors"
+)
+
+var (
+	all      = flag.Bool("a", false, "include entries starting with a dot")
+	single   = flag.Bool("1", false, "list one entry per line")
+	cells    = flag.Int("cells", 2, "width of the thumbnails in character cells")
+	workers  = flag.Int("j", runtime.NumCPU(), "number of thumbnails generated concurrently")
+	noCache  = flag.Bool("no-cache", false, "generate the thumbnails again, instead of reusing the ones cached")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing each thumbnail displayed, with the listing written to the terminal instead")
+)
+
+// thumbSize is the maximum width and height of thumbnails in pixels, which is
+// plenty for an image a couple of cells wide.
+const thumbSize = 64
+
+// cache holds the thumbnails generated, nil if they are not cached.
+var cache *thumbcache.Cache
+
+// logJSON describes the thumbnails displayed with -json, nil otherwise.
+var logJSON imgcat.Option
+
+// Formats for which thumbnails are generated.
+var thumbFormats = map[imgcat.Format]bool{imgcat.PNG: true, imgcat.JPEG: true, imgcat.GIF: true}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] [dir]*\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if !imgcat.IsSupported() {
+		log.Fatal("lsimg is only supported with iTerm2")
+	}
+
+	if !*noCache {
+		var err error
+		if cache, err = openCache(); err != nil {
+			log.Printf("thumbnails are not cached: %v", err)
+		}
+	}
+
+	// The standard output carries the descriptions of the thumbnails with
+	// -json.
+	out := io.Writer(os.Stdout)
+	if *jsonFlag {
+		out, logJSON = imgcat.JSONMode()
+	}
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	failed := false
+	for i, dir := range dirs {
+		if len(dirs) > 1 {
+			header := dir + ":\n"
+			if i > 0 {
+				header = "\n" + header
+			}
+			if _, err := io.WriteString(out, header); err != nil {
+				log.Fatalf("could not write listing: %v", err)
+			}
+		}
+		if err := list(out, dir); err != nil {
+			log.Print(err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// An entry in a directory listing.
+type entry struct {
+	name  string
+	thumb []byte
+}
+
+func list(w io.Writer, dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "could not list %s", dir)
+	}
+	var entries []*entry
+	for _, info := range infos {
+		name := info.Name()
+		if !*all && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if info.IsDir() {
+			name += "/"
+		}
+		entries = append(entries, &entry{name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	thumbnails(dir, entries)
+
+	cols := 1
+	width := 0
+	for _, e := range entries {
+		if n := textwidth.Width(e.name); n > width {
+			width = n
+		}
+	}
+	// Each column has a thumbnail, a space, the name, and two spaces.
+	width += *cells + 3
+	if !*single {
+		size, err := termsize.Get()
+		if err != nil {
+			size.Cols = 80
+		}
+		if cols = size.Cols / width; cols < 1 {
+			cols = 1
+		}
+	}
+	rows := (len(entries) + cols - 1) / cols
+
+	buf := new(bytes.Buffer)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			i := c*rows + r
+			if i >= len(entries) {
+				break
+			}
+			e := entries[i]
+			if e.thumb != nil {
+				buf.Write(e.thumb)
+			} else {
+				buf.WriteString(strings.Repeat(" ", *cells))
+			}
+			fmt.Fprintf(buf, " %s", e.name)
+			if c < cols-1 && i+rows < len(entries) {
+				buf.WriteString(strings.Repeat(" ", width-*cells-1-textwidth.Width(e.name)))
+			}
+		}
+		buf.WriteString("\n")
+	}
+	_, err = w.Write(buf.Bytes())
+	return errors.Wrap(err, "could not write listing")
+}
+
+// thumbnails generates the thumbnails for the images among entries, using at
+// most -j goroutines. Entries that are not images are left without one.
+func thumbnails(dir string, entries []*entry) {
+	if cache != nil {
+		cached(dir, entries)
+		return
+	}
+	n := *workers
+	if n < 1 {
+		n = 1
+	}
+	work := make(chan *entry)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range work {
+				thumb, err := thumbnail(filepath.Join(dir, e.name))
+				if err != nil {
+					log.Print(err)
+				}
+				e.thumb = thumb
+			}
+		}()
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.name, "/") {
+			work <- e
+		}
+	}
+	close(work)
+	wg.Wait()
+}
+
+// openCache returns the cache of thumbnails in the default directory.
+func openCache() (*thumbcache.Cache, error) {
+	dir, err := thumbcache.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return thumbcache.New(dir, thumbSize, thumbcache.DefaultMaxBytes)
+}
+
+// cached sets the thumbnails of the images among entries from the cache,
+// generating the missing ones with at most -j goroutines.
+func cached(dir string, entries []*entry) {
+	var files []*entry
+	var paths []string
+	for _, e := range entries {
+		if !strings.HasSuffix(e.name, "/") {
+			files = append(files, e)
+			paths = append(paths, filepath.Join(dir, e.name))
+		}
+	}
+	for i, r := range cache.Thumbnails(paths, *workers) {
+		if r.Err == thumbcache.ErrNotImage {
+			continue
+		}
+		if r.Err != nil {
+			log.Print(r.Err)
+			continue
+		}
+		thumb, err := encode(bytes.NewReader(r.Thumbnail), filepath.Base(r.Path))
+		if err != nil {
+			log.Print(errors.Wrapf(err, "could not create thumbnail for %s", r.Path))
+			continue
+		}
+		files[i].thumb = thumb
+	}
+}
+
+// thumbnail returns the escape sequence displaying the image at path as a
+// thumbnail, or nil if path is not an image.
+func thumbnail(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	defer f.Close()
+
+	magic := make([]byte, 12)
+	n, _ := io.ReadFull(f, magic)
+	if !thumbFormats[imgcat.Sniff(magic[:n])] {
+		return nil, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+
+	b, err := encode(f, filepath.Base(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create thumbnail for %s", path)
+	}
+	return b, nil
+}
+
+// encode returns the escape sequence displaying the image with the given name
+// read from r as a thumbnail.
+func encode(r io.Reader, name string) ([]byte, error) {
+	options := []imgcat.Option{
+		imgcat.Inline(true),
+		imgcat.Name(name),
+		imgcat.Width(imgcat.Cells(*cells)),
+		imgcat.Height(imgcat.Cells(1)),
+		imgcat.MaxPixels(imgcat.DefaultMaxPixels),
+		imgcat.Downsample(thumbSize * thumbSize),
+	}
+	if logJSON != nil {
+		options = append(options, logJSON)
+	}
+	return imgcat.EncodeToBytes(r, options...)
+}
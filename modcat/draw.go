@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// Sizes of the drawing, in pixels at scale 1.
+const (
+	scale   = 2
+	padding = 4
+	margin  = 10
+	colGap  = 30
+	rowGap  = 6
+	// maxLabel is the longest label drawn, in characters.
+	maxLabel = 60
+)
+
+// Colors of the drawing.
+var (
+	edgeColor    = color.RGBA{0xa0, 0xa0, 0xa0, 0xff}
+	nodeColor    = color.RGBA{0xde, 0xeb, 0xf7, 0xff}
+	rootColor    = color.RGBA{0x9e, 0xca, 0xe1, 0xff}
+	matchColor   = color.RGBA{0xfd, 0xd0, 0xa2, 0xff}
+	borderColor  = color.RGBA{0x40, 0x40, 0x40, 0xff}
+	versionColor = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+)
+
+// draw draws the modules in columns, from the main module on the left, with
+// lines from the modules to their requirements. Modules matching match are
+// highlighted, and the ones drawn in several versions have a red border.
+func (l *layered) draw(match *regexp.Regexp) image.Image {
+	l.order()
+	versions := map[string]int{}
+	for n := range l.nodes {
+		versions[modulePath(n)]++
+	}
+
+	rowH := (bitmapfont.Height + 2*padding + rowGap) * scale
+	title := fmt.Sprintf("%s: %d modules", l.root, len(l.nodes)-1)
+	top := (margin+margin)*scale + bitmapfont.Measure(title, scale).Y
+
+	// Position of the box of every module.
+	boxes := map[string]image.Rectangle{}
+	x, tallest := margin*scale, 0
+	for _, layer := range l.layers {
+		if len(layer) > tallest {
+			tallest = len(layer)
+		}
+	}
+	for _, layer := range l.layers {
+		w := 0
+		for _, n := range layer {
+			if lw := bitmapfont.Measure(label(n), scale).X; lw > w {
+				w = lw
+			}
+		}
+		w += 2 * padding * scale
+		// Columns are centered vertically.
+		y := top + (tallest-len(layer))*rowH/2
+		for _, n := range layer {
+			boxes[n] = image.Rect(x, y, x+w, y+rowH-rowGap*scale)
+			y += rowH
+		}
+		x += w + colGap*scale
+	}
+	width := x - colGap*scale + margin*scale
+	if tw := bitmapfont.Measure(title, scale).X + 2*margin*scale; tw > width {
+		width = tw
+	}
+	height := top + tallest*rowH + margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	bitmapfont.Draw(img, title, image.Pt(margin*scale, margin*scale), color.Black, scale)
+	for _, layer := range l.layers {
+		for _, n := range layer {
+			from := boxes[n]
+			for _, m := range l.edges[n] {
+				to := boxes[m]
+				line(img, image.Pt(from.Max.X, (from.Min.Y+from.Max.Y)/2), image.Pt(to.Min.X, (to.Min.Y+to.Max.Y)/2), edgeColor)
+			}
+		}
+	}
+	for n, b := range boxes {
+		fill := nodeColor
+		switch {
+		case n == l.root:
+			fill = rootColor
+		case match != nil && match.MatchString(n):
+			fill = matchColor
+		}
+		border := borderColor
+		if versions[modulePath(n)] > 1 {
+			border = versionColor
+		}
+		draw.Draw(img, b, image.NewUniform(border), image.Point{}, draw.Src)
+		draw.Draw(img, b.Inset(scale), image.NewUniform(fill), image.Point{}, draw.Src)
+		bitmapfont.Draw(img, label(n), b.Min.Add(image.Pt(padding*scale, padding*scale)), color.Black, scale)
+	}
+	return img
+}
+
+// order sorts the modules of every layer by the mean position of the modules
+// requiring them in the previous one, which avoids most crossings of lines.
+func (l *layered) order() {
+	for d := 1; d < len(l.layers); d++ {
+		pos := map[string]int{}
+		for i, n := range l.layers[d-1] {
+			pos[n] = i
+		}
+		sum, count := map[string]int{}, map[string]int{}
+		for _, n := range l.layers[d-1] {
+			for _, m := range l.edges[n] {
+				sum[m] += pos[n]
+				count[m]++
+			}
+		}
+		layer := l.layers[d]
+		sort.SliceStable(layer, func(i, j int) bool {
+			// Compare the means without dividing, all counts are positive.
+			a, b := sum[layer[i]]*count[layer[j]], sum[layer[j]]*count[layer[i]]
+			if a != b {
+				return a < b
+			}
+			return layer[i] < layer[j]
+		})
+	}
+}
+
+// modulePath returns the path of a module without its version.
+func modulePath(n string) string {
+	if i := strings.LastIndex(n, "@"); i >= 0 {
+		return n[:i]
+	}
+	return n
+}
+
+// label returns the text drawn for a module, shortened if it's too long.
+func label(n string) string {
+	r := []rune(n)
+	if len(r) <= maxLabel {
+		return n
+	}
+	return "..." + string(r[len(r)-maxLabel+3:])
+}
+
+// line draws a line from a to b.
+func line(img *image.RGBA, a, b image.Point, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := abs(dx)
+	if abs(dy) > steps {
+		steps = abs(dy)
+	}
+	for i := 0; i <= steps; i++ {
+		p := a
+		if steps > 0 {
+			p = p.Add(image.Pt(dx*i/steps, dy*i/steps))
+		}
+		img.Set(p.X, p.Y, c)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
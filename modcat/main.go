@@ -0,0 +1,256 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// modcat draws the module graph of a Go module, as listed by go mod graph, and
+// displays it in the terminal, to see where dependencies come from during
+// audits.
+//
+// Usage:
+//
+//	modcat [flags] [dir]
+//	go mod graph | modcat [flags] -
+//
+// Modules are drawn in columns by their distance from the main module, with
+// lines from the modules requiring them.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/pkg/errors"
+)
+
+var (
+	depth    = flag.Int("depth", 2, "draw the modules at most this many requirements away from the main module, zero means all of them")
+	filter   = flag.String("filter", "", "draw only the modules matching this regular expression, and the ones requiring them")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the graph displayed, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] [dir]\n\tgo mod graph | %s [flags] -\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var match *regexp.Regexp
+	if *filter != "" {
+		var err error
+		if match, err = regexp.Compile(*filter); err != nil {
+			log.Fatalf("bad -filter: %v", err)
+		}
+	}
+	if err := run(flag.Arg(0), match); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dir string, match *regexp.Regexp) error {
+	var in io.Reader
+	if dir == "-" {
+		in = os.Stdin
+	} else {
+		out, err := modGraph(dir)
+		if err != nil {
+			return err
+		}
+		in = bytes.NewReader(out)
+	}
+	g, err := parse(in)
+	if err != nil {
+		return err
+	}
+	if g.root == "" {
+		return errors.New("the module graph is empty")
+	}
+	sub := g.prune(*depth, match)
+	if match != nil && len(sub.nodes) == 1 {
+		return errors.Errorf("no module matches %q", *filter)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, sub.draw(match)); err != nil {
+		return errors.Wrap(err, "could not encode graph")
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name("modules.png")}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(enc.Encode(buf), "could not display graph")
+}
+
+// modGraph returns the output of go mod graph run in dir.
+func modGraph(dir string) ([]byte, error) {
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command("go", "mod", "graph")
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Errorf("go mod graph failed: %s", msg)
+		}
+		return nil, errors.Wrap(err, "go mod graph failed")
+	}
+	return out, nil
+}
+
+// A graph of modules, named with their versions such as
+// github.com/pkg/errors@v0.8.1, except for the main module.
+type graph struct {
+	root string
+	// nodes in the order they were first seen.
+	nodes []string
+	// requirements of each module, in order.
+	edges map[string][]string
+}
+
+// parse reads the output of go mod graph, made of lines such as
+// main github.com/pkg/errors@v0.8.1. The main module is the first one listed.
+// The versions of go and of the toolchain required are skipped.
+func parse(r io.Reader) (*graph, error) {
+	g := &graph{edges: map[string][]string{}}
+	seen := map[string]bool{}
+	add := func(n string) {
+		if !seen[n] {
+			seen[n] = true
+			g.nodes = append(g.nodes, n)
+		}
+	}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d is not an edge of go mod graph: %q", n, s.Text())
+		}
+		from, to := fields[0], fields[1]
+		if strings.HasPrefix(to, "go@") || strings.HasPrefix(to, "toolchain@") {
+			continue
+		}
+		if g.root == "" {
+			g.root = from
+		}
+		add(from)
+		add(to)
+		g.edges[from] = append(g.edges[from], to)
+	}
+	return g, errors.Wrap(s.Err(), "could not read module graph")
+}
+
+// A layered graph holds the modules at most some distance from the main
+// module, by distance, and the requirements leading to them from the closer
+// ones.
+type layered struct {
+	root   string
+	layers [][]string
+	nodes  map[string]int
+	edges  map[string][]string
+}
+
+// prune returns the modules at most depth requirements away from the root, all
+// of them if depth is zero. If match isn't nil, only the modules matching it
+// and the ones on the shortest paths from the root to them are kept.
+func (g *graph) prune(depth int, match *regexp.Regexp) *layered {
+	// Distance of every module from the root, by breadth first search.
+	dist := map[string]int{g.root: 0}
+	queue := []string{g.root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if depth > 0 && dist[n] >= depth {
+			continue
+		}
+		for _, m := range g.edges[n] {
+			if _, ok := dist[m]; !ok {
+				dist[m] = dist[n] + 1
+				queue = append(queue, m)
+			}
+		}
+	}
+	// next reports whether the edge from n to m leads one step further away.
+	next := func(n, m string) bool {
+		d, ok := dist[m]
+		return ok && d == dist[n]+1
+	}
+
+	keep := map[string]bool{}
+	if match == nil {
+		for n := range dist {
+			keep[n] = true
+		}
+	} else {
+		// Modules are kept if they match or require a kept module further
+		// away, visiting the furthest ones first.
+		byDist := map[int][]string{}
+		max := 0
+		for _, n := range g.nodes {
+			if d, ok := dist[n]; ok {
+				byDist[d] = append(byDist[d], n)
+				if d > max {
+					max = d
+				}
+			}
+		}
+		for d := max; d >= 0; d-- {
+			for _, n := range byDist[d] {
+				keep[n] = match.MatchString(n)
+				for _, m := range g.edges[n] {
+					keep[n] = keep[n] || (next(n, m) && keep[m])
+				}
+			}
+		}
+		keep[g.root] = true
+	}
+
+	l := &layered{root: g.root, nodes: map[string]int{}, edges: map[string][]string{}}
+	for _, n := range g.nodes {
+		if !keep[n] {
+			continue
+		}
+		d := dist[n]
+		for len(l.layers) <= d {
+			l.layers = append(l.layers, nil)
+		}
+		l.layers[d] = append(l.layers[d], n)
+		l.nodes[n] = d
+		for _, m := range g.edges[n] {
+			if keep[m] && next(n, m) {
+				l.edges[n] = append(l.edges[n], m)
+			}
+		}
+	}
+	return l
+}
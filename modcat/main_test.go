@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testGraph is the output of go mod graph for a small module.
+const testGraph = `example.com/app go@1.21
+example.com/app github.com/pkg/errors@v0.9.1
+example.com/app golang.org/x/text@v0.14.0
+golang.org/x/text@v0.14.0 golang.org/x/tools@v0.6.0
+golang.org/x/tools@v0.6.0 golang.org/x/mod@v0.8.0
+golang.org/x/tools@v0.6.0 golang.org/x/text@v0.14.0
+golang.org/x/mod@v0.8.0 toolchain@go1.21.0
+`
+
+func TestParse(t *testing.T) {
+	tc := []struct {
+		name  string
+		input string
+		want  *graph
+	}{
+		{
+			name:  "graph",
+			input: testGraph,
+			want: &graph{
+				root:  "example.com/app",
+				nodes: []string{"example.com/app", "github.com/pkg/errors@v0.9.1", "golang.org/x/text@v0.14.0", "golang.org/x/tools@v0.6.0", "golang.org/x/mod@v0.8.0"},
+				edges: map[string][]string{
+					"example.com/app":           {"github.com/pkg/errors@v0.9.1", "golang.org/x/text@v0.14.0"},
+					"golang.org/x/text@v0.14.0": {"golang.org/x/tools@v0.6.0"},
+					"golang.org/x/tools@v0.6.0": {"golang.org/x/mod@v0.8.0", "golang.org/x/text@v0.14.0"},
+				},
+			},
+		},
+		{
+			name:  "blank lines and spaces",
+			input: "\n  main   a@v1.0.0  \n\nmain b@v1.0.0\n",
+			want: &graph{
+				root:  "main",
+				nodes: []string{"main", "a@v1.0.0", "b@v1.0.0"},
+				edges: map[string][]string{"main": {"a@v1.0.0", "b@v1.0.0"}},
+			},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  &graph{edges: map[string][]string{}},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("could not parse: %v", err)
+			}
+			if !reflect.DeepEqual(g, tt.want) {
+				t.Errorf("expected %+v; got %+v", tt.want, g)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tc := []struct {
+		name, input, err string
+	}{
+		{"one module", "main a@v1.0.0\nmain\n", "line 2 is not an edge"},
+		{"three modules", "main a@v1.0.0 b@v1.0.0\n", "line 1 is not an edge"},
+		{"error message", "go: cannot find main module, but found .git/config\n", "line 1 is not an edge"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	g, err := parse(strings.NewReader(testGraph))
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+	tc := []struct {
+		name   string
+		depth  int
+		match  *regexp.Regexp
+		layers [][]string
+	}{
+		{"all", 0, nil, [][]string{
+			{"example.com/app"},
+			{"github.com/pkg/errors@v0.9.1", "golang.org/x/text@v0.14.0"},
+			{"golang.org/x/tools@v0.6.0"},
+			{"golang.org/x/mod@v0.8.0"},
+		}},
+		{"depth", 1, nil, [][]string{
+			{"example.com/app"},
+			{"github.com/pkg/errors@v0.9.1", "golang.org/x/text@v0.14.0"},
+		}},
+		{"match", 0, regexp.MustCompile("x/mod"), [][]string{
+			{"example.com/app"},
+			{"golang.org/x/text@v0.14.0"},
+			{"golang.org/x/tools@v0.6.0"},
+			{"golang.org/x/mod@v0.8.0"},
+		}},
+		{"match beyond depth", 2, regexp.MustCompile("x/mod"), [][]string{{"example.com/app"}}},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			if l := g.prune(tt.depth, tt.match); !reflect.DeepEqual(l.layers, tt.layers) {
+				t.Errorf("expected layers %q; got %q", tt.layers, l.layers)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// notify runs a command and, once it finishes, shows a notification in iTerm2
+// with a thumbnail of the image it produced, if any. It's useful for long tasks
+// such as renders or training runs.
+//
+// Usage:
+//
+//	notify [-image path] [-m message] [-json] [command [args]*]
+//
+// For instance:
+//
+//	notify -image out.png blender -b scene.blend -o out.png -f 1
+//
+// Without a command, notify shows the notification right away.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+)
+
+var (
+	img      = flag.String("image", "", "image shown as a thumbnail in the notification")
+	message  = flag.String("m", "", "message of the notification, defaults to the status of the command")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the thumbnail displayed, with the notification written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [-image path] [-m message] [-json] [command [args]*]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if !imgcat.IsSupported() {
+		log.Fatal("notify is only supported with iTerm2")
+	}
+
+	code := 0
+	msg := *message
+	if args := flag.Args(); len(args) > 0 {
+		start := time.Now()
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err := cmd.Run()
+		elapsed := time.Since(start).Round(time.Second)
+
+		name := strings.Join(args, " ")
+		status := fmt.Sprintf("%s finished in %v", name, elapsed)
+		if err != nil {
+			status = fmt.Sprintf("%s failed after %v: %v", name, elapsed, err)
+			code = 1
+			if exit, ok := err.(*exec.ExitError); ok {
+				code = exit.ExitCode()
+			}
+		}
+		if msg == "" {
+			msg = status
+		}
+	}
+	if msg == "" {
+		msg = "done"
+	}
+
+	if err := notify(msg, *img); err != nil {
+		log.Print(err)
+	}
+	os.Exit(code)
+}
+
+func notify(msg, path string) error {
+	out, options := io.Writer(os.Stdout), []imgcat.Option(nil)
+	if path != "" {
+		options = append(options, imgcat.Name(filepath.Base(path)))
+	}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return enc.Notify(msg, nil)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		// Notify anyway, the task might have failed before writing the image.
+		if err := enc.Notify(msg, nil); err != nil {
+			return err
+		}
+		return fmt.Errorf("could not show thumbnail: %v", err)
+	}
+	defer f.Close()
+	return enc.Notify(msg, f)
+}
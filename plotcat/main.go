@@ -0,0 +1,271 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// plotcat draws a line or scatter plot of the columns of a CSV or TSV file and
+// displays it in the terminal, making it easy to inspect data without leaving
+// the shell.
+//
+// Usage:
+//
+//	plotcat [flags] data.csv
+//
+// For instance:
+//
+//	plotcat data.csv -x time -y latency,errors
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/pkg/errors"
+)
+
+var (
+	xCol     = flag.String("x", "", "column used for the x axis, defaults to the row number")
+	yCols    = flag.String("y", "", "comma separated columns to plot, defaults to all the numeric ones")
+	scatter  = flag.Bool("scatter", false, "draw points instead of lines")
+	sep      = flag.String("sep", "", "field separator, defaults to tab for .tsv files and comma otherwise")
+	title    = flag.String("title", "", "title of the plot")
+	width    = flag.Int("width", chart.DefaultWidth, "width of the plot in pixels")
+	height   = flag.Int("height", chart.DefaultHeight, "height of the plot in pixels")
+	output   = flag.String("o", "", "write the plot as a PNG file instead of displaying it")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the plot displayed, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] [data.csv]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	args := parseFlags(os.Args[1:])
+	if len(args) > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	path := "-"
+	if len(args) == 1 {
+		path = args[0]
+	}
+	c, err := load(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, c.Draw()); err != nil {
+		log.Fatalf("could not encode plot: %v", err)
+	}
+	if *output != "" {
+		if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+			log.Fatalf("could not write plot: %v", err)
+		}
+		return
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name(filepath.Base(path) + ".png")}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := enc.Encode(buf); err != nil {
+		log.Fatalf("could not display plot: %v", err)
+	}
+}
+
+// parseFlags parses the flags in args, allowing them after the file name,
+// and returns the remaining arguments.
+func parseFlags(args []string) []string {
+	var rest []string
+	for {
+		// flag.CommandLine exits on error.
+		_ = flag.CommandLine.Parse(args)
+		if flag.NArg() == 0 {
+			return rest
+		}
+		rest = append(rest, flag.Arg(0))
+		args = flag.Args()[1:]
+	}
+}
+
+// load reads the table in the given file, or stdin if path is "-", and
+// returns a chart plotting the selected columns.
+func load(path string) (*chart.Chart, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not open %s", path)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = separator(path)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path)
+	}
+	if len(rows) < 2 {
+		return nil, errors.Errorf("%s has no data rows", path)
+	}
+	header, rows := rows[0], rows[1:]
+
+	c := &chart.Chart{Title: *title, Width: *width, Height: *height}
+	if *scatter {
+		c.Kind = chart.Scatter
+	}
+
+	xs := make([]float64, len(rows))
+	for i := range xs {
+		xs[i] = float64(i + 1)
+	}
+	xIdx := -1
+	if *xCol != "" {
+		if xIdx, err = column(header, *xCol); err != nil {
+			return nil, err
+		}
+		xs, c.XTime = parseX(rows, xIdx)
+	}
+
+	var ys []int
+	if *yCols == "" {
+		for i := range header {
+			if i != xIdx && numeric(rows, i) {
+				ys = append(ys, i)
+			}
+		}
+		if len(ys) == 0 {
+			return nil, errors.Errorf("no numeric columns to plot in %s", path)
+		}
+	} else {
+		for _, name := range strings.Split(*yCols, ",") {
+			i, err := column(header, strings.TrimSpace(name))
+			if err != nil {
+				return nil, err
+			}
+			ys = append(ys, i)
+		}
+	}
+
+	for _, i := range ys {
+		s := chart.Series{Name: header[i], X: xs, Y: make([]float64, len(rows))}
+		for j, row := range rows {
+			s.Y[j] = parseFloat(field(row, i))
+		}
+		c.Series = append(c.Series, s)
+	}
+	return c, nil
+}
+
+func separator(path string) rune {
+	if *sep != "" {
+		if *sep == `\t` {
+			return '\t'
+		}
+		return []rune(*sep)[0]
+	}
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+func column(header []string, name string) (int, error) {
+	for i, h := range header {
+		if h == name {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("unknown column %q, available columns are %s", name, strings.Join(header, ", "))
+}
+
+func field(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return v
+}
+
+// numeric reports whether the first non empty value of column i is a number.
+func numeric(rows [][]string, i int) bool {
+	for _, row := range rows {
+		if s := field(row, i); s != "" {
+			return !math.IsNaN(parseFloat(s))
+		}
+	}
+	return false
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"15:04:05",
+}
+
+// parseX parses the values of column i as numbers or, if they are not,
+// as times in seconds since the epoch.
+func parseX(rows [][]string, i int) (xs []float64, isTime bool) {
+	xs = make([]float64, len(rows))
+	if numeric(rows, i) {
+		for j, row := range rows {
+			xs[j] = parseFloat(field(row, i))
+		}
+		return xs, false
+	}
+	for j, row := range rows {
+		xs[j] = math.NaN()
+		s := strings.TrimSpace(field(row, i))
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				xs[j] = float64(t.UnixNano()) / 1e9
+				break
+			}
+		}
+	}
+	return xs, true
+}
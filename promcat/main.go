@@ -0,0 +1,201 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// promcat runs a PromQL range query against a Prometheus server and displays
+// the result as a time series chart in the terminal.
+//
+// Usage:
+//
+//	promcat [flags] query
+//
+// For instance:
+//
+//	promcat -range 6h 'rate(http_requests_total[5m])'
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/pkg/errors"
+)
+
+var (
+	server    = flag.String("server", defaultServer(), "address of the Prometheus server, defaults to $PROMETHEUS_URL")
+	period    = flag.Duration("range", time.Hour, "time range to query, ending now")
+	step      = flag.Duration("step", 0, "query resolution, defaults to a fraction of the range")
+	maxSeries = flag.Int("max-series", 10, "maximum number of series to plot")
+	title     = flag.String("title", "", "title of the chart, defaults to the query")
+	width     = flag.Int("width", chart.DefaultWidth, "width of the chart in pixels")
+	height    = flag.Int("height", chart.DefaultHeight, "height of the chart in pixels")
+	timeout   = flag.Duration("timeout", 30*time.Second, "timeout for the query")
+	jsonFlag  = flag.Bool("json", false, "print a line of JSON describing the chart displayed, written to the terminal instead")
+)
+
+func defaultServer() string {
+	if s := os.Getenv("PROMETHEUS_URL"); s != "" {
+		return s
+	}
+	return "http://localhost:9090"
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] query\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	query := flag.Arg(0)
+
+	end := time.Now()
+	res := *step
+	if res <= 0 {
+		// Enough points for a smooth line, without hitting the server limits.
+		res = *period / 250
+		if res < time.Second {
+			res = time.Second
+		}
+	}
+	series, err := queryRange(query, end.Add(-*period), end, res)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(series) == 0 {
+		log.Fatalf("query %q returned no data", query)
+	}
+	if len(series) > *maxSeries {
+		fmt.Fprintf(os.Stderr, "plotting %d out of %d series\n", *maxSeries, len(series))
+		series = series[:*maxSeries]
+	}
+
+	c := &chart.Chart{Title: *title, Series: series, XTime: true, Width: *width, Height: *height}
+	if c.Title == "" {
+		c.Title = query
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, c.Draw()); err != nil {
+		log.Fatalf("could not encode chart: %v", err)
+	}
+
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name("promcat.png")}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := enc.Encode(buf); err != nil {
+		log.Fatalf("could not display chart: %v", err)
+	}
+}
+
+// response is the body returned by the Prometheus HTTP API.
+type response struct {
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	ErrorType string `json:"errorType"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryRange runs the query between start and end and returns a series for
+// each of the resulting time series.
+func queryRange(query string, start, end time.Time, step time.Duration) ([]chart.Series, error) {
+	u, err := url.Parse(strings.TrimSuffix(*server, "/") + "/api/v1/query_range")
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad server address %s", *server)
+	}
+	u.RawQuery = url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}.Encode()
+
+	client := &http.Client{Timeout: *timeout}
+	res, err := client.Get(u.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not query Prometheus")
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var body response
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, errors.Wrapf(err, "could not decode response (%s)", res.Status)
+	}
+	if body.Status != "success" {
+		return nil, errors.Errorf("query failed: %s: %s", body.ErrorType, body.Error)
+	}
+	if body.Data.ResultType != "matrix" {
+		return nil, errors.Errorf("unexpected result type %q", body.Data.ResultType)
+	}
+
+	var series []chart.Series
+	for _, r := range body.Data.Result {
+		s := chart.Series{Name: seriesName(r.Metric)}
+		for _, v := range r.Values {
+			ts, _ := v[0].(float64)
+			str, _ := v[1].(string)
+			y, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				y = math.NaN()
+			}
+			s.X, s.Y = append(s.X, ts), append(s.Y, y)
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// seriesName formats the labels of a series as in the Prometheus UI.
+func seriesName(metric map[string]string) string {
+	name := metric["__name__"]
+	var labels []string
+	for k, v := range metric {
+		if k != "__name__" {
+			labels = append(labels, fmt.Sprintf("%s=%q", k, v))
+		}
+	}
+	if len(labels) == 0 && name != "" {
+		return name
+	}
+	sort.Strings(labels)
+	return name + "{" + strings.Join(labels, ", ") + "}"
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/campoy/tools/imgcat/bitmapfont"
+)
+
+// maxLabel is the longest label of a goroutine drawn, in characters.
+const maxLabel = 32
+
+// Colors of the drawing.
+var (
+	laneColor     = color.RGBA{0xf4, 0xf4, 0xf4, 0xff}
+	gridColor     = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	runningColor  = color.RGBA{0x31, 0xa3, 0x54, 0xff}
+	runnableColor = color.RGBA{0xfd, 0xd0, 0xa2, 0xff}
+	syscallColor  = color.RGBA{0x75, 0x6b, 0xb1, 0xff}
+	assistColor   = color.RGBA{0xe6, 0x55, 0x0d, 0xff}
+	gcColor       = color.RGBA{0x9e, 0xca, 0xe1, 0xff}
+	stwColor      = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+)
+
+// draw draws the timeline w pixels wide, with a lane for at most n of the
+// goroutines that ran the longest, leaving out the ones of the runtime unless
+// system is set, below a lane for the garbage collections.
+func (t *timeline) draw(name string, w, n int, system bool) image.Image {
+	scale := w / 400
+	if scale < 1 {
+		scale = 1
+	}
+	margin, gap := 10*scale, 2*scale
+	laneH := (bitmapfont.Height + 4) * scale
+	duration := t.end - t.start
+
+	var shown []*goroutine
+	for _, g := range t.goroutines {
+		if len(g.spans) > 0 && (system || !strings.HasPrefix(g.fn, "runtime.")) {
+			shown = append(shown, g)
+		}
+	}
+	sort.Slice(shown, func(i, j int) bool {
+		if shown[i].running != shown[j].running {
+			return shown[i].running > shown[j].running
+		}
+		return shown[i].id < shown[j].id
+	})
+	hidden := 0
+	if len(shown) > n {
+		hidden, shown = len(shown)-n, shown[:n]
+	}
+	sort.Slice(shown, func(i, j int) bool { return shown[i].id < shown[j].id })
+
+	var stopped int64
+	for _, s := range t.stw {
+		stopped += s.to - s.from
+	}
+	title := fmt.Sprintf("%s: %s, %d goroutines, %d GCs, stopped for %s",
+		name, short(duration), len(t.goroutines), len(t.gc), short(stopped))
+
+	labels := []string{"GC"}
+	for _, g := range shown {
+		labels = append(labels, label(g))
+	}
+	left := 0
+	for _, l := range labels {
+		if lw := bitmapfont.Measure(l, scale).X; lw > left {
+			left = lw
+		}
+	}
+	left += 2 * margin
+	plot := w - left - margin
+	if plot < 1 {
+		plot, w = 1, left+margin+1
+	}
+	x := func(at int64) int {
+		if duration <= 0 {
+			return left
+		}
+		return left + int((at-t.start)*int64(plot)/duration)
+	}
+
+	textH := bitmapfont.Measure("0", scale).Y
+	axis := margin + textH + margin
+	top := axis + textH + gap
+	bottom := top + len(labels)*(laneH+gap)
+	legend := []struct {
+		name string
+		c    color.Color
+	}{
+		{"running", runningColor}, {"runnable", runnableColor}, {"syscall", syscallColor},
+		{"mark assist", assistColor}, {"GC mark", gcColor}, {"stop the world", stwColor},
+	}
+	// The legend wraps when it's wider than the image.
+	box := textH * 3 / 4
+	at := make([]image.Point, len(legend))
+	lx, ly := margin, bottom+margin
+	for i, l := range legend {
+		lw := box + gap + bitmapfont.Measure(l.name, scale).X
+		if lx > margin && lx+lw > w-margin {
+			lx, ly = margin, ly+textH+gap
+		}
+		at[i] = image.Pt(lx, ly)
+		lx += lw + margin
+	}
+	h := ly + textH + margin
+	if hidden > 0 {
+		h += textH + gap
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	fill := func(r image.Rectangle, c color.Color) {
+		draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	bitmapfont.Draw(img, title, image.Pt(margin, margin), color.Black, scale)
+
+	// Lanes, with the spans of a goroutine at least a pixel wide so that
+	// short ones are seen.
+	lane := func(i int) image.Rectangle {
+		y := top + i*(laneH+gap)
+		return image.Rect(left, y, left+plot, y+laneH)
+	}
+	spans := func(r image.Rectangle, spans []span, c color.Color) {
+		for _, s := range spans {
+			x0, x1 := x(s.from), x(s.to)
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			fill(image.Rect(x0, r.Min.Y, x1, r.Max.Y), c)
+		}
+	}
+	for i, l := range labels {
+		r := lane(i)
+		fill(r, laneColor)
+		bitmapfont.Draw(img, l, image.Pt(margin, r.Min.Y+2*scale), color.Black, scale)
+	}
+
+	// Ticks of the time axis, below the lanes.
+	step := tick(duration, plot/(50*scale))
+	for at := int64(0); step > 0 && at <= duration; at += step {
+		tx := x(t.start + at)
+		fill(image.Rect(tx, axis+textH, tx+1, bottom-gap), gridColor)
+		s := short(at)
+		if lx := tx - bitmapfont.Measure(s, scale).X/2; lx >= left-margin {
+			bitmapfont.Draw(img, s, image.Pt(lx, axis), color.Black, scale)
+		}
+	}
+
+	gc := lane(0)
+	spans(gc, t.gc, gcColor)
+	spans(gc, t.stw, stwColor)
+	colors := map[string]color.Color{running: runningColor, runnable: runnableColor, syscall: syscallColor}
+	for i, g := range shown {
+		r := lane(i + 1)
+		for _, s := range g.spans {
+			spans(r, []span{s}, colors[s.state])
+		}
+		// Assists are drawn in the lower half of the running spans.
+		spans(image.Rect(r.Min.X, (r.Min.Y+r.Max.Y)/2, r.Max.X, r.Max.Y), g.assist, assistColor)
+	}
+
+	for i, l := range legend {
+		p := at[i]
+		fill(image.Rect(p.X, p.Y+(textH-box)/2, p.X+box, p.Y+(textH+box)/2), l.c)
+		bitmapfont.Draw(img, l.name, p.Add(image.Pt(box+gap, 0)), color.Black, scale)
+	}
+	if hidden > 0 {
+		bitmapfont.Draw(img, fmt.Sprintf("%d more goroutines not drawn, see -n", hidden), image.Pt(margin, ly+textH+gap), color.Black, scale)
+	}
+	return img
+}
+
+// label returns the label of the lane of g: its id and the function it
+// started with, without the path of its package.
+func label(g *goroutine) string {
+	l := fmt.Sprintf("G%d", g.id)
+	if g.fn != "" {
+		l += " " + g.fn[strings.LastIndex(g.fn, "/")+1:]
+	}
+	if r := []rune(l); len(r) > maxLabel {
+		l = string(r[:maxLabel-3]) + "..."
+	}
+	return l
+}
+
+// tick returns a step of 1, 2, or 5 times a power of ten nanoseconds that
+// divides d in at most n parts, zero if d is not positive.
+func tick(d int64, n int) int64 {
+	if d <= 0 {
+		return 0
+	}
+	if n < 1 {
+		n = 1
+	}
+	for step := int64(1); ; step *= 10 {
+		for _, m := range []int64{1, 2, 5} {
+			if d/(step*m) <= int64(n) {
+				return step * m
+			}
+		}
+	}
+}
+
+// short formats the duration of ns nanoseconds with at most a few digits.
+func short(ns int64) string {
+	d := time.Duration(ns)
+	switch {
+	case d >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		d = d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		d = d.Round(10 * time.Nanosecond)
+	}
+	// The font has no µ.
+	return strings.Replace(d.String(), "µ", "u", 1)
+}
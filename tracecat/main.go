@@ -0,0 +1,128 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// tracecat draws a timeline of a Go execution trace, with what goroutines did
+// over time and the garbage collections, and displays it in the terminal: a
+// quick look before deciding to open the full trace with go tool trace.
+//
+// Usage:
+//
+//	go test -trace trace.out && tracecat trace.out
+//	go tool trace -d=parsed trace.out | tracecat [flags] -
+//
+// The events of the trace are read with go tool trace, from Go 1.23 or later.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/chart"
+	"github.com/pkg/errors"
+)
+
+var (
+	lanes    = flag.Int("n", 20, "draw at most this many goroutines, the ones that ran the longest")
+	system   = flag.Bool("runtime", false, "draw the goroutines of the runtime too, such as the workers of the garbage collector")
+	width    = flag.Int("width", chart.DefaultWidth, "width of the image in pixels")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the timeline displayed, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] trace.out\n\tgo tool trace -d=parsed trace.out | %s [flags] -\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *lanes < 1 {
+		log.Fatal("-n must be at least 1")
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(name string) error {
+	var t *timeline
+	var err error
+	if name == "-" {
+		name = "trace"
+		t, err = parse(os.Stdin)
+	} else {
+		t, err = goTrace(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, t.draw(filepath.Base(name), *width, *lanes, *system)); err != nil {
+		return errors.Wrap(err, "could not encode timeline")
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.Name("trace.png")}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(enc.Encode(buf), "could not display timeline")
+}
+
+// goTrace parses the events of the trace in the named file, printed by go tool
+// trace as they're read, since they can be much larger than the trace.
+func goTrace(name string) (*timeline, error) {
+	if _, err := os.Stat(name); err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", name)
+	}
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command("go", "tool", "trace", "-d=parsed", name)
+	cmd.Stderr = stderr
+	events, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not run go tool trace")
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "could not run go tool trace")
+	}
+	t, parseErr := parse(events)
+	// What's left is read so go tool trace doesn't block, and then exits.
+	_, copyErr := io.Copy(ioutil.Discard, events)
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Errorf("go tool trace failed: %s", msg)
+		}
+		return nil, errors.Wrap(err, "go tool trace failed")
+	}
+	if copyErr != nil {
+		return nil, errors.Wrap(copyErr, "could not read go tool trace")
+	}
+	return t, parseErr
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// States of goroutines drawn, others such as Waiting are left blank.
+const (
+	running  = "Running"
+	runnable = "Runnable"
+	syscall  = "Syscall"
+)
+
+// A span of time, in nanoseconds.
+type span struct {
+	from, to int64
+	// state of the goroutine during the span, if it's one.
+	state string
+}
+
+// A goroutine and what it did during the trace.
+type goroutine struct {
+	id int64
+	// fn is the function the goroutine started with, empty if it started
+	// before the trace.
+	fn     string
+	spans  []span
+	assist []span
+	// running is the time spent running.
+	running int64
+	// Current state, and since when.
+	state string
+	since int64
+}
+
+// enter records that g entered state at t.
+func (g *goroutine) enter(state string, t int64) {
+	if g.state == running || g.state == runnable || g.state == syscall {
+		g.spans = append(g.spans, span{g.since, t, g.state})
+		if g.state == running {
+			g.running += t - g.since
+		}
+	}
+	g.state, g.since = state, t
+}
+
+// A timeline of the goroutines and garbage collections of a trace.
+type timeline struct {
+	start, end int64
+	goroutines map[int64]*goroutine
+	// gc are the mark phases of garbage collections, and stw the times the
+	// world was stopped.
+	gc, stw []span
+}
+
+var (
+	eventRE      = regexp.MustCompile(`^M=\S+ P=\S+ G=\S+ (\w+) Time=(\d+)(.*)$`)
+	transitionRE = regexp.MustCompile(`(\w+ID)=(-?\d+) (\w+)->(\w+)`)
+	rangeRE      = regexp.MustCompile(`Name="([^"]*)" Scope=(\S+)`)
+	scopeRE      = regexp.MustCompile(`^Goroutine\((\d+)\)$`)
+)
+
+// parse reads the events of a trace as printed by go tool trace -d=parsed,
+// made of lines such as
+//
+//	M=1 P=0 G=1 StateTransition Time=100 GoID=7 Runnable->Running Reason=""
+//
+// followed by indented stacks. The function a goroutine starts with is the
+// first frame of the stack of its creation. Events cut short, as at the end of
+// truncated output, are errors.
+func parse(r io.Reader) (*timeline, error) {
+	t := &timeline{goroutines: map[int64]*goroutine{}}
+	get := func(id int64) *goroutine {
+		g, ok := t.goroutines[id]
+		if !ok {
+			g = &goroutine{id: id}
+			t.goroutines[id] = g
+		}
+		return g
+	}
+	// Ranges begun, by name and scope.
+	open := map[string]int64{}
+	// created is the goroutine whose creation stack follows, if any.
+	var created *goroutine
+	inStack, events := false, 0
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if strings.HasPrefix(line, "TransitionStack=") {
+			inStack = created != nil
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			if inStack {
+				created.fn = strings.TrimSpace(strings.Split(line, " @ ")[0])
+				created, inStack = nil, false
+			}
+			continue
+		}
+		m := eventRE.FindStringSubmatch(line)
+		if m == nil {
+			if strings.HasPrefix(line, "M=") {
+				return nil, errors.Errorf("line %d is not a complete event: %q", n, line)
+			}
+			continue
+		}
+		created, inStack = nil, false
+		kind, rest := m[1], m[3]
+		at, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("line %d has a bad time: %q", n, line)
+		}
+		if events == 0 {
+			t.start = at
+		}
+		if at > t.end {
+			t.end = at
+		}
+		events++
+
+		switch kind {
+		case "StateTransition":
+			m := transitionRE.FindStringSubmatch(rest)
+			if m == nil {
+				return nil, errors.Errorf("line %d is not a complete state transition: %q", n, line)
+			}
+			if m[1] != "GoID" {
+				// Transitions of Ps and Ms are not drawn.
+				continue
+			}
+			id, err := strconv.ParseInt(m[2], 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("line %d has a bad goroutine: %q", n, line)
+			}
+			g := get(id)
+			g.enter(m[4], at)
+			if m[3] == "NotExist" {
+				created = g
+			}
+		case "RangeBegin", "RangeActive", "RangeEnd":
+			m := rangeRE.FindStringSubmatch(rest)
+			if m == nil {
+				return nil, errors.Errorf("line %d is not a complete range: %q", n, line)
+			}
+			key := m[1] + " " + m[2]
+			if kind != "RangeEnd" {
+				open[key] = at
+				continue
+			}
+			from, ok := open[key]
+			if !ok {
+				from = t.start
+			}
+			delete(open, key)
+			t.addRange(m[1], m[2], span{from: from, to: at}, get)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read the trace")
+	}
+	if events == 0 {
+		return nil, errors.New("no events found, is it the output of go tool trace -d=parsed?")
+	}
+
+	// What's still going on lasts until the end of the trace.
+	for key, from := range open {
+		i := strings.LastIndex(key, " ")
+		t.addRange(key[:i], key[i+1:], span{from: from, to: t.end}, get)
+	}
+	for _, g := range t.goroutines {
+		g.enter("", t.end)
+	}
+	return t, nil
+}
+
+// addRange records the range of the given name and scope, if it's drawn.
+func (t *timeline) addRange(name, scope string, s span, get func(int64) *goroutine) {
+	switch {
+	case name == "GC concurrent mark phase":
+		t.gc = append(t.gc, s)
+	case strings.HasPrefix(name, "stop-the-world"):
+		t.stw = append(t.stw, s)
+	case name == "GC mark assist":
+		if m := scopeRE.FindStringSubmatch(scope); m != nil {
+			id, _ := strconv.ParseInt(m[1], 10, 64)
+			g := get(id)
+			g.assist = append(g.assist, s)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tc := []struct {
+		name       string
+		input      string
+		start, end int64
+		goroutines map[int64]*goroutine
+		gc, stw    []span
+	}{
+		{
+			name: "transitions",
+			input: `M=1 P=0 G=1 StateTransition Time=100 GoID=7 NotExist->Runnable Reason=""
+TransitionStack=
+	main.worker @ 0x4a12e0
+		/src/main.go:10
+Stack=
+	main.main @ 0x4a1200
+		/src/main.go:20
+M=1 P=0 G=1 StateTransition Time=150 GoID=7 Runnable->Running Reason=""
+M=1 P=0 G=7 StateTransition Time=250 GoID=7 Running->Syscall Reason=""
+M=1 P=-1 G=7 StateTransition Time=260 ProcID=0 Running->Idle Reason=""
+M=1 P=1 G=7 StateTransition Time=300 GoID=7 Syscall->Running Reason=""
+M=1 P=1 G=7 StateTransition Time=350 GoID=7 Running->Waiting Reason="chan receive"
+`,
+			start: 100,
+			end:   350,
+			goroutines: map[int64]*goroutine{
+				7: {id: 7, fn: "main.worker", running: 150, since: 350, spans: []span{
+					{100, 150, runnable}, {150, 250, running}, {250, 300, syscall}, {300, 350, running},
+				}},
+			},
+		},
+		{
+			name: "started before the trace",
+			input: `M=1 P=0 G=1 StateTransition Time=10 GoID=1 Undetermined->Running Reason=""
+M=1 P=0 G=1 StateTransition Time=40 GoID=2 Undetermined->Runnable Reason=""
+TransitionStack=
+	runtime.main @ 0x1
+M=1 P=0 G=1 Metric Time=90 Name="/sched/gomaxprocs:threads" Value=Uint64(8)
+`,
+			start: 10,
+			end:   90,
+			goroutines: map[int64]*goroutine{
+				1: {id: 1, running: 80, since: 90, spans: []span{{10, 90, running}}},
+				2: {id: 2, since: 90, spans: []span{{40, 90, runnable}}},
+			},
+		},
+		{
+			name: "ranges",
+			input: `M=1 P=0 G=1 RangeBegin Time=100 Name="stop-the-world (GC sweep termination)" Scope=Goroutine(1)
+M=1 P=0 G=1 RangeEnd Time=120 Name="stop-the-world (GC sweep termination)" Scope=Goroutine(1) Attributes=[]
+M=1 P=0 G=1 RangeBegin Time=120 Name="GC concurrent mark phase" Scope=None
+M=1 P=0 G=3 RangeBegin Time=130 Name="GC mark assist" Scope=Goroutine(3)
+M=1 P=0 G=3 RangeEnd Time=140 Name="GC mark assist" Scope=Goroutine(3) Attributes=[]
+M=1 P=0 G=4 RangeEnd Time=150 Name="GC mark assist" Scope=Goroutine(4) Attributes=[]
+M=1 P=0 G=1 RangeBegin Time=160 Name="GC incremental sweep" Scope=Proc(0)
+M=1 P=0 G=1 RangeEnd Time=170 Name="GC incremental sweep" Scope=Proc(0) Attributes=[]
+M=1 P=0 G=1 Sync Time=200 N=2
+`,
+			start: 100,
+			end:   200,
+			goroutines: map[int64]*goroutine{
+				3: {id: 3, since: 200, assist: []span{{from: 130, to: 140}}},
+				4: {id: 4, since: 200, assist: []span{{from: 100, to: 150}}},
+			},
+			// The mark phase still going on lasts until the end.
+			gc:  []span{{from: 120, to: 200}},
+			stw: []span{{from: 100, to: 120}},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			tl, err := parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("could not parse: %v", err)
+			}
+			if tl.start != tt.start || tl.end != tt.end {
+				t.Errorf("expected times %d to %d; got %d to %d", tt.start, tt.end, tl.start, tl.end)
+			}
+			if !reflect.DeepEqual(tl.goroutines, tt.goroutines) {
+				t.Errorf("expected goroutines %v; got %v", tt.goroutines, tl.goroutines)
+			}
+			if !reflect.DeepEqual(tl.gc, tt.gc) || !reflect.DeepEqual(tl.stw, tt.stw) {
+				t.Errorf("expected gc %v and stw %v; got %v and %v", tt.gc, tt.stw, tl.gc, tl.stw)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tc := []struct {
+		name, input, err string
+	}{
+		{"empty", "", "no events found"},
+		{"not a trace", "go: cannot find main module\n", "no events found"},
+		{"cut event", "M=1 P=0 G=1 Sync Time=10 N=1\nM=1 P=0 G=1 StateTrans\n", "line 2 is not a complete event"},
+		{"cut transition", "M=1 P=0 G=1 StateTransition Time=10 GoID=7 Runn\n", "line 1 is not a complete state transition"},
+		{"cut range", "M=1 P=0 G=1 RangeBegin Time=10 Name=\"GC concur\n", "line 1 is not a complete range"},
+		{"bad time", "M=1 P=0 G=1 Sync Time=99999999999999999999 N=1\n", "line 1 has a bad time"},
+		{"bad goroutine", "M=1 P=0 G=1 StateTransition Time=10 GoID=99999999999999999999 Running->Waiting\n", "line 1 has a bad goroutine"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseTrace(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "trace.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tl, err := parse(f)
+	if err != nil {
+		t.Fatalf("could not parse: %v", err)
+	}
+	if tl.start != 10525535537088 || tl.end != 10525536157441 {
+		t.Errorf("expected times 10525535537088 to 10525536157441; got %d to %d", tl.start, tl.end)
+	}
+	fns := map[int64]string{}
+	for id, g := range tl.goroutines {
+		if g.fn != "" {
+			fns[id] = g.fn
+		}
+	}
+	want := map[int64]string{
+		6:  "runtime.traceStartReadCPU.func1",
+		7:  "runtime.(*traceAdvancerState).start.func1",
+		8:  "runtime/trace.(*traceMultiplexer).startLocked.func1",
+		9:  "main.main.func1",
+		10: "main.main.func1",
+		11: "runtime.gcBgMarkWorker",
+	}
+	if !reflect.DeepEqual(fns, want) {
+		t.Errorf("expected functions %q; got %q", want, fns)
+	}
+	if len(tl.gc) != 1 || len(tl.stw) != 3 {
+		t.Errorf("expected 1 mark phase and 3 stops of the world; got %v and %v", tl.gc, tl.stw)
+	}
+	if g := tl.goroutines[1]; g == nil || g.running == 0 {
+		t.Errorf("expected the main goroutine to run; got %+v", g)
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "trace.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n")
+	for i, line := range lines {
+		// Whole lines read fine, with what's still going on lasting to the end.
+		if _, err := parse(strings.NewReader(strings.Join(lines[:i+1], ""))); err != nil {
+			t.Errorf("could not parse the first %d lines: %v", i+1, err)
+		}
+		prefix := strings.Join(lines[:i], "")
+		// Lines cut anywhere must not panic.
+		parse(strings.NewReader(prefix + line[:len(line)/2]))
+		// Events cut before their time are errors.
+		if j := strings.Index(line, " Time="); strings.HasPrefix(line, "M=") && j >= 0 {
+			if _, err := parse(strings.NewReader(prefix + line[:j])); err == nil {
+				t.Errorf("expected an error for line %d cut short: %q", i+1, line[:j])
+			}
+		}
+	}
+}
+
+func TestGoTrace(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "trace.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("trace", func(t *testing.T) {
+		// go tool trace -d=parsed needs Go 1.23 or later.
+		if err := exec.Command("go", "tool", "trace", "-d=parsed", filepath.Join("testdata", "trace.out")).Run(); err != nil {
+			t.Skipf("go tool trace can't read the trace: %v", err)
+		}
+		tl, err := goTrace(filepath.Join("testdata", "trace.out"))
+		if err != nil {
+			t.Fatalf("could not read the trace: %v", err)
+		}
+		if len(tl.goroutines) == 0 || tl.end <= tl.start {
+			t.Errorf("expected goroutines over some time; got %d from %d to %d", len(tl.goroutines), tl.start, tl.end)
+		}
+	})
+
+	corrupt := append([]byte{}, b...)
+	for i := 16; i < 64; i++ {
+		corrupt[i] = 0xff
+	}
+	tc := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", b[:len(b)/2]},
+		{"corrupt", corrupt},
+		{"header only", b[:10]},
+		{"not a trace", []byte("PASS\nok  \texample.com/foo\t0.01s\n")},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "trace.out")
+			if err := ioutil.WriteFile(name, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := goTrace(name); err == nil {
+				t.Errorf("expected an error for a %s trace", tt.name)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		if _, err := goTrace(filepath.Join(t.TempDir(), "trace.out")); err == nil || !strings.Contains(err.Error(), "could not open") {
+			t.Errorf("expected could not open; got %v", err)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// watermark applies a watermark, an image such as a logo or a line of text, to
+// every image in a directory, writing the results to another directory. The
+// first images are previewed before and after in iTerm2 as they're done.
+//
+// Usage:
+//
+//	watermark -mark logo.png [flags] dir outdir
+//	watermark -text "© ACME" [flags] dir outdir
+//
+// With -json, a line of JSON describes each image written, or why it wasn't.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/bitmapfont"
+	"github.com/campoy/tools/imgcat/convert"
+	"github.com/pkg/errors"
+)
+
+var (
+	markPath = flag.String("mark", "", "image drawn over the images, such as a PNG logo")
+	text     = flag.String("text", "", "text drawn over the images, instead of -mark")
+	position = flag.String("pos", "bottom-right", "where the mark is drawn: top-left, top-right, bottom-left, bottom-right, or center")
+	opacity  = flag.Float64("opacity", 0.5, "opacity of the mark, from 0 to 1")
+	scale    = flag.Float64("scale", 0.2, "width of the mark relative to the width of the images")
+	quality  = flag.Int("q", convert.DefaultQuality, "quality of JPEG output from 1 to 100")
+	workers  = flag.Int("j", runtime.NumCPU(), "number of images processed concurrently")
+	previews = flag.Int("preview", 3, "number of images previewed before and after, when the output is iTerm2")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing each image written, with the progress written to the terminal instead")
+)
+
+var positions = map[string]bool{
+	"top-left": true, "top-right": true, "bottom-left": true, "bottom-right": true, "center": true,
+}
+
+// previewSize is the size of the images of previews, in pixels.
+const previewSize = 320
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s -mark logo.png [flags] dir outdir\n\t%s -text text [flags] dir outdir\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || (*markPath == "") == (*text == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !positions[*position] {
+		log.Fatalf("unknown position %q, use top-left, top-right, bottom-left, bottom-right, or center", *position)
+	}
+	if *opacity < 0 || *opacity > 1 || *scale <= 0 || *scale > 1 {
+		log.Fatal("-opacity must be between 0 and 1, and -scale between 0 and 1 but not 0")
+	}
+	if err := run(flag.Arg(0), flag.Arg(1)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A result of watermarking a file.
+type result struct {
+	before, after image.Image
+	// size of the image in pixels, and bytes written.
+	size  image.Point
+	bytes int64
+	err   error
+}
+
+// A description of an image written printed with -json, with fields named
+// like the ones of imgcat -json.
+type description struct {
+	Name   string        `json:"name"`
+	Format imgcat.Format `json:"format,omitempty"`
+	Width  int           `json:"width,omitempty"`
+	Height int           `json:"height,omitempty"`
+	Output int64         `json:"output_bytes"`
+	Err    string        `json:"error,omitempty"`
+}
+
+func run(dir, outdir string) error {
+	if same, err := sameDir(dir, outdir); err != nil || same {
+		if err == nil {
+			err = errors.New("the output directory must be different, images are not overwritten")
+		}
+		return err
+	}
+	var mark image.Image
+	if *markPath != "" {
+		var err error
+		if mark, err = load(*markPath); err != nil {
+			return err
+		}
+	}
+	names, err := imageNames(dir)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.Errorf("no images in %s", dir)
+	}
+	if err := os.MkdirAll(outdir, 0755); err != nil {
+		return errors.Wrapf(err, "could not create %s", outdir)
+	}
+
+	// The standard output carries the descriptions of the images with -json.
+	out := io.Writer(os.Stdout)
+	if *jsonFlag {
+		out, _ = imgcat.JSONMode()
+	}
+	var enc *imgcat.Encoder
+	if *previews > 0 && imgcat.IsSupported() && imgcat.IsTerminal(out) {
+		enc, _ = imgcat.NewEncoder(out, imgcat.Inline(true), imgcat.PreserveAspectRatio(true))
+	}
+
+	// Files are processed by a pool of workers, and reported in order.
+	results := make([]chan result, len(names))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+	work := make(chan int)
+	n := *workers
+	if n < 1 {
+		n = 1
+	}
+	for w := 0; w < n; w++ {
+		go func() {
+			for i := range work {
+				keep := enc != nil && i < *previews
+				results[i] <- process(filepath.Join(dir, names[i]), filepath.Join(outdir, names[i]), mark, keep)
+			}
+		}()
+	}
+	go func() {
+		for i := range names {
+			work <- i
+		}
+		close(work)
+	}()
+
+	failed := 0
+	for i, name := range names {
+		r := <-results[i]
+		if *jsonFlag {
+			d := description{Name: filepath.Join(outdir, name), Output: r.bytes}
+			if r.err != nil {
+				d.Err = r.err.Error()
+			} else {
+				d.Format, d.Width, d.Height = convert.FormatOf(name), r.size.X, r.size.Y
+			}
+			b, _ := json.Marshal(d)
+			fmt.Printf("%s\n", b)
+		}
+		if r.err != nil {
+			if !*jsonFlag {
+				log.Print(r.err)
+			}
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "%s -> %s\n", filepath.Join(dir, name), filepath.Join(outdir, name))
+		if r.before != nil {
+			sheet := imgcat.ContactSheet([]imgcat.Thumbnail{{Image: r.before, Label: name}, {Image: r.after, Label: "watermarked"}}, 2, previewSize)
+			buf := new(bytes.Buffer)
+			if err := png.Encode(buf, sheet); err != nil {
+				return errors.Wrap(err, "could not encode preview")
+			}
+			if err := enc.Encode(buf); err != nil {
+				return err
+			}
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d images could not be watermarked", failed, len(names))
+	}
+	return nil
+}
+
+// process watermarks the image in path and writes it to out, in the same
+// format. If keep is set, the images before and after, scaled down for
+// previews, are returned too.
+func process(path, out string, mark image.Image, keep bool) result {
+	var r result
+	img, err := load(path)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	marked := watermark(img, mark)
+
+	f, err := os.Create(out)
+	if err != nil {
+		r.err = errors.Wrapf(err, "could not create %s", out)
+		return r
+	}
+	if err := convert.Encode(f, marked, convert.FormatOf(out), *quality); err != nil {
+		f.Close()
+		r.err = errors.Wrapf(err, "could not write %s", out)
+		return r
+	}
+	if info, err := f.Stat(); err == nil {
+		r.bytes = info.Size()
+	}
+	if err := f.Close(); err != nil {
+		r.err = errors.Wrapf(err, "could not write %s", out)
+		return r
+	}
+	r.size = img.Bounds().Size()
+	if keep {
+		r.before = imgcat.Letterbox(img, previewSize, previewSize, color.Transparent)
+		r.after = imgcat.Letterbox(marked, previewSize, previewSize, color.Transparent)
+	}
+	return r
+}
+
+// watermark returns img with mark, or the text given with -text, drawn over
+// it at -pos with -opacity, scaled to -scale times the width of img.
+func watermark(img, mark image.Image) image.Image {
+	b := img.Bounds()
+	w := int(float64(b.Dx()) * *scale)
+	if w < 1 {
+		w = 1
+	}
+	if mark == nil {
+		mark = textMark(*text, w)
+	} else {
+		mark = convert.Scale(mark, w, 0)
+	}
+	mb := mark.Bounds()
+
+	margin := b.Dx()
+	if b.Dy() < margin {
+		margin = b.Dy()
+	}
+	margin /= 50
+	free := b.Size().Sub(mb.Size()).Sub(image.Pt(2*margin, 2*margin))
+	at := image.Pt(margin, margin)
+	if *position == "center" {
+		at = at.Add(free.Div(2))
+	}
+	if strings.HasSuffix(*position, "-right") {
+		at.X += free.X
+	}
+	if strings.HasPrefix(*position, "bottom-") {
+		at.Y += free.Y
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	alpha := image.NewUniform(color.Alpha{uint8(*opacity*0xff + 0.5)})
+	draw.DrawMask(dst, mb.Sub(mb.Min).Add(at), mark, mb.Min, alpha, image.Point{}, draw.Over)
+	return dst
+}
+
+// textMark returns text drawn in white over a dark shadow, at the largest
+// scale that fits in width pixels.
+func textMark(text string, width int) image.Image {
+	s := width / bitmapfont.Measure(text, 1).X
+	if s < 1 {
+		s = 1
+	}
+	size := bitmapfont.Measure(text, s).Add(image.Pt(s, s))
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	bitmapfont.Draw(dst, text, image.Pt(s, s), color.Black, s)
+	bitmapfont.Draw(dst, text, image.Point{}, color.White, s)
+	return dst
+}
+
+// imageNames returns the names of the images in dir that can be written back
+// in the same format, sorted.
+func imageNames(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list %s", dir)
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		switch convert.FormatOf(info.Name()) {
+		case imgcat.PNG, imgcat.JPEG, imgcat.GIF:
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// sameDir reports whether a and b are the same directory. A directory that
+// doesn't exist yet is not the same as any other.
+func sameDir(a, b string) (bool, error) {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not open %s", a)
+	}
+	bi, err := os.Stat(b)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "could not open %s", b)
+	}
+	return os.SameFile(ai, bi), nil
+}
+
+func load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", path)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", path)
+	}
+	return img, nil
+}
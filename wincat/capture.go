@@ -0,0 +1,186 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// A capturer takes screenshots of windows with external tools.
+type capturer interface {
+	// find returns the id of the first window whose title contains title,
+	// ignoring case.
+	find(title string) (string, error)
+	// capture returns a PNG screenshot of the window with the given id.
+	capture(id string) ([]byte, error)
+}
+
+// capturers by the name given to -backend.
+var capturers = map[string]capturer{
+	"x11":  x11{},
+	"sway": sway{},
+}
+
+// detect returns the name of the capturer for the current session.
+func detect() (string, error) {
+	switch {
+	case os.Getenv("SWAYSOCK") != "":
+		return "sway", nil
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		return "", errors.New("only sway can capture windows in Wayland sessions, use -backend x11 for XWayland windows")
+	case os.Getenv("DISPLAY") != "":
+		return "x11", nil
+	}
+	return "", errors.New("no X11 or Wayland session found, set DISPLAY or use -backend")
+}
+
+// x11 captures windows with xdotool and ImageMagick.
+type x11 struct{}
+
+func (x11) find(title string) (string, error) {
+	// xdotool matches names with regular expressions, ignoring case.
+	out, err := command("xdotool", "search", "--onlyvisible", "--name", regexpQuote(title))
+	if ids := strings.Fields(string(out)); len(ids) > 0 {
+		return ids[0], nil
+	}
+	// xdotool exits with an error, and says nothing, when no window matches.
+	if _, ok := errors.Cause(err).(*exec.ExitError); err != nil && !ok {
+		return "", err
+	}
+	return "", errors.Errorf("no window named %q", title)
+}
+
+func (x11) capture(id string) ([]byte, error) {
+	return command("import", "-silent", "-window", id, "png:-")
+}
+
+// sway captures windows of the sway compositor with swaymsg and grim.
+type sway struct{}
+
+// swayNode is a node of the tree returned by swaymsg -t get_tree.
+type swayNode struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Rect struct {
+		X      int `json:"x"`
+		Y      int `json:"y"`
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"rect"`
+	Nodes         []*swayNode `json:"nodes"`
+	FloatingNodes []*swayNode `json:"floating_nodes"`
+}
+
+// walk calls fn for n and its descendants until it returns true, and returns
+// the node it returned true for.
+func (n *swayNode) walk(fn func(*swayNode) bool) *swayNode {
+	if fn(n) {
+		return n
+	}
+	for _, children := range [][]*swayNode{n.Nodes, n.FloatingNodes} {
+		for _, c := range children {
+			if found := c.walk(fn); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func (sway) tree() (*swayNode, error) {
+	out, err := command("swaymsg", "-t", "get_tree", "-r")
+	if err != nil {
+		return nil, err
+	}
+	root := new(swayNode)
+	if err := json.Unmarshal(out, root); err != nil {
+		return nil, errors.Wrap(err, "could not parse the tree of windows")
+	}
+	return root, nil
+}
+
+func (s sway) find(title string) (string, error) {
+	root, err := s.tree()
+	if err != nil {
+		return "", err
+	}
+	lower := strings.ToLower(title)
+	n := root.walk(func(n *swayNode) bool {
+		window := n.Type == "con" || n.Type == "floating_con"
+		return window && len(n.Nodes) == 0 && strings.Contains(strings.ToLower(n.Name), lower)
+	})
+	if n == nil {
+		return "", errors.Errorf("no window named %q", title)
+	}
+	return strconv.FormatInt(n.ID, 10), nil
+}
+
+func (s sway) capture(id string) ([]byte, error) {
+	root, err := s.tree()
+	if err != nil {
+		return nil, err
+	}
+	// Windows move, so their position is looked up for every capture.
+	n := root.walk(func(n *swayNode) bool { return strconv.FormatInt(n.ID, 10) == id })
+	if n == nil {
+		return nil, errors.Errorf("no window with id %s", id)
+	}
+	r := n.Rect
+	if r.Width <= 0 || r.Height <= 0 {
+		return nil, errors.Errorf("window %s is not visible", id)
+	}
+	return command("grim", "-g", fmt.Sprintf("%d,%d %dx%d", r.X, r.Y, r.Width, r.Height), "-")
+}
+
+// command runs the named command and returns its output, or an error with what
+// it wrote to its standard error.
+func command(name string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, errors.Errorf("%s is needed to capture windows, but was not found", name)
+	}
+	stderr := new(bytes.Buffer)
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, errors.Errorf("%s failed: %s", name, msg)
+		}
+		return out, errors.Wrapf(err, "%s failed", name)
+	}
+	return out, nil
+}
+
+// regexpQuote escapes the characters of s that have a meaning in the
+// extended regular expressions used by xdotool.
+func regexpQuote(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// wincat captures a window of the X11 or sway desktop and displays it in the
+// terminal, optionally refreshing it on an interval, as a poor man's remote
+// screen sharing.
+//
+// Usage:
+//
+//	wincat [flags] title
+//	wincat -id id [flags]
+//
+// Windows are found by a part of their title, and captured with xdotool and
+// ImageMagick on X11, or swaymsg and grim on sway.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/campoy/tools/imgcat"
+	"github.com/campoy/tools/imgcat/termsize"
+	"github.com/pkg/errors"
+)
+
+var (
+	id       = flag.String("id", "", "id of the window to capture, instead of a title")
+	interval = flag.Duration("interval", 0, "capture the window again on this interval until interrupted, such as 2s")
+	backend  = flag.String("backend", "", "tools used to capture windows: x11 or sway, detected by default")
+	jsonFlag = flag.Bool("json", false, "print a line of JSON describing the capture displayed without -interval, written to the terminal instead")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s [flags] title\n\t%s -id id [flags]\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	// Either a title or -id is needed, not both.
+	want := 1
+	if *id != "" {
+		want = 0
+	}
+	if flag.NArg() != want {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *jsonFlag && *interval > 0 {
+		log.Fatal("-json describes a single capture, it doesn't apply to -interval")
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(title string) error {
+	name := *backend
+	if name == "" {
+		var err error
+		if name, err = detect(); err != nil {
+			return err
+		}
+	}
+	c, ok := capturers[name]
+	if !ok {
+		return errors.Errorf("unknown backend %q, use x11 or sway", name)
+	}
+	window := *id
+	if window == "" {
+		var err error
+		if window, err = c.find(title); err != nil {
+			return err
+		}
+	}
+	if *interval <= 0 {
+		return once(c, window)
+	}
+	return watch(c, window, *interval)
+}
+
+// once displays a single capture of window inline.
+func once(c capturer, window string) error {
+	img, err := c.capture(window)
+	if err != nil {
+		return err
+	}
+	out, options := io.Writer(os.Stdout), []imgcat.Option{imgcat.Inline(true), imgcat.PaneWidth(100), imgcat.PreserveAspectRatio(true)}
+	if *jsonFlag {
+		var opt imgcat.Option
+		out, opt = imgcat.JSONMode()
+		options = append(options, opt)
+	}
+	enc, err := imgcat.NewEncoder(out, options...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(bytes.NewReader(img))
+}
+
+// watch displays window full screen, captured every interval, with a status
+// line below it, until interrupted. Captures that didn't change are not sent
+// again.
+func watch(c capturer, window string, interval time.Duration) error {
+	size, err := termsize.Get()
+	if err != nil {
+		size = termsize.Size{Cols: 80, Rows: 24}
+	}
+	// The last row holds the status line.
+	rows := size.Rows - 1
+	if rows < 1 {
+		rows = 1
+	}
+	enc, err := imgcat.NewEncoder(os.Stdout, imgcat.Inline(true), imgcat.Width(imgcat.Cells(size.Cols)),
+		imgcat.Height(imgcat.Cells(rows)), imgcat.PreserveAspectRatio(true), imgcat.Newline(false))
+	if err != nil {
+		return err
+	}
+
+	// Use the alternate screen, without cursor, and restore the terminal
+	// when interrupted.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	status := func(format string, args ...interface{}) {
+		fmt.Printf("\x1b[%d;1H\x1b[2K%s", size.Rows, fmt.Sprintf(format, args...))
+	}
+
+	var last [sha256.Size]byte
+	for {
+		img, err := c.capture(window)
+		if err != nil {
+			return err
+		}
+		now := time.Now().Format("15:04:05")
+		if sum := sha256.Sum256(img); sum != last {
+			last = sum
+			fmt.Print("\x1b[H")
+			if err := enc.Encode(bytes.NewReader(img)); err != nil {
+				return err
+			}
+			if cfg, _, err := image.DecodeConfig(bytes.NewReader(img)); err == nil {
+				status("window %s  %dx%d  updated at %s", window, cfg.Width, cfg.Height, now)
+			} else {
+				status("window %s  updated at %s", window, now)
+			}
+		}
+
+		select {
+		case <-interrupt:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}